@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+	"ewallet-wallet/config"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/repository"
+	"ewallet-wallet/internal/services"
+	"ewallet-wallet/lifecycle"
+	"fmt"
+	"log"
+	"os"
+)
+
+// RunAuditVerify walks the audit log hash chain and exits non-zero when an
+// entry was altered, removed or inserted out of band.
+func RunAuditVerify() {
+	auditSvc := &services.AuditService{
+		AuditRepo: &repository.AuditRepo{
+			DB: helpers.DB,
+		},
+	}
+
+	result, err := auditSvc.VerifyChain(context.Background())
+	if err != nil {
+		log.Fatal("failed to verify audit chain: ", err)
+	}
+
+	fmt.Println(result)
+	if !result.Valid {
+		os.Exit(1)
+	}
+}
+
+// StartAuditRetryWorker writes the audit entries of committed changes that
+// could not be written at the time.
+func StartAuditRetryWorker(lc *lifecycle.Manager, cfg config.Audit, svc *services.AuditService) {
+	lc.Go("audit retry worker", func(ctx context.Context) {
+		svc.RunRetryWorker(ctx, cfg.RetryInterval)
+	})
+}
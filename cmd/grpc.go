@@ -1,15 +1,16 @@
 package cmd
 
 import (
-	"ewallet-wallet/helpers"
+	"ewallet-wallet/config"
+	"ewallet-wallet/lifecycle"
+	"fmt"
 	"log"
 	"net"
 
-	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 )
 
-func ServeGRPC() {
+func ServeGRPC(lc *lifecycle.Manager, cfg config.App) {
 	// init dependency
 	// dependency := dependencyInject()
 
@@ -17,14 +18,10 @@ func ServeGRPC() {
 	// list method
 	// tokenvalidation.RegisterTokenValidationServer(s, dependency.TokenValidationAPI)
 
-	lis, err := net.Listen("tcp", ":"+helpers.GetEnv("GRPC_PORT", "7000"))
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
 	if err != nil {
 		log.Fatal("failed to listen grpc port: ", err)
 	}
 
-	logrus.Info("start listening grpc on port:" + helpers.GetEnv("GRPC_PORT", "7000"))
-	if err := s.Serve(lis); err != nil {
-		log.Fatal("failed to serve grpc port: ", err)
-	}
-
+	lc.AddGRPC("grpc", s, lis)
 }
@@ -1,45 +1,252 @@
 package cmd
 
 import (
-	"ewallet-wallet/external"
+	"context"
+	"ewallet-wallet/config"
+	"ewallet-wallet/constants"
 	"ewallet-wallet/helpers"
+	adminHandler "ewallet-wallet/internal/handler/admin"
 	healthHandler "ewallet-wallet/internal/handler/healthcheck"
+	paymentRequestHandler "ewallet-wallet/internal/handler/paymentrequest"
+	pinHandler "ewallet-wallet/internal/handler/pin"
+	scheduledPaymentHandler "ewallet-wallet/internal/handler/scheduledpayment"
+	settlementHandler "ewallet-wallet/internal/handler/settlement"
+	tokenHandler "ewallet-wallet/internal/handler/token"
+	topUpHandler "ewallet-wallet/internal/handler/topup"
 	walletHandler "ewallet-wallet/internal/handler/wallet"
+	withdrawalHandler "ewallet-wallet/internal/handler/withdrawal"
 	"ewallet-wallet/internal/repository"
 	"ewallet-wallet/internal/services"
+	"ewallet-wallet/lifecycle"
 	"ewallet-wallet/middleware"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-func ServeHttp() {
+// ServeHttp serves HTTP, and gRPC when enabled, with the background workers
+// until SIGTERM or SIGINT, then drains them and closes the DB pool.
+func ServeHttp(cfg *config.Config) {
+	migrateOnStart(cfg.DB)
+
+	lc := lifecycle.New(cfg.App.ShutdownTimeout)
+	lc.OnStop("database", closeDatabase)
 
 	r := gin.Default()
 
-	healthcheckSvc := &services.Healthcheck{}
+	auditRepo := &repository.AuditRepo{
+		DB: helpers.DB,
+	}
+	auditSvc := &services.AuditService{
+		AuditRepo: auditRepo,
+	}
 
 	walletRepo := &repository.WalletRepo{
 		DB: helpers.DB,
 	}
+	feeSvc := &services.FeeService{
+		FeeRepo: &repository.FeeRepo{
+			DB: helpers.DB,
+		},
+		WalletRepo:   walletRepo,
+		AuditService: auditSvc,
+	}
+	err := feeSvc.EnsureRevenueWallet(context.Background())
+	if err != nil {
+		log.Fatal("failed to set up fee revenue wallet: ", err)
+	}
+
 	walletSvc := &services.WalletService{
-		WalletRepo: walletRepo,
+		WalletRepo:   walletRepo,
+		AuditService: auditSvc,
+		FeeService:   feeSvc,
+	}
+	promoSvc := &services.PromoService{
+		PromoRepo: &repository.PromoRepo{
+			DB: helpers.DB,
+		},
+		WalletService: walletSvc,
+		AuditService:  auditSvc,
 	}
+	walletSvc.PromoService = promoSvc
+	pinSvc := newPINService(cfg, walletRepo, auditSvc)
+	walletSvc.PINService = pinSvc
+	riskSvc := newRiskService(cfg.Risk, walletRepo, walletSvc, auditSvc)
+	walletSvc.RiskService = riskSvc
 
-	external := &external.External{}
+	tokenValidator, umsClient := newTokenValidator(cfg)
+	if umsClient != nil {
+		lc.OnStop("ums client", umsClient.Close)
+	}
+	tokenCache := newTokenCache(tokenValidator, cfg.TokenCache, walletSvc.SyncEmail)
 
+	operatorJWT := helpers.JWTConfig{
+		Issuer: cfg.App.Name,
+		Secret: cfg.Admin.Secret,
+	}
 	middleware := &middleware.ExternalDependency{
-		External: external,
+		External:               tokenCache,
+		DefaultScopes:          unscopedTokenScopes(cfg.Auth),
+		OperatorJWT:            operatorJWT,
+		InternalAPIKey:         cfg.App.InternalAPIKey,
+		LegacyBalanceSignature: cfg.Auth.LegacyBalanceSignature,
+	}
+	if cfg.Auth.LegacyBalanceSignature {
+		log.Println("AUTH_LEGACY_BALANCE_SIGNATURE is on, the client balance route still takes GET signatures over an empty payload")
 	}
+	r.Use(middleware.MiddlewareRequestMeta)
 
-	walletHandler := walletHandler.NewHandler(r, walletSvc, external, middleware)
+	walletHandler := walletHandler.NewHandler(r, walletSvc, tokenCache, middleware)
 	walletHandler.RegisterRoute()
 
+	pinHandler := pinHandler.NewHandler(r, pinSvc, middleware)
+	pinHandler.RegisterRoute()
+
+	adminRepo := &repository.AdminRepo{
+		DB: helpers.DB,
+	}
+	adminSvc := &services.AdminService{
+		AdminRepo:     adminRepo,
+		WalletRepo:    walletRepo,
+		WalletService: walletSvc,
+		AuditService:  auditSvc,
+		FeeService:    feeSvc,
+		PromoService:  promoSvc,
+		RiskService:   riskSvc,
+		OperatorJWT:   operatorJWT,
+	}
+
+	bootstrapUsername := cfg.Admin.BootstrapUsername
+	if bootstrapUsername != "" {
+		err := adminSvc.BootstrapOperator(context.Background(), bootstrapUsername, cfg.Admin.BootstrapPassword)
+		if err != nil {
+			log.Fatal("failed to bootstrap admin operator: ", err)
+		}
+	}
+
+	adminHandler := adminHandler.NewHandler(r, adminSvc, middleware)
+	adminHandler.RegisterRoute()
+
+	scheduledPaymentSvc := &services.ScheduledPaymentService{
+		ScheduledPaymentRepo: &repository.ScheduledPaymentRepo{
+			DB: helpers.DB,
+		},
+		WalletRepo:    walletRepo,
+		WalletService: walletSvc,
+		AuditService:  auditSvc,
+		RetryInterval: cfg.ScheduledPayment.RetryInterval,
+		MaxRetries:    cfg.ScheduledPayment.MaxRetries,
+	}
+	riskSvc.ScheduledPaymentService = scheduledPaymentSvc
+	scheduledPaymentHandler := scheduledPaymentHandler.NewHandler(r, scheduledPaymentSvc, middleware)
+	scheduledPaymentHandler.RegisterRoute()
+
+	paymentRequestSvc := &services.PaymentRequestService{
+		PaymentRequestRepo: &repository.PaymentRequestRepo{
+			DB: helpers.DB,
+		},
+		WalletRepo:    walletRepo,
+		WalletService: walletSvc,
+		AuditService:  auditSvc,
+		FeeService:    feeSvc,
+		PINService:    pinSvc,
+		RiskService:   riskSvc,
+	}
+	riskSvc.PaymentRequestService = paymentRequestSvc
+	paymentRequestHandler := paymentRequestHandler.NewHandler(r, paymentRequestSvc, middleware)
+	paymentRequestHandler.RegisterRoute()
+
+	topUpSvc := &services.TopUpService{
+		TopUpRepo: &repository.TopUpRepo{
+			DB: helpers.DB,
+		},
+		WalletRepo:    walletRepo,
+		WalletService: walletSvc,
+		AuditService:  auditSvc,
+		Gateway:       newPaymentGateway(cfg.TopUp),
+	}
+	topUpHandler := topUpHandler.NewHandler(r, topUpSvc, middleware)
+	topUpHandler.RegisterRoute()
+
+	withdrawalSvc := &services.WithdrawalService{
+		WithdrawalRepo: &repository.WithdrawalRepo{
+			DB: helpers.DB,
+		},
+		WalletRepo:    walletRepo,
+		WalletService: walletSvc,
+		AuditService:  auditSvc,
+		Provider:      newPayoutProvider(cfg.Withdrawal),
+		FeeService:    feeSvc,
+		PINService:    pinSvc,
+		RiskService:   riskSvc,
+	}
+	riskSvc.WithdrawalService = withdrawalSvc
+	withdrawalHandler := withdrawalHandler.NewHandler(r, withdrawalSvc, middleware)
+	withdrawalHandler.RegisterRoute()
+
+	settlementHandler := settlementHandler.NewHandler(r, newSettlementService(cfg.Settlement), middleware)
+	settlementHandler.RegisterRoute()
+
+	tokenHandler := tokenHandler.NewHandler(r, tokenCache, middleware)
+	tokenHandler.RegisterRoute()
+
+	healthcheckSvc := &services.Healthcheck{
+		HealthcheckRepository: &repository.HealthcheckRepo{
+			DB:       helpers.DB,
+			Migrator: newMigrator(cfg.DB),
+		},
+		Timeout: cfg.Health.Timeout,
+	}
+	if umsClient != nil {
+		healthcheckSvc.UMS = umsClient
+	}
 	healthcheckHandler := healthHandler.NewHandler(r, healthcheckSvc)
 	healthcheckHandler.RegisterRoute()
 
-	err := r.Run(":" + helpers.GetEnv("PORT", ""))
+	StartReconciliationScheduler(lc, cfg.Reconciliation)
+	StartScheduledPaymentWorker(lc, cfg.ScheduledPayment, scheduledPaymentSvc)
+	StartPaymentRequestSweeper(lc, cfg.PaymentRequest, paymentRequestSvc)
+	StartTopUpSweeper(lc, cfg.TopUp, topUpSvc)
+	StartWithdrawalWorker(lc, cfg.Withdrawal, withdrawalSvc)
+	StartPromoExpiryWorker(lc, cfg.Promo, promoSvc)
+	StartAuditRetryWorker(lc, cfg.Audit, auditSvc)
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.App.Port))
+	if err != nil {
+		log.Fatal("failed to listen http port: ", err)
+	}
+	lc.AddHTTP("http", &http.Server{Handler: r}, lis)
+
+	if cfg.App.GRPCEnabled {
+		ServeGRPC(lc, cfg.App)
+	}
+
+	err = lc.Run(context.Background())
 	if err != nil {
 		log.Fatal(err)
 	}
+	log.Println("stopped")
+}
+
+func closeDatabase() error {
+	db, err := helpers.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	return db.Close()
+}
+
+// unscopedTokenScopes are the scopes a user token without a scope claim gets,
+// the full access of the tokens issued before scopes existed has to be asked for.
+func unscopedTokenScopes(cfg config.Auth) []string {
+	if !cfg.LegacyFullAccess {
+		return cfg.DefaultScopes
+	}
+
+	log.Println("AUTH_LEGACY_FULL_ACCESS is on, tokens without scopes can move money")
+	return []string{constants.ScopeWalletRead, constants.ScopeWalletDebit, constants.ScopeWalletCredit}
 }
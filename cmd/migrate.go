@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"ewallet-wallet/config"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/migrations"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+func newMigrator(cfg config.DB) *migrations.Migrator {
+	db, err := helpers.DB.DB()
+	if err != nil {
+		log.Fatal("failed to get database pool: ", err)
+	}
+
+	migrator, err := migrations.New(db, cfg.Driver)
+	if err != nil {
+		log.Fatal("failed to load migrations: ", err)
+	}
+	migrator.LockTimeout = cfg.LockTimeout
+
+	return migrator
+}
+
+// migrateOnStart applies the pending migrations before the server takes
+// traffic, DB_MIGRATE=false leaves that to the migrate command.
+func migrateOnStart(cfg config.DB) {
+	if !cfg.Migrate {
+		return
+	}
+
+	applied, err := newMigrator(cfg).Up(context.Background())
+	if err != nil {
+		log.Fatal("failed to migrate database: ", err)
+	}
+	for _, migration := range applied {
+		log.Printf("applied migration %d_%s\n", migration.Version, migration.Name)
+	}
+}
+
+// RunMigrate changes the schema from the command line:
+//
+//	migrate up            apply every pending migration
+//	migrate down [steps]  revert the last steps migrations, 1 by default
+//	migrate to <version>  migrate up or down to version, 0 reverts everything
+//	migrate status        list the migrations and whether they are applied
+func RunMigrate(cfg config.DB, args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: migrate up | down [steps] | to <version> | status")
+	}
+
+	migrator := newMigrator(cfg)
+	ctx := context.Background()
+
+	var (
+		changed []migrations.Migration
+		err     error
+	)
+	switch args[0] {
+	case "up":
+		changed, err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				log.Fatalf("invalid steps %s", args[1])
+			}
+		}
+		changed, err = migrator.Down(ctx, steps)
+	case "to":
+		if len(args) < 2 {
+			log.Fatal("usage: migrate to <version>")
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil || version < 0 {
+			log.Fatalf("invalid version %s", args[1])
+		}
+		changed, err = migrator.To(ctx, version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal("failed to get migration status: ", err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Modified {
+				state += ", modified since"
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+		return
+	default:
+		log.Fatalf("unknown migrate command %s", args[0])
+	}
+	if err != nil {
+		log.Fatal("failed to migrate: ", err)
+	}
+
+	if len(changed) == 0 {
+		fmt.Println("nothing to migrate")
+	}
+	for _, migration := range changed {
+		fmt.Printf("migrated %04d_%s\n", migration.Version, migration.Name)
+	}
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"context"
+	"ewallet-wallet/config"
+	"ewallet-wallet/internal/services"
+	"ewallet-wallet/lifecycle"
+)
+
+// StartPaymentRequestSweeper expires pending payment requests in the
+// background, PAYMENT_REQUEST_SWEEP_INTERVAL=0 turns the sweeper off.
+func StartPaymentRequestSweeper(lc *lifecycle.Manager, cfg config.PaymentRequest, svc *services.PaymentRequestService) {
+	if cfg.SweepInterval <= 0 {
+		return
+	}
+
+	lc.Go("payment request sweeper", func(ctx context.Context) {
+		svc.RunExpirySweeper(ctx, cfg.SweepInterval)
+	})
+}
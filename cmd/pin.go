@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"ewallet-wallet/config"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/external"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/interfaces/i_external"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/repository"
+	"ewallet-wallet/internal/services"
+	"log"
+)
+
+// newPINService builds the PIN policy, debits above the PIN threshold need the
+// PIN or a step-up token signed with the step-up secret.
+func newPINService(cfg *config.Config, walletRepo i_repository.IWalletRepo, auditSvc *services.AuditService) *services.PINService {
+	return &services.PINService{
+		PINRepo: &repository.PINRepo{
+			DB: helpers.DB,
+		},
+		WalletRepo:   walletRepo,
+		AuditService: auditSvc,
+		OTPSender:    newOTPSender(cfg.PIN.OTPSender),
+		Threshold:    cfg.PIN.Threshold,
+		MaxAttempts:  cfg.PIN.MaxAttempts,
+		LockDuration: cfg.PIN.LockDuration,
+		OTPTTL:       cfg.PIN.OTPTTL,
+		StepUpTTL:    cfg.PIN.StepUpTTL,
+		StepUpJWT: helpers.JWTConfig{
+			Issuer: cfg.App.Name,
+			Secret: cfg.PIN.StepUpSecret,
+		},
+	}
+}
+
+// newOTPSender picks the sender by name, only the log sender exists for now.
+func newOTPSender(sender string) i_external.OTPSender {
+	switch sender {
+	case constants.OTPSenderLog:
+		return &external.LogOTPSender{}
+	default:
+		log.Fatalf("unknown OTP_SENDER %s", sender)
+		return nil
+	}
+}
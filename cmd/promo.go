@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"context"
+	"ewallet-wallet/config"
+	"ewallet-wallet/internal/services"
+	"ewallet-wallet/lifecycle"
+)
+
+// StartPromoExpiryWorker takes back expired promo credit in the background,
+// PROMO_EXPIRY_INTERVAL=0 turns the worker off.
+func StartPromoExpiryWorker(lc *lifecycle.Manager, cfg config.Promo, svc *services.PromoService) {
+	if cfg.ExpiryInterval <= 0 {
+		return
+	}
+
+	lc.Go("promo expiry worker", func(ctx context.Context) {
+		svc.RunExpiryWorker(ctx, cfg.ExpiryInterval)
+	})
+}
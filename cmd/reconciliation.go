@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"ewallet-wallet/config"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"ewallet-wallet/internal/repository"
+	"ewallet-wallet/internal/services"
+	"ewallet-wallet/lifecycle"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+func newReconciliationService() *services.ReconciliationService {
+	return &services.ReconciliationService{
+		ReconciliationRepo: &repository.ReconciliationRepo{
+			DB: helpers.DB,
+		},
+		AuditService: &services.AuditService{
+			AuditRepo: &repository.AuditRepo{
+				DB: helpers.DB,
+			},
+		},
+		RecheckDelay: 5 * time.Second,
+	}
+}
+
+func reconciliationOptions(cfg config.Reconciliation) models.ReconciliationOptions {
+	return models.ReconciliationOptions{
+		Formats:        cfg.Formats,
+		OutputDir:      cfg.ReportDir,
+		CreateSuspense: cfg.Suspense,
+	}
+}
+
+// RunReconciliation runs a single reconciliation from the command line and
+// exits non-zero when any wallet does not match its ledger.
+func RunReconciliation(cfg config.Reconciliation, args []string) {
+	defaults := reconciliationOptions(cfg)
+
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	formats := fs.String("format", strings.Join(defaults.Formats, ","), "report formats, comma separated json,csv")
+	outputDir := fs.String("out", defaults.OutputDir, "directory the reports are written to")
+	suspense := fs.Bool("suspense", defaults.CreateSuspense, "open a suspense entry for every mismatch")
+	_ = fs.Parse(args)
+
+	report, err := newReconciliationService().Run(context.Background(), models.ReconciliationOptions{
+		Formats:        strings.Split(*formats, ","),
+		OutputDir:      *outputDir,
+		CreateSuspense: *suspense,
+	})
+	if err != nil {
+		log.Fatal("failed to run reconciliation: ", err)
+	}
+
+	fmt.Printf("reconciliation run %d: %d wallets checked, %d mismatches\n", report.Run.ID, report.Run.WalletCount, report.Run.MismatchCount)
+	for _, file := range report.Files {
+		fmt.Println("report written to", file)
+	}
+
+	if report.Run.MismatchCount > 0 {
+		os.Exit(1)
+	}
+}
+
+// StartReconciliationScheduler runs the nightly reconciliation in the
+// background when RECONCILIATION_SCHEDULE (HH:MM) is set.
+func StartReconciliationScheduler(lc *lifecycle.Manager, cfg config.Reconciliation) {
+	at := cfg.Schedule
+	if at == "" {
+		return
+	}
+
+	lc.Go("reconciliation scheduler", func(ctx context.Context) {
+		err := newReconciliationService().Schedule(ctx, at, reconciliationOptions(cfg))
+		if err != nil {
+			log.Println("reconciliation scheduler stopped: ", err)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"ewallet-wallet/config"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"ewallet-wallet/internal/repository"
+	"ewallet-wallet/internal/services"
+	"log"
+)
+
+// newRiskService loads the rules of the rules file. Without the file every
+// movement is allowed, the decisions are still recorded.
+func newRiskService(cfg config.Risk, walletRepo i_repository.IWalletRepo, walletSvc *services.WalletService, auditSvc *services.AuditService) *services.RiskService {
+	var rules []models.RiskRule
+
+	path := cfg.RulesFile
+	if path == "" {
+		log.Println("RISK_RULES_FILE is not configured, risk screening allows every transaction")
+	} else {
+		var err error
+		rules, err = services.LoadRiskRules(path)
+		if err != nil {
+			log.Fatal("invalid RISK_RULES_FILE: ", err)
+		}
+	}
+
+	return &services.RiskService{
+		RiskRepo: &repository.RiskRepo{
+			DB: helpers.DB,
+		},
+		WalletRepo:    walletRepo,
+		WalletService: walletSvc,
+		AuditService:  auditSvc,
+		Rules:         rules,
+	}
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"context"
+	"ewallet-wallet/config"
+	"ewallet-wallet/internal/services"
+	"ewallet-wallet/lifecycle"
+)
+
+// StartScheduledPaymentWorker charges due scheduled payments in the
+// background, SCHEDULED_PAYMENT_INTERVAL=0 turns the worker off.
+func StartScheduledPaymentWorker(lc *lifecycle.Manager, cfg config.ScheduledPayment, svc *services.ScheduledPaymentService) {
+	if cfg.Interval <= 0 {
+		return
+	}
+
+	lc.Go("scheduled payment worker", func(ctx context.Context) {
+		svc.RunWorker(ctx, cfg.Interval)
+	})
+}
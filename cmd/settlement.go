@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"ewallet-wallet/config"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"ewallet-wallet/internal/repository"
+	"ewallet-wallet/internal/services"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func newSettlementService(cfg config.Settlement) *services.SettlementService {
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		log.Fatal("invalid SETTLEMENT_TIMEZONE: ", err)
+	}
+
+	return &services.SettlementService{
+		SettlementRepo: &repository.SettlementRepo{
+			DB: helpers.DB,
+		},
+		Location: loc,
+	}
+}
+
+// RunSettlement writes the daily settlement of one or every client to a file,
+// by default for yesterday.
+func RunSettlement(cfg config.Settlement, args []string) {
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+
+	fs := flag.NewFlagSet("settlement", flag.ExitOnError)
+	dateFrom := fs.String("from", yesterday, "first settlement day, YYYY-MM-DD")
+	dateTo := fs.String("to", yesterday, "last settlement day, YYYY-MM-DD")
+	clientID := fs.String("client", "", "settle a single client instead of every client")
+	format := fs.String("format", constants.ReportFormatCSV, "report format, json or csv")
+	outputDir := fs.String("out", cfg.ReportDir, "directory the report is written to")
+	_ = fs.Parse(args)
+
+	if *format != constants.ReportFormatJSON && *format != constants.ReportFormatCSV {
+		log.Fatalf("unknown report format %s", *format)
+	}
+
+	svc := newSettlementService(cfg)
+	ctx := context.Background()
+
+	var (
+		days []models.SettlementDay
+		err  error
+		name = "all"
+	)
+	if *clientID != "" {
+		name = *clientID
+		days, err = svc.GetClientSettlement(ctx, *clientID, *dateFrom, *dateTo)
+	} else {
+		days, err = svc.GetSettlements(ctx, *dateFrom, *dateTo)
+	}
+	if err != nil {
+		log.Fatal("failed to build settlement: ", err)
+	}
+
+	err = os.MkdirAll(*outputDir, 0o750)
+	if err != nil {
+		log.Fatal("failed to create report directory: ", err)
+	}
+
+	path := filepath.Join(*outputDir, fmt.Sprintf("settlement-%s-%s-%s.%s", name, *dateFrom, *dateTo, *format))
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatal("failed to create report file: ", err)
+	}
+	defer file.Close()
+
+	switch *format {
+	case constants.ReportFormatJSON:
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(days)
+	case constants.ReportFormatCSV:
+		err = helpers.WriteSettlementCSV(file, days)
+	}
+	if err != nil {
+		log.Fatal("failed to write report: ", err)
+	}
+
+	fmt.Printf("settlement %s to %s: %d rows written to %s\n", *dateFrom, *dateTo, len(days), path)
+}
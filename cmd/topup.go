@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"ewallet-wallet/config"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/external"
+	"ewallet-wallet/internal/interfaces/i_external"
+	"ewallet-wallet/internal/models"
+	"ewallet-wallet/internal/services"
+	"ewallet-wallet/lifecycle"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// newPaymentGateway picks the gateway named by TOPUP_GATEWAY, only the fake
+// gateway exists for now.
+func newPaymentGateway(cfg config.TopUp) i_external.PaymentGateway {
+	switch cfg.Gateway {
+	case constants.GatewayFake:
+		return newFakeGateway(cfg)
+	default:
+		log.Fatalf("unknown TOPUP_GATEWAY %s", cfg.Gateway)
+		return nil
+	}
+}
+
+func newFakeGateway(cfg config.TopUp) *external.FakeGateway {
+	return &external.FakeGateway{
+		Secret:  cfg.GatewaySecret,
+		BaseURL: cfg.GatewayBaseURL,
+	}
+}
+
+// StartTopUpSweeper expires unpaid top-ups in the background,
+// TOPUP_SWEEP_INTERVAL=0 turns the sweeper off.
+func StartTopUpSweeper(lc *lifecycle.Manager, cfg config.TopUp, svc *services.TopUpService) {
+	if cfg.SweepInterval <= 0 {
+		return
+	}
+
+	lc.Go("top-up sweeper", func(ctx context.Context) {
+		svc.RunExpirySweeper(ctx, cfg.SweepInterval)
+	})
+}
+
+// RunTopUpCallback prints a signed fake gateway callback, so a top-up can be
+// paid locally with curl.
+func RunTopUpCallback(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("topup-callback", flag.ExitOnError)
+	reference := fs.String("reference", "", "top-up reference")
+	amount := fs.String("amount", "", "paid amount")
+	status := fs.String("status", constants.TopUpStatusPaid, "paid or failed")
+	reason := fs.String("reason", "", "failure reason")
+	fs.Parse(args)
+
+	if *reference == "" {
+		log.Fatal("-reference is required")
+	}
+
+	paidAmount, err := strconv.ParseFloat(*amount, 64)
+	if err != nil {
+		log.Fatal("invalid -amount: ", err)
+	}
+
+	gateway := newFakeGateway(cfg.TopUp)
+	payload, signature, err := gateway.NewFakeCallback(models.GatewayCallback{
+		Reference:     *reference,
+		Status:        *status,
+		Amount:        paidAmount,
+		FailureReason: *reason,
+	})
+	if err != nil {
+		log.Fatal("failed to build callback: ", err)
+	}
+
+	fmt.Printf("curl -X POST http://localhost:%d/wallet/v1/topups/callback -H '%s: %s' -d '%s'\n",
+		cfg.App.Port, constants.HeaderCallbackSignature, signature, payload)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"ewallet-wallet/config"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/external"
+	"ewallet-wallet/internal/models"
+	"log"
+	"net/http"
+)
+
+// newUMSClient connects to the UMS, TLS turns on TLS and a client
+// certificate with its key mutual TLS.
+func newUMSClient(cfg config.UMS) *external.UMSClient {
+	client, err := external.NewUMSClient(external.UMSConfig{
+		Address:          cfg.Address,
+		TLS:              cfg.TLS,
+		CAFile:           cfg.CAFile,
+		CertFile:         cfg.CertFile,
+		KeyFile:          cfg.KeyFile,
+		ServerName:       cfg.ServerName,
+		Timeout:          cfg.Timeout,
+		MaxRetries:       cfg.MaxRetries,
+		RetryBackoff:     cfg.RetryBackoff,
+		BreakerThreshold: cfg.BreakerThreshold,
+		BreakerCooldown:  cfg.BreakerCooldown,
+	})
+	if err != nil {
+		log.Fatal("failed to create ums client: ", err)
+	}
+
+	return client
+}
+
+// newTokenValidator picks how user tokens are checked from the auth mode, the
+// UMS client is only created, and returned for closing, in remote mode.
+func newTokenValidator(cfg *config.Config) (external.TokenValidator, *external.UMSClient) {
+	tokenCfg := external.LocalTokenConfig{
+		Secret:    cfg.App.Secret,
+		Issuer:    cfg.Auth.Issuer,
+		Audience:  cfg.Auth.Audience,
+		ClockSkew: cfg.Auth.ClockSkew,
+	}
+
+	switch cfg.Auth.Mode {
+	case constants.AuthModeRemote:
+		umsClient := newUMSClient(cfg.UMS)
+		return umsClient, umsClient
+	case constants.AuthModeLocalHS256:
+		return external.NewHS256TokenValidator(tokenCfg), nil
+	case constants.AuthModeLocalJWKS:
+		jwks, err := external.NewJWKS(context.Background(), external.JWKSConfig{
+			Source:          cfg.Auth.JWKSSource,
+			RefreshInterval: cfg.Auth.JWKSRefreshInterval,
+			HTTPClient: &http.Client{
+				Timeout: cfg.Auth.JWKSFetchTimeout,
+			},
+		})
+		if err != nil {
+			log.Fatal("failed to load jwks: ", err)
+		}
+		return external.NewJWKSTokenValidator(jwks, tokenCfg), nil
+	default:
+		log.Fatalf("unknown AUTH_MODE %s", cfg.Auth.Mode)
+		return nil, nil
+	}
+}
+
+// newTokenCache puts a token cache in front of the validator, a size of 0
+// turns caching off. onValidated runs for every token the validator accepted.
+func newTokenCache(validator external.TokenValidator, cfg config.TokenCache, onValidated func(ctx context.Context, data models.TokenData)) *external.TokenCache {
+	return external.NewTokenCache(validator, external.TokenCacheConfig{
+		MaxEntries:  cfg.Size,
+		TTL:         cfg.TTL,
+		NegativeTTL: cfg.NegativeTTL,
+		OnValidated: onValidated,
+	})
+}
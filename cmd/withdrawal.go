@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"ewallet-wallet/config"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/external"
+	"ewallet-wallet/internal/interfaces/i_external"
+	"ewallet-wallet/internal/models"
+	"ewallet-wallet/internal/services"
+	"ewallet-wallet/lifecycle"
+	"flag"
+	"fmt"
+	"log"
+)
+
+// newPayoutProvider picks the provider named by PAYOUT_PROVIDER, only the
+// simulated provider exists for now.
+func newPayoutProvider(cfg config.Withdrawal) i_external.PayoutProvider {
+	switch cfg.PayoutProvider {
+	case constants.PayoutProviderSimulated:
+		return newSimulatedPayoutProvider(cfg)
+	default:
+		log.Fatalf("unknown PAYOUT_PROVIDER %s", cfg.PayoutProvider)
+		return nil
+	}
+}
+
+func newSimulatedPayoutProvider(cfg config.Withdrawal) *external.SimulatedPayoutProvider {
+	return &external.SimulatedPayoutProvider{
+		Secret: cfg.PayoutProviderSecret,
+	}
+}
+
+// StartWithdrawalWorker submits the withdrawals the provider never accepted,
+// WITHDRAWAL_RETRY_INTERVAL=0 turns the worker off.
+func StartWithdrawalWorker(lc *lifecycle.Manager, cfg config.Withdrawal, svc *services.WithdrawalService) {
+	if cfg.RetryInterval <= 0 {
+		return
+	}
+
+	lc.Go("withdrawal worker", func(ctx context.Context) {
+		svc.RunWorker(ctx, cfg.RetryInterval)
+	})
+}
+
+// RunPayoutCallback prints a signed simulated payout callback, so a
+// withdrawal can be finished locally with curl.
+func RunPayoutCallback(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("payout-callback", flag.ExitOnError)
+	reference := fs.String("reference", "", "withdrawal payout reference")
+	status := fs.String("status", constants.WithdrawalStatusSucceeded, "succeeded or failed")
+	reason := fs.String("reason", "", "failure reason")
+	fs.Parse(args)
+
+	if *reference == "" {
+		log.Fatal("-reference is required")
+	}
+
+	provider := newSimulatedPayoutProvider(cfg.Withdrawal)
+	payload, signature, err := provider.NewSimulatedCallback(models.PayoutCallback{
+		Reference:     *reference,
+		Status:        *status,
+		FailureReason: *reason,
+	})
+	if err != nil {
+		log.Fatal("failed to build callback: ", err)
+	}
+
+	fmt.Printf("curl -X POST http://localhost:%d/wallet/v1/withdrawals/callback -H '%s: %s' -d '%s'\n",
+		cfg.App.Port, constants.HeaderCallbackSignature, signature, payload)
+}
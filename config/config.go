@@ -0,0 +1,173 @@
+package config
+
+import "time"
+
+// Config is every setting of the service. Each field is read from the
+// environment variable named by its env tag, the yaml tags give its place in
+// the YAML file and the default tag its value when no source sets it. Fields
+// tagged secret are redacted when the config is printed.
+type Config struct {
+	App              App              `yaml:"app"`
+	DB               DB               `yaml:"db"`
+	Admin            Admin            `yaml:"admin"`
+	Auth             Auth             `yaml:"auth"`
+	UMS              UMS              `yaml:"ums"`
+	TokenCache       TokenCache       `yaml:"token_cache"`
+	PIN              PIN              `yaml:"pin"`
+	Risk             Risk             `yaml:"risk"`
+	ScheduledPayment ScheduledPayment `yaml:"scheduled_payment"`
+	PaymentRequest   PaymentRequest   `yaml:"payment_request"`
+	TopUp            TopUp            `yaml:"topup"`
+	Withdrawal       Withdrawal       `yaml:"withdrawal"`
+	Promo            Promo            `yaml:"promo"`
+	Reconciliation   Reconciliation   `yaml:"reconciliation"`
+	Settlement       Settlement       `yaml:"settlement"`
+	Health           Health           `yaml:"health"`
+	Audit            Audit            `yaml:"audit"`
+}
+
+type App struct {
+	Name     string `yaml:"name" env:"APP_NAME"`
+	Port     int    `yaml:"port" env:"PORT" default:"8080"`
+	GRPCPort int    `yaml:"grpc_port" env:"GRPC_PORT" default:"7000"`
+	// GRPCEnabled serves gRPC on GRPCPort next to HTTP
+	GRPCEnabled bool `yaml:"grpc_enabled" env:"GRPC_ENABLED" default:"false"`
+	// ShutdownTimeout bounds draining the in flight requests and stopping
+	// the workers after SIGTERM or SIGINT
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	// Secret signs the user tokens checked in the local_hs256 auth mode
+	Secret         string `yaml:"secret" env:"APP_SECRET" secret:"true"`
+	InternalAPIKey string `yaml:"internal_api_key" env:"INTERNAL_API_KEY" secret:"true"`
+}
+
+type DB struct {
+	// Driver is mysql, postgres or sqlite, postgres usually listens on port
+	// 5432. For sqlite Name is the path of the database file and the server
+	// settings are not used.
+	Driver   string `yaml:"driver" env:"DB_DRIVER" default:"mysql"`
+	Host     string `yaml:"host" env:"DB_HOST" default:"127.0.0.1"`
+	Port     int    `yaml:"port" env:"DB_PORT" default:"3306"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	// SSLMode is the postgres sslmode, mysql ignores it
+	SSLMode string `yaml:"ssl_mode" env:"DB_SSL_MODE" default:"prefer"`
+	// Migrate applies the pending migrations when the server starts
+	Migrate     bool          `yaml:"migrate" env:"DB_MIGRATE" default:"true"`
+	LockTimeout time.Duration `yaml:"lock_timeout" env:"DB_MIGRATION_LOCK_TIMEOUT" default:"1m"`
+}
+
+type Admin struct {
+	Secret            string `yaml:"secret" env:"ADMIN_SECRET" secret:"true"`
+	BootstrapUsername string `yaml:"bootstrap_username" env:"ADMIN_BOOTSTRAP_USERNAME"`
+	BootstrapPassword string `yaml:"bootstrap_password" env:"ADMIN_BOOTSTRAP_PASSWORD" secret:"true"`
+}
+
+type Auth struct {
+	Mode                string        `yaml:"mode" env:"AUTH_MODE" default:"remote"`
+	Issuer              string        `yaml:"issuer" env:"AUTH_ISSUER"`
+	Audience            string        `yaml:"audience" env:"AUTH_AUDIENCE"`
+	ClockSkew           time.Duration `yaml:"clock_skew" env:"AUTH_CLOCK_SKEW" default:"30s"`
+	JWKSSource          string        `yaml:"jwks_source" env:"AUTH_JWKS_SOURCE"`
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval" env:"AUTH_JWKS_REFRESH_INTERVAL" default:"10m"`
+	JWKSFetchTimeout    time.Duration `yaml:"jwks_fetch_timeout" env:"AUTH_JWKS_FETCH_TIMEOUT" default:"5s"`
+	// DefaultScopes are granted to user tokens without a scope claim and may
+	// only give read access
+	DefaultScopes []string `yaml:"default_scopes" env:"AUTH_DEFAULT_SCOPES"`
+	// LegacyFullAccess gives user tokens without a scope claim the read, debit
+	// and credit access every user had before scopes existed
+	LegacyFullAccess bool `yaml:"legacy_full_access" env:"AUTH_LEGACY_FULL_ACCESS" default:"false"`
+	// LegacyBalanceSignature still accepts the old signature over an empty
+	// payload on the client balance route, to be turned off once every
+	// partner signs the method, path, query and timestamp
+	LegacyBalanceSignature bool `yaml:"legacy_balance_signature" env:"AUTH_LEGACY_BALANCE_SIGNATURE" default:"true"`
+}
+
+type UMS struct {
+	Address          string        `yaml:"address" env:"UMS_ADDRESS" default:"localhost:7000"`
+	TLS              bool          `yaml:"tls" env:"UMS_TLS" default:"false"`
+	CAFile           string        `yaml:"ca_file" env:"UMS_CA_FILE"`
+	CertFile         string        `yaml:"cert_file" env:"UMS_CERT_FILE"`
+	KeyFile          string        `yaml:"key_file" env:"UMS_KEY_FILE"`
+	ServerName       string        `yaml:"server_name" env:"UMS_SERVER_NAME"`
+	Timeout          time.Duration `yaml:"timeout" env:"UMS_TIMEOUT" default:"2s"`
+	MaxRetries       int           `yaml:"max_retries" env:"UMS_MAX_RETRIES" default:"2"`
+	RetryBackoff     time.Duration `yaml:"retry_backoff" env:"UMS_RETRY_BACKOFF" default:"100ms"`
+	BreakerThreshold int           `yaml:"breaker_threshold" env:"UMS_BREAKER_THRESHOLD" default:"5"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" env:"UMS_BREAKER_COOLDOWN" default:"30s"`
+}
+
+type TokenCache struct {
+	// Size of 0 turns caching off
+	Size        int           `yaml:"size" env:"TOKEN_CACHE_SIZE" default:"10000"`
+	TTL         time.Duration `yaml:"ttl" env:"TOKEN_CACHE_TTL" default:"1m"`
+	NegativeTTL time.Duration `yaml:"negative_ttl" env:"TOKEN_CACHE_NEGATIVE_TTL" default:"10s"`
+}
+
+type PIN struct {
+	Threshold    float64       `yaml:"threshold" env:"PIN_THRESHOLD" default:"1000000"`
+	MaxAttempts  int           `yaml:"max_attempts" env:"PIN_MAX_ATTEMPTS" default:"5"`
+	LockDuration time.Duration `yaml:"lock_duration" env:"PIN_LOCK_DURATION" default:"30m"`
+	OTPTTL       time.Duration `yaml:"otp_ttl" env:"PIN_OTP_TTL" default:"10m"`
+	OTPSender    string        `yaml:"otp_sender" env:"OTP_SENDER" default:"log"`
+	StepUpSecret string        `yaml:"step_up_secret" env:"STEP_UP_SECRET" secret:"true"`
+	StepUpTTL    time.Duration `yaml:"step_up_ttl" env:"STEP_UP_TTL" default:"5m"`
+}
+
+type Risk struct {
+	// RulesFile empty allows every movement
+	RulesFile string `yaml:"rules_file" env:"RISK_RULES_FILE"`
+}
+
+// the intervals of the background workers below turn the worker off at 0
+
+type ScheduledPayment struct {
+	Interval      time.Duration `yaml:"interval" env:"SCHEDULED_PAYMENT_INTERVAL" default:"1m"`
+	RetryInterval time.Duration `yaml:"retry_interval" env:"SCHEDULED_PAYMENT_RETRY_INTERVAL" default:"1h"`
+	MaxRetries    int           `yaml:"max_retries" env:"SCHEDULED_PAYMENT_MAX_RETRIES" default:"3"`
+}
+
+type PaymentRequest struct {
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"PAYMENT_REQUEST_SWEEP_INTERVAL" default:"5m"`
+}
+
+type TopUp struct {
+	Gateway        string        `yaml:"gateway" env:"TOPUP_GATEWAY" default:"fake"`
+	GatewaySecret  string        `yaml:"gateway_secret" env:"TOPUP_GATEWAY_SECRET" secret:"true"`
+	GatewayBaseURL string        `yaml:"gateway_base_url" env:"TOPUP_GATEWAY_BASE_URL" default:"http://localhost:8080/fake-gateway"`
+	SweepInterval  time.Duration `yaml:"sweep_interval" env:"TOPUP_SWEEP_INTERVAL" default:"5m"`
+}
+
+type Withdrawal struct {
+	PayoutProvider       string        `yaml:"payout_provider" env:"PAYOUT_PROVIDER" default:"simulated"`
+	PayoutProviderSecret string        `yaml:"payout_provider_secret" env:"PAYOUT_PROVIDER_SECRET" secret:"true"`
+	RetryInterval        time.Duration `yaml:"retry_interval" env:"WITHDRAWAL_RETRY_INTERVAL" default:"1m"`
+}
+
+type Promo struct {
+	ExpiryInterval time.Duration `yaml:"expiry_interval" env:"PROMO_EXPIRY_INTERVAL" default:"15m"`
+}
+
+type Reconciliation struct {
+	// Schedule is the HH:MM of the nightly run, empty turns it off
+	Schedule  string   `yaml:"schedule" env:"RECONCILIATION_SCHEDULE"`
+	Formats   []string `yaml:"formats" env:"RECONCILIATION_FORMATS" default:"json,csv"`
+	ReportDir string   `yaml:"report_dir" env:"RECONCILIATION_REPORT_DIR" default:"reports"`
+	Suspense  bool     `yaml:"suspense" env:"RECONCILIATION_SUSPENSE" default:"false"`
+}
+
+type Settlement struct {
+	Timezone  string `yaml:"timezone" env:"SETTLEMENT_TIMEZONE" default:"Local"`
+	ReportDir string `yaml:"report_dir" env:"SETTLEMENT_REPORT_DIR" default:"reports"`
+}
+
+type Health struct {
+	// Timeout bounds every dependency checked by /readyz and /startupz
+	Timeout time.Duration `yaml:"timeout" env:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+}
+
+type Audit struct {
+	// RetryInterval is how often audit entries that failed to be written
+	// after their change committed are tried again
+	RetryInterval time.Duration `yaml:"retry_interval" env:"AUDIT_RETRY_INTERVAL" default:"30s"`
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var requiredEnv = []string{
+	"DB_USER=wallet",
+	"DB_NAME=ewallet_wallet",
+	"STEP_UP_SECRET=step-up-secret",
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0o600)
+	assert.NoError(t, err)
+	return path
+}
+
+func TestLoadFrom_Defaults(t *testing.T) {
+	cfg, err := LoadFrom(Sources{
+		DotEnvFile: filepath.Join(t.TempDir(), ".env"),
+		Environ:    requiredEnv,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 8080, cfg.App.Port)
+	assert.Equal(t, 7000, cfg.App.GRPCPort)
+	assert.False(t, cfg.App.GRPCEnabled)
+	assert.Equal(t, 30*time.Second, cfg.App.ShutdownTimeout)
+	assert.Equal(t, "mysql", cfg.DB.Driver)
+	assert.Equal(t, "127.0.0.1", cfg.DB.Host)
+	assert.Equal(t, 3306, cfg.DB.Port)
+	assert.Equal(t, "remote", cfg.Auth.Mode)
+	assert.Empty(t, cfg.Auth.DefaultScopes)
+	assert.False(t, cfg.Auth.LegacyFullAccess)
+	assert.True(t, cfg.Auth.LegacyBalanceSignature)
+	assert.Equal(t, 2*time.Second, cfg.UMS.Timeout)
+	assert.Equal(t, 1000000.0, cfg.PIN.Threshold)
+	assert.Equal(t, []string{"json", "csv"}, cfg.Reconciliation.Formats)
+	assert.False(t, cfg.Reconciliation.Suspense)
+}
+
+func TestLoadFrom_SQLite(t *testing.T) {
+	// the file needs no server settings
+	cfg, err := LoadFrom(Sources{
+		Environ: []string{"DB_DRIVER=sqlite", "DB_NAME=wallet.db", "DB_HOST=", "STEP_UP_SECRET=step-up-secret"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "sqlite", cfg.DB.Driver)
+	assert.Equal(t, "wallet.db", cfg.DB.Name)
+}
+
+func TestLoadFrom_Precedence(t *testing.T) {
+	yamlFile := writeFile(t, "config.yaml", `
+app:
+  name: from-yaml
+  port: 9000
+  grpc_port: 9001
+db:
+  host: yaml-host
+  port: 3307
+reconciliation:
+  formats: [csv]
+`)
+	dotEnv := writeFile(t, ".env", "CONFIG_FILE="+yamlFile+"\nPORT=9100\nDB_HOST=dotenv-host\nAPP_NAME=\n")
+
+	cfg, err := LoadFrom(Sources{
+		DotEnvFile: dotEnv,
+		Environ:    append([]string{"DB_HOST=env-host", "GRPC_PORT=", "RECONCILIATION_SUSPENSE=true"}, requiredEnv...),
+	})
+	assert.NoError(t, err)
+
+	// the yaml file wins over the defaults
+	assert.Equal(t, 3307, cfg.DB.Port)
+	assert.Equal(t, []string{"csv"}, cfg.Reconciliation.Formats)
+	// .env wins over the yaml file, empty values do not
+	assert.Equal(t, 9100, cfg.App.Port)
+	assert.Equal(t, "from-yaml", cfg.App.Name)
+	// the environment wins over .env, empty values do not
+	assert.Equal(t, "env-host", cfg.DB.Host)
+	assert.Equal(t, 9001, cfg.App.GRPCPort)
+	assert.True(t, cfg.Reconciliation.Suspense)
+}
+
+func TestLoadFrom_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		environ     []string
+		wantErrs    []string
+		notWantErrs []string
+	}{
+		{
+			name: "error every missing setting reported",
+			environ: []string{
+				"AUTH_MODE=local_hs256",
+				"DB_PORT=70000",
+			},
+			wantErrs: []string{
+				"DB_USER is required",
+				"DB_NAME is required",
+				"DB_PORT must be between 1 and 65535",
+				"STEP_UP_SECRET is required",
+				"APP_SECRET is required for AUTH_MODE local_hs256",
+			},
+		},
+		{
+			name: "error invalid values",
+			environ: append([]string{
+				"PORT=http",
+				"UMS_TIMEOUT=2",
+				"UMS_TLS=maybe",
+				"SHUTDOWN_TIMEOUT=soon",
+			}, requiredEnv...),
+			wantErrs: []string{
+				`PORT: invalid number "http"`,
+				`UMS_TIMEOUT: invalid duration "2"`,
+				`UMS_TLS: invalid boolean "maybe"`,
+				`SHUTDOWN_TIMEOUT: invalid duration "soon"`,
+			},
+		},
+		{
+			name: "error invalid values reported with the missing settings",
+			environ: []string{
+				"PORT=http",
+				"DB_PORT=70000",
+			},
+			wantErrs: []string{
+				`PORT: invalid number "http"`,
+				"DB_PORT must be between 1 and 65535",
+				"DB_USER is required",
+				"STEP_UP_SECRET is required",
+			},
+			notWantErrs: []string{
+				"\nPORT must be between 1 and 65535",
+			},
+		},
+		{
+			name: "error unknown options",
+			environ: append([]string{
+				"AUTH_MODE=basic",
+				"DB_DRIVER=sqlserver",
+				"TOPUP_GATEWAY=stripe",
+				"RECONCILIATION_FORMATS=json,xml",
+				"RECONCILIATION_SCHEDULE=2am",
+				"SETTLEMENT_TIMEZONE=Mars/Olympus",
+			}, requiredEnv...),
+			wantErrs: []string{
+				"AUTH_MODE must be one of remote, local_hs256, local_jwks",
+				"DB_DRIVER must be one of mysql, postgres, sqlite",
+				"TOPUP_GATEWAY must be fake",
+				"RECONCILIATION_FORMATS has unknown format xml",
+				"RECONCILIATION_SCHEDULE must be HH:MM",
+				"SETTLEMENT_TIMEZONE Mars/Olympus is not a known time zone",
+			},
+		},
+		{
+			name: "error default scopes beyond read",
+			environ: append([]string{
+				"AUTH_DEFAULT_SCOPES=wallet:read wallet:debit",
+			}, requiredEnv...),
+			wantErrs: []string{
+				"AUTH_DEFAULT_SCOPES may only grant wallet:read",
+			},
+		},
+		{
+			name:    "error unknown yaml key",
+			yaml:    "db:\n  hostname: db\napp:\n  port: 8080\n",
+			environ: requiredEnv,
+			wantErrs: []string{
+				"unknown key db.hostname",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Sources{Environ: tt.environ}
+			if tt.yaml != "" {
+				src.YAMLFile = writeFile(t, "config.yaml", tt.yaml)
+			}
+
+			got, err := LoadFrom(src)
+			assert.Nil(t, got)
+			assert.Error(t, err)
+			for _, want := range tt.wantErrs {
+				assert.Contains(t, err.Error(), want)
+			}
+			for _, notWant := range tt.notWantErrs {
+				assert.NotContains(t, err.Error(), notWant)
+			}
+		})
+	}
+}
+
+func TestConfig_String(t *testing.T) {
+	cfg, err := LoadFrom(Sources{
+		Environ: append([]string{"DB_PASSWORD=db-password", "ADMIN_SECRET=admin-secret"}, requiredEnv...),
+	})
+	assert.NoError(t, err)
+
+	got := cfg.String()
+	assert.NotContains(t, got, "db-password")
+	assert.NotContains(t, got, "admin-secret")
+	assert.NotContains(t, got, "step-up-secret")
+	assert.Contains(t, got, "DB_PASSWORD=******\n")
+	assert.Contains(t, got, "STEP_UP_SECRET=******\n")
+	// unset secrets stay empty so a missing one is visible
+	assert.Contains(t, got, "APP_SECRET=\n")
+	assert.Contains(t, got, "DB_USER=wallet\n")
+	assert.Contains(t, got, "UMS_TIMEOUT=2s\n")
+	assert.Contains(t, got, "RECONCILIATION_FORMATS=json,csv\n")
+	assert.True(t, strings.HasPrefix(got, "APP_NAME="))
+}
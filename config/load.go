@@ -0,0 +1,372 @@
+package config
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "******"
+
+// Sources are where the config is read from. Each source overrides the one
+// before it: the defaults, the YAML file, the .env file and last the
+// process environment. Empty values count as not set. The files are
+// optional, YAMLFile empty falls back to CONFIG_FILE of the other sources.
+type Sources struct {
+	YAMLFile   string
+	DotEnvFile string
+	Environ    []string
+}
+
+// Load reads the config of the running process, .env from the working
+// directory and the environment.
+func Load() (*Config, error) {
+	return LoadFrom(Sources{
+		DotEnvFile: ".env",
+		Environ:    os.Environ(),
+	})
+}
+
+// LoadFrom reads and validates the config, every problem found is reported
+// in the one error returned.
+func LoadFrom(src Sources) (*Config, error) {
+	var errs []error
+
+	env := map[string]string{}
+	for _, kv := range src.Environ {
+		key, value, _ := strings.Cut(kv, "=")
+		env[key] = value
+	}
+
+	dotEnv := map[string]string{}
+	if src.DotEnvFile != "" {
+		var err error
+		dotEnv, err = godotenv.Read(src.DotEnvFile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s: %w", src.DotEnvFile, err)
+		}
+	}
+
+	yamlFile := src.YAMLFile
+	if yamlFile == "" {
+		yamlFile = firstSet("CONFIG_FILE", env, dotEnv)
+	}
+
+	values := map[string]string{}
+	if yamlFile != "" {
+		yamlValues, err := readYAML(yamlFile)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range yamlValues {
+			values[key] = value
+		}
+	}
+	for _, source := range []map[string]string{dotEnv, env} {
+		for key, value := range source {
+			if value != "" {
+				values[key] = value
+			}
+		}
+	}
+
+	cfg := &Config{}
+	unparsed := map[string]bool{}
+	for _, f := range fields(cfg) {
+		raw, ok := values[f.env]
+		if !ok {
+			raw = f.tag.Get("default")
+		}
+		if err := setValue(f.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", f.env, err))
+			unparsed[f.env] = true
+		}
+	}
+
+	// a setting that did not parse is already reported, its zero value is
+	// not checked again
+	for _, err := range cfg.validate() {
+		key, _, _ := strings.Cut(err.Error(), " ")
+		if !unparsed[key] {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
+
+	return cfg, nil
+}
+
+func firstSet(key string, sources ...map[string]string) string {
+	for _, source := range sources {
+		if source[key] != "" {
+			return source[key]
+		}
+	}
+	return ""
+}
+
+type field struct {
+	env    string
+	yaml   string
+	tag    reflect.StructTag
+	value  reflect.Value
+	secret bool
+}
+
+// fields lists the settings in declaration order, section by section.
+func fields(cfg *Config) []field {
+	var resp []field
+
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Field(i)
+		sectionName := root.Type().Field(i).Tag.Get("yaml")
+		for j := 0; j < section.NumField(); j++ {
+			sf := section.Type().Field(j)
+			resp = append(resp, field{
+				env:    sf.Tag.Get("env"),
+				yaml:   sectionName + "." + sf.Tag.Get("yaml"),
+				tag:    sf.Tag,
+				value:  section.Field(j),
+				secret: sf.Tag.Get("secret") == "true",
+			})
+		}
+	}
+
+	return resp
+}
+
+// readYAML flattens the YAML file to the environment variable names of the
+// settings, a key the config does not know is an error.
+func readYAML(path string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	doc := map[string]map[string]interface{}{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	envByYAML := map[string]string{}
+	for _, f := range fields(&Config{}) {
+		envByYAML[f.yaml] = f.env
+	}
+
+	var errs []error
+	resp := map[string]string{}
+	for section, settings := range doc {
+		for key, value := range settings {
+			env, ok := envByYAML[section+"."+key]
+			if !ok {
+				errs = append(errs, fmt.Errorf("unknown key %s.%s in %s", section, key, path))
+				continue
+			}
+
+			if list, ok := value.([]interface{}); ok {
+				items := make([]string, 0, len(list))
+				for _, item := range list {
+					items = append(items, fmt.Sprint(item))
+				}
+				resp[env] = strings.Join(items, ",")
+				continue
+			}
+			if value != nil {
+				resp[env] = fmt.Sprint(value)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return resp, nil
+}
+
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		if raw == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		if raw == "" {
+			v.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		// lists are comma or space separated
+		items := strings.FieldsFunc(raw, func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func (c *Config) validate() []error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.App.Port), "PORT must be between 1 and 65535")
+	check(validPort(c.App.GRPCPort), "GRPC_PORT must be between 1 and 65535")
+	check(c.App.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+
+	switch c.DB.Driver {
+	case constants.DBDriverMySQL, constants.DBDriverPostgres:
+		check(c.DB.Host != "", "DB_HOST is required")
+		check(validPort(c.DB.Port), "DB_PORT must be between 1 and 65535")
+		check(c.DB.User != "", "DB_USER is required")
+	case constants.DBDriverSQLite:
+	default:
+		check(false, "DB_DRIVER must be one of %s, %s, %s", constants.DBDriverMySQL, constants.DBDriverPostgres, constants.DBDriverSQLite)
+	}
+	check(c.DB.Name != "", "DB_NAME is required")
+	check(c.DB.LockTimeout >= time.Second, "DB_MIGRATION_LOCK_TIMEOUT must be at least 1s")
+
+	switch c.Auth.Mode {
+	case constants.AuthModeRemote:
+		check(c.UMS.Address != "", "UMS_ADDRESS is required for AUTH_MODE %s", c.Auth.Mode)
+	case constants.AuthModeLocalHS256:
+		check(c.App.Secret != "", "APP_SECRET is required for AUTH_MODE %s", c.Auth.Mode)
+	case constants.AuthModeLocalJWKS:
+		check(c.Auth.JWKSSource != "", "AUTH_JWKS_SOURCE is required for AUTH_MODE %s", c.Auth.Mode)
+	default:
+		check(false, "AUTH_MODE must be one of %s, %s, %s", constants.AuthModeRemote, constants.AuthModeLocalHS256, constants.AuthModeLocalJWKS)
+	}
+	check(c.Auth.ClockSkew >= 0, "AUTH_CLOCK_SKEW must not be negative")
+	check(c.Auth.JWKSRefreshInterval > 0, "AUTH_JWKS_REFRESH_INTERVAL must be positive")
+	check(c.Auth.JWKSFetchTimeout > 0, "AUTH_JWKS_FETCH_TIMEOUT must be positive")
+	for _, scope := range c.Auth.DefaultScopes {
+		check(scope == constants.ScopeWalletRead, "AUTH_DEFAULT_SCOPES may only grant %s, set AUTH_LEGACY_FULL_ACCESS to give tokens without scopes full access", constants.ScopeWalletRead)
+	}
+
+	check(c.UMS.Timeout > 0, "UMS_TIMEOUT must be positive")
+	check(c.UMS.MaxRetries >= 0, "UMS_MAX_RETRIES must not be negative")
+	check(c.UMS.RetryBackoff > 0, "UMS_RETRY_BACKOFF must be positive")
+	check(c.UMS.BreakerThreshold > 0, "UMS_BREAKER_THRESHOLD must be positive")
+	check(c.UMS.BreakerCooldown > 0, "UMS_BREAKER_COOLDOWN must be positive")
+	check(c.TokenCache.Size >= 0, "TOKEN_CACHE_SIZE must not be negative")
+	check(c.TokenCache.TTL > 0, "TOKEN_CACHE_TTL must be positive")
+	check(c.TokenCache.NegativeTTL > 0, "TOKEN_CACHE_NEGATIVE_TTL must be positive")
+
+	check(c.PIN.StepUpSecret != "", "STEP_UP_SECRET is required")
+	check(c.PIN.Threshold >= 0, "PIN_THRESHOLD must not be negative")
+	check(c.PIN.MaxAttempts > 0, "PIN_MAX_ATTEMPTS must be positive")
+	check(c.PIN.LockDuration > 0, "PIN_LOCK_DURATION must be positive")
+	check(c.PIN.OTPTTL > 0, "PIN_OTP_TTL must be positive")
+	check(c.PIN.StepUpTTL > 0, "STEP_UP_TTL must be positive")
+	check(c.PIN.OTPSender == constants.OTPSenderLog, "OTP_SENDER must be %s", constants.OTPSenderLog)
+
+	check(c.ScheduledPayment.RetryInterval >= 0, "SCHEDULED_PAYMENT_RETRY_INTERVAL must not be negative")
+	check(c.ScheduledPayment.MaxRetries >= 0, "SCHEDULED_PAYMENT_MAX_RETRIES must not be negative")
+	check(c.TopUp.Gateway == constants.GatewayFake, "TOPUP_GATEWAY must be %s", constants.GatewayFake)
+	check(c.Withdrawal.PayoutProvider == constants.PayoutProviderSimulated, "PAYOUT_PROVIDER must be %s", constants.PayoutProviderSimulated)
+
+	if c.Reconciliation.Schedule != "" {
+		_, err := time.Parse("15:04", c.Reconciliation.Schedule)
+		check(err == nil, "RECONCILIATION_SCHEDULE must be HH:MM")
+	}
+	for _, format := range c.Reconciliation.Formats {
+		check(format == constants.ReportFormatJSON || format == constants.ReportFormatCSV, "RECONCILIATION_FORMATS has unknown format %s", format)
+	}
+
+	_, err := time.LoadLocation(c.Settlement.Timezone)
+	check(err == nil, "SETTLEMENT_TIMEZONE %s is not a known time zone", c.Settlement.Timezone)
+
+	check(c.Health.Timeout > 0, "HEALTH_CHECK_TIMEOUT must be positive")
+	check(c.Audit.RetryInterval > 0, "AUDIT_RETRY_INTERVAL must be positive")
+
+	return errs
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+// String prints every setting as KEY=value with the secrets redacted, safe
+// for the logs.
+func (c Config) String() string {
+	var b strings.Builder
+	for _, f := range fields(&c) {
+		b.WriteString(f.env)
+		b.WriteString("=")
+		b.WriteString(displayValue(f))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func displayValue(f field) string {
+	if f.secret {
+		if f.value.String() == "" {
+			return ""
+		}
+		return redacted
+	}
+
+	switch v := f.value.Interface().(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case time.Duration:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 const (
 	SuccessMessage      = "success"
 	ErrFailedBadRequest = "Data tidak sesuai"
@@ -9,3 +11,325 @@ const (
 var MappingClient = map[string]string{
 	"fastcampus_ecommerce": "ini_secret_key",
 }
+
+// SignatureMaxAge is how far the Timestamp of a signed client request may be
+// from now, either way.
+const SignatureMaxAge = 5 * time.Minute
+
+// LegacySignatureRoute still takes the GET signature over an empty payload
+// while AUTH_LEGACY_BALANCE_SIGNATURE is on, partners calling it before the
+// GET signature covered the request are moving over.
+const LegacySignatureRoute = "/wallet/v1/ex/:wallet_id/balance"
+
+const (
+	WalletStatusActive    = "active"
+	WalletStatusFrozen    = "frozen"
+	WalletStatusSuspended = "suspended"
+	WalletStatusClosed    = "closed"
+
+	WalletTypeUser   = "user"
+	WalletTypeSystem = "system"
+)
+
+const (
+	WalletActionFreeze    = "freeze"
+	WalletActionUnfreeze  = "unfreeze"
+	WalletActionSuspend   = "suspend"
+	WalletActionUnsuspend = "unsuspend"
+	WalletActionClose     = "close"
+)
+
+var MappingWalletStatusReasonCode = map[string]string{
+	"FRAUD_SUSPECTED":     "Suspected fraudulent activity",
+	"ACCOUNT_COMPROMISED": "Account credentials compromised",
+	"COMPLIANCE_REVIEW":   "Compliance or regulatory review",
+	"CUSTOMER_REQUEST":    "Requested by the customer",
+	"DORMANT":             "Dormant account",
+	"REVIEW_CLEARED":      "Review completed, no issue found",
+}
+
+const (
+	OperatorRoleViewer  = "viewer"
+	OperatorRoleSupport = "support"
+	OperatorRoleFinance = "finance"
+	OperatorRoleAdmin   = "admin"
+)
+
+const (
+	PermissionWalletRead        = "wallet:read"
+	PermissionWalletStatus      = "wallet:status"
+	PermissionWalletClose       = "wallet:close"
+	PermissionLinkResendOTP     = "link:resend_otp"
+	PermissionAdjustmentCreate  = "adjustment:create"
+	PermissionAdjustmentApprove = "adjustment:approve"
+	PermissionOperatorManage    = "operator:manage"
+	PermissionAuditRead         = "audit:read"
+	PermissionFeeManage         = "fee:manage"
+	PermissionPromoManage       = "promo:manage"
+	PermissionRiskReview        = "risk:review"
+)
+
+var MappingRolePermissions = map[string][]string{
+	OperatorRoleViewer: {
+		PermissionWalletRead,
+	},
+	OperatorRoleSupport: {
+		PermissionWalletRead,
+		PermissionWalletStatus,
+		PermissionLinkResendOTP,
+	},
+	OperatorRoleFinance: {
+		PermissionWalletRead,
+		PermissionAdjustmentCreate,
+		PermissionAdjustmentApprove,
+		PermissionFeeManage,
+		PermissionPromoManage,
+		PermissionRiskReview,
+	},
+	OperatorRoleAdmin: {
+		PermissionWalletRead,
+		PermissionWalletStatus,
+		PermissionWalletClose,
+		PermissionLinkResendOTP,
+		PermissionAdjustmentCreate,
+		PermissionAdjustmentApprove,
+		PermissionOperatorManage,
+		PermissionAuditRead,
+		PermissionFeeManage,
+		PermissionPromoManage,
+		PermissionRiskReview,
+	},
+}
+
+const (
+	AuditActorOperator = "operator"
+	AuditActorUser     = "user"
+	AuditActorClient   = "client"
+	AuditActorSystem   = "system"
+
+	AuditTargetWallet     = "wallet"
+	AuditTargetLink       = "wallet_link"
+	AuditTargetOperator   = "operator"
+	AuditTargetAdjustment = "balance_adjustment"
+)
+
+const (
+	TransactionTypeCredit     = "CREDIT"
+	TransactionTypeDebit      = "DEBIT"
+	TransactionTypeAdjustment = "ADJUSTMENT"
+	TransactionTypeRefund     = "REFUND"
+	TransactionTypeFee        = "FEE"
+)
+
+const (
+	AdjustmentStatusPending  = "pending"
+	AdjustmentStatusApproved = "approved"
+	AdjustmentStatusRejected = "rejected"
+	AdjustmentStatusExpired  = "expired"
+	AdjustmentStatusFailed   = "failed"
+
+	DefaultAdjustmentExpiryHours = 24
+)
+
+var MappingAdjustmentReasonCode = map[string]string{
+	"DUPLICATE_CHARGE":   "Customer was charged twice",
+	"FAILED_TRANSACTION": "Transaction failed after the balance was moved",
+	"SYSTEM_ERROR":       "Balance corrupted by a system error",
+	"CHARGEBACK":         "Chargeback from a partner",
+	"GOODWILL":           "Goodwill credit approved by finance",
+}
+
+const (
+	ReconciliationStatusRunning   = "running"
+	ReconciliationStatusCompleted = "completed"
+	ReconciliationStatusFailed    = "failed"
+
+	// balances are stored with two decimals, anything below a cent is rounding
+	ReconciliationTolerance = 0.01
+
+	SuspenseStatusOpen     = "open"
+	SuspenseStatusResolved = "resolved"
+
+	ReportFormatJSON = "json"
+	ReportFormatCSV  = "csv"
+	ReportFormatPDF  = "pdf"
+)
+
+const (
+	ScheduleFrequencyDaily   = "daily"
+	ScheduleFrequencyWeekly  = "weekly"
+	ScheduleFrequencyMonthly = "monthly"
+
+	ScheduleStatusActive      = "active"
+	ScheduleStatusPaused      = "paused"
+	ScheduleStatusCancelled   = "cancelled"
+	ScheduleStatusCompleted   = "completed"
+	ScheduleStatusUnderReview = "under_review"
+
+	ScheduleActionPause  = "pause"
+	ScheduleActionResume = "resume"
+	ScheduleActionCancel = "cancel"
+
+	AuditTargetSchedule = "scheduled_payment"
+
+	DefaultScheduleMaxRetries = 3
+)
+
+const (
+	PaymentRequestStatusPending   = "pending"
+	PaymentRequestStatusPaid      = "paid"
+	PaymentRequestStatusDeclined  = "declined"
+	PaymentRequestStatusExpired   = "expired"
+	PaymentRequestStatusCancelled = "cancelled"
+
+	AuditTargetPaymentRequest = "payment_request"
+
+	DefaultPaymentRequestExpiryHours = 72
+)
+
+const (
+	TopUpStatusPending = "pending"
+	TopUpStatusPaid    = "paid"
+	TopUpStatusFailed  = "failed"
+	TopUpStatusExpired = "expired"
+
+	AuditTargetTopUp = "topup_intent"
+
+	DefaultTopUpExpiryHours = 24
+
+	GatewayFake = "fake"
+
+	HeaderCallbackSignature = "X-Callback-Signature"
+	HeaderInternalKey       = "X-Internal-Key"
+)
+
+const (
+	WithdrawalStatusRequested  = "requested"
+	WithdrawalStatusProcessing = "processing"
+	WithdrawalStatusSucceeded  = "succeeded"
+	WithdrawalStatusFailed     = "failed"
+
+	AuditTargetWithdrawal  = "withdrawal"
+	AuditTargetBankAccount = "bank_account"
+
+	PayoutProviderSimulated = "simulated"
+)
+
+var MappingBankCode = map[string]string{
+	"BCA":     "Bank Central Asia",
+	"BNI":     "Bank Negara Indonesia",
+	"BRI":     "Bank Rakyat Indonesia",
+	"MANDIRI": "Bank Mandiri",
+	"PERMATA": "Bank Permata",
+	"CIMB":    "CIMB Niaga",
+}
+
+const (
+	WalletTierBasic   = "basic"
+	WalletTierPremium = "premium"
+)
+
+const (
+	FeeOperationExternalDebit = "external_debit"
+	FeeOperationWithdrawal    = "withdrawal"
+	FeeOperationP2PTransfer   = "p2p_transfer"
+
+	FeeTypeFlat       = "flat"
+	FeeTypePercentage = "percentage"
+	FeeTypeTiered     = "tiered"
+
+	AuditTargetFeeRule = "fee_rule"
+
+	// FeeRevenueUserID owns the wallet collecting every fee, no user logs in
+	// with id 0. The wallet is also a WalletTypeSystem wallet, which every
+	// request path refuses.
+	FeeRevenueUserID uint64 = 0
+)
+
+const (
+	BalanceTypeCash  = "cash"
+	BalanceTypePromo = "promo"
+
+	SpendOrderPromoFirst = "promo_first"
+	SpendOrderCashFirst  = "cash_first"
+
+	PromoGrantStatusActive  = "active"
+	PromoGrantStatusExpired = "expired"
+
+	AuditTargetCampaign   = "campaign"
+	AuditTargetPromoGrant = "promo_grant"
+
+	DefaultPromoValidityDays = 30
+)
+
+const (
+	HeaderWalletPIN   = "X-Wallet-PIN"
+	HeaderStepUpToken = "X-Step-Up-Token"
+
+	AuditTargetPIN = "wallet_pin"
+
+	OTPPurposePINReset = "pin_reset"
+	OTPSenderLog       = "log"
+)
+
+const (
+	RiskOutcomeAllow  = "allow"
+	RiskOutcomeReview = "review"
+	RiskOutcomeBlock  = "block"
+
+	RiskOperationDebit      = "debit"
+	RiskOperationTransfer   = "transfer"
+	RiskOperationExternal   = "external"
+	RiskOperationWithdrawal = "withdrawal"
+
+	RiskRuleDebitVelocity     = "debit_velocity"
+	RiskRuleNewLinkLargeDebit = "new_link_large_debit"
+	RiskRuleClientReferences  = "client_references"
+	RiskRuleUnusualAmount     = "unusual_amount"
+
+	RiskReviewStatusPending  = "pending"
+	RiskReviewStatusApproved = "approved"
+	RiskReviewStatusRejected = "rejected"
+	RiskReviewStatusFailed   = "failed"
+
+	AuditTargetRiskReview = "risk_review"
+)
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+const (
+	DBDriverMySQL    = "mysql"
+	DBDriverPostgres = "postgres"
+	// DBDriverSQLite keeps the database in a local file, for development
+	// and tests without a database server
+	DBDriverSQLite = "sqlite"
+)
+
+const (
+	// AuthModeRemote asks the UMS about every token, the local modes check
+	// the signature in process
+	AuthModeRemote     = "remote"
+	AuthModeLocalHS256 = "local_hs256"
+	AuthModeLocalJWKS  = "local_jwks"
+
+	// a token carrying scopes may only use the routes asking for one of
+	// them, admin is allowed everywhere
+	ScopeWalletRead   = "wallet:read"
+	ScopeWalletDebit  = "wallet:debit"
+	ScopeWalletCredit = "wallet:credit"
+	ScopeAdmin        = "admin"
+)
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+
+	// the dependencies checked before the service takes traffic
+	HealthComponentDatabase   = "database"
+	HealthComponentMigrations = "migrations"
+	HealthComponentUMS        = "ums"
+)
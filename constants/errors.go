@@ -0,0 +1,102 @@
+package constants
+
+import "errors"
+
+var (
+	ErrInsufficientBalance = errors.New("current balance is not enough to perform the transaction")
+	ErrWalletFrozen        = errors.New("wallet is frozen")
+	ErrWalletSuspended     = errors.New("wallet is suspended")
+	ErrWalletClosed        = errors.New("wallet is closed")
+	ErrInvalidStatusChange = errors.New("invalid wallet status change")
+	ErrSystemWallet        = errors.New("system wallet can not be used")
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid operator credentials")
+	ErrLinkNotPending     = errors.New("wallet link is not pending")
+)
+
+var (
+	ErrAdjustmentNotPending = errors.New("balance adjustment is not pending")
+	ErrAdjustmentExpired    = errors.New("balance adjustment is expired")
+	ErrSelfApproval         = errors.New("balance adjustment can not be decided by its maker")
+)
+
+var (
+	ErrInvalidSettlementRange = errors.New("invalid settlement date range")
+	ErrInvalidStatementRange  = errors.New("invalid statement date range")
+)
+
+var (
+	ErrDuplicateReference     = errors.New("reference is duplicated")
+	ErrWalletNotLinked        = errors.New("wallet is not linked to the client")
+	ErrScheduleNotFound       = errors.New("scheduled payment not found")
+	ErrInvalidScheduleChange  = errors.New("invalid scheduled payment status change")
+	ErrInvalidScheduleRequest = errors.New("invalid scheduled payment")
+)
+
+var (
+	ErrPaymentRequestNotFound   = errors.New("payment request not found")
+	ErrPaymentRequestNotPending = errors.New("payment request is not pending")
+	ErrInvalidPaymentRequest    = errors.New("invalid payment request")
+)
+
+var (
+	ErrTopUpNotFound           = errors.New("top-up not found")
+	ErrTopUpNotPending         = errors.New("top-up is not pending")
+	ErrTopUpAlreadyPaid        = errors.New("top-up is already paid")
+	ErrInvalidGatewaySignature = errors.New("invalid gateway callback signature")
+	ErrInvalidGatewayCallback  = errors.New("invalid gateway callback")
+)
+
+var (
+	ErrBankAccountNotFound    = errors.New("bank account not found")
+	ErrInvalidBankAccount     = errors.New("invalid bank account")
+	ErrWithdrawalNotFound     = errors.New("withdrawal not found")
+	ErrWithdrawalNotInFlight  = errors.New("withdrawal is not in flight")
+	ErrInvalidPayoutSignature = errors.New("invalid payout callback signature")
+	ErrInvalidPayoutCallback  = errors.New("invalid payout callback")
+)
+
+var (
+	ErrFeeRuleNotFound = errors.New("fee rule not found")
+	ErrInvalidFeeRule  = errors.New("invalid fee rule")
+)
+
+var (
+	ErrCampaignNotFound   = errors.New("campaign not found")
+	ErrInvalidCampaign    = errors.New("invalid campaign")
+	ErrPromoGrantNotFound = errors.New("promo grant not found")
+)
+
+var (
+	ErrPINRequired        = errors.New("transaction pin is required")
+	ErrPINNotSet          = errors.New("transaction pin is not set")
+	ErrPINAlreadySet      = errors.New("transaction pin is already set")
+	ErrInvalidPIN         = errors.New("invalid transaction pin")
+	ErrPINLocked          = errors.New("transaction pin is locked")
+	ErrInvalidOTP         = errors.New("invalid or expired otp")
+	ErrOTPTooSoon         = errors.New("otp was sent moments ago")
+	ErrInvalidStepUpToken = errors.New("invalid step-up token")
+)
+
+var (
+	ErrTransactionBlocked     = errors.New("transaction is blocked by risk screening")
+	ErrTransactionUnderReview = errors.New("transaction is held for review")
+	ErrRiskReviewNotFound     = errors.New("risk review not found")
+	ErrRiskReviewNotPending   = errors.New("risk review is not pending")
+	ErrInvalidRiskRule        = errors.New("invalid risk rule")
+)
+
+var (
+	ErrUMSUnavailable = errors.New("user management service is unavailable")
+	ErrInvalidToken   = errors.New("invalid token")
+
+	ErrKeySetUnavailable = errors.New("token signing keys are unavailable")
+)
+
+var (
+	ErrMigrationLocked   = errors.New("another migration is running")
+	ErrMigrationModified = errors.New("applied migration was modified")
+	ErrMigrationUnknown  = errors.New("applied migration is unknown to this build")
+)
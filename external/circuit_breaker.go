@@ -0,0 +1,88 @@
+package external
+
+import (
+	"ewallet-wallet/constants"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker stops calling a dependency after Threshold failures in a
+// row. Once Cooldown has passed a single trial call is let through, its
+// result closes the circuit again or keeps it open for another cooldown.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	now      func() time.Time
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		state:     constants.CircuitClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow tells whether a call may go out.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case constants.CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.Cooldown {
+			return false
+		}
+		b.state = constants.CircuitHalfOpen
+		return true
+	case constants.CircuitHalfOpen:
+		// the trial call is still out
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.state = constants.CircuitClosed
+}
+
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == constants.CircuitHalfOpen || b.failures >= b.Threshold {
+		b.state = constants.CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Abandon settles a call whose caller gave up before it answered, which says
+// nothing about the dependency. A trial call gives the circuit back open with
+// the cooldown already over, so the next call goes out as the trial.
+func (b *CircuitBreaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == constants.CircuitHalfOpen {
+		b.state = constants.CircuitOpen
+	}
+}
+
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
@@ -0,0 +1,307 @@
+package external
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// an unknown kid triggers a refetch at most this often, so tokens with made
+// up key ids can not hammer the key server
+const jwksUnknownKeyRefresh = 30 * time.Second
+
+type JWKSConfig struct {
+	// Source is a file path or an http(s) URL
+	Source          string
+	RefreshInterval time.Duration
+	HTTPClient      *http.Client
+}
+
+// JWKS holds the public keys the user tokens are signed with. The set is
+// fetched again every RefreshInterval and when a token names a key it does
+// not know, so keys rotated by the issuer are picked up without a restart.
+// A failed refresh keeps the keys already known. The fetch runs outside the
+// lock, one at a time, and the callers asking meanwhile wait for its result.
+type JWKS struct {
+	source          string
+	refreshInterval time.Duration
+	client          *http.Client
+	now             func() time.Time
+	flight          singleflight.Group
+
+	mu          sync.Mutex
+	keys        map[string]jwk
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	refreshing  bool
+	refreshErr  error
+}
+
+type jwk struct {
+	alg string
+	key crypto.PublicKey
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func NewJWKS(ctx context.Context, cfg JWKSConfig) (*JWKS, error) {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	j := &JWKS{
+		source:          cfg.Source,
+		refreshInterval: cfg.RefreshInterval,
+		client:          client,
+		now:             time.Now,
+	}
+
+	if err := j.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Key returns the key with the given id, an empty id is accepted when the
+// set holds a single key.
+func (j *JWKS) Key(ctx context.Context, kid string) (jwk, error) {
+	now := j.now()
+
+	j.mu.Lock()
+	due := now.Sub(j.fetchedAt) >= j.refreshInterval && now.Sub(j.attemptedAt) >= jwksUnknownKeyRefresh
+	j.mu.Unlock()
+	if due {
+		if err := j.refresh(ctx); err != nil {
+			log.Println("failed to refresh jwks, keeping the known keys: ", err)
+		}
+	}
+
+	j.mu.Lock()
+	key, ok := j.lookup(kid)
+	// a refresh already running may bring the key in, it is waited for
+	retry := !ok && (j.refreshing || now.Sub(j.attemptedAt) >= jwksUnknownKeyRefresh)
+	j.mu.Unlock()
+	if retry {
+		_ = j.refresh(ctx)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if retry {
+		key, ok = j.lookup(kid)
+	}
+	if !ok {
+		// the key may have been rotated in while the key server is down,
+		// that is not the token's fault
+		if j.refreshErr != nil {
+			return jwk{}, j.refreshErr
+		}
+		return jwk{}, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	return key, nil
+}
+
+// lookup is called with mu held.
+func (j *JWKS) lookup(kid string) (jwk, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// refresh fetches the set and swaps it in, a caller arriving while a fetch is
+// out shares its result instead of fetching again.
+func (j *JWKS) refresh(ctx context.Context) error {
+	_, err, _ := j.flight.Do("jwks", func() (interface{}, error) {
+		attemptedAt := j.now()
+		j.mu.Lock()
+		j.attemptedAt = attemptedAt
+		j.refreshing = true
+		j.mu.Unlock()
+
+		// the fetch is shared, one caller giving up must not fail the others,
+		// the http client timeout bounds it
+		keys, err := j.load(context.WithoutCancel(ctx))
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		j.refreshing = false
+		j.refreshErr = err
+		if err == nil {
+			j.keys = keys
+			j.fetchedAt = attemptedAt
+		}
+
+		return nil, err
+	})
+
+	return err
+}
+
+func (j *JWKS) load(ctx context.Context) (map[string]jwk, error) {
+	raw, err := j.fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrKeySetUnavailable, err)
+	}
+
+	keys, err := parseJWKS(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", constants.ErrKeySetUnavailable, err)
+	}
+
+	return keys, nil
+}
+
+func (j *JWKS) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create jwks request")
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch jwks")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %d fetching jwks", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// parseJWKS keeps the RSA and EC signing keys, other keys are skipped.
+func parseJWKS(raw []byte) (map[string]jwk, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, errors.Wrap(err, "failed to parse jwks")
+	}
+
+	keys := map[string]jwk{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = parseRSAKey(k)
+		case "EC":
+			key, err = parseECKey(k)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %v", k.Kid, err)
+		}
+
+		keys[k.Kid] = jwk{alg: k.Alg, key: key}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing key in jwks")
+	}
+
+	return keys, nil
+}
+
+func parseRSAKey(k jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil || len(n) == 0 {
+		return nil, fmt.Errorf("invalid modulus")
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+func parseECKey(k jsonWebKey) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x coordinate")
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y coordinate")
+	}
+
+	key := &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+	if !curve.IsOnCurve(key.X, key.Y) {
+		return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,119 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type LocalTokenConfig struct {
+	// Secret is the HS256 key, unused with a JWKS
+	Secret string
+	// Issuer and Audience are checked when set
+	Issuer    string
+	Audience  string
+	ClockSkew time.Duration
+}
+
+// LocalTokenValidator checks user tokens in process instead of asking the
+// UMS, either against a shared secret (HS256) or against the keys of a JWKS
+// (RS256, ES256). Both give the same TokenData the UMS would.
+type LocalTokenValidator struct {
+	jwks    *JWKS
+	secret  helpers.JWTConfig
+	options []jwt.ParserOption
+}
+
+func NewHS256TokenValidator(cfg LocalTokenConfig) *LocalTokenValidator {
+	return &LocalTokenValidator{
+		secret:  helpers.JWTConfig{Issuer: cfg.Issuer, Secret: cfg.Secret},
+		options: localTokenOptions(cfg, jwt.SigningMethodHS256.Alg()),
+	}
+}
+
+func NewJWKSTokenValidator(jwks *JWKS, cfg LocalTokenConfig) *LocalTokenValidator {
+	return &LocalTokenValidator{
+		jwks:    jwks,
+		options: localTokenOptions(cfg, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()),
+	}
+}
+
+func localTokenOptions(cfg LocalTokenConfig, methods ...string) []jwt.ParserOption {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.ClockSkew),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return opts
+}
+
+func (v *LocalTokenValidator) ValidateToken(ctx context.Context, token string) (models.TokenData, error) {
+	var (
+		resp  models.TokenData
+		claim *helpers.ClaimToken
+		err   error
+	)
+
+	token = strings.TrimPrefix(token, "Bearer ")
+	if v.jwks == nil {
+		claim, err = helpers.ValidateToken(ctx, v.secret, token, v.options...)
+	} else {
+		claim, err = v.validateJWKS(ctx, token)
+	}
+	if err != nil {
+		if errors.Is(err, constants.ErrKeySetUnavailable) {
+			return resp, err
+		}
+		return resp, fmt.Errorf("%w: %v", constants.ErrInvalidToken, err)
+	}
+
+	if claim.UserID == 0 {
+		return resp, fmt.Errorf("%w: user id is missing", constants.ErrInvalidToken)
+	}
+
+	resp.UserID = claim.UserID
+	resp.Username = claim.Username
+	resp.Fullname = claim.Fullname
+	resp.Email = claim.Email
+	resp.Scopes = strings.Fields(claim.Scope)
+
+	return resp, nil
+}
+
+func (v *LocalTokenValidator) validateJWKS(ctx context.Context, token string) (*helpers.ClaimToken, error) {
+	jwtToken, err := jwt.ParseWithClaims(token, &helpers.ClaimToken{}, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := v.jwks.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.alg != "" && key.alg != t.Method.Alg() {
+			return nil, fmt.Errorf("key %q is not for %s", kid, t.Method.Alg())
+		}
+		return key.key, nil
+	}, v.options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jwt: %w", err)
+	}
+
+	claimToken, ok := jwtToken.Claims.(*helpers.ClaimToken)
+	if !ok || !jwtToken.Valid {
+		return nil, fmt.Errorf("token invalid")
+	}
+
+	return claimToken, nil
+}
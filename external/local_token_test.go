@@ -0,0 +1,390 @@
+package external
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func userClaims(now time.Time, exp time.Duration, issuer string, audience string) helpers.ClaimToken {
+	claims := helpers.ClaimToken{
+		UserID:   7,
+		Username: "alice",
+		Fullname: "Alice Doe",
+		Email:    "alice@mail.com",
+		Scope:    "wallet:read wallet:debit",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(exp)),
+		},
+	}
+	if audience != "" {
+		claims.Audience = jwt.ClaimStrings{audience}
+	}
+
+	return claims
+}
+
+func TestLocalTokenValidator_HS256(t *testing.T) {
+	now := time.Now()
+	want := models.TokenData{UserID: 7, Username: "alice", Fullname: "Alice Doe", Email: "alice@mail.com", Scopes: []string{"wallet:read", "wallet:debit"}}
+
+	sign := func(claims helpers.ClaimToken, method jwt.SigningMethod, key interface{}) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		assert.NoError(t, err)
+		return "Bearer " + token
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{
+			name:  "success",
+			token: sign(userClaims(now, time.Hour, "ums", "ewallet"), jwt.SigningMethodHS256, []byte("app-secret")),
+		},
+		{
+			name:  "success expired within clock skew",
+			token: sign(userClaims(now.Add(-time.Hour), time.Hour-10*time.Second, "ums", "ewallet"), jwt.SigningMethodHS256, []byte("app-secret")),
+		},
+		{
+			name:    "error expired beyond clock skew",
+			token:   sign(userClaims(now.Add(-time.Hour), time.Hour-time.Minute, "ums", "ewallet"), jwt.SigningMethodHS256, []byte("app-secret")),
+			wantErr: true,
+		},
+		{
+			name:    "error wrong issuer",
+			token:   sign(userClaims(now, time.Hour, "someone-else", "ewallet"), jwt.SigningMethodHS256, []byte("app-secret")),
+			wantErr: true,
+		},
+		{
+			name:    "error wrong audience",
+			token:   sign(userClaims(now, time.Hour, "ums", "ewallet-admin"), jwt.SigningMethodHS256, []byte("app-secret")),
+			wantErr: true,
+		},
+		{
+			name:    "error wrong secret",
+			token:   sign(userClaims(now, time.Hour, "ums", "ewallet"), jwt.SigningMethodHS256, []byte("other-secret")),
+			wantErr: true,
+		},
+		{
+			name:    "error other hmac method",
+			token:   sign(userClaims(now, time.Hour, "ums", "ewallet"), jwt.SigningMethodHS512, []byte("app-secret")),
+			wantErr: true,
+		},
+		{
+			name:    "error none method",
+			token:   sign(userClaims(now, time.Hour, "ums", "ewallet"), jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType),
+			wantErr: true,
+		},
+		{
+			name:    "error malformed",
+			token:   "Bearer token",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewHS256TokenValidator(LocalTokenConfig{Secret: "app-secret", Issuer: "ums", Audience: "ewallet", ClockSkew: 30 * time.Second})
+
+			got, err := v.ValidateToken(context.Background(), tt.token)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, constants.ErrInvalidToken)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestLocalTokenValidator_HS256SecretNotConfigured(t *testing.T) {
+	v := NewHS256TokenValidator(LocalTokenConfig{})
+
+	// a token signed with the empty key must not pass either
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims(time.Now(), time.Hour, "", "")).SignedString([]byte(""))
+	assert.NoError(t, err)
+
+	_, err = v.ValidateToken(context.Background(), token)
+	assert.ErrorIs(t, err, constants.ErrInvalidToken)
+}
+
+type fakeKeyServer struct {
+	mu    sync.Mutex
+	keys  []map[string]string
+	fail  bool
+	calls atomic.Int32
+}
+
+func (f *fakeKeyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.calls.Add(1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": f.keys})
+}
+
+func (f *fakeKeyServer) setKeys(keys ...map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = keys
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   b64(key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   b64(key.X.FillBytes(make([]byte, 32))),
+		"y":   b64(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func TestLocalTokenValidator_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	rotatedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	sign := func(kid string, method jwt.SigningMethod, key interface{}) string {
+		token := jwt.NewWithClaims(method, userClaims(now, time.Hour, "ums", ""))
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		assert.NoError(t, err)
+		return "Bearer " + signed
+	}
+
+	server := &fakeKeyServer{}
+	server.setKeys(rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	jwks, err := NewJWKS(context.Background(), JWKSConfig{Source: srv.URL, RefreshInterval: time.Hour})
+	assert.NoError(t, err)
+	clock := time.Now()
+	jwks.now = func() time.Time { return clock }
+
+	v := NewJWKSTokenValidator(jwks, LocalTokenConfig{Issuer: "ums", ClockSkew: 30 * time.Second})
+
+	t.Run("success rs256", func(t *testing.T) {
+		got, err := v.ValidateToken(context.Background(), sign("rsa-1", jwt.SigningMethodRS256, rsaKey))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(7), got.UserID)
+	})
+
+	t.Run("success es256", func(t *testing.T) {
+		got, err := v.ValidateToken(context.Background(), sign("ec-1", jwt.SigningMethodES256, ecKey))
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", got.Username)
+	})
+
+	t.Run("error key of another algorithm", func(t *testing.T) {
+		_, err := v.ValidateToken(context.Background(), sign("rsa-1", jwt.SigningMethodPS256, rsaKey))
+		assert.ErrorIs(t, err, constants.ErrInvalidToken)
+	})
+
+	t.Run("error signed by an unknown key", func(t *testing.T) {
+		_, err := v.ValidateToken(context.Background(), sign("rsa-1", jwt.SigningMethodRS256, rotatedKey))
+		assert.ErrorIs(t, err, constants.ErrInvalidToken)
+	})
+
+	t.Run("error hs256 with the public key", func(t *testing.T) {
+		_, err := v.ValidateToken(context.Background(), sign("rsa-1", jwt.SigningMethodHS256, []byte(rsaJWK("rsa-1", rsaKey)["n"])))
+		assert.ErrorIs(t, err, constants.ErrInvalidToken)
+	})
+
+	t.Run("rotated key is fetched on first use", func(t *testing.T) {
+		server.setKeys(rsaJWK("rsa-2", rotatedKey))
+		clock = clock.Add(jwksUnknownKeyRefresh)
+		calls := server.calls.Load()
+
+		got, err := v.ValidateToken(context.Background(), sign("rsa-2", jwt.SigningMethodRS256, rotatedKey))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(7), got.UserID)
+		assert.Equal(t, calls+1, server.calls.Load())
+
+		// the retired key is gone with the old set
+		_, err = v.ValidateToken(context.Background(), sign("rsa-1", jwt.SigningMethodRS256, rsaKey))
+		assert.ErrorIs(t, err, constants.ErrInvalidToken)
+	})
+
+	t.Run("unknown key ids do not hammer the key server", func(t *testing.T) {
+		calls := server.calls.Load()
+		for i := 0; i < 5; i++ {
+			_, err := v.ValidateToken(context.Background(), sign("made-up", jwt.SigningMethodRS256, rsaKey))
+			assert.ErrorIs(t, err, constants.ErrInvalidToken)
+		}
+		assert.Equal(t, calls, server.calls.Load())
+	})
+
+	t.Run("known keys kept when the key server fails", func(t *testing.T) {
+		server.mu.Lock()
+		server.fail = true
+		server.mu.Unlock()
+		clock = clock.Add(time.Hour)
+
+		got, err := v.ValidateToken(context.Background(), sign("rsa-2", jwt.SigningMethodRS256, rotatedKey))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(7), got.UserID)
+	})
+
+	t.Run("error unknown key while the key server fails", func(t *testing.T) {
+		clock = clock.Add(jwksUnknownKeyRefresh)
+
+		_, err := v.ValidateToken(context.Background(), sign("rsa-3", jwt.SigningMethodRS256, rsaKey))
+		assert.ErrorIs(t, err, constants.ErrKeySetUnavailable)
+	})
+}
+
+func TestJWKS_RefreshOutsideLock(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	rotatedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	server := &fakeKeyServer{}
+	server.setKeys(rsaJWK("rsa-1", rsaKey))
+	var (
+		blocked atomic.Bool
+		fetches atomic.Int32
+	)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if blocked.Load() {
+			<-release
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	jwks, err := NewJWKS(context.Background(), JWKSConfig{Source: srv.URL, RefreshInterval: time.Hour})
+	assert.NoError(t, err)
+	clock := time.Now().Add(jwksUnknownKeyRefresh)
+	jwks.now = func() time.Time { return clock }
+
+	// the rotated key is asked for at once while the key server hangs
+	blocked.Store(true)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key, err := jwks.Key(context.Background(), "rsa-2")
+			assert.NoError(t, err)
+			assert.Equal(t, "RS256", key.alg)
+		}()
+	}
+	assert.Eventually(t, func() bool { return fetches.Load() == 2 }, time.Second, time.Millisecond)
+
+	// a known key does not wait for the fetch
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := jwks.Key(context.Background(), "rsa-1")
+		assert.NoError(t, err)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("known key waited for the key server")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	server.setKeys(rsaJWK("rsa-2", rotatedKey))
+	close(release)
+	wg.Wait()
+
+	// the callers shared the one fetch
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestNewJWKS(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		wantErr bool
+	}{
+		{
+			name:   "success skips encryption and unsupported keys",
+			body:   `{"keys":[{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"},{"kty":"OKP","kid":"ed"},{"kty":"RSA","kid":"sig","n":"AQAB","e":"AQAB"}]}`,
+			status: http.StatusOK,
+		},
+		{
+			name:    "error no signing key",
+			body:    `{"keys":[{"kty":"oct","kid":"hmac","k":"c2VjcmV0"}]}`,
+			status:  http.StatusOK,
+			wantErr: true,
+		},
+		{
+			name:    "error point not on curve",
+			body:    `{"keys":[{"kty":"EC","kid":"ec","crv":"P-256","x":"AQ","y":"AQ"}]}`,
+			status:  http.StatusOK,
+			wantErr: true,
+		},
+		{
+			name:    "error key server down",
+			status:  http.StatusBadGateway,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewJWKS(context.Background(), JWKSConfig{Source: srv.URL, RefreshInterval: time.Hour})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, constants.ErrKeySetUnavailable)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
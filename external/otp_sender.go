@@ -0,0 +1,16 @@
+package external
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"log"
+)
+
+// LogOTPSender stands in for an SMS or email provider in local setups, it
+// writes the OTP to the service log.
+type LogOTPSender struct{}
+
+func (s *LogOTPSender) SendOTP(ctx context.Context, msg models.OTPMessage) error {
+	log.Printf("otp %s for user %d <%s>: %s, valid until %s\n", msg.Purpose, msg.UserID, msg.Email, msg.OTP, msg.ExpiresAt.Format("15:04:05"))
+	return nil
+}
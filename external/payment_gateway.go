@@ -0,0 +1,83 @@
+package external
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const fakeGatewayReferencePrefix = "FAKE-"
+
+// FakeGateway stands in for a real payment gateway in local and test setups.
+// It hands out a virtual account without calling anything and accepts
+// callbacks signed with Secret, see SignCallback.
+type FakeGateway struct {
+	Secret  string
+	BaseURL string
+}
+
+func (g *FakeGateway) Name() string {
+	return constants.GatewayFake
+}
+
+func (g *FakeGateway) CreatePayment(ctx context.Context, req models.GatewayPaymentRequest) (models.GatewayPayment, error) {
+	gatewayReference := fakeGatewayReferencePrefix + req.Reference
+
+	return models.GatewayPayment{
+		GatewayReference: gatewayReference,
+		PaymentCode:      fmt.Sprintf("8808%012d", req.WalletID),
+		PaymentURL:       strings.TrimSuffix(g.BaseURL, "/") + "/pay/" + gatewayReference,
+	}, nil
+}
+
+func (g *FakeGateway) VerifyCallback(ctx context.Context, payload []byte, signature string) (models.GatewayCallback, error) {
+	var (
+		resp models.GatewayCallback
+	)
+
+	if g.Secret == "" || !hmac.Equal([]byte(signature), []byte(g.SignCallback(payload))) {
+		return resp, constants.ErrInvalidGatewaySignature
+	}
+
+	err := json.Unmarshal(payload, &resp)
+	if err != nil {
+		return resp, fmt.Errorf("%w: %v", constants.ErrInvalidGatewayCallback, err)
+	}
+
+	return resp, nil
+}
+
+// SignCallback signs a raw callback body the way the fake gateway does.
+func (g *FakeGateway) SignCallback(payload []byte) string {
+	return signHMAC(g.Secret, payload)
+}
+
+// NewFakeCallback builds a signed callback body for the fake gateway, used to
+// simulate a payment locally.
+func (g *FakeGateway) NewFakeCallback(callback models.GatewayCallback) ([]byte, string, error) {
+	if callback.GatewayReference == "" {
+		callback.GatewayReference = fakeGatewayReferencePrefix + callback.Reference
+	}
+
+	payload, err := json.Marshal(callback)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to marshal callback")
+	}
+
+	return payload, g.SignCallback(payload), nil
+}
+
+// signHMAC returns the hex HMAC-SHA256 of payload.
+func signHMAC(secret string, payload []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
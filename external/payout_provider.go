@@ -0,0 +1,72 @@
+package external
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const simulatedPayoutReferencePrefix = "SIM-"
+
+// SimulatedPayoutProvider stands in for a bank payout provider in local and
+// test setups. Account numbers ending in 0000 do not exist, every payout is
+// accepted and its outcome arrives through a callback signed with Secret.
+type SimulatedPayoutProvider struct {
+	Secret string
+}
+
+func (p *SimulatedPayoutProvider) Name() string {
+	return constants.PayoutProviderSimulated
+}
+
+func (p *SimulatedPayoutProvider) InquireAccount(ctx context.Context, bankCode string, accountNumber string) (string, error) {
+	if constants.MappingBankCode[bankCode] == "" || strings.HasSuffix(accountNumber, "0000") {
+		return "", fmt.Errorf("%w: %s %s", constants.ErrInvalidBankAccount, bankCode, accountNumber)
+	}
+
+	return "SIMULATED HOLDER " + accountNumber[len(accountNumber)-4:], nil
+}
+
+func (p *SimulatedPayoutProvider) CreatePayout(ctx context.Context, req models.PayoutRequest) (models.Payout, error) {
+	return models.Payout{
+		ProviderReference: simulatedPayoutReferencePrefix + req.Reference,
+	}, nil
+}
+
+func (p *SimulatedPayoutProvider) VerifyCallback(ctx context.Context, payload []byte, signature string) (models.PayoutCallback, error) {
+	var (
+		resp models.PayoutCallback
+	)
+
+	if p.Secret == "" || !hmac.Equal([]byte(signature), []byte(signHMAC(p.Secret, payload))) {
+		return resp, constants.ErrInvalidPayoutSignature
+	}
+
+	err := json.Unmarshal(payload, &resp)
+	if err != nil {
+		return resp, fmt.Errorf("%w: %v", constants.ErrInvalidPayoutCallback, err)
+	}
+
+	return resp, nil
+}
+
+// NewSimulatedCallback builds a signed callback body for the simulated
+// provider, used to finish a payout locally.
+func (p *SimulatedPayoutProvider) NewSimulatedCallback(callback models.PayoutCallback) ([]byte, string, error) {
+	if callback.ProviderReference == "" {
+		callback.ProviderReference = simulatedPayoutReferencePrefix + callback.Reference
+	}
+
+	payload, err := json.Marshal(callback)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to marshal callback")
+	}
+
+	return payload, signHMAC(p.Secret, payload), nil
+}
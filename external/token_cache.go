@@ -0,0 +1,227 @@
+package external
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenValidator turns a user token into its TokenData, remotely through the
+// UMS or locally.
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, token string) (models.TokenData, error)
+}
+
+type TokenCacheConfig struct {
+	MaxEntries  int
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	// OnValidated sees the user data of every token the validator accepted,
+	// it is not called for the answers served from the cache
+	OnValidated func(ctx context.Context, data models.TokenData)
+}
+
+// TokenCache remembers token validations for a while, keyed by the hash of
+// the token so that no token is kept in memory. A valid token is never kept
+// past its own exp, a token without a readable exp is not cached at all.
+// Only a definitive rejection is cached as invalid, an unavailable UMS or
+// key set is asked again on the next request.
+type TokenCache struct {
+	next        TokenValidator
+	maxEntries  int
+	ttl         time.Duration
+	negativeTTL time.Duration
+	onValidated func(ctx context.Context, data models.TokenData)
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	// generation moves on every revocation, answers fetched before it are
+	// not cached so an in-flight validation cannot bring a revoked token back
+	generation uint64
+	stats      models.TokenCacheStats
+}
+
+type tokenCacheEntry struct {
+	key       string
+	data      models.TokenData
+	valid     bool
+	expiresAt time.Time
+}
+
+func NewTokenCache(next TokenValidator, cfg TokenCacheConfig) *TokenCache {
+	return &TokenCache{
+		next:        next,
+		maxEntries:  cfg.MaxEntries,
+		ttl:         cfg.TTL,
+		negativeTTL: cfg.NegativeTTL,
+		onValidated: cfg.OnValidated,
+		now:         time.Now,
+		entries:     map[string]*list.Element{},
+		order:       list.New(),
+	}
+}
+
+func (tc *TokenCache) ValidateToken(ctx context.Context, token string) (models.TokenData, error) {
+	key := tokenKey(token)
+
+	data, valid, found, generation := tc.get(key)
+	if found {
+		if !valid {
+			return models.TokenData{}, constants.ErrInvalidToken
+		}
+		return data, nil
+	}
+
+	data, err := tc.next.ValidateToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, constants.ErrInvalidToken) {
+			tc.set(key, generation, models.TokenData{}, false, tc.now().Add(tc.negativeTTL))
+		}
+		return data, err
+	}
+	if tc.onValidated != nil {
+		tc.onValidated(ctx, data)
+	}
+
+	expiresAt := tc.now().Add(tc.ttl)
+	exp, ok := tokenExpiry(token)
+	if !ok {
+		return data, nil
+	}
+	if exp.Before(expiresAt) {
+		expiresAt = exp
+	}
+	tc.set(key, generation, data, true, expiresAt)
+
+	return data, nil
+}
+
+func (tc *TokenCache) get(key string) (models.TokenData, bool, bool, uint64) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	elem, ok := tc.entries[key]
+	if !ok {
+		tc.stats.Misses++
+		return models.TokenData{}, false, false, tc.generation
+	}
+
+	entry := elem.Value.(*tokenCacheEntry)
+	if !tc.now().Before(entry.expiresAt) {
+		tc.remove(elem)
+		tc.stats.Misses++
+		return models.TokenData{}, false, false, tc.generation
+	}
+
+	tc.order.MoveToFront(elem)
+	if entry.valid {
+		tc.stats.Hits++
+	} else {
+		tc.stats.NegativeHits++
+	}
+
+	return entry.data, entry.valid, true, tc.generation
+}
+
+func (tc *TokenCache) set(key string, generation uint64, data models.TokenData, valid bool, expiresAt time.Time) {
+	if tc.maxEntries <= 0 || !tc.now().Before(expiresAt) {
+		return
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if generation != tc.generation {
+		return
+	}
+
+	if elem, ok := tc.entries[key]; ok {
+		tc.remove(elem)
+	}
+
+	tc.entries[key] = tc.order.PushFront(&tokenCacheEntry{
+		key:       key,
+		data:      data,
+		valid:     valid,
+		expiresAt: expiresAt,
+	})
+
+	for tc.order.Len() > tc.maxEntries {
+		tc.remove(tc.order.Back())
+		tc.stats.Evictions++
+	}
+}
+
+func (tc *TokenCache) remove(elem *list.Element) {
+	tc.order.Remove(elem)
+	delete(tc.entries, elem.Value.(*tokenCacheEntry).key)
+}
+
+// Revoke drops the token, the next request with it is validated again.
+func (tc *TokenCache) Revoke(token string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if elem, ok := tc.entries[tokenKey(token)]; ok {
+		tc.remove(elem)
+	}
+	tc.generation++
+	tc.stats.Revocations++
+}
+
+// RevokeUser drops every cached token of the user, for a logout everywhere
+// or a blocked account.
+func (tc *TokenCache) RevokeUser(userID uint64) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	for elem := tc.order.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*tokenCacheEntry)
+		if entry.valid && entry.data.UserID == userID {
+			tc.remove(elem)
+		}
+		elem = next
+	}
+	tc.generation++
+	tc.stats.Revocations++
+}
+
+func (tc *TokenCache) Stats() models.TokenCacheStats {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	stats := tc.stats
+	stats.Entries = tc.order.Len()
+
+	return stats
+}
+
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimPrefix(token, "Bearer ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenExpiry reads the exp claim without checking the signature, the
+// validator already vouched for the token, the claim only bounds how long we
+// trust it.
+func tokenExpiry(token string) (time.Time, bool) {
+	claims := jwt.RegisteredClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(strings.TrimPrefix(token, "Bearer "), &claims)
+	if err != nil || claims.ExpiresAt == nil {
+		return time.Time{}, false
+	}
+
+	return claims.ExpiresAt.Time, true
+}
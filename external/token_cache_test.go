@@ -0,0 +1,311 @@
+package external
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeValidator struct {
+	mu    sync.Mutex
+	calls map[string]int
+	users map[string]uint64
+	err   error
+}
+
+func (f *fakeValidator) ValidateToken(ctx context.Context, token string) (models.TokenData, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.calls[token]++
+	if f.err != nil {
+		return models.TokenData{}, f.err
+	}
+
+	return models.TokenData{UserID: f.users[token], Username: "alice"}, nil
+}
+
+func signedToken(t *testing.T, exp time.Time) string {
+	claims := jwt.RegisteredClaims{}
+	if !exp.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(exp)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	return "Bearer " + token
+}
+
+func newTestTokenCache(next TokenValidator, maxEntries int, now *time.Time) *TokenCache {
+	tc := NewTokenCache(next, TokenCacheConfig{
+		MaxEntries:  maxEntries,
+		TTL:         time.Minute,
+		NegativeTTL: 10 * time.Second,
+	})
+	tc.now = func() time.Time { return *now }
+
+	return tc
+}
+
+func TestTokenCache_ValidateToken(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		exp       time.Duration
+		noExp     bool
+		err       error
+		elapsed   time.Duration
+		wantCalls int
+		wantErr   error
+		wantStats models.TokenCacheStats
+	}{
+		{
+			name:      "hit within ttl",
+			exp:       time.Hour,
+			elapsed:   30 * time.Second,
+			wantCalls: 1,
+			wantStats: models.TokenCacheStats{Entries: 1, Hits: 1, Misses: 1},
+		},
+		{
+			name:      "miss after ttl",
+			exp:       time.Hour,
+			elapsed:   time.Minute,
+			wantCalls: 2,
+			wantStats: models.TokenCacheStats{Entries: 1, Misses: 2},
+		},
+		{
+			name:      "miss after token expiry shorter than ttl",
+			exp:       20 * time.Second,
+			elapsed:   20 * time.Second,
+			wantCalls: 2,
+			wantStats: models.TokenCacheStats{Misses: 2},
+		},
+		{
+			name:      "hit before token expiry shorter than ttl",
+			exp:       20 * time.Second,
+			elapsed:   19 * time.Second,
+			wantCalls: 1,
+			wantStats: models.TokenCacheStats{Entries: 1, Hits: 1, Misses: 1},
+		},
+		{
+			name:      "token without exp is not cached",
+			noExp:     true,
+			wantCalls: 2,
+			wantStats: models.TokenCacheStats{Misses: 2},
+		},
+		{
+			name:      "expired token is not cached",
+			exp:       -time.Second,
+			wantCalls: 2,
+			wantStats: models.TokenCacheStats{Misses: 2},
+		},
+		{
+			name:      "invalid token cached as negative",
+			exp:       time.Hour,
+			err:       fmt.Errorf("%w: token is revoked", constants.ErrInvalidToken),
+			elapsed:   5 * time.Second,
+			wantCalls: 1,
+			wantErr:   constants.ErrInvalidToken,
+			wantStats: models.TokenCacheStats{Entries: 1, NegativeHits: 1, Misses: 1},
+		},
+		{
+			name:      "invalid token asked again after negative ttl",
+			exp:       time.Hour,
+			err:       fmt.Errorf("%w: token is revoked", constants.ErrInvalidToken),
+			elapsed:   10 * time.Second,
+			wantCalls: 2,
+			wantErr:   constants.ErrInvalidToken,
+			wantStats: models.TokenCacheStats{Entries: 1, Misses: 2},
+		},
+		{
+			name:      "unavailable ums is not cached",
+			exp:       time.Hour,
+			err:       fmt.Errorf("%w: down", constants.ErrUMSUnavailable),
+			wantCalls: 2,
+			wantErr:   constants.ErrUMSUnavailable,
+			wantStats: models.TokenCacheStats{Misses: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start
+			next := &fakeValidator{err: tt.err}
+			tc := newTestTokenCache(next, 10, &now)
+
+			var exp time.Time
+			if !tt.noExp {
+				exp = start.Add(tt.exp)
+			}
+			token := signedToken(t, exp)
+
+			for i := 0; i < 2; i++ {
+				_, err := tc.ValidateToken(context.Background(), token)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+				} else {
+					assert.NoError(t, err)
+				}
+				now = now.Add(tt.elapsed)
+			}
+
+			assert.Equal(t, tt.wantCalls, next.calls[token])
+			assert.Equal(t, tt.wantStats, tc.Stats())
+		})
+	}
+}
+
+func TestTokenCache_Eviction(t *testing.T) {
+	now := time.Now()
+	next := &fakeValidator{}
+	tc := newTestTokenCache(next, 2, &now)
+
+	first := signedToken(t, now.Add(time.Hour))
+	second := signedToken(t, now.Add(2*time.Hour))
+	third := signedToken(t, now.Add(3*time.Hour))
+
+	for _, token := range []string{first, second, first, third, first, second} {
+		_, err := tc.ValidateToken(context.Background(), token)
+		assert.NoError(t, err)
+	}
+
+	// second was the least recently used when third came in
+	assert.Equal(t, 1, next.calls[first])
+	assert.Equal(t, 2, next.calls[second])
+	assert.Equal(t, models.TokenCacheStats{Entries: 2, Hits: 2, Misses: 4, Evictions: 2}, tc.Stats())
+}
+
+func TestTokenCache_Disabled(t *testing.T) {
+	now := time.Now()
+	next := &fakeValidator{}
+	tc := newTestTokenCache(next, 0, &now)
+
+	token := signedToken(t, now.Add(time.Hour))
+	for i := 0; i < 2; i++ {
+		_, err := tc.ValidateToken(context.Background(), token)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, next.calls[token])
+	assert.Equal(t, 0, tc.Stats().Entries)
+}
+
+func TestTokenCache_Revoke(t *testing.T) {
+	now := time.Now()
+
+	alice := signedToken(t, now.Add(time.Hour))
+	aliceOtherDevice := signedToken(t, now.Add(2*time.Hour))
+	bob := signedToken(t, now.Add(3*time.Hour))
+	users := map[string]uint64{alice: 1, aliceOtherDevice: 1, bob: 2}
+
+	tests := []struct {
+		name      string
+		revoke    func(tc *TokenCache)
+		wantCalls map[string]int
+	}{
+		{
+			name:      "revoke token",
+			revoke:    func(tc *TokenCache) { tc.Revoke(alice) },
+			wantCalls: map[string]int{alice: 2, aliceOtherDevice: 1, bob: 1},
+		},
+		{
+			name:      "revoke token without bearer prefix",
+			revoke:    func(tc *TokenCache) { tc.Revoke(alice[len("Bearer "):]) },
+			wantCalls: map[string]int{alice: 2, aliceOtherDevice: 1, bob: 1},
+		},
+		{
+			name:      "revoke user",
+			revoke:    func(tc *TokenCache) { tc.RevokeUser(1) },
+			wantCalls: map[string]int{alice: 2, aliceOtherDevice: 2, bob: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &fakeValidator{users: users}
+			tc := newTestTokenCache(next, 10, &now)
+
+			for _, token := range []string{alice, aliceOtherDevice, bob} {
+				_, err := tc.ValidateToken(context.Background(), token)
+				assert.NoError(t, err)
+			}
+
+			tt.revoke(tc)
+
+			for _, token := range []string{alice, aliceOtherDevice, bob} {
+				got, err := tc.ValidateToken(context.Background(), token)
+				assert.NoError(t, err)
+				assert.Equal(t, users[token], got.UserID)
+			}
+
+			assert.Equal(t, tt.wantCalls, next.calls)
+			assert.Equal(t, uint64(1), tc.Stats().Revocations)
+		})
+	}
+}
+
+type revokingValidator struct {
+	fakeValidator
+	revoke func()
+}
+
+func (r *revokingValidator) ValidateToken(ctx context.Context, token string) (models.TokenData, error) {
+	data, err := r.fakeValidator.ValidateToken(ctx, token)
+	r.revoke()
+
+	return data, err
+}
+
+func TestTokenCache_RevokeDuringValidation(t *testing.T) {
+	now := time.Now()
+	token := signedToken(t, now.Add(time.Hour))
+
+	next := &revokingValidator{}
+	tc := newTestTokenCache(next, 10, &now)
+	next.revoke = func() { tc.Revoke(token) }
+
+	_, err := tc.ValidateToken(context.Background(), token)
+	assert.NoError(t, err)
+
+	// the answer was fetched before the revocation, it must not be cached
+	assert.Equal(t, 0, tc.Stats().Entries)
+}
+
+func TestTokenCache_OnValidated(t *testing.T) {
+	now := time.Now()
+	next := &fakeValidator{users: map[string]uint64{}}
+
+	var seen []uint64
+	tc := NewTokenCache(next, TokenCacheConfig{
+		MaxEntries:  10,
+		TTL:         time.Minute,
+		NegativeTTL: 10 * time.Second,
+		OnValidated: func(ctx context.Context, data models.TokenData) {
+			seen = append(seen, data.UserID)
+		},
+	})
+	tc.now = func() time.Time { return now }
+
+	token := signedToken(t, now.Add(time.Hour))
+	next.users[token] = 7
+	for i := 0; i < 3; i++ {
+		_, err := tc.ValidateToken(context.Background(), token)
+		assert.NoError(t, err)
+	}
+
+	next.err = constants.ErrInvalidToken
+	_, err := tc.ValidateToken(context.Background(), signedToken(t, now.Add(2*time.Hour)))
+	assert.ErrorIs(t, err, constants.ErrInvalidToken)
+
+	// only the one answer from the validator, not the cached ones or the rejection
+	assert.Equal(t, []uint64{7}, seen)
+}
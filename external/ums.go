@@ -2,48 +2,217 @@ package external
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"ewallet-wallet/constants"
 	"ewallet-wallet/external/proto/tokenvalidation"
+	"ewallet-wallet/helpers"
 	"ewallet-wallet/internal/models"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
-type External struct {
+type UMSConfig struct {
+	Address string
+	// TLS turns on TLS, with CertFile and KeyFile the client authenticates
+	// itself too. Without CAFile the system roots are trusted.
+	TLS              bool
+	CAFile           string
+	CertFile         string
+	KeyFile          string
+	ServerName       string
+	Timeout          time.Duration
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	DialOptions      []grpc.DialOption
 }
 
-func (*External) ValidateToken(ctx context.Context, token string) (models.TokenData, error) {
-	var (
-		resp models.TokenData
-	)
+// UMSClient validates tokens with the UMS over one long-lived connection.
+// Every attempt has its own deadline, transient failures are retried and
+// feed a circuit breaker, so a degraded UMS fails fast with
+// ErrUMSUnavailable.
+type UMSClient struct {
+	conn         *grpc.ClientConn
+	client       tokenvalidation.TokenValidationClient
+	breaker      *CircuitBreaker
+	timeout      time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+}
 
-	conn, err := grpc.NewClient("localhost:7000", grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewUMSClient(cfg UMSConfig) (*UMSClient, error) {
+	creds := insecure.NewCredentials()
+	if cfg.TLS {
+		tlsConfig, err := umsTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, cfg.DialOptions...)
+	conn, err := grpc.NewClient(cfg.Address, opts...)
 	if err != nil {
-		return resp, errors.Wrap(err, "failed to dial grpc")
+		return nil, errors.Wrap(err, "failed to create ums client")
+	}
+
+	return &UMSClient{
+		conn:         conn,
+		client:       tokenvalidation.NewTokenValidationClient(conn),
+		breaker:      NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		timeout:      cfg.Timeout,
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+	}, nil
+}
+
+func umsTLSConfig(cfg UMSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: cfg.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.CAFile != "" {
+		ca, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read ums ca")
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load ums client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
-	defer conn.Close()
+	return tlsConfig, nil
+}
+
+func (c *UMSClient) Close() error {
+	return c.conn.Close()
+}
+
+// ConnState is the state of the gRPC connection to the UMS. The connection
+// goes idle when no token was checked for a while, an idle one is asked to
+// reconnect so the next check finds it ready again.
+func (c *UMSClient) ConnState() connectivity.State {
+	state := c.conn.GetState()
+	if state == connectivity.Idle {
+		c.conn.Connect()
+	}
+	return state
+}
+
+// State is the state of the circuit breaker in front of the UMS.
+func (c *UMSClient) State() string {
+	return c.breaker.State()
+}
+
+func (c *UMSClient) ValidateToken(ctx context.Context, token string) (models.TokenData, error) {
+	var (
+		resp     models.TokenData
+		response *tokenvalidation.TokenResponse
+		err      error
+	)
 
-	client := tokenvalidation.NewTokenValidationClient(conn)
 	req := &tokenvalidation.TokenRequest{
 		Token: token,
 	}
-	response, err := client.ValidateToken(ctx, req)
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return resp, errors.Wrap(ctx.Err(), "failed to validate token")
+			case <-time.After(c.retryBackoff * time.Duration(attempt)):
+			}
+		}
+
+		if !c.breaker.Allow() {
+			return resp, errors.Wrap(constants.ErrUMSUnavailable, "circuit breaker is open")
+		}
+
+		response, err = c.validateToken(ctx, req)
+		if err != nil && ctx.Err() != nil {
+			// the caller gave up, that says nothing about the UMS
+			c.breaker.Abandon()
+			return resp, errors.Wrap(err, "failed to validate token")
+		}
+
+		if err == nil || !isTransient(err) {
+			c.breaker.Success()
+			break
+		}
+
+		c.breaker.Failure()
+	}
+
 	if err != nil {
+		if isTransient(err) {
+			return resp, fmt.Errorf("%w: %v", constants.ErrUMSUnavailable, err)
+		}
 		return resp, errors.Wrap(err, "failed to validate token")
 	}
 
-	if response.Message != constants.SuccessMessage {
-		return resp, fmt.Errorf("got response error from ums: %s", response.Message)
+	if response.GetMessage() != constants.SuccessMessage {
+		return resp, fmt.Errorf("%w: got response error from ums: %s", constants.ErrInvalidToken, response.GetMessage())
 	}
 
-	resp.UserID = response.Data.UserId
-	resp.Username = response.Data.Username
-	resp.Fullname = response.Data.FullName
-	resp.Email = response.Data.Email
+	resp.UserID = response.GetData().GetUserId()
+	resp.Username = response.GetData().GetUsername()
+	resp.Fullname = response.GetData().GetFullName()
+	resp.Email = response.GetData().GetEmail()
+	resp.Scopes = tokenScopes(token)
 
 	return resp, nil
 }
+
+// tokenScopes reads the scope claim of a token the UMS accepted, the UMS
+// answer does not carry the scopes.
+func tokenScopes(token string) []string {
+	claims := helpers.ClaimToken{}
+	_, _, err := jwt.NewParser().ParseUnverified(strings.TrimPrefix(token, "Bearer "), &claims)
+	if err != nil {
+		return nil
+	}
+
+	return strings.Fields(claims.Scope)
+}
+
+func (c *UMSClient) validateToken(ctx context.Context, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return c.client.ValidateToken(ctx, req)
+}
+
+// isTransient tells whether the call may work when tried again.
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
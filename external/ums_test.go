@@ -0,0 +1,404 @@
+package external
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/external/proto/tokenvalidation"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeTokenValidation struct {
+	tokenvalidation.UnimplementedTokenValidationServer
+	calls    atomic.Int32
+	validate func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error)
+}
+
+func (f *fakeTokenValidation) ValidateToken(ctx context.Context, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+	return f.validate(f.calls.Add(1), req)
+}
+
+func validTokenResponse() *tokenvalidation.TokenResponse {
+	return &tokenvalidation.TokenResponse{
+		Message: constants.SuccessMessage,
+		Data: &tokenvalidation.UserData{
+			UserId:   7,
+			Username: "alice",
+			FullName: "Alice Doe",
+			Email:    "alice@mail.com",
+		},
+	}
+}
+
+// startFakeUMS serves fake on an in-process listener, the returned config
+// dials it.
+func startFakeUMS(t *testing.T, fake *fakeTokenValidation, opts ...grpc.ServerOption) UMSConfig {
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(opts...)
+	tokenvalidation.RegisterTokenValidationServer(s, fake)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	return UMSConfig{
+		Address:          "passthrough:///bufnet",
+		Timeout:          100 * time.Millisecond,
+		MaxRetries:       2,
+		RetryBackoff:     time.Millisecond,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+		DialOptions: []grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+		},
+	}
+}
+
+func TestUMSClient_ValidateToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		validate    func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error)
+		want        models.TokenData
+		wantErr     bool
+		unavailable bool
+		invalid     bool
+		wantCalls   int32
+	}{
+		{
+			name: "success",
+			validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+				assert.Equal(t, "Bearer token", req.Token)
+				return validTokenResponse(), nil
+			},
+			want:      models.TokenData{UserID: 7, Username: "alice", Fullname: "Alice Doe", Email: "alice@mail.com"},
+			wantCalls: 1,
+		},
+		{
+			name: "success after transient failures",
+			validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+				if call < 3 {
+					return nil, status.Error(codes.Unavailable, "restarting")
+				}
+				return validTokenResponse(), nil
+			},
+			want:      models.TokenData{UserID: 7, Username: "alice", Fullname: "Alice Doe", Email: "alice@mail.com"},
+			wantCalls: 3,
+		},
+		{
+			name: "error invalid token",
+			validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+				return &tokenvalidation.TokenResponse{Message: "token is expired"}, nil
+			},
+			wantErr:   true,
+			invalid:   true,
+			wantCalls: 1,
+		},
+		{
+			name: "error not retried",
+			validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+				return nil, status.Error(codes.InvalidArgument, "bad token")
+			},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name: "error unavailable after every retry",
+			validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+				return nil, status.Error(codes.Unavailable, "down")
+			},
+			wantErr:     true,
+			unavailable: true,
+			wantCalls:   3,
+		},
+		{
+			name: "error every attempt past its deadline",
+			validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+				time.Sleep(200 * time.Millisecond)
+				return validTokenResponse(), nil
+			},
+			wantErr:     true,
+			unavailable: true,
+			wantCalls:   3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTokenValidation{validate: tt.validate}
+			client, err := NewUMSClient(startFakeUMS(t, fake))
+			assert.NoError(t, err)
+			defer client.Close()
+
+			got, err := client.ValidateToken(context.Background(), "Bearer token")
+			assert.Equal(t, tt.wantCalls, fake.calls.Load())
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.unavailable, errors.Is(err, constants.ErrUMSUnavailable))
+				assert.Equal(t, tt.invalid, errors.Is(err, constants.ErrInvalidToken))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUMSClient_CircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	fake := &fakeTokenValidation{
+		validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+			if !healthy.Load() {
+				return nil, status.Error(codes.Unavailable, "down")
+			}
+			return validTokenResponse(), nil
+		},
+	}
+
+	cfg := startFakeUMS(t, fake)
+	cfg.MaxRetries = 0
+	cfg.BreakerThreshold = 2
+	cfg.BreakerCooldown = 50 * time.Millisecond
+	client, err := NewUMSClient(cfg)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err = client.ValidateToken(context.Background(), "token")
+		assert.ErrorIs(t, err, constants.ErrUMSUnavailable)
+	}
+	assert.Equal(t, constants.CircuitOpen, client.State())
+
+	// open, the UMS is not called at all
+	_, err = client.ValidateToken(context.Background(), "token")
+	assert.ErrorIs(t, err, constants.ErrUMSUnavailable)
+	assert.Equal(t, int32(2), fake.calls.Load())
+
+	// the trial after the cooldown fails, the circuit opens again
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.ValidateToken(context.Background(), "token")
+	assert.ErrorIs(t, err, constants.ErrUMSUnavailable)
+	assert.Equal(t, int32(3), fake.calls.Load())
+	assert.Equal(t, constants.CircuitOpen, client.State())
+
+	// the trial after the next cooldown works, the circuit closes
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	got, err := client.ValidateToken(context.Background(), "token")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), got.UserID)
+	assert.Equal(t, constants.CircuitClosed, client.State())
+}
+
+func TestUMSClient_TokenScopes(t *testing.T) {
+	fake := &fakeTokenValidation{
+		validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+			return validTokenResponse(), nil
+		},
+	}
+	client, err := NewUMSClient(startFakeUMS(t, fake))
+	assert.NoError(t, err)
+	defer client.Close()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, helpers.ClaimToken{
+		UserID: 7,
+		Scope:  "wallet:read admin",
+	}).SignedString([]byte("ums-secret"))
+	assert.NoError(t, err)
+
+	got, err := client.ValidateToken(context.Background(), "Bearer "+token)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{constants.ScopeWalletRead, constants.ScopeAdmin}, got.Scopes)
+}
+
+func TestUMSClient_CallerCancelled(t *testing.T) {
+	fake := &fakeTokenValidation{
+		validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+			time.Sleep(50 * time.Millisecond)
+			return validTokenResponse(), nil
+		},
+	}
+
+	cfg := startFakeUMS(t, fake)
+	cfg.BreakerThreshold = 1
+	client, err := NewUMSClient(cfg)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = client.ValidateToken(ctx, "token")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, constants.ErrUMSUnavailable))
+	assert.Equal(t, int32(1), fake.calls.Load())
+	assert.Equal(t, constants.CircuitClosed, client.State())
+}
+
+func TestUMSClient_CallerCancelledTrial(t *testing.T) {
+	var slow atomic.Bool
+	fake := &fakeTokenValidation{
+		validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+			if call == 1 {
+				return nil, status.Error(codes.Unavailable, "down")
+			}
+			if slow.Load() {
+				time.Sleep(50 * time.Millisecond)
+			}
+			return validTokenResponse(), nil
+		},
+	}
+
+	cfg := startFakeUMS(t, fake)
+	cfg.MaxRetries = 0
+	cfg.BreakerThreshold = 1
+	cfg.BreakerCooldown = 20 * time.Millisecond
+	client, err := NewUMSClient(cfg)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.ValidateToken(context.Background(), "token")
+	assert.ErrorIs(t, err, constants.ErrUMSUnavailable)
+	assert.Equal(t, constants.CircuitOpen, client.State())
+
+	// the caller of the trial gives up, the circuit must not stay half-open
+	time.Sleep(30 * time.Millisecond)
+	slow.Store(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.ValidateToken(ctx, "token")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, constants.ErrUMSUnavailable))
+	assert.Equal(t, constants.CircuitOpen, client.State())
+
+	// the next call goes out as the trial and closes the circuit
+	slow.Store(false)
+	got, err := client.ValidateToken(context.Background(), "token")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), got.UserID)
+	assert.Equal(t, int32(3), fake.calls.Load())
+	assert.Equal(t, constants.CircuitClosed, client.State())
+}
+
+func TestUMSClient_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newTestCertificate(t, nil, nil, "ums-ca", true)
+	serverCert, serverKey := newTestCertificate(t, ca, caKey, "ums.internal", false)
+	clientCert, clientKey := newTestCertificate(t, ca, caKey, "ewallet-wallet", false)
+
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", ca.Raw)
+	certFile := writePEM(t, dir, "client.pem", "CERTIFICATE", clientCert.Raw)
+	keyFile := writePEM(t, dir, "client-key.pem", "PRIVATE KEY", marshalKey(t, clientKey))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	serverTLS := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+
+	fake := &fakeTokenValidation{
+		validate: func(call int32, req *tokenvalidation.TokenRequest) (*tokenvalidation.TokenResponse, error) {
+			return validTokenResponse(), nil
+		},
+	}
+
+	t.Run("success with client certificate", func(t *testing.T) {
+		cfg := startFakeUMS(t, fake, grpc.Creds(serverTLS))
+		cfg.TLS = true
+		cfg.CAFile = caFile
+		cfg.CertFile = certFile
+		cfg.KeyFile = keyFile
+		cfg.ServerName = "ums.internal"
+		client, err := NewUMSClient(cfg)
+		assert.NoError(t, err)
+		defer client.Close()
+
+		got, err := client.ValidateToken(context.Background(), "token")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(7), got.UserID)
+	})
+
+	t.Run("error without client certificate", func(t *testing.T) {
+		cfg := startFakeUMS(t, fake, grpc.Creds(serverTLS))
+		cfg.TLS = true
+		cfg.CAFile = caFile
+		cfg.ServerName = "ums.internal"
+		cfg.MaxRetries = 0
+		client, err := NewUMSClient(cfg)
+		assert.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.ValidateToken(context.Background(), "token")
+		assert.Error(t, err)
+	})
+
+	t.Run("error unreadable ca", func(t *testing.T) {
+		_, err := NewUMSClient(UMSConfig{Address: "localhost:7000", TLS: true, CAFile: filepath.Join(dir, "missing.pem")})
+		assert.Error(t, err)
+	})
+}
+
+func newTestCertificate(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, name string, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return cert, key
+}
+
+func marshalKey(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	return der
+}
+
+func writePEM(t *testing.T, dir string, name string, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)
+	assert.NoError(t, err)
+	return path
+}
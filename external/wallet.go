@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"ewallet-wallet/helpers"
+	"ewallet-wallet/constants"
 	"fmt"
 	"net/http"
 
@@ -22,6 +22,10 @@ type UpdateBalanceResponse struct {
 }
 
 type Wallet struct {
+	Host           string
+	CreditEndpoint string
+	DebitEndpoint  string
+	InternalKey    string
 }
 
 func (e *Wallet) CreditBalance(ctx context.Context, req UpdateBalance) (*UpdateBalanceResponse, error) {
@@ -30,12 +34,13 @@ func (e *Wallet) CreditBalance(ctx context.Context, req UpdateBalance) (*UpdateB
 		return nil, errors.Wrap(err, "failed to marshal json")
 	}
 
-	url := helpers.GetEnv("WALLET_HOST", "") + helpers.GetEnv("WALLET_ENDPOINT_CREDIT", "")
+	url := e.Host + e.CreditEndpoint
 
 	httpReq, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create wallet http request")
 	}
+	httpReq.Header.Set(constants.HeaderInternalKey, e.InternalKey)
 
 	client := &http.Client{}
 	resp, err := client.Do(httpReq)
@@ -63,7 +68,7 @@ func (e *Wallet) DebitBalance(ctx context.Context, req UpdateBalance) (*UpdateBa
 		return nil, errors.Wrap(err, "failed to marshal json")
 	}
 
-	url := helpers.GetEnv("WALLET_HOST", "") + helpers.GetEnv("WALLET_ENDPOINT_DEBIT", "")
+	url := e.Host + e.DebitEndpoint
 
 	httpReq, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
 	if err != nil {
@@ -14,9 +14,12 @@ import (
 
 func GenerateSignature(endpoint, clientID string, now time.Time, method string, strPayload string) string {
 	secretKey := constants.MappingClient[clientID]
-	timestamp := time.Now().Format(time.RFC3339)
+	timestamp := now.Format(time.RFC3339)
 
-	if method != http.MethodGet {
+	if method == http.MethodGet {
+		path, rawQuery, _ := strings.Cut(endpoint, "?")
+		strPayload = method + "\n" + path + "\n" + rawQuery + "\n" + timestamp
+	} else {
 		re := regexp.MustCompile(`[^a-zA-Z0-9]+`)
 		strPayload = re.ReplaceAllString(strPayload, "")
 		strPayload = strings.ToLower(strPayload) + timestamp + endpoint
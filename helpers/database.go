@@ -1,49 +1,142 @@
 package helpers
 
 import (
-	"ewallet-wallet/internal/models"
+	"ewallet-wallet/config"
+	"ewallet-wallet/constants"
 	"fmt"
+	"net/url"
+	"strings"
 
+	"github.com/glebarez/sqlite"
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-func SetupMySQL() {
-	dbuser := GetEnv("DB_USER", "")
-	dbpass := GetEnv("DB_PASSWORD", "")
-	dbhost := GetEnv("DB_HOST", "127.0.0.1")
-	dbport := GetEnv("DB_PORT", "3036")
-	dbname := GetEnv("DB_NAME", "")
+// SetupDatabase creates the database when it is missing and connects to it
+// with the driver of the config.
+func SetupDatabase(cfg config.DB) {
+	var err error
 
-	createDBDsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/", dbuser, dbpass, dbhost, dbport)
-	database, err := gorm.Open(mysql.Open(createDBDsn), &gorm.Config{})
+	switch cfg.Driver {
+	case constants.DBDriverPostgres:
+		DB, err = setupPostgres(cfg)
+	case constants.DBDriverSQLite:
+		DB, err = OpenSQLite(cfg.Name)
+	default:
+		DB, err = setupMySQL(cfg)
+	}
 	if err != nil {
-		logrus.Fatal("failed to create database", err)
+		logrus.Fatal(err)
 	}
 
-	result := database.Exec("CREATE DATABASE IF NOT EXISTS " + dbname + ";")
+	logrus.Info("successfully connect to database")
+}
+
+func setupMySQL(cfg config.DB) (*gorm.DB, error) {
+	createDBDsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/", cfg.User, cfg.Password, cfg.Host, cfg.Port)
+	database, err := gorm.Open(mysql.Open(createDBDsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
 
+	result := database.Exec("CREATE DATABASE IF NOT EXISTS " + cfg.Name + ";")
 	if result.Error != nil {
-		logrus.Fatal("failed create database")
+		return nil, fmt.Errorf("failed create database: %w", result.Error)
 	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=true&loc=Local",
-		dbuser,
-		dbpass,
-		dbhost,
-		dbport,
-		dbname,
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=true&loc=Local",
+		cfg.User,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.Name,
 	)
 
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
-		logrus.Fatal("failed to connect to database", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	logrus.Info("successfully connect to database")
+	return db, nil
+}
+
+func setupPostgres(cfg config.DB) (*gorm.DB, error) {
+	// postgres has no CREATE DATABASE IF NOT EXISTS, the catalog is asked
+	// from the maintenance database first
+	database, err := gorm.Open(postgres.Open(postgresDSN(cfg, "postgres")), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
+
+	var count int64
+	err = database.Raw("SELECT COUNT(*) FROM pg_database WHERE datname = ?", cfg.Name).Scan(&count).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed create database: %w", err)
+	}
+	if count == 0 {
+		err = database.Exec(`CREATE DATABASE "` + cfg.Name + `"`).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed create database: %w", err)
+		}
+	}
+
+	db, err := gorm.Open(postgres.Open(postgresDSN(cfg, cfg.Name)), &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return db, nil
+}
+
+func postgresDSN(cfg config.DB, name string) string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Path:     "/" + name,
+		RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+	}
+
+	return dsn.String()
+}
+
+// OpenSQLite opens, or creates, the sqlite database in the file at path.
+//
+// SQLite has no row locks and rejects FOR UPDATE, so the suffix is dropped
+// from the queries and every transaction begins IMMEDIATE instead: it takes
+// the write lock of the whole file up front and the balance read under it
+// can not change before the update, like the locked row on the other
+// engines. Writers queue on busy_timeout, WAL keeps reads going meanwhile.
+func OpenSQLite(path string) (*gorm.DB, error) {
+	dsn := path + "?_txlock=immediate&_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)"
+
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	err = db.Callback().Row().Before("gorm:row").Register("sqlite:drop_for_update", dropForUpdate)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Callback().Query().Before("gorm:query").Register("sqlite:drop_for_update", dropForUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func dropForUpdate(db *gorm.DB) {
+	query := db.Statement.SQL.String()
+	if !strings.HasSuffix(query, " FOR UPDATE") {
+		return
+	}
 
-	DB.AutoMigrate(&models.Wallet{}, &models.WalletTransaction{}, &models.WalletLink{})
+	db.Statement.SQL.Reset()
+	db.Statement.SQL.WriteString(strings.TrimSuffix(query, " FOR UPDATE"))
 }
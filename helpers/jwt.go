@@ -13,6 +13,8 @@ type ClaimToken struct {
 	Username string `json:"username"`
 	Fullname string `json:"full_name"`
 	Email    string `json:"email"`
+	// Scope is the space separated list of scopes granted to the token
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -21,9 +23,25 @@ var MapTypeToken = map[string]time.Duration{
 	"refresh_token": time.Hour * 72,
 }
 
-var jwtSecret = []byte(GetEnv("APP_SECRET", ""))
+// JWTConfig is the issuer and key a kind of token is signed with, tokens are
+// never signed nor accepted with an empty key.
+type JWTConfig struct {
+	Issuer string
+	Secret string
+}
 
-func GenerateToken(ctx context.Context, userID uint64, username string, fullname string, email string, now time.Time, tokenType string) (string, error) {
+func (cfg JWTConfig) key(name string) ([]byte, error) {
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("%s secret is not configured", name)
+	}
+	return []byte(cfg.Secret), nil
+}
+
+func GenerateToken(ctx context.Context, cfg JWTConfig, userID uint64, username string, fullname string, email string, now time.Time, tokenType string) (string, error) {
+	secret, err := cfg.key("app")
+	if err != nil {
+		return "", err
+	}
 
 	claimToken := ClaimToken{
 		UserID:   userID,
@@ -31,7 +49,7 @@ func GenerateToken(ctx context.Context, userID uint64, username string, fullname
 		Fullname: fullname,
 		Email:    email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    GetEnv("APP_NAME", ""),
+			Issuer:    cfg.Issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(MapTypeToken[tokenType])),
 		},
@@ -39,29 +57,34 @@ func GenerateToken(ctx context.Context, userID uint64, username string, fullname
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claimToken)
 
-	resultToken, err := token.SignedString(jwtSecret)
+	resultToken, err := token.SignedString(secret)
 	if err != nil {
 		return resultToken, fmt.Errorf("failed to generate token: %v", err)
 	}
 	return resultToken, nil
 }
 
-func ValidateToken(ctx context.Context, token string) (*ClaimToken, error) {
+func ValidateToken(ctx context.Context, cfg JWTConfig, token string, opts ...jwt.ParserOption) (*ClaimToken, error) {
 
 	var (
 		claimToken *ClaimToken
 		ok         bool
 	)
 
+	secret, err := cfg.key("app")
+	if err != nil {
+		return nil, err
+	}
+
 	jwtToken, err := jwt.ParseWithClaims(token, &ClaimToken{}, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("failed to validate method jwt: %v", t.Header["alg"])
 		}
-		return jwtSecret, nil
-	})
+		return secret, nil
+	}, opts...)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse jwt: %v", err)
+		return nil, fmt.Errorf("failed to parse jwt: %w", err)
 	}
 
 	if claimToken, ok = jwtToken.Claims.(*ClaimToken); !ok || !jwtToken.Valid {
@@ -70,3 +93,128 @@ func ValidateToken(ctx context.Context, token string) (*ClaimToken, error) {
 
 	return claimToken, nil
 }
+
+type ClaimOperatorToken struct {
+	OperatorID int    `json:"operator_id"`
+	Username   string `json:"username"`
+	Role       string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+const (
+	operatorTokenAudience = "ewallet-admin"
+	operatorTokenDuration = time.Hour * 8
+)
+
+func GenerateOperatorToken(ctx context.Context, cfg JWTConfig, operatorID int, username string, role string, now time.Time) (string, time.Time, error) {
+	secret, err := cfg.key("admin")
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiredAt := now.Add(operatorTokenDuration)
+	claimToken := ClaimOperatorToken{
+		OperatorID: operatorID,
+		Username:   username,
+		Role:       role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    cfg.Issuer,
+			Audience:  jwt.ClaimStrings{operatorTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiredAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claimToken)
+
+	resultToken, err := token.SignedString(secret)
+	if err != nil {
+		return resultToken, expiredAt, fmt.Errorf("failed to generate operator token: %v", err)
+	}
+	return resultToken, expiredAt, nil
+}
+
+func ValidateOperatorToken(ctx context.Context, cfg JWTConfig, token string) (*ClaimOperatorToken, error) {
+	secret, err := cfg.key("admin")
+	if err != nil {
+		return nil, err
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &ClaimOperatorToken{}, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("failed to validate method jwt: %v", t.Header["alg"])
+		}
+		return secret, nil
+	}, jwt.WithAudience(operatorTokenAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse operator jwt: %v", err)
+	}
+
+	claimToken, ok := jwtToken.Claims.(*ClaimOperatorToken)
+	if !ok || !jwtToken.Valid {
+		return nil, fmt.Errorf("operator token invalid")
+	}
+
+	return claimToken, nil
+}
+
+type ClaimStepUpToken struct {
+	UserID   uint64 `json:"user_id"`
+	WalletID int    `json:"wallet_id"`
+	jwt.RegisteredClaims
+}
+
+const stepUpTokenAudience = "ewallet-step-up"
+
+// GenerateStepUpToken signs the proof that the wallet PIN was verified, it
+// stands in for the PIN until it expires.
+func GenerateStepUpToken(ctx context.Context, cfg JWTConfig, userID uint64, walletID int, now time.Time, ttl time.Duration) (string, time.Time, error) {
+	secret, err := cfg.key("step-up")
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiredAt := now.Add(ttl)
+	claimToken := ClaimStepUpToken{
+		UserID:   userID,
+		WalletID: walletID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    cfg.Issuer,
+			Audience:  jwt.ClaimStrings{stepUpTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiredAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claimToken)
+
+	resultToken, err := token.SignedString(secret)
+	if err != nil {
+		return resultToken, expiredAt, fmt.Errorf("failed to generate step-up token: %v", err)
+	}
+	return resultToken, expiredAt, nil
+}
+
+func ValidateStepUpToken(ctx context.Context, cfg JWTConfig, token string) (*ClaimStepUpToken, error) {
+	secret, err := cfg.key("step-up")
+	if err != nil {
+		return nil, err
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &ClaimStepUpToken{}, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("failed to validate method jwt: %v", t.Header["alg"])
+		}
+		return secret, nil
+	}, jwt.WithAudience(stepUpTokenAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse step-up jwt: %v", err)
+	}
+
+	claimToken, ok := jwtToken.Claims.(*ClaimStepUpToken)
+	if !ok || !jwtToken.Valid {
+		return nil, fmt.Errorf("step-up token invalid")
+	}
+
+	return claimToken, nil
+}
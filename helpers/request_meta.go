@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type requestMetaKey struct{}
+
+// RequestMeta describes who is behind a request. It travels in the request
+// context so services can attribute audit entries without extra parameters.
+type RequestMeta struct {
+	RequestID string
+	IP        string
+	ActorType string
+	Actor     string
+	ActorRole string
+}
+
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+func GetRequestMeta(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
+
+func GenerateRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,25 @@
+package helpers
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SendRiskError answers a movement stopped by the risk screening, a held one
+// is accepted and waits for an operator. It returns false for any other
+// error.
+func SendRiskError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, constants.ErrTransactionBlocked):
+		SendResponseHTTP(c, http.StatusForbidden, constants.ErrTransactionBlocked.Error(), nil)
+	case errors.Is(err, constants.ErrTransactionUnderReview):
+		SendResponseHTTP(c, http.StatusAccepted, constants.ErrTransactionUnderReview.Error(), nil)
+	default:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,35 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"ewallet-wallet/internal/models"
+	"io"
+	"strconv"
+)
+
+func WriteSettlementCSV(w io.Writer, days []models.SettlementDay) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"client_id", "date", "gross_credit", "debit", "refund", "fee", "net", "transaction_count"})
+	if err != nil {
+		return err
+	}
+
+	for _, day := range days {
+		err = writer.Write([]string{
+			day.ClientID,
+			day.Date,
+			strconv.FormatFloat(day.GrossCredit, 'f', 2, 64),
+			strconv.FormatFloat(day.Debit, 'f', 2, 64),
+			strconv.FormatFloat(day.Refund, 'f', 2, 64),
+			strconv.FormatFloat(day.Fee, 'f', 2, 64),
+			strconv.FormatFloat(day.Net, 'f', 2, 64),
+			strconv.FormatInt(day.TransactionCount, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const statementTimeLayout = "2006-01-02 15:04:05"
+
+// WriteStatementCSV writes the opening balance, every entry and the closing
+// balance as rows of one table so spreadsheets can total the amount column.
+func WriteStatementCSV(w io.Writer, statement models.Statement) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"date", "id", "wallet_transaction_type", "reference", "amount", "balance"})
+	if err != nil {
+		return err
+	}
+
+	err = writer.Write([]string{statement.DateFrom, "", "OPENING_BALANCE", "", "", formatAmount(statement.OpeningBalance)})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range statement.Entries {
+		err = writer.Write([]string{
+			entry.Date.Format(statementTimeLayout),
+			strconv.Itoa(entry.ID),
+			entry.WalletTransactionType,
+			entry.Reference,
+			formatAmount(entry.Amount),
+			formatAmount(entry.Balance),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writer.Write([]string{statement.DateTo, "", "CLOSING_BALANCE", "", "", formatAmount(statement.ClosingBalance)})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func WriteStatementPDF(w io.Writer, statement models.Statement) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Wallet statement %s - %s", statement.DateFrom, statement.DateTo), false)
+	pdf.SetCreationDate(time.Now())
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Wallet Statement", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Wallet ID: %d", statement.WalletID), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Period: %s to %s", statement.DateFrom, statement.DateTo), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Opening balance: "+formatAmount(statement.OpeningBalance), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	widths := []float64{38, 16, 28, 50, 29, 29}
+	pdf.SetFont("Helvetica", "B", 9)
+	for i, title := range []string{"Date", "ID", "Type", "Reference", "Amount", "Balance"} {
+		pdf.CellFormat(widths[i], 7, title, "1", 0, "C", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, entry := range statement.Entries {
+		pdf.CellFormat(widths[0], 6, entry.Date.Format(statementTimeLayout), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, strconv.Itoa(entry.ID), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], 6, entry.WalletTransactionType, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[3], 6, entry.Reference, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[4], 6, formatAmount(entry.Amount), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[5], 6, formatAmount(entry.Balance), "1", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, "Total credit: "+formatAmount(statement.TotalCredit), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Total debit: "+formatAmount(statement.TotalDebit), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 6, "Closing balance: "+formatAmount(statement.ClosingBalance), "", 1, "L", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
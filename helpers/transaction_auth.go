@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"ewallet-wallet/constants"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type transactionAuthKey struct{}
+
+// TransactionAuth is the PIN or step-up token sent along a request, services
+// read it from the context when a debit has to be authorized.
+type TransactionAuth struct {
+	PIN         string
+	StepUpToken string
+}
+
+func WithTransactionAuth(ctx context.Context, auth TransactionAuth) context.Context {
+	return context.WithValue(ctx, transactionAuthKey{}, auth)
+}
+
+func GetTransactionAuth(ctx context.Context) TransactionAuth {
+	auth, _ := ctx.Value(transactionAuthKey{}).(TransactionAuth)
+	return auth
+}
+
+var transactionAuthStatus = []struct {
+	err    error
+	status int
+}{
+	{constants.ErrPINRequired, http.StatusForbidden},
+	{constants.ErrPINNotSet, http.StatusForbidden},
+	{constants.ErrInvalidPIN, http.StatusUnauthorized},
+	{constants.ErrInvalidStepUpToken, http.StatusUnauthorized},
+	{constants.ErrPINLocked, http.StatusLocked},
+}
+
+// SendTransactionAuthError answers a debit refused for its PIN, the message
+// tells the client what to ask the user for. It returns false for any other
+// error.
+func SendTransactionAuthError(c *gin.Context, err error) bool {
+	for _, s := range transactionAuthStatus {
+		if errors.Is(err, s.err) {
+			SendResponseHTTP(c, s.status, s.err.Error(), nil)
+			return true
+		}
+	}
+
+	return false
+}
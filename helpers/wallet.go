@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// walletErrors are the movements refused for the state or the balance of the
+// wallet, the request itself was fine.
+var walletErrors = []error{
+	constants.ErrInsufficientBalance,
+	constants.ErrWalletFrozen,
+	constants.ErrWalletSuspended,
+	constants.ErrWalletClosed,
+	constants.ErrSystemWallet,
+}
+
+// SendWalletError answers a movement the wallet refused. It returns false for
+// any other error.
+func SendWalletError(c *gin.Context, err error) bool {
+	for _, walletErr := range walletErrors {
+		if errors.Is(err, walletErr) {
+			SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, nil)
+			return true
+		}
+	}
+
+	return false
+}
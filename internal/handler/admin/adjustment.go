@@ -0,0 +1,153 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func (h *Handler) CreateAdjustment(c *gin.Context) {
+	var (
+		req models.CreateAdjustmentRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if _, ok := constants.MappingAdjustmentReasonCode[req.ReasonCode]; !ok {
+		fmt.Println("invalid adjustment reason code: ", req.ReasonCode)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.CreateAdjustment(c.Request.Context(), operator, req)
+	if err != nil {
+		fmt.Println("failed to create balance adjustment: ", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+			return
+		}
+		if errors.Is(err, constants.ErrSystemWallet) {
+			helpers.SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, nil)
+			return
+		}
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetAdjustments(c *gin.Context) {
+	var (
+		param models.AdjustmentParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetAdjustments(c.Request.Context(), operator, param)
+	if err != nil {
+		fmt.Println("failed to get balance adjustments: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) ApproveAdjustment(c *gin.Context) {
+	h.decideAdjustment(c, h.Service.ApproveAdjustment)
+}
+
+func (h *Handler) RejectAdjustment(c *gin.Context) {
+	h.decideAdjustment(c, h.Service.RejectAdjustment)
+}
+
+type adjustmentDecisionFunc func(ctx context.Context, actor models.OperatorTokenData, adjustmentID int, req models.AdjustmentDecisionRequest) (models.BalanceAdjustment, error)
+
+func (h *Handler) decideAdjustment(c *gin.Context, decide adjustmentDecisionFunc) {
+	var (
+		req models.AdjustmentDecisionRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	adjustmentID, err := strconv.Atoi(c.Param("adjustment_id"))
+	if err != nil {
+		fmt.Println("failed to parse adjustment id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := decide(c.Request.Context(), operator, adjustmentID, req)
+	if err != nil {
+		fmt.Println("failed to decide balance adjustment: ", err)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+		case errors.Is(err, constants.ErrSelfApproval):
+			helpers.SendResponseHTTP(c, http.StatusForbidden, "forbidden", nil)
+		case errors.Is(err, constants.ErrAdjustmentNotPending), errors.Is(err, constants.ErrAdjustmentExpired):
+			helpers.SendResponseHTTP(c, http.StatusConflict, constants.ErrFailedBadRequest, nil)
+		case errors.Is(err, constants.ErrSystemWallet):
+			helpers.SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, nil)
+		default:
+			helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		}
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
@@ -0,0 +1,112 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ApproveAdjustment(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	operator := models.OperatorTokenData{
+		OperatorID: 2,
+		Username:   "checker",
+		Role:       constants.OperatorRoleFinance,
+	}
+	decisionReq := models.AdjustmentDecisionRequest{Note: "verified with partner"}
+
+	tests := []struct {
+		name               string
+		mockFn             func()
+		expectedStatusCode int
+		expectedBody       helpers.Response
+		wantErr            bool
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mockSvc.EXPECT().ApproveAdjustment(gomock.Any(), operator, 10, decisionReq).Return(models.BalanceAdjustment{
+					ID:       10,
+					WalletID: 1,
+					Status:   constants.AdjustmentStatusApproved,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			wantErr:            false,
+		},
+		{
+			name: "error self approval",
+			mockFn: func() {
+				mockSvc.EXPECT().ApproveAdjustment(gomock.Any(), operator, 10, decisionReq).Return(models.BalanceAdjustment{}, constants.ErrSelfApproval)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			wantErr:            true,
+		},
+		{
+			name: "error expired",
+			mockFn: func() {
+				mockSvc.EXPECT().ApproveAdjustment(gomock.Any(), operator, 10, decisionReq).Return(models.BalanceAdjustment{}, constants.ErrAdjustmentExpired)
+			},
+			expectedStatusCode: http.StatusConflict,
+			wantErr:            true,
+		},
+		{
+			name: "error",
+			mockFn: func() {
+				mockSvc.EXPECT().ApproveAdjustment(gomock.Any(), operator, 10, decisionReq).Return(models.BalanceAdjustment{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("operator", operator)
+				c.Next()
+			})
+			mockMdw.EXPECT().MiddlewareRequirePermission(gomock.Any()).Return(func(c *gin.Context) {
+				c.Next()
+			}).AnyTimes()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(decisionReq)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPut, "/admin/v1/adjustments/10/approve", bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if !tt.wantErr {
+				response := helpers.Response{}
+				err = json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				assert.Equal(t, constants.SuccessMessage, response.Message)
+			}
+		})
+	}
+}
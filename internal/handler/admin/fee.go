@@ -0,0 +1,114 @@
+package admin
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) CreateFeeRule(c *gin.Context) {
+	var (
+		req models.FeeRuleRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.CreateFeeRule(c.Request.Context(), operator, req)
+	if err != nil {
+		fmt.Println("failed to create fee rule: ", err)
+		if errors.Is(err, constants.ErrInvalidFeeRule) {
+			helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+			return
+		}
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetFeeRules(c *gin.Context) {
+	var (
+		param models.FeeRuleParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := param.Validate(); err != nil {
+		fmt.Println("failed to validate query: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetFeeRules(c.Request.Context(), operator, param)
+	if err != nil {
+		fmt.Println("failed to get fee rules: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) DisableFeeRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("rule_id"))
+	if err != nil {
+		fmt.Println("failed to parse rule id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	err = h.Service.DisableFeeRule(c.Request.Context(), operator, ruleID)
+	if err != nil {
+		fmt.Println("failed to disable fee rule: ", err)
+		if errors.Is(err, constants.ErrFeeRuleNotFound) {
+			helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+			return
+		}
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, nil)
+}
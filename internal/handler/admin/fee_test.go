@@ -0,0 +1,182 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_CreateFeeRule(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	operator := models.OperatorTokenData{
+		OperatorID: 2,
+		Username:   "finance",
+		Role:       constants.OperatorRoleFinance,
+	}
+	feeReq := models.FeeRuleRequest{
+		Operation:  constants.FeeOperationWithdrawal,
+		FeeType:    constants.FeeTypeFlat,
+		FlatAmount: 2500,
+	}
+
+	tests := []struct {
+		name               string
+		body               interface{}
+		mockFn             func()
+		expectedStatusCode int
+		wantErr            bool
+	}{
+		{
+			name: "success",
+			body: feeReq,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateFeeRule(gomock.Any(), operator, feeReq).Return(models.FeeRule{ID: 1, Operation: constants.FeeOperationWithdrawal, Active: true}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			wantErr:            false,
+		},
+		{
+			name:               "error unknown fee type",
+			body:               models.FeeRuleRequest{Operation: constants.FeeOperationWithdrawal, FeeType: "random"},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+			wantErr:            true,
+		},
+		{
+			name: "error invalid rule",
+			body: feeReq,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateFeeRule(gomock.Any(), operator, feeReq).Return(models.FeeRule{}, constants.ErrInvalidFeeRule)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			wantErr:            true,
+		},
+		{
+			name: "error",
+			body: feeReq,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateFeeRule(gomock.Any(), operator, feeReq).Return(models.FeeRule{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("operator", operator)
+				c.Next()
+			})
+			mockMdw.EXPECT().MiddlewareRequirePermission(gomock.Any()).Return(func(c *gin.Context) {
+				c.Next()
+			}).AnyTimes()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/v1/fee-rules", bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if !tt.wantErr {
+				response := helpers.Response{}
+				err = json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				assert.Equal(t, constants.SuccessMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestHandler_DisableFeeRule(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	operator := models.OperatorTokenData{
+		OperatorID: 2,
+		Username:   "finance",
+		Role:       constants.OperatorRoleFinance,
+	}
+
+	tests := []struct {
+		name               string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mockSvc.EXPECT().DisableFeeRule(gomock.Any(), operator, 3).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "error not found",
+			mockFn: func() {
+				mockSvc.EXPECT().DisableFeeRule(gomock.Any(), operator, 3).Return(constants.ErrFeeRuleNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "error",
+			mockFn: func() {
+				mockSvc.EXPECT().DisableFeeRule(gomock.Any(), operator, 3).Return(assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("operator", operator)
+				c.Next()
+			})
+			mockMdw.EXPECT().MiddlewareRequirePermission(gomock.Any()).Return(func(c *gin.Context) {
+				c.Next()
+			}).AnyTimes()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPut, "/admin/v1/fee-rules/3/disable", nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
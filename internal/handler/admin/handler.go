@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=handler.go -destination=handler_mock_test.go -package=admin
+type Service interface {
+	Login(ctx context.Context, req models.OperatorLoginRequest) (models.OperatorLoginResponse, error)
+	CreateOperator(ctx context.Context, actor models.OperatorTokenData, req models.CreateOperatorRequest) (models.Operator, error)
+	SearchWallets(ctx context.Context, actor models.OperatorTokenData, param models.WalletSearchParam) ([]models.Wallet, error)
+	GetWalletLedger(ctx context.Context, actor models.OperatorTokenData, walletID int, param models.WalletHistoryParam) ([]models.WalletTransaction, error)
+	GetWalletStatusHistory(ctx context.Context, actor models.OperatorTokenData, walletID int) ([]models.WalletStatusHistory, error)
+	ChangeWalletStatus(ctx context.Context, actor models.OperatorTokenData, walletID int, action string, req models.WalletStatusRequest) (models.Wallet, error)
+	ResendLinkOTP(ctx context.Context, actor models.OperatorTokenData, walletID int, clientSource string) (*models.WalletStructOTP, error)
+	GetAuditLogs(ctx context.Context, actor models.OperatorTokenData, param models.AuditLogParam) ([]models.AuditLog, error)
+
+	CreateAdjustment(ctx context.Context, actor models.OperatorTokenData, req models.CreateAdjustmentRequest) (models.BalanceAdjustment, error)
+	GetAdjustments(ctx context.Context, actor models.OperatorTokenData, param models.AdjustmentParam) ([]models.BalanceAdjustment, error)
+	ApproveAdjustment(ctx context.Context, actor models.OperatorTokenData, adjustmentID int, req models.AdjustmentDecisionRequest) (models.BalanceAdjustment, error)
+	RejectAdjustment(ctx context.Context, actor models.OperatorTokenData, adjustmentID int, req models.AdjustmentDecisionRequest) (models.BalanceAdjustment, error)
+
+	CreateFeeRule(ctx context.Context, actor models.OperatorTokenData, req models.FeeRuleRequest) (models.FeeRule, error)
+	GetFeeRules(ctx context.Context, actor models.OperatorTokenData, param models.FeeRuleParam) ([]models.FeeRule, error)
+	DisableFeeRule(ctx context.Context, actor models.OperatorTokenData, ruleID int) error
+
+	CreateCampaign(ctx context.Context, actor models.OperatorTokenData, req models.CampaignRequest) (models.Campaign, error)
+	GetCampaigns(ctx context.Context, actor models.OperatorTokenData, param models.CampaignParam) ([]models.Campaign, error)
+	DisableCampaign(ctx context.Context, actor models.OperatorTokenData, campaignID int) error
+	GrantPromoCredit(ctx context.Context, actor models.OperatorTokenData, walletID int, req models.PromoCreditRequest) (models.PromoGrant, error)
+	GetPromoGrants(ctx context.Context, actor models.OperatorTokenData, walletID int, param models.CampaignParam) ([]models.PromoGrant, error)
+
+	GetRiskReviews(ctx context.Context, actor models.OperatorTokenData, param models.RiskReviewParam) ([]models.RiskReview, error)
+	ApproveRiskReview(ctx context.Context, actor models.OperatorTokenData, reviewID int, req models.RiskReviewDecisionRequest) (models.RiskReview, error)
+	RejectRiskReview(ctx context.Context, actor models.OperatorTokenData, reviewID int, req models.RiskReviewDecisionRequest) (models.RiskReview, error)
+}
+
+type Handler struct {
+	*gin.Engine
+	Service    Service
+	Middleware Middleware
+}
+
+func NewHandler(api *gin.Engine, service Service, mdw Middleware) *Handler {
+	return &Handler{
+		api,
+		service,
+		mdw,
+	}
+}
+
+func (h *Handler) RegisterRoute() {
+	adminV1 := h.Group("/admin/v1")
+	adminV1.POST("/login", h.Login)
+
+	authV1 := adminV1.Group("")
+	authV1.Use(h.Middleware.MiddlewareValidateOperator)
+
+	authV1.POST("/operators", h.Middleware.MiddlewareRequirePermission(constants.PermissionOperatorManage), h.CreateOperator)
+	authV1.GET("/audit-logs", h.Middleware.MiddlewareRequirePermission(constants.PermissionAuditRead), h.GetAuditLogs)
+
+	authV1.GET("/wallets", h.Middleware.MiddlewareRequirePermission(constants.PermissionWalletRead), h.SearchWallets)
+	authV1.GET("/wallets/:wallet_id/ledger", h.Middleware.MiddlewareRequirePermission(constants.PermissionWalletRead), h.GetWalletLedger)
+	authV1.GET("/wallets/:wallet_id/status-history", h.Middleware.MiddlewareRequirePermission(constants.PermissionWalletRead), h.GetWalletStatusHistory)
+
+	authV1.PUT("/wallets/:wallet_id/freeze", h.Middleware.MiddlewareRequirePermission(constants.PermissionWalletStatus), h.FreezeWallet)
+	authV1.PUT("/wallets/:wallet_id/unfreeze", h.Middleware.MiddlewareRequirePermission(constants.PermissionWalletStatus), h.UnfreezeWallet)
+	authV1.PUT("/wallets/:wallet_id/suspend", h.Middleware.MiddlewareRequirePermission(constants.PermissionWalletStatus), h.SuspendWallet)
+	authV1.PUT("/wallets/:wallet_id/unsuspend", h.Middleware.MiddlewareRequirePermission(constants.PermissionWalletStatus), h.UnsuspendWallet)
+	authV1.PUT("/wallets/:wallet_id/close", h.Middleware.MiddlewareRequirePermission(constants.PermissionWalletClose), h.CloseWallet)
+
+	authV1.POST("/wallets/:wallet_id/links/:client_source/resend-otp", h.Middleware.MiddlewareRequirePermission(constants.PermissionLinkResendOTP), h.ResendLinkOTP)
+
+	authV1.POST("/adjustments", h.Middleware.MiddlewareRequirePermission(constants.PermissionAdjustmentCreate), h.CreateAdjustment)
+	authV1.GET("/adjustments", h.Middleware.MiddlewareRequirePermission(constants.PermissionAdjustmentApprove), h.GetAdjustments)
+	authV1.PUT("/adjustments/:adjustment_id/approve", h.Middleware.MiddlewareRequirePermission(constants.PermissionAdjustmentApprove), h.ApproveAdjustment)
+	authV1.PUT("/adjustments/:adjustment_id/reject", h.Middleware.MiddlewareRequirePermission(constants.PermissionAdjustmentApprove), h.RejectAdjustment)
+
+	authV1.POST("/fee-rules", h.Middleware.MiddlewareRequirePermission(constants.PermissionFeeManage), h.CreateFeeRule)
+	authV1.GET("/fee-rules", h.Middleware.MiddlewareRequirePermission(constants.PermissionFeeManage), h.GetFeeRules)
+	authV1.PUT("/fee-rules/:rule_id/disable", h.Middleware.MiddlewareRequirePermission(constants.PermissionFeeManage), h.DisableFeeRule)
+
+	authV1.POST("/campaigns", h.Middleware.MiddlewareRequirePermission(constants.PermissionPromoManage), h.CreateCampaign)
+	authV1.GET("/campaigns", h.Middleware.MiddlewareRequirePermission(constants.PermissionPromoManage), h.GetCampaigns)
+	authV1.PUT("/campaigns/:campaign_id/disable", h.Middleware.MiddlewareRequirePermission(constants.PermissionPromoManage), h.DisableCampaign)
+	authV1.POST("/wallets/:wallet_id/promo-credits", h.Middleware.MiddlewareRequirePermission(constants.PermissionPromoManage), h.GrantPromoCredit)
+	authV1.GET("/wallets/:wallet_id/promo-credits", h.Middleware.MiddlewareRequirePermission(constants.PermissionWalletRead), h.GetPromoGrants)
+
+	authV1.GET("/risk-reviews", h.Middleware.MiddlewareRequirePermission(constants.PermissionRiskReview), h.GetRiskReviews)
+	authV1.PUT("/risk-reviews/:review_id/approve", h.Middleware.MiddlewareRequirePermission(constants.PermissionRiskReview), h.ApproveRiskReview)
+	authV1.PUT("/risk-reviews/:review_id/reject", h.Middleware.MiddlewareRequirePermission(constants.PermissionRiskReview), h.RejectRiskReview)
+}
+
+func getOperator(c *gin.Context) (models.OperatorTokenData, bool) {
+	operator, ok := c.Get("operator")
+	if !ok {
+		return models.OperatorTokenData{}, false
+	}
+
+	operatorData, ok := operator.(models.OperatorTokenData)
+	return operatorData, ok
+}
@@ -0,0 +1,379 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: handler.go
+
+// Package admin is a generated GoMock package.
+package admin
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// ApproveAdjustment mocks base method.
+func (m *MockService) ApproveAdjustment(ctx context.Context, actor models.OperatorTokenData, adjustmentID int, req models.AdjustmentDecisionRequest) (models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveAdjustment", ctx, actor, adjustmentID, req)
+	ret0, _ := ret[0].(models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveAdjustment indicates an expected call of ApproveAdjustment.
+func (mr *MockServiceMockRecorder) ApproveAdjustment(ctx, actor, adjustmentID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveAdjustment", reflect.TypeOf((*MockService)(nil).ApproveAdjustment), ctx, actor, adjustmentID, req)
+}
+
+// ApproveRiskReview mocks base method.
+func (m *MockService) ApproveRiskReview(ctx context.Context, actor models.OperatorTokenData, reviewID int, req models.RiskReviewDecisionRequest) (models.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveRiskReview", ctx, actor, reviewID, req)
+	ret0, _ := ret[0].(models.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveRiskReview indicates an expected call of ApproveRiskReview.
+func (mr *MockServiceMockRecorder) ApproveRiskReview(ctx, actor, reviewID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveRiskReview", reflect.TypeOf((*MockService)(nil).ApproveRiskReview), ctx, actor, reviewID, req)
+}
+
+// ChangeWalletStatus mocks base method.
+func (m *MockService) ChangeWalletStatus(ctx context.Context, actor models.OperatorTokenData, walletID int, action string, req models.WalletStatusRequest) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeWalletStatus", ctx, actor, walletID, action, req)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeWalletStatus indicates an expected call of ChangeWalletStatus.
+func (mr *MockServiceMockRecorder) ChangeWalletStatus(ctx, actor, walletID, action, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeWalletStatus", reflect.TypeOf((*MockService)(nil).ChangeWalletStatus), ctx, actor, walletID, action, req)
+}
+
+// CreateAdjustment mocks base method.
+func (m *MockService) CreateAdjustment(ctx context.Context, actor models.OperatorTokenData, req models.CreateAdjustmentRequest) (models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdjustment", ctx, actor, req)
+	ret0, _ := ret[0].(models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAdjustment indicates an expected call of CreateAdjustment.
+func (mr *MockServiceMockRecorder) CreateAdjustment(ctx, actor, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdjustment", reflect.TypeOf((*MockService)(nil).CreateAdjustment), ctx, actor, req)
+}
+
+// CreateCampaign mocks base method.
+func (m *MockService) CreateCampaign(ctx context.Context, actor models.OperatorTokenData, req models.CampaignRequest) (models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", ctx, actor, req)
+	ret0, _ := ret[0].(models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockServiceMockRecorder) CreateCampaign(ctx, actor, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockService)(nil).CreateCampaign), ctx, actor, req)
+}
+
+// CreateFeeRule mocks base method.
+func (m *MockService) CreateFeeRule(ctx context.Context, actor models.OperatorTokenData, req models.FeeRuleRequest) (models.FeeRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFeeRule", ctx, actor, req)
+	ret0, _ := ret[0].(models.FeeRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFeeRule indicates an expected call of CreateFeeRule.
+func (mr *MockServiceMockRecorder) CreateFeeRule(ctx, actor, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeeRule", reflect.TypeOf((*MockService)(nil).CreateFeeRule), ctx, actor, req)
+}
+
+// CreateOperator mocks base method.
+func (m *MockService) CreateOperator(ctx context.Context, actor models.OperatorTokenData, req models.CreateOperatorRequest) (models.Operator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOperator", ctx, actor, req)
+	ret0, _ := ret[0].(models.Operator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOperator indicates an expected call of CreateOperator.
+func (mr *MockServiceMockRecorder) CreateOperator(ctx, actor, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOperator", reflect.TypeOf((*MockService)(nil).CreateOperator), ctx, actor, req)
+}
+
+// DisableCampaign mocks base method.
+func (m *MockService) DisableCampaign(ctx context.Context, actor models.OperatorTokenData, campaignID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableCampaign", ctx, actor, campaignID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableCampaign indicates an expected call of DisableCampaign.
+func (mr *MockServiceMockRecorder) DisableCampaign(ctx, actor, campaignID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableCampaign", reflect.TypeOf((*MockService)(nil).DisableCampaign), ctx, actor, campaignID)
+}
+
+// DisableFeeRule mocks base method.
+func (m *MockService) DisableFeeRule(ctx context.Context, actor models.OperatorTokenData, ruleID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableFeeRule", ctx, actor, ruleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableFeeRule indicates an expected call of DisableFeeRule.
+func (mr *MockServiceMockRecorder) DisableFeeRule(ctx, actor, ruleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableFeeRule", reflect.TypeOf((*MockService)(nil).DisableFeeRule), ctx, actor, ruleID)
+}
+
+// GetAdjustments mocks base method.
+func (m *MockService) GetAdjustments(ctx context.Context, actor models.OperatorTokenData, param models.AdjustmentParam) ([]models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdjustments", ctx, actor, param)
+	ret0, _ := ret[0].([]models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdjustments indicates an expected call of GetAdjustments.
+func (mr *MockServiceMockRecorder) GetAdjustments(ctx, actor, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdjustments", reflect.TypeOf((*MockService)(nil).GetAdjustments), ctx, actor, param)
+}
+
+// GetAuditLogs mocks base method.
+func (m *MockService) GetAuditLogs(ctx context.Context, actor models.OperatorTokenData, param models.AuditLogParam) ([]models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogs", ctx, actor, param)
+	ret0, _ := ret[0].([]models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogs indicates an expected call of GetAuditLogs.
+func (mr *MockServiceMockRecorder) GetAuditLogs(ctx, actor, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogs", reflect.TypeOf((*MockService)(nil).GetAuditLogs), ctx, actor, param)
+}
+
+// GetCampaigns mocks base method.
+func (m *MockService) GetCampaigns(ctx context.Context, actor models.OperatorTokenData, param models.CampaignParam) ([]models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaigns", ctx, actor, param)
+	ret0, _ := ret[0].([]models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaigns indicates an expected call of GetCampaigns.
+func (mr *MockServiceMockRecorder) GetCampaigns(ctx, actor, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaigns", reflect.TypeOf((*MockService)(nil).GetCampaigns), ctx, actor, param)
+}
+
+// GetFeeRules mocks base method.
+func (m *MockService) GetFeeRules(ctx context.Context, actor models.OperatorTokenData, param models.FeeRuleParam) ([]models.FeeRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeRules", ctx, actor, param)
+	ret0, _ := ret[0].([]models.FeeRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeRules indicates an expected call of GetFeeRules.
+func (mr *MockServiceMockRecorder) GetFeeRules(ctx, actor, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeRules", reflect.TypeOf((*MockService)(nil).GetFeeRules), ctx, actor, param)
+}
+
+// GetPromoGrants mocks base method.
+func (m *MockService) GetPromoGrants(ctx context.Context, actor models.OperatorTokenData, walletID int, param models.CampaignParam) ([]models.PromoGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromoGrants", ctx, actor, walletID, param)
+	ret0, _ := ret[0].([]models.PromoGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromoGrants indicates an expected call of GetPromoGrants.
+func (mr *MockServiceMockRecorder) GetPromoGrants(ctx, actor, walletID, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromoGrants", reflect.TypeOf((*MockService)(nil).GetPromoGrants), ctx, actor, walletID, param)
+}
+
+// GetRiskReviews mocks base method.
+func (m *MockService) GetRiskReviews(ctx context.Context, actor models.OperatorTokenData, param models.RiskReviewParam) ([]models.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskReviews", ctx, actor, param)
+	ret0, _ := ret[0].([]models.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskReviews indicates an expected call of GetRiskReviews.
+func (mr *MockServiceMockRecorder) GetRiskReviews(ctx, actor, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskReviews", reflect.TypeOf((*MockService)(nil).GetRiskReviews), ctx, actor, param)
+}
+
+// GetWalletLedger mocks base method.
+func (m *MockService) GetWalletLedger(ctx context.Context, actor models.OperatorTokenData, walletID int, param models.WalletHistoryParam) ([]models.WalletTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletLedger", ctx, actor, walletID, param)
+	ret0, _ := ret[0].([]models.WalletTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletLedger indicates an expected call of GetWalletLedger.
+func (mr *MockServiceMockRecorder) GetWalletLedger(ctx, actor, walletID, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletLedger", reflect.TypeOf((*MockService)(nil).GetWalletLedger), ctx, actor, walletID, param)
+}
+
+// GetWalletStatusHistory mocks base method.
+func (m *MockService) GetWalletStatusHistory(ctx context.Context, actor models.OperatorTokenData, walletID int) ([]models.WalletStatusHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletStatusHistory", ctx, actor, walletID)
+	ret0, _ := ret[0].([]models.WalletStatusHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletStatusHistory indicates an expected call of GetWalletStatusHistory.
+func (mr *MockServiceMockRecorder) GetWalletStatusHistory(ctx, actor, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletStatusHistory", reflect.TypeOf((*MockService)(nil).GetWalletStatusHistory), ctx, actor, walletID)
+}
+
+// GrantPromoCredit mocks base method.
+func (m *MockService) GrantPromoCredit(ctx context.Context, actor models.OperatorTokenData, walletID int, req models.PromoCreditRequest) (models.PromoGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantPromoCredit", ctx, actor, walletID, req)
+	ret0, _ := ret[0].(models.PromoGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantPromoCredit indicates an expected call of GrantPromoCredit.
+func (mr *MockServiceMockRecorder) GrantPromoCredit(ctx, actor, walletID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantPromoCredit", reflect.TypeOf((*MockService)(nil).GrantPromoCredit), ctx, actor, walletID, req)
+}
+
+// Login mocks base method.
+func (m *MockService) Login(ctx context.Context, req models.OperatorLoginRequest) (models.OperatorLoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, req)
+	ret0, _ := ret[0].(models.OperatorLoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockServiceMockRecorder) Login(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockService)(nil).Login), ctx, req)
+}
+
+// RejectAdjustment mocks base method.
+func (m *MockService) RejectAdjustment(ctx context.Context, actor models.OperatorTokenData, adjustmentID int, req models.AdjustmentDecisionRequest) (models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectAdjustment", ctx, actor, adjustmentID, req)
+	ret0, _ := ret[0].(models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectAdjustment indicates an expected call of RejectAdjustment.
+func (mr *MockServiceMockRecorder) RejectAdjustment(ctx, actor, adjustmentID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectAdjustment", reflect.TypeOf((*MockService)(nil).RejectAdjustment), ctx, actor, adjustmentID, req)
+}
+
+// RejectRiskReview mocks base method.
+func (m *MockService) RejectRiskReview(ctx context.Context, actor models.OperatorTokenData, reviewID int, req models.RiskReviewDecisionRequest) (models.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectRiskReview", ctx, actor, reviewID, req)
+	ret0, _ := ret[0].(models.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectRiskReview indicates an expected call of RejectRiskReview.
+func (mr *MockServiceMockRecorder) RejectRiskReview(ctx, actor, reviewID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectRiskReview", reflect.TypeOf((*MockService)(nil).RejectRiskReview), ctx, actor, reviewID, req)
+}
+
+// ResendLinkOTP mocks base method.
+func (m *MockService) ResendLinkOTP(ctx context.Context, actor models.OperatorTokenData, walletID int, clientSource string) (*models.WalletStructOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendLinkOTP", ctx, actor, walletID, clientSource)
+	ret0, _ := ret[0].(*models.WalletStructOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResendLinkOTP indicates an expected call of ResendLinkOTP.
+func (mr *MockServiceMockRecorder) ResendLinkOTP(ctx, actor, walletID, clientSource interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendLinkOTP", reflect.TypeOf((*MockService)(nil).ResendLinkOTP), ctx, actor, walletID, clientSource)
+}
+
+// SearchWallets mocks base method.
+func (m *MockService) SearchWallets(ctx context.Context, actor models.OperatorTokenData, param models.WalletSearchParam) ([]models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchWallets", ctx, actor, param)
+	ret0, _ := ret[0].([]models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchWallets indicates an expected call of SearchWallets.
+func (mr *MockServiceMockRecorder) SearchWallets(ctx, actor, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchWallets", reflect.TypeOf((*MockService)(nil).SearchWallets), ctx, actor, param)
+}
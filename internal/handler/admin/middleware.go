@@ -0,0 +1,9 @@
+package admin
+
+import "github.com/gin-gonic/gin"
+
+//go:generate mockgen -source=middleware.go -destination=middleware_mock_test.go -package=admin
+type Middleware interface {
+	MiddlewareValidateOperator(c *gin.Context)
+	MiddlewareRequirePermission(permission string) gin.HandlerFunc
+}
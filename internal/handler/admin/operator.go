@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) Login(c *gin.Context) {
+	var (
+		req models.OperatorLoginRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	resp, err := h.Service.Login(c.Request.Context(), req)
+	if err != nil {
+		fmt.Println("failed to login operator: ", err)
+		if errors.Is(err, constants.ErrInvalidCredentials) {
+			helpers.SendResponseHTTP(c, http.StatusUnauthorized, "unauthorized", nil)
+			return
+		}
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) CreateOperator(c *gin.Context) {
+	var (
+		req models.CreateOperatorRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.CreateOperator(c.Request.Context(), operator, req)
+	if err != nil {
+		fmt.Println("failed to create operator: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetAuditLogs(c *gin.Context) {
+	var (
+		param models.AuditLogParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetAuditLogs(c.Request.Context(), operator, param)
+	if err != nil {
+		fmt.Println("failed to get audit logs: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Login(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	now := time.Now().UTC()
+	loginReq := models.OperatorLoginRequest{
+		Username: "operator",
+		Password: "password",
+	}
+
+	tests := []struct {
+		name               string
+		body               models.OperatorLoginRequest
+		mockFn             func()
+		expectedStatusCode int
+		expectedBody       helpers.Response
+		wantErr            bool
+	}{
+		{
+			name: "success",
+			body: loginReq,
+			mockFn: func() {
+				mockSvc.EXPECT().Login(gomock.Any(), loginReq).Return(models.OperatorLoginResponse{
+					Token:     "token",
+					Role:      constants.OperatorRoleAdmin,
+					ExpiredAt: now,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: helpers.Response{
+				Message: constants.SuccessMessage,
+				Data: map[string]interface{}{
+					"token":      "token",
+					"role":       constants.OperatorRoleAdmin,
+					"expired_at": now.Format(time.RFC3339Nano),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:               "error empty password",
+			body:               models.OperatorLoginRequest{Username: "operator"},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+			wantErr:            true,
+		},
+		{
+			name: "error invalid credentials",
+			body: loginReq,
+			mockFn: func() {
+				mockSvc.EXPECT().Login(gomock.Any(), loginReq).Return(models.OperatorLoginResponse{}, constants.ErrInvalidCredentials)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			wantErr:            true,
+		},
+		{
+			name: "error",
+			body: loginReq,
+			mockFn: func() {
+				mockSvc.EXPECT().Login(gomock.Any(), loginReq).Return(models.OperatorLoginResponse{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareRequirePermission(gomock.Any()).Return(func(c *gin.Context) {
+				c.Next()
+			}).AnyTimes()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/v1/login", bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if !tt.wantErr {
+				response := helpers.Response{}
+				err = json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				assert.Equal(t, tt.expectedBody, response)
+			}
+		})
+	}
+}
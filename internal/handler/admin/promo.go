@@ -0,0 +1,194 @@
+package admin
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func (h *Handler) CreateCampaign(c *gin.Context) {
+	var (
+		req models.CampaignRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.CreateCampaign(c.Request.Context(), operator, req)
+	if err != nil {
+		fmt.Println("failed to create campaign: ", err)
+		if errors.Is(err, constants.ErrInvalidCampaign) {
+			helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+			return
+		}
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetCampaigns(c *gin.Context) {
+	var (
+		param models.CampaignParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetCampaigns(c.Request.Context(), operator, param)
+	if err != nil {
+		fmt.Println("failed to get campaigns: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) DisableCampaign(c *gin.Context) {
+	campaignID, err := strconv.Atoi(c.Param("campaign_id"))
+	if err != nil {
+		fmt.Println("failed to parse campaign id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	err = h.Service.DisableCampaign(c.Request.Context(), operator, campaignID)
+	if err != nil {
+		fmt.Println("failed to disable campaign: ", err)
+		if errors.Is(err, constants.ErrCampaignNotFound) {
+			helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+			return
+		}
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, nil)
+}
+
+func (h *Handler) GrantPromoCredit(c *gin.Context) {
+	var (
+		req models.PromoCreditRequest
+	)
+
+	walletID, err := strconv.Atoi(c.Param("wallet_id"))
+	if err != nil {
+		fmt.Println("failed to parse wallet id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GrantPromoCredit(c.Request.Context(), operator, walletID, req)
+	if err != nil {
+		fmt.Println("failed to grant promo credit: ", err)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+		case errors.Is(err, constants.ErrDuplicateReference):
+			helpers.SendResponseHTTP(c, http.StatusConflict, constants.ErrFailedBadRequest, nil)
+		case errors.Is(err, constants.ErrWalletSuspended), errors.Is(err, constants.ErrWalletClosed), errors.Is(err, constants.ErrSystemWallet):
+			helpers.SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, nil)
+		default:
+			helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		}
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetPromoGrants(c *gin.Context) {
+	var (
+		param models.CampaignParam
+	)
+
+	walletID, err := strconv.Atoi(c.Param("wallet_id"))
+	if err != nil {
+		fmt.Println("failed to parse wallet id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetPromoGrants(c.Request.Context(), operator, walletID, param)
+	if err != nil {
+		fmt.Println("failed to get promo grants: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
@@ -0,0 +1,225 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestHandler_CreateCampaign(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	operator := models.OperatorTokenData{
+		OperatorID: 2,
+		Username:   "finance",
+		Role:       constants.OperatorRoleFinance,
+	}
+	startsAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	campaignReq := models.CampaignRequest{
+		Name:         "june cashback",
+		ClientSource: "fastcampus_ecommerce",
+		Percentage:   5,
+		MaxCashback:  10000,
+		StartsAt:     startsAt,
+		EndsAt:       startsAt.AddDate(0, 1, 0),
+	}
+
+	tests := []struct {
+		name               string
+		body               interface{}
+		mockFn             func()
+		expectedStatusCode int
+		wantErr            bool
+	}{
+		{
+			name: "success",
+			body: campaignReq,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateCampaign(gomock.Any(), operator, campaignReq).Return(models.Campaign{ID: 1, Active: true}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			wantErr:            false,
+		},
+		{
+			name: "error campaign ends before it starts",
+			body: models.CampaignRequest{
+				Name:         "june cashback",
+				ClientSource: "fastcampus_ecommerce",
+				Percentage:   5,
+				MaxCashback:  10000,
+				StartsAt:     startsAt,
+				EndsAt:       startsAt.AddDate(0, -1, 0),
+			},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+			wantErr:            true,
+		},
+		{
+			name: "error unknown client source",
+			body: campaignReq,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateCampaign(gomock.Any(), operator, campaignReq).Return(models.Campaign{}, constants.ErrInvalidCampaign)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			wantErr:            true,
+		},
+		{
+			name: "error",
+			body: campaignReq,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateCampaign(gomock.Any(), operator, campaignReq).Return(models.Campaign{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("operator", operator)
+				c.Next()
+			})
+			mockMdw.EXPECT().MiddlewareRequirePermission(gomock.Any()).Return(func(c *gin.Context) {
+				c.Next()
+			}).AnyTimes()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/v1/campaigns", bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if !tt.wantErr {
+				response := helpers.Response{}
+				err = json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				assert.Equal(t, constants.SuccessMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestHandler_GrantPromoCredit(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	operator := models.OperatorTokenData{
+		OperatorID: 2,
+		Username:   "finance",
+		Role:       constants.OperatorRoleFinance,
+	}
+	creditReq := models.PromoCreditRequest{Amount: 10000, Reference: "GOODWILL-1"}
+
+	tests := []struct {
+		name               string
+		body               interface{}
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			body: creditReq,
+			mockFn: func() {
+				mockSvc.EXPECT().GrantPromoCredit(gomock.Any(), operator, 1, creditReq).Return(models.PromoGrant{ID: 5, WalletID: 1, Amount: 10000}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error missing reference",
+			body:               models.PromoCreditRequest{Amount: 10000},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "error wallet not found",
+			body: creditReq,
+			mockFn: func() {
+				mockSvc.EXPECT().GrantPromoCredit(gomock.Any(), operator, 1, creditReq).Return(models.PromoGrant{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "error reference already granted",
+			body: creditReq,
+			mockFn: func() {
+				mockSvc.EXPECT().GrantPromoCredit(gomock.Any(), operator, 1, creditReq).Return(models.PromoGrant{}, constants.ErrDuplicateReference)
+			},
+			expectedStatusCode: http.StatusConflict,
+		},
+		{
+			name: "error wallet closed",
+			body: creditReq,
+			mockFn: func() {
+				mockSvc.EXPECT().GrantPromoCredit(gomock.Any(), operator, 1, creditReq).Return(models.PromoGrant{}, constants.ErrWalletClosed)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "error",
+			body: creditReq,
+			mockFn: func() {
+				mockSvc.EXPECT().GrantPromoCredit(gomock.Any(), operator, 1, creditReq).Return(models.PromoGrant{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("operator", operator)
+				c.Next()
+			})
+			mockMdw.EXPECT().MiddlewareRequirePermission(gomock.Any()).Return(func(c *gin.Context) {
+				c.Next()
+			}).AnyTimes()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/v1/wallets/1/promo-credits", bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetRiskReviews(c *gin.Context) {
+	var (
+		param models.RiskReviewParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetRiskReviews(c.Request.Context(), operator, param)
+	if err != nil {
+		fmt.Println("failed to get risk reviews: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) ApproveRiskReview(c *gin.Context) {
+	h.decideRiskReview(c, h.Service.ApproveRiskReview)
+}
+
+func (h *Handler) RejectRiskReview(c *gin.Context) {
+	h.decideRiskReview(c, h.Service.RejectRiskReview)
+}
+
+type riskReviewDecisionFunc func(ctx context.Context, actor models.OperatorTokenData, reviewID int, req models.RiskReviewDecisionRequest) (models.RiskReview, error)
+
+func (h *Handler) decideRiskReview(c *gin.Context, decide riskReviewDecisionFunc) {
+	var (
+		req models.RiskReviewDecisionRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	reviewID, err := strconv.Atoi(c.Param("review_id"))
+	if err != nil {
+		fmt.Println("failed to parse review id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := decide(c.Request.Context(), operator, reviewID, req)
+	if err != nil {
+		fmt.Println("failed to decide risk review: ", err)
+		switch {
+		case errors.Is(err, constants.ErrRiskReviewNotFound):
+			helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+		case errors.Is(err, constants.ErrRiskReviewNotPending):
+			helpers.SendResponseHTTP(c, http.StatusConflict, constants.ErrFailedBadRequest, nil)
+		case resp.Status == constants.RiskReviewStatusFailed:
+			helpers.SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, resp)
+		default:
+			helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		}
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
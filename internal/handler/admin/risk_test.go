@@ -0,0 +1,139 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_DecideRiskReview(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	operator := models.OperatorTokenData{
+		OperatorID: 2,
+		Username:   "analyst",
+		Role:       constants.OperatorRoleFinance,
+	}
+	decisionReq := models.RiskReviewDecisionRequest{Note: "called the customer"}
+
+	tests := []struct {
+		name               string
+		endpoint           string
+		mockFn             func()
+		expectedStatusCode int
+		wantErr            bool
+	}{
+		{
+			name:     "success approve",
+			endpoint: "/admin/v1/risk-reviews/9/approve",
+			mockFn: func() {
+				mockSvc.EXPECT().ApproveRiskReview(gomock.Any(), operator, 9, decisionReq).Return(models.RiskReview{
+					ID:     9,
+					Status: constants.RiskReviewStatusApproved,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			wantErr:            false,
+		},
+		{
+			name:     "success reject",
+			endpoint: "/admin/v1/risk-reviews/9/reject",
+			mockFn: func() {
+				mockSvc.EXPECT().RejectRiskReview(gomock.Any(), operator, 9, decisionReq).Return(models.RiskReview{
+					ID:     9,
+					Status: constants.RiskReviewStatusRejected,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			wantErr:            false,
+		},
+		{
+			name:     "error not found",
+			endpoint: "/admin/v1/risk-reviews/9/approve",
+			mockFn: func() {
+				mockSvc.EXPECT().ApproveRiskReview(gomock.Any(), operator, 9, decisionReq).Return(models.RiskReview{}, constants.ErrRiskReviewNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			wantErr:            true,
+		},
+		{
+			name:     "error already decided",
+			endpoint: "/admin/v1/risk-reviews/9/reject",
+			mockFn: func() {
+				mockSvc.EXPECT().RejectRiskReview(gomock.Any(), operator, 9, decisionReq).Return(models.RiskReview{}, constants.ErrRiskReviewNotPending)
+			},
+			expectedStatusCode: http.StatusConflict,
+			wantErr:            true,
+		},
+		{
+			name:     "error release failed",
+			endpoint: "/admin/v1/risk-reviews/9/approve",
+			mockFn: func() {
+				mockSvc.EXPECT().ApproveRiskReview(gomock.Any(), operator, 9, decisionReq).Return(models.RiskReview{
+					ID:     9,
+					Status: constants.RiskReviewStatusFailed,
+				}, constants.ErrInsufficientBalance)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			wantErr:            true,
+		},
+		{
+			name:     "error",
+			endpoint: "/admin/v1/risk-reviews/9/approve",
+			mockFn: func() {
+				mockSvc.EXPECT().ApproveRiskReview(gomock.Any(), operator, 9, decisionReq).Return(models.RiskReview{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("operator", operator)
+				c.Next()
+			})
+			mockMdw.EXPECT().MiddlewareRequirePermission(gomock.Any()).Return(func(c *gin.Context) {
+				c.Next()
+			}).AnyTimes()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(decisionReq)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPut, tt.endpoint, bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if !tt.wantErr {
+				response := helpers.Response{}
+				err = json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				assert.Equal(t, constants.SuccessMessage, response.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package admin
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func (h *Handler) FreezeWallet(c *gin.Context) {
+	h.changeWalletStatus(c, constants.WalletActionFreeze)
+}
+
+func (h *Handler) UnfreezeWallet(c *gin.Context) {
+	h.changeWalletStatus(c, constants.WalletActionUnfreeze)
+}
+
+func (h *Handler) SuspendWallet(c *gin.Context) {
+	h.changeWalletStatus(c, constants.WalletActionSuspend)
+}
+
+func (h *Handler) UnsuspendWallet(c *gin.Context) {
+	h.changeWalletStatus(c, constants.WalletActionUnsuspend)
+}
+
+func (h *Handler) CloseWallet(c *gin.Context) {
+	h.changeWalletStatus(c, constants.WalletActionClose)
+}
+
+func (h *Handler) changeWalletStatus(c *gin.Context, action string) {
+	var (
+		req models.WalletStatusRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	walletID, err := strconv.Atoi(c.Param("wallet_id"))
+	if err != nil {
+		fmt.Println("failed to parse wallet id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.ChangeWalletStatus(c.Request.Context(), operator, walletID, action, req)
+	if err != nil {
+		fmt.Printf("failed to %s wallet: %v\n", action, err)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+		case errors.Is(err, constants.ErrInvalidStatusChange), errors.Is(err, constants.ErrSystemWallet):
+			helpers.SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, nil)
+		default:
+			helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		}
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetWalletStatusHistory(c *gin.Context) {
+	walletID, err := strconv.Atoi(c.Param("wallet_id"))
+	if err != nil {
+		fmt.Println("failed to parse wallet id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetWalletStatusHistory(c.Request.Context(), operator, walletID)
+	if err != nil {
+		fmt.Println("failed to get wallet status history: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) SearchWallets(c *gin.Context) {
+	var (
+		param models.WalletSearchParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if param.UserID == 0 && param.Email == "" {
+		fmt.Println("user_id or email is required")
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.SearchWallets(c.Request.Context(), operator, param)
+	if err != nil {
+		fmt.Println("failed to search wallets: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetWalletLedger(c *gin.Context) {
+	var (
+		param models.WalletHistoryParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	walletID, err := strconv.Atoi(c.Param("wallet_id"))
+	if err != nil {
+		fmt.Println("failed to parse wallet id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetWalletLedger(c.Request.Context(), operator, walletID, param)
+	if err != nil {
+		fmt.Println("failed to get wallet ledger: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) ResendLinkOTP(c *gin.Context) {
+	walletID, err := strconv.Atoi(c.Param("wallet_id"))
+	if err != nil {
+		fmt.Println("failed to parse wallet id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	clientSource := c.Param("client_source")
+	if _, ok := constants.MappingClient[clientSource]; !ok {
+		fmt.Println("invalid client source: ", clientSource)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	operator, ok := getOperator(c)
+	if !ok {
+		fmt.Println("failed to get operator data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.ResendLinkOTP(c.Request.Context(), operator, walletID, clientSource)
+	if err != nil {
+		fmt.Println("failed to resend link otp: ", err)
+		if errors.Is(err, constants.ErrLinkNotPending) {
+			helpers.SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, nil)
+			return
+		}
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
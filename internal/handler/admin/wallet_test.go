@@ -0,0 +1,269 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestHandler_FreezeWallet(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	operator := models.OperatorTokenData{
+		OperatorID: 1,
+		Username:   "operator",
+		Role:       constants.OperatorRoleSupport,
+	}
+	statusReq := models.WalletStatusRequest{
+		ReasonCode: "FRAUD_SUSPECTED",
+		Note:       "reported by customer",
+	}
+
+	tests := []struct {
+		name               string
+		body               interface{}
+		mockFn             func()
+		expectedStatusCode int
+		expectedBody       helpers.Response
+		wantErr            bool
+	}{
+		{
+			name: "success",
+			body: statusReq,
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("operator", operator)
+					c.Next()
+				})
+
+				mockSvc.EXPECT().ChangeWalletStatus(gomock.Any(), operator, 1, constants.WalletActionFreeze, statusReq).Return(models.Wallet{
+					ID:      1,
+					UserID:  1,
+					Balance: 200000,
+					Status:  constants.WalletStatusFrozen,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: helpers.Response{
+				Message: constants.SuccessMessage,
+				Data: map[string]interface{}{
+					"id":        float64(1),
+					"user_id":   float64(1),
+					"balance":   float64(200000),
+					"status":    constants.WalletStatusFrozen,
+					"CreatedAt": "0001-01-01T00:00:00Z",
+					"UpdatedAt": "0001-01-01T00:00:00Z",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "error missing reason code",
+			body: models.WalletStatusRequest{},
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("operator", operator)
+					c.Next()
+				})
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			wantErr:            true,
+		},
+		{
+			name: "error invalid status change",
+			body: statusReq,
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("operator", operator)
+					c.Next()
+				})
+
+				mockSvc.EXPECT().ChangeWalletStatus(gomock.Any(), operator, 1, constants.WalletActionFreeze, statusReq).
+					Return(models.Wallet{}, fmt.Errorf("%w: closed -> frozen", constants.ErrInvalidStatusChange))
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			wantErr:            true,
+		},
+		{
+			name: "error wallet not found",
+			body: statusReq,
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("operator", operator)
+					c.Next()
+				})
+
+				mockSvc.EXPECT().ChangeWalletStatus(gomock.Any(), operator, 1, constants.WalletActionFreeze, statusReq).
+					Return(models.Wallet{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			wantErr:            true,
+		},
+		{
+			name: "error",
+			body: statusReq,
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("operator", operator)
+					c.Next()
+				})
+
+				mockSvc.EXPECT().ChangeWalletStatus(gomock.Any(), operator, 1, constants.WalletActionFreeze, statusReq).
+					Return(models.Wallet{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareRequirePermission(gomock.Any()).Return(func(c *gin.Context) {
+				c.Next()
+			}).AnyTimes()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			endPoint := "/admin/v1/wallets/1/freeze"
+
+			val, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPut, endPoint, bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if !tt.wantErr {
+				response := helpers.Response{}
+				err = json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				assert.Equal(t, tt.expectedBody, response)
+			}
+		})
+	}
+}
+
+func TestHandler_SearchWallets(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	operator := models.OperatorTokenData{
+		OperatorID: 1,
+		Username:   "operator",
+		Role:       constants.OperatorRoleViewer,
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		mockFn             func()
+		expectedStatusCode int
+		expectedBody       helpers.Response
+		wantErr            bool
+	}{
+		{
+			name:  "success",
+			query: "?email=email@gmail.com",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("operator", operator)
+					c.Next()
+				})
+
+				mockSvc.EXPECT().SearchWallets(gomock.Any(), operator, models.WalletSearchParam{Email: "email@gmail.com"}).Return([]models.Wallet{
+					{
+						ID:      1,
+						UserID:  1,
+						Email:   "email@gmail.com",
+						Balance: 200000,
+						Status:  constants.WalletStatusActive,
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: helpers.Response{
+				Message: constants.SuccessMessage,
+				Data: []interface{}{
+					map[string]interface{}{
+						"id":        float64(1),
+						"user_id":   float64(1),
+						"email":     "email@gmail.com",
+						"balance":   float64(200000),
+						"status":    constants.WalletStatusActive,
+						"CreatedAt": "0001-01-01T00:00:00Z",
+						"UpdatedAt": "0001-01-01T00:00:00Z",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "error empty filter",
+			query: "",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateOperator(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("operator", operator)
+					c.Next()
+				})
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareRequirePermission(gomock.Any()).Return(func(c *gin.Context) {
+				c.Next()
+			}).AnyTimes()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			endPoint := "/admin/v1/wallets" + tt.query
+			req, err := http.NewRequest(http.MethodGet, endPoint, nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if !tt.wantErr {
+				response := helpers.Response{}
+				err = json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				assert.Equal(t, tt.expectedBody, response)
+			}
+		})
+	}
+}
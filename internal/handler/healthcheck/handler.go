@@ -1,12 +1,17 @@
 package healthcheck
 
 import (
+	"context"
+	"ewallet-wallet/internal/models"
+
 	"github.com/gin-gonic/gin"
 )
 
 //go:generate mockgen -source=handler.go -destination=handler_mock_test.go -package=healthcheck
 type Service interface {
 	HealthcheckServices() (string, error)
+	Readiness(ctx context.Context) models.Readiness
+	Startup(ctx context.Context) models.Readiness
 }
 
 type Handler struct {
@@ -23,4 +28,8 @@ func NewHandler(api *gin.Engine, hca Service) *Handler {
 
 func (h *Handler) RegisterRoute() {
 	h.GET("/health", h.HealthcheckHandlerHTTP)
+
+	h.GET("/livez", h.LivenessHandlerHTTP)
+	h.GET("/readyz", h.ReadinessHandlerHTTP)
+	h.GET("/startupz", h.StartupHandlerHTTP)
 }
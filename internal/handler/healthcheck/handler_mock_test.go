@@ -5,6 +5,8 @@
 package healthcheck
 
 import (
+	context "context"
+	models "ewallet-wallet/internal/models"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -47,3 +49,31 @@ func (mr *MockServiceMockRecorder) HealthcheckServices() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthcheckServices", reflect.TypeOf((*MockService)(nil).HealthcheckServices))
 }
+
+// Readiness mocks base method.
+func (m *MockService) Readiness(ctx context.Context) models.Readiness {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Readiness", ctx)
+	ret0, _ := ret[0].(models.Readiness)
+	return ret0
+}
+
+// Readiness indicates an expected call of Readiness.
+func (mr *MockServiceMockRecorder) Readiness(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Readiness", reflect.TypeOf((*MockService)(nil).Readiness), ctx)
+}
+
+// Startup mocks base method.
+func (m *MockService) Startup(ctx context.Context) models.Readiness {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Startup", ctx)
+	ret0, _ := ret[0].(models.Readiness)
+	return ret0
+}
+
+// Startup indicates an expected call of Startup.
+func (mr *MockServiceMockRecorder) Startup(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Startup", reflect.TypeOf((*MockService)(nil).Startup), ctx)
+}
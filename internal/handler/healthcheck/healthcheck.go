@@ -1,7 +1,9 @@
 package healthcheck
 
 import (
+	"ewallet-wallet/constants"
 	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -16,3 +18,28 @@ func (h *Handler) HealthcheckHandlerHTTP(c *gin.Context) {
 
 	helpers.SendResponseHTTP(c, http.StatusOK, msg, nil)
 }
+
+// LivenessHandlerHTTP only tells the process answers, a dependency being
+// down is no reason to restart it.
+func (h *Handler) LivenessHandlerHTTP(c *gin.Context) {
+	helpers.SendResponseHTTP(c, http.StatusOK, "alive", nil)
+}
+
+func (h *Handler) ReadinessHandlerHTTP(c *gin.Context) {
+	sendReadiness(c, h.Service.Readiness(c.Request.Context()))
+}
+
+func (h *Handler) StartupHandlerHTTP(c *gin.Context) {
+	sendReadiness(c, h.Service.Startup(c.Request.Context()))
+}
+
+func sendReadiness(c *gin.Context, readiness models.Readiness) {
+	switch {
+	case readiness.Status == constants.HealthStatusUp:
+		helpers.SendResponseHTTP(c, http.StatusOK, "ready", readiness)
+	case !readiness.Started:
+		helpers.SendResponseHTTP(c, http.StatusServiceUnavailable, "starting", readiness)
+	default:
+		helpers.SendResponseHTTP(c, http.StatusServiceUnavailable, "not ready", readiness)
+	}
+}
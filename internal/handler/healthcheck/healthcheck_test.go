@@ -0,0 +1,121 @@
+package healthcheck
+
+import (
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Probes(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+
+	up := models.Readiness{
+		Status:  constants.HealthStatusUp,
+		Started: true,
+		Components: []models.ComponentHealth{
+			{Name: constants.HealthComponentDatabase, Status: constants.HealthStatusUp, LatencyMS: 1.5},
+		},
+	}
+	down := models.Readiness{
+		Status:  constants.HealthStatusDown,
+		Started: true,
+		Components: []models.ComponentHealth{
+			{Name: constants.HealthComponentDatabase, Status: constants.HealthStatusDown, LatencyMS: 2000, Error: "failed to ping database: context deadline exceeded"},
+		},
+	}
+	warming := models.Readiness{
+		Status: constants.HealthStatusDown,
+		Components: []models.ComponentHealth{
+			{Name: constants.HealthComponentMigrations, Status: constants.HealthStatusDown, LatencyMS: 3, Detail: "latest version 2", Error: "1 migrations pending"},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		path               string
+		mockFn             func()
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "live",
+			path:               "/livez",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"message":"alive"}`,
+		},
+		{
+			name: "ready",
+			path: "/readyz",
+			mockFn: func() {
+				mockSvc.EXPECT().Readiness(gomock.Any()).Return(up)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"message":"ready","data":{"status":"up","started":true,"components":[{"name":"database","status":"up","latency_ms":1.5}]}}`,
+		},
+		{
+			name: "not ready",
+			path: "/readyz",
+			mockFn: func() {
+				mockSvc.EXPECT().Readiness(gomock.Any()).Return(down)
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `{"message":"not ready","data":{"status":"down","started":true,"components":[{"name":"database","status":"down","latency_ms":2000,"error":"failed to ping database: context deadline exceeded"}]}}`,
+		},
+		{
+			name: "ready before warm",
+			path: "/readyz",
+			mockFn: func() {
+				mockSvc.EXPECT().Readiness(gomock.Any()).Return(warming)
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `{"message":"starting","data":{"status":"down","started":false,"components":[{"name":"migrations","status":"down","latency_ms":3,"detail":"latest version 2","error":"1 migrations pending"}]}}`,
+		},
+		{
+			name: "started",
+			path: "/startupz",
+			mockFn: func() {
+				mockSvc.EXPECT().Startup(gomock.Any()).Return(models.Readiness{Status: constants.HealthStatusUp, Started: true})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"message":"ready","data":{"status":"up","started":true}}`,
+		},
+		{
+			name: "starting",
+			path: "/startupz",
+			mockFn: func() {
+				mockSvc.EXPECT().Startup(gomock.Any()).Return(warming)
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `{"message":"starting","data":{"status":"down","started":false,"components":[{"name":"migrations","status":"down","latency_ms":3,"detail":"latest version 2","error":"1 migrations pending"}]}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			api := gin.New()
+			h := &Handler{
+				Engine:  api,
+				Service: mockSvc,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package paymentrequest
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=handler.go -destination=handler_mock_test.go -package=paymentrequest
+type Service interface {
+	CreatePaymentRequest(ctx context.Context, userID uint64, req models.CreatePaymentRequest) (models.PaymentRequest, error)
+	GetIncomingPaymentRequests(ctx context.Context, userID uint64, param models.PaymentRequestParam) ([]models.PaymentRequest, error)
+	GetOutgoingPaymentRequests(ctx context.Context, userID uint64, param models.PaymentRequestParam) ([]models.PaymentRequest, error)
+	AcceptPaymentRequest(ctx context.Context, userID uint64, requestID int) (models.PaymentRequest, error)
+	DeclinePaymentRequest(ctx context.Context, userID uint64, requestID int) (models.PaymentRequest, error)
+	CancelPaymentRequest(ctx context.Context, userID uint64, requestID int) (models.PaymentRequest, error)
+}
+
+type Handler struct {
+	*gin.Engine
+	Service    Service
+	Middleware Middleware
+}
+
+func NewHandler(api *gin.Engine, service Service, mdw Middleware) *Handler {
+	return &Handler{
+		api,
+		service,
+		mdw,
+	}
+}
+
+func (h *Handler) RegisterRoute() {
+	walletV1 := h.Group("/wallet/v1/payment-requests")
+	walletV1.Use(h.Middleware.MiddlewareValidateToken)
+	walletV1.POST("", h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.CreatePaymentRequest)
+	walletV1.GET("/incoming", h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetIncomingPaymentRequests)
+	walletV1.GET("/outgoing", h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetOutgoingPaymentRequests)
+	walletV1.PUT("/:request_id/accept", h.Middleware.MiddlewareRequireScope(constants.ScopeWalletDebit), h.AcceptPaymentRequest)
+	walletV1.PUT("/:request_id/decline", h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.DeclinePaymentRequest)
+	walletV1.PUT("/:request_id/cancel", h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.CancelPaymentRequest)
+}
@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: handler.go
+
+// Package paymentrequest is a generated GoMock package.
+package paymentrequest
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptPaymentRequest mocks base method.
+func (m *MockService) AcceptPaymentRequest(ctx context.Context, userID uint64, requestID int) (models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptPaymentRequest", ctx, userID, requestID)
+	ret0, _ := ret[0].(models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptPaymentRequest indicates an expected call of AcceptPaymentRequest.
+func (mr *MockServiceMockRecorder) AcceptPaymentRequest(ctx, userID, requestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptPaymentRequest", reflect.TypeOf((*MockService)(nil).AcceptPaymentRequest), ctx, userID, requestID)
+}
+
+// CancelPaymentRequest mocks base method.
+func (m *MockService) CancelPaymentRequest(ctx context.Context, userID uint64, requestID int) (models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPaymentRequest", ctx, userID, requestID)
+	ret0, _ := ret[0].(models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelPaymentRequest indicates an expected call of CancelPaymentRequest.
+func (mr *MockServiceMockRecorder) CancelPaymentRequest(ctx, userID, requestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPaymentRequest", reflect.TypeOf((*MockService)(nil).CancelPaymentRequest), ctx, userID, requestID)
+}
+
+// CreatePaymentRequest mocks base method.
+func (m *MockService) CreatePaymentRequest(ctx context.Context, userID uint64, req models.CreatePaymentRequest) (models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentRequest", ctx, userID, req)
+	ret0, _ := ret[0].(models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentRequest indicates an expected call of CreatePaymentRequest.
+func (mr *MockServiceMockRecorder) CreatePaymentRequest(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentRequest", reflect.TypeOf((*MockService)(nil).CreatePaymentRequest), ctx, userID, req)
+}
+
+// DeclinePaymentRequest mocks base method.
+func (m *MockService) DeclinePaymentRequest(ctx context.Context, userID uint64, requestID int) (models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclinePaymentRequest", ctx, userID, requestID)
+	ret0, _ := ret[0].(models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeclinePaymentRequest indicates an expected call of DeclinePaymentRequest.
+func (mr *MockServiceMockRecorder) DeclinePaymentRequest(ctx, userID, requestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclinePaymentRequest", reflect.TypeOf((*MockService)(nil).DeclinePaymentRequest), ctx, userID, requestID)
+}
+
+// GetIncomingPaymentRequests mocks base method.
+func (m *MockService) GetIncomingPaymentRequests(ctx context.Context, userID uint64, param models.PaymentRequestParam) ([]models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncomingPaymentRequests", ctx, userID, param)
+	ret0, _ := ret[0].([]models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncomingPaymentRequests indicates an expected call of GetIncomingPaymentRequests.
+func (mr *MockServiceMockRecorder) GetIncomingPaymentRequests(ctx, userID, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncomingPaymentRequests", reflect.TypeOf((*MockService)(nil).GetIncomingPaymentRequests), ctx, userID, param)
+}
+
+// GetOutgoingPaymentRequests mocks base method.
+func (m *MockService) GetOutgoingPaymentRequests(ctx context.Context, userID uint64, param models.PaymentRequestParam) ([]models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutgoingPaymentRequests", ctx, userID, param)
+	ret0, _ := ret[0].([]models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutgoingPaymentRequests indicates an expected call of GetOutgoingPaymentRequests.
+func (mr *MockServiceMockRecorder) GetOutgoingPaymentRequests(ctx, userID, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutgoingPaymentRequests", reflect.TypeOf((*MockService)(nil).GetOutgoingPaymentRequests), ctx, userID, param)
+}
@@ -0,0 +1,9 @@
+package paymentrequest
+
+import "github.com/gin-gonic/gin"
+
+//go:generate mockgen -source=middleware.go -destination=middleware_mock_test.go -package=paymentrequest
+type Middleware interface {
+	MiddlewareValidateToken(c *gin.Context)
+	MiddlewareRequireScope(scope string) gin.HandlerFunc
+}
@@ -0,0 +1,154 @@
+package paymentrequest
+
+import (
+	"context"
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) CreatePaymentRequest(c *gin.Context) {
+	var (
+		req models.CreatePaymentRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.CreatePaymentRequest(c.Request.Context(), tokenData.UserID, req)
+	if err != nil {
+		fmt.Println("failed to create payment request: ", err)
+		sendPaymentRequestError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetIncomingPaymentRequests(c *gin.Context) {
+	h.getPaymentRequests(c, h.Service.GetIncomingPaymentRequests)
+}
+
+func (h *Handler) GetOutgoingPaymentRequests(c *gin.Context) {
+	h.getPaymentRequests(c, h.Service.GetOutgoingPaymentRequests)
+}
+
+func (h *Handler) AcceptPaymentRequest(c *gin.Context) {
+	h.decidePaymentRequest(c, "accept", h.Service.AcceptPaymentRequest)
+}
+
+func (h *Handler) DeclinePaymentRequest(c *gin.Context) {
+	h.decidePaymentRequest(c, "decline", h.Service.DeclinePaymentRequest)
+}
+
+func (h *Handler) CancelPaymentRequest(c *gin.Context) {
+	h.decidePaymentRequest(c, "cancel", h.Service.CancelPaymentRequest)
+}
+
+func (h *Handler) getPaymentRequests(c *gin.Context, get func(context.Context, uint64, models.PaymentRequestParam) ([]models.PaymentRequest, error)) {
+	var (
+		param models.PaymentRequestParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := param.Validate(); err != nil {
+		fmt.Println("failed to validate query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := get(c.Request.Context(), tokenData.UserID, param)
+	if err != nil {
+		fmt.Println("failed to get payment requests: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) decidePaymentRequest(c *gin.Context, action string, decide func(context.Context, uint64, int) (models.PaymentRequest, error)) {
+	requestID, err := strconv.Atoi(c.Param("request_id"))
+	if err != nil {
+		fmt.Println("failed to parse request id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := decide(c.Request.Context(), tokenData.UserID, requestID)
+	if err != nil {
+		fmt.Printf("failed to %s payment request: %v\n", action, err)
+		sendPaymentRequestError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func sendPaymentRequestError(c *gin.Context, err error) {
+	if helpers.SendTransactionAuthError(c, err) || helpers.SendRiskError(c, err) || helpers.SendWalletError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, constants.ErrPaymentRequestNotFound):
+		helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+	case errors.Is(err, constants.ErrInvalidPaymentRequest):
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+	case errors.Is(err, constants.ErrPaymentRequestNotPending):
+		helpers.SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, nil)
+	default:
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+	}
+}
+
+func getTokenData(c *gin.Context) (models.TokenData, bool) {
+	token, ok := c.Get("token")
+	if !ok {
+		return models.TokenData{}, false
+	}
+
+	tokenData, ok := token.(models.TokenData)
+	return tokenData, ok
+}
@@ -0,0 +1,299 @@
+package paymentrequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_CreatePaymentRequest(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   10,
+		Username: "username",
+	}
+	req := models.CreatePaymentRequest{
+		PayerUserID: 20,
+		Amount:      25000,
+		Note:        "dinner",
+	}
+
+	tests := []struct {
+		name               string
+		body               models.CreatePaymentRequest
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreatePaymentRequest(gomock.Any(), tokenData.UserID, req).
+					Return(models.PaymentRequest{ID: 5, Status: constants.PaymentRequestStatusPending}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error zero amount",
+			body:               models.CreatePaymentRequest{PayerUserID: 20},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "error payer has no wallet",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreatePaymentRequest(gomock.Any(), tokenData.UserID, req).
+					Return(models.PaymentRequest{}, constants.ErrInvalidPaymentRequest)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "error",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreatePaymentRequest(gomock.Any(), tokenData.UserID, req).
+					Return(models.PaymentRequest{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/wallet/v1/payment-requests", bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_GetPaymentRequests(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   20,
+		Username: "username",
+	}
+
+	tests := []struct {
+		name               string
+		endpoint           string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name:     "success incoming",
+			endpoint: "/wallet/v1/payment-requests/incoming?status=pending",
+			mockFn: func() {
+				mockSvc.EXPECT().GetIncomingPaymentRequests(gomock.Any(), tokenData.UserID, models.PaymentRequestParam{Status: constants.PaymentRequestStatusPending}).
+					Return([]models.PaymentRequest{{ID: 5}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:     "success outgoing",
+			endpoint: "/wallet/v1/payment-requests/outgoing?page=2&limit=10",
+			mockFn: func() {
+				mockSvc.EXPECT().GetOutgoingPaymentRequests(gomock.Any(), tokenData.UserID, models.PaymentRequestParam{Page: 2, Limit: 10}).
+					Return([]models.PaymentRequest{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error unknown status",
+			endpoint:           "/wallet/v1/payment-requests/incoming?status=unknown",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:     "error",
+			endpoint: "/wallet/v1/payment-requests/incoming",
+			mockFn: func() {
+				mockSvc.EXPECT().GetIncomingPaymentRequests(gomock.Any(), tokenData.UserID, models.PaymentRequestParam{}).
+					Return(nil, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.endpoint, nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_DecidePaymentRequest(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   20,
+		Username: "username",
+	}
+
+	tests := []struct {
+		name               string
+		endpoint           string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name:     "success accept",
+			endpoint: "/wallet/v1/payment-requests/5/accept",
+			mockFn: func() {
+				mockSvc.EXPECT().AcceptPaymentRequest(gomock.Any(), tokenData.UserID, 5).
+					Return(models.PaymentRequest{ID: 5, Status: constants.PaymentRequestStatusPaid}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:     "success decline",
+			endpoint: "/wallet/v1/payment-requests/5/decline",
+			mockFn: func() {
+				mockSvc.EXPECT().DeclinePaymentRequest(gomock.Any(), tokenData.UserID, 5).
+					Return(models.PaymentRequest{ID: 5, Status: constants.PaymentRequestStatusDeclined}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:     "success cancel",
+			endpoint: "/wallet/v1/payment-requests/5/cancel",
+			mockFn: func() {
+				mockSvc.EXPECT().CancelPaymentRequest(gomock.Any(), tokenData.UserID, 5).
+					Return(models.PaymentRequest{ID: 5, Status: constants.PaymentRequestStatusCancelled}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error invalid id",
+			endpoint:           "/wallet/v1/payment-requests/abc/accept",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:     "error not found",
+			endpoint: "/wallet/v1/payment-requests/5/accept",
+			mockFn: func() {
+				mockSvc.EXPECT().AcceptPaymentRequest(gomock.Any(), tokenData.UserID, 5).
+					Return(models.PaymentRequest{}, constants.ErrPaymentRequestNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:     "error insufficient balance",
+			endpoint: "/wallet/v1/payment-requests/5/accept",
+			mockFn: func() {
+				mockSvc.EXPECT().AcceptPaymentRequest(gomock.Any(), tokenData.UserID, 5).
+					Return(models.PaymentRequest{}, constants.ErrInsufficientBalance)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "error not pending",
+			endpoint: "/wallet/v1/payment-requests/5/decline",
+			mockFn: func() {
+				mockSvc.EXPECT().DeclinePaymentRequest(gomock.Any(), tokenData.UserID, 5).
+					Return(models.PaymentRequest{}, constants.ErrPaymentRequestNotPending)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "error",
+			endpoint: "/wallet/v1/payment-requests/5/cancel",
+			mockFn: func() {
+				mockSvc.EXPECT().CancelPaymentRequest(gomock.Any(), tokenData.UserID, 5).
+					Return(models.PaymentRequest{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPut, tt.endpoint, nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package pin
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=handler.go -destination=handler_mock_test.go -package=pin
+type Service interface {
+	SetPIN(ctx context.Context, userID uint64, req models.SetPINRequest) error
+	ChangePIN(ctx context.Context, userID uint64, req models.ChangePINRequest) error
+	RequestPINReset(ctx context.Context, userID uint64, email string) error
+	ResetPIN(ctx context.Context, userID uint64, req models.ResetPINRequest) error
+	VerifyPIN(ctx context.Context, userID uint64, req models.VerifyPINRequest) (models.StepUpToken, error)
+}
+
+type Handler struct {
+	*gin.Engine
+	Service    Service
+	Middleware Middleware
+}
+
+func NewHandler(api *gin.Engine, service Service, mdw Middleware) *Handler {
+	return &Handler{
+		api,
+		service,
+		mdw,
+	}
+}
+
+func (h *Handler) RegisterRoute() {
+	pinV1 := h.Group("/wallet/v1/pin")
+	pinV1.Use(h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletDebit))
+	pinV1.POST("", h.SetPIN)
+	pinV1.PUT("", h.ChangePIN)
+	pinV1.POST("/verify", h.VerifyPIN)
+	pinV1.POST("/reset", h.RequestPINReset)
+	pinV1.PUT("/reset", h.ResetPIN)
+}
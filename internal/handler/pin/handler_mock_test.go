@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: handler.go
+
+// Package pin is a generated GoMock package.
+package pin
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// ChangePIN mocks base method.
+func (m *MockService) ChangePIN(ctx context.Context, userID uint64, req models.ChangePINRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePIN", ctx, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePIN indicates an expected call of ChangePIN.
+func (mr *MockServiceMockRecorder) ChangePIN(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePIN", reflect.TypeOf((*MockService)(nil).ChangePIN), ctx, userID, req)
+}
+
+// RequestPINReset mocks base method.
+func (m *MockService) RequestPINReset(ctx context.Context, userID uint64, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPINReset", ctx, userID, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPINReset indicates an expected call of RequestPINReset.
+func (mr *MockServiceMockRecorder) RequestPINReset(ctx, userID, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPINReset", reflect.TypeOf((*MockService)(nil).RequestPINReset), ctx, userID, email)
+}
+
+// ResetPIN mocks base method.
+func (m *MockService) ResetPIN(ctx context.Context, userID uint64, req models.ResetPINRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPIN", ctx, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPIN indicates an expected call of ResetPIN.
+func (mr *MockServiceMockRecorder) ResetPIN(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPIN", reflect.TypeOf((*MockService)(nil).ResetPIN), ctx, userID, req)
+}
+
+// SetPIN mocks base method.
+func (m *MockService) SetPIN(ctx context.Context, userID uint64, req models.SetPINRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPIN", ctx, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPIN indicates an expected call of SetPIN.
+func (mr *MockServiceMockRecorder) SetPIN(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPIN", reflect.TypeOf((*MockService)(nil).SetPIN), ctx, userID, req)
+}
+
+// VerifyPIN mocks base method.
+func (m *MockService) VerifyPIN(ctx context.Context, userID uint64, req models.VerifyPINRequest) (models.StepUpToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyPIN", ctx, userID, req)
+	ret0, _ := ret[0].(models.StepUpToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyPIN indicates an expected call of VerifyPIN.
+func (mr *MockServiceMockRecorder) VerifyPIN(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPIN", reflect.TypeOf((*MockService)(nil).VerifyPIN), ctx, userID, req)
+}
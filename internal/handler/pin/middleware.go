@@ -0,0 +1,9 @@
+package pin
+
+import "github.com/gin-gonic/gin"
+
+//go:generate mockgen -source=middleware.go -destination=middleware_mock_test.go -package=pin
+type Middleware interface {
+	MiddlewareValidateToken(c *gin.Context)
+	MiddlewareRequireScope(scope string) gin.HandlerFunc
+}
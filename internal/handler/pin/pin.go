@@ -0,0 +1,193 @@
+package pin
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) SetPIN(c *gin.Context) {
+	var (
+		req models.SetPINRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	err := h.Service.SetPIN(c.Request.Context(), tokenData.UserID, req)
+	if err != nil {
+		fmt.Println("failed to set pin: ", err)
+		sendPINError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, nil)
+}
+
+func (h *Handler) ChangePIN(c *gin.Context) {
+	var (
+		req models.ChangePINRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	err := h.Service.ChangePIN(c.Request.Context(), tokenData.UserID, req)
+	if err != nil {
+		fmt.Println("failed to change pin: ", err)
+		sendPINError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, nil)
+}
+
+func (h *Handler) VerifyPIN(c *gin.Context) {
+	var (
+		req models.VerifyPINRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.VerifyPIN(c.Request.Context(), tokenData.UserID, req)
+	if err != nil {
+		fmt.Println("failed to verify pin: ", err)
+		sendPINError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) RequestPINReset(c *gin.Context) {
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	err := h.Service.RequestPINReset(c.Request.Context(), tokenData.UserID, tokenData.Email)
+	if err != nil {
+		fmt.Println("failed to request pin reset: ", err)
+		sendPINError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, nil)
+}
+
+func (h *Handler) ResetPIN(c *gin.Context) {
+	var (
+		req models.ResetPINRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	err := h.Service.ResetPIN(c.Request.Context(), tokenData.UserID, req)
+	if err != nil {
+		fmt.Println("failed to reset pin: ", err)
+		sendPINError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, nil)
+}
+
+func sendPINError(c *gin.Context, err error) {
+	if helpers.SendTransactionAuthError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, constants.ErrPINAlreadySet):
+		helpers.SendResponseHTTP(c, http.StatusConflict, constants.ErrPINAlreadySet.Error(), nil)
+	case errors.Is(err, constants.ErrInvalidOTP):
+		helpers.SendResponseHTTP(c, http.StatusUnauthorized, constants.ErrInvalidOTP.Error(), nil)
+	case errors.Is(err, constants.ErrOTPTooSoon):
+		helpers.SendResponseHTTP(c, http.StatusTooManyRequests, constants.ErrOTPTooSoon.Error(), nil)
+	default:
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+	}
+}
+
+func getTokenData(c *gin.Context) (models.TokenData, bool) {
+	token, ok := c.Get("token")
+	if !ok {
+		return models.TokenData{}, false
+	}
+
+	tokenData, ok := token.(models.TokenData)
+	return tokenData, ok
+}
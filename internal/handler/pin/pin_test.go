@@ -0,0 +1,197 @@
+package pin
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_PIN(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   10,
+		Username: "username",
+		Email:    "user@mail.com",
+	}
+	expiredAt := time.Date(2024, 6, 1, 10, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		method             string
+		endpoint           string
+		body               interface{}
+		mockFn             func()
+		expectedStatusCode int
+		expectedMessage    string
+	}{
+		{
+			name:     "success set pin",
+			method:   http.MethodPost,
+			endpoint: "/wallet/v1/pin",
+			body:     models.SetPINRequest{PIN: "123456"},
+			mockFn: func() {
+				mockSvc.EXPECT().SetPIN(gomock.Any(), tokenData.UserID, models.SetPINRequest{PIN: "123456"}).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    constants.SuccessMessage,
+		},
+		{
+			name:               "error set pin not six digits",
+			method:             http.MethodPost,
+			endpoint:           "/wallet/v1/pin",
+			body:               models.SetPINRequest{PIN: "12ab56"},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMessage:    constants.ErrFailedBadRequest,
+		},
+		{
+			name:     "error set pin already set",
+			method:   http.MethodPost,
+			endpoint: "/wallet/v1/pin",
+			body:     models.SetPINRequest{PIN: "123456"},
+			mockFn: func() {
+				mockSvc.EXPECT().SetPIN(gomock.Any(), tokenData.UserID, gomock.Any()).Return(constants.ErrPINAlreadySet)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedMessage:    constants.ErrPINAlreadySet.Error(),
+		},
+		{
+			name:               "error change pin to the same pin",
+			method:             http.MethodPut,
+			endpoint:           "/wallet/v1/pin",
+			body:               models.ChangePINRequest{OldPIN: "123456", NewPIN: "123456"},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMessage:    constants.ErrFailedBadRequest,
+		},
+		{
+			name:     "error change pin wrong old pin",
+			method:   http.MethodPut,
+			endpoint: "/wallet/v1/pin",
+			body:     models.ChangePINRequest{OldPIN: "123456", NewPIN: "654321"},
+			mockFn: func() {
+				mockSvc.EXPECT().ChangePIN(gomock.Any(), tokenData.UserID, gomock.Any()).Return(constants.ErrInvalidPIN)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedMessage:    constants.ErrInvalidPIN.Error(),
+		},
+		{
+			name:     "success verify pin",
+			method:   http.MethodPost,
+			endpoint: "/wallet/v1/pin/verify",
+			body:     models.VerifyPINRequest{PIN: "123456"},
+			mockFn: func() {
+				mockSvc.EXPECT().VerifyPIN(gomock.Any(), tokenData.UserID, models.VerifyPINRequest{PIN: "123456"}).
+					Return(models.StepUpToken{Token: "step-up", ExpiredAt: expiredAt}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    constants.SuccessMessage,
+		},
+		{
+			name:     "error verify pin locked",
+			method:   http.MethodPost,
+			endpoint: "/wallet/v1/pin/verify",
+			body:     models.VerifyPINRequest{PIN: "123456"},
+			mockFn: func() {
+				mockSvc.EXPECT().VerifyPIN(gomock.Any(), tokenData.UserID, gomock.Any()).Return(models.StepUpToken{}, constants.ErrPINLocked)
+			},
+			expectedStatusCode: http.StatusLocked,
+			expectedMessage:    constants.ErrPINLocked.Error(),
+		},
+		{
+			name:     "success request reset",
+			method:   http.MethodPost,
+			endpoint: "/wallet/v1/pin/reset",
+			mockFn: func() {
+				mockSvc.EXPECT().RequestPINReset(gomock.Any(), tokenData.UserID, "user@mail.com").Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedMessage:    constants.SuccessMessage,
+		},
+		{
+			name:     "error request reset too soon",
+			method:   http.MethodPost,
+			endpoint: "/wallet/v1/pin/reset",
+			mockFn: func() {
+				mockSvc.EXPECT().RequestPINReset(gomock.Any(), tokenData.UserID, "user@mail.com").Return(constants.ErrOTPTooSoon)
+			},
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedMessage:    constants.ErrOTPTooSoon.Error(),
+		},
+		{
+			name:     "error reset wrong otp",
+			method:   http.MethodPut,
+			endpoint: "/wallet/v1/pin/reset",
+			body:     models.ResetPINRequest{OTP: "111111", NewPIN: "654321"},
+			mockFn: func() {
+				mockSvc.EXPECT().ResetPIN(gomock.Any(), tokenData.UserID, gomock.Any()).Return(constants.ErrInvalidOTP)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedMessage:    constants.ErrInvalidOTP.Error(),
+		},
+		{
+			name:     "error reset",
+			method:   http.MethodPut,
+			endpoint: "/wallet/v1/pin/reset",
+			body:     models.ResetPINRequest{OTP: "111111", NewPIN: "654321"},
+			mockFn: func() {
+				mockSvc.EXPECT().ResetPIN(gomock.Any(), tokenData.UserID, gomock.Any()).Return(assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedMessage:    constants.ErrServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			var body []byte
+			if tt.body != nil {
+				val, err := json.Marshal(tt.body)
+				assert.NoError(t, err)
+				body = val
+			}
+
+			req, err := http.NewRequest(tt.method, tt.endpoint, bytes.NewReader(body))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+
+			response := helpers.Response{}
+			err = json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedMessage, response.Message)
+		})
+	}
+}
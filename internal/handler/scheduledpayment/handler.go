@@ -0,0 +1,49 @@
+package scheduledpayment
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=handler.go -destination=handler_mock_test.go -package=scheduledpayment
+type Service interface {
+	CreateScheduledPayment(ctx context.Context, clientID string, req models.ScheduledPaymentRequest) (models.ScheduledPayment, error)
+	GetUserScheduledPayments(ctx context.Context, userID uint64) ([]models.ScheduledPayment, error)
+	GetClientScheduledPayments(ctx context.Context, clientID string, param models.ScheduledPaymentParam) ([]models.ScheduledPayment, error)
+	ChangeUserScheduledPayment(ctx context.Context, userID uint64, paymentID int, action string) (models.ScheduledPayment, error)
+	ChangeClientScheduledPayment(ctx context.Context, clientID string, paymentID int, action string) (models.ScheduledPayment, error)
+}
+
+type Handler struct {
+	*gin.Engine
+	Service    Service
+	Middleware Middleware
+}
+
+func NewHandler(api *gin.Engine, service Service, mdw Middleware) *Handler {
+	return &Handler{
+		api,
+		service,
+		mdw,
+	}
+}
+
+func (h *Handler) RegisterRoute() {
+	walletV1 := h.Group("/wallet/v1/scheduled-payments")
+	walletV1.Use(h.Middleware.MiddlewareValidateToken)
+	walletV1.GET("", h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetUserScheduledPayments)
+	walletV1.PUT("/:payment_id/pause", h.Middleware.MiddlewareRequireScope(constants.ScopeWalletDebit), h.PauseUserScheduledPayment)
+	walletV1.PUT("/:payment_id/resume", h.Middleware.MiddlewareRequireScope(constants.ScopeWalletDebit), h.ResumeUserScheduledPayment)
+	walletV1.PUT("/:payment_id/cancel", h.Middleware.MiddlewareRequireScope(constants.ScopeWalletDebit), h.CancelUserScheduledPayment)
+
+	exWalletV1 := h.Group("/wallet/v1/ex/scheduled-payments")
+	exWalletV1.Use(h.Middleware.MiddlewareSignatureValidation)
+	exWalletV1.POST("", h.CreateScheduledPayment)
+	exWalletV1.GET("", h.GetClientScheduledPayments)
+	exWalletV1.PUT("/:payment_id/pause", h.PauseClientScheduledPayment)
+	exWalletV1.PUT("/:payment_id/resume", h.ResumeClientScheduledPayment)
+	exWalletV1.PUT("/:payment_id/cancel", h.CancelClientScheduledPayment)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: handler.go
+
+// Package scheduledpayment is a generated GoMock package.
+package scheduledpayment
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// ChangeClientScheduledPayment mocks base method.
+func (m *MockService) ChangeClientScheduledPayment(ctx context.Context, clientID string, paymentID int, action string) (models.ScheduledPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeClientScheduledPayment", ctx, clientID, paymentID, action)
+	ret0, _ := ret[0].(models.ScheduledPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeClientScheduledPayment indicates an expected call of ChangeClientScheduledPayment.
+func (mr *MockServiceMockRecorder) ChangeClientScheduledPayment(ctx, clientID, paymentID, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeClientScheduledPayment", reflect.TypeOf((*MockService)(nil).ChangeClientScheduledPayment), ctx, clientID, paymentID, action)
+}
+
+// ChangeUserScheduledPayment mocks base method.
+func (m *MockService) ChangeUserScheduledPayment(ctx context.Context, userID uint64, paymentID int, action string) (models.ScheduledPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeUserScheduledPayment", ctx, userID, paymentID, action)
+	ret0, _ := ret[0].(models.ScheduledPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeUserScheduledPayment indicates an expected call of ChangeUserScheduledPayment.
+func (mr *MockServiceMockRecorder) ChangeUserScheduledPayment(ctx, userID, paymentID, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserScheduledPayment", reflect.TypeOf((*MockService)(nil).ChangeUserScheduledPayment), ctx, userID, paymentID, action)
+}
+
+// CreateScheduledPayment mocks base method.
+func (m *MockService) CreateScheduledPayment(ctx context.Context, clientID string, req models.ScheduledPaymentRequest) (models.ScheduledPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledPayment", ctx, clientID, req)
+	ret0, _ := ret[0].(models.ScheduledPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledPayment indicates an expected call of CreateScheduledPayment.
+func (mr *MockServiceMockRecorder) CreateScheduledPayment(ctx, clientID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledPayment", reflect.TypeOf((*MockService)(nil).CreateScheduledPayment), ctx, clientID, req)
+}
+
+// GetClientScheduledPayments mocks base method.
+func (m *MockService) GetClientScheduledPayments(ctx context.Context, clientID string, param models.ScheduledPaymentParam) ([]models.ScheduledPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClientScheduledPayments", ctx, clientID, param)
+	ret0, _ := ret[0].([]models.ScheduledPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClientScheduledPayments indicates an expected call of GetClientScheduledPayments.
+func (mr *MockServiceMockRecorder) GetClientScheduledPayments(ctx, clientID, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientScheduledPayments", reflect.TypeOf((*MockService)(nil).GetClientScheduledPayments), ctx, clientID, param)
+}
+
+// GetUserScheduledPayments mocks base method.
+func (m *MockService) GetUserScheduledPayments(ctx context.Context, userID uint64) ([]models.ScheduledPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserScheduledPayments", ctx, userID)
+	ret0, _ := ret[0].([]models.ScheduledPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserScheduledPayments indicates an expected call of GetUserScheduledPayments.
+func (mr *MockServiceMockRecorder) GetUserScheduledPayments(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserScheduledPayments", reflect.TypeOf((*MockService)(nil).GetUserScheduledPayments), ctx, userID)
+}
@@ -0,0 +1,10 @@
+package scheduledpayment
+
+import "github.com/gin-gonic/gin"
+
+//go:generate mockgen -source=middleware.go -destination=middleware_mock_test.go -package=scheduledpayment
+type Middleware interface {
+	MiddlewareValidateToken(c *gin.Context)
+	MiddlewareRequireScope(scope string) gin.HandlerFunc
+	MiddlewareSignatureValidation(c *gin.Context)
+}
@@ -0,0 +1,201 @@
+package scheduledpayment
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func (h *Handler) CreateScheduledPayment(c *gin.Context) {
+	var (
+		req models.ScheduledPaymentRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	clientID, ok := getClientID(c)
+	if !ok {
+		fmt.Println("failed to get client id")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.CreateScheduledPayment(c.Request.Context(), clientID, req)
+	if err != nil {
+		fmt.Println("failed to create scheduled payment: ", err)
+		sendScheduleError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetUserScheduledPayments(c *gin.Context) {
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetUserScheduledPayments(c.Request.Context(), tokenData.UserID)
+	if err != nil {
+		fmt.Println("failed to get scheduled payments: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetClientScheduledPayments(c *gin.Context) {
+	var (
+		param models.ScheduledPaymentParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	clientID, ok := getClientID(c)
+	if !ok {
+		fmt.Println("failed to get client id")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetClientScheduledPayments(c.Request.Context(), clientID, param)
+	if err != nil {
+		fmt.Println("failed to get scheduled payments: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) PauseUserScheduledPayment(c *gin.Context) {
+	h.changeUserScheduledPayment(c, constants.ScheduleActionPause)
+}
+
+func (h *Handler) ResumeUserScheduledPayment(c *gin.Context) {
+	h.changeUserScheduledPayment(c, constants.ScheduleActionResume)
+}
+
+func (h *Handler) CancelUserScheduledPayment(c *gin.Context) {
+	h.changeUserScheduledPayment(c, constants.ScheduleActionCancel)
+}
+
+func (h *Handler) PauseClientScheduledPayment(c *gin.Context) {
+	h.changeClientScheduledPayment(c, constants.ScheduleActionPause)
+}
+
+func (h *Handler) ResumeClientScheduledPayment(c *gin.Context) {
+	h.changeClientScheduledPayment(c, constants.ScheduleActionResume)
+}
+
+func (h *Handler) CancelClientScheduledPayment(c *gin.Context) {
+	h.changeClientScheduledPayment(c, constants.ScheduleActionCancel)
+}
+
+func (h *Handler) changeUserScheduledPayment(c *gin.Context, action string) {
+	paymentID, err := strconv.Atoi(c.Param("payment_id"))
+	if err != nil {
+		fmt.Println("failed to parse payment id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.ChangeUserScheduledPayment(c.Request.Context(), tokenData.UserID, paymentID, action)
+	if err != nil {
+		fmt.Printf("failed to %s scheduled payment: %v\n", action, err)
+		sendScheduleError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) changeClientScheduledPayment(c *gin.Context, action string) {
+	paymentID, err := strconv.Atoi(c.Param("payment_id"))
+	if err != nil {
+		fmt.Println("failed to parse payment id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	clientID, ok := getClientID(c)
+	if !ok {
+		fmt.Println("failed to get client id")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.ChangeClientScheduledPayment(c.Request.Context(), clientID, paymentID, action)
+	if err != nil {
+		fmt.Printf("failed to %s scheduled payment: %v\n", action, err)
+		sendScheduleError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func sendScheduleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, constants.ErrScheduleNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+	case errors.Is(err, constants.ErrInvalidScheduleRequest):
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+	case errors.Is(err, constants.ErrWalletNotLinked), errors.Is(err, constants.ErrInvalidScheduleChange):
+		helpers.SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, nil)
+	default:
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+	}
+}
+
+func getTokenData(c *gin.Context) (models.TokenData, bool) {
+	token, ok := c.Get("token")
+	if !ok {
+		return models.TokenData{}, false
+	}
+
+	tokenData, ok := token.(models.TokenData)
+	return tokenData, ok
+}
+
+func getClientID(c *gin.Context) (string, bool) {
+	clientID, ok := c.Get("client_id")
+	if !ok {
+		return "", false
+	}
+
+	clientSource, ok := clientID.(string)
+	return clientSource, ok
+}
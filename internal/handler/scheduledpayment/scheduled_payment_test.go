@@ -0,0 +1,245 @@
+package scheduledpayment
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_CreateScheduledPayment(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	clientID := "fastcampus_ecommerce"
+	req := models.ScheduledPaymentRequest{
+		WalletID:       1,
+		Amount:         49000,
+		Frequency:      constants.ScheduleFrequencyMonthly,
+		MaxOccurrences: 12,
+	}
+
+	tests := []struct {
+		name               string
+		body               models.ScheduledPaymentRequest
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateScheduledPayment(gomock.Any(), clientID, req).Return(models.ScheduledPayment{ID: 3, Status: constants.ScheduleStatusActive}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error unknown frequency",
+			body:               models.ScheduledPaymentRequest{WalletID: 1, Amount: 49000, Frequency: "yearly"},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "error wallet not linked",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateScheduledPayment(gomock.Any(), clientID, req).Return(models.ScheduledPayment{}, constants.ErrWalletNotLinked)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "error",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateScheduledPayment(gomock.Any(), clientID, req).Return(models.ScheduledPayment{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareSignatureValidation(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("client_id", clientID)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/wallet/v1/ex/scheduled-payments", bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_ChangeUserScheduledPayment(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   1,
+		Username: "username",
+	}
+
+	tests := []struct {
+		name               string
+		endpoint           string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name:     "success pause",
+			endpoint: "/wallet/v1/scheduled-payments/3/pause",
+			mockFn: func() {
+				mockSvc.EXPECT().ChangeUserScheduledPayment(gomock.Any(), tokenData.UserID, 3, constants.ScheduleActionPause).
+					Return(models.ScheduledPayment{ID: 3, Status: constants.ScheduleStatusPaused}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:     "success resume",
+			endpoint: "/wallet/v1/scheduled-payments/3/resume",
+			mockFn: func() {
+				mockSvc.EXPECT().ChangeUserScheduledPayment(gomock.Any(), tokenData.UserID, 3, constants.ScheduleActionResume).
+					Return(models.ScheduledPayment{ID: 3, Status: constants.ScheduleStatusActive}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error invalid payment id",
+			endpoint:           "/wallet/v1/scheduled-payments/abc/cancel",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:     "error not found",
+			endpoint: "/wallet/v1/scheduled-payments/3/cancel",
+			mockFn: func() {
+				mockSvc.EXPECT().ChangeUserScheduledPayment(gomock.Any(), tokenData.UserID, 3, constants.ScheduleActionCancel).
+					Return(models.ScheduledPayment{}, constants.ErrScheduleNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:     "error invalid change",
+			endpoint: "/wallet/v1/scheduled-payments/3/cancel",
+			mockFn: func() {
+				mockSvc.EXPECT().ChangeUserScheduledPayment(gomock.Any(), tokenData.UserID, 3, constants.ScheduleActionCancel).
+					Return(models.ScheduledPayment{}, constants.ErrInvalidScheduleChange)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPut, tt.endpoint, nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_GetClientScheduledPayments(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	clientID := "fastcampus_ecommerce"
+
+	tests := []struct {
+		name               string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mockSvc.EXPECT().GetClientScheduledPayments(gomock.Any(), clientID, models.ScheduledPaymentParam{WalletID: 1}).
+					Return([]models.ScheduledPayment{{ID: 3, WalletID: 1, ClientID: clientID}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "error",
+			mockFn: func() {
+				mockSvc.EXPECT().GetClientScheduledPayments(gomock.Any(), clientID, models.ScheduledPaymentParam{WalletID: 1}).
+					Return(nil, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareSignatureValidation(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("client_id", clientID)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/wallet/v1/ex/scheduled-payments?wallet_id=1", nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
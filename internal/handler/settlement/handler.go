@@ -0,0 +1,32 @@
+package settlement
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=handler.go -destination=handler_mock_test.go -package=settlement
+type Service interface {
+	GetClientSettlement(ctx context.Context, clientID string, dateFrom string, dateTo string) ([]models.SettlementDay, error)
+}
+
+type Handler struct {
+	*gin.Engine
+	Service    Service
+	Middleware Middleware
+}
+
+func NewHandler(api *gin.Engine, service Service, mdw Middleware) *Handler {
+	return &Handler{
+		api,
+		service,
+		mdw,
+	}
+}
+
+func (h *Handler) RegisterRoute() {
+	exWalletV1 := h.Group("/wallet/v1/ex")
+	exWalletV1.GET("/settlement", h.Middleware.MiddlewareSignatureValidation, h.GetSettlement)
+}
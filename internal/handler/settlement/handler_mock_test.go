@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: handler.go
+
+// Package settlement is a generated GoMock package.
+package settlement
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// GetClientSettlement mocks base method.
+func (m *MockService) GetClientSettlement(ctx context.Context, clientID, dateFrom, dateTo string) ([]models.SettlementDay, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClientSettlement", ctx, clientID, dateFrom, dateTo)
+	ret0, _ := ret[0].([]models.SettlementDay)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClientSettlement indicates an expected call of GetClientSettlement.
+func (mr *MockServiceMockRecorder) GetClientSettlement(ctx, clientID, dateFrom, dateTo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientSettlement", reflect.TypeOf((*MockService)(nil).GetClientSettlement), ctx, clientID, dateFrom, dateTo)
+}
@@ -0,0 +1,8 @@
+package settlement
+
+import "github.com/gin-gonic/gin"
+
+//go:generate mockgen -source=middleware.go -destination=middleware_mock_test.go -package=settlement
+type Middleware interface {
+	MiddlewareSignatureValidation(c *gin.Context)
+}
@@ -0,0 +1,72 @@
+package settlement
+
+import (
+	"bytes"
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetSettlement(c *gin.Context) {
+	var (
+		param models.SettlementParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := param.Validate(); err != nil {
+		fmt.Println("failed to validate query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	clientID, ok := c.Get("client_id")
+	if !ok {
+		fmt.Println("failed to get client id")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	clientSource, ok := clientID.(string)
+	if !ok {
+		fmt.Println("failed to parse client id")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetClientSettlement(c.Request.Context(), clientSource, param.DateFrom, param.DateTo)
+	if err != nil {
+		fmt.Println("failed to get settlement: ", err)
+		if errors.Is(err, constants.ErrInvalidSettlementRange) {
+			helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+			return
+		}
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	if param.Format != constants.ReportFormatCSV {
+		helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := helpers.WriteSettlementCSV(&buf, resp); err != nil {
+		fmt.Println("failed to write settlement csv: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	fileName := fmt.Sprintf("settlement-%s-%s-%s.csv", clientSource, param.DateFrom, param.DateTo)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, "text/csv", buf.Bytes())
+}
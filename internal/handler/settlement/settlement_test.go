@@ -0,0 +1,116 @@
+package settlement
+
+import (
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_GetSettlement(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	clientID := "fastcampus_ecommerce"
+	days := []models.SettlementDay{
+		{ClientID: clientID, Date: "2024-05-01", GrossCredit: 500, Debit: 120, Refund: 20, Fee: 2.5, Net: 397.5, TransactionCount: 6},
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		mockFn             func()
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:  "success json",
+			query: "date_from=2024-05-01&date_to=2024-05-01",
+			mockFn: func() {
+				mockSvc.EXPECT().GetClientSettlement(gomock.Any(), clientID, "2024-05-01", "2024-05-01").Return(days, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"message":"success","data":[{"client_id":"fastcampus_ecommerce","date":"2024-05-01","gross_credit":500,"debit":120,"refund":20,"fee":2.5,"net":397.5,"transaction_count":6}]}`,
+		},
+		{
+			name:  "success csv",
+			query: "date_from=2024-05-01&date_to=2024-05-01&format=csv",
+			mockFn: func() {
+				mockSvc.EXPECT().GetClientSettlement(gomock.Any(), clientID, "2024-05-01", "2024-05-01").Return(days, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: "client_id,date,gross_credit,debit,refund,fee,net,transaction_count\n" +
+				"fastcampus_ecommerce,2024-05-01,500.00,120.00,20.00,2.50,397.50,6\n",
+		},
+		{
+			name:               "error missing date",
+			query:              "date_from=2024-05-01",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "error unknown format",
+			query:              "date_from=2024-05-01&date_to=2024-05-01&format=xml",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "error invalid range",
+			query: "date_from=2024-05-01&date_to=2024-07-01",
+			mockFn: func() {
+				mockSvc.EXPECT().GetClientSettlement(gomock.Any(), clientID, "2024-05-01", "2024-07-01").
+					Return(nil, fmt.Errorf("%w: too long", constants.ErrInvalidSettlementRange))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "error",
+			query: "date_from=2024-05-01&date_to=2024-05-01",
+			mockFn: func() {
+				mockSvc.EXPECT().GetClientSettlement(gomock.Any(), clientID, "2024-05-01", "2024-05-01").Return(nil, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareSignatureValidation(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("client_id", clientID)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/wallet/v1/ex/settlement?"+tt.query, nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				response := helpers.Response{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				return
+			}
+
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
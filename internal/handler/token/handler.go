@@ -0,0 +1,35 @@
+package token
+
+import (
+	"ewallet-wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=handler.go -destination=handler_mock_test.go -package=token
+type Service interface {
+	Revoke(token string)
+	RevokeUser(userID uint64)
+	Stats() models.TokenCacheStats
+}
+
+type Handler struct {
+	*gin.Engine
+	Service    Service
+	Middleware Middleware
+}
+
+func NewHandler(api *gin.Engine, service Service, mdw Middleware) *Handler {
+	return &Handler{
+		api,
+		service,
+		mdw,
+	}
+}
+
+func (h *Handler) RegisterRoute() {
+	tokenV1 := h.Group("/token/v1")
+	tokenV1.Use(h.Middleware.MiddlewareInternalOnly)
+	tokenV1.POST("/revoke", h.Revoke)
+	tokenV1.GET("/cache/stats", h.GetCacheStats)
+}
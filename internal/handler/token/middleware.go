@@ -0,0 +1,8 @@
+package token
+
+import "github.com/gin-gonic/gin"
+
+//go:generate mockgen -source=middleware.go -destination=middleware_mock_test.go -package=token
+type Middleware interface {
+	MiddlewareInternalOnly(c *gin.Context)
+}
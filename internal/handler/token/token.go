@@ -0,0 +1,42 @@
+package token
+
+import (
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) Revoke(c *gin.Context) {
+	var (
+		req models.RevokeTokenRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if req.Token != "" {
+		h.Service.Revoke(req.Token)
+	}
+	if req.UserID != 0 {
+		h.Service.RevokeUser(req.UserID)
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, nil)
+}
+
+func (h *Handler) GetCacheStats(c *gin.Context) {
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, h.Service.Stats())
+}
@@ -0,0 +1,130 @@
+package token
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Revoke(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	tests := []struct {
+		name               string
+		body               string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success token",
+			body: `{"token":"Bearer token"}`,
+			mockFn: func() {
+				mockSvc.EXPECT().Revoke("Bearer token")
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "success user",
+			body: `{"user_id":7}`,
+			mockFn: func() {
+				mockSvc.EXPECT().RevokeUser(uint64(7))
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "success token and user",
+			body: `{"token":"Bearer token","user_id":7}`,
+			mockFn: func() {
+				mockSvc.EXPECT().Revoke("Bearer token")
+				mockSvc.EXPECT().RevokeUser(uint64(7))
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error empty request",
+			body:               `{}`,
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "error invalid body",
+			body:               `{"user_id":"seven"}`,
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareInternalOnly(gomock.Any()).Do(func(c *gin.Context) {
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/token/v1/revoke", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_GetCacheStats(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+
+	stats := models.TokenCacheStats{Entries: 3, Hits: 10, NegativeHits: 1, Misses: 4, Evictions: 1, Revocations: 2}
+	mockSvc.EXPECT().Stats().Return(stats)
+	mockMdw.EXPECT().MiddlewareInternalOnly(gomock.Any()).Do(func(c *gin.Context) {
+		c.Next()
+	})
+
+	api := gin.New()
+	h := &Handler{
+		Engine:     api,
+		Service:    mockSvc,
+		Middleware: mockMdw,
+	}
+	h.RegisterRoute()
+	w := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodGet, "/token/v1/cache/stats", nil)
+	assert.NoError(t, err)
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response := helpers.Response{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, constants.SuccessMessage, response.Message)
+
+	data, err := json.Marshal(response.Data)
+	assert.NoError(t, err)
+	got := models.TokenCacheStats{}
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, stats, got)
+}
@@ -0,0 +1,41 @@
+package topup
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=handler.go -destination=handler_mock_test.go -package=topup
+type Service interface {
+	CreateTopUp(ctx context.Context, userID uint64, req models.TopUpRequest) (models.TopUpIntent, error)
+	GetTopUp(ctx context.Context, userID uint64, intentID int) (models.TopUpIntent, error)
+	GetTopUps(ctx context.Context, userID uint64, param models.TopUpParam) ([]models.TopUpIntent, error)
+	HandleCallback(ctx context.Context, payload []byte, signature string) (models.TopUpIntent, error)
+}
+
+type Handler struct {
+	*gin.Engine
+	Service    Service
+	Middleware Middleware
+}
+
+func NewHandler(api *gin.Engine, service Service, mdw Middleware) *Handler {
+	return &Handler{
+		api,
+		service,
+		mdw,
+	}
+}
+
+func (h *Handler) RegisterRoute() {
+	walletV1 := h.Group("/wallet/v1/topups")
+	walletV1.POST("", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletCredit), h.CreateTopUp)
+	walletV1.GET("", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetTopUps)
+	walletV1.GET("/:topup_id", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetTopUp)
+
+	// called by the gateway, authenticated by the callback signature
+	walletV1.POST("/callback", h.TopUpCallback)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: handler.go
+
+// Package topup is a generated GoMock package.
+package topup
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateTopUp mocks base method.
+func (m *MockService) CreateTopUp(ctx context.Context, userID uint64, req models.TopUpRequest) (models.TopUpIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTopUp", ctx, userID, req)
+	ret0, _ := ret[0].(models.TopUpIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTopUp indicates an expected call of CreateTopUp.
+func (mr *MockServiceMockRecorder) CreateTopUp(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTopUp", reflect.TypeOf((*MockService)(nil).CreateTopUp), ctx, userID, req)
+}
+
+// GetTopUp mocks base method.
+func (m *MockService) GetTopUp(ctx context.Context, userID uint64, intentID int) (models.TopUpIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopUp", ctx, userID, intentID)
+	ret0, _ := ret[0].(models.TopUpIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopUp indicates an expected call of GetTopUp.
+func (mr *MockServiceMockRecorder) GetTopUp(ctx, userID, intentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopUp", reflect.TypeOf((*MockService)(nil).GetTopUp), ctx, userID, intentID)
+}
+
+// GetTopUps mocks base method.
+func (m *MockService) GetTopUps(ctx context.Context, userID uint64, param models.TopUpParam) ([]models.TopUpIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopUps", ctx, userID, param)
+	ret0, _ := ret[0].([]models.TopUpIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopUps indicates an expected call of GetTopUps.
+func (mr *MockServiceMockRecorder) GetTopUps(ctx, userID, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopUps", reflect.TypeOf((*MockService)(nil).GetTopUps), ctx, userID, param)
+}
+
+// HandleCallback mocks base method.
+func (m *MockService) HandleCallback(ctx context.Context, payload []byte, signature string) (models.TopUpIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleCallback", ctx, payload, signature)
+	ret0, _ := ret[0].(models.TopUpIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HandleCallback indicates an expected call of HandleCallback.
+func (mr *MockServiceMockRecorder) HandleCallback(ctx, payload, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleCallback", reflect.TypeOf((*MockService)(nil).HandleCallback), ctx, payload, signature)
+}
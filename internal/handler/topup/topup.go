@@ -0,0 +1,151 @@
+package topup
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) CreateTopUp(c *gin.Context) {
+	var (
+		req models.TopUpRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.CreateTopUp(c.Request.Context(), tokenData.UserID, req)
+	if err != nil {
+		fmt.Println("failed to create top-up: ", err)
+		sendTopUpError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetTopUps(c *gin.Context) {
+	var (
+		param models.TopUpParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := param.Validate(); err != nil {
+		fmt.Println("failed to validate query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetTopUps(c.Request.Context(), tokenData.UserID, param)
+	if err != nil {
+		fmt.Println("failed to get top-ups: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetTopUp(c *gin.Context) {
+	intentID, err := strconv.Atoi(c.Param("topup_id"))
+	if err != nil {
+		fmt.Println("failed to parse top-up id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetTopUp(c.Request.Context(), tokenData.UserID, intentID)
+	if err != nil {
+		fmt.Println("failed to get top-up: ", err)
+		sendTopUpError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) TopUpCallback(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		fmt.Println("failed to read callback body: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	resp, err := h.Service.HandleCallback(c.Request.Context(), payload, c.Request.Header.Get(constants.HeaderCallbackSignature))
+	if err != nil {
+		fmt.Println("failed to handle top-up callback: ", err)
+		sendTopUpError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func sendTopUpError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, constants.ErrInvalidGatewaySignature):
+		helpers.SendResponseHTTP(c, http.StatusUnauthorized, "unauthorized", nil)
+	case errors.Is(err, constants.ErrTopUpNotFound):
+		helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+	case errors.Is(err, constants.ErrInvalidGatewayCallback):
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+	case errors.Is(err, constants.ErrTopUpNotPending), errors.Is(err, constants.ErrWalletSuspended), errors.Is(err, constants.ErrWalletClosed),
+		errors.Is(err, constants.ErrSystemWallet):
+		helpers.SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, nil)
+	default:
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+	}
+}
+
+func getTokenData(c *gin.Context) (models.TokenData, bool) {
+	token, ok := c.Get("token")
+	if !ok {
+		return models.TokenData{}, false
+	}
+
+	tokenData, ok := token.(models.TokenData)
+	return tokenData, ok
+}
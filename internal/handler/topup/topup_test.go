@@ -0,0 +1,255 @@
+package topup
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_CreateTopUp(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   10,
+		Username: "username",
+	}
+	req := models.TopUpRequest{Amount: 100000}
+
+	tests := []struct {
+		name               string
+		body               models.TopUpRequest
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateTopUp(gomock.Any(), tokenData.UserID, req).
+					Return(models.TopUpIntent{ID: 7, Status: constants.TopUpStatusPending}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error zero amount",
+			body:               models.TopUpRequest{},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "error wallet suspended",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateTopUp(gomock.Any(), tokenData.UserID, req).Return(models.TopUpIntent{}, constants.ErrWalletSuspended)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "error",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateTopUp(gomock.Any(), tokenData.UserID, req).Return(models.TopUpIntent{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/wallet/v1/topups", bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_GetTopUp(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   10,
+		Username: "username",
+	}
+
+	tests := []struct {
+		name               string
+		endpoint           string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name:     "success",
+			endpoint: "/wallet/v1/topups/7",
+			mockFn: func() {
+				mockSvc.EXPECT().GetTopUp(gomock.Any(), tokenData.UserID, 7).Return(models.TopUpIntent{ID: 7, Status: constants.TopUpStatusPaid}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:     "success list",
+			endpoint: "/wallet/v1/topups?status=pending",
+			mockFn: func() {
+				mockSvc.EXPECT().GetTopUps(gomock.Any(), tokenData.UserID, models.TopUpParam{Status: constants.TopUpStatusPending}).Return([]models.TopUpIntent{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error unknown status",
+			endpoint:           "/wallet/v1/topups?status=unknown",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "error invalid id",
+			endpoint:           "/wallet/v1/topups/abc",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:     "error not found",
+			endpoint: "/wallet/v1/topups/7",
+			mockFn: func() {
+				mockSvc.EXPECT().GetTopUp(gomock.Any(), tokenData.UserID, 7).Return(models.TopUpIntent{}, constants.ErrTopUpNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.endpoint, nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_TopUpCallback(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	payload := []byte(`{"reference":"TOP-1-1","gateway_reference":"FAKE-TOP-1-1","status":"paid","amount":100000}`)
+
+	tests := []struct {
+		name               string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mockSvc.EXPECT().HandleCallback(gomock.Any(), payload, "signature").Return(models.TopUpIntent{ID: 7, Status: constants.TopUpStatusPaid}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "error invalid signature",
+			mockFn: func() {
+				mockSvc.EXPECT().HandleCallback(gomock.Any(), payload, "signature").Return(models.TopUpIntent{}, constants.ErrInvalidGatewaySignature)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name: "error invalid callback",
+			mockFn: func() {
+				mockSvc.EXPECT().HandleCallback(gomock.Any(), payload, "signature").Return(models.TopUpIntent{}, constants.ErrInvalidGatewayCallback)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "error not pending",
+			mockFn: func() {
+				mockSvc.EXPECT().HandleCallback(gomock.Any(), payload, "signature").Return(models.TopUpIntent{}, constants.ErrTopUpNotPending)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "error",
+			mockFn: func() {
+				mockSvc.EXPECT().HandleCallback(gomock.Any(), payload, "signature").Return(models.TopUpIntent{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/wallet/v1/topups/callback", bytes.NewReader(payload))
+			assert.NoError(t, err)
+			req.Header.Set("X-Callback-Signature", "signature")
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
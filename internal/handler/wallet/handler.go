@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"context"
+	"ewallet-wallet/constants"
 	"ewallet-wallet/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -14,7 +15,11 @@ type Service interface {
 	DebitBalance(ctx context.Context, userID uint64, req models.TransactionRequest) (models.BalanceResponse, error)
 	GetBalance(ctx context.Context, userID uint64) (models.BalanceResponse, error)
 	GetWalletHistory(ctx context.Context, userID uint64, param models.WalletHistoryParam) ([]models.WalletTransaction, error)
+	GetStatement(ctx context.Context, userID uint64, dateFrom string, dateTo string) (models.Statement, error)
+	QuoteFee(ctx context.Context, userID uint64, param models.FeeQuoteParam) (models.FeeQuote, error)
+	UpdateSpendOrder(ctx context.Context, userID uint64, req models.SpendOrderRequest) error
 	ExGetBalance(ctx context.Context, walletID int) (models.BalanceResponse, error)
+	ExQuoteFee(ctx context.Context, walletID int, clientID string, param models.ExFeeQuoteParam) (models.FeeQuote, error)
 
 	CreateWalletLink(ctx context.Context, clientSource string, req *models.WalletLink) (*models.WalletStructOTP, error)
 	WalletLinkConfirmation(ctx context.Context, walletID int, clientSource string, otp string) error
@@ -41,10 +46,14 @@ func NewHandler(api *gin.Engine, service Service, ext External, mdw Middleware)
 func (h *Handler) RegisterRoute() {
 	walletV1 := h.Group("/wallet/v1")
 	walletV1.POST("/", h.Create)
-	walletV1.PUT("/balance/credit", h.Middleware.MiddlewareValidateToken, h.CreditBalance)
-	walletV1.PUT("/balance/debit", h.Middleware.MiddlewareValidateToken, h.DebitBalance)
-	walletV1.GET("/balance", h.Middleware.MiddlewareValidateToken, h.GetBalance)
-	walletV1.GET("/history", h.Middleware.MiddlewareValidateToken, h.GetWalletHistory)
+	// users top up through the gateway, only internal services credit directly
+	walletV1.PUT("/balance/credit", h.Middleware.MiddlewareInternalOnly, h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletCredit), h.CreditBalance)
+	walletV1.PUT("/balance/debit", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletDebit), h.DebitBalance)
+	walletV1.GET("/balance", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetBalance)
+	walletV1.GET("/history", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetWalletHistory)
+	walletV1.GET("/statement", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetStatement)
+	walletV1.GET("/fees/quote", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.QuoteFee)
+	walletV1.PUT("/spend-order", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletDebit), h.UpdateSpendOrder)
 
 	exWalletv1 := walletV1.Group("/ex")
 	exWalletv1.Use(h.Middleware.MiddlewareSignatureValidation)
@@ -52,5 +61,6 @@ func (h *Handler) RegisterRoute() {
 	exWalletv1.PUT("/link/:wallet_id/confirmation", h.WalletLinkConfirmation)
 	exWalletv1.DELETE("/:wallet_id/unlink", h.WalletUnlink)
 	exWalletv1.GET("/:wallet_id/balance", h.ExGetBalance)
+	exWalletv1.GET("/:wallet_id/fees/quote", h.ExQuoteFee)
 	exWalletv1.POST("/transaction", h.ExternalTransaction)
 }
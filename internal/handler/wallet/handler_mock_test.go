@@ -109,6 +109,21 @@ func (mr *MockServiceMockRecorder) ExGetBalance(ctx, walletID interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExGetBalance", reflect.TypeOf((*MockService)(nil).ExGetBalance), ctx, walletID)
 }
 
+// ExQuoteFee mocks base method.
+func (m *MockService) ExQuoteFee(ctx context.Context, walletID int, clientID string, param models.ExFeeQuoteParam) (models.FeeQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExQuoteFee", ctx, walletID, clientID, param)
+	ret0, _ := ret[0].(models.FeeQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExQuoteFee indicates an expected call of ExQuoteFee.
+func (mr *MockServiceMockRecorder) ExQuoteFee(ctx, walletID, clientID, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExQuoteFee", reflect.TypeOf((*MockService)(nil).ExQuoteFee), ctx, walletID, clientID, param)
+}
+
 // ExternalTransaction mocks base method.
 func (m *MockService) ExternalTransaction(ctx context.Context, req models.ExternalTransactionRequest) (models.BalanceResponse, error) {
 	m.ctrl.T.Helper()
@@ -139,6 +154,21 @@ func (mr *MockServiceMockRecorder) GetBalance(ctx, userID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockService)(nil).GetBalance), ctx, userID)
 }
 
+// GetStatement mocks base method.
+func (m *MockService) GetStatement(ctx context.Context, userID uint64, dateFrom, dateTo string) (models.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", ctx, userID, dateFrom, dateTo)
+	ret0, _ := ret[0].(models.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockServiceMockRecorder) GetStatement(ctx, userID, dateFrom, dateTo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockService)(nil).GetStatement), ctx, userID, dateFrom, dateTo)
+}
+
 // GetWalletHistory mocks base method.
 func (m *MockService) GetWalletHistory(ctx context.Context, userID uint64, param models.WalletHistoryParam) ([]models.WalletTransaction, error) {
 	m.ctrl.T.Helper()
@@ -154,6 +184,35 @@ func (mr *MockServiceMockRecorder) GetWalletHistory(ctx, userID, param interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletHistory", reflect.TypeOf((*MockService)(nil).GetWalletHistory), ctx, userID, param)
 }
 
+// QuoteFee mocks base method.
+func (m *MockService) QuoteFee(ctx context.Context, userID uint64, param models.FeeQuoteParam) (models.FeeQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteFee", ctx, userID, param)
+	ret0, _ := ret[0].(models.FeeQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteFee indicates an expected call of QuoteFee.
+func (mr *MockServiceMockRecorder) QuoteFee(ctx, userID, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteFee", reflect.TypeOf((*MockService)(nil).QuoteFee), ctx, userID, param)
+}
+
+// UpdateSpendOrder mocks base method.
+func (m *MockService) UpdateSpendOrder(ctx context.Context, userID uint64, req models.SpendOrderRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSpendOrder", ctx, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSpendOrder indicates an expected call of UpdateSpendOrder.
+func (mr *MockServiceMockRecorder) UpdateSpendOrder(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSpendOrder", reflect.TypeOf((*MockService)(nil).UpdateSpendOrder), ctx, userID, req)
+}
+
 // WalletLinkConfirmation mocks base method.
 func (m *MockService) WalletLinkConfirmation(ctx context.Context, walletID int, clientSource, otp string) error {
 	m.ctrl.T.Helper()
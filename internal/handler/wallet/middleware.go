@@ -5,5 +5,7 @@ import "github.com/gin-gonic/gin"
 //go:generate mockgen -source=middleware.go -destination=middleware_mock_test.go -package=wallet
 type Middleware interface {
 	MiddlewareValidateToken(c *gin.Context)
+	MiddlewareRequireScope(scope string) gin.HandlerFunc
 	MiddlewareSignatureValidation(c *gin.Context)
+	MiddlewareInternalOnly(c *gin.Context)
 }
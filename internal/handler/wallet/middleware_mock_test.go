@@ -34,6 +34,32 @@ func (m *MockMiddleware) EXPECT() *MockMiddlewareMockRecorder {
 	return m.recorder
 }
 
+// MiddlewareInternalOnly mocks base method.
+func (m *MockMiddleware) MiddlewareInternalOnly(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MiddlewareInternalOnly", c)
+}
+
+// MiddlewareInternalOnly indicates an expected call of MiddlewareInternalOnly.
+func (mr *MockMiddlewareMockRecorder) MiddlewareInternalOnly(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MiddlewareInternalOnly", reflect.TypeOf((*MockMiddleware)(nil).MiddlewareInternalOnly), c)
+}
+
+// MiddlewareRequireScope mocks base method.
+func (m *MockMiddleware) MiddlewareRequireScope(scope string) gin.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MiddlewareRequireScope", scope)
+	ret0, _ := ret[0].(gin.HandlerFunc)
+	return ret0
+}
+
+// MiddlewareRequireScope indicates an expected call of MiddlewareRequireScope.
+func (mr *MockMiddlewareMockRecorder) MiddlewareRequireScope(scope interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MiddlewareRequireScope", reflect.TypeOf((*MockMiddleware)(nil).MiddlewareRequireScope), scope)
+}
+
 // MiddlewareSignatureValidation mocks base method.
 func (m *MockMiddleware) MiddlewareSignatureValidation(c *gin.Context) {
 	m.ctrl.T.Helper()
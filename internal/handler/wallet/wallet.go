@@ -1,6 +1,8 @@
 package wallet
 
 import (
+	"bytes"
+	"errors"
 	"ewallet-wallet/constants"
 	"ewallet-wallet/helpers"
 	"ewallet-wallet/internal/models"
@@ -9,6 +11,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func (h *Handler) Create(c *gin.Context) {
@@ -74,6 +77,9 @@ func (h *Handler) CreditBalance(c *gin.Context) {
 
 	if err != nil {
 		fmt.Printf("failed to debit balance of wallet: %v", err)
+		if helpers.SendWalletError(c, err) {
+			return
+		}
 		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
 		return
 	}
@@ -115,6 +121,9 @@ func (h *Handler) DebitBalance(c *gin.Context) {
 	resp, err := h.Service.DebitBalance(c.Request.Context(), tokenData.UserID, req)
 	if err != nil {
 		fmt.Printf("failed to debit balance of wallet: %v", err)
+		if helpers.SendTransactionAuthError(c, err) || helpers.SendRiskError(c, err) || helpers.SendWalletError(c, err) {
+			return
+		}
 		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
 		return
 	}
@@ -160,7 +169,7 @@ func (h *Handler) GetWalletHistory(c *gin.Context) {
 	}
 
 	if param.WalletTransactionType != "" {
-		if param.WalletTransactionType != "CREDIT" && param.WalletTransactionType != "DEBIT" {
+		if param.WalletTransactionType != "CREDIT" && param.WalletTransactionType != "DEBIT" && param.WalletTransactionType != "ADJUSTMENT" {
 			fmt.Println("invalid wallet transaction_type")
 			helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrFailedBadRequest, nil)
 			return
@@ -191,6 +200,74 @@ func (h *Handler) GetWalletHistory(c *gin.Context) {
 	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
 }
 
+func (h *Handler) GetStatement(c *gin.Context) {
+	var (
+		param models.StatementParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := param.Validate(); err != nil {
+		fmt.Println("failed to validate query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	token, ok := c.Get("token")
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	tokenData, ok := token.(models.TokenData)
+	if !ok {
+		fmt.Println("failed to parse token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetStatement(c.Request.Context(), tokenData.UserID, param.DateFrom, param.DateTo)
+	if err != nil {
+		fmt.Println("failed to get statement: ", err)
+		if errors.Is(err, constants.ErrInvalidStatementRange) {
+			helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+			return
+		}
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	var (
+		buf         bytes.Buffer
+		contentType string
+	)
+	switch param.Format {
+	case constants.ReportFormatCSV:
+		contentType = "text/csv"
+		err = helpers.WriteStatementCSV(&buf, resp)
+	case constants.ReportFormatPDF:
+		contentType = "application/pdf"
+		err = helpers.WriteStatementPDF(&buf, resp)
+	default:
+		helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+		return
+	}
+	if err != nil {
+		fmt.Println("failed to write statement: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	fileName := fmt.Sprintf("statement-%s-%s.%s", param.DateFrom, param.DateTo, param.Format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
 func (h *Handler) CreateWalletLink(c *gin.Context) {
 	var (
 		req models.WalletLink
@@ -345,6 +422,134 @@ func (h *Handler) ExGetBalance(c *gin.Context) {
 	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
 }
 
+func (h *Handler) QuoteFee(c *gin.Context) {
+	var (
+		param models.FeeQuoteParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := param.Validate(); err != nil {
+		fmt.Println("failed to validate query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	token, ok := c.Get("token")
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	tokenData, ok := token.(models.TokenData)
+	if !ok {
+		fmt.Println("failed to parse token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.QuoteFee(c.Request.Context(), tokenData.UserID, param)
+	if err != nil {
+		fmt.Println("failed to quote fee: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) UpdateSpendOrder(c *gin.Context) {
+	var (
+		req models.SpendOrderRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	token, ok := c.Get("token")
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	tokenData, ok := token.(models.TokenData)
+	if !ok {
+		fmt.Println("failed to parse token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	err := h.Service.UpdateSpendOrder(c.Request.Context(), tokenData.UserID, req)
+	if err != nil {
+		fmt.Println("failed to update spend order: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, nil)
+}
+
+func (h *Handler) ExQuoteFee(c *gin.Context) {
+	var (
+		param models.ExFeeQuoteParam
+	)
+
+	walletID, err := strconv.Atoi(c.Param("wallet_id"))
+	if err != nil {
+		fmt.Println("failed to parse wallet id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := param.Validate(); err != nil {
+		fmt.Println("failed to validate query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	clientID, ok := c.Get("client_id")
+	if !ok {
+		fmt.Println("failed to get client id")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+	clientSource, _ := clientID.(string)
+
+	resp, err := h.Service.ExQuoteFee(c.Request.Context(), walletID, clientSource, param)
+	if err != nil {
+		fmt.Println("failed to quote fee: ", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+			return
+		}
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
 func (h *Handler) ExternalTransaction(c *gin.Context) {
 	var (
 		req models.ExternalTransactionRequest
@@ -356,9 +561,26 @@ func (h *Handler) ExternalTransaction(c *gin.Context) {
 		return
 	}
 
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	clientID, ok := c.Get("client_id")
+	if !ok {
+		fmt.Println("failed to get client id")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+	req.ClientID, _ = clientID.(string)
+
 	resp, err := h.Service.ExternalTransaction(c.Request.Context(), req)
 	if err != nil {
 		fmt.Println("failed to create external transaction, ", err)
+		if helpers.SendRiskError(c, err) || helpers.SendWalletError(c, err) {
+			return
+		}
 		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
 		return
 	}
@@ -7,6 +7,7 @@ import (
 	"ewallet-wallet/constants"
 	"ewallet-wallet/helpers"
 	"ewallet-wallet/internal/models"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -15,6 +16,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestHandler_Create(t *testing.T) {
@@ -23,6 +25,9 @@ func TestHandler_Create(t *testing.T) {
 
 	mockSvc := NewMockService(ctrlMock)
 	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
 	mockExt := NewMockExternal(ctrlMock)
 
 	ctx := context.Background()
@@ -136,6 +141,9 @@ func TestHandler_CreditBalance(t *testing.T) {
 
 	mockSvc := NewMockService(ctrlMock)
 	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
 	mockExt := NewMockExternal(ctrlMock)
 
 	reference := "reference"
@@ -150,6 +158,7 @@ func TestHandler_CreditBalance(t *testing.T) {
 		{
 			name: "success",
 			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareInternalOnly(gomock.Any())
 				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
 					tokenData := models.TokenData{
 						UserID:   1,
@@ -177,9 +186,21 @@ func TestHandler_CreditBalance(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "error not internal caller",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareInternalOnly(gomock.Any()).Do(func(c *gin.Context) {
+					helpers.SendResponseHTTP(c, http.StatusForbidden, "forbidden", nil)
+					c.Abort()
+				})
+			},
+			expectedStatusCode: http.StatusForbidden,
+			wantErr:            true,
+		},
 		{
 			name: "error ",
 			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareInternalOnly(gomock.Any())
 				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
 					tokenData := models.TokenData{
 						UserID:   1,
@@ -202,6 +223,23 @@ func TestHandler_CreditBalance(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "error wallet closed",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareInternalOnly(gomock.Any())
+				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("token", models.TokenData{UserID: 1})
+				})
+
+				transactionReq := models.TransactionRequest{
+					Reference: reference,
+					Amount:    100000,
+				}
+				mockSvc.EXPECT().CreditBalance(gomock.Any(), uint64(1), transactionReq).Return(models.BalanceResponse{}, fmt.Errorf("failed to credit balance: %w", constants.ErrWalletClosed))
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			wantErr:            true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -254,6 +292,9 @@ func TestHandler_DebitBalance(t *testing.T) {
 
 	mockSvc := NewMockService(ctrlMock)
 	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
 	mockExt := NewMockExternal(ctrlMock)
 
 	reference := "reference"
@@ -320,6 +361,102 @@ func TestHandler_DebitBalance(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "error pin required above the threshold",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("token", models.TokenData{UserID: 1})
+				})
+
+				transactionReq := models.TransactionRequest{
+					Reference: reference,
+					Amount:    100000,
+				}
+				mockSvc.EXPECT().DebitBalance(gomock.Any(), uint64(1), transactionReq).Return(models.BalanceResponse{}, constants.ErrPINRequired)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			wantErr:            true,
+		},
+		{
+			name: "error pin locked",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("token", models.TokenData{UserID: 1})
+				})
+
+				transactionReq := models.TransactionRequest{
+					Reference: reference,
+					Amount:    100000,
+				}
+				mockSvc.EXPECT().DebitBalance(gomock.Any(), uint64(1), transactionReq).Return(models.BalanceResponse{}, constants.ErrPINLocked)
+			},
+			expectedStatusCode: http.StatusLocked,
+			wantErr:            true,
+		},
+		{
+			name: "held for risk review",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("token", models.TokenData{UserID: 1})
+				})
+
+				transactionReq := models.TransactionRequest{
+					Reference: reference,
+					Amount:    100000,
+				}
+				mockSvc.EXPECT().DebitBalance(gomock.Any(), uint64(1), transactionReq).Return(models.BalanceResponse{}, constants.ErrTransactionUnderReview)
+			},
+			expectedStatusCode: http.StatusAccepted,
+			wantErr:            true,
+		},
+		{
+			name: "error blocked by risk screening",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("token", models.TokenData{UserID: 1})
+				})
+
+				transactionReq := models.TransactionRequest{
+					Reference: reference,
+					Amount:    100000,
+				}
+				mockSvc.EXPECT().DebitBalance(gomock.Any(), uint64(1), transactionReq).Return(models.BalanceResponse{}, constants.ErrTransactionBlocked)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			wantErr:            true,
+		},
+		{
+			name: "error insufficient balance",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("token", models.TokenData{UserID: 1})
+				})
+
+				transactionReq := models.TransactionRequest{
+					Reference: reference,
+					Amount:    100000,
+				}
+				mockSvc.EXPECT().DebitBalance(gomock.Any(), uint64(1), transactionReq).Return(models.BalanceResponse{}, fmt.Errorf("failed to debit balance: %w", constants.ErrInsufficientBalance))
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			wantErr:            true,
+		},
+		{
+			name: "error wallet frozen",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("token", models.TokenData{UserID: 1})
+				})
+
+				transactionReq := models.TransactionRequest{
+					Reference: reference,
+					Amount:    100000,
+				}
+				mockSvc.EXPECT().DebitBalance(gomock.Any(), uint64(1), transactionReq).Return(models.BalanceResponse{}, constants.ErrWalletFrozen)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			wantErr:            true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -372,6 +509,9 @@ func TestHandler_GetBalance(t *testing.T) {
 
 	mockSvc := NewMockService(ctrlMock)
 	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
 	mockExt := NewMockExternal(ctrlMock)
 
 	tokenData := models.TokenData{
@@ -463,6 +603,9 @@ func TestHandler_GetWalletHistory(t *testing.T) {
 
 	mockSvc := NewMockService(ctrlMock)
 	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
 	mockExt := NewMockExternal(ctrlMock)
 
 	reference := "REFERENCE"
@@ -617,6 +760,9 @@ func TestHandler_CreateWalletLink(t *testing.T) {
 
 	mockSvc := NewMockService(ctrlMock)
 	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
 	mockExt := NewMockExternal(ctrlMock)
 
 	clientSource := "fastcampus_ecommerce"
@@ -728,6 +874,9 @@ func TestHandler_WalletLinkConfirmation(t *testing.T) {
 
 	mockSvc := NewMockService(ctrlMock)
 	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
 	mockExt := NewMockExternal(ctrlMock)
 
 	clientSource := "fastcampus_ecommerce"
@@ -819,6 +968,9 @@ func TestHandler_WalletUnlink(t *testing.T) {
 
 	mockSvc := NewMockService(ctrlMock)
 	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
 	mockExt := NewMockExternal(ctrlMock)
 
 	clientSource := "fastcampus_ecommerce"
@@ -903,6 +1055,9 @@ func TestHandler_ExGetBalance(t *testing.T) {
 
 	mockSvc := NewMockService(ctrlMock)
 	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
 	mockExt := NewMockExternal(ctrlMock)
 
 	clientID := "fastcampus_ecommerce"
@@ -994,6 +1149,9 @@ func TestHandler_ExternalTransaction(t *testing.T) {
 
 	mockSvc := NewMockService(ctrlMock)
 	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
 	mockExt := NewMockExternal(ctrlMock)
 
 	clientID := "fastcampus_ecommerce"
@@ -1003,6 +1161,7 @@ func TestHandler_ExternalTransaction(t *testing.T) {
 	tests := []struct {
 		name               string
 		mockFn             func()
+		transactionType    string
 		expectedStatusCode int
 		expectedBody       helpers.Response
 		wantErr            bool
@@ -1020,6 +1179,7 @@ func TestHandler_ExternalTransaction(t *testing.T) {
 					Reference:       reference,
 					TransactionType: transactionType,
 					WalletID:        1,
+					ClientID:        clientID,
 				}).Return(models.BalanceResponse{
 					Balance: 100000,
 				}, nil)
@@ -1033,6 +1193,18 @@ func TestHandler_ExternalTransaction(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "error invalid transaction type",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareSignatureValidation(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("client_id", clientID)
+					c.Next()
+				})
+			},
+			transactionType:    "ADJUSTMENT",
+			expectedStatusCode: http.StatusBadRequest,
+			wantErr:            true,
+		},
 		{
 			name: "error",
 			mockFn: func() {
@@ -1046,6 +1218,7 @@ func TestHandler_ExternalTransaction(t *testing.T) {
 					Reference:       reference,
 					TransactionType: transactionType,
 					WalletID:        1,
+					ClientID:        clientID,
 				}).Return(models.BalanceResponse{}, assert.AnError)
 			},
 			expectedStatusCode: http.StatusInternalServerError,
@@ -1054,6 +1227,25 @@ func TestHandler_ExternalTransaction(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "error wallet suspended",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareSignatureValidation(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("client_id", clientID)
+					c.Next()
+				})
+
+				mockSvc.EXPECT().ExternalTransaction(gomock.Any(), models.ExternalTransactionRequest{
+					Amount:          100000,
+					Reference:       reference,
+					TransactionType: transactionType,
+					WalletID:        1,
+					ClientID:        clientID,
+				}).Return(models.BalanceResponse{}, constants.ErrWalletSuspended)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			wantErr:            true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1076,6 +1268,9 @@ func TestHandler_ExternalTransaction(t *testing.T) {
 				TransactionType: transactionType,
 				WalletID:        1,
 			}
+			if tt.transactionType != "" {
+				model.TransactionType = tt.transactionType
+			}
 			val, err := json.Marshal(model)
 			assert.NoError(t, err)
 
@@ -1098,3 +1293,316 @@ func TestHandler_ExternalTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_GetStatement(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+	mockExt := NewMockExternal(ctrlMock)
+
+	tokenData := models.TokenData{
+		UserID:   1,
+		Username: "username",
+		Fullname: "fullname",
+		Email:    "email",
+	}
+	date := time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC)
+	statement := models.Statement{
+		WalletID:       1,
+		DateFrom:       "2024-05-01",
+		DateTo:         "2024-05-31",
+		OpeningBalance: 100,
+		TotalCredit:    50,
+		ClosingBalance: 150,
+		Entries: []models.StatementEntry{
+			{ID: 3, Date: date, WalletTransactionType: "CREDIT", Reference: "REFERENCE", Amount: 50, Balance: 150},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		mockFn              func()
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:  "success json",
+			query: "date_from=2024-05-01&date_to=2024-05-31",
+			mockFn: func() {
+				mockSvc.EXPECT().GetStatement(gomock.Any(), tokenData.UserID, "2024-05-01", "2024-05-31").Return(statement, nil)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{"message":"success","data":{"wallet_id":1,"date_from":"2024-05-01","date_to":"2024-05-31","opening_balance":100,"total_credit":50,"total_debit":0,"closing_balance":150,` +
+				`"entries":[{"id":3,"date":"2024-05-10T08:00:00Z","wallet_transaction_type":"CREDIT","reference":"REFERENCE","amount":50,"balance":150}]}}`,
+		},
+		{
+			name:  "success csv",
+			query: "date_from=2024-05-01&date_to=2024-05-31&format=csv",
+			mockFn: func() {
+				mockSvc.EXPECT().GetStatement(gomock.Any(), tokenData.UserID, "2024-05-01", "2024-05-31").Return(statement, nil)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody: "date,id,wallet_transaction_type,reference,amount,balance\n" +
+				"2024-05-01,,OPENING_BALANCE,,,100.00\n" +
+				"2024-05-10 08:00:00,3,CREDIT,REFERENCE,50.00,150.00\n" +
+				"2024-05-31,,CLOSING_BALANCE,,,150.00\n",
+		},
+		{
+			name:  "success pdf",
+			query: "date_from=2024-05-01&date_to=2024-05-31&format=pdf",
+			mockFn: func() {
+				mockSvc.EXPECT().GetStatement(gomock.Any(), tokenData.UserID, "2024-05-01", "2024-05-31").Return(statement, nil)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/pdf",
+		},
+		{
+			name:               "error unknown format",
+			query:              "date_from=2024-05-01&date_to=2024-05-31&format=xlsx",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "error invalid range",
+			query: "date_from=2024-05-31&date_to=2024-05-01",
+			mockFn: func() {
+				mockSvc.EXPECT().GetStatement(gomock.Any(), tokenData.UserID, "2024-05-31", "2024-05-01").
+					Return(models.Statement{}, constants.ErrInvalidStatementRange)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "error",
+			query: "date_from=2024-05-01&date_to=2024-05-31",
+			mockFn: func() {
+				mockSvc.EXPECT().GetStatement(gomock.Any(), tokenData.UserID, "2024-05-01", "2024-05-31").Return(models.Statement{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				External:   mockExt,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/wallet/v1/statement?"+tt.query, nil)
+			assert.NoError(t, err)
+
+			h.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			switch tt.expectedContentType {
+			case "application/pdf":
+				assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")))
+			default:
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_QuoteFee(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+	mockExt := NewMockExternal(ctrlMock)
+
+	tokenData := models.TokenData{
+		UserID:   1,
+		Username: "username",
+		Fullname: "fullname",
+		Email:    "email",
+	}
+	param := models.FeeQuoteParam{Operation: constants.FeeOperationWithdrawal, Amount: 100000}
+
+	tests := []struct {
+		name               string
+		endPoint           string
+		mockFn             func()
+		expectedStatusCode int
+		expectedBody       helpers.Response
+		wantErr            bool
+	}{
+		{
+			name:     "success",
+			endPoint: "/wallet/v1/fees/quote?operation=withdrawal&amount=100000",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("token", tokenData)
+				})
+
+				mockSvc.EXPECT().QuoteFee(gomock.Any(), tokenData.UserID, param).Return(models.FeeQuote{
+					Operation: constants.FeeOperationWithdrawal,
+					Amount:    100000,
+					Fee:       2500,
+					Total:     102500,
+					RuleID:    1,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: helpers.Response{
+				Message: constants.SuccessMessage,
+				Data: map[string]interface{}{
+					"operation": constants.FeeOperationWithdrawal,
+					"amount":    float64(100000),
+					"fee":       float64(2500),
+					"total":     float64(102500),
+					"rule_id":   float64(1),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "error external debit is quoted by the partner",
+			endPoint: "/wallet/v1/fees/quote?operation=external_debit&amount=100000",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("token", tokenData)
+				})
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			wantErr:            true,
+		},
+		{
+			name:     "error",
+			endPoint: "/wallet/v1/fees/quote?operation=withdrawal&amount=100000",
+			mockFn: func() {
+				mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+					c.Set("token", tokenData)
+				})
+
+				mockSvc.EXPECT().QuoteFee(gomock.Any(), tokenData.UserID, param).Return(models.FeeQuote{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				External:   mockExt,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.endPoint, nil)
+			assert.NoError(t, err)
+
+			h.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if !tt.wantErr {
+				response := helpers.Response{}
+				err = json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				assert.Equal(t, tt.expectedBody, response)
+			}
+		})
+	}
+}
+
+func TestHandler_ExQuoteFee(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+	mockExt := NewMockExternal(ctrlMock)
+
+	clientID := "fastcampus_ecommerce"
+	param := models.ExFeeQuoteParam{Amount: 50000}
+
+	tests := []struct {
+		name               string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mockSvc.EXPECT().ExQuoteFee(gomock.Any(), 1, clientID, param).Return(models.FeeQuote{
+					Operation: constants.FeeOperationExternalDebit,
+					Amount:    50000,
+					Fee:       500,
+					Total:     50500,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "error wallet not found",
+			mockFn: func() {
+				mockSvc.EXPECT().ExQuoteFee(gomock.Any(), 1, clientID, param).Return(models.FeeQuote{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "error",
+			mockFn: func() {
+				mockSvc.EXPECT().ExQuoteFee(gomock.Any(), 1, clientID, param).Return(models.FeeQuote{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareSignatureValidation(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("client_id", clientID)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				External:   mockExt,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/wallet/v1/ex/1/fees/quote?amount=50000", nil)
+			assert.NoError(t, err)
+
+			h.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
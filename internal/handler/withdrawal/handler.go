@@ -0,0 +1,49 @@
+package withdrawal
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=handler.go -destination=handler_mock_test.go -package=withdrawal
+type Service interface {
+	AddBankAccount(ctx context.Context, userID uint64, req models.BankAccountRequest) (models.BankAccount, error)
+	GetBankAccounts(ctx context.Context, userID uint64) ([]models.BankAccount, error)
+	DeleteBankAccount(ctx context.Context, userID uint64, accountID int) error
+	CreateWithdrawal(ctx context.Context, userID uint64, req models.WithdrawalRequest) (models.Withdrawal, error)
+	GetWithdrawal(ctx context.Context, userID uint64, withdrawalID int) (models.Withdrawal, error)
+	GetWithdrawals(ctx context.Context, userID uint64, param models.WithdrawalParam) ([]models.Withdrawal, error)
+	HandleCallback(ctx context.Context, payload []byte, signature string) (models.Withdrawal, error)
+}
+
+type Handler struct {
+	*gin.Engine
+	Service    Service
+	Middleware Middleware
+}
+
+func NewHandler(api *gin.Engine, service Service, mdw Middleware) *Handler {
+	return &Handler{
+		api,
+		service,
+		mdw,
+	}
+}
+
+func (h *Handler) RegisterRoute() {
+	bankAccountV1 := h.Group("/wallet/v1/bank-accounts")
+	bankAccountV1.POST("", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletDebit), h.AddBankAccount)
+	bankAccountV1.GET("", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetBankAccounts)
+	bankAccountV1.DELETE("/:account_id", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletDebit), h.DeleteBankAccount)
+
+	withdrawalV1 := h.Group("/wallet/v1/withdrawals")
+	withdrawalV1.POST("", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletDebit), h.CreateWithdrawal)
+	withdrawalV1.GET("", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetWithdrawals)
+	withdrawalV1.GET("/:withdrawal_id", h.Middleware.MiddlewareValidateToken, h.Middleware.MiddlewareRequireScope(constants.ScopeWalletRead), h.GetWithdrawal)
+
+	// called by the payout provider, authenticated by the callback signature
+	withdrawalV1.POST("/callback", h.WithdrawalCallback)
+}
@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: handler.go
+
+// Package withdrawal is a generated GoMock package.
+package withdrawal
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// AddBankAccount mocks base method.
+func (m *MockService) AddBankAccount(ctx context.Context, userID uint64, req models.BankAccountRequest) (models.BankAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBankAccount", ctx, userID, req)
+	ret0, _ := ret[0].(models.BankAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBankAccount indicates an expected call of AddBankAccount.
+func (mr *MockServiceMockRecorder) AddBankAccount(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBankAccount", reflect.TypeOf((*MockService)(nil).AddBankAccount), ctx, userID, req)
+}
+
+// CreateWithdrawal mocks base method.
+func (m *MockService) CreateWithdrawal(ctx context.Context, userID uint64, req models.WithdrawalRequest) (models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithdrawal", ctx, userID, req)
+	ret0, _ := ret[0].(models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithdrawal indicates an expected call of CreateWithdrawal.
+func (mr *MockServiceMockRecorder) CreateWithdrawal(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdrawal", reflect.TypeOf((*MockService)(nil).CreateWithdrawal), ctx, userID, req)
+}
+
+// DeleteBankAccount mocks base method.
+func (m *MockService) DeleteBankAccount(ctx context.Context, userID uint64, accountID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBankAccount", ctx, userID, accountID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBankAccount indicates an expected call of DeleteBankAccount.
+func (mr *MockServiceMockRecorder) DeleteBankAccount(ctx, userID, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBankAccount", reflect.TypeOf((*MockService)(nil).DeleteBankAccount), ctx, userID, accountID)
+}
+
+// GetBankAccounts mocks base method.
+func (m *MockService) GetBankAccounts(ctx context.Context, userID uint64) ([]models.BankAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBankAccounts", ctx, userID)
+	ret0, _ := ret[0].([]models.BankAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBankAccounts indicates an expected call of GetBankAccounts.
+func (mr *MockServiceMockRecorder) GetBankAccounts(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankAccounts", reflect.TypeOf((*MockService)(nil).GetBankAccounts), ctx, userID)
+}
+
+// GetWithdrawal mocks base method.
+func (m *MockService) GetWithdrawal(ctx context.Context, userID uint64, withdrawalID int) (models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawal", ctx, userID, withdrawalID)
+	ret0, _ := ret[0].(models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawal indicates an expected call of GetWithdrawal.
+func (mr *MockServiceMockRecorder) GetWithdrawal(ctx, userID, withdrawalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawal", reflect.TypeOf((*MockService)(nil).GetWithdrawal), ctx, userID, withdrawalID)
+}
+
+// GetWithdrawals mocks base method.
+func (m *MockService) GetWithdrawals(ctx context.Context, userID uint64, param models.WithdrawalParam) ([]models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawals", ctx, userID, param)
+	ret0, _ := ret[0].([]models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawals indicates an expected call of GetWithdrawals.
+func (mr *MockServiceMockRecorder) GetWithdrawals(ctx, userID, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockService)(nil).GetWithdrawals), ctx, userID, param)
+}
+
+// HandleCallback mocks base method.
+func (m *MockService) HandleCallback(ctx context.Context, payload []byte, signature string) (models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleCallback", ctx, payload, signature)
+	ret0, _ := ret[0].(models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HandleCallback indicates an expected call of HandleCallback.
+func (mr *MockServiceMockRecorder) HandleCallback(ctx, payload, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleCallback", reflect.TypeOf((*MockService)(nil).HandleCallback), ctx, payload, signature)
+}
@@ -0,0 +1,231 @@
+package withdrawal
+
+import (
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) AddBankAccount(c *gin.Context) {
+	var (
+		req models.BankAccountRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.AddBankAccount(c.Request.Context(), tokenData.UserID, req)
+	if err != nil {
+		fmt.Println("failed to add bank account: ", err)
+		sendWithdrawalError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetBankAccounts(c *gin.Context) {
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetBankAccounts(c.Request.Context(), tokenData.UserID)
+	if err != nil {
+		fmt.Println("failed to get bank accounts: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) DeleteBankAccount(c *gin.Context) {
+	accountID, err := strconv.Atoi(c.Param("account_id"))
+	if err != nil {
+		fmt.Println("failed to parse account id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	err = h.Service.DeleteBankAccount(c.Request.Context(), tokenData.UserID, accountID)
+	if err != nil {
+		fmt.Println("failed to delete bank account: ", err)
+		sendWithdrawalError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, nil)
+}
+
+func (h *Handler) CreateWithdrawal(c *gin.Context) {
+	var (
+		req models.WithdrawalRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Println("failed to parse request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fmt.Println("failed to validate request: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.CreateWithdrawal(c.Request.Context(), tokenData.UserID, req)
+	if err != nil {
+		fmt.Println("failed to create withdrawal: ", err)
+		sendWithdrawalError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetWithdrawals(c *gin.Context) {
+	var (
+		param models.WithdrawalParam
+	)
+
+	if err := c.ShouldBindQuery(&param); err != nil {
+		fmt.Println("failed to parse query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	if err := param.Validate(); err != nil {
+		fmt.Println("failed to validate query param: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetWithdrawals(c.Request.Context(), tokenData.UserID, param)
+	if err != nil {
+		fmt.Println("failed to get withdrawals: ", err)
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) GetWithdrawal(c *gin.Context) {
+	withdrawalID, err := strconv.Atoi(c.Param("withdrawal_id"))
+	if err != nil {
+		fmt.Println("failed to parse withdrawal id to int : ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	tokenData, ok := getTokenData(c)
+	if !ok {
+		fmt.Println("failed to get token data")
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return
+	}
+
+	resp, err := h.Service.GetWithdrawal(c.Request.Context(), tokenData.UserID, withdrawalID)
+	if err != nil {
+		fmt.Println("failed to get withdrawal: ", err)
+		sendWithdrawalError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func (h *Handler) WithdrawalCallback(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		fmt.Println("failed to read callback body: ", err)
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+		return
+	}
+
+	resp, err := h.Service.HandleCallback(c.Request.Context(), payload, c.Request.Header.Get(constants.HeaderCallbackSignature))
+	if err != nil {
+		fmt.Println("failed to handle payout callback: ", err)
+		sendWithdrawalError(c, err)
+		return
+	}
+
+	helpers.SendResponseHTTP(c, http.StatusOK, constants.SuccessMessage, resp)
+}
+
+func sendWithdrawalError(c *gin.Context, err error) {
+	if helpers.SendTransactionAuthError(c, err) || helpers.SendRiskError(c, err) || helpers.SendWalletError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, constants.ErrInvalidPayoutSignature):
+		helpers.SendResponseHTTP(c, http.StatusUnauthorized, "unauthorized", nil)
+	case errors.Is(err, constants.ErrBankAccountNotFound), errors.Is(err, constants.ErrWithdrawalNotFound):
+		helpers.SendResponseHTTP(c, http.StatusNotFound, constants.ErrFailedBadRequest, nil)
+	case errors.Is(err, constants.ErrInvalidBankAccount), errors.Is(err, constants.ErrInvalidPayoutCallback), errors.Is(err, constants.ErrDuplicateReference):
+		helpers.SendResponseHTTP(c, http.StatusBadRequest, constants.ErrFailedBadRequest, nil)
+	case errors.Is(err, constants.ErrWithdrawalNotInFlight):
+		helpers.SendResponseHTTP(c, http.StatusUnprocessableEntity, constants.ErrFailedBadRequest, nil)
+	default:
+		helpers.SendResponseHTTP(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+	}
+}
+
+func getTokenData(c *gin.Context) (models.TokenData, bool) {
+	token, ok := c.Get("token")
+	if !ok {
+		return models.TokenData{}, false
+	}
+
+	tokenData, ok := token.(models.TokenData)
+	return tokenData, ok
+}
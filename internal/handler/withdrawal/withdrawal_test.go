@@ -0,0 +1,416 @@
+package withdrawal
+
+import (
+	"bytes"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_AddBankAccount(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   10,
+		Username: "username",
+	}
+	req := models.BankAccountRequest{BankCode: "BCA", AccountNumber: "1234567890"}
+
+	tests := []struct {
+		name               string
+		body               models.BankAccountRequest
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().AddBankAccount(gomock.Any(), tokenData.UserID, req).Return(models.BankAccount{ID: 3}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error non numeric account number",
+			body:               models.BankAccountRequest{BankCode: "BCA", AccountNumber: "12345abc"},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "error invalid bank account",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().AddBankAccount(gomock.Any(), tokenData.UserID, req).Return(models.BankAccount{}, constants.ErrInvalidBankAccount)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "error",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().AddBankAccount(gomock.Any(), tokenData.UserID, req).Return(models.BankAccount{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/wallet/v1/bank-accounts", bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_DeleteBankAccount(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   10,
+		Username: "username",
+	}
+
+	tests := []struct {
+		name               string
+		endpoint           string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name:     "success",
+			endpoint: "/wallet/v1/bank-accounts/3",
+			mockFn: func() {
+				mockSvc.EXPECT().DeleteBankAccount(gomock.Any(), tokenData.UserID, 3).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error invalid id",
+			endpoint:           "/wallet/v1/bank-accounts/abc",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:     "error not found",
+			endpoint: "/wallet/v1/bank-accounts/3",
+			mockFn: func() {
+				mockSvc.EXPECT().DeleteBankAccount(gomock.Any(), tokenData.UserID, 3).Return(constants.ErrBankAccountNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodDelete, tt.endpoint, nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_CreateWithdrawal(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   10,
+		Username: "username",
+	}
+	req := models.WithdrawalRequest{BankAccountID: 3, Amount: 100000, Reference: "ref-1"}
+
+	tests := []struct {
+		name               string
+		body               models.WithdrawalRequest
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateWithdrawal(gomock.Any(), tokenData.UserID, req).
+					Return(models.Withdrawal{ID: 5, Status: constants.WithdrawalStatusProcessing}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error missing reference",
+			body:               models.WithdrawalRequest{BankAccountID: 3, Amount: 100000},
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "error duplicate reference",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateWithdrawal(gomock.Any(), tokenData.UserID, req).Return(models.Withdrawal{}, constants.ErrDuplicateReference)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "error insufficient balance",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateWithdrawal(gomock.Any(), tokenData.UserID, req).Return(models.Withdrawal{}, constants.ErrInsufficientBalance)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "error bank account not found",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateWithdrawal(gomock.Any(), tokenData.UserID, req).Return(models.Withdrawal{}, constants.ErrBankAccountNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "success held for review",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateWithdrawal(gomock.Any(), tokenData.UserID, req).Return(models.Withdrawal{}, constants.ErrTransactionUnderReview)
+			},
+			expectedStatusCode: http.StatusAccepted,
+		},
+		{
+			name: "error blocked",
+			body: req,
+			mockFn: func() {
+				mockSvc.EXPECT().CreateWithdrawal(gomock.Any(), tokenData.UserID, req).Return(models.Withdrawal{}, constants.ErrTransactionBlocked)
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			val, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/wallet/v1/withdrawals", bytes.NewReader(val))
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_GetWithdrawal(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	tokenData := models.TokenData{
+		UserID:   10,
+		Username: "username",
+	}
+
+	tests := []struct {
+		name               string
+		endpoint           string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name:     "success",
+			endpoint: "/wallet/v1/withdrawals/5",
+			mockFn: func() {
+				mockSvc.EXPECT().GetWithdrawal(gomock.Any(), tokenData.UserID, 5).Return(models.Withdrawal{ID: 5}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:     "success list",
+			endpoint: "/wallet/v1/withdrawals?status=processing",
+			mockFn: func() {
+				mockSvc.EXPECT().GetWithdrawals(gomock.Any(), tokenData.UserID, models.WithdrawalParam{Status: constants.WithdrawalStatusProcessing}).Return([]models.Withdrawal{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error unknown status",
+			endpoint:           "/wallet/v1/withdrawals?status=unknown",
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:     "error not found",
+			endpoint: "/wallet/v1/withdrawals/5",
+			mockFn: func() {
+				mockSvc.EXPECT().GetWithdrawal(gomock.Any(), tokenData.UserID, 5).Return(models.Withdrawal{}, constants.ErrWithdrawalNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			mockMdw.EXPECT().MiddlewareValidateToken(gomock.Any()).Do(func(c *gin.Context) {
+				c.Set("token", tokenData)
+				c.Next()
+			})
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.endpoint, nil)
+			assert.NoError(t, err)
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestHandler_WithdrawalCallback(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockSvc := NewMockService(ctrlMock)
+	mockMdw := NewMockMiddleware(ctrlMock)
+	mockMdw.EXPECT().MiddlewareRequireScope(gomock.Any()).Return(func(c *gin.Context) {
+		c.Next()
+	}).AnyTimes()
+
+	payload := []byte(`{"reference":"WDR-1-1","provider_reference":"SIM-WDR-1-1","status":"succeeded"}`)
+
+	tests := []struct {
+		name               string
+		mockFn             func()
+		expectedStatusCode int
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mockSvc.EXPECT().HandleCallback(gomock.Any(), payload, "signature").Return(models.Withdrawal{ID: 5, Status: constants.WithdrawalStatusSucceeded}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "error invalid signature",
+			mockFn: func() {
+				mockSvc.EXPECT().HandleCallback(gomock.Any(), payload, "signature").Return(models.Withdrawal{}, constants.ErrInvalidPayoutSignature)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name: "error not in flight",
+			mockFn: func() {
+				mockSvc.EXPECT().HandleCallback(gomock.Any(), payload, "signature").Return(models.Withdrawal{}, constants.ErrWithdrawalNotInFlight)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "error",
+			mockFn: func() {
+				mockSvc.EXPECT().HandleCallback(gomock.Any(), payload, "signature").Return(models.Withdrawal{}, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			api := gin.New()
+			h := &Handler{
+				Engine:     api,
+				Service:    mockSvc,
+				Middleware: mockMdw,
+			}
+			h.RegisterRoute()
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/wallet/v1/withdrawals/callback", bytes.NewReader(payload))
+			assert.NoError(t, err)
+			req.Header.Set("X-Callback-Signature", "signature")
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
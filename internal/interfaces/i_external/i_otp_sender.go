@@ -0,0 +1,11 @@
+package i_external
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+)
+
+//go:generate mockgen -source=i_otp_sender.go -destination=../../services/otp_sender_mock_test.go -package=services
+type OTPSender interface {
+	SendOTP(ctx context.Context, msg models.OTPMessage) error
+}
@@ -0,0 +1,15 @@
+package i_external
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+)
+
+//go:generate mockgen -source=i_payment_gateway.go -destination=../../services/payment_gateway_mock_test.go -package=services
+type PaymentGateway interface {
+	Name() string
+	CreatePayment(ctx context.Context, req models.GatewayPaymentRequest) (models.GatewayPayment, error)
+	// VerifyCallback checks the signature of a raw callback body before
+	// parsing it, a bad signature returns constants.ErrInvalidGatewaySignature.
+	VerifyCallback(ctx context.Context, payload []byte, signature string) (models.GatewayCallback, error)
+}
@@ -0,0 +1,18 @@
+package i_external
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+)
+
+//go:generate mockgen -source=i_payout_provider.go -destination=../../services/payout_provider_mock_test.go -package=services
+type PayoutProvider interface {
+	Name() string
+	// InquireAccount returns the holder name of a bank account, an unknown
+	// account returns constants.ErrInvalidBankAccount.
+	InquireAccount(ctx context.Context, bankCode string, accountNumber string) (string, error)
+	// CreatePayout has to be idempotent per reference, a payout is resubmitted
+	// when the first attempt did not get an answer.
+	CreatePayout(ctx context.Context, req models.PayoutRequest) (models.Payout, error)
+	VerifyCallback(ctx context.Context, payload []byte, signature string) (models.PayoutCallback, error)
+}
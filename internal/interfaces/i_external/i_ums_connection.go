@@ -0,0 +1,13 @@
+package i_external
+
+import (
+	"google.golang.org/grpc/connectivity"
+)
+
+//go:generate mockgen -source=i_ums_connection.go -destination=../../services/ums_connection_mock_test.go -package=services
+type UMSConnection interface {
+	// ConnState is the state of the gRPC connection to the UMS
+	ConnState() connectivity.State
+	// State is the state of the circuit breaker in front of it
+	State() string
+}
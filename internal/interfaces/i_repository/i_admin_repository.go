@@ -0,0 +1,22 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=i_admin_repository.go -destination=../../services/admin_repository_mock_test.go -package=services
+type IAdminRepo interface {
+	CreateOperator(ctx context.Context, operator *models.Operator) error
+	GetOperatorByUsername(ctx context.Context, username string) (models.Operator, error)
+	CountOperators(ctx context.Context) (int64, error)
+
+	CreateAdjustment(ctx context.Context, adjustment *models.BalanceAdjustment) error
+	GetAdjustmentByID(ctx context.Context, adjustmentID int) (models.BalanceAdjustment, error)
+	GetAdjustments(ctx context.Context, param models.AdjustmentParam, offset int, limit int) ([]models.BalanceAdjustment, error)
+	DecideAdjustment(ctx context.Context, adjustmentID int, status string, checkerID int, checkerUsername string, note string, now time.Time) error
+	ApproveAdjustment(ctx context.Context, adjustmentID int, checkerID int, checkerUsername string, note string, walletTrx *models.WalletTransaction, now time.Time) (models.Wallet, error)
+	FailAdjustment(ctx context.Context, adjustmentID int, checkerID int, checkerUsername string, note string, reason string, now time.Time) error
+	UpdateAdjustmentStatus(ctx context.Context, adjustmentID int, fromStatus string, toStatus string) error
+}
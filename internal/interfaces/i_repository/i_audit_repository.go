@@ -0,0 +1,13 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+)
+
+//go:generate mockgen -source=i_audit_repository.go -destination=../../services/audit_repository_mock_test.go -package=services
+type IAuditRepo interface {
+	AppendAuditLog(ctx context.Context, auditLog *models.AuditLog) error
+	GetAuditLogs(ctx context.Context, param models.AuditLogParam, offset int, limit int) ([]models.AuditLog, error)
+	GetAuditLogChain(ctx context.Context, afterID int, limit int) ([]models.AuditLog, error)
+}
@@ -0,0 +1,16 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=i_fee_repository.go -destination=../../services/fee_repository_mock_test.go -package=services
+type IFeeRepo interface {
+	CreateFeeRule(ctx context.Context, rule *models.FeeRule) error
+	GetFeeRules(ctx context.Context, operation string, offset int, limit int) ([]models.FeeRule, error)
+	GetActiveFeeRules(ctx context.Context, operation string) ([]models.FeeRule, error)
+	DisableFeeRule(ctx context.Context, ruleID int, now time.Time) error
+	CountPaidTransfers(ctx context.Context, walletID int, since time.Time) (int, error)
+}
@@ -1,4 +1,12 @@
 package i_repository
 
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+)
+
+//go:generate mockgen -source=i_healthcheckRepo.go -destination=../../services/healthcheck_repository_mock_test.go -package=services
 type IHealthcheckRepo interface {
+	Ping(ctx context.Context) error
+	GetMigrationHealth(ctx context.Context) (models.MigrationHealth, error)
 }
@@ -0,0 +1,17 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=i_payment_request_repository.go -destination=../../services/payment_request_repository_mock_test.go -package=services
+type IPaymentRequestRepo interface {
+	CreatePaymentRequest(ctx context.Context, request *models.PaymentRequest) error
+	GetPaymentRequestByID(ctx context.Context, requestID int) (models.PaymentRequest, error)
+	GetPaymentRequests(ctx context.Context, requesterUserID uint64, payerUserID uint64, status string, offset int, limit int) ([]models.PaymentRequest, error)
+	UpdatePaymentRequestStatus(ctx context.Context, requestID int, toStatus string, now time.Time) error
+	PayPaymentRequest(ctx context.Context, requestID int, debit *models.WalletTransaction, credit *models.WalletTransaction, fee *models.FeePosting, now time.Time) (models.Wallet, models.Wallet, error)
+	GetExpiredPaymentRequests(ctx context.Context, now time.Time, limit int) ([]models.PaymentRequest, error)
+}
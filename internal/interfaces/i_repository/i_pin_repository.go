@@ -0,0 +1,18 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=i_pin_repository.go -destination=../../services/pin_repository_mock_test.go -package=services
+type IPINRepo interface {
+	GetWalletPIN(ctx context.Context, walletID int) (models.WalletPIN, error)
+	CreateWalletPIN(ctx context.Context, pin *models.WalletPIN) error
+	UpdatePINHash(ctx context.Context, walletID int, pinHash string, now time.Time) error
+	ReservePINAttempt(ctx context.Context, walletID int, maxAttempts int, now time.Time, lockUntil time.Time) (int, error)
+	ResetPINFailures(ctx context.Context, walletID int) error
+	SaveResetOTP(ctx context.Context, walletID int, otpHash string, now time.Time) error
+	ReserveResetAttempt(ctx context.Context, walletID int, maxAttempts int) (int, error)
+}
@@ -0,0 +1,20 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=i_promo_repository.go -destination=../../services/promo_repository_mock_test.go -package=services
+type IPromoRepo interface {
+	CreateCampaign(ctx context.Context, campaign *models.Campaign) error
+	GetCampaigns(ctx context.Context, clientSource string, offset int, limit int) ([]models.Campaign, error)
+	GetActiveCampaigns(ctx context.Context, clientSource string, now time.Time) ([]models.Campaign, error)
+	DisableCampaign(ctx context.Context, campaignID int, now time.Time) error
+
+	GrantPromo(ctx context.Context, grant *models.PromoGrant, credit *models.WalletTransaction) (models.Wallet, error)
+	GetPromoGrants(ctx context.Context, walletID int, offset int, limit int) ([]models.PromoGrant, error)
+	GetExpiredPromoGrants(ctx context.Context, now time.Time, limit int) ([]models.PromoGrant, error)
+	ExpirePromoGrant(ctx context.Context, grant models.PromoGrant, clawback *models.WalletTransaction, now time.Time) (models.Wallet, error)
+}
@@ -0,0 +1,18 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+)
+
+//go:generate mockgen -source=i_reconciliation_repository.go -destination=../../services/reconciliation_repository_mock_test.go -package=services
+type IReconciliationRepo interface {
+	GetLedgerSnapshot(ctx context.Context, walletIDs []int) (models.LedgerSnapshot, error)
+	GetLastCleanTransactionID(ctx context.Context, walletID int) (int, error)
+	GetTransactionWindow(ctx context.Context, walletID int, afterID int, toID int) (models.TransactionWindow, error)
+
+	CreateRun(ctx context.Context, run *models.ReconciliationRun) error
+	UpdateRun(ctx context.Context, run *models.ReconciliationRun) error
+	CreateMismatches(ctx context.Context, mismatches []models.ReconciliationMismatch) error
+	CreateSuspenseEntry(ctx context.Context, entry *models.SuspenseEntry) (bool, error)
+}
@@ -0,0 +1,21 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=i_risk_repository.go -destination=../../services/risk_repository_mock_test.go -package=services
+type IRiskRepo interface {
+	CountDebitsSince(ctx context.Context, walletID int, since time.Time) (int, error)
+	CountLinksSince(ctx context.Context, walletID int, since time.Time) (int, error)
+	CountClientReferencesSince(ctx context.Context, clientID string, since time.Time) (int, error)
+	GetDebitStats(ctx context.Context, walletID int, since time.Time) (models.RiskDebitStats, error)
+
+	RecordRiskDecision(ctx context.Context, decision *models.RiskDecision, review *models.RiskReview) error
+	GetRiskReviews(ctx context.Context, param models.RiskReviewParam, offset int, limit int) ([]models.RiskReview, error)
+	GetRiskReviewByID(ctx context.Context, reviewID int) (models.RiskReview, error)
+	DecideRiskReview(ctx context.Context, reviewID int, status string, decidedBy string, note string, now time.Time) error
+	UpdateRiskReviewStatus(ctx context.Context, reviewID int, fromStatus string, toStatus string, note string) error
+}
@@ -0,0 +1,21 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=i_scheduled_payment_repository.go -destination=../../services/scheduled_payment_repository_mock_test.go -package=services
+type IScheduledPaymentRepo interface {
+	CreateScheduledPayment(ctx context.Context, payment *models.ScheduledPayment) error
+	GetScheduledPaymentByID(ctx context.Context, paymentID int) (models.ScheduledPayment, error)
+	GetScheduledPayments(ctx context.Context, walletID int, clientID string) ([]models.ScheduledPayment, error)
+	UpdateScheduledPaymentStatus(ctx context.Context, paymentID int, fromStatuses []string, toStatus string) error
+	ResumeScheduledPayment(ctx context.Context, payment *models.ScheduledPayment) error
+
+	GetDueScheduledPayments(ctx context.Context, now time.Time, limit int) ([]models.ScheduledPayment, error)
+	ClaimScheduledPayment(ctx context.Context, paymentID int, now time.Time, until time.Time) (bool, error)
+	UpdateScheduledPaymentRun(ctx context.Context, payment *models.ScheduledPayment) error
+	SettleScheduledPaymentReview(ctx context.Context, payment *models.ScheduledPayment, occurrence int) error
+}
@@ -0,0 +1,13 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=i_settlement_repository.go -destination=../../services/settlement_repository_mock_test.go -package=services
+type ISettlementRepo interface {
+	GetSettlementTotals(ctx context.Context, clientID string, from time.Time, to time.Time) (models.SettlementTotals, error)
+	GetSettlementClientIDs(ctx context.Context, from time.Time, to time.Time) ([]string, error)
+}
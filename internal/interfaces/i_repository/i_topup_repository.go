@@ -0,0 +1,18 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=i_topup_repository.go -destination=../../services/topup_repository_mock_test.go -package=services
+type ITopUpRepo interface {
+	CreateTopUpIntent(ctx context.Context, intent *models.TopUpIntent) error
+	GetTopUpIntentByID(ctx context.Context, intentID int) (models.TopUpIntent, error)
+	GetTopUpIntentByReference(ctx context.Context, reference string) (models.TopUpIntent, error)
+	GetTopUpIntents(ctx context.Context, userID uint64, status string, offset int, limit int) ([]models.TopUpIntent, error)
+	UpdateTopUpIntentStatus(ctx context.Context, intentID int, toStatus string, reason string, now time.Time) error
+	CompleteTopUpIntent(ctx context.Context, intentID int, walletTrx *models.WalletTransaction, now time.Time) (models.Wallet, error)
+	GetExpiredTopUpIntents(ctx context.Context, now time.Time, limit int) ([]models.TopUpIntent, error)
+}
@@ -3,12 +3,13 @@ package i_repository
 import (
 	"context"
 	"ewallet-wallet/internal/models"
+	"time"
 )
 
 //go:generate mockgen -source=i_wallet_repository.go -destination=../../services/service_mock_test.go -package=services
 type IWalletRepo interface {
 	CreateWallet(ctx context.Context, wallet *models.Wallet) error
-	UpdateBalance(ctx context.Context, userID uint64, amount float64) (models.Wallet, error)
+	UpdateBalance(ctx context.Context, userID uint64, amount float64, walletTrx *models.WalletTransaction) (models.Wallet, error)
 	CreateWalletTrx(ctx context.Context, walletHistory *models.WalletTransaction) error
 	GetWalletTransactionByReference(ctx context.Context, reference string) (models.WalletTransaction, error)
 	GetWalletByUserID(ctx context.Context, userID uint64) (models.Wallet, error)
@@ -18,5 +19,15 @@ type IWalletRepo interface {
 	InsertWalletLink(ctx context.Context, req *models.WalletLink) error
 	GetWalletLink(ctx context.Context, walletID int, clientSource string) (models.WalletLink, error)
 	UpdateStatusWalletLink(ctx context.Context, walletID int, clientSource string, status string) error
-	UpdateBalanceByID(ctx context.Context, walletID int, amount float64) (models.Wallet, error)
+	UpdateBalanceByID(ctx context.Context, walletID int, amount float64, walletTrx *models.WalletTransaction) (models.Wallet, error)
+	DebitWithFee(ctx context.Context, debit *models.WalletTransaction, fee *models.FeePosting) (models.Wallet, float64, error)
+	UpdateSpendOrder(ctx context.Context, userID uint64, spendOrder string) error
+
+	UpdateWalletStatus(ctx context.Context, walletID int, fromStatuses []string, history *models.WalletStatusHistory) (models.Wallet, error)
+	GetWalletStatusHistory(ctx context.Context, walletID int) ([]models.WalletStatusHistory, error)
+
+	SearchWallets(ctx context.Context, userID uint64, email string, offset int, limit int) ([]models.Wallet, error)
+	UpdateWalletEmail(ctx context.Context, userID uint64, email string) error
+	UpdateWalletLinkOTP(ctx context.Context, walletID int, clientSource string, otp string) error
+	GetStatementSnapshot(ctx context.Context, walletID int, from time.Time, to time.Time) (models.StatementSnapshot, error)
 }
@@ -0,0 +1,25 @@
+package i_repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+)
+
+//go:generate mockgen -source=i_withdrawal_repository.go -destination=../../services/withdrawal_repository_mock_test.go -package=services
+type IWithdrawalRepo interface {
+	CreateBankAccount(ctx context.Context, account *models.BankAccount) error
+	GetBankAccounts(ctx context.Context, userID uint64) ([]models.BankAccount, error)
+	GetBankAccountByID(ctx context.Context, accountID int) (models.BankAccount, error)
+	DeleteBankAccount(ctx context.Context, userID uint64, accountID int) error
+
+	CreateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error
+	GetWithdrawalByID(ctx context.Context, withdrawalID int) (models.Withdrawal, error)
+	GetWithdrawalByReference(ctx context.Context, userID uint64, reference string) (models.Withdrawal, error)
+	GetWithdrawalByPayoutReference(ctx context.Context, payoutReference string) (models.Withdrawal, error)
+	GetWithdrawals(ctx context.Context, userID uint64, status string, offset int, limit int) ([]models.Withdrawal, error)
+	MarkWithdrawalProcessing(ctx context.Context, withdrawalID int, providerReference string, now time.Time) error
+	CompleteWithdrawal(ctx context.Context, withdrawal models.Withdrawal, debit *models.WalletTransaction, fee *models.FeePosting, now time.Time) (models.Wallet, error)
+	FailWithdrawal(ctx context.Context, withdrawal models.Withdrawal, reason string, now time.Time) error
+	GetUnsubmittedWithdrawals(ctx context.Context, before time.Time, limit int) ([]models.Withdrawal, error)
+}
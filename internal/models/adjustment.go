@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+type BalanceAdjustment struct {
+	ID              int        `json:"id"`
+	WalletID        int        `json:"wallet_id" gorm:"column:wallet_id;index"`
+	Amount          float64    `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
+	Direction       string     `json:"direction" gorm:"column:direction;type:varchar(10)"`
+	ReasonCode      string     `json:"reason_code" gorm:"column:reason_code;type:varchar(50)"`
+	Note            string     `json:"note" gorm:"column:note;type:varchar(255)"`
+	Status          string     `json:"status" gorm:"column:status;type:varchar(20);index"`
+	Reference       string     `json:"reference" gorm:"column:reference;type:varchar(100);unique"`
+	MakerID         int        `json:"maker_id" gorm:"column:maker_id"`
+	MakerUsername   string     `json:"maker_username" gorm:"column:maker_username;type:varchar(100)"`
+	CheckerID       int        `json:"checker_id,omitempty" gorm:"column:checker_id"`
+	CheckerUsername string     `json:"checker_username,omitempty" gorm:"column:checker_username;type:varchar(100)"`
+	DecisionNote    string     `json:"decision_note,omitempty" gorm:"column:decision_note;type:varchar(255)"`
+	FailureReason   string     `json:"failure_reason,omitempty" gorm:"column:failure_reason;type:varchar(255)"`
+	ExpiresAt       time.Time  `json:"expires_at" gorm:"column:expires_at"`
+	DecidedAt       *time.Time `json:"decided_at,omitempty" gorm:"column:decided_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (*BalanceAdjustment) TableName() string {
+	return "balance_adjustments"
+}
+
+// SignedAmount is the balance movement the adjustment posts once approved.
+func (a BalanceAdjustment) SignedAmount() float64 {
+	if a.Direction == "DEBIT" {
+		return -a.Amount
+	}
+	return a.Amount
+}
+
+type CreateAdjustmentRequest struct {
+	WalletID       int     `json:"wallet_id" validate:"required"`
+	Amount         float64 `json:"amount" validate:"required,gt=0"`
+	Direction      string  `json:"direction" validate:"required,oneof=CREDIT DEBIT"`
+	ReasonCode     string  `json:"reason_code" validate:"required"`
+	Note           string  `json:"note" validate:"required,max=255"`
+	ExpiresInHours int     `json:"expires_in_hours" validate:"omitempty,min=1,max=72"`
+}
+
+func (l CreateAdjustmentRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type AdjustmentDecisionRequest struct {
+	Note string `json:"note" validate:"max=255"`
+}
+
+func (l AdjustmentDecisionRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type AdjustmentParam struct {
+	Status   string `form:"status"`
+	WalletID int    `form:"wallet_id"`
+	Page     int    `form:"page"`
+	Limit    int    `form:"limit"`
+}
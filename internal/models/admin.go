@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+type Operator struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username" gorm:"column:username;type:varchar(100);unique"`
+	PasswordHash string    `json:"-" gorm:"column:password_hash;type:varchar(255)"`
+	Role         string    `json:"role" gorm:"column:role;type:varchar(20)"`
+	Status       string    `json:"status" gorm:"column:status;type:varchar(20);default:active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (*Operator) TableName() string {
+	return "operators"
+}
+
+type OperatorTokenData struct {
+	OperatorID int
+	Username   string
+	Role       string
+}
+
+type OperatorLoginRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (l OperatorLoginRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type OperatorLoginResponse struct {
+	Token     string    `json:"token"`
+	Role      string    `json:"role"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type CreateOperatorRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=100"`
+	Password string `json:"password" validate:"required,min=12"`
+	Role     string `json:"role" validate:"required,oneof=viewer support finance admin"`
+}
+
+func (l CreateOperatorRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type WalletSearchParam struct {
+	UserID uint64 `form:"user_id"`
+	Email  string `form:"email"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
@@ -13,14 +13,20 @@ func (l TransactionRequest) Validate() error {
 }
 
 type BalanceResponse struct {
-	Balance float64 `json:"balance"`
+	Balance      float64 `json:"balance"`
+	HeldBalance  float64 `json:"held_balance,omitempty"`
+	PromoBalance float64 `json:"promo_balance,omitempty"`
+	Fee          float64 `json:"fee,omitempty"`
+	PromoSpent   float64 `json:"promo_spent,omitempty"`
+	Cashback     float64 `json:"cashback,omitempty"`
 }
 
 type ExternalTransactionRequest struct {
-	Amount          float64 `json:"amount" valid:"required"`
-	Reference       string  `json:"reference" valid:"required"`
-	TransactionType string  `json:"transaction_type" valid:"required"`
-	WalletID        int     `json:"wallet_id" valid:"required"`
+	Amount          float64 `json:"amount" validate:"required,gt=0"`
+	Reference       string  `json:"reference" validate:"required,max=100"`
+	TransactionType string  `json:"transaction_type" validate:"required,oneof=CREDIT DEBIT REFUND"`
+	WalletID        int     `json:"wallet_id" validate:"required"`
+	ClientID        string  `json:"-"`
 }
 
 func (l ExternalTransactionRequest) Validate() error {
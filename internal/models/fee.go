@@ -0,0 +1,168 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// FeeRule prices one operation. An empty ClientSource or WalletTier matches
+// every client or tier, the most specific active rule wins.
+type FeeRule struct {
+	ID           int      `json:"id"`
+	Operation    string   `json:"operation" gorm:"column:operation;type:varchar(30);index"`
+	ClientSource string   `json:"client_source,omitempty" gorm:"column:client_source;type:varchar(100)"`
+	WalletTier   string   `json:"wallet_tier,omitempty" gorm:"column:wallet_tier;type:varchar(20)"`
+	FeeType      string   `json:"fee_type" gorm:"column:fee_type;type:varchar(20)"`
+	FlatAmount   float64  `json:"flat_amount,omitempty" gorm:"column:flat_amount;type:decimal(15,2)"`
+	Percentage   float64  `json:"percentage,omitempty" gorm:"column:percentage;type:decimal(7,4)"`
+	Tiers        FeeTiers `json:"tiers,omitempty" gorm:"column:tiers;type:text"`
+	MinFee       float64  `json:"min_fee,omitempty" gorm:"column:min_fee;type:decimal(15,2)"`
+	MaxFee       float64  `json:"max_fee,omitempty" gorm:"column:max_fee;type:decimal(15,2)"`
+	// FreeQuota is the number of operations per calendar month charged
+	// nothing before the rule applies, only P2P transfers have one.
+	FreeQuota int       `json:"free_quota,omitempty" gorm:"column:free_quota"`
+	Active    bool      `json:"active" gorm:"column:active;default:true"`
+	CreatedBy string    `json:"created_by" gorm:"column:created_by;type:varchar(100)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (*FeeRule) TableName() string {
+	return "fee_rules"
+}
+
+// FeeTier prices the amounts up to UpTo, the last tier has no UpTo and covers
+// everything above the previous one.
+type FeeTier struct {
+	UpTo       float64 `json:"up_to,omitempty" validate:"gte=0"`
+	FlatAmount float64 `json:"flat_amount,omitempty" validate:"gte=0"`
+	Percentage float64 `json:"percentage,omitempty" validate:"gte=0,lte=100"`
+}
+
+type FeeTiers []FeeTier
+
+func (t FeeTiers) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return "", nil
+	}
+
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (t *FeeTiers) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("can not scan %T into fee tiers", value)
+	}
+
+	if len(b) == 0 {
+		*t = nil
+		return nil
+	}
+
+	return json.Unmarshal(b, t)
+}
+
+type FeeRuleRequest struct {
+	Operation    string    `json:"operation" validate:"required,oneof=external_debit withdrawal p2p_transfer"`
+	ClientSource string    `json:"client_source" validate:"max=100"`
+	WalletTier   string    `json:"wallet_tier" validate:"omitempty,oneof=basic premium"`
+	FeeType      string    `json:"fee_type" validate:"required,oneof=flat percentage tiered"`
+	FlatAmount   float64   `json:"flat_amount" validate:"gte=0"`
+	Percentage   float64   `json:"percentage" validate:"gte=0,lte=100"`
+	Tiers        []FeeTier `json:"tiers" validate:"dive"`
+	MinFee       float64   `json:"min_fee" validate:"gte=0"`
+	MaxFee       float64   `json:"max_fee" validate:"omitempty,gtefield=MinFee"`
+	FreeQuota    int       `json:"free_quota" validate:"gte=0"`
+}
+
+func (l FeeRuleRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type FeeRuleParam struct {
+	Page      int    `form:"page"`
+	Limit     int    `form:"limit"`
+	Operation string `form:"operation" validate:"omitempty,oneof=external_debit withdrawal p2p_transfer"`
+}
+
+func (l FeeRuleParam) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+// FeeQuote is what an operation would cost right now, RuleID is empty when
+// no rule applies.
+type FeeQuote struct {
+	Operation string  `json:"operation"`
+	Amount    float64 `json:"amount"`
+	Fee       float64 `json:"fee"`
+	Total     float64 `json:"total"`
+	RuleID    int     `json:"rule_id,omitempty"`
+	// FreeQuotaLeft counts the free operations left this month, including
+	// the quoted one.
+	FreeQuotaLeft int `json:"free_quota_left,omitempty"`
+	// FreeQuota and QuotaFee are what a free quoted operation is booked
+	// with, the fee is charged if the quota is used up by then.
+	FreeQuota int     `json:"-"`
+	QuotaFee  float64 `json:"-"`
+}
+
+type FeeQuoteParam struct {
+	Operation string  `form:"operation" validate:"required,oneof=withdrawal p2p_transfer"`
+	Amount    float64 `form:"amount" validate:"required,gt=0"`
+}
+
+func (l FeeQuoteParam) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type ExFeeQuoteParam struct {
+	Amount float64 `form:"amount" validate:"required,gt=0"`
+}
+
+func (l ExFeeQuoteParam) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+// FeePosting is a fee about to be booked, a FEE row on the paying wallet and
+// a CREDIT row on the revenue wallet.
+type FeePosting struct {
+	Fee     *WalletTransaction
+	Revenue *WalletTransaction
+	// FreeQuota waives the fee when the paying wallet paid fewer transfers
+	// since QuotaSince, counted by the booking transaction which sets Waived.
+	FreeQuota  int
+	QuotaSince time.Time
+	Waived     bool
+}
+
+// Amount is zero for a nil or waived posting, so callers do not check for
+// one.
+func (f *FeePosting) Amount() float64 {
+	if f == nil || f.Waived {
+		return 0
+	}
+
+	return f.Fee.Amount
+}
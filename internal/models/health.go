@@ -0,0 +1,27 @@
+package models
+
+// ComponentHealth is the result of checking one dependency, LatencyMS is how
+// long the check took.
+type ComponentHealth struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Readiness is up only when every component is up. Started stays false until
+// the first time it was up, the service takes no traffic before that.
+type Readiness struct {
+	Status     string            `json:"status"`
+	Started    bool              `json:"started"`
+	Components []ComponentHealth `json:"components,omitempty"`
+}
+
+// MigrationHealth counts the migrations of the binary not applied to the
+// database and the applied ones whose file changed since.
+type MigrationHealth struct {
+	Latest   int `json:"latest"`
+	Pending  int `json:"pending"`
+	Modified int `json:"modified"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// PaymentRequest asks another user to pay the requester. Nothing moves until
+// the payer accepts, which debits the payer and credits the requester in one
+// transaction.
+type PaymentRequest struct {
+	ID                int        `json:"id"`
+	RequesterUserID   uint64     `json:"requester_user_id" gorm:"column:requester_user_id;index"`
+	RequesterWalletID int        `json:"requester_wallet_id" gorm:"column:requester_wallet_id"`
+	PayerUserID       uint64     `json:"payer_user_id" gorm:"column:payer_user_id;index"`
+	PayerWalletID     int        `json:"payer_wallet_id" gorm:"column:payer_wallet_id"`
+	Amount            float64    `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
+	Fee               float64    `json:"fee,omitempty" gorm:"column:fee;type:decimal(15,2);default:0"`
+	Note              string     `json:"note" gorm:"column:note;type:varchar(255)"`
+	Status            string     `json:"status" gorm:"column:status;type:varchar(20);index"`
+	Reference         string     `json:"reference" gorm:"column:reference;type:varchar(100);unique"`
+	ExpiresAt         time.Time  `json:"expires_at" gorm:"column:expires_at;index"`
+	DecidedAt         *time.Time `json:"decided_at,omitempty" gorm:"column:decided_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+func (*PaymentRequest) TableName() string {
+	return "payment_requests"
+}
+
+type CreatePaymentRequest struct {
+	PayerUserID    uint64  `json:"payer_user_id" validate:"required"`
+	Amount         float64 `json:"amount" validate:"required,gt=0"`
+	Note           string  `json:"note" validate:"max=255"`
+	ExpiresInHours int     `json:"expires_in_hours" validate:"omitempty,min=1,max=720"`
+}
+
+func (l CreatePaymentRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type PaymentRequestParam struct {
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+	Status string `form:"status" validate:"omitempty,oneof=pending paid declined expired cancelled"`
+}
+
+func (l PaymentRequestParam) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
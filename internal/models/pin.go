@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// WalletPIN authorizes the debits above the PIN threshold. Only bcrypt hashes
+// of the PIN and of the reset OTP are stored.
+type WalletPIN struct {
+	ID             int        `json:"id"`
+	WalletID       int        `json:"wallet_id" gorm:"column:wallet_id;uniqueIndex"`
+	PINHash        string     `json:"-" gorm:"column:pin_hash;type:varchar(100)"`
+	FailedAttempts int        `json:"failed_attempts" gorm:"column:failed_attempts;default:0"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty" gorm:"column:locked_until"`
+	ResetOTPHash   string     `json:"-" gorm:"column:reset_otp_hash;type:varchar(100)"`
+	ResetOTPSentAt *time.Time `json:"-" gorm:"column:reset_otp_sent_at"`
+	ResetAttempts  int        `json:"-" gorm:"column:reset_attempts;default:0"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (*WalletPIN) TableName() string {
+	return "wallet_pins"
+}
+
+type SetPINRequest struct {
+	PIN string `json:"pin" validate:"required,len=6,numeric"`
+}
+
+func (l SetPINRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type ChangePINRequest struct {
+	OldPIN string `json:"old_pin" validate:"required,len=6,numeric"`
+	NewPIN string `json:"new_pin" validate:"required,len=6,numeric,nefield=OldPIN"`
+}
+
+func (l ChangePINRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type ResetPINRequest struct {
+	OTP    string `json:"otp" validate:"required,len=6,numeric"`
+	NewPIN string `json:"new_pin" validate:"required,len=6,numeric"`
+}
+
+func (l ResetPINRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type VerifyPINRequest struct {
+	PIN string `json:"pin" validate:"required,len=6,numeric"`
+}
+
+func (l VerifyPINRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type StepUpToken struct {
+	Token     string    `json:"token"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+// OTPMessage is handed to the OTP sender, the OTP never leaves the service
+// in a response.
+type OTPMessage struct {
+	UserID    uint64
+	Email     string
+	Purpose   string
+	OTP       string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// Campaign gives cashback as promo credit on partner debits from one client
+// source, Percentage of the debit up to MaxCashback per debit.
+type Campaign struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name" gorm:"column:name;type:varchar(100)"`
+	ClientSource string    `json:"client_source" gorm:"column:client_source;type:varchar(100);index"`
+	Percentage   float64   `json:"percentage" gorm:"column:percentage;type:decimal(7,4)"`
+	MaxCashback  float64   `json:"max_cashback" gorm:"column:max_cashback;type:decimal(15,2)"`
+	ValidityDays int       `json:"validity_days" gorm:"column:validity_days"`
+	StartsAt     time.Time `json:"starts_at" gorm:"column:starts_at"`
+	EndsAt       time.Time `json:"ends_at" gorm:"column:ends_at"`
+	Active       bool      `json:"active" gorm:"column:active;default:true"`
+	CreatedBy    string    `json:"created_by" gorm:"column:created_by;type:varchar(100)"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (*Campaign) TableName() string {
+	return "campaigns"
+}
+
+type CampaignRequest struct {
+	Name         string    `json:"name" validate:"required,max=100"`
+	ClientSource string    `json:"client_source" validate:"required,max=100"`
+	Percentage   float64   `json:"percentage" validate:"gt=0,lte=100"`
+	MaxCashback  float64   `json:"max_cashback" validate:"gt=0"`
+	ValidityDays int       `json:"validity_days" validate:"gte=0"`
+	StartsAt     time.Time `json:"starts_at" validate:"required"`
+	EndsAt       time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+}
+
+func (l CampaignRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type CampaignParam struct {
+	Page         int    `form:"page"`
+	Limit        int    `form:"limit"`
+	ClientSource string `form:"client_source"`
+}
+
+// PromoGrant is one promo credit given to a wallet. Partner debits spend the
+// grants expiring first, so when a grant expires the part of PromoBalance
+// not covered by later grants is what is left of it.
+type PromoGrant struct {
+	ID         int     `json:"id"`
+	WalletID   int     `json:"wallet_id" gorm:"column:wallet_id;index"`
+	CampaignID int     `json:"campaign_id,omitempty" gorm:"column:campaign_id;index"`
+	Amount     float64 `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
+	// TransactionID is the promo CREDIT row of the grant.
+	TransactionID int       `json:"transaction_id" gorm:"column:transaction_id"`
+	ClawedBack    float64   `json:"clawed_back,omitempty" gorm:"column:clawed_back;type:decimal(15,2);default:0"`
+	Status        string    `json:"status" gorm:"column:status;type:varchar(20);index:idx_promo_grants_status_expires"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"column:expires_at;index:idx_promo_grants_status_expires"`
+	GrantedBy     string    `json:"granted_by" gorm:"column:granted_by;type:varchar(100)"`
+	Note          string    `json:"note,omitempty" gorm:"column:note;type:varchar(255)"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (*PromoGrant) TableName() string {
+	return "promo_grants"
+}
+
+type PromoCreditRequest struct {
+	Amount       float64 `json:"amount" validate:"required,gt=0"`
+	Reference    string  `json:"reference" validate:"required,max=80"`
+	ValidityDays int     `json:"validity_days" validate:"gte=0"`
+	Note         string  `json:"note" validate:"max=255"`
+}
+
+func (l PromoCreditRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type SpendOrderRequest struct {
+	SpendOrder string `json:"spend_order" validate:"required,oneof=promo_first cash_first"`
+}
+
+func (l SpendOrderRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+)
+
+type ReconciliationRun struct {
+	ID                int        `json:"id"`
+	Status            string     `json:"status" gorm:"column:status;type:varchar(20)"`
+	WalletCount       int64      `json:"wallet_count" gorm:"column:wallet_count"`
+	MismatchCount     int        `json:"mismatch_count" gorm:"column:mismatch_count"`
+	LastTransactionID int        `json:"last_transaction_id" gorm:"column:last_transaction_id"`
+	StartedAt         time.Time  `json:"started_at" gorm:"column:started_at"`
+	FinishedAt        *time.Time `json:"finished_at" gorm:"column:finished_at"`
+}
+
+func (*ReconciliationRun) TableName() string {
+	return "reconciliation_runs"
+}
+
+// ReconciliationMismatch is a wallet whose stored balance, or promo balance,
+// does not match the sum of its ledger, or of its promo rows. The window
+// covers the transactions booked since the last run in which the wallet was
+// still consistent, the drift happened in there.
+type ReconciliationMismatch struct {
+	ID                     int        `json:"id"`
+	RunID                  int        `json:"run_id" gorm:"column:run_id;index"`
+	WalletID               int        `json:"wallet_id" gorm:"column:wallet_id;index"`
+	Balance                float64    `json:"balance" gorm:"column:balance;type:decimal(15,2)"`
+	LedgerBalance          float64    `json:"ledger_balance" gorm:"column:ledger_balance;type:decimal(15,2)"`
+	Difference             float64    `json:"difference" gorm:"column:difference;type:decimal(15,2)"`
+	PromoBalance           float64    `json:"promo_balance" gorm:"column:promo_balance;type:decimal(15,2)"`
+	PromoLedgerBalance     float64    `json:"promo_ledger_balance" gorm:"column:promo_ledger_balance;type:decimal(15,2)"`
+	PromoDifference        float64    `json:"promo_difference" gorm:"column:promo_difference;type:decimal(15,2)"`
+	WindowFromID           int        `json:"window_from_id" gorm:"column:window_from_id"`
+	WindowToID             int        `json:"window_to_id" gorm:"column:window_to_id"`
+	WindowFromTime         *time.Time `json:"window_from_time" gorm:"column:window_from_time"`
+	WindowToTime           *time.Time `json:"window_to_time" gorm:"column:window_to_time"`
+	WindowTransactionCount int64      `json:"window_transaction_count" gorm:"column:window_transaction_count"`
+	SuspenseEntryID        int        `json:"suspense_entry_id,omitempty" gorm:"column:suspense_entry_id"`
+	CreatedAt              time.Time  `json:"created_at"`
+}
+
+func (*ReconciliationMismatch) TableName() string {
+	return "reconciliation_mismatches"
+}
+
+// SuspenseEntry parks an unexplained difference for finance to investigate.
+// It never touches the wallet balance itself.
+type SuspenseEntry struct {
+	ID         int        `json:"id"`
+	WalletID   int        `json:"wallet_id" gorm:"column:wallet_id;index"`
+	RunID      int        `json:"run_id" gorm:"column:run_id"`
+	Amount     float64    `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
+	Status     string     `json:"status" gorm:"column:status;type:varchar(20);index"`
+	Note       string     `json:"note" gorm:"column:note;type:varchar(255)"`
+	ResolvedAt *time.Time `json:"resolved_at" gorm:"column:resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (*SuspenseEntry) TableName() string {
+	return "suspense_entries"
+}
+
+type WalletLedgerBalance struct {
+	WalletID           int     `gorm:"column:wallet_id"`
+	Balance            float64 `gorm:"column:balance"`
+	LedgerBalance      float64 `gorm:"column:ledger_balance"`
+	PromoBalance       float64 `gorm:"column:promo_balance"`
+	PromoLedgerBalance float64 `gorm:"column:promo_ledger_balance"`
+}
+
+type LedgerSnapshot struct {
+	LastTransactionID int
+	WalletCount       int64
+	Mismatches        []WalletLedgerBalance
+}
+
+type TransactionWindow struct {
+	FromID           int        `gorm:"column:from_id"`
+	ToID             int        `gorm:"column:to_id"`
+	FromTime         *time.Time `gorm:"column:from_time"`
+	ToTime           *time.Time `gorm:"column:to_time"`
+	TransactionCount int64      `gorm:"column:transaction_count"`
+}
+
+type ReconciliationOptions struct {
+	Formats        []string
+	OutputDir      string
+	CreateSuspense bool
+}
+
+type ReconciliationReport struct {
+	Run        ReconciliationRun        `json:"run"`
+	Mismatches []ReconciliationMismatch `json:"mismatches"`
+	Files      []string                 `json:"files,omitempty"`
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// RiskRule is one check of the risk screening, read from the rules file. A
+// rule that fires gives its Outcome, the worst outcome of the fired rules is
+// the decision.
+type RiskRule struct {
+	Name    string `json:"name" validate:"required"`
+	Type    string `json:"type" validate:"required,oneof=debit_velocity new_link_large_debit client_references unusual_amount"`
+	Outcome string `json:"outcome" validate:"required,oneof=review block"`
+	// Operations limits the rule to debit, transfer, external or withdrawal,
+	// all of them when empty.
+	Operations []string `json:"operations" validate:"dive,oneof=debit transfer external withdrawal"`
+	Window     string   `json:"window" validate:"required"`
+	MaxCount   int      `json:"max_count" validate:"min=0"`
+	MinAmount  float64  `json:"min_amount" validate:"min=0"`
+	Multiplier float64  `json:"multiplier" validate:"min=0"`
+	MinHistory int      `json:"min_history" validate:"min=0"`
+	Disabled   bool     `json:"disabled"`
+
+	WindowDuration time.Duration `json:"-"`
+}
+
+func (l RiskRule) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+// AppliesTo tells whether the rule screens the operation.
+func (l RiskRule) AppliesTo(operation string) bool {
+	if len(l.Operations) == 0 {
+		return true
+	}
+
+	for _, op := range l.Operations {
+		if op == operation {
+			return true
+		}
+	}
+
+	return false
+}
+
+type RiskRuleSet struct {
+	Rules []RiskRule `json:"rules"`
+}
+
+// RiskCheck is the money movement being screened.
+type RiskCheck struct {
+	Operation        string
+	TransactionType  string
+	UserID           uint64
+	WalletID         int
+	ClientID         string
+	Amount           float64
+	Reference        string
+	PaymentRequestID int
+	BankAccountID    int
+}
+
+type RiskDebitStats struct {
+	Count   int     `gorm:"column:count"`
+	Average float64 `gorm:"column:average"`
+}
+
+type RiskDecision struct {
+	ID              int       `json:"id"`
+	Operation       string    `json:"operation" gorm:"column:operation;type:varchar(20)"`
+	TransactionType string    `json:"transaction_type" gorm:"column:transaction_type;type:varchar(10)"`
+	WalletID        int       `json:"wallet_id" gorm:"column:wallet_id;index"`
+	UserID          uint64    `json:"user_id,omitempty" gorm:"column:user_id"`
+	ClientID        string    `json:"client_id,omitempty" gorm:"column:client_id;type:varchar(100)"`
+	Amount          float64   `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
+	Reference       string    `json:"reference" gorm:"column:reference;type:varchar(100);index"`
+	Outcome         string    `json:"outcome" gorm:"column:outcome;type:varchar(10);index"`
+	Reasons         string    `json:"reasons,omitempty" gorm:"column:reasons;type:text"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (*RiskDecision) TableName() string {
+	return "risk_decisions"
+}
+
+// RiskReview is a movement held until an operator approves it, the approval
+// replays it.
+type RiskReview struct {
+	ID               int        `json:"id"`
+	DecisionID       int        `json:"decision_id" gorm:"column:decision_id"`
+	Operation        string     `json:"operation" gorm:"column:operation;type:varchar(20)"`
+	TransactionType  string     `json:"transaction_type" gorm:"column:transaction_type;type:varchar(10)"`
+	WalletID         int        `json:"wallet_id" gorm:"column:wallet_id;index"`
+	UserID           uint64     `json:"user_id,omitempty" gorm:"column:user_id"`
+	ClientID         string     `json:"client_id,omitempty" gorm:"column:client_id;type:varchar(100)"`
+	Amount           float64    `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
+	Reference        string     `json:"reference" gorm:"column:reference;type:varchar(100);uniqueIndex"`
+	PaymentRequestID int        `json:"payment_request_id,omitempty" gorm:"column:payment_request_id"`
+	BankAccountID    int        `json:"bank_account_id,omitempty" gorm:"column:bank_account_id"`
+	Reasons          string     `json:"reasons" gorm:"column:reasons;type:text"`
+	Status           string     `json:"status" gorm:"column:status;type:varchar(20);index"`
+	DecidedBy        string     `json:"decided_by,omitempty" gorm:"column:decided_by;type:varchar(100)"`
+	DecisionNote     string     `json:"decision_note,omitempty" gorm:"column:decision_note;type:varchar(255)"`
+	DecidedAt        *time.Time `json:"decided_at,omitempty" gorm:"column:decided_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func (*RiskReview) TableName() string {
+	return "risk_reviews"
+}
+
+type RiskReviewParam struct {
+	Status   string `form:"status"`
+	WalletID int    `form:"wallet_id"`
+	Page     int    `form:"page"`
+	Limit    int    `form:"limit"`
+}
+
+type RiskReviewDecisionRequest struct {
+	Note string `json:"note" validate:"max=255"`
+}
+
+func (l RiskReviewDecisionRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// ScheduledPayment debits a linked wallet on behalf of a client at a fixed
+// frequency. Occurrences are numbered from 1 and each one is posted with its
+// own reference, so retrying an occurrence can never charge it twice.
+type ScheduledPayment struct {
+	ID              int        `json:"id"`
+	WalletID        int        `json:"wallet_id" gorm:"column:wallet_id;index"`
+	ClientID        string     `json:"client_id" gorm:"column:client_id;type:varchar(100);index"`
+	Amount          float64    `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
+	Description     string     `json:"description" gorm:"column:description;type:varchar(255)"`
+	Frequency       string     `json:"frequency" gorm:"column:frequency;type:varchar(10)"`
+	StartAt         time.Time  `json:"start_at" gorm:"column:start_at"`
+	EndAt           *time.Time `json:"end_at,omitempty" gorm:"column:end_at"`
+	MaxOccurrences  int        `json:"max_occurrences,omitempty" gorm:"column:max_occurrences"`
+	OccurrenceCount int        `json:"occurrence_count" gorm:"column:occurrence_count"`
+	PaidCount       int        `json:"paid_count" gorm:"column:paid_count"`
+	RetryCount      int        `json:"retry_count" gorm:"column:retry_count"`
+	NextRunAt       time.Time  `json:"next_run_at" gorm:"column:next_run_at;index"`
+	LastRunAt       *time.Time `json:"last_run_at,omitempty" gorm:"column:last_run_at"`
+	LastError       string     `json:"last_error,omitempty" gorm:"column:last_error;type:varchar(255)"`
+	Status          string     `json:"status" gorm:"column:status;type:varchar(20);index"`
+	LockedUntil     *time.Time `json:"-" gorm:"column:locked_until"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (*ScheduledPayment) TableName() string {
+	return "scheduled_payments"
+}
+
+type ScheduledPaymentRequest struct {
+	WalletID       int        `json:"wallet_id" validate:"required"`
+	Amount         float64    `json:"amount" validate:"required,gt=0"`
+	Description    string     `json:"description" validate:"max=255"`
+	Frequency      string     `json:"frequency" validate:"required,oneof=daily weekly monthly"`
+	StartAt        *time.Time `json:"start_at"`
+	EndAt          *time.Time `json:"end_at"`
+	MaxOccurrences int        `json:"max_occurrences" validate:"min=0"`
+}
+
+func (l ScheduledPaymentRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type ScheduledPaymentParam struct {
+	WalletID int `form:"wallet_id"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"github.com/go-playground/validator"
+)
+
+type SettlementTotals struct {
+	GrossCredit      float64 `gorm:"column:gross_credit"`
+	Debit            float64 `gorm:"column:debit"`
+	Refund           float64 `gorm:"column:refund"`
+	Fee              float64 `gorm:"column:fee"`
+	TransactionCount int64   `gorm:"column:transaction_count"`
+}
+
+// SettlementDay sums one client's transactions over one calendar day. Net is
+// the combined effect on the wallets, credits and refunds minus debits and
+// fees, so a negative net means the client collected money from wallets.
+type SettlementDay struct {
+	ClientID         string  `json:"client_id"`
+	Date             string  `json:"date"`
+	GrossCredit      float64 `json:"gross_credit"`
+	Debit            float64 `json:"debit"`
+	Refund           float64 `json:"refund"`
+	Fee              float64 `json:"fee"`
+	Net              float64 `json:"net"`
+	TransactionCount int64   `json:"transaction_count"`
+}
+
+type SettlementParam struct {
+	DateFrom string `form:"date_from" validate:"required"`
+	DateTo   string `form:"date_to" validate:"required"`
+	Format   string `form:"format" validate:"omitempty,oneof=json csv"`
+}
+
+func (l SettlementParam) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+type StatementParam struct {
+	DateFrom string `form:"date_from" validate:"required"`
+	DateTo   string `form:"date_to" validate:"required"`
+	Format   string `form:"format" validate:"omitempty,oneof=json csv pdf"`
+}
+
+func (l StatementParam) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+// StatementSnapshot is read in a single transaction, the opening balance is
+// the ledger up to the start of the range and the transactions are every row
+// booked inside it.
+type StatementSnapshot struct {
+	OpeningBalance float64
+	Transactions   []WalletTransaction
+}
+
+type StatementEntry struct {
+	ID                    int       `json:"id"`
+	Date                  time.Time `json:"date"`
+	WalletTransactionType string    `json:"wallet_transaction_type"`
+	Reference             string    `json:"reference"`
+	Amount                float64   `json:"amount"`
+	Balance               float64   `json:"balance"`
+}
+
+// Statement lists the movements of a wallet between two dates. Entry amounts
+// are signed and Balance is the running balance after each entry, so the
+// opening balance plus every amount is always the closing balance.
+type Statement struct {
+	WalletID       int              `json:"wallet_id"`
+	DateFrom       string           `json:"date_from"`
+	DateTo         string           `json:"date_to"`
+	OpeningBalance float64          `json:"opening_balance"`
+	TotalCredit    float64          `json:"total_credit"`
+	TotalDebit     float64          `json:"total_debit"`
+	ClosingBalance float64          `json:"closing_balance"`
+	Entries        []StatementEntry `json:"entries"`
+}
@@ -1,8 +1,32 @@
 package models
 
+import "github.com/go-playground/validator"
+
 type TokenData struct {
 	UserID   uint64
 	Username string
 	Fullname string
 	Email    string
+	Scopes   []string
+}
+
+type TokenCacheStats struct {
+	Entries      int    `json:"entries"`
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negative_hits"`
+	Misses       uint64 `json:"misses"`
+	Evictions    uint64 `json:"evictions"`
+	Revocations  uint64 `json:"revocations"`
+}
+
+// RevokeTokenRequest drops a single token or every token of a user from the
+// token cache.
+type RevokeTokenRequest struct {
+	Token  string `json:"token" validate:"required_without=UserID"`
+	UserID uint64 `json:"user_id" validate:"required_without=Token"`
+}
+
+func (l RevokeTokenRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
 }
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// TopUpIntent tracks a top-up from the moment the user asks for payment
+// instructions until the gateway reports the payment. The wallet is credited
+// once, when the intent turns paid.
+type TopUpIntent struct {
+	ID               int        `json:"id"`
+	UserID           uint64     `json:"user_id" gorm:"column:user_id;index"`
+	WalletID         int        `json:"wallet_id" gorm:"column:wallet_id"`
+	Amount           float64    `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
+	Status           string     `json:"status" gorm:"column:status;type:varchar(20);index"`
+	Reference        string     `json:"reference" gorm:"column:reference;type:varchar(100);unique"`
+	Gateway          string     `json:"gateway" gorm:"column:gateway;type:varchar(50)"`
+	GatewayReference string     `json:"gateway_reference" gorm:"column:gateway_reference;type:varchar(100)"`
+	PaymentCode      string     `json:"payment_code" gorm:"column:payment_code;type:varchar(100)"`
+	PaymentURL       string     `json:"payment_url" gorm:"column:payment_url;type:varchar(255)"`
+	FailureReason    string     `json:"failure_reason,omitempty" gorm:"column:failure_reason;type:varchar(255)"`
+	ExpiresAt        time.Time  `json:"expires_at" gorm:"column:expires_at;index"`
+	PaidAt           *time.Time `json:"paid_at,omitempty" gorm:"column:paid_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func (*TopUpIntent) TableName() string {
+	return "topup_intents"
+}
+
+type TopUpRequest struct {
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+}
+
+func (l TopUpRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type TopUpParam struct {
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+	Status string `form:"status" validate:"omitempty,oneof=pending paid failed expired"`
+}
+
+func (l TopUpParam) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type GatewayPaymentRequest struct {
+	Reference string
+	WalletID  int
+	Amount    float64
+	ExpiresAt time.Time
+}
+
+// GatewayPayment holds the instructions the user follows to pay.
+type GatewayPayment struct {
+	GatewayReference string
+	PaymentCode      string
+	PaymentURL       string
+}
+
+// GatewayCallback is a verified payment notification, Status is either
+// paid or failed.
+type GatewayCallback struct {
+	Reference        string  `json:"reference"`
+	GatewayReference string  `json:"gateway_reference"`
+	Status           string  `json:"status"`
+	Amount           float64 `json:"amount"`
+	FailureReason    string  `json:"failure_reason"`
+}
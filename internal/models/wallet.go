@@ -7,9 +7,24 @@ import (
 )
 
 type Wallet struct {
-	ID        int     `json:"id"`
-	UserID    uint64  `json:"user_id" gorm:"column:user_id;unique"`
-	Balance   float64 `json:"balance" gorm:"column:balance;type:decimal(15,2)"`
+	ID      int     `json:"id"`
+	UserID  uint64  `json:"user_id" gorm:"column:user_id;unique"`
+	Email   string  `json:"email,omitempty" gorm:"column:email;type:varchar(100);index"`
+	Balance float64 `json:"balance" gorm:"column:balance;type:decimal(15,2)"`
+	// HeldBalance is the part of Balance reserved by in flight withdrawals,
+	// it can not be spent until the withdrawal succeeds or fails.
+	HeldBalance float64 `json:"held_balance,omitempty" gorm:"column:held_balance;type:decimal(15,2);default:0"`
+	Status      string  `json:"status,omitempty" gorm:"column:status;type:varchar(20);default:active"`
+	// Tier picks the fee rules that apply to the wallet.
+	Tier string `json:"tier,omitempty" gorm:"column:tier;type:varchar(20);default:basic"`
+	// PromoBalance is the part of Balance granted as promotional credit, it
+	// can pay partners but can not be withdrawn or transferred.
+	PromoBalance float64 `json:"promo_balance,omitempty" gorm:"column:promo_balance;type:decimal(15,2);default:0"`
+	// SpendOrder picks the sub-balance a partner debit takes from first.
+	SpendOrder string `json:"spend_order,omitempty" gorm:"column:spend_order;type:varchar(20);default:promo_first"`
+	// Type tells user wallets from system ones, such as the fee revenue
+	// wallet, that only the service itself moves.
+	Type      string `json:"type,omitempty" gorm:"column:type;type:varchar(20);default:user"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -19,13 +34,19 @@ func (*Wallet) TableName() string {
 }
 
 type WalletTransaction struct {
-	ID                    int       `json:"id"`
-	WalletID              int       `json:"wallet_id" gorm:"column:wallet_id"`
-	Amount                float64   `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
-	WalletTransactionType string    `json:"wallet_transaction_type" gorm:"column:wallet_transaction_type;type:enum('CREDIT', 'DEBIT')"`
-	Reference             string    `json:"reference" gorm:"column:reference;type:varchar(100);unique"`
-	CreatedAt             time.Time `json:"created_at"`
-	UpdatedAt             time.Time `json:"updated_at"`
+	ID                    int     `json:"id" gorm:"index:idx_wallet_transactions_wallet_id_id,priority:2"`
+	WalletID              int     `json:"wallet_id" gorm:"column:wallet_id;index:idx_wallet_transactions_wallet_id_id,priority:1"`
+	Amount                float64 `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
+	WalletTransactionType string  `json:"wallet_transaction_type" gorm:"column:wallet_transaction_type;type:enum('CREDIT', 'DEBIT', 'ADJUSTMENT', 'REFUND', 'FEE')"`
+	Reference             string  `json:"reference" gorm:"column:reference;type:varchar(100);unique"`
+	ClientID              string  `json:"client_id,omitempty" gorm:"column:client_id;type:varchar(100);index"`
+	// ParentID links a fee row, and its revenue row, to the transaction that
+	// was charged.
+	ParentID int `json:"parent_id,omitempty" gorm:"column:parent_id;index"`
+	// BalanceType is the sub-balance the row moves, cash or promo.
+	BalanceType string    `json:"balance_type,omitempty" gorm:"column:balance_type;type:varchar(10);default:cash"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 func (*WalletTransaction) TableName() string {
@@ -40,8 +61,8 @@ type WalletHistoryParam struct {
 
 type WalletLink struct {
 	ID           int    `json:"id"`
-	WalletID     int    `json:"wallet_id" gorm:"column:wallet_id" validate:"required"`
-	ClientSource string `json:"client_source" gorm:"column:client_source;type:varchar(100)"`
+	WalletID     int    `json:"wallet_id" gorm:"column:wallet_id;index:idx_wallet_links_wallet_id_client_source,priority:1" validate:"required"`
+	ClientSource string `json:"client_source" gorm:"column:client_source;type:varchar(100);index:idx_wallet_links_wallet_id_client_source,priority:2"`
 	OTP          string `json:"otp" gorm:"column:otp;type:varchar(6)"`
 	Status       string `json:"status" gorm:"column:status;type:varchar(10)"`
 	CreatedAt    time.Time
@@ -65,3 +86,30 @@ func (l WalletStructOTP) Validate() error {
 	v := validator.New()
 	return v.Struct(l)
 }
+
+type WalletStatusHistory struct {
+	ID           int       `json:"id"`
+	WalletID     int       `json:"wallet_id" gorm:"column:wallet_id;index"`
+	FromStatus   string    `json:"from_status" gorm:"column:from_status;type:varchar(20)"`
+	ToStatus     string    `json:"to_status" gorm:"column:to_status;type:varchar(20)"`
+	ReasonCode   string    `json:"reason_code" gorm:"column:reason_code;type:varchar(50)"`
+	Note         string    `json:"note" gorm:"column:note;type:varchar(255)"`
+	ChangedBy    string    `json:"changed_by" gorm:"column:changed_by;type:varchar(100)"`
+	PayoutRef    string    `json:"payout_reference,omitempty" gorm:"column:payout_reference;type:varchar(100)"`
+	PayoutAmount float64   `json:"payout_amount,omitempty" gorm:"column:payout_amount;type:decimal(15,2)"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (*WalletStatusHistory) TableName() string {
+	return "wallet_status_histories"
+}
+
+type WalletStatusRequest struct {
+	ReasonCode string `json:"reason_code" validate:"required"`
+	Note       string `json:"note" validate:"max=255"`
+}
+
+func (l WalletStatusRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// BankAccount is a payout destination saved by a user, the holder name comes
+// from the payout provider's account inquiry.
+type BankAccount struct {
+	ID            int       `json:"id"`
+	UserID        uint64    `json:"user_id" gorm:"column:user_id;uniqueIndex:idx_bank_account_user_number"`
+	BankCode      string    `json:"bank_code" gorm:"column:bank_code;type:varchar(20);uniqueIndex:idx_bank_account_user_number"`
+	AccountNumber string    `json:"account_number" gorm:"column:account_number;type:varchar(30);uniqueIndex:idx_bank_account_user_number"`
+	AccountName   string    `json:"account_name" gorm:"column:account_name;type:varchar(100)"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (*BankAccount) TableName() string {
+	return "bank_accounts"
+}
+
+type BankAccountRequest struct {
+	BankCode      string `json:"bank_code" validate:"required"`
+	AccountNumber string `json:"account_number" validate:"required,numeric,min=6,max=20"`
+}
+
+func (l BankAccountRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+// Withdrawal moves money from the wallet to a bank account. Amount plus Fee
+// is held on the wallet while the payout is in flight, and is debited when
+// the provider reports success or released when it reports failure.
+type Withdrawal struct {
+	ID                int        `json:"id"`
+	UserID            uint64     `json:"user_id" gorm:"column:user_id;uniqueIndex:idx_withdrawal_user_reference"`
+	WalletID          int        `json:"wallet_id" gorm:"column:wallet_id;index"`
+	BankAccountID     int        `json:"bank_account_id" gorm:"column:bank_account_id"`
+	BankCode          string     `json:"bank_code" gorm:"column:bank_code;type:varchar(20)"`
+	AccountNumber     string     `json:"account_number" gorm:"column:account_number;type:varchar(30)"`
+	AccountName       string     `json:"account_name" gorm:"column:account_name;type:varchar(100)"`
+	Amount            float64    `json:"amount" gorm:"column:amount;type:decimal(15,2)"`
+	Fee               float64    `json:"fee" gorm:"column:fee;type:decimal(15,2)"`
+	Status            string     `json:"status" gorm:"column:status;type:varchar(20);index"`
+	Reference         string     `json:"reference" gorm:"column:reference;type:varchar(100);uniqueIndex:idx_withdrawal_user_reference"`
+	PayoutReference   string     `json:"payout_reference" gorm:"column:payout_reference;type:varchar(100);unique"`
+	Provider          string     `json:"provider" gorm:"column:provider;type:varchar(50)"`
+	ProviderReference string     `json:"provider_reference,omitempty" gorm:"column:provider_reference;type:varchar(100)"`
+	FailureReason     string     `json:"failure_reason,omitempty" gorm:"column:failure_reason;type:varchar(255)"`
+	SubmittedAt       *time.Time `json:"submitted_at,omitempty" gorm:"column:submitted_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty" gorm:"column:completed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+func (*Withdrawal) TableName() string {
+	return "withdrawals"
+}
+
+// Total is what the wallet pays for the withdrawal.
+func (w Withdrawal) Total() float64 {
+	return w.Amount + w.Fee
+}
+
+type WithdrawalRequest struct {
+	BankAccountID int     `json:"bank_account_id" validate:"required"`
+	Amount        float64 `json:"amount" validate:"required,gt=0"`
+	// Reference is the caller's idempotency key, repeating a request with the
+	// same reference returns the withdrawal created the first time.
+	Reference string `json:"reference" validate:"required,max=100"`
+}
+
+func (l WithdrawalRequest) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type WithdrawalParam struct {
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+	Status string `form:"status" validate:"omitempty,oneof=requested processing succeeded failed"`
+}
+
+func (l WithdrawalParam) Validate() error {
+	v := validator.New()
+	return v.Struct(l)
+}
+
+type PayoutRequest struct {
+	Reference     string
+	BankCode      string
+	AccountNumber string
+	AccountName   string
+	Amount        float64
+}
+
+type Payout struct {
+	ProviderReference string
+}
+
+// PayoutCallback is a verified payout notification, Status is either
+// succeeded or failed.
+type PayoutCallback struct {
+	Reference         string `json:"reference"`
+	ProviderReference string `json:"provider_reference"`
+	Status            string `json:"status"`
+	FailureReason     string `json:"failure_reason"`
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AdminRepo struct {
+	DB *gorm.DB
+}
+
+func (r *AdminRepo) CreateOperator(ctx context.Context, operator *models.Operator) error {
+	return r.DB.Create(operator).Error
+}
+
+func (r *AdminRepo) GetOperatorByUsername(ctx context.Context, username string) (models.Operator, error) {
+	var (
+		resp models.Operator
+	)
+
+	err := r.DB.Where("username = ?", username).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *AdminRepo) CountOperators(ctx context.Context) (int64, error) {
+	var (
+		resp int64
+	)
+
+	err := r.DB.Model(&models.Operator{}).Count(&resp).Error
+
+	return resp, err
+}
+
+func (r *AdminRepo) CreateAdjustment(ctx context.Context, adjustment *models.BalanceAdjustment) error {
+	return r.DB.Create(adjustment).Error
+}
+
+func (r *AdminRepo) GetAdjustmentByID(ctx context.Context, adjustmentID int) (models.BalanceAdjustment, error) {
+	var (
+		resp models.BalanceAdjustment
+	)
+
+	err := r.DB.Where("id = ?", adjustmentID).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *AdminRepo) GetAdjustments(ctx context.Context, param models.AdjustmentParam, offset int, limit int) ([]models.BalanceAdjustment, error) {
+	var (
+		resp []models.BalanceAdjustment
+	)
+
+	sql := r.DB
+	if param.Status != "" {
+		sql = sql.Where("status = ?", param.Status)
+	}
+	if param.WalletID != 0 {
+		sql = sql.Where("wallet_id = ?", param.WalletID)
+	}
+	err := sql.Limit(limit).Offset(offset).Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+// DecideAdjustment moves a pending adjustment to approved or rejected. The
+// conditions are repeated in the statement so two checkers racing on the same
+// adjustment, the maker, or a late decision after expiry can never win.
+func (r *AdminRepo) DecideAdjustment(ctx context.Context, adjustmentID int, status string, checkerID int, checkerUsername string, note string, now time.Time) error {
+	return decideAdjustment(r.DB, adjustmentID, status, checkerID, checkerUsername, note, now)
+}
+
+// ApproveAdjustment approves a pending adjustment and posts walletTrx in the
+// same transaction, an adjustment is never approved without its posting. The
+// wallet is returned as it was before.
+func (r *AdminRepo) ApproveAdjustment(ctx context.Context, adjustmentID int, checkerID int, checkerUsername string, note string, walletTrx *models.WalletTransaction, now time.Time) (models.Wallet, error) {
+	var wallet models.Wallet
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := decideAdjustment(tx, adjustmentID, constants.AdjustmentStatusApproved, checkerID, checkerUsername, note, now)
+		if err != nil {
+			return err
+		}
+
+		wallet, err = moveBalance(tx, walletTrx.WalletID, walletTrx.Amount, walletTrx)
+		return err
+	})
+
+	return wallet, err
+}
+
+// FailAdjustment records the checker's decision on a pending adjustment whose
+// posting the wallet refused, with the reason apart from the decision note.
+func (r *AdminRepo) FailAdjustment(ctx context.Context, adjustmentID int, checkerID int, checkerUsername string, note string, reason string, now time.Time) error {
+	result := r.DB.Exec("UPDATE balance_adjustments SET status = ?, checker_id = ?, checker_username = ?, decision_note = ?, failure_reason = ?, decided_at = ?, updated_at = ? "+
+		"WHERE id = ? AND status = ?",
+		constants.AdjustmentStatusFailed, checkerID, checkerUsername, note, reason, now, now,
+		adjustmentID, constants.AdjustmentStatusPending)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrAdjustmentNotPending
+	}
+
+	return nil
+}
+
+func decideAdjustment(db *gorm.DB, adjustmentID int, status string, checkerID int, checkerUsername string, note string, now time.Time) error {
+	result := db.Exec("UPDATE balance_adjustments SET status = ?, checker_id = ?, checker_username = ?, decision_note = ?, decided_at = ?, updated_at = ? "+
+		"WHERE id = ? AND status = ? AND maker_id <> ? AND expires_at > ?",
+		status, checkerID, checkerUsername, note, now, now,
+		adjustmentID, constants.AdjustmentStatusPending, checkerID, now)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrAdjustmentNotPending
+	}
+
+	return nil
+}
+
+func (r *AdminRepo) UpdateAdjustmentStatus(ctx context.Context, adjustmentID int, fromStatus string, toStatus string) error {
+	result := r.DB.Exec("UPDATE balance_adjustments SET status = ?, updated_at = ? WHERE id = ? AND status = ?",
+		toStatus, time.Now(), adjustmentID, fromStatus)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrAdjustmentNotPending
+	}
+
+	return nil
+}
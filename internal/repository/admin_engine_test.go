@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminRepoEngine_ApproveAdjustment(t *testing.T) {
+	db := openEngine(t)
+	walletRepo := &WalletRepo{DB: db}
+	r := &AdminRepo{DB: db}
+
+	wallet := createEngineWallet(t, walletRepo, 1, 100)
+	now := time.Now()
+
+	createAdjustment := func(reference string) models.BalanceAdjustment {
+		adjustment := models.BalanceAdjustment{
+			WalletID:  wallet.ID,
+			Status:    constants.AdjustmentStatusPending,
+			Reference: reference,
+			MakerID:   1,
+			ExpiresAt: now.Add(time.Hour),
+		}
+		assert.NoError(t, r.CreateAdjustment(context.Background(), &adjustment))
+		return adjustment
+	}
+	adjustmentTrx := func(reference string, amount float64) *models.WalletTransaction {
+		return &models.WalletTransaction{
+			WalletID:              wallet.ID,
+			Amount:                amount,
+			Reference:             reference,
+			WalletTransactionType: constants.TransactionTypeAdjustment,
+		}
+	}
+
+	t.Run("approval posts with it", func(t *testing.T) {
+		adjustment := createAdjustment("ADJ-1")
+
+		got, err := r.ApproveAdjustment(context.Background(), adjustment.ID, 2, "checker", "ok", adjustmentTrx("ADJ-1", -40), now)
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, got.Balance)
+
+		saved, err := r.GetAdjustmentByID(context.Background(), adjustment.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, constants.AdjustmentStatusApproved, saved.Status)
+
+		trx, err := walletRepo.GetWalletTransactionByReference(context.Background(), "ADJ-1")
+		assert.NoError(t, err)
+		assert.Equal(t, -40.0, trx.Amount)
+	})
+
+	t.Run("refused posting leaves it pending", func(t *testing.T) {
+		adjustment := createAdjustment("ADJ-2")
+
+		_, err := r.ApproveAdjustment(context.Background(), adjustment.ID, 2, "checker", "ok", adjustmentTrx("ADJ-2", -500), now)
+		assert.ErrorIs(t, err, constants.ErrInsufficientBalance)
+
+		saved, err := r.GetAdjustmentByID(context.Background(), adjustment.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, constants.AdjustmentStatusPending, saved.Status)
+
+		// the failure keeps the checker's note
+		assert.NoError(t, r.FailAdjustment(context.Background(), adjustment.ID, 2, "checker", "ok", "insufficient balance", now))
+		saved, err = r.GetAdjustmentByID(context.Background(), adjustment.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, constants.AdjustmentStatusFailed, saved.Status)
+		assert.Equal(t, "ok", saved.DecisionNote)
+		assert.Equal(t, "insufficient balance", saved.FailureReason)
+
+		got, err := walletRepo.GetWalletByID(context.Background(), wallet.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 60.0, got.Balance)
+	})
+
+	t.Run("error decided already", func(t *testing.T) {
+		_, err := r.ApproveAdjustment(context.Background(), createAdjustment("ADJ-3").ID, 1, "maker", "", adjustmentTrx("ADJ-3", 10), now)
+		assert.ErrorIs(t, err, constants.ErrAdjustmentNotPending)
+
+		_, err = walletRepo.GetWalletTransactionByReference(context.Background(), "ADJ-3")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestAdminRepo_DecideAdjustment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+	query := regexp.QuoteMeta("UPDATE balance_adjustments SET status = ?, checker_id = ?, checker_username = ?, decision_note = ?, decided_at = ?, updated_at = ? " +
+		"WHERE id = ? AND status = ? AND maker_id <> ? AND expires_at > ?")
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mock.ExpectExec(query).WithArgs(
+					"approved", 2, "checker", "ok", now, now, 10, "pending", 2, now,
+				).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "error not pending",
+			wantErr: constants.ErrAdjustmentNotPending,
+			mockFn: func() {
+				mock.ExpectExec(query).WithArgs(
+					"approved", 2, "checker", "ok", now, now, 10, "pending", 2, now,
+				).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: assert.AnError,
+			mockFn: func() {
+				mock.ExpectExec(query).WillReturnError(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &AdminRepo{
+				DB: gormDB,
+			}
+			err := r.DecideAdjustment(context.Background(), 10, "approved", 2, "checker", "ok", now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"ewallet-wallet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const auditAppendAttempts = 3
+
+type AuditRepo struct {
+	DB *gorm.DB
+}
+
+// AppendAuditLog links the entry to the current head of the chain and inserts
+// it. Two writers that read the same head collide on the unique prev_hash
+// index, the loser re-reads the head and tries again.
+func (r *AuditRepo) AppendAuditLog(ctx context.Context, auditLog *models.AuditLog) error {
+	for attempt := 1; ; attempt++ {
+		err := r.DB.Transaction(func(tx *gorm.DB) error {
+			var head models.AuditLog
+			err := tx.Raw("SELECT id, hash FROM audit_logs ORDER BY id DESC LIMIT 1 FOR UPDATE").Scan(&head).Error
+			if err != nil {
+				return err
+			}
+
+			auditLog.ID = 0
+			auditLog.PrevHash = head.Hash
+			auditLog.Hash = auditLog.ComputeHash()
+
+			return tx.Create(auditLog).Error
+		})
+		if err == nil || attempt >= auditAppendAttempts || !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return err
+		}
+	}
+}
+
+func (r *AuditRepo) GetAuditLogs(ctx context.Context, param models.AuditLogParam, offset int, limit int) ([]models.AuditLog, error) {
+	var (
+		resp []models.AuditLog
+	)
+
+	sql := r.DB
+	if param.Actor != "" {
+		sql = sql.Where("actor = ?", param.Actor)
+	}
+	if param.Action != "" {
+		sql = sql.Where("action = ?", param.Action)
+	}
+	if param.TargetType != "" {
+		sql = sql.Where("target_type = ?", param.TargetType)
+	}
+	if param.TargetID != "" {
+		sql = sql.Where("target_id = ?", param.TargetID)
+	}
+	if param.RequestID != "" {
+		sql = sql.Where("request_id = ?", param.RequestID)
+	}
+	err := sql.Limit(limit).Offset(offset).Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+// GetAuditLogChain returns entries in chain order, starting after afterID.
+func (r *AuditRepo) GetAuditLogChain(ctx context.Context, afterID int, limit int) ([]models.AuditLog, error) {
+	var (
+		resp []models.AuditLog
+	)
+
+	err := r.DB.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&resp).Error
+
+	return resp, err
+}
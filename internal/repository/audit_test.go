@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestAuditRepo_AppendAuditLog(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now().Truncate(time.Second)
+	headHash := "5d41402abc4b2a76b9719d911017c592"
+
+	type args struct {
+		ctx      context.Context
+		auditLog *models.AuditLog
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantErr      bool
+		wantPrevHash string
+		mockFn       func(args args)
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx: context.Background(),
+				auditLog: &models.AuditLog{
+					ActorType:  "operator",
+					Actor:      "operator",
+					ActorRole:  "admin",
+					Action:     "wallet.freeze",
+					TargetType: "wallet",
+					TargetID:   "1",
+					RequestID:  "request-id",
+					IP:         "127.0.0.1",
+					Before:     `{"status":"active"}`,
+					After:      `{"status":"frozen"}`,
+					CreatedAt:  now,
+				},
+			},
+			wantErr:      false,
+			wantPrevHash: headHash,
+			mockFn: func(args args) {
+				expected := *args.auditLog
+				expected.PrevHash = headHash
+
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, hash FROM audit_logs ORDER BY id DESC LIMIT 1 FOR UPDATE")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "hash"}).AddRow(7, headHash))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `audit_logs` (`actor_type`,`actor`,`actor_role`,`action`,`target_type`,`target_id`,`request_id`,`ip`,`before_value`,`after_value`,`detail`,`prev_hash`,`hash`,`created_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).WithArgs(
+					expected.ActorType,
+					expected.Actor,
+					expected.ActorRole,
+					expected.Action,
+					expected.TargetType,
+					expected.TargetID,
+					expected.RequestID,
+					expected.IP,
+					expected.Before,
+					expected.After,
+					expected.Detail,
+					headHash,
+					expected.ComputeHash(),
+					sqlmock.AnyArg(),
+				).WillReturnResult(sqlmock.NewResult(8, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "success first entry",
+			args: args{
+				ctx: context.Background(),
+				auditLog: &models.AuditLog{
+					ActorType: "system",
+					Action:    "balance.credit",
+					CreatedAt: now,
+				},
+			},
+			wantErr:      false,
+			wantPrevHash: "",
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, hash FROM audit_logs ORDER BY id DESC LIMIT 1 FOR UPDATE")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "hash"}))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `audit_logs`")).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "success retry after chain fork",
+			args: args{
+				ctx: context.Background(),
+				auditLog: &models.AuditLog{
+					ActorType: "system",
+					Action:    "balance.credit",
+					CreatedAt: now,
+				},
+			},
+			wantErr:      false,
+			wantPrevHash: "newer-head",
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, hash FROM audit_logs ORDER BY id DESC LIMIT 1 FOR UPDATE")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "hash"}).AddRow(7, headHash))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `audit_logs`")).WillReturnError(gorm.ErrDuplicatedKey)
+				mock.ExpectRollback()
+
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, hash FROM audit_logs ORDER BY id DESC LIMIT 1 FOR UPDATE")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "hash"}).AddRow(8, "newer-head"))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `audit_logs`")).WillReturnResult(sqlmock.NewResult(9, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "error",
+			args: args{
+				ctx: context.Background(),
+				auditLog: &models.AuditLog{
+					ActorType: "operator",
+					Actor:     "operator",
+					Action:    "wallet.freeze",
+				},
+			},
+			wantErr: true,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, hash FROM audit_logs ORDER BY id DESC LIMIT 1 FOR UPDATE")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "hash"}))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `audit_logs`")).WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.args)
+			r := &AuditRepo{
+				DB: gormDB,
+			}
+			err := r.AppendAuditLog(tt.args.ctx, tt.args.auditLog)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AuditRepo.AppendAuditLog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.wantPrevHash, tt.args.auditLog.PrevHash)
+				assert.Equal(t, tt.args.auditLog.ComputeHash(), tt.args.auditLog.Hash)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuditRepo_GetAuditLogs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `audit_logs` WHERE actor = ? AND target_type = ? AND request_id = ? ORDER BY id DESC LIMIT ? OFFSET ?")).WithArgs(
+		"operator",
+		"wallet",
+		"request-id",
+		10,
+		10,
+	).WillReturnRows(sqlmock.NewRows([]string{"id", "actor", "action"}).AddRow(2, "operator", "wallet.freeze"))
+
+	r := &AuditRepo{
+		DB: gormDB,
+	}
+	got, err := r.GetAuditLogs(context.Background(), models.AuditLogParam{Actor: "operator", TargetType: "wallet", RequestID: "request-id"}, 10, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.AuditLog{{ID: 2, Actor: "operator", Action: "wallet.freeze"}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepo_GetAuditLogChain(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `audit_logs` WHERE id > ? ORDER BY id ASC LIMIT ?")).WithArgs(
+		5,
+		100,
+	).WillReturnRows(sqlmock.NewRows([]string{"id", "prev_hash", "hash"}).AddRow(6, "a", "b"))
+
+	r := &AuditRepo{
+		DB: gormDB,
+	}
+	got, err := r.GetAuditLogChain(context.Background(), 5, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.AuditLog{{ID: 6, PrevHash: "a", Hash: "b"}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
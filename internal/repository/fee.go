@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type FeeRepo struct {
+	DB *gorm.DB
+}
+
+func (r *FeeRepo) CreateFeeRule(ctx context.Context, rule *models.FeeRule) error {
+	return r.DB.Create(rule).Error
+}
+
+func (r *FeeRepo) GetFeeRules(ctx context.Context, operation string, offset int, limit int) ([]models.FeeRule, error) {
+	var (
+		resp []models.FeeRule
+	)
+
+	sql := r.DB
+	if operation != "" {
+		sql = sql.Where("operation = ?", operation)
+	}
+	err := sql.Limit(limit).Offset(offset).Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+func (r *FeeRepo) GetActiveFeeRules(ctx context.Context, operation string) ([]models.FeeRule, error) {
+	var (
+		resp []models.FeeRule
+	)
+
+	err := r.DB.Where("operation = ? AND active = ?", operation, true).Order("id ASC").Find(&resp).Error
+
+	return resp, err
+}
+
+func (r *FeeRepo) DisableFeeRule(ctx context.Context, ruleID int, now time.Time) error {
+	result := r.DB.Exec("UPDATE fee_rules SET active = ?, updated_at = ? WHERE id = ? AND active = ?", false, now, ruleID, true)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrFeeRuleNotFound
+	}
+
+	return nil
+}
+
+// CountPaidTransfers counts the P2P transfers paid from the wallet since the
+// given time, accepted payment requests are the only P2P transfers.
+func (r *FeeRepo) CountPaidTransfers(ctx context.Context, walletID int, since time.Time) (int, error) {
+	return countPaidTransfers(r.DB, walletID, since)
+}
+
+func countPaidTransfers(db *gorm.DB, walletID int, since time.Time) (int, error) {
+	var count int64
+
+	err := db.Model(&models.PaymentRequest{}).
+		Where("payer_wallet_id = ? AND status = ? AND decided_at >= ?", walletID, constants.PaymentRequestStatusPaid, since).
+		Count(&count).Error
+
+	return int(count), err
+}
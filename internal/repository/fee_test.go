@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestFeeRepo_DisableFeeRule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+	disableQuery := regexp.QuoteMeta("UPDATE fee_rules SET active = ?, updated_at = ? WHERE id = ? AND active = ?")
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mock.ExpectExec(disableQuery).WithArgs(false, now, 3, true).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "error already disabled",
+			wantErr: constants.ErrFeeRuleNotFound,
+			mockFn: func() {
+				mock.ExpectExec(disableQuery).WithArgs(false, now, 3, true).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &FeeRepo{
+				DB: gormDB,
+			}
+			err := r.DisableFeeRule(context.Background(), 3, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFeeRepo_CountPaidTransfers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	since := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `payment_requests` WHERE payer_wallet_id = ? AND status = ? AND decided_at >= ?")).
+		WithArgs(2, constants.PaymentRequestStatusPaid, since).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+	r := &FeeRepo{
+		DB: gormDB,
+	}
+	got, err := r.CountPaidTransfers(context.Background(), 2, since)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
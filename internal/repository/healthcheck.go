@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"ewallet-wallet/migrations"
+
+	"gorm.io/gorm"
+)
+
+type HealthcheckRepo struct {
+	DB       *gorm.DB
+	Migrator *migrations.Migrator
+}
+
+func (r *HealthcheckRepo) Ping(ctx context.Context) error {
+	db, err := r.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	return db.PingContext(ctx)
+}
+
+// GetMigrationHealth compares the migrations embedded in the binary with the
+// ones recorded in the database.
+func (r *HealthcheckRepo) GetMigrationHealth(ctx context.Context) (models.MigrationHealth, error) {
+	resp := models.MigrationHealth{
+		Latest: r.Migrator.Latest(),
+	}
+
+	statuses, err := r.Migrator.Status(ctx)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, status := range statuses {
+		switch {
+		case !status.Applied:
+			resp.Pending++
+		case status.Modified:
+			resp.Modified++
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"ewallet-wallet/migrations"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestHealthcheckRepo_Ping(t *testing.T) {
+	db := openEngine(t)
+	r := &HealthcheckRepo{DB: db}
+
+	assert.NoError(t, r.Ping(context.Background()))
+
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.Close()
+	assert.Error(t, r.Ping(context.Background()))
+}
+
+func TestHealthcheckRepo_GetMigrationHealth(t *testing.T) {
+	db := openEngine(t)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+
+	driver := os.Getenv("TEST_DB_DRIVER")
+	if driver == "" {
+		driver = constants.DBDriverSQLite
+	}
+	embedded, err := migrations.New(sqlDB, driver)
+	assert.NoError(t, err)
+
+	// a build with one migration more than the database
+	newer, err := migrations.NewFromFS(sqlDB, fstest.MapFS{
+		"9999_next.up.sql":   {Data: []byte("SELECT 1;")},
+		"9999_next.down.sql": {Data: []byte("SELECT 1;")},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		migrator *migrations.Migrator
+		mockFn   func(db *gorm.DB)
+		want     models.MigrationHealth
+	}{
+		{
+			name:     "current",
+			migrator: embedded,
+			want:     models.MigrationHealth{Latest: embedded.Latest()},
+		},
+		{
+			name:     "pending",
+			migrator: newer,
+			want:     models.MigrationHealth{Latest: 9999, Pending: 1},
+		},
+		{
+			name:     "modified",
+			migrator: embedded,
+			mockFn: func(db *gorm.DB) {
+				var checksum string
+				assert.NoError(t, db.Raw("SELECT checksum FROM schema_migrations WHERE version = ?", embedded.Latest()).Scan(&checksum).Error)
+				assert.NoError(t, db.Exec("UPDATE schema_migrations SET checksum = ? WHERE version = ?", "changed", embedded.Latest()).Error)
+				t.Cleanup(func() {
+					db.Exec("UPDATE schema_migrations SET checksum = ? WHERE version = ?", checksum, embedded.Latest())
+				})
+			},
+			want: models.MigrationHealth{Latest: embedded.Latest(), Modified: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockFn != nil {
+				tt.mockFn(db)
+			}
+
+			r := &HealthcheckRepo{DB: db, Migrator: tt.migrator}
+			got, err := r.GetMigrationHealth(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PaymentRequestRepo struct {
+	DB *gorm.DB
+}
+
+func (r *PaymentRequestRepo) CreatePaymentRequest(ctx context.Context, request *models.PaymentRequest) error {
+	return r.DB.Create(request).Error
+}
+
+func (r *PaymentRequestRepo) GetPaymentRequestByID(ctx context.Context, requestID int) (models.PaymentRequest, error) {
+	var (
+		resp models.PaymentRequest
+	)
+
+	err := r.DB.Where("id = ?", requestID).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *PaymentRequestRepo) GetPaymentRequests(ctx context.Context, requesterUserID uint64, payerUserID uint64, status string, offset int, limit int) ([]models.PaymentRequest, error) {
+	var (
+		resp []models.PaymentRequest
+	)
+
+	sql := r.DB
+	if requesterUserID != 0 {
+		sql = sql.Where("requester_user_id = ?", requesterUserID)
+	}
+	if payerUserID != 0 {
+		sql = sql.Where("payer_user_id = ?", payerUserID)
+	}
+	if status != "" {
+		sql = sql.Where("status = ?", status)
+	}
+	err := sql.Limit(limit).Offset(offset).Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+// UpdatePaymentRequestStatus closes a pending request. Only the sweeper may
+// expire a request and only once its expiry passed, every other decision has
+// to land before it.
+func (r *PaymentRequestRepo) UpdatePaymentRequestStatus(ctx context.Context, requestID int, toStatus string, now time.Time) error {
+	expiryCondition := "expires_at > ?"
+	if toStatus == constants.PaymentRequestStatusExpired {
+		expiryCondition = "expires_at <= ?"
+	}
+
+	result := r.DB.Exec("UPDATE payment_requests SET status = ?, decided_at = ?, updated_at = ? WHERE id = ? AND status = ? AND "+expiryCondition,
+		toStatus, now, now, requestID, constants.PaymentRequestStatusPending, now)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrPaymentRequestNotPending
+	}
+
+	return nil
+}
+
+// PayPaymentRequest marks the request paid and moves the money in the same
+// transaction, a request is either paid with both ledger rows or untouched.
+// A fee with a free quota is waived when the payer has paid fewer transfers,
+// counted once both wallets are locked so two transfers can not both take
+// the last free one.
+func (r *PaymentRequestRepo) PayPaymentRequest(ctx context.Context, requestID int, debit *models.WalletTransaction, credit *models.WalletTransaction, fee *models.FeePosting, now time.Time) (models.Wallet, models.Wallet, error) {
+	var (
+		from models.Wallet
+		to   models.Wallet
+	)
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if fee != nil && fee.FreeQuota > 0 {
+			var locked []int
+			err := tx.Raw("SELECT id FROM wallets WHERE id IN ? ORDER BY id FOR UPDATE",
+				[]int{debit.WalletID, credit.WalletID}).Scan(&locked).Error
+			if err != nil {
+				return err
+			}
+
+			used, err := countPaidTransfers(tx, debit.WalletID, fee.QuotaSince)
+			if err != nil {
+				return err
+			}
+			fee.Waived = used < fee.FreeQuota
+		}
+
+		result := tx.Exec("UPDATE payment_requests SET status = ?, fee = ?, decided_at = ?, updated_at = ? WHERE id = ? AND status = ? AND expires_at > ?",
+			constants.PaymentRequestStatusPaid, fee.Amount(), now, now, requestID, constants.PaymentRequestStatusPending, now)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return constants.ErrPaymentRequestNotPending
+		}
+
+		var err error
+		from, to, err = transferBalance(tx, debit, credit, fee)
+		return err
+	})
+
+	return from, to, err
+}
+
+func (r *PaymentRequestRepo) GetExpiredPaymentRequests(ctx context.Context, now time.Time, limit int) ([]models.PaymentRequest, error) {
+	var (
+		resp []models.PaymentRequest
+	)
+
+	err := r.DB.Where("status = ? AND expires_at <= ?", constants.PaymentRequestStatusPending, now).
+		Order("expires_at ASC").Limit(limit).Find(&resp).Error
+
+	return resp, err
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentRequestRepoEngine_ParallelFreeQuota(t *testing.T) {
+	db := openEngine(t)
+	walletRepo := &WalletRepo{DB: db}
+	r := &PaymentRequestRepo{DB: db}
+
+	payer := createEngineWallet(t, walletRepo, 1, 1000)
+	requester := createEngineWallet(t, walletRepo, 2, 0)
+	revenue := createEngineWallet(t, walletRepo, constants.FeeRevenueUserID, 0)
+
+	now := time.Now()
+	requests := make([]models.PaymentRequest, 6)
+	for i := range requests {
+		requests[i] = models.PaymentRequest{
+			RequesterUserID:   requester.UserID,
+			RequesterWalletID: requester.ID,
+			PayerUserID:       payer.UserID,
+			PayerWalletID:     payer.ID,
+			Amount:            10,
+			Status:            constants.PaymentRequestStatusPending,
+			Reference:         fmt.Sprintf("PRQ-%d", i),
+			ExpiresAt:         now.Add(time.Hour),
+		}
+		assert.NoError(t, r.CreatePaymentRequest(context.Background(), &requests[i]))
+	}
+
+	// the paid transfers are counted under the wallet locks, so however many
+	// are paid at once only two of them are free
+	var wg sync.WaitGroup
+	for _, request := range requests {
+		wg.Add(1)
+		go func(request models.PaymentRequest) {
+			defer wg.Done()
+			fee := &models.FeePosting{
+				Fee:        &models.WalletTransaction{WalletID: payer.ID, Amount: 1, WalletTransactionType: constants.TransactionTypeFee},
+				Revenue:    &models.WalletTransaction{WalletID: revenue.ID, Amount: 1, WalletTransactionType: constants.TransactionTypeCredit},
+				FreeQuota:  2,
+				QuotaSince: now.Add(-time.Hour),
+			}
+			_, _, err := r.PayPaymentRequest(context.Background(), request.ID,
+				&models.WalletTransaction{WalletID: payer.ID, Amount: 10, WalletTransactionType: constants.TransactionTypeDebit, Reference: request.Reference + "-D"},
+				&models.WalletTransaction{WalletID: requester.ID, Amount: 10, WalletTransactionType: constants.TransactionTypeCredit, Reference: request.Reference + "-C"},
+				fee, now)
+			assert.NoError(t, err)
+		}(request)
+	}
+	wg.Wait()
+
+	var free int64
+	assert.NoError(t, db.Model(&models.PaymentRequest{}).Where("status = ? AND fee = 0", constants.PaymentRequestStatusPaid).Count(&free).Error)
+	assert.Equal(t, int64(2), free)
+
+	got, err := walletRepo.GetWalletByID(context.Background(), payer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 936.0, got.Balance)
+
+	got, err = walletRepo.GetWalletByID(context.Background(), revenue.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 4.0, got.Balance)
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestPaymentRequestRepo_UpdatePaymentRequestStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		toStatus string
+		wantErr  error
+		mockFn   func()
+	}{
+		{
+			name:     "success decline",
+			toStatus: constants.PaymentRequestStatusDeclined,
+			mockFn: func() {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE payment_requests SET status = ?, decided_at = ?, updated_at = ? WHERE id = ? AND status = ? AND expires_at > ?")).
+					WithArgs(constants.PaymentRequestStatusDeclined, now, now, 5, constants.PaymentRequestStatusPending, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "success expire",
+			toStatus: constants.PaymentRequestStatusExpired,
+			mockFn: func() {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE payment_requests SET status = ?, decided_at = ?, updated_at = ? WHERE id = ? AND status = ? AND expires_at <= ?")).
+					WithArgs(constants.PaymentRequestStatusExpired, now, now, 5, constants.PaymentRequestStatusPending, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "error not pending",
+			toStatus: constants.PaymentRequestStatusCancelled,
+			wantErr:  constants.ErrPaymentRequestNotPending,
+			mockFn: func() {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE payment_requests SET status = ?")).
+					WithArgs(constants.PaymentRequestStatusCancelled, now, now, 5, constants.PaymentRequestStatusPending, now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:     "error",
+			toStatus: constants.PaymentRequestStatusDeclined,
+			wantErr:  assert.AnError,
+			mockFn: func() {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE payment_requests SET status = ?")).
+					WithArgs(constants.PaymentRequestStatusDeclined, now, now, 5, constants.PaymentRequestStatusPending, now).
+					WillReturnError(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &PaymentRequestRepo{
+				DB: gormDB,
+			}
+			err := r.UpdatePaymentRequestStatus(context.Background(), 5, tt.toStatus, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPaymentRequestRepo_PayPaymentRequest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+	updateQuery := regexp.QuoteMeta("UPDATE payment_requests SET status = ?, fee = ?, decided_at = ?, updated_at = ? WHERE id = ? AND status = ? AND expires_at > ?")
+	lockQuery := regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id IN (?,?) ORDER BY id FOR UPDATE")
+	walletColumns := []string{"id", "user_id", "balance", "status"}
+
+	tests := []struct {
+		name     string
+		wantFrom models.Wallet
+		wantTo   models.Wallet
+		wantErr  error
+		mockFn   func()
+	}{
+		{
+			name:     "success",
+			wantFrom: models.Wallet{ID: 2, UserID: 20, Balance: 100000, Status: constants.WalletStatusActive},
+			wantTo:   models.Wallet{ID: 1, UserID: 10, Balance: 5000, Status: constants.WalletStatusActive},
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(updateQuery).
+					WithArgs(constants.PaymentRequestStatusPaid, 0.0, now, now, 5, constants.PaymentRequestStatusPending, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(lockQuery).WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).
+						AddRow(1, 10, 5000, constants.WalletStatusActive).
+						AddRow(2, 20, 100000, constants.WalletStatusActive))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance - ? WHERE id = ?")).WithArgs(25000.0, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + ? WHERE id = ?")).WithArgs(25000.0, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(2, 25000.0, constants.TransactionTypeDebit, "PRQ-1-1-D", "", 0, constants.BalanceTypeCash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(11, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(1, 25000.0, constants.TransactionTypeCredit, "PRQ-1-1-C", "", 0, constants.BalanceTypeCash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(12, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error not pending",
+			wantErr: constants.ErrPaymentRequestNotPending,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(updateQuery).
+					WithArgs(constants.PaymentRequestStatusPaid, 0.0, now, now, 5, constants.PaymentRequestStatusPending, now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name:     "error insufficient balance",
+			wantFrom: models.Wallet{ID: 2, UserID: 20, Balance: 1000, Status: constants.WalletStatusActive},
+			wantTo:   models.Wallet{ID: 1, UserID: 10, Balance: 5000, Status: constants.WalletStatusActive},
+			wantErr:  constants.ErrInsufficientBalance,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(updateQuery).
+					WithArgs(constants.PaymentRequestStatusPaid, 0.0, now, now, 5, constants.PaymentRequestStatusPending, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(lockQuery).WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).
+						AddRow(1, 10, 5000, constants.WalletStatusActive).
+						AddRow(2, 20, 1000, constants.WalletStatusActive))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name:     "error payer frozen",
+			wantFrom: models.Wallet{ID: 2, UserID: 20, Balance: 100000, Status: constants.WalletStatusFrozen},
+			wantTo:   models.Wallet{ID: 1, UserID: 10, Balance: 5000, Status: constants.WalletStatusActive},
+			wantErr:  constants.ErrWalletFrozen,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(updateQuery).
+					WithArgs(constants.PaymentRequestStatusPaid, 0.0, now, now, 5, constants.PaymentRequestStatusPending, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(lockQuery).WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).
+						AddRow(1, 10, 5000, constants.WalletStatusActive).
+						AddRow(2, 20, 100000, constants.WalletStatusFrozen))
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &PaymentRequestRepo{
+				DB: gormDB,
+			}
+			debit := &models.WalletTransaction{WalletID: 2, Amount: 25000, WalletTransactionType: constants.TransactionTypeDebit, Reference: "PRQ-1-1-D"}
+			credit := &models.WalletTransaction{WalletID: 1, Amount: 25000, WalletTransactionType: constants.TransactionTypeCredit, Reference: "PRQ-1-1-C"}
+			from, to, err := r.PayPaymentRequest(context.Background(), 5, debit, credit, nil, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 11, debit.ID)
+				assert.Equal(t, 12, credit.ID)
+			}
+			assert.Equal(t, tt.wantFrom, from)
+			assert.Equal(t, tt.wantTo, to)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PINRepo struct {
+	DB *gorm.DB
+}
+
+func (r *PINRepo) GetWalletPIN(ctx context.Context, walletID int) (models.WalletPIN, error) {
+	var (
+		resp models.WalletPIN
+	)
+
+	err := r.DB.Where("wallet_id = ?", walletID).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *PINRepo) CreateWalletPIN(ctx context.Context, pin *models.WalletPIN) error {
+	return r.DB.Create(pin).Error
+}
+
+// UpdatePINHash stores a new PIN and clears the lockout and any pending
+// reset OTP.
+func (r *PINRepo) UpdatePINHash(ctx context.Context, walletID int, pinHash string, now time.Time) error {
+	result := r.DB.Exec("UPDATE wallet_pins SET pin_hash = ?, failed_attempts = 0, locked_until = NULL, reset_otp_hash = '', reset_otp_sent_at = NULL, reset_attempts = 0, updated_at = ? WHERE wallet_id = ?",
+		pinHash, now, walletID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ReservePINAttempt takes one of the maxAttempts tries a PIN allows before
+// the PIN is compared, so parallel wrong PINs can not run past the limit. The
+// try reaching maxAttempts locks the PIN until lockUntil, a lock over by now
+// starts the count over. It returns the number of the try taken, 0 when the
+// PIN is locked.
+func (r *PINRepo) ReservePINAttempt(ctx context.Context, walletID int, maxAttempts int, now time.Time, lockUntil time.Time) (int, error) {
+	var attempt int
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("UPDATE wallet_pins SET failed_attempts = CASE WHEN locked_until IS NULL THEN failed_attempts + 1 ELSE 1 END, locked_until = NULL WHERE wallet_id = ? AND ((locked_until IS NULL AND failed_attempts < ?) OR locked_until <= ?)",
+			walletID, maxAttempts, now)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		err := tx.Raw("SELECT failed_attempts FROM wallet_pins WHERE wallet_id = ?", walletID).Scan(&attempt).Error
+		if err != nil {
+			return err
+		}
+
+		if attempt < maxAttempts {
+			return nil
+		}
+
+		return tx.Exec("UPDATE wallet_pins SET locked_until = ? WHERE wallet_id = ?", lockUntil, walletID).Error
+	})
+
+	return attempt, err
+}
+
+// ResetPINFailures starts the count over after a right PIN, lifting the lock
+// its try may have placed.
+func (r *PINRepo) ResetPINFailures(ctx context.Context, walletID int) error {
+	return r.DB.Exec("UPDATE wallet_pins SET failed_attempts = 0, locked_until = NULL WHERE wallet_id = ? AND failed_attempts > 0", walletID).Error
+}
+
+func (r *PINRepo) SaveResetOTP(ctx context.Context, walletID int, otpHash string, now time.Time) error {
+	return r.DB.Exec("UPDATE wallet_pins SET reset_otp_hash = ?, reset_otp_sent_at = ?, reset_attempts = 0, updated_at = ? WHERE wallet_id = ?",
+		otpHash, now, now, walletID).Error
+}
+
+// ReserveResetAttempt takes one of the maxAttempts guesses a reset OTP allows
+// before the OTP is compared, so parallel guesses can not run past the limit.
+// It returns the number of the attempt taken, 0 when none is left.
+func (r *PINRepo) ReserveResetAttempt(ctx context.Context, walletID int, maxAttempts int) (int, error) {
+	var attempt int
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("UPDATE wallet_pins SET reset_attempts = reset_attempts + 1 WHERE wallet_id = ? AND reset_otp_hash <> '' AND reset_attempts < ?",
+			walletID, maxAttempts)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		return tx.Raw("SELECT reset_attempts FROM wallet_pins WHERE wallet_id = ?", walletID).Scan(&attempt).Error
+	})
+
+	return attempt, err
+}
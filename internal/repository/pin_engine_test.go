@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPINRepoEngine_ParallelResetGuesses(t *testing.T) {
+	db := openEngine(t)
+	walletRepo := &WalletRepo{DB: db}
+	r := &PINRepo{DB: db}
+
+	wallet := createEngineWallet(t, walletRepo, 1, 0)
+	assert.NoError(t, r.CreateWalletPIN(context.Background(), &models.WalletPIN{WalletID: wallet.ID, PINHash: "pin"}))
+
+	// no otp requested, nothing to guess at
+	attempt, err := r.ReserveResetAttempt(context.Background(), wallet.ID, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, attempt)
+
+	assert.NoError(t, r.SaveResetOTP(context.Background(), wallet.ID, "otp", time.Now()))
+
+	// every guess takes its attempt before comparing, so only three of them
+	// get to compare however many arrive at once
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		attempts []int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			attempt, err := r.ReserveResetAttempt(context.Background(), wallet.ID, 3)
+			assert.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if attempt > 0 {
+				attempts = append(attempts, attempt)
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Ints(attempts)
+	assert.Equal(t, []int{1, 2, 3}, attempts)
+
+	// a new otp starts the count over
+	assert.NoError(t, r.SaveResetOTP(context.Background(), wallet.ID, "otp", time.Now()))
+	attempt, err = r.ReserveResetAttempt(context.Background(), wallet.ID, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempt)
+}
+
+func TestPINRepoEngine_ParallelWrongPINs(t *testing.T) {
+	db := openEngine(t)
+	walletRepo := &WalletRepo{DB: db}
+	r := &PINRepo{DB: db}
+
+	wallet := createEngineWallet(t, walletRepo, 1, 0)
+	assert.NoError(t, r.CreateWalletPIN(context.Background(), &models.WalletPIN{WalletID: wallet.ID, PINHash: "pin"}))
+
+	now := time.Now()
+	lockUntil := now.Add(15 * time.Minute)
+
+	// every try is taken before the compare, so only three get to compare
+	// however many arrive at once, and the third locks the pin
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		attempts []int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			attempt, err := r.ReservePINAttempt(context.Background(), wallet.ID, 3, now, lockUntil)
+			assert.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if attempt > 0 {
+				attempts = append(attempts, attempt)
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Ints(attempts)
+	assert.Equal(t, []int{1, 2, 3}, attempts)
+
+	pin, err := r.GetWalletPIN(context.Background(), wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pin.FailedAttempts)
+	assert.WithinDuration(t, lockUntil, *pin.LockedUntil, time.Second)
+
+	// the lock over, the count starts again
+	attempt, err := r.ReservePINAttempt(context.Background(), wallet.ID, 3, lockUntil.Add(time.Second), lockUntil.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempt)
+
+	// a right pin clears the count
+	assert.NoError(t, r.ResetPINFailures(context.Background(), wallet.ID))
+	pin, err = r.GetWalletPIN(context.Background(), wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pin.FailedAttempts)
+	assert.Nil(t, pin.LockedUntil)
+}
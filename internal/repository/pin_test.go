@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestPINRepo_ReservePINAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	reserveQuery := regexp.QuoteMeta("UPDATE wallet_pins SET failed_attempts = CASE WHEN locked_until IS NULL THEN failed_attempts + 1 ELSE 1 END, locked_until = NULL WHERE wallet_id = ? AND ((locked_until IS NULL AND failed_attempts < ?) OR locked_until <= ?)")
+	countQuery := regexp.QuoteMeta("SELECT failed_attempts FROM wallet_pins WHERE wallet_id = ?")
+	lockQuery := regexp.QuoteMeta("UPDATE wallet_pins SET locked_until = ? WHERE wallet_id = ?")
+	now := time.Date(2024, 6, 1, 10, 15, 0, 0, time.UTC)
+	lockUntil := time.Date(2024, 6, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success counted",
+			want: 2,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(reserveQuery).WithArgs(1, 5, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(countQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"failed_attempts"}).AddRow(2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "success last attempt locks",
+			want: 5,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(reserveQuery).WithArgs(1, 5, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(countQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"failed_attempts"}).AddRow(5))
+				mock.ExpectExec(lockQuery).WithArgs(lockUntil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "success locked takes none",
+			want: 0,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(reserveQuery).WithArgs(1, 5, now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error database",
+			wantErr: assert.AnError,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(reserveQuery).WithArgs(1, 5, now).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &PINRepo{
+				DB: gormDB,
+			}
+			got, err := r.ReservePINAttempt(context.Background(), 1, 5, now, lockUntil)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPINRepo_UpdatePINHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+	updateQuery := regexp.QuoteMeta("UPDATE wallet_pins SET pin_hash = ?, failed_attempts = 0, locked_until = NULL, reset_otp_hash = '', reset_otp_sent_at = NULL, reset_attempts = 0, updated_at = ? WHERE wallet_id = ?")
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mock.ExpectExec(updateQuery).WithArgs("hash", now, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "error pin not set",
+			wantErr: gorm.ErrRecordNotFound,
+			mockFn: func() {
+				mock.ExpectExec(updateQuery).WithArgs("hash", now, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &PINRepo{
+				DB: gormDB,
+			}
+			err := r.UpdatePINHash(context.Background(), 1, "hash", now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
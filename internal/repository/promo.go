@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PromoRepo struct {
+	DB *gorm.DB
+}
+
+func (r *PromoRepo) CreateCampaign(ctx context.Context, campaign *models.Campaign) error {
+	return r.DB.Create(campaign).Error
+}
+
+func (r *PromoRepo) GetCampaigns(ctx context.Context, clientSource string, offset int, limit int) ([]models.Campaign, error) {
+	var (
+		resp []models.Campaign
+	)
+
+	sql := r.DB
+	if clientSource != "" {
+		sql = sql.Where("client_source = ?", clientSource)
+	}
+	err := sql.Limit(limit).Offset(offset).Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+func (r *PromoRepo) GetActiveCampaigns(ctx context.Context, clientSource string, now time.Time) ([]models.Campaign, error) {
+	var (
+		resp []models.Campaign
+	)
+
+	err := r.DB.Where("client_source = ? AND active = ? AND starts_at <= ? AND ends_at > ?", clientSource, true, now, now).
+		Order("id ASC").Find(&resp).Error
+
+	return resp, err
+}
+
+func (r *PromoRepo) DisableCampaign(ctx context.Context, campaignID int, now time.Time) error {
+	result := r.DB.Exec("UPDATE campaigns SET active = ?, updated_at = ? WHERE id = ? AND active = ?", false, now, campaignID, true)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrCampaignNotFound
+	}
+
+	return nil
+}
+
+// GrantPromo books promo credit on the wallet and records the grant it
+// expires with. The wallet is returned as it was before.
+func (r *PromoRepo) GrantPromo(ctx context.Context, grant *models.PromoGrant, credit *models.WalletTransaction) (models.Wallet, error) {
+	var wallet models.Wallet
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE", grant.WalletID).Scan(&wallet).Error
+		if err != nil {
+			return err
+		}
+
+		if wallet.ID == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := checkWalletMovement(wallet, grant.Amount); err != nil {
+			return err
+		}
+
+		err = tx.Exec("UPDATE wallets SET balance = balance + ?, promo_balance = promo_balance + ? WHERE id = ?", grant.Amount, grant.Amount, wallet.ID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(credit).Error
+		if err != nil {
+			return err
+		}
+
+		grant.TransactionID = credit.ID
+		return tx.Create(grant).Error
+	})
+
+	return wallet, err
+}
+
+func (r *PromoRepo) GetPromoGrants(ctx context.Context, walletID int, offset int, limit int) ([]models.PromoGrant, error) {
+	var (
+		resp []models.PromoGrant
+	)
+
+	err := r.DB.Where("wallet_id = ?", walletID).Limit(limit).Offset(offset).Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+func (r *PromoRepo) GetExpiredPromoGrants(ctx context.Context, now time.Time, limit int) ([]models.PromoGrant, error) {
+	var (
+		resp []models.PromoGrant
+	)
+
+	err := r.DB.Where("status = ? AND expires_at <= ?", constants.PromoGrantStatusActive, now).
+		Order("expires_at ASC, id ASC").Limit(limit).Find(&resp).Error
+
+	return resp, err
+}
+
+// ExpirePromoGrant closes the grant and takes back what is left of it. Debits
+// spend the grants expiring first, so the promo credit left beyond what the
+// grants expiring later cover belongs to this grant. The clawback row gets its
+// amount here, nothing is booked when the grant was spent.
+func (r *PromoRepo) ExpirePromoGrant(ctx context.Context, grant models.PromoGrant, clawback *models.WalletTransaction, now time.Time) (models.Wallet, error) {
+	var wallet models.Wallet
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE", grant.WalletID).Scan(&wallet).Error
+		if err != nil {
+			return err
+		}
+
+		var later float64
+		err = tx.Raw("SELECT COALESCE(SUM(amount), 0) FROM promo_grants WHERE wallet_id = ? AND status = ? AND (expires_at > ? OR (expires_at = ? AND id > ?))",
+			grant.WalletID, constants.PromoGrantStatusActive, grant.ExpiresAt, grant.ExpiresAt, grant.ID).Scan(&later).Error
+		if err != nil {
+			return err
+		}
+
+		left := math.Min(math.Max(wallet.PromoBalance-later, 0), grant.Amount)
+
+		result := tx.Exec("UPDATE promo_grants SET status = ?, clawed_back = ?, updated_at = ? WHERE id = ? AND status = ?",
+			constants.PromoGrantStatusExpired, left, now, grant.ID, constants.PromoGrantStatusActive)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return constants.ErrPromoGrantNotFound
+		}
+
+		if left <= 0 {
+			return nil
+		}
+
+		err = tx.Exec("UPDATE wallets SET balance = balance - ?, promo_balance = promo_balance - ? WHERE id = ?", left, left, wallet.ID).Error
+		if err != nil {
+			return err
+		}
+
+		clawback.Amount = left
+		return tx.Create(clawback).Error
+	})
+
+	return wallet, err
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func Test_splitSpend(t *testing.T) {
+	tests := []struct {
+		name    string
+		wallet  models.Wallet
+		amount  float64
+		fee     float64
+		want    float64
+		wantErr bool
+	}{
+		{
+			name:   "promo first spends promo before cash",
+			wallet: models.Wallet{Balance: 30000, PromoBalance: 10000},
+			amount: 25000,
+			fee:    500,
+			want:   10000,
+		},
+		{
+			name:   "promo first covered by promo only",
+			wallet: models.Wallet{Balance: 30000, PromoBalance: 10000},
+			amount: 4000,
+			want:   4000,
+		},
+		{
+			name:   "cash first spends promo for the rest",
+			wallet: models.Wallet{Balance: 30000, PromoBalance: 10000, SpendOrder: constants.SpendOrderCashFirst},
+			amount: 25000,
+			fee:    500,
+			want:   5500,
+		},
+		{
+			name:   "cash first leaves promo untouched",
+			wallet: models.Wallet{Balance: 30000, PromoBalance: 10000, SpendOrder: constants.SpendOrderCashFirst},
+			amount: 15000,
+			fee:    500,
+		},
+		{
+			name:   "held cash is not spendable",
+			wallet: models.Wallet{Balance: 30000, HeldBalance: 15000, PromoBalance: 10000, SpendOrder: constants.SpendOrderCashFirst},
+			amount: 10000,
+			want:   5000,
+		},
+		{
+			name:    "error fee is paid in cash",
+			wallet:  models.Wallet{Balance: 10000, PromoBalance: 10000},
+			amount:  5000,
+			fee:     500,
+			wantErr: true,
+		},
+		{
+			name:    "error not enough cash and promo",
+			wallet:  models.Wallet{Balance: 30000, PromoBalance: 10000, SpendOrder: constants.SpendOrderCashFirst},
+			amount:  35000,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitSpend(tt.wallet, tt.amount, tt.fee)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, constants.ErrInsufficientBalance)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPromoRepo_GrantPromo(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	lockQuery := regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")
+	walletColumns := []string{"id", "user_id", "balance", "held_balance", "promo_balance", "status"}
+	expiresAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		want    models.Wallet
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			want: models.Wallet{ID: 1, UserID: 7, Balance: 20000, PromoBalance: 5000, Status: constants.WalletStatusActive},
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 7, 20000, 0, 5000, constants.WalletStatusActive))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + ?, promo_balance = promo_balance + ? WHERE id = ?")).
+					WithArgs(1500.0, 1500.0, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(1, 1500.0, constants.TransactionTypeCredit, "CASHBACK-11", "", 11, constants.BalanceTypePromo, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(12, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `promo_grants`")).
+					WithArgs(1, 3, 1500.0, 12, 0.0, constants.PromoGrantStatusActive, expiresAt, "campaign", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(5, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error wallet closed",
+			want:    models.Wallet{ID: 1, UserID: 7, Status: constants.WalletStatusClosed},
+			wantErr: constants.ErrWalletClosed,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 7, 0, 0, 0, constants.WalletStatusClosed))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name:    "error wallet not found",
+			wantErr: gorm.ErrRecordNotFound,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns))
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &PromoRepo{
+				DB: gormDB,
+			}
+			grant := &models.PromoGrant{WalletID: 1, CampaignID: 3, Amount: 1500, Status: constants.PromoGrantStatusActive, ExpiresAt: expiresAt, GrantedBy: "campaign"}
+			credit := &models.WalletTransaction{WalletID: 1, Amount: 1500, WalletTransactionType: constants.TransactionTypeCredit, Reference: "CASHBACK-11", ParentID: 11, BalanceType: constants.BalanceTypePromo}
+			got, err := r.GrantPromo(context.Background(), grant, credit)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 12, grant.TransactionID)
+				assert.Equal(t, 5, grant.ID)
+			}
+			assert.Equal(t, tt.want, got)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPromoRepo_ExpirePromoGrant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	lockQuery := regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")
+	walletColumns := []string{"id", "user_id", "balance", "held_balance", "promo_balance", "status"}
+	laterQuery := regexp.QuoteMeta("SELECT COALESCE(SUM(amount), 0) FROM promo_grants WHERE wallet_id = ? AND status = ? AND (expires_at > ? OR (expires_at = ? AND id > ?))")
+	expireQuery := regexp.QuoteMeta("UPDATE promo_grants SET status = ?, clawed_back = ?, updated_at = ? WHERE id = ? AND status = ?")
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	grant := models.PromoGrant{ID: 5, WalletID: 1, Amount: 3000, TransactionID: 12, Status: constants.PromoGrantStatusActive, ExpiresAt: now}
+
+	tests := []struct {
+		name         string
+		wantClawback float64
+		wantErr      error
+		mockFn       func()
+	}{
+		{
+			name:         "success claws back what later grants do not cover",
+			wantClawback: 2000,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 7, 20000, 0, 6000, constants.WalletStatusActive))
+				mock.ExpectQuery(laterQuery).WithArgs(1, constants.PromoGrantStatusActive, now, now, 5).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(4000))
+				mock.ExpectExec(expireQuery).WithArgs(constants.PromoGrantStatusExpired, 2000.0, now, 5, constants.PromoGrantStatusActive).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance - ?, promo_balance = promo_balance - ? WHERE id = ?")).
+					WithArgs(2000.0, 2000.0, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(1, 2000.0, constants.TransactionTypeDebit, "PROMO-EXP-5", "", 12, constants.BalanceTypePromo, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(20, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "success grant already spent",
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 7, 20000, 0, 4000, constants.WalletStatusActive))
+				mock.ExpectQuery(laterQuery).WithArgs(1, constants.PromoGrantStatusActive, now, now, 5).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(4000))
+				mock.ExpectExec(expireQuery).WithArgs(constants.PromoGrantStatusExpired, 0.0, now, 5, constants.PromoGrantStatusActive).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error already expired",
+			wantErr: constants.ErrPromoGrantNotFound,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 7, 20000, 0, 6000, constants.WalletStatusActive))
+				mock.ExpectQuery(laterQuery).WithArgs(1, constants.PromoGrantStatusActive, now, now, 5).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+				mock.ExpectExec(expireQuery).WithArgs(constants.PromoGrantStatusExpired, 3000.0, now, 5, constants.PromoGrantStatusActive).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &PromoRepo{
+				DB: gormDB,
+			}
+			clawback := &models.WalletTransaction{WalletID: 1, WalletTransactionType: constants.TransactionTypeDebit, Reference: "PROMO-EXP-5", ParentID: 12, BalanceType: constants.BalanceTypePromo}
+			_, err := r.ExpirePromoGrant(context.Background(), grant, clawback, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantClawback, clawback.Amount)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
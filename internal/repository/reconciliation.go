@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ledgerAmountExpr turns a ledger row into its signed effect on the balance.
+// ADJUSTMENT rows already carry their sign.
+const ledgerAmountExpr = "CASE t.wallet_transaction_type WHEN 'CREDIT' THEN t.amount WHEN 'REFUND' THEN t.amount WHEN 'DEBIT' THEN -t.amount WHEN 'FEE' THEN -t.amount WHEN 'ADJUSTMENT' THEN t.amount ELSE 0 END"
+
+// ledgerBalanceExpr sums every ledger row of a wallet, the total balance
+// includes the promo credit. promoLedgerBalanceExpr sums the promo rows only.
+const (
+	ledgerBalanceExpr      = "COALESCE(SUM(" + ledgerAmountExpr + "), 0)"
+	promoLedgerBalanceExpr = "COALESCE(SUM(CASE WHEN t.balance_type = 'promo' THEN " + ledgerAmountExpr + " ELSE 0 END), 0)"
+)
+
+type ReconciliationRepo struct {
+	DB *gorm.DB
+}
+
+// GetLedgerSnapshot compares every wallet balance with the sum of its ledger,
+// and its promo balance with the sum of its promo rows, inside a single read
+// only transaction, so balances and ledger rows come from the same point in
+// time. Only the wallets that do not match are returned.
+func (r *ReconciliationRepo) GetLedgerSnapshot(ctx context.Context, walletIDs []int) (models.LedgerSnapshot, error) {
+	var (
+		resp models.LedgerSnapshot
+	)
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw("SELECT COALESCE(MAX(id), 0) FROM wallet_transactions").Scan(&resp.LastTransactionID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.Wallet{}).Count(&resp.WalletCount).Error
+		if err != nil {
+			return err
+		}
+
+		query := "SELECT w.id AS wallet_id, w.balance AS balance, " + ledgerBalanceExpr + " AS ledger_balance, " +
+			"COALESCE(w.promo_balance, 0) AS promo_balance, " + promoLedgerBalanceExpr + " AS promo_ledger_balance " +
+			"FROM wallets w LEFT JOIN wallet_transactions t ON t.wallet_id = w.id AND t.id <= ? "
+		args := []interface{}{resp.LastTransactionID}
+		if len(walletIDs) > 0 {
+			query += "WHERE w.id IN ? "
+			args = append(args, walletIDs)
+		}
+		query += "GROUP BY w.id, w.balance, w.promo_balance " +
+			"HAVING ABS(w.balance - " + ledgerBalanceExpr + ") >= ? " +
+			"OR ABS(COALESCE(w.promo_balance, 0) - " + promoLedgerBalanceExpr + ") >= ? " +
+			"ORDER BY w.id"
+		args = append(args, constants.ReconciliationTolerance, constants.ReconciliationTolerance)
+
+		return tx.Raw(query, args...).Scan(&resp.Mismatches).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+
+	return resp, err
+}
+
+// GetLastCleanTransactionID returns the ledger position of the latest
+// completed run that found the wallet consistent, or 0 if there is none.
+func (r *ReconciliationRepo) GetLastCleanTransactionID(ctx context.Context, walletID int) (int, error) {
+	var (
+		resp int
+	)
+
+	err := r.DB.Raw("SELECT COALESCE(MAX(r.last_transaction_id), 0) FROM reconciliation_runs r "+
+		"WHERE r.status = ? AND NOT EXISTS (SELECT 1 FROM reconciliation_mismatches m WHERE m.run_id = r.id AND m.wallet_id = ?)",
+		constants.ReconciliationStatusCompleted, walletID).Scan(&resp).Error
+
+	return resp, err
+}
+
+func (r *ReconciliationRepo) GetTransactionWindow(ctx context.Context, walletID int, afterID int, toID int) (models.TransactionWindow, error) {
+	var (
+		resp models.TransactionWindow
+	)
+
+	err := r.DB.Raw("SELECT COALESCE(MIN(id), 0) AS from_id, COALESCE(MAX(id), 0) AS to_id, MIN(created_at) AS from_time, MAX(created_at) AS to_time, COUNT(id) AS transaction_count "+
+		"FROM wallet_transactions WHERE wallet_id = ? AND id > ? AND id <= ?", walletID, afterID, toID).Scan(&resp).Error
+
+	return resp, err
+}
+
+func (r *ReconciliationRepo) CreateRun(ctx context.Context, run *models.ReconciliationRun) error {
+	return r.DB.Create(run).Error
+}
+
+func (r *ReconciliationRepo) UpdateRun(ctx context.Context, run *models.ReconciliationRun) error {
+	return r.DB.Save(run).Error
+}
+
+func (r *ReconciliationRepo) CreateMismatches(ctx context.Context, mismatches []models.ReconciliationMismatch) error {
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	return r.DB.Create(&mismatches).Error
+}
+
+// CreateSuspenseEntry keeps at most one open entry per wallet. When one is
+// already open the entry is filled with it and false is returned.
+func (r *ReconciliationRepo) CreateSuspenseEntry(ctx context.Context, entry *models.SuspenseEntry) (bool, error) {
+	created := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.SuspenseEntry
+		err := tx.Raw("SELECT * FROM suspense_entries WHERE wallet_id = ? AND status = ? LIMIT 1 FOR UPDATE",
+			entry.WalletID, constants.SuspenseStatusOpen).Scan(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		if existing.ID > 0 {
+			*entry = existing
+			return nil
+		}
+
+		created = true
+		return tx.Create(entry).Error
+	})
+
+	return created, err
+}
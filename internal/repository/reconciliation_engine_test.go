@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func createEngineLedgerRows(t *testing.T, db *gorm.DB, walletID int, rows ...models.WalletTransaction) {
+	t.Helper()
+
+	for i := range rows {
+		rows[i].WalletID = walletID
+		rows[i].Reference = fmt.Sprintf("seed-%d-%d", walletID, i)
+		assert.NoError(t, db.Create(&rows[i]).Error)
+	}
+}
+
+func TestReconciliationRepoEngine_GetLedgerSnapshot(t *testing.T) {
+	db := openEngine(t)
+	walletRepo := &WalletRepo{DB: db}
+	r := &ReconciliationRepo{DB: db}
+
+	// consistent with a debit and the fee charged on it
+	clean := createEngineWallet(t, walletRepo, 1, 140)
+	createEngineLedgerRows(t, db, clean.ID,
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeCredit, Amount: 200},
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeDebit, Amount: 50},
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeFee, Amount: 10},
+	)
+	// lost the ledger row of a 300 credit
+	lostRow := createEngineWallet(t, walletRepo, 2, 500)
+	createEngineLedgerRows(t, db, lostRow.ID,
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeCredit, Amount: 300},
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeDebit, Amount: 100},
+	)
+	// consistent through a refund and a negative adjustment
+	adjusted := createEngineWallet(t, walletRepo, 3, 80)
+	createEngineLedgerRows(t, db, adjusted.ID,
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeCredit, Amount: 100},
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeRefund, Amount: 10},
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeAdjustment, Amount: -30},
+	)
+	// balance bumped without any ledger row
+	noLedger := createEngineWallet(t, walletRepo, 4, 25)
+	// ledger rows whose balance change never landed
+	noBalance := createEngineWallet(t, walletRepo, 5, 0)
+	createEngineLedgerRows(t, db, noBalance.ID,
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeCredit, Amount: 40},
+	)
+
+	// consistent promo credit, granted and partly spent
+	promo := createEngineWallet(t, walletRepo, 6, 70)
+	assert.NoError(t, db.Exec("UPDATE wallets SET promo_balance = 20 WHERE id = ?", promo.ID).Error)
+	createEngineLedgerRows(t, db, promo.ID,
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeCredit, Amount: 50},
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeCredit, Amount: 30, BalanceType: constants.BalanceTypePromo},
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeDebit, Amount: 10},
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeDebit, Amount: 10, BalanceType: constants.BalanceTypePromo},
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeCredit, Amount: 10},
+	)
+	// the total matches but a cash credit was counted as promo credit
+	promoDrift := createEngineWallet(t, walletRepo, 7, 60)
+	assert.NoError(t, db.Exec("UPDATE wallets SET promo_balance = 40 WHERE id = ?", promoDrift.ID).Error)
+	createEngineLedgerRows(t, db, promoDrift.ID,
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeCredit, Amount: 30},
+		models.WalletTransaction{WalletTransactionType: constants.TransactionTypeCredit, Amount: 30, BalanceType: constants.BalanceTypePromo},
+	)
+
+	var lastTransactionID int
+	assert.NoError(t, db.Raw("SELECT MAX(id) FROM wallet_transactions").Scan(&lastTransactionID).Error)
+
+	tests := []struct {
+		name      string
+		walletIDs []int
+		want      models.LedgerSnapshot
+	}{
+		{
+			name: "every wallet",
+			want: models.LedgerSnapshot{
+				LastTransactionID: lastTransactionID,
+				WalletCount:       7,
+				Mismatches: []models.WalletLedgerBalance{
+					{WalletID: lostRow.ID, Balance: 500, LedgerBalance: 200},
+					{WalletID: noLedger.ID, Balance: 25, LedgerBalance: 0},
+					{WalletID: noBalance.ID, Balance: 0, LedgerBalance: 40},
+					{WalletID: promoDrift.ID, Balance: 60, LedgerBalance: 60, PromoBalance: 40, PromoLedgerBalance: 30},
+				},
+			},
+		},
+		{
+			name:      "only the wallets asked for",
+			walletIDs: []int{clean.ID, noLedger.ID},
+			want: models.LedgerSnapshot{
+				LastTransactionID: lastTransactionID,
+				WalletCount:       7,
+				Mismatches: []models.WalletLedgerBalance{
+					{WalletID: noLedger.ID, Balance: 25, LedgerBalance: 0},
+				},
+			},
+		},
+		{
+			name:      "consistent wallets only",
+			walletIDs: []int{clean.ID, adjusted.ID, promo.ID},
+			want: models.LedgerSnapshot{
+				LastTransactionID: lastTransactionID,
+				WalletCount:       7,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.GetLedgerSnapshot(context.Background(), tt.walletIDs)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestReconciliationRepo_GetLedgerSnapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		walletIDs []int
+		want      models.LedgerSnapshot
+		wantErr   bool
+		mockFn    func()
+	}{
+		{
+			name: "success",
+			want: models.LedgerSnapshot{
+				LastTransactionID: 6,
+				WalletCount:       4,
+				Mismatches: []models.WalletLedgerBalance{
+					{WalletID: 2, Balance: 500, LedgerBalance: 200},
+				},
+			},
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(id), 0) FROM wallet_transactions")).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(6))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `wallets`")).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT w.id AS wallet_id, w.balance AS balance, "+ledgerBalanceExpr+" AS ledger_balance, "+
+					"COALESCE(w.promo_balance, 0) AS promo_balance, "+promoLedgerBalanceExpr+" AS promo_ledger_balance "+
+					"FROM wallets w LEFT JOIN wallet_transactions t ON t.wallet_id = w.id AND t.id <= ? "+
+					"GROUP BY w.id, w.balance, w.promo_balance HAVING ABS(w.balance - "+ledgerBalanceExpr+") >= ? "+
+					"OR ABS(COALESCE(w.promo_balance, 0) - "+promoLedgerBalanceExpr+") >= ? ORDER BY w.id")).
+					WithArgs(6, 0.01, 0.01).
+					WillReturnRows(sqlmock.NewRows([]string{"wallet_id", "balance", "ledger_balance", "promo_balance", "promo_ledger_balance"}).AddRow(2, 500, 200, 0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:      "success recheck wallets",
+			walletIDs: []int{2, 4},
+			want: models.LedgerSnapshot{
+				LastTransactionID: 7,
+				WalletCount:       4,
+			},
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(id), 0) FROM wallet_transactions")).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(7))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `wallets`")).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+				mock.ExpectQuery(regexp.QuoteMeta("AND t.id <= ? WHERE w.id IN (?,?) GROUP BY w.id, w.balance, w.promo_balance")).
+					WithArgs(7, 2, 4, 0.01, 0.01).
+					WillReturnRows(sqlmock.NewRows([]string{"wallet_id", "balance", "ledger_balance"}))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(id), 0) FROM wallet_transactions")).WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &ReconciliationRepo{
+				DB: gormDB,
+			}
+			got, err := r.GetLedgerSnapshot(context.Background(), tt.walletIDs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReconciliationRepo.GetLedgerSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReconciliationRepo_GetTransactionWindow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM wallet_transactions WHERE wallet_id = ? AND id > ? AND id <= ?")).
+		WithArgs(2, 3, 6).
+		WillReturnRows(sqlmock.NewRows([]string{"from_id", "to_id", "from_time", "to_time", "transaction_count"}).AddRow(4, 5, nil, nil, 2))
+
+	r := &ReconciliationRepo{
+		DB: gormDB,
+	}
+	got, err := r.GetTransactionWindow(context.Background(), 2, 3, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TransactionWindow{FromID: 4, ToID: 5, TransactionCount: 2}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReconciliationRepo_CreateSuspenseEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		wantCreated bool
+		wantID      int
+		mockFn      func()
+	}{
+		{
+			name:        "success create",
+			wantCreated: true,
+			wantID:      3,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM suspense_entries WHERE wallet_id = ? AND status = ? LIMIT 1 FOR UPDATE")).
+					WithArgs(2, "open").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `suspense_entries`")).WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:        "success already open",
+			wantCreated: false,
+			wantID:      1,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM suspense_entries WHERE wallet_id = ? AND status = ? LIMIT 1 FOR UPDATE")).
+					WithArgs(2, "open").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "wallet_id", "amount", "status"}).AddRow(1, 2, 300, "open"))
+				mock.ExpectCommit()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &ReconciliationRepo{
+				DB: gormDB,
+			}
+			entry := &models.SuspenseEntry{WalletID: 2, Amount: 300, Status: "open"}
+			created, err := r.CreateSuspenseEntry(context.Background(), entry)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCreated, created)
+			assert.Equal(t, tt.wantID, entry.ID)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
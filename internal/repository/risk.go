@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RiskRepo struct {
+	DB *gorm.DB
+}
+
+func (r *RiskRepo) CountDebitsSince(ctx context.Context, walletID int, since time.Time) (int, error) {
+	var count int64
+
+	err := r.DB.Model(&models.WalletTransaction{}).
+		Where("wallet_id = ? AND wallet_transaction_type = ? AND created_at >= ?", walletID, constants.TransactionTypeDebit, since).
+		Count(&count).Error
+
+	return int(count), err
+}
+
+// CountLinksSince counts the clients linked to the wallet since the given
+// time, the confirmation is the last update of a linked row.
+func (r *RiskRepo) CountLinksSince(ctx context.Context, walletID int, since time.Time) (int, error) {
+	var count int64
+
+	err := r.DB.Model(&models.WalletLink{}).
+		Where("wallet_id = ? AND status = ? AND updated_at >= ?", walletID, "linked", since).
+		Count(&count).Error
+
+	return int(count), err
+}
+
+func (r *RiskRepo) CountClientReferencesSince(ctx context.Context, clientID string, since time.Time) (int, error) {
+	var count int64
+
+	err := r.DB.Model(&models.WalletTransaction{}).
+		Where("client_id = ? AND created_at >= ?", clientID, since).
+		Distinct("reference").
+		Count(&count).Error
+
+	return int(count), err
+}
+
+func (r *RiskRepo) GetDebitStats(ctx context.Context, walletID int, since time.Time) (models.RiskDebitStats, error) {
+	var (
+		resp models.RiskDebitStats
+	)
+
+	err := r.DB.Raw("SELECT COUNT(*) AS count, COALESCE(AVG(amount), 0) AS average FROM wallet_transactions "+
+		"WHERE wallet_id = ? AND wallet_transaction_type = ? AND created_at >= ?",
+		walletID, constants.TransactionTypeDebit, since).
+		Scan(&resp).Error
+
+	return resp, err
+}
+
+// RecordRiskDecision saves the decision and, for a review, the held movement
+// in one transaction. A reference already held fails with
+// gorm.ErrDuplicatedKey.
+func (r *RiskRepo) RecordRiskDecision(ctx context.Context, decision *models.RiskDecision, review *models.RiskReview) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(decision).Error
+		if err != nil {
+			return err
+		}
+
+		if review == nil {
+			return nil
+		}
+
+		review.DecisionID = decision.ID
+		return tx.Create(review).Error
+	})
+}
+
+func (r *RiskRepo) GetRiskReviews(ctx context.Context, param models.RiskReviewParam, offset int, limit int) ([]models.RiskReview, error) {
+	var (
+		resp []models.RiskReview
+	)
+
+	sql := r.DB
+	if param.Status != "" {
+		sql = sql.Where("status = ?", param.Status)
+	}
+	if param.WalletID != 0 {
+		sql = sql.Where("wallet_id = ?", param.WalletID)
+	}
+	err := sql.Limit(limit).Offset(offset).Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+func (r *RiskRepo) GetRiskReviewByID(ctx context.Context, reviewID int) (models.RiskReview, error) {
+	var (
+		resp models.RiskReview
+	)
+
+	err := r.DB.Where("id = ?", reviewID).First(&resp).Error
+
+	return resp, err
+}
+
+// DecideRiskReview moves a pending review to approved or rejected, two
+// operators deciding the same review cannot both win.
+func (r *RiskRepo) DecideRiskReview(ctx context.Context, reviewID int, status string, decidedBy string, note string, now time.Time) error {
+	result := r.DB.Exec("UPDATE risk_reviews SET status = ?, decided_by = ?, decision_note = ?, decided_at = ?, updated_at = ? WHERE id = ? AND status = ?",
+		status, decidedBy, note, now, now, reviewID, constants.RiskReviewStatusPending)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrRiskReviewNotPending
+	}
+
+	return nil
+}
+
+func (r *RiskRepo) UpdateRiskReviewStatus(ctx context.Context, reviewID int, fromStatus string, toStatus string, note string) error {
+	result := r.DB.Exec("UPDATE risk_reviews SET status = ?, decision_note = ?, updated_at = ? WHERE id = ? AND status = ?",
+		toStatus, note, time.Now(), reviewID, fromStatus)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrRiskReviewNotPending
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestRiskRepo_CountClientReferencesSince(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	since := time.Now().Add(-time.Minute)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(DISTINCT(`reference`)) FROM `wallet_transactions` WHERE client_id = ? AND created_at >= ?")).
+		WithArgs("fastcampus_ecommerce", since).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	r := &RiskRepo{
+		DB: gormDB,
+	}
+	got, err := r.CountClientReferencesSince(context.Background(), "fastcampus_ecommerce", since)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, got)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRiskRepo_RecordRiskDecision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		review  bool
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success allow",
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `risk_decisions`")).
+					WithArgs(constants.RiskOperationDebit, constants.TransactionTypeDebit, 1, 7, "", 100000.0, "REF-1", constants.RiskOutcomeAllow, "", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "success review",
+			review: true,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `risk_decisions`")).
+					WithArgs(constants.RiskOperationDebit, constants.TransactionTypeDebit, 1, 7, "", 100000.0, "REF-1", constants.RiskOutcomeAllow, "", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `risk_reviews`")).
+					WithArgs(3, constants.RiskOperationDebit, constants.TransactionTypeDebit, 1, 7, "", 100000.0, "REF-1", 0, 0, "", constants.RiskReviewStatusPending, "", "", nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(9, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error review rolls the decision back",
+			review:  true,
+			wantErr: true,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `risk_decisions`")).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `risk_reviews`")).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &RiskRepo{
+				DB: gormDB,
+			}
+			decision := &models.RiskDecision{
+				Operation:       constants.RiskOperationDebit,
+				TransactionType: constants.TransactionTypeDebit,
+				WalletID:        1,
+				UserID:          7,
+				Amount:          100000,
+				Reference:       "REF-1",
+				Outcome:         constants.RiskOutcomeAllow,
+			}
+			var review *models.RiskReview
+			if tt.review {
+				review = &models.RiskReview{
+					Operation:       constants.RiskOperationDebit,
+					TransactionType: constants.TransactionTypeDebit,
+					WalletID:        1,
+					UserID:          7,
+					Amount:          100000,
+					Reference:       "REF-1",
+					Status:          constants.RiskReviewStatusPending,
+				}
+			}
+			err := r.RecordRiskDecision(context.Background(), decision, review)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 3, decision.ID)
+				if review != nil {
+					assert.Equal(t, 3, review.DecisionID)
+					assert.Equal(t, 9, review.ID)
+				}
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRiskRepo_DecideRiskReview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+	decideQuery := regexp.QuoteMeta("UPDATE risk_reviews SET status = ?, decided_by = ?, decision_note = ?, decided_at = ?, updated_at = ? WHERE id = ? AND status = ?")
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mock.ExpectExec(decideQuery).WithArgs(constants.RiskReviewStatusApproved, "alice", "ok", now, now, 9, constants.RiskReviewStatusPending).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "error decided already",
+			wantErr: constants.ErrRiskReviewNotPending,
+			mockFn: func() {
+				mock.ExpectExec(decideQuery).WithArgs(constants.RiskReviewStatusApproved, "alice", "ok", now, now, 9, constants.RiskReviewStatusPending).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &RiskRepo{
+				DB: gormDB,
+			}
+			err := r.DecideRiskReview(context.Background(), 9, constants.RiskReviewStatusApproved, "alice", "ok", now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ScheduledPaymentRepo struct {
+	DB *gorm.DB
+}
+
+func (r *ScheduledPaymentRepo) CreateScheduledPayment(ctx context.Context, payment *models.ScheduledPayment) error {
+	return r.DB.Create(payment).Error
+}
+
+func (r *ScheduledPaymentRepo) GetScheduledPaymentByID(ctx context.Context, paymentID int) (models.ScheduledPayment, error) {
+	var (
+		resp models.ScheduledPayment
+	)
+
+	err := r.DB.Where("id = ?", paymentID).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *ScheduledPaymentRepo) GetScheduledPayments(ctx context.Context, walletID int, clientID string) ([]models.ScheduledPayment, error) {
+	var (
+		resp []models.ScheduledPayment
+	)
+
+	sql := r.DB
+	if walletID != 0 {
+		sql = sql.Where("wallet_id = ?", walletID)
+	}
+	if clientID != "" {
+		sql = sql.Where("client_id = ?", clientID)
+	}
+	err := sql.Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+func (r *ScheduledPaymentRepo) UpdateScheduledPaymentStatus(ctx context.Context, paymentID int, fromStatuses []string, toStatus string) error {
+	result := r.DB.Exec("UPDATE scheduled_payments SET status = ?, updated_at = ? WHERE id = ? AND status IN ?",
+		toStatus, time.Now(), paymentID, fromStatuses)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrInvalidScheduleChange
+	}
+
+	return nil
+}
+
+// ResumeScheduledPayment reactivates a paused schedule from the occurrence the
+// service picked. It is refused while a worker still holds the schedule, that
+// run would otherwise overwrite the new position.
+func (r *ScheduledPaymentRepo) ResumeScheduledPayment(ctx context.Context, payment *models.ScheduledPayment) error {
+	now := time.Now()
+	result := r.DB.Exec("UPDATE scheduled_payments SET status = ?, occurrence_count = ?, next_run_at = ?, retry_count = 0, updated_at = ? "+
+		"WHERE id = ? AND status = ? AND (locked_until IS NULL OR locked_until < ?)",
+		constants.ScheduleStatusActive, payment.OccurrenceCount, payment.NextRunAt, now,
+		payment.ID, constants.ScheduleStatusPaused, now)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrInvalidScheduleChange
+	}
+
+	return nil
+}
+
+func (r *ScheduledPaymentRepo) GetDueScheduledPayments(ctx context.Context, now time.Time, limit int) ([]models.ScheduledPayment, error) {
+	var (
+		resp []models.ScheduledPayment
+	)
+
+	err := r.DB.Where("status = ? AND next_run_at <= ? AND (locked_until IS NULL OR locked_until < ?)", constants.ScheduleStatusActive, now, now).
+		Order("next_run_at ASC").Limit(limit).Find(&resp).Error
+
+	return resp, err
+}
+
+// ClaimScheduledPayment leases a due schedule to the calling worker until the
+// given time. Only one of several workers racing on the same row gets true.
+func (r *ScheduledPaymentRepo) ClaimScheduledPayment(ctx context.Context, paymentID int, now time.Time, until time.Time) (bool, error) {
+	result := r.DB.Exec("UPDATE scheduled_payments SET locked_until = ? "+
+		"WHERE id = ? AND status = ? AND next_run_at <= ? AND (locked_until IS NULL OR locked_until < ?)",
+		until, paymentID, constants.ScheduleStatusActive, now, now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// SettleScheduledPaymentReview moves a schedule parked on a risk review past
+// the held occurrence. Only the schedule still parked on that occurrence is
+// moved, a repeated decision changes nothing.
+func (r *ScheduledPaymentRepo) SettleScheduledPaymentReview(ctx context.Context, payment *models.ScheduledPayment, occurrence int) error {
+	result := r.DB.Exec("UPDATE scheduled_payments SET occurrence_count = ?, paid_count = ?, retry_count = 0, next_run_at = ?, last_error = ?, status = ?, updated_at = ? "+
+		"WHERE id = ? AND status = ? AND occurrence_count = ?",
+		payment.OccurrenceCount, payment.PaidCount, payment.NextRunAt, payment.LastError, payment.Status, time.Now(),
+		payment.ID, constants.ScheduleStatusUnderReview, occurrence-1)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrInvalidScheduleChange
+	}
+
+	return nil
+}
+
+// UpdateScheduledPaymentRun stores the outcome of a run and releases the
+// lease. A schedule paused or cancelled while it ran keeps that status.
+func (r *ScheduledPaymentRepo) UpdateScheduledPaymentRun(ctx context.Context, payment *models.ScheduledPayment) error {
+	return r.DB.Exec("UPDATE scheduled_payments SET occurrence_count = ?, paid_count = ?, retry_count = ?, next_run_at = ?, last_run_at = ?, last_error = ?, "+
+		"status = CASE WHEN status = ? THEN ? ELSE status END, locked_until = NULL, updated_at = ? WHERE id = ?",
+		payment.OccurrenceCount, payment.PaidCount, payment.RetryCount, payment.NextRunAt, payment.LastRunAt, payment.LastError,
+		constants.ScheduleStatusActive, payment.Status, time.Now(), payment.ID).Error
+}
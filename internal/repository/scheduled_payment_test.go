@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestScheduledPaymentRepo_GetDueScheduledPayments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		want    []models.ScheduledPayment
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			want: []models.ScheduledPayment{
+				{ID: 3, WalletID: 1, ClientID: "fastcampus_ecommerce", Amount: 49000, Status: constants.ScheduleStatusActive, NextRunAt: now},
+			},
+			mockFn: func() {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `scheduled_payments` WHERE status = ? AND next_run_at <= ? AND (locked_until IS NULL OR locked_until < ?) ORDER BY next_run_at ASC LIMIT ?")).
+					WithArgs(constants.ScheduleStatusActive, now, now, 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "wallet_id", "client_id", "amount", "status", "next_run_at"}).
+						AddRow(3, 1, "fastcampus_ecommerce", 49000, constants.ScheduleStatusActive, now))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mockFn: func() {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `scheduled_payments`")).
+					WithArgs(constants.ScheduleStatusActive, now, now, 100).
+					WillReturnError(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &ScheduledPaymentRepo{
+				DB: gormDB,
+			}
+			got, err := r.GetDueScheduledPayments(context.Background(), now, 100)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestScheduledPaymentRepo_ClaimScheduledPayment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+	until := now.Add(5 * time.Minute)
+	query := regexp.QuoteMeta("UPDATE scheduled_payments SET locked_until = ? WHERE id = ? AND status = ? AND next_run_at <= ? AND (locked_until IS NULL OR locked_until < ?)")
+
+	tests := []struct {
+		name    string
+		want    bool
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success claimed",
+			want: true,
+			mockFn: func() {
+				mock.ExpectExec(query).WithArgs(until, 3, constants.ScheduleStatusActive, now, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "success taken by another worker",
+			want: false,
+			mockFn: func() {
+				mock.ExpectExec(query).WithArgs(until, 3, constants.ScheduleStatusActive, now, now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mockFn: func() {
+				mock.ExpectExec(query).WithArgs(until, 3, constants.ScheduleStatusActive, now, now).
+					WillReturnError(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &ScheduledPaymentRepo{
+				DB: gormDB,
+			}
+			got, err := r.ClaimScheduledPayment(context.Background(), 3, now, until)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestScheduledPaymentRepo_UpdateScheduledPaymentStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	query := regexp.QuoteMeta("UPDATE scheduled_payments SET status = ?, updated_at = ? WHERE id = ? AND status IN (?,?)")
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mock.ExpectExec(query).WithArgs(constants.ScheduleStatusCancelled, sqlmock.AnyArg(), 3, constants.ScheduleStatusActive, constants.ScheduleStatusPaused).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "error status already changed",
+			wantErr: constants.ErrInvalidScheduleChange,
+			mockFn: func() {
+				mock.ExpectExec(query).WithArgs(constants.ScheduleStatusCancelled, sqlmock.AnyArg(), 3, constants.ScheduleStatusActive, constants.ScheduleStatusPaused).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: assert.AnError,
+			mockFn: func() {
+				mock.ExpectExec(query).WithArgs(constants.ScheduleStatusCancelled, sqlmock.AnyArg(), 3, constants.ScheduleStatusActive, constants.ScheduleStatusPaused).
+					WillReturnError(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &ScheduledPaymentRepo{
+				DB: gormDB,
+			}
+			err := r.UpdateScheduledPaymentStatus(context.Background(), 3,
+				[]string{constants.ScheduleStatusActive, constants.ScheduleStatusPaused}, constants.ScheduleStatusCancelled)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestScheduledPaymentRepo_SettleScheduledPaymentReview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	query := regexp.QuoteMeta("UPDATE scheduled_payments SET occurrence_count = ?, paid_count = ?, retry_count = 0, next_run_at = ?, last_error = ?, status = ?, updated_at = ? " +
+		"WHERE id = ? AND status = ? AND occurrence_count = ?")
+	nextRunAt := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	payment := models.ScheduledPayment{ID: 3, OccurrenceCount: 3, PaidCount: 3, NextRunAt: nextRunAt, Status: constants.ScheduleStatusActive}
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mock.ExpectExec(query).WithArgs(3, 3, nextRunAt, "", constants.ScheduleStatusActive, sqlmock.AnyArg(), 3, constants.ScheduleStatusUnderReview, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:    "error settled already",
+			wantErr: constants.ErrInvalidScheduleChange,
+			mockFn: func() {
+				mock.ExpectExec(query).WithArgs(3, 3, nextRunAt, "", constants.ScheduleStatusActive, sqlmock.AnyArg(), 3, constants.ScheduleStatusUnderReview, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &ScheduledPaymentRepo{
+				DB: gormDB,
+			}
+			err := r.SettleScheduledPaymentReview(context.Background(), &payment, 3)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
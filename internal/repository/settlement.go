@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SettlementRepo struct {
+	DB *gorm.DB
+}
+
+func (r *SettlementRepo) GetSettlementTotals(ctx context.Context, clientID string, from time.Time, to time.Time) (models.SettlementTotals, error) {
+	var (
+		resp models.SettlementTotals
+	)
+
+	err := r.DB.Raw("SELECT "+
+		"COALESCE(SUM(CASE WHEN wallet_transaction_type = 'CREDIT' THEN amount ELSE 0 END), 0) AS gross_credit, "+
+		"COALESCE(SUM(CASE WHEN wallet_transaction_type = 'DEBIT' THEN amount ELSE 0 END), 0) AS debit, "+
+		"COALESCE(SUM(CASE WHEN wallet_transaction_type = 'REFUND' THEN amount ELSE 0 END), 0) AS refund, "+
+		"COALESCE(SUM(CASE WHEN wallet_transaction_type = 'FEE' THEN amount ELSE 0 END), 0) AS fee, "+
+		"COUNT(id) AS transaction_count "+
+		"FROM wallet_transactions WHERE client_id = ? AND created_at >= ? AND created_at < ?", clientID, from, to).Scan(&resp).Error
+
+	return resp, err
+}
+
+func (r *SettlementRepo) GetSettlementClientIDs(ctx context.Context, from time.Time, to time.Time) ([]string, error) {
+	var (
+		resp []string
+	)
+
+	err := r.DB.Model(&models.WalletTransaction{}).
+		Where("client_id <> '' AND created_at >= ? AND created_at < ?", from, to).
+		Distinct().Order("client_id").Pluck("client_id", &resp).Error
+
+	return resp, err
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestSettlementRepo_GetSettlementTotals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name    string
+		want    models.SettlementTotals
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			want: models.SettlementTotals{GrossCredit: 500, Debit: 120, Refund: 20, Fee: 2.5, TransactionCount: 6},
+			mockFn: func() {
+				mock.ExpectQuery(regexp.QuoteMeta("COUNT(id) AS transaction_count FROM wallet_transactions WHERE client_id = ? AND created_at >= ? AND created_at < ?")).
+					WithArgs("fastcampus_ecommerce", from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"gross_credit", "debit", "refund", "fee", "transaction_count"}).
+						AddRow(500, 120, 20, 2.5, 6))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mockFn: func() {
+				mock.ExpectQuery(regexp.QuoteMeta("FROM wallet_transactions WHERE client_id = ?")).
+					WithArgs("fastcampus_ecommerce", from, to).
+					WillReturnError(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &SettlementRepo{
+				DB: gormDB,
+			}
+			got, err := r.GetSettlementTotals(context.Background(), "fastcampus_ecommerce", from, to)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSettlementRepo_GetSettlementClientIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	tests := []struct {
+		name    string
+		want    []string
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			want: []string{"fastcampus_ecommerce", "fastcampus_travel"},
+			mockFn: func() {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT `client_id` FROM `wallet_transactions` WHERE client_id <> '' AND created_at >= ? AND created_at < ? ORDER BY client_id")).
+					WithArgs(from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"client_id"}).AddRow("fastcampus_ecommerce").AddRow("fastcampus_travel"))
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mockFn: func() {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT `client_id` FROM `wallet_transactions`")).
+					WithArgs(from, to).
+					WillReturnError(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &SettlementRepo{
+				DB: gormDB,
+			}
+			got, err := r.GetSettlementClientIDs(context.Background(), from, to)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TopUpRepo struct {
+	DB *gorm.DB
+}
+
+func (r *TopUpRepo) CreateTopUpIntent(ctx context.Context, intent *models.TopUpIntent) error {
+	return r.DB.Create(intent).Error
+}
+
+func (r *TopUpRepo) GetTopUpIntentByID(ctx context.Context, intentID int) (models.TopUpIntent, error) {
+	var (
+		resp models.TopUpIntent
+	)
+
+	err := r.DB.Where("id = ?", intentID).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *TopUpRepo) GetTopUpIntentByReference(ctx context.Context, reference string) (models.TopUpIntent, error) {
+	var (
+		resp models.TopUpIntent
+	)
+
+	err := r.DB.Where("reference = ?", reference).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *TopUpRepo) GetTopUpIntents(ctx context.Context, userID uint64, status string, offset int, limit int) ([]models.TopUpIntent, error) {
+	var (
+		resp []models.TopUpIntent
+	)
+
+	sql := r.DB.Where("user_id = ?", userID)
+	if status != "" {
+		sql = sql.Where("status = ?", status)
+	}
+	err := sql.Limit(limit).Offset(offset).Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+// UpdateTopUpIntentStatus fails or expires a pending intent. An intent only
+// expires once its expiry passed.
+func (r *TopUpRepo) UpdateTopUpIntentStatus(ctx context.Context, intentID int, toStatus string, reason string, now time.Time) error {
+	query := "UPDATE topup_intents SET status = ?, failure_reason = ?, updated_at = ? WHERE id = ? AND status = ?"
+	args := []interface{}{toStatus, reason, now, intentID, constants.TopUpStatusPending}
+	if toStatus == constants.TopUpStatusExpired {
+		query += " AND expires_at <= ?"
+		args = append(args, now)
+	}
+
+	result := r.DB.Exec(query, args...)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrTopUpNotPending
+	}
+
+	return nil
+}
+
+// CompleteTopUpIntent marks the intent paid and credits the wallet in one
+// transaction. The conditional update lets only one callback through, a
+// replayed one gets constants.ErrTopUpAlreadyPaid. A payment confirmed after
+// the intent failed or expired is still credited, the gateway holds the money.
+func (r *TopUpRepo) CompleteTopUpIntent(ctx context.Context, intentID int, walletTrx *models.WalletTransaction, now time.Time) (models.Wallet, error) {
+	var wallet models.Wallet
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("UPDATE topup_intents SET status = ?, paid_at = ?, updated_at = ? WHERE id = ? AND status <> ?",
+			constants.TopUpStatusPaid, now, now, intentID, constants.TopUpStatusPaid)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return constants.ErrTopUpAlreadyPaid
+		}
+
+		err := tx.Raw("SELECT id, user_id, balance, status, type FROM wallets WHERE id = ? FOR UPDATE", walletTrx.WalletID).Scan(&wallet).Error
+		if err != nil {
+			return err
+		}
+
+		if wallet.ID == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := checkWalletMovement(wallet, walletTrx.Amount); err != nil {
+			return err
+		}
+
+		err = tx.Exec("UPDATE wallets SET balance = balance + ? WHERE id = ?", walletTrx.Amount, wallet.ID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(walletTrx).Error
+	})
+
+	return wallet, err
+}
+
+func (r *TopUpRepo) GetExpiredTopUpIntents(ctx context.Context, now time.Time, limit int) ([]models.TopUpIntent, error) {
+	var (
+		resp []models.TopUpIntent
+	)
+
+	err := r.DB.Where("status = ? AND expires_at <= ?", constants.TopUpStatusPending, now).
+		Order("expires_at ASC").Limit(limit).Find(&resp).Error
+
+	return resp, err
+}
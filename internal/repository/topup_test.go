@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestTopUpRepo_CompleteTopUpIntent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+	updateQuery := regexp.QuoteMeta("UPDATE topup_intents SET status = ?, paid_at = ?, updated_at = ? WHERE id = ? AND status <> ?")
+	lockQuery := regexp.QuoteMeta("SELECT id, user_id, balance, status, type FROM wallets WHERE id = ? FOR UPDATE")
+	walletColumns := []string{"id", "user_id", "balance", "status"}
+
+	tests := []struct {
+		name    string
+		want    models.Wallet
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			want: models.Wallet{ID: 1, UserID: 10, Balance: 5000, Status: constants.WalletStatusActive},
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(updateQuery).
+					WithArgs(constants.TopUpStatusPaid, now, now, 7, constants.TopUpStatusPaid).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 10, 5000, constants.WalletStatusActive))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + ? WHERE id = ?")).WithArgs(100000.0, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(1, 100000.0, constants.TransactionTypeCredit, "TOP-1-1", "", 0, constants.BalanceTypeCash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(11, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error already paid",
+			wantErr: constants.ErrTopUpAlreadyPaid,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(updateQuery).
+					WithArgs(constants.TopUpStatusPaid, now, now, 7, constants.TopUpStatusPaid).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name:    "error wallet suspended",
+			want:    models.Wallet{ID: 1, UserID: 10, Balance: 5000, Status: constants.WalletStatusSuspended},
+			wantErr: constants.ErrWalletSuspended,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(updateQuery).
+					WithArgs(constants.TopUpStatusPaid, now, now, 7, constants.TopUpStatusPaid).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 10, 5000, constants.WalletStatusSuspended))
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &TopUpRepo{
+				DB: gormDB,
+			}
+			walletTrx := &models.WalletTransaction{WalletID: 1, Amount: 100000, WalletTransactionType: constants.TransactionTypeCredit, Reference: "TOP-1-1"}
+			got, err := r.CompleteTopUpIntent(context.Background(), 7, walletTrx, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 11, walletTrx.ID)
+			}
+			assert.Equal(t, tt.want, got)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTopUpRepo_UpdateTopUpIntentStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		toStatus string
+		reason   string
+		wantErr  error
+		mockFn   func()
+	}{
+		{
+			name:     "success failed",
+			toStatus: constants.TopUpStatusFailed,
+			reason:   "card declined",
+			mockFn: func() {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE topup_intents SET status = ?, failure_reason = ?, updated_at = ? WHERE id = ? AND status = ?")).
+					WithArgs(constants.TopUpStatusFailed, "card declined", now, 7, constants.TopUpStatusPending).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "success expired",
+			toStatus: constants.TopUpStatusExpired,
+			mockFn: func() {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE topup_intents SET status = ?, failure_reason = ?, updated_at = ? WHERE id = ? AND status = ? AND expires_at <= ?")).
+					WithArgs(constants.TopUpStatusExpired, "", now, 7, constants.TopUpStatusPending, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "error not pending",
+			toStatus: constants.TopUpStatusFailed,
+			wantErr:  constants.ErrTopUpNotPending,
+			mockFn: func() {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE topup_intents SET status = ?")).
+					WithArgs(constants.TopUpStatusFailed, "", now, 7, constants.TopUpStatusPending).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &TopUpRepo{
+				DB: gormDB,
+			}
+			err := r.UpdateTopUpIntentStatus(context.Background(), 7, tt.toStatus, tt.reason, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
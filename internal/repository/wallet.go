@@ -2,8 +2,13 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"ewallet-wallet/constants"
 	"ewallet-wallet/internal/models"
 	"fmt"
+	"math"
+	"slices"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,23 +21,36 @@ func (r *WalletRepo) CreateWallet(ctx context.Context, wallet *models.Wallet) er
 	return r.DB.Create(wallet).Error
 }
 
-func (r *WalletRepo) UpdateBalance(ctx context.Context, userID uint64, amount float64) (models.Wallet, error) {
+// UpdateBalance moves the balance of the user's wallet by amount and books
+// walletTrx for it in the same transaction, so the balance never moves
+// without its ledger row. The wallet is returned as it was before.
+func (r *WalletRepo) UpdateBalance(ctx context.Context, userID uint64, amount float64, walletTrx *models.WalletTransaction) (models.Wallet, error) {
 	var wallet models.Wallet
 	err := r.DB.Transaction(func(tx *gorm.DB) error {
-		err := tx.Raw("SELECT id, user_id, balance FROM wallets WHERE user_id = ? FOR UPDATE", userID).Scan(&wallet).Error
+		err := tx.Raw("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE user_id = ? FOR UPDATE", userID).Scan(&wallet).Error
 		if err != nil {
 			return err
 		}
 
-		if (wallet.Balance + amount) < 0 {
-			return fmt.Errorf("current balance is not enough to perform the transaction: %f - %f", wallet.Balance, amount)
+		if wallet.ID == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := checkWalletMovement(wallet, amount); err != nil {
+			return err
+		}
+
+		if err := checkAvailableBalance(wallet, amount); err != nil {
+			return err
 		}
 
 		err = tx.Exec("UPDATE wallets SET balance = balance + ? WHERE user_id = ?", amount, userID).Error
 		if err != nil {
 			return err
 		}
-		return nil
+
+		walletTrx.WalletID = wallet.ID
+		return tx.Create(walletTrx).Error
 	})
 
 	return wallet, err
@@ -67,7 +85,7 @@ func (r *WalletRepo) GetWalletHistory(ctx context.Context, walletID int, offset
 		resp []models.WalletTransaction
 	)
 
-	sql := r.DB
+	sql := r.DB.Where("wallet_id = ?", walletID)
 	if transactionType != "" {
 		sql = sql.Where("wallet_transaction_type = ?", transactionType)
 	}
@@ -106,24 +124,450 @@ func (r *WalletRepo) GetWalletByID(ctx context.Context, walletID int) (models.Wa
 	return resp, err
 }
 
-func (r *WalletRepo) UpdateBalanceByID(ctx context.Context, walletID int, amount float64) (models.Wallet, error) {
+// UpdateBalanceByID is UpdateBalance for a wallet known by its id.
+func (r *WalletRepo) UpdateBalanceByID(ctx context.Context, walletID int, amount float64, walletTrx *models.WalletTransaction) (models.Wallet, error) {
+	var wallet models.Wallet
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		wallet, err = moveBalance(tx, walletID, amount, walletTrx)
+		return err
+	})
+
+	return wallet, err
+}
+
+// moveBalance moves the balance of the locked wallet by amount and books
+// walletTrx with it, within the caller's transaction. The wallet is returned
+// as it was before.
+func moveBalance(tx *gorm.DB, walletID int, amount float64, walletTrx *models.WalletTransaction) (models.Wallet, error) {
+	var wallet models.Wallet
+	err := tx.Raw("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE", walletID).Scan(&wallet).Error
+	if err != nil {
+		return wallet, err
+	}
+
+	if wallet.ID == 0 {
+		return wallet, gorm.ErrRecordNotFound
+	}
+
+	if err := checkWalletMovement(wallet, amount); err != nil {
+		return wallet, err
+	}
+
+	if err := checkAvailableBalance(wallet, amount); err != nil {
+		return wallet, err
+	}
+
+	err = tx.Exec("UPDATE wallets SET balance = balance + ? WHERE id = ?", amount, walletID).Error
+	if err != nil {
+		return wallet, err
+	}
+
+	walletTrx.WalletID = wallet.ID
+	return wallet, tx.Create(walletTrx).Error
+}
+
+// DebitWithFee debits the wallet for a partner payment and its fee, takes the
+// promo credit it can spend in the wallet's spend order and books the fee, all
+// in one transaction. The wallet is returned as it was before, with the promo
+// credit spent.
+func (r *WalletRepo) DebitWithFee(ctx context.Context, debit *models.WalletTransaction, fee *models.FeePosting) (models.Wallet, float64, error) {
+	var (
+		wallet models.Wallet
+		promo  float64
+	)
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw("SELECT id, balance, held_balance, promo_balance, status, spend_order, type FROM wallets WHERE id = ? FOR UPDATE", debit.WalletID).Scan(&wallet).Error
+		if err != nil {
+			return err
+		}
+
+		if wallet.ID == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		total := debit.Amount + fee.Amount()
+		if err := checkWalletMovement(wallet, -total); err != nil {
+			return err
+		}
+
+		promo, err = splitSpend(wallet, debit.Amount, fee.Amount())
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("UPDATE wallets SET balance = balance - ?, promo_balance = promo_balance - ? WHERE id = ?", total, promo, wallet.ID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(debit).Error
+		if err != nil {
+			return err
+		}
+
+		err = bookPromoSpend(tx, debit, promo)
+		if err != nil {
+			return err
+		}
+
+		return bookFee(tx, debit, fee)
+	})
+
+	return wallet, promo, err
+}
+
+func (r *WalletRepo) UpdateSpendOrder(ctx context.Context, userID uint64, spendOrder string) error {
+	result := r.DB.Exec("UPDATE wallets SET spend_order = ? WHERE user_id = ?", spendOrder, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *WalletRepo) UpdateWalletStatus(ctx context.Context, walletID int, fromStatuses []string, history *models.WalletStatusHistory) (models.Wallet, error) {
 	var wallet models.Wallet
 	err := r.DB.Transaction(func(tx *gorm.DB) error {
-		err := tx.Raw("SELECT id, balance FROM wallets WHERE id = ? FOR UPDATE", walletID).Scan(&wallet).Error
+		err := tx.Raw("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE", walletID).Scan(&wallet).Error
 		if err != nil {
 			return err
 		}
 
-		if (wallet.Balance + amount) < 0 {
-			return fmt.Errorf("current balance is not enough to perform the transaction: %f - %f", wallet.Balance, amount)
+		if wallet.ID == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if wallet.Type == constants.WalletTypeSystem {
+			return constants.ErrSystemWallet
 		}
 
-		err = tx.Exec("UPDATE wallets SET balance = balance + ? WHERE id = ?", amount, walletID).Error
+		if wallet.Status == "" {
+			wallet.Status = constants.WalletStatusActive
+		}
+
+		if !slices.Contains(fromStatuses, wallet.Status) {
+			return fmt.Errorf("%w: %s -> %s", constants.ErrInvalidStatusChange, wallet.Status, history.ToStatus)
+		}
+
+		// the held part is owed to a withdrawal still in flight
+		if history.ToStatus == constants.WalletStatusClosed && wallet.HeldBalance > 0 {
+			return fmt.Errorf("%w: %f is held by withdrawals", constants.ErrInvalidStatusChange, wallet.HeldBalance)
+		}
+
+		// promo credit is not paid out, it is taken back before the wallet closes
+		if history.ToStatus == constants.WalletStatusClosed && wallet.PromoBalance > 0 {
+			clawback := &models.WalletTransaction{
+				WalletID:              wallet.ID,
+				Amount:                wallet.PromoBalance,
+				WalletTransactionType: constants.TransactionTypeDebit,
+				Reference:             fmt.Sprintf("PROMO-CLOSE-%d-%d", wallet.ID, time.Now().UnixNano()),
+				BalanceType:           constants.BalanceTypePromo,
+			}
+			if err := tx.Create(clawback).Error; err != nil {
+				return err
+			}
+
+			err = tx.Exec("UPDATE wallets SET balance = balance - promo_balance, promo_balance = 0 WHERE id = ?", walletID).Error
+			if err != nil {
+				return err
+			}
+			wallet.Balance -= wallet.PromoBalance
+			wallet.PromoBalance = 0
+		}
+
+		// a wallet can only be closed with a zero balance, so the remainder is paid out first
+		if history.ToStatus == constants.WalletStatusClosed && wallet.Balance > 0 {
+			payout := &models.WalletTransaction{
+				WalletID:              wallet.ID,
+				Amount:                wallet.Balance,
+				WalletTransactionType: constants.TransactionTypeDebit,
+				Reference:             history.PayoutRef,
+			}
+			if err := tx.Create(payout).Error; err != nil {
+				return err
+			}
+
+			err = tx.Exec("UPDATE wallets SET balance = 0 WHERE id = ?", walletID).Error
+			if err != nil {
+				return err
+			}
+			history.PayoutAmount = wallet.Balance
+			wallet.Balance = 0
+		} else {
+			history.PayoutRef = ""
+		}
+
+		err = tx.Exec("UPDATE wallets SET status = ? WHERE id = ?", history.ToStatus, walletID).Error
 		if err != nil {
 			return err
 		}
+
+		history.WalletID = wallet.ID
+		history.FromStatus = wallet.Status
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+
+		wallet.Status = history.ToStatus
 		return nil
 	})
 
 	return wallet, err
 }
+
+func (r *WalletRepo) GetWalletStatusHistory(ctx context.Context, walletID int) ([]models.WalletStatusHistory, error) {
+	var (
+		resp []models.WalletStatusHistory
+	)
+
+	err := r.DB.Where("wallet_id = ?", walletID).Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+// checkWalletMovement enforces the wallet lifecycle: frozen wallets accept
+// credits only, suspended and closed wallets accept no movement at all.
+// System wallets are moved by bookFee only, never by a request.
+func checkWalletMovement(wallet models.Wallet, amount float64) error {
+	if wallet.Type == constants.WalletTypeSystem {
+		return constants.ErrSystemWallet
+	}
+
+	switch wallet.Status {
+	case constants.WalletStatusFrozen:
+		if amount < 0 {
+			return constants.ErrWalletFrozen
+		}
+	case constants.WalletStatusSuspended:
+		return constants.ErrWalletSuspended
+	case constants.WalletStatusClosed:
+		return constants.ErrWalletClosed
+	}
+
+	return nil
+}
+
+// checkAvailableBalance makes sure a movement does not spend the balance held
+// for withdrawals, nor the promo credit that only partner debits can spend.
+func checkAvailableBalance(wallet models.Wallet, amount float64) error {
+	available := wallet.Balance - wallet.HeldBalance - wallet.PromoBalance
+	if (available + amount) < 0 {
+		return fmt.Errorf("%w: %f - %f", constants.ErrInsufficientBalance, available, amount)
+	}
+
+	return nil
+}
+
+// splitSpend returns the promo credit a partner debit of amount takes. The
+// fee is always paid in cash, the cash available covers the rest.
+func splitSpend(wallet models.Wallet, amount float64, fee float64) (float64, error) {
+	cash := wallet.Balance - wallet.HeldBalance - wallet.PromoBalance
+
+	var promo float64
+	if wallet.SpendOrder == constants.SpendOrderCashFirst {
+		promo = math.Max(amount-math.Max(cash-fee, 0), 0)
+	} else {
+		promo = math.Min(amount, wallet.PromoBalance)
+	}
+	// balances are stored with two decimals
+	promo = math.Round(promo*100) / 100
+
+	if promo > wallet.PromoBalance || cash-(amount-promo)-fee < 0 {
+		return 0, fmt.Errorf("%w: %f cash and %f promo - %f", constants.ErrInsufficientBalance, cash, wallet.PromoBalance, amount+fee)
+	}
+
+	return promo, nil
+}
+
+// bookPromoSpend moves the promo credit spent by principal into cash, a promo
+// DEBIT and a cash CREDIT linked to the principal, so the principal row keeps
+// the full amount. The balances are updated by the caller.
+func bookPromoSpend(tx *gorm.DB, principal *models.WalletTransaction, promo float64) error {
+	if promo <= 0 {
+		return nil
+	}
+
+	err := tx.Create(&models.WalletTransaction{
+		WalletID:              principal.WalletID,
+		Amount:                promo,
+		WalletTransactionType: constants.TransactionTypeDebit,
+		Reference:             fmt.Sprintf("PROMO-%d", principal.ID),
+		ParentID:              principal.ID,
+		BalanceType:           constants.BalanceTypePromo,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.WalletTransaction{
+		WalletID:              principal.WalletID,
+		Amount:                promo,
+		WalletTransactionType: constants.TransactionTypeCredit,
+		Reference:             fmt.Sprintf("PROMO-%d-CASH", principal.ID),
+		ParentID:              principal.ID,
+		BalanceType:           constants.BalanceTypeCash,
+	}).Error
+}
+
+// transferBalance moves amount between two wallets and books both ledger rows,
+// plus the fee paid by the sender when there is one, inside the caller's
+// transaction. Wallets are locked in id order so two opposite transfers can
+// not deadlock. The wallets are returned as they were before the transfer.
+func transferBalance(tx *gorm.DB, debit *models.WalletTransaction, credit *models.WalletTransaction, fee *models.FeePosting) (models.Wallet, models.Wallet, error) {
+	var (
+		wallets []models.Wallet
+		from    models.Wallet
+		to      models.Wallet
+	)
+
+	err := tx.Raw("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id IN ? ORDER BY id FOR UPDATE",
+		[]int{debit.WalletID, credit.WalletID}).Scan(&wallets).Error
+	if err != nil {
+		return from, to, err
+	}
+
+	for _, wallet := range wallets {
+		if wallet.ID == debit.WalletID {
+			from = wallet
+		}
+		if wallet.ID == credit.WalletID {
+			to = wallet
+		}
+	}
+
+	if from.ID == 0 || to.ID == 0 {
+		return from, to, gorm.ErrRecordNotFound
+	}
+
+	total := debit.Amount + fee.Amount()
+	if err := checkWalletMovement(from, -total); err != nil {
+		return from, to, err
+	}
+
+	if err := checkWalletMovement(to, credit.Amount); err != nil {
+		return from, to, err
+	}
+
+	if err := checkAvailableBalance(from, -total); err != nil {
+		return from, to, err
+	}
+
+	err = tx.Exec("UPDATE wallets SET balance = balance - ? WHERE id = ?", total, from.ID).Error
+	if err != nil {
+		return from, to, err
+	}
+
+	err = tx.Exec("UPDATE wallets SET balance = balance + ? WHERE id = ?", credit.Amount, to.ID).Error
+	if err != nil {
+		return from, to, err
+	}
+
+	err = tx.Create(debit).Error
+	if err != nil {
+		return from, to, err
+	}
+
+	err = tx.Create(credit).Error
+	if err != nil {
+		return from, to, err
+	}
+
+	return from, to, bookFee(tx, debit, fee)
+}
+
+// bookFee writes the fee rows of principal, referenced by its id, and credits
+// the revenue wallet.
+// The paying wallet is debited by the caller, together with the principal.
+// The revenue wallet is always locked last, after the wallets it is paid
+// from.
+// Every fee bearing movement updates the same revenue row, so they queue on
+// its lock until their transaction commits. bookFee runs last in each of
+// them and takes the lock by its last statement but one, so the row is only
+// held for the revenue ledger insert and the commit. If that ever bounds the
+// throughput, the revenue can be spread over several system wallets picked
+// by principal id.
+func bookFee(tx *gorm.DB, principal *models.WalletTransaction, fee *models.FeePosting) error {
+	if fee.Amount() == 0 {
+		return nil
+	}
+
+	fee.Fee.ParentID = principal.ID
+	fee.Fee.Reference = fmt.Sprintf("FEE-%d", principal.ID)
+	fee.Revenue.ParentID = principal.ID
+	fee.Revenue.Reference = fmt.Sprintf("FEE-%d-REV", principal.ID)
+
+	err := tx.Create(fee.Fee).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Exec("UPDATE wallets SET balance = balance + ? WHERE id = ?", fee.Revenue.Amount, fee.Revenue.WalletID).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(fee.Revenue).Error
+}
+
+func (r *WalletRepo) SearchWallets(ctx context.Context, userID uint64, email string, offset int, limit int) ([]models.Wallet, error) {
+	var (
+		resp []models.Wallet
+	)
+
+	sql := r.DB
+	if userID != 0 {
+		sql = sql.Where("user_id = ?", userID)
+	}
+	if email != "" {
+		sql = sql.Where("email = ?", email)
+	}
+	err := sql.Limit(limit).Offset(offset).Order("id ASC").Find(&resp).Error
+
+	return resp, err
+}
+
+// UpdateWalletEmail keeps the email of the wallet in step with the one the
+// UMS knows, the row is only written when it changed.
+func (r *WalletRepo) UpdateWalletEmail(ctx context.Context, userID uint64, email string) error {
+	return r.DB.Exec("UPDATE wallets SET email = ? WHERE user_id = ? AND (email IS NULL OR email <> ?)", email, userID, email).Error
+}
+
+func (r *WalletRepo) UpdateWalletLinkOTP(ctx context.Context, walletID int, clientSource string, otp string) error {
+	result := r.DB.Exec("UPDATE wallet_links SET otp = ? WHERE wallet_id = ? AND client_source = ? AND status = ?", otp, walletID, clientSource, "pending")
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrLinkNotPending
+	}
+
+	return nil
+}
+
+// GetStatementSnapshot reads the opening balance and the transactions of the
+// range inside one read only transaction, so no row booked while the statement
+// is built can land in one and not the other.
+func (r *WalletRepo) GetStatementSnapshot(ctx context.Context, walletID int, from time.Time, to time.Time) (models.StatementSnapshot, error) {
+	var (
+		resp models.StatementSnapshot
+	)
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw("SELECT COALESCE(SUM("+ledgerAmountExpr+"), 0) FROM wallet_transactions t WHERE t.wallet_id = ? AND t.created_at < ?",
+			walletID, from).Scan(&resp.OpeningBalance).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("wallet_id = ? AND created_at >= ? AND created_at < ?", walletID, from, to).
+			Order("created_at ASC, id ASC").Find(&resp.Transactions).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+
+	return resp, err
+}
@@ -0,0 +1,376 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func createEngineWallet(t *testing.T, r *WalletRepo, userID uint64, balance float64) models.Wallet {
+	t.Helper()
+
+	wallet := models.Wallet{UserID: userID, Balance: balance}
+	assert.NoError(t, r.CreateWallet(context.Background(), &wallet))
+	return wallet
+}
+
+func TestWalletRepoEngine_ParallelDebits(t *testing.T) {
+	r := &WalletRepo{DB: openEngine(t)}
+	wallet := createEngineWallet(t, r, 1, 100)
+
+	// the row lock makes every debit see the balance left by the one before,
+	// so exactly ten of them fit
+	var (
+		wg           sync.WaitGroup
+		mu           sync.Mutex
+		succeeded    int
+		insufficient int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := r.UpdateBalanceByID(context.Background(), wallet.ID, -10, &models.WalletTransaction{
+				Amount:                10,
+				WalletTransactionType: constants.TransactionTypeDebit,
+				Reference:             fmt.Sprintf("debit-%d", i),
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, constants.ErrInsufficientBalance):
+				insufficient++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, succeeded)
+	assert.Equal(t, 10, insufficient)
+
+	got, err := r.GetWalletByID(context.Background(), wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, got.Balance)
+}
+
+func TestWalletRepoEngine_BalanceMovesWithItsLedgerRow(t *testing.T) {
+	r := &WalletRepo{DB: openEngine(t)}
+	wallet := createEngineWallet(t, r, 1, 100)
+	assert.NoError(t, r.CreateWalletTrx(context.Background(), &models.WalletTransaction{
+		WalletID:              wallet.ID,
+		Amount:                100,
+		WalletTransactionType: constants.TransactionTypeCredit,
+		Reference:             "taken",
+	}))
+
+	// the ledger row fails on its reference, the balance stays where it was
+	_, err := r.UpdateBalanceByID(context.Background(), wallet.ID, -10, &models.WalletTransaction{
+		Amount:                -10,
+		WalletTransactionType: constants.TransactionTypeAdjustment,
+		Reference:             "taken",
+	})
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+	_, err = r.UpdateBalance(context.Background(), 1, 10, &models.WalletTransaction{
+		Amount:                10,
+		WalletTransactionType: constants.TransactionTypeCredit,
+		Reference:             "taken",
+	})
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+
+	got, err := r.GetWalletByID(context.Background(), wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, got.Balance)
+
+	walletTrx := &models.WalletTransaction{
+		Amount:                -10,
+		WalletTransactionType: constants.TransactionTypeAdjustment,
+		Reference:             "adjustment",
+	}
+	before, err := r.UpdateBalanceByID(context.Background(), wallet.ID, -10, walletTrx)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, before.Balance)
+	assert.Equal(t, wallet.ID, walletTrx.WalletID)
+
+	got, err = r.GetWalletByID(context.Background(), wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 90.0, got.Balance)
+
+	_, err = r.UpdateBalanceByID(context.Background(), wallet.ID+100, 10, &models.WalletTransaction{Reference: "unknown"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestWalletRepoEngine_SystemWalletRefusesRequests(t *testing.T) {
+	r := &WalletRepo{DB: openEngine(t)}
+	revenue := models.Wallet{UserID: constants.FeeRevenueUserID, Type: constants.WalletTypeSystem}
+	assert.NoError(t, r.CreateWallet(context.Background(), &revenue))
+	payer := createEngineWallet(t, r, 1, 100)
+
+	_, err := r.UpdateBalanceByID(context.Background(), revenue.ID, 50, &models.WalletTransaction{
+		Amount:                50,
+		WalletTransactionType: constants.TransactionTypeAdjustment,
+		Reference:             "adjust-revenue",
+	})
+	assert.ErrorIs(t, err, constants.ErrSystemWallet)
+
+	_, err = r.UpdateBalance(context.Background(), constants.FeeRevenueUserID, -10, &models.WalletTransaction{
+		Amount:                10,
+		WalletTransactionType: constants.TransactionTypeDebit,
+		Reference:             "debit-revenue",
+	})
+	assert.ErrorIs(t, err, constants.ErrSystemWallet)
+
+	_, err = r.UpdateWalletStatus(context.Background(), revenue.ID, []string{constants.WalletStatusActive}, &models.WalletStatusHistory{
+		WalletID:   revenue.ID,
+		FromStatus: constants.WalletStatusActive,
+		ToStatus:   constants.WalletStatusFrozen,
+	})
+	assert.ErrorIs(t, err, constants.ErrSystemWallet)
+
+	// the fee postings still credit it
+	_, _, err = r.DebitWithFee(context.Background(), &models.WalletTransaction{
+		WalletID:              payer.ID,
+		Amount:                20,
+		WalletTransactionType: constants.TransactionTypeDebit,
+		Reference:             "debit-with-fee",
+	}, &models.FeePosting{
+		Fee:     &models.WalletTransaction{WalletID: payer.ID, Amount: 2, WalletTransactionType: constants.TransactionTypeFee},
+		Revenue: &models.WalletTransaction{WalletID: revenue.ID, Amount: 2, WalletTransactionType: constants.TransactionTypeCredit},
+	})
+	assert.NoError(t, err)
+
+	got, err := r.GetWalletByID(context.Background(), revenue.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), got.Balance)
+	assert.Equal(t, constants.WalletTypeSystem, got.Type)
+
+	got, err = r.GetWalletByID(context.Background(), payer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(78), got.Balance)
+	assert.Equal(t, constants.WalletTypeUser, got.Type)
+}
+
+func TestWalletRepoEngine_OppositeTransfers(t *testing.T) {
+	r := &WalletRepo{DB: openEngine(t)}
+	a := createEngineWallet(t, r, 1, 100)
+	b := createEngineWallet(t, r, 2, 100)
+
+	// the wallets are locked in id order, so transfers going both ways at
+	// once queue up instead of deadlocking
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := r.DB.Transaction(func(tx *gorm.DB) error {
+				_, _, err := transferBalance(tx,
+					&models.WalletTransaction{WalletID: from.ID, Amount: 5, WalletTransactionType: constants.TransactionTypeDebit, Reference: fmt.Sprintf("transfer-%d-out", i)},
+					&models.WalletTransaction{WalletID: to.ID, Amount: 5, WalletTransactionType: constants.TransactionTypeCredit, Reference: fmt.Sprintf("transfer-%d-in", i)},
+					nil)
+				return err
+			})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	gotA, err := r.GetWalletByID(context.Background(), a.ID)
+	assert.NoError(t, err)
+	gotB, err := r.GetWalletByID(context.Background(), b.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, gotA.Balance)
+	assert.Equal(t, 100.0, gotB.Balance)
+}
+
+func TestWalletRepoEngine_StatementSnapshot(t *testing.T) {
+	r := &WalletRepo{DB: openEngine(t)}
+	wallet := createEngineWallet(t, r, 1, 0)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	rows := []models.WalletTransaction{
+		{Amount: 100, WalletTransactionType: constants.TransactionTypeCredit, CreatedAt: from.Add(-time.Hour)},
+		{Amount: 30, WalletTransactionType: constants.TransactionTypeDebit, CreatedAt: from.Add(-time.Minute)},
+		{Amount: 2, WalletTransactionType: constants.TransactionTypeFee, CreatedAt: from.Add(-time.Second)},
+		{Amount: 50, WalletTransactionType: constants.TransactionTypeCredit, CreatedAt: from.Add(time.Hour)},
+		{Amount: 10, WalletTransactionType: constants.TransactionTypeDebit, CreatedAt: to},
+	}
+	for i := range rows {
+		rows[i].WalletID = wallet.ID
+		rows[i].Reference = fmt.Sprintf("statement-%d", i)
+		assert.NoError(t, r.CreateWalletTrx(context.Background(), &rows[i]))
+	}
+
+	got, err := r.GetStatementSnapshot(context.Background(), wallet.ID, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, 68.0, got.OpeningBalance)
+	if assert.Len(t, got.Transactions, 1) {
+		assert.Equal(t, "statement-3", got.Transactions[0].Reference)
+	}
+}
+
+func TestWalletRepoEngine_UniqueReference(t *testing.T) {
+	r := &WalletRepo{DB: openEngine(t)}
+	wallet := createEngineWallet(t, r, 1, 0)
+
+	// the same reference sent ten times at once is booked once
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		created    int
+		duplicates int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := r.CreateWalletTrx(context.Background(), &models.WalletTransaction{
+				WalletID:              wallet.ID,
+				Amount:                10,
+				WalletTransactionType: constants.TransactionTypeCredit,
+				Reference:             "same-reference",
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				created++
+			case errors.Is(err, gorm.ErrDuplicatedKey):
+				duplicates++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, created)
+	assert.Equal(t, 9, duplicates)
+
+	got, err := r.GetWalletTransactionByReference(context.Background(), "same-reference")
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.ID, got.WalletID)
+
+	_, err = r.GetWalletTransactionByReference(context.Background(), "other-reference")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestWalletRepoEngine_HistoryScoping(t *testing.T) {
+	r := &WalletRepo{DB: openEngine(t)}
+	a := createEngineWallet(t, r, 1, 0)
+	b := createEngineWallet(t, r, 2, 0)
+
+	rows := []models.WalletTransaction{
+		{WalletID: a.ID, WalletTransactionType: constants.TransactionTypeCredit},
+		{WalletID: b.ID, WalletTransactionType: constants.TransactionTypeCredit},
+		{WalletID: a.ID, WalletTransactionType: constants.TransactionTypeDebit},
+		{WalletID: a.ID, WalletTransactionType: constants.TransactionTypeCredit},
+		{WalletID: b.ID, WalletTransactionType: constants.TransactionTypeDebit},
+		{WalletID: a.ID, WalletTransactionType: constants.TransactionTypeCredit},
+	}
+	for i := range rows {
+		rows[i].Amount = float64(i + 1)
+		rows[i].Reference = fmt.Sprintf("history-%d", i)
+		assert.NoError(t, r.CreateWalletTrx(context.Background(), &rows[i]))
+	}
+
+	references := func(trxs []models.WalletTransaction) []string {
+		resp := []string{}
+		for _, trx := range trxs {
+			resp = append(resp, trx.Reference)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		name            string
+		walletID        int
+		offset          int
+		limit           int
+		transactionType string
+		want            []string
+	}{
+		{
+			name:     "only the rows of the wallet, newest first",
+			walletID: a.ID,
+			limit:    10,
+			want:     []string{"history-5", "history-3", "history-2", "history-0"},
+		},
+		{
+			name:     "other wallet",
+			walletID: b.ID,
+			limit:    10,
+			want:     []string{"history-4", "history-1"},
+		},
+		{
+			name:            "type filter",
+			walletID:        a.ID,
+			limit:           10,
+			transactionType: constants.TransactionTypeCredit,
+			want:            []string{"history-5", "history-3", "history-0"},
+		},
+		{
+			name:     "page",
+			walletID: a.ID,
+			offset:   1,
+			limit:    2,
+			want:     []string{"history-3", "history-2"},
+		},
+		{
+			name:     "unknown wallet",
+			walletID: b.ID + 100,
+			limit:    10,
+			want:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.GetWalletHistory(context.Background(), tt.walletID, tt.offset, tt.limit, tt.transactionType)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, references(got))
+		})
+	}
+}
+
+func TestWalletRepoEngine_UpdateWalletEmail(t *testing.T) {
+	r := &WalletRepo{DB: openEngine(t)}
+	wallet := createEngineWallet(t, r, 1, 0)
+	createEngineWallet(t, r, 2, 0)
+
+	got, err := r.SearchWallets(context.Background(), 0, "alice@example.com", 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	// the email arrives with the first validated token and follows changes
+	for _, email := range []string{"alice@example.com", "alice@example.com", "alice@example.org"} {
+		assert.NoError(t, r.UpdateWalletEmail(context.Background(), 1, email))
+
+		got, err = r.SearchWallets(context.Background(), 0, email, 0, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, wallet.ID, got[0].ID)
+		}
+	}
+
+	got, err = r.SearchWallets(context.Background(), 0, "alice@example.com", 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
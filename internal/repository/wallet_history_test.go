@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// the history of one wallet never includes the rows of another
+func TestWalletRepo_GetWalletHistoryScopedToWallet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	tests := []struct {
+		name            string
+		walletID        int
+		transactionType string
+		query           string
+		args            []driver.Value
+	}{
+		{
+			name:     "all types",
+			walletID: 7,
+			query:    "SELECT * FROM `wallet_transactions` WHERE wallet_id = ? ORDER BY id DESC LIMIT ? OFFSET ?",
+			args:     []driver.Value{7, 10, 20},
+		},
+		{
+			name:            "one type",
+			walletID:        7,
+			transactionType: "CREDIT",
+			query:           "SELECT * FROM `wallet_transactions` WHERE wallet_id = ? AND wallet_transaction_type = ? ORDER BY id DESC LIMIT ? OFFSET ?",
+			args:            []driver.Value{7, "CREDIT", 10, 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(tt.query)).WithArgs(tt.args...).
+				WillReturnRows(mock.NewRows([]string{"id", "wallet_id", "amount", "wallet_transaction_type", "reference", "created_at", "updated_at"}).
+					AddRow(9, tt.walletID, 1000, "CREDIT", "reference", now, now))
+
+			r := &WalletRepo{DB: gormDB}
+			got, err := r.GetWalletHistory(context.Background(), tt.walletID, 20, 10, tt.transactionType)
+			assert.NoError(t, err)
+			if assert.Len(t, got, 1) {
+				assert.Equal(t, tt.walletID, got[0].WalletID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
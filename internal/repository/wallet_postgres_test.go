@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// TestWalletRepo_Postgres runs the raw queries of WalletRepo through the
+// postgres dialector, they have to come out with $n placeholders and keep
+// the row locks the balance checks rely on.
+func TestWalletRepo_Postgres(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+		run     func(r *WalletRepo) error
+	}{
+		{
+			name: "update balance",
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE user_id = $1 FOR UPDATE")).
+					WithArgs(7).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance"}).AddRow(1, 7, 100))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + $1 WHERE user_id = $2")).
+					WithArgs(-40.0, 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "wallet_transactions" ("wallet_id","amount","wallet_transaction_type","reference","client_id","parent_id","balance_type","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "id"`)).
+					WithArgs(1, 40.0, constants.TransactionTypeDebit, "reference", "", 0, "cash", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+				mock.ExpectCommit()
+			},
+			run: func(r *WalletRepo) error {
+				_, err := r.UpdateBalance(context.Background(), 7, -40, &models.WalletTransaction{
+					Amount:                40,
+					WalletTransactionType: constants.TransactionTypeDebit,
+					Reference:             "reference",
+				})
+				return err
+			},
+		},
+		{
+			name:    "update balance by id insufficient balance",
+			wantErr: constants.ErrInsufficientBalance,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = $1 FOR UPDATE")).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held_balance"}).AddRow(1, 100, 80))
+				mock.ExpectRollback()
+			},
+			run: func(r *WalletRepo) error {
+				_, err := r.UpdateBalanceByID(context.Background(), 1, -40, &models.WalletTransaction{})
+				return err
+			},
+		},
+		{
+			name: "debit with fee",
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, spend_order, type FROM wallets WHERE id = $1 FOR UPDATE")).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "spend_order"}).AddRow(1, 100, constants.SpendOrderCashFirst))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance - $1, promo_balance = promo_balance - $2 WHERE id = $3")).
+					WithArgs(40.0, 0.0, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "wallet_transactions" ("wallet_id","amount","wallet_transaction_type","reference","client_id","parent_id","balance_type","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "id"`)).
+					WithArgs(1, 40.0, constants.TransactionTypeDebit, "reference", "", 0, "cash", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+				mock.ExpectCommit()
+			},
+			run: func(r *WalletRepo) error {
+				_, _, err := r.DebitWithFee(context.Background(), &models.WalletTransaction{
+					WalletID:              1,
+					Amount:                40,
+					WalletTransactionType: constants.TransactionTypeDebit,
+					Reference:             "reference",
+					BalanceType:           "cash",
+				}, nil)
+				return err
+			},
+		},
+		{
+			name: "transfer locks both wallets in id order",
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id IN ($1,$2) ORDER BY id FOR UPDATE")).
+					WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 0).AddRow(2, 100))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance - $1 WHERE id = $2")).
+					WithArgs(30.0, 2).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantErr: assert.AnError,
+			run: func(r *WalletRepo) error {
+				return r.DB.Transaction(func(tx *gorm.DB) error {
+					_, _, err := transferBalance(tx,
+						&models.WalletTransaction{WalletID: 2, Amount: 30},
+						&models.WalletTransaction{WalletID: 1, Amount: 30}, nil)
+					return err
+				})
+			},
+		},
+		{
+			name: "wallet history",
+			mockFn: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "wallet_transactions" WHERE wallet_id = $1 AND wallet_transaction_type = $2 ORDER BY id DESC LIMIT $3 OFFSET $4`)).
+					WithArgs(1, constants.TransactionTypeCredit, 10, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "wallet_id", "created_at"}).AddRow(3, 1, now))
+			},
+			run: func(r *WalletRepo) error {
+				_, err := r.GetWalletHistory(context.Background(), 1, 10, 10, constants.TransactionTypeCredit)
+				return err
+			},
+		},
+		{
+			name: "statement snapshot",
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM("+ledgerAmountExpr+"), 0) FROM wallet_transactions t WHERE t.wallet_id = $1 AND t.created_at < $2")).
+					WithArgs(1, from).
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(150))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "wallet_transactions" WHERE wallet_id = $1 AND created_at >= $2 AND created_at < $3 ORDER BY created_at ASC, id ASC`)).
+					WithArgs(1, from, now).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectCommit()
+			},
+			run: func(r *WalletRepo) error {
+				_, err := r.GetStatementSnapshot(context.Background(), 1, from, now)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &WalletRepo{
+				DB: gormDB,
+			}
+			err := tt.run(r)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
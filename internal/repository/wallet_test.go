@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"ewallet-wallet/constants"
 	"ewallet-wallet/internal/models"
 	"reflect"
 	"regexp"
@@ -14,6 +15,8 @@ import (
 	"gorm.io/gorm"
 )
 
+const ledgerInsertQuery = "INSERT INTO `wallet_transactions` (`wallet_id`,`amount`,`wallet_transaction_type`,`reference`,`client_id`,`parent_id`,`balance_type`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?)"
+
 func TestWalletRepo_CreateWallet(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -48,9 +51,16 @@ func TestWalletRepo_CreateWallet(t *testing.T) {
 			},
 			mockFn: func(args args) {
 				mock.ExpectBegin()
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallets` (`user_id`,`balance`,`created_at`,`updated_at`) VALUES (?,?,?,?)")).WithArgs(
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallets` (`user_id`,`email`,`balance`,`held_balance`,`status`,`tier`,`promo_balance`,`spend_order`,`type`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).WithArgs(
 					args.wallet.UserID,
+					args.wallet.Email,
 					args.wallet.Balance,
+					args.wallet.HeldBalance,
+					"active",
+					"basic",
+					args.wallet.PromoBalance,
+					constants.SpendOrderPromoFirst,
+					constants.WalletTypeUser,
 					sqlmock.AnyArg(),
 					sqlmock.AnyArg(),
 				).WillReturnResult(sqlmock.NewResult(1, 1))
@@ -69,9 +79,16 @@ func TestWalletRepo_CreateWallet(t *testing.T) {
 			},
 			mockFn: func(args args) {
 				mock.ExpectBegin()
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallets` (`user_id`,`balance`,`created_at`,`updated_at`) VALUES (?,?,?,?)")).WithArgs(
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallets` (`user_id`,`email`,`balance`,`held_balance`,`status`,`tier`,`promo_balance`,`spend_order`,`type`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).WithArgs(
 					args.wallet.UserID,
+					args.wallet.Email,
 					args.wallet.Balance,
+					args.wallet.HeldBalance,
+					"active",
+					"basic",
+					args.wallet.PromoBalance,
+					constants.SpendOrderPromoFirst,
+					constants.WalletTypeUser,
 					sqlmock.AnyArg(),
 					sqlmock.AnyArg(),
 				).WillReturnError(assert.AnError)
@@ -133,7 +150,7 @@ func TestWalletRepo_UpdateBalance(t *testing.T) {
 			mockFn: func(args args) {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance FROM wallets WHERE user_id = ? FOR UPDATE")).WithArgs(
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE user_id = ? FOR UPDATE")).WithArgs(
 					args.userID,
 				).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance"}).AddRow(1, 1, 100000))
 
@@ -142,6 +159,18 @@ func TestWalletRepo_UpdateBalance(t *testing.T) {
 					args.userID,
 				).WillReturnResult(sqlmock.NewResult(1, 1))
 
+				mock.ExpectExec(regexp.QuoteMeta(ledgerInsertQuery)).WithArgs(
+					1,
+					args.amount,
+					constants.TransactionTypeAdjustment,
+					"reference",
+					"",
+					0,
+					constants.BalanceTypeCash,
+					sqlmock.AnyArg(),
+					sqlmock.AnyArg(),
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
 				mock.ExpectCommit()
 			},
 		},
@@ -157,7 +186,7 @@ func TestWalletRepo_UpdateBalance(t *testing.T) {
 			mockFn: func(args args) {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance FROM wallets WHERE user_id = ? FOR UPDATE")).WithArgs(
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE user_id = ? FOR UPDATE")).WithArgs(
 					args.userID,
 				).WillReturnError(assert.AnError)
 
@@ -180,7 +209,7 @@ func TestWalletRepo_UpdateBalance(t *testing.T) {
 			mockFn: func(args args) {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance FROM wallets WHERE user_id = ? FOR UPDATE")).WithArgs(
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE user_id = ? FOR UPDATE")).WithArgs(
 					args.userID,
 				).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance"}).AddRow(1, 1, 100000))
 
@@ -208,7 +237,7 @@ func TestWalletRepo_UpdateBalance(t *testing.T) {
 			mockFn: func(args args) {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance FROM wallets WHERE user_id = ? FOR UPDATE")).WithArgs(
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE user_id = ? FOR UPDATE")).WithArgs(
 					args.userID,
 				).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance"}).AddRow(1, 1, 100000))
 
@@ -217,6 +246,18 @@ func TestWalletRepo_UpdateBalance(t *testing.T) {
 					args.userID,
 				).WillReturnResult(sqlmock.NewResult(1, 1))
 
+				mock.ExpectExec(regexp.QuoteMeta(ledgerInsertQuery)).WithArgs(
+					1,
+					args.amount,
+					constants.TransactionTypeAdjustment,
+					"reference",
+					"",
+					0,
+					constants.BalanceTypeCash,
+					sqlmock.AnyArg(),
+					sqlmock.AnyArg(),
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
 				mock.ExpectCommit()
 			},
 		},
@@ -236,7 +277,7 @@ func TestWalletRepo_UpdateBalance(t *testing.T) {
 			mockFn: func(args args) {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance FROM wallets WHERE user_id = ? FOR UPDATE")).WithArgs(
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE user_id = ? FOR UPDATE")).WithArgs(
 					args.userID,
 				).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance"}).AddRow(1, 1, 100000))
 
@@ -250,7 +291,11 @@ func TestWalletRepo_UpdateBalance(t *testing.T) {
 			r := &WalletRepo{
 				DB: gormDB,
 			}
-			got, err := r.UpdateBalance(tt.args.ctx, tt.args.userID, tt.args.amount)
+			got, err := r.UpdateBalance(tt.args.ctx, tt.args.userID, tt.args.amount, &models.WalletTransaction{
+				Amount:                tt.args.amount,
+				WalletTransactionType: constants.TransactionTypeAdjustment,
+				Reference:             "reference",
+			})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("WalletRepo.UpdateBalance() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -299,11 +344,14 @@ func TestWalletRepo_CreateWalletTrx(t *testing.T) {
 			wantErr: false,
 			mockFn: func(args args) {
 				mock.ExpectBegin()
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions` (`wallet_id`,`amount`,`wallet_transaction_type`,`reference`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?)")).WithArgs(
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions` (`wallet_id`,`amount`,`wallet_transaction_type`,`reference`,`client_id`,`parent_id`,`balance_type`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?)")).WithArgs(
 					args.walletHistory.WalletID,
 					args.walletHistory.Amount,
 					args.walletHistory.WalletTransactionType,
 					args.walletHistory.Reference,
+					args.walletHistory.ClientID,
+					args.walletHistory.ParentID,
+					constants.BalanceTypeCash,
 					sqlmock.AnyArg(),
 					sqlmock.AnyArg(),
 				).WillReturnResult(sqlmock.NewResult(1, 1))
@@ -324,11 +372,14 @@ func TestWalletRepo_CreateWalletTrx(t *testing.T) {
 			wantErr: true,
 			mockFn: func(args args) {
 				mock.ExpectBegin()
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions` (`wallet_id`,`amount`,`wallet_transaction_type`,`reference`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?)")).WithArgs(
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions` (`wallet_id`,`amount`,`wallet_transaction_type`,`reference`,`client_id`,`parent_id`,`balance_type`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?)")).WithArgs(
 					args.walletHistory.WalletID,
 					args.walletHistory.Amount,
 					args.walletHistory.WalletTransactionType,
 					args.walletHistory.Reference,
+					args.walletHistory.ClientID,
+					args.walletHistory.ParentID,
+					constants.BalanceTypeCash,
 					sqlmock.AnyArg(),
 					sqlmock.AnyArg(),
 				).WillReturnError(assert.AnError)
@@ -349,11 +400,14 @@ func TestWalletRepo_CreateWalletTrx(t *testing.T) {
 			wantErr: true,
 			mockFn: func(args args) {
 				mock.ExpectBegin()
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions` (`wallet_id`,`amount`,`wallet_transaction_type`,`reference`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?)")).WithArgs(
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions` (`wallet_id`,`amount`,`wallet_transaction_type`,`reference`,`client_id`,`parent_id`,`balance_type`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?)")).WithArgs(
 					args.walletHistory.WalletID,
 					args.walletHistory.Amount,
 					args.walletHistory.WalletTransactionType,
 					args.walletHistory.Reference,
+					args.walletHistory.ClientID,
+					args.walletHistory.ParentID,
+					constants.BalanceTypeCash,
 					sqlmock.AnyArg(),
 					sqlmock.AnyArg(),
 				).WillReturnError(assert.AnError)
@@ -580,7 +634,7 @@ func TestWalletRepo_GetWalletHistory(t *testing.T) {
 			want: []models.WalletTransaction{
 				{
 					ID:                    3,
-					WalletID:              3,
+					WalletID:              1,
 					Amount:                300000,
 					WalletTransactionType: "DEBIT",
 					Reference:             "reference",
@@ -589,7 +643,7 @@ func TestWalletRepo_GetWalletHistory(t *testing.T) {
 				},
 				{
 					ID:                    5,
-					WalletID:              5,
+					WalletID:              1,
 					Amount:                500000,
 					WalletTransactionType: "DEBIT",
 					Reference:             "reference",
@@ -599,12 +653,13 @@ func TestWalletRepo_GetWalletHistory(t *testing.T) {
 			},
 			wantErr: false,
 			mockFn: func(args args) {
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallet_transactions` WHERE wallet_transaction_type = ? ORDER BY id DESC LIMIT ? OFFSET ?")).WithArgs(
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallet_transactions` WHERE wallet_id = ? AND wallet_transaction_type = ? ORDER BY id DESC LIMIT ? OFFSET ?")).WithArgs(
+					args.walletID,
 					args.transactionType,
 					args.limit,
 					args.offset,
 				).WillReturnRows(mock.NewRows([]string{"id", "wallet_id", "amount", "wallet_transaction_type", "reference", "created_at", "updated_at"}).
-					AddRow(3, 3, 300000, "DEBIT", "reference", now, now).AddRow(5, 5, 500000, "DEBIT", "reference", now, now))
+					AddRow(3, 1, 300000, "DEBIT", "reference", now, now).AddRow(5, 1, 500000, "DEBIT", "reference", now, now))
 			},
 		},
 		{
@@ -619,7 +674,8 @@ func TestWalletRepo_GetWalletHistory(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 			mockFn: func(args args) {
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallet_transactions` WHERE wallet_transaction_type = ? ORDER BY id DESC LIMIT ? OFFSET ?")).WithArgs(
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallet_transactions` WHERE wallet_id = ? AND wallet_transaction_type = ? ORDER BY id DESC LIMIT ? OFFSET ?")).WithArgs(
+					args.walletID,
 					args.transactionType,
 					args.limit,
 					args.offset,
@@ -638,7 +694,7 @@ func TestWalletRepo_GetWalletHistory(t *testing.T) {
 			want: []models.WalletTransaction{
 				{
 					ID:                    3,
-					WalletID:              3,
+					WalletID:              1,
 					Amount:                300000,
 					WalletTransactionType: "DEBIT",
 					Reference:             "reference",
@@ -647,7 +703,7 @@ func TestWalletRepo_GetWalletHistory(t *testing.T) {
 				},
 				{
 					ID:                    4,
-					WalletID:              4,
+					WalletID:              1,
 					Amount:                400000,
 					WalletTransactionType: "CREDIT",
 					Reference:             "reference",
@@ -656,7 +712,7 @@ func TestWalletRepo_GetWalletHistory(t *testing.T) {
 				},
 				{
 					ID:                    5,
-					WalletID:              5,
+					WalletID:              1,
 					Amount:                500000,
 					WalletTransactionType: "DEBIT",
 					Reference:             "reference",
@@ -666,12 +722,13 @@ func TestWalletRepo_GetWalletHistory(t *testing.T) {
 			},
 			wantErr: false,
 			mockFn: func(args args) {
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallet_transactions` ORDER BY id DESC LIMIT ? OFFSET ?")).WithArgs(
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallet_transactions` WHERE wallet_id = ? ORDER BY id DESC LIMIT ? OFFSET ?")).WithArgs(
+					args.walletID,
 					args.limit,
 					args.offset,
 				).WillReturnRows(mock.NewRows([]string{"id", "wallet_id", "amount", "wallet_transaction_type", "reference", "created_at", "updated_at"}).
-					AddRow(3, 3, 300000, "DEBIT", "reference", now, now).AddRow(4, 4, 400000, "CREDIT", "reference", now, now).
-					AddRow(5, 5, 500000, "DEBIT", "reference", now, now))
+					AddRow(3, 1, 300000, "DEBIT", "reference", now, now).AddRow(4, 1, 400000, "CREDIT", "reference", now, now).
+					AddRow(5, 1, 500000, "DEBIT", "reference", now, now))
 			},
 		},
 		{
@@ -686,7 +743,8 @@ func TestWalletRepo_GetWalletHistory(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 			mockFn: func(args args) {
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallet_transactions` ORDER BY id DESC LIMIT ? OFFSET ?")).WithArgs(
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallet_transactions` WHERE wallet_id = ? ORDER BY id DESC LIMIT ? OFFSET ?")).WithArgs(
+					args.walletID,
 					args.limit,
 					args.offset,
 				).WillReturnError(assert.AnError)
@@ -1079,13 +1137,25 @@ func TestWalletRepo_UpdateBalanceByID(t *testing.T) {
 			mockFn: func(args args) {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance FROM wallets WHERE id = ? FOR UPDATE")).WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 200000))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 200000))
 
 				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + ? WHERE id = ?")).WithArgs(
 					args.amount,
 					args.walletID,
 				).WillReturnResult(sqlmock.NewResult(1, 1))
 
+				mock.ExpectExec(regexp.QuoteMeta(ledgerInsertQuery)).WithArgs(
+					1,
+					args.amount,
+					constants.TransactionTypeAdjustment,
+					"reference",
+					"",
+					0,
+					constants.BalanceTypeCash,
+					sqlmock.AnyArg(),
+					sqlmock.AnyArg(),
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
 				mock.ExpectCommit()
 			},
 		},
@@ -1104,13 +1174,25 @@ func TestWalletRepo_UpdateBalanceByID(t *testing.T) {
 			mockFn: func(args args) {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance FROM wallets WHERE id = ? FOR UPDATE")).WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 200000))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 200000))
 
 				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + ? WHERE id = ?")).WithArgs(
 					args.amount,
 					args.walletID,
 				).WillReturnResult(sqlmock.NewResult(1, 1))
 
+				mock.ExpectExec(regexp.QuoteMeta(ledgerInsertQuery)).WithArgs(
+					1,
+					args.amount,
+					constants.TransactionTypeAdjustment,
+					"reference",
+					"",
+					0,
+					constants.BalanceTypeCash,
+					sqlmock.AnyArg(),
+					sqlmock.AnyArg(),
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
 				mock.ExpectCommit()
 			},
 		},
@@ -1126,7 +1208,7 @@ func TestWalletRepo_UpdateBalanceByID(t *testing.T) {
 			mockFn: func(args args) {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance FROM wallets WHERE id = ? FOR UPDATE")).WillReturnError(assert.AnError)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WillReturnError(assert.AnError)
 
 				mock.ExpectRollback()
 			},
@@ -1146,7 +1228,7 @@ func TestWalletRepo_UpdateBalanceByID(t *testing.T) {
 			mockFn: func(args args) {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance FROM wallets WHERE id = ? FOR UPDATE")).WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 200000))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 200000))
 
 				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + ? WHERE id = ?")).WithArgs(
 					args.amount,
@@ -1156,6 +1238,33 @@ func TestWalletRepo_UpdateBalanceByID(t *testing.T) {
 				mock.ExpectRollback()
 			},
 		},
+		{
+			name: "error insert ledger row rolls the balance back",
+			args: args{
+				ctx:      context.Background(),
+				walletID: 1,
+				amount:   -50000,
+			},
+			want: models.Wallet{
+				ID:      1,
+				Balance: 200000,
+			},
+			wantErr: true,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 200000))
+
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + ? WHERE id = ?")).WithArgs(
+					args.amount,
+					args.walletID,
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectExec(regexp.QuoteMeta(ledgerInsertQuery)).WillReturnError(assert.AnError)
+
+				mock.ExpectRollback()
+			},
+		},
 		{
 			name: "error decrease balance",
 			args: args{
@@ -1171,10 +1280,119 @@ func TestWalletRepo_UpdateBalanceByID(t *testing.T) {
 			mockFn: func(args args) {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
 					args.walletID,
 				).WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 200000))
 
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name: "error decrease held balance",
+			args: args{
+				ctx:      context.Background(),
+				walletID: 1,
+				amount:   -150000,
+			},
+			want: models.Wallet{
+				ID:          1,
+				Balance:     200000,
+				HeldBalance: 100000,
+			},
+			wantErr: true,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
+					args.walletID,
+				).WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held_balance"}).AddRow(1, 200000, 100000))
+
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name: "error debit frozen wallet",
+			args: args{
+				ctx:      context.Background(),
+				walletID: 1,
+				amount:   -50000,
+			},
+			want: models.Wallet{
+				ID:      1,
+				Balance: 200000,
+				Status:  "frozen",
+			},
+			wantErr: true,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
+					args.walletID,
+				).WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow(1, 200000, "frozen"))
+
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name: "success credit frozen wallet",
+			args: args{
+				ctx:      context.Background(),
+				walletID: 1,
+				amount:   50000,
+			},
+			want: models.Wallet{
+				ID:      1,
+				Balance: 200000,
+				Status:  "frozen",
+			},
+			wantErr: false,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
+					args.walletID,
+				).WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow(1, 200000, "frozen"))
+
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + ? WHERE id = ?")).WithArgs(
+					args.amount,
+					args.walletID,
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectExec(regexp.QuoteMeta(ledgerInsertQuery)).WithArgs(
+					1,
+					args.amount,
+					constants.TransactionTypeAdjustment,
+					"reference",
+					"",
+					0,
+					constants.BalanceTypeCash,
+					sqlmock.AnyArg(),
+					sqlmock.AnyArg(),
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "error credit suspended wallet",
+			args: args{
+				ctx:      context.Background(),
+				walletID: 1,
+				amount:   50000,
+			},
+			want: models.Wallet{
+				ID:      1,
+				Balance: 200000,
+				Status:  "suspended",
+			},
+			wantErr: true,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
+					args.walletID,
+				).WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow(1, 200000, "suspended"))
+
 				mock.ExpectRollback()
 			},
 		},
@@ -1185,7 +1403,11 @@ func TestWalletRepo_UpdateBalanceByID(t *testing.T) {
 			r := &WalletRepo{
 				DB: gormDB,
 			}
-			got, err := r.UpdateBalanceByID(tt.args.ctx, tt.args.walletID, tt.args.amount)
+			got, err := r.UpdateBalanceByID(tt.args.ctx, tt.args.walletID, tt.args.amount, &models.WalletTransaction{
+				Amount:                tt.args.amount,
+				WalletTransactionType: constants.TransactionTypeAdjustment,
+				Reference:             "reference",
+			})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("WalletRepo.UpdateBalanceByID() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1197,3 +1419,381 @@ func TestWalletRepo_UpdateBalanceByID(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletRepo_UpdateWalletStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	type args struct {
+		ctx          context.Context
+		walletID     int
+		fromStatuses []string
+		history      *models.WalletStatusHistory
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    models.Wallet
+		wantErr bool
+		mockFn  func(args args)
+	}{
+		{
+			name: "success freeze",
+			args: args{
+				ctx:          context.Background(),
+				walletID:     1,
+				fromStatuses: []string{"active"},
+				history: &models.WalletStatusHistory{
+					ToStatus:   "frozen",
+					ReasonCode: "FRAUD_SUSPECTED",
+					ChangedBy:  "operator",
+				},
+			},
+			want: models.Wallet{
+				ID:      1,
+				UserID:  1,
+				Balance: 100000,
+				Status:  "frozen",
+			},
+			wantErr: false,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
+					args.walletID,
+				).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).AddRow(1, 1, 100000, "active"))
+
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET status = ? WHERE id = ?")).WithArgs(
+					"frozen",
+					args.walletID,
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_status_histories`")).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "success close with payout",
+			args: args{
+				ctx:          context.Background(),
+				walletID:     1,
+				fromStatuses: []string{"active", "frozen", "suspended"},
+				history: &models.WalletStatusHistory{
+					ToStatus:   "closed",
+					ReasonCode: "CUSTOMER_REQUEST",
+					ChangedBy:  "operator",
+					PayoutRef:  "CLOSE-1",
+				},
+			},
+			want: models.Wallet{
+				ID:      1,
+				UserID:  1,
+				Balance: 0,
+				Status:  "closed",
+			},
+			wantErr: false,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
+					args.walletID,
+				).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).AddRow(1, 1, 100000, "frozen"))
+
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions` (`wallet_id`,`amount`,`wallet_transaction_type`,`reference`,`client_id`,`parent_id`,`balance_type`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?)")).WithArgs(
+					1,
+					float64(100000),
+					"DEBIT",
+					"CLOSE-1",
+					"",
+					0,
+					constants.BalanceTypeCash,
+					sqlmock.AnyArg(),
+					sqlmock.AnyArg(),
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = 0 WHERE id = ?")).WithArgs(
+					args.walletID,
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET status = ? WHERE id = ?")).WithArgs(
+					"closed",
+					args.walletID,
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_status_histories`")).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "error invalid transition",
+			args: args{
+				ctx:          context.Background(),
+				walletID:     1,
+				fromStatuses: []string{"frozen"},
+				history: &models.WalletStatusHistory{
+					ToStatus: "active",
+				},
+			},
+			want: models.Wallet{
+				ID:      1,
+				UserID:  1,
+				Balance: 100000,
+				Status:  "closed",
+			},
+			wantErr: true,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
+					args.walletID,
+				).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).AddRow(1, 1, 100000, "closed"))
+
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name: "error close with held balance",
+			args: args{
+				ctx:          context.Background(),
+				walletID:     1,
+				fromStatuses: []string{"active"},
+				history: &models.WalletStatusHistory{
+					ToStatus: "closed",
+				},
+			},
+			want: models.Wallet{
+				ID:          1,
+				UserID:      1,
+				Balance:     100000,
+				HeldBalance: 2500,
+				Status:      "active",
+			},
+			wantErr: true,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
+					args.walletID,
+				).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "held_balance", "status"}).AddRow(1, 1, 100000, 2500, "active"))
+
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name: "error wallet not found",
+			args: args{
+				ctx:          context.Background(),
+				walletID:     1,
+				fromStatuses: []string{"active"},
+				history: &models.WalletStatusHistory{
+					ToStatus: "frozen",
+				},
+			},
+			want:    models.Wallet{},
+			wantErr: true,
+			mockFn: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(
+					args.walletID,
+				).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}))
+
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.args)
+			r := &WalletRepo{
+				DB: gormDB,
+			}
+			got, err := r.UpdateWalletStatus(tt.args.ctx, tt.args.walletID, tt.args.fromStatuses, tt.args.history)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WalletRepo.UpdateWalletStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WalletRepo.UpdateWalletStatus() = %v, want %v", got, tt.want)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWalletRepo_GetStatementSnapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		want    models.StatementSnapshot
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			want: models.StatementSnapshot{
+				OpeningBalance: 150,
+				Transactions: []models.WalletTransaction{
+					{ID: 3, WalletID: 1, Amount: 300, WalletTransactionType: "CREDIT", Reference: "reference", CreatedAt: now, UpdatedAt: now},
+				},
+			},
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM("+ledgerAmountExpr+"), 0) FROM wallet_transactions t WHERE t.wallet_id = ? AND t.created_at < ?")).
+					WithArgs(1, from).
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(150))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `wallet_transactions` WHERE wallet_id = ? AND created_at >= ? AND created_at < ? ORDER BY created_at ASC, id ASC")).
+					WithArgs(1, from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "wallet_id", "amount", "wallet_transaction_type", "reference", "created_at", "updated_at"}).
+						AddRow(3, 1, 300, "CREDIT", "reference", now, now))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error",
+			wantErr: true,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("FROM wallet_transactions t WHERE t.wallet_id = ? AND t.created_at < ?")).
+					WithArgs(1, from).
+					WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &WalletRepo{
+				DB: gormDB,
+			}
+			got, err := r.GetStatementSnapshot(context.Background(), 1, from, to)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWalletRepo_DebitWithFee(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	lockQuery := regexp.QuoteMeta("SELECT id, balance, held_balance, promo_balance, status, spend_order, type FROM wallets WHERE id = ? FOR UPDATE")
+	walletColumns := []string{"id", "balance", "held_balance", "promo_balance", "status", "spend_order"}
+
+	tests := []struct {
+		name      string
+		want      models.Wallet
+		wantPromo float64
+		wantErr   error
+		mockFn    func()
+	}{
+		{
+			name: "success",
+			want: models.Wallet{ID: 1, Balance: 100000, Status: constants.WalletStatusActive, SpendOrder: constants.SpendOrderPromoFirst},
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 100000, 0, 0, constants.WalletStatusActive, constants.SpendOrderPromoFirst))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance - ?, promo_balance = promo_balance - ? WHERE id = ?")).WithArgs(50500.0, 0.0, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(1, 50000.0, constants.TransactionTypeDebit, "reference", "client", 0, constants.BalanceTypeCash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(11, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(1, 500.0, constants.TransactionTypeFee, "FEE-11", "client", 11, constants.BalanceTypeCash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(12, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + ? WHERE id = ?")).WithArgs(500.0, 99).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(99, 500.0, constants.TransactionTypeCredit, "FEE-11-REV", "", 11, constants.BalanceTypeCash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(13, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error balance covers the debit but not the fee",
+			want:    models.Wallet{ID: 1, Balance: 50000, Status: constants.WalletStatusActive, SpendOrder: constants.SpendOrderPromoFirst},
+			wantErr: constants.ErrInsufficientBalance,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 50000, 0, 0, constants.WalletStatusActive, constants.SpendOrderPromoFirst))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name:    "error wallet not found",
+			wantErr: gorm.ErrRecordNotFound,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns))
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &WalletRepo{
+				DB: gormDB,
+			}
+			debit := &models.WalletTransaction{WalletID: 1, Amount: 50000, WalletTransactionType: constants.TransactionTypeDebit, Reference: "reference", ClientID: "client"}
+			fee := &models.FeePosting{
+				Fee:     &models.WalletTransaction{WalletID: 1, Amount: 500, WalletTransactionType: constants.TransactionTypeFee, ClientID: "client"},
+				Revenue: &models.WalletTransaction{WalletID: 99, Amount: 500, WalletTransactionType: constants.TransactionTypeCredit},
+			}
+			got, promo, err := r.DebitWithFee(context.Background(), debit, fee)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 11, fee.Fee.ParentID)
+				assert.Equal(t, 13, fee.Revenue.ID)
+			}
+			assert.Equal(t, tt.wantPromo, promo)
+			assert.Equal(t, tt.want, got)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WithdrawalRepo struct {
+	DB *gorm.DB
+}
+
+func (r *WithdrawalRepo) CreateBankAccount(ctx context.Context, account *models.BankAccount) error {
+	return r.DB.Create(account).Error
+}
+
+func (r *WithdrawalRepo) GetBankAccounts(ctx context.Context, userID uint64) ([]models.BankAccount, error) {
+	var (
+		resp []models.BankAccount
+	)
+
+	err := r.DB.Where("user_id = ?", userID).Order("id ASC").Find(&resp).Error
+
+	return resp, err
+}
+
+func (r *WithdrawalRepo) GetBankAccountByID(ctx context.Context, accountID int) (models.BankAccount, error) {
+	var (
+		resp models.BankAccount
+	)
+
+	err := r.DB.Where("id = ?", accountID).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *WithdrawalRepo) DeleteBankAccount(ctx context.Context, userID uint64, accountID int) error {
+	result := r.DB.Exec("DELETE FROM bank_accounts WHERE id = ? AND user_id = ?", accountID, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrBankAccountNotFound
+	}
+
+	return nil
+}
+
+// CreateWithdrawal holds the withdrawal total on the wallet and stores the
+// withdrawal in one transaction. The hold fails like a debit would.
+func (r *WithdrawalRepo) CreateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var wallet models.Wallet
+		err := tx.Raw("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE", withdrawal.WalletID).Scan(&wallet).Error
+		if err != nil {
+			return err
+		}
+
+		if wallet.ID == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := checkWalletMovement(wallet, -withdrawal.Total()); err != nil {
+			return err
+		}
+
+		if err := checkAvailableBalance(wallet, -withdrawal.Total()); err != nil {
+			return err
+		}
+
+		err = tx.Exec("UPDATE wallets SET held_balance = held_balance + ? WHERE id = ?", withdrawal.Total(), wallet.ID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(withdrawal).Error
+	})
+}
+
+func (r *WithdrawalRepo) GetWithdrawalByID(ctx context.Context, withdrawalID int) (models.Withdrawal, error) {
+	var (
+		resp models.Withdrawal
+	)
+
+	err := r.DB.Where("id = ?", withdrawalID).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *WithdrawalRepo) GetWithdrawalByReference(ctx context.Context, userID uint64, reference string) (models.Withdrawal, error) {
+	var (
+		resp models.Withdrawal
+	)
+
+	err := r.DB.Where("user_id = ? AND reference = ?", userID, reference).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *WithdrawalRepo) GetWithdrawalByPayoutReference(ctx context.Context, payoutReference string) (models.Withdrawal, error) {
+	var (
+		resp models.Withdrawal
+	)
+
+	err := r.DB.Where("payout_reference = ?", payoutReference).First(&resp).Error
+
+	return resp, err
+}
+
+func (r *WithdrawalRepo) GetWithdrawals(ctx context.Context, userID uint64, status string, offset int, limit int) ([]models.Withdrawal, error) {
+	var (
+		resp []models.Withdrawal
+	)
+
+	sql := r.DB.Where("user_id = ?", userID)
+	if status != "" {
+		sql = sql.Where("status = ?", status)
+	}
+	err := sql.Limit(limit).Offset(offset).Order("id DESC").Find(&resp).Error
+
+	return resp, err
+}
+
+func (r *WithdrawalRepo) MarkWithdrawalProcessing(ctx context.Context, withdrawalID int, providerReference string, now time.Time) error {
+	result := r.DB.Exec("UPDATE withdrawals SET status = ?, provider_reference = ?, submitted_at = ?, updated_at = ? WHERE id = ? AND status = ?",
+		constants.WithdrawalStatusProcessing, providerReference, now, now, withdrawalID, constants.WithdrawalStatusRequested)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrWithdrawalNotInFlight
+	}
+
+	return nil
+}
+
+// CompleteWithdrawal turns the hold into a debit, and books the fee when the
+// withdrawal has one. The wallet status is not checked, the money already
+// left through the bank.
+func (r *WithdrawalRepo) CompleteWithdrawal(ctx context.Context, withdrawal models.Withdrawal, debit *models.WalletTransaction, fee *models.FeePosting, now time.Time) (models.Wallet, error) {
+	var wallet models.Wallet
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := r.closeWithdrawal(tx, withdrawal.ID, constants.WithdrawalStatusSucceeded, "", now)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Raw("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE", withdrawal.WalletID).Scan(&wallet).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("UPDATE wallets SET balance = balance - ?, held_balance = held_balance - ? WHERE id = ?",
+			withdrawal.Total(), withdrawal.Total(), withdrawal.WalletID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(debit).Error
+		if err != nil {
+			return err
+		}
+
+		return bookFee(tx, debit, fee)
+	})
+
+	return wallet, err
+}
+
+// FailWithdrawal releases the hold of a withdrawal that did not go out.
+func (r *WithdrawalRepo) FailWithdrawal(ctx context.Context, withdrawal models.Withdrawal, reason string, now time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := r.closeWithdrawal(tx, withdrawal.ID, constants.WithdrawalStatusFailed, reason, now)
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec("UPDATE wallets SET held_balance = held_balance - ? WHERE id = ?", withdrawal.Total(), withdrawal.WalletID).Error
+	})
+}
+
+// closeWithdrawal lets only one outcome through for an in flight withdrawal.
+func (r *WithdrawalRepo) closeWithdrawal(tx *gorm.DB, withdrawalID int, toStatus string, reason string, now time.Time) error {
+	result := tx.Exec("UPDATE withdrawals SET status = ?, failure_reason = ?, completed_at = ?, updated_at = ? WHERE id = ? AND status IN ?",
+		toStatus, reason, now, now, withdrawalID, []string{constants.WithdrawalStatusRequested, constants.WithdrawalStatusProcessing})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return constants.ErrWithdrawalNotInFlight
+	}
+
+	return nil
+}
+
+func (r *WithdrawalRepo) GetUnsubmittedWithdrawals(ctx context.Context, before time.Time, limit int) ([]models.Withdrawal, error) {
+	var (
+		resp []models.Withdrawal
+	)
+
+	err := r.DB.Where("status = ? AND created_at <= ?", constants.WithdrawalStatusRequested, before).
+		Order("id ASC").Limit(limit).Find(&resp).Error
+
+	return resp, err
+}
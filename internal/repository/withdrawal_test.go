@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestWithdrawalRepo_CreateWithdrawal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	lockQuery := regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")
+	walletColumns := []string{"id", "user_id", "balance", "held_balance", "status"}
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 10, 150000, 40000, constants.WalletStatusActive))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET held_balance = held_balance + ? WHERE id = ?")).WithArgs(102500.0, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `withdrawals`")).
+					WillReturnResult(sqlmock.NewResult(5, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error balance already held",
+			wantErr: constants.ErrInsufficientBalance,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 10, 150000, 50000, constants.WalletStatusActive))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name:    "error wallet frozen",
+			wantErr: constants.ErrWalletFrozen,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(1, 10, 150000, 0, constants.WalletStatusFrozen))
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &WithdrawalRepo{
+				DB: gormDB,
+			}
+			withdrawal := &models.Withdrawal{
+				UserID:          10,
+				WalletID:        1,
+				Amount:          100000,
+				Fee:             2500,
+				Status:          constants.WithdrawalStatusRequested,
+				Reference:       "ref-1",
+				PayoutReference: "WDR-1-1",
+			}
+			err := r.CreateWithdrawal(context.Background(), withdrawal)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 5, withdrawal.ID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWithdrawalRepo_CompleteWithdrawal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+	closeQuery := regexp.QuoteMeta("UPDATE withdrawals SET status = ?, failure_reason = ?, completed_at = ?, updated_at = ? WHERE id = ? AND status IN (?,?)")
+
+	tests := []struct {
+		name    string
+		want    models.Wallet
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			want: models.Wallet{ID: 1, UserID: 10, Balance: 150000, HeldBalance: 102500, Status: constants.WalletStatusFrozen},
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(closeQuery).
+					WithArgs(constants.WithdrawalStatusSucceeded, "", now, now, 5, constants.WithdrawalStatusRequested, constants.WithdrawalStatusProcessing).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, balance, held_balance, promo_balance, status, type FROM wallets WHERE id = ? FOR UPDATE")).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "held_balance", "status"}).
+						AddRow(1, 10, 150000, 102500, constants.WalletStatusFrozen))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance - ?, held_balance = held_balance - ? WHERE id = ?")).
+					WithArgs(102500.0, 102500.0, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(1, 100000.0, constants.TransactionTypeDebit, "WDR-1-1", "", 0, constants.BalanceTypeCash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(11, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(1, 2500.0, constants.TransactionTypeFee, "FEE-11", "", 11, constants.BalanceTypeCash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(12, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = balance + ? WHERE id = ?")).WithArgs(2500.0, 99).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `wallet_transactions`")).
+					WithArgs(99, 2500.0, constants.TransactionTypeCredit, "FEE-11-REV", "", 11, constants.BalanceTypeCash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(13, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "error not in flight",
+			wantErr: constants.ErrWithdrawalNotInFlight,
+			mockFn: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(closeQuery).
+					WithArgs(constants.WithdrawalStatusSucceeded, "", now, now, 5, constants.WithdrawalStatusRequested, constants.WithdrawalStatusProcessing).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			r := &WithdrawalRepo{
+				DB: gormDB,
+			}
+			withdrawal := models.Withdrawal{ID: 5, WalletID: 1, Amount: 100000, Fee: 2500}
+			debit := &models.WalletTransaction{WalletID: 1, Amount: 100000, WalletTransactionType: constants.TransactionTypeDebit, Reference: "WDR-1-1"}
+			fee := &models.FeePosting{
+				Fee:     &models.WalletTransaction{WalletID: 1, Amount: 2500, WalletTransactionType: constants.TransactionTypeFee},
+				Revenue: &models.WalletTransaction{WalletID: 99, Amount: 2500, WalletTransactionType: constants.TransactionTypeCredit},
+			}
+			got, err := r.CompleteWithdrawal(context.Background(), withdrawal, debit, fee, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 11, debit.ID)
+				assert.Equal(t, 12, fee.Fee.ID)
+				assert.Equal(t, 13, fee.Revenue.ID)
+			}
+			assert.Equal(t, tt.want, got)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWithdrawalRepo_FailWithdrawal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	assert.NoError(t, err)
+
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE withdrawals SET status = ?, failure_reason = ?, completed_at = ?, updated_at = ? WHERE id = ? AND status IN (?,?)")).
+		WithArgs(constants.WithdrawalStatusFailed, "account closed", now, now, 5, constants.WithdrawalStatusRequested, constants.WithdrawalStatusProcessing).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET held_balance = held_balance - ? WHERE id = ?")).WithArgs(102500.0, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	r := &WithdrawalRepo{
+		DB: gormDB,
+	}
+	err = r.FailWithdrawal(context.Background(), models.Withdrawal{ID: 5, WalletID: 1, Amount: 100000, Fee: 2500}, "account closed", now)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
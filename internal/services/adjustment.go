@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func (s *AdminService) CreateAdjustment(ctx context.Context, actor models.OperatorTokenData, req models.CreateAdjustmentRequest) (models.BalanceAdjustment, error) {
+	var (
+		resp models.BalanceAdjustment
+	)
+
+	if _, ok := constants.MappingAdjustmentReasonCode[req.ReasonCode]; !ok {
+		return resp, fmt.Errorf("unknown adjustment reason code %s", req.ReasonCode)
+	}
+
+	wallet, err := s.WalletRepo.GetWalletByID(ctx, req.WalletID)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get wallet")
+	}
+
+	if wallet.Type == constants.WalletTypeSystem {
+		return resp, constants.ErrSystemWallet
+	}
+
+	expiresInHours := req.ExpiresInHours
+	if expiresInHours == 0 {
+		expiresInHours = constants.DefaultAdjustmentExpiryHours
+	}
+
+	now := time.Now()
+	resp = models.BalanceAdjustment{
+		WalletID:      req.WalletID,
+		Amount:        req.Amount,
+		Direction:     req.Direction,
+		ReasonCode:    req.ReasonCode,
+		Note:          req.Note,
+		Status:        constants.AdjustmentStatusPending,
+		Reference:     fmt.Sprintf("ADJ-%d-%d", req.WalletID, now.UnixNano()),
+		MakerID:       actor.OperatorID,
+		MakerUsername: actor.Username,
+		ExpiresAt:     now.Add(time.Duration(expiresInHours) * time.Hour),
+	}
+
+	err = s.AdminRepo.CreateAdjustment(ctx, &resp)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to create balance adjustment")
+	}
+
+	if err := s.writeAuditLog(ctx, actor, "adjustment.create", constants.AuditTargetAdjustment, strconv.Itoa(resp.ID), adjustmentAuditDetail(resp)); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+func (s *AdminService) GetAdjustments(ctx context.Context, actor models.OperatorTokenData, param models.AdjustmentParam) ([]models.BalanceAdjustment, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.AdminRepo.GetAdjustments(ctx, param, offset, limit)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get balance adjustments")
+	}
+
+	return resp, nil
+}
+
+func (s *AdminService) ApproveAdjustment(ctx context.Context, actor models.OperatorTokenData, adjustmentID int, req models.AdjustmentDecisionRequest) (models.BalanceAdjustment, error) {
+	adjustment, err := s.getPendingAdjustment(ctx, actor, adjustmentID)
+	if err != nil {
+		return adjustment, err
+	}
+
+	walletTrx := &models.WalletTransaction{
+		WalletID:              adjustment.WalletID,
+		Amount:                adjustment.SignedAmount(),
+		Reference:             adjustment.Reference,
+		WalletTransactionType: constants.TransactionTypeAdjustment,
+	}
+
+	// the approval commits with the posting, an approved adjustment is
+	// always posted
+	now := time.Now()
+	wallet, err := s.AdminRepo.ApproveAdjustment(ctx, adjustmentID, actor.OperatorID, actor.Username, req.Note, walletTrx, now)
+	if err != nil {
+		if postingRefused(err) {
+			return s.failAdjustment(ctx, actor, adjustment, req.Note, err, now)
+		}
+		return adjustment, errors.Wrap(err, "failed to approve balance adjustment")
+	}
+
+	adjustment.Status = constants.AdjustmentStatusApproved
+	adjustment.CheckerID = actor.OperatorID
+	adjustment.CheckerUsername = actor.Username
+	adjustment.DecisionNote = req.Note
+	adjustment.DecidedAt = &now
+
+	balance := wallet.Balance + walletTrx.Amount
+	s.WalletService.recordBalanceChange(operatorContext(ctx, actor), "balance.adjustment", wallet.ID, wallet.Balance, balance, walletTrx)
+
+	s.recordCommitted(ctx, actor, "adjustment.approve", constants.AuditTargetAdjustment, strconv.Itoa(adjustmentID), adjustmentAuditDetail(adjustment))
+
+	detail := adjustmentAuditDetail(adjustment)
+	detail["balance_after"] = balance
+	s.recordCommitted(ctx, actor, "adjustment.post", constants.AuditTargetAdjustment, strconv.Itoa(adjustmentID), detail)
+
+	return adjustment, nil
+}
+
+// failAdjustment closes an adjustment the wallet refused to post, the reason
+// is kept apart from the checker's note.
+func (s *AdminService) failAdjustment(ctx context.Context, actor models.OperatorTokenData, adjustment models.BalanceAdjustment, note string, postErr error, now time.Time) (models.BalanceAdjustment, error) {
+	err := s.AdminRepo.FailAdjustment(ctx, adjustment.ID, actor.OperatorID, actor.Username, note, postErr.Error(), now)
+	if err != nil {
+		return adjustment, errors.Wrap(err, "failed to mark balance adjustment as failed")
+	}
+
+	adjustment.Status = constants.AdjustmentStatusFailed
+	adjustment.CheckerID = actor.OperatorID
+	adjustment.CheckerUsername = actor.Username
+	adjustment.DecisionNote = note
+	adjustment.FailureReason = postErr.Error()
+	adjustment.DecidedAt = &now
+
+	detail := adjustmentAuditDetail(adjustment)
+	detail["error"] = postErr.Error()
+	s.recordCommitted(ctx, actor, "adjustment.fail", constants.AuditTargetAdjustment, strconv.Itoa(adjustment.ID), detail)
+
+	return adjustment, errors.Wrap(postErr, "failed to post balance adjustment")
+}
+
+func (s *AdminService) RejectAdjustment(ctx context.Context, actor models.OperatorTokenData, adjustmentID int, req models.AdjustmentDecisionRequest) (models.BalanceAdjustment, error) {
+	adjustment, err := s.getPendingAdjustment(ctx, actor, adjustmentID)
+	if err != nil {
+		return adjustment, err
+	}
+
+	now := time.Now()
+	err = s.AdminRepo.DecideAdjustment(ctx, adjustmentID, constants.AdjustmentStatusRejected, actor.OperatorID, actor.Username, req.Note, now)
+	if err != nil {
+		return adjustment, errors.Wrap(err, "failed to reject balance adjustment")
+	}
+
+	adjustment.Status = constants.AdjustmentStatusRejected
+	adjustment.CheckerID = actor.OperatorID
+	adjustment.CheckerUsername = actor.Username
+	adjustment.DecisionNote = req.Note
+	adjustment.DecidedAt = &now
+
+	if err := s.writeAuditLog(ctx, actor, "adjustment.reject", constants.AuditTargetAdjustment, strconv.Itoa(adjustmentID), adjustmentAuditDetail(adjustment)); err != nil {
+		return adjustment, err
+	}
+
+	return adjustment, nil
+}
+
+// getPendingAdjustment loads an adjustment that the actor is allowed to
+// decide on, expiring it on the way if its window has passed.
+func (s *AdminService) getPendingAdjustment(ctx context.Context, actor models.OperatorTokenData, adjustmentID int) (models.BalanceAdjustment, error) {
+	adjustment, err := s.AdminRepo.GetAdjustmentByID(ctx, adjustmentID)
+	if err != nil {
+		return adjustment, errors.Wrap(err, "failed to get balance adjustment")
+	}
+
+	if adjustment.MakerID == actor.OperatorID {
+		if err := s.writeAuditLog(ctx, actor, "adjustment.self_approval_blocked", constants.AuditTargetAdjustment, strconv.Itoa(adjustmentID), nil); err != nil {
+			return adjustment, err
+		}
+		return adjustment, constants.ErrSelfApproval
+	}
+
+	if adjustment.Status != constants.AdjustmentStatusPending {
+		return adjustment, constants.ErrAdjustmentNotPending
+	}
+
+	if !time.Now().Before(adjustment.ExpiresAt) {
+		err = s.AdminRepo.UpdateAdjustmentStatus(ctx, adjustmentID, constants.AdjustmentStatusPending, constants.AdjustmentStatusExpired)
+		if err != nil {
+			return adjustment, errors.Wrap(err, "failed to expire balance adjustment")
+		}
+		adjustment.Status = constants.AdjustmentStatusExpired
+
+		if err := s.writeAuditLog(ctx, actor, "adjustment.expire", constants.AuditTargetAdjustment, strconv.Itoa(adjustmentID), adjustmentAuditDetail(adjustment)); err != nil {
+			return adjustment, err
+		}
+		return adjustment, constants.ErrAdjustmentExpired
+	}
+
+	return adjustment, nil
+}
+
+// postingRefused tells the errors the wallet refuses a posting with, trying
+// again does not change them.
+func postingRefused(err error) bool {
+	for _, refusal := range []error{
+		constants.ErrInsufficientBalance,
+		constants.ErrWalletFrozen,
+		constants.ErrWalletSuspended,
+		constants.ErrWalletClosed,
+		constants.ErrSystemWallet,
+		gorm.ErrRecordNotFound,
+	} {
+		if errors.Is(err, refusal) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func adjustmentAuditDetail(adjustment models.BalanceAdjustment) map[string]interface{} {
+	return map[string]interface{}{
+		"wallet_id":   adjustment.WalletID,
+		"amount":      adjustment.Amount,
+		"direction":   adjustment.Direction,
+		"reason_code": adjustment.ReasonCode,
+		"reference":   adjustment.Reference,
+		"status":      adjustment.Status,
+		"maker":       adjustment.MakerUsername,
+		"checker":     adjustment.CheckerUsername,
+	}
+}
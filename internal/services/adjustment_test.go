@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminService_CreateAdjustment(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAdminRepo := NewMockIAdminRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+
+	maker := models.OperatorTokenData{OperatorID: 1, Username: "maker", Role: constants.OperatorRoleFinance}
+
+	tests := []struct {
+		name    string
+		req     models.CreateAdjustmentRequest
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			req: models.CreateAdjustmentRequest{
+				WalletID:   1,
+				Amount:     5000,
+				Direction:  "CREDIT",
+				ReasonCode: "DUPLICATE_CHARGE",
+				Note:       "charged twice on order 10",
+			},
+			wantErr: false,
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletByID(gomock.Any(), 1).Return(models.Wallet{ID: 1}, nil)
+				mockAdminRepo.EXPECT().CreateAdjustment(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, adjustment *models.BalanceAdjustment) error {
+					assert.Equal(t, constants.AdjustmentStatusPending, adjustment.Status)
+					assert.Equal(t, maker.OperatorID, adjustment.MakerID)
+					assert.NotEmpty(t, adjustment.Reference)
+					assert.WithinDuration(t, time.Now().Add(24*time.Hour), adjustment.ExpiresAt, time.Minute)
+					adjustment.ID = 10
+					return nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "adjustment.create", auditLog.Action)
+					assert.Equal(t, "10", auditLog.TargetID)
+					return nil
+				})
+			},
+		},
+		{
+			name: "error unknown reason code",
+			req: models.CreateAdjustmentRequest{
+				WalletID:   1,
+				Amount:     5000,
+				Direction:  "CREDIT",
+				ReasonCode: "BECAUSE",
+				Note:       "note",
+			},
+			wantErr: true,
+			mockFn:  func() {},
+		},
+		{
+			name: "error wallet not found",
+			req: models.CreateAdjustmentRequest{
+				WalletID:   1,
+				Amount:     5000,
+				Direction:  "CREDIT",
+				ReasonCode: "DUPLICATE_CHARGE",
+				Note:       "note",
+			},
+			wantErr: true,
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletByID(gomock.Any(), 1).Return(models.Wallet{}, assert.AnError)
+			},
+		},
+		{
+			name: "error system wallet",
+			req: models.CreateAdjustmentRequest{
+				WalletID:   1,
+				Amount:     5000,
+				Direction:  "DEBIT",
+				ReasonCode: "DUPLICATE_CHARGE",
+				Note:       "note",
+			},
+			wantErr: true,
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletByID(gomock.Any(), 1).Return(models.Wallet{ID: 1, Type: constants.WalletTypeSystem}, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &AdminService{
+				AdminRepo:    mockAdminRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				WalletRepo:   mockRepo,
+			}
+			_, err := s.CreateAdjustment(context.Background(), maker, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AdminService.CreateAdjustment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdminService_ApproveAdjustment(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAdminRepo := NewMockIAdminRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+
+	checker := models.OperatorTokenData{OperatorID: 2, Username: "checker", Role: constants.OperatorRoleFinance}
+
+	pending := models.BalanceAdjustment{
+		ID:            10,
+		WalletID:      1,
+		Amount:        5000,
+		Direction:     "DEBIT",
+		ReasonCode:    "DUPLICATE_CHARGE",
+		Status:        constants.AdjustmentStatusPending,
+		Reference:     "ADJ-1-1",
+		MakerID:       1,
+		MakerUsername: "maker",
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name       string
+		actor      models.OperatorTokenData
+		wantErr    error
+		wantStatus string
+		mockFn     func()
+	}{
+		{
+			name:       "success",
+			actor:      checker,
+			wantStatus: constants.AdjustmentStatusApproved,
+			mockFn: func() {
+				mockAdminRepo.EXPECT().GetAdjustmentByID(gomock.Any(), 10).Return(pending, nil)
+				mockAdminRepo.EXPECT().ApproveAdjustment(gomock.Any(), 10, checker.OperatorID, checker.Username, "", &models.WalletTransaction{
+					WalletID:              1,
+					Amount:                -5000,
+					Reference:             "ADJ-1-1",
+					WalletTransactionType: constants.TransactionTypeAdjustment,
+				}, gomock.Any()).Return(models.Wallet{ID: 1, Balance: 20000}, nil)
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.adjustment", auditLog.Action)
+						assert.JSONEq(t, `{"balance":15000}`, auditLog.After)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "adjustment.approve", auditLog.Action)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "adjustment.post", auditLog.Action)
+						return nil
+					}),
+				)
+			},
+		},
+		{
+			name:       "success audit write fails after the posting",
+			actor:      checker,
+			wantStatus: constants.AdjustmentStatusApproved,
+			mockFn: func() {
+				mockAdminRepo.EXPECT().GetAdjustmentByID(gomock.Any(), 10).Return(pending, nil)
+				mockAdminRepo.EXPECT().ApproveAdjustment(gomock.Any(), 10, checker.OperatorID, checker.Username, "", gomock.Any(), gomock.Any()).
+					Return(models.Wallet{ID: 1, Balance: 20000}, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(assert.AnError).Times(3)
+			},
+		},
+		{
+			name:    "error self approval",
+			actor:   models.OperatorTokenData{OperatorID: 1, Username: "maker", Role: constants.OperatorRoleFinance},
+			wantErr: constants.ErrSelfApproval,
+			mockFn: func() {
+				mockAdminRepo.EXPECT().GetAdjustmentByID(gomock.Any(), 10).Return(pending, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "adjustment.self_approval_blocked", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error already decided",
+			actor:   checker,
+			wantErr: constants.ErrAdjustmentNotPending,
+			mockFn: func() {
+				decided := pending
+				decided.Status = constants.AdjustmentStatusRejected
+				mockAdminRepo.EXPECT().GetAdjustmentByID(gomock.Any(), 10).Return(decided, nil)
+			},
+		},
+		{
+			name:       "error expired",
+			actor:      checker,
+			wantErr:    constants.ErrAdjustmentExpired,
+			wantStatus: constants.AdjustmentStatusExpired,
+			mockFn: func() {
+				expired := pending
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				mockAdminRepo.EXPECT().GetAdjustmentByID(gomock.Any(), 10).Return(expired, nil)
+				mockAdminRepo.EXPECT().UpdateAdjustmentStatus(gomock.Any(), 10, constants.AdjustmentStatusPending, constants.AdjustmentStatusExpired).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "error lost decision race",
+			actor:   checker,
+			wantErr: constants.ErrAdjustmentNotPending,
+			mockFn: func() {
+				mockAdminRepo.EXPECT().GetAdjustmentByID(gomock.Any(), 10).Return(pending, nil)
+				mockAdminRepo.EXPECT().ApproveAdjustment(gomock.Any(), 10, checker.OperatorID, checker.Username, "", gomock.Any(), gomock.Any()).
+					Return(models.Wallet{}, constants.ErrAdjustmentNotPending)
+			},
+		},
+		{
+			name:       "error database stays pending",
+			actor:      checker,
+			wantErr:    assert.AnError,
+			wantStatus: constants.AdjustmentStatusPending,
+			mockFn: func() {
+				mockAdminRepo.EXPECT().GetAdjustmentByID(gomock.Any(), 10).Return(pending, nil)
+				mockAdminRepo.EXPECT().ApproveAdjustment(gomock.Any(), 10, checker.OperatorID, checker.Username, "", gomock.Any(), gomock.Any()).
+					Return(models.Wallet{}, assert.AnError)
+			},
+		},
+		{
+			name:       "error post balance",
+			actor:      checker,
+			wantErr:    constants.ErrInsufficientBalance,
+			wantStatus: constants.AdjustmentStatusFailed,
+			mockFn: func() {
+				mockAdminRepo.EXPECT().GetAdjustmentByID(gomock.Any(), 10).Return(pending, nil)
+				mockAdminRepo.EXPECT().ApproveAdjustment(gomock.Any(), 10, checker.OperatorID, checker.Username, "", gomock.Any(), gomock.Any()).
+					Return(models.Wallet{}, fmt.Errorf("%w: 100 - 5000", constants.ErrInsufficientBalance))
+				mockAdminRepo.EXPECT().FailAdjustment(gomock.Any(), 10, checker.OperatorID, checker.Username, "", gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, adjustmentID int, checkerID int, checkerUsername string, note string, reason string, now time.Time) error {
+						assert.Contains(t, reason, constants.ErrInsufficientBalance.Error())
+						return nil
+					})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "adjustment.fail", auditLog.Action)
+					return nil
+				})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &AdminService{
+				AdminRepo:    mockAdminRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				WalletRepo:   mockRepo,
+				WalletService: &WalletService{
+					WalletRepo:   mockRepo,
+					AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				},
+			}
+			got, err := s.ApproveAdjustment(context.Background(), tt.actor, 10, models.AdjustmentDecisionRequest{})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.wantStatus != "" {
+				assert.Equal(t, tt.wantStatus, got.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAdminPageLimit = 20
+	maxAdminPageLimit     = 100
+)
+
+// dummyPasswordHash is compared against when the operator is not found, so an
+// unknown username costs the same bcrypt time as a wrong password
+var dummyPasswordHash = []byte("$2a$10$3wfXeXF/b0V.Z6v0Q9ecT.KZoWaCN0a2kUDD.dQie73gEZf6PjAY2")
+
+type AdminService struct {
+	AdminRepo     i_repository.IAdminRepo
+	WalletRepo    i_repository.IWalletRepo
+	WalletService *WalletService
+	AuditService  *AuditService
+	FeeService    *FeeService
+	PromoService  *PromoService
+	RiskService   *RiskService
+	OperatorJWT   helpers.JWTConfig
+}
+
+// BootstrapOperator creates the first admin operator so the back office can
+// be used on a fresh database. It does nothing once any operator exists.
+func (s *AdminService) BootstrapOperator(ctx context.Context, username string, password string) error {
+	total, err := s.AdminRepo.CountOperators(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to count operators")
+	}
+
+	if total > 0 {
+		return nil
+	}
+
+	_, err = s.createOperator(ctx, models.CreateOperatorRequest{
+		Username: username,
+		Password: password,
+		Role:     constants.OperatorRoleAdmin,
+	})
+	return err
+}
+
+func (s *AdminService) Login(ctx context.Context, req models.OperatorLoginRequest) (models.OperatorLoginResponse, error) {
+	var (
+		resp models.OperatorLoginResponse
+	)
+
+	operator, err := s.AdminRepo.GetOperatorByUsername(ctx, req.Username)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return resp, errors.Wrap(err, "failed to get operator")
+	}
+
+	passwordHash := dummyPasswordHash
+	if operator.ID != 0 {
+		passwordHash = []byte(operator.PasswordHash)
+	}
+	passwordErr := bcrypt.CompareHashAndPassword(passwordHash, []byte(req.Password))
+
+	if operator.ID == 0 || operator.Status != "active" || passwordErr != nil {
+		username := req.Username
+		if len(username) > 100 {
+			username = username[:100]
+		}
+		actor := models.OperatorTokenData{Username: username}
+		if err := s.writeAuditLog(ctx, actor, "operator.login_failed", constants.AuditTargetOperator, username, nil); err != nil {
+			return resp, err
+		}
+		return resp, constants.ErrInvalidCredentials
+	}
+
+	token, expiredAt, err := helpers.GenerateOperatorToken(ctx, s.OperatorJWT, operator.ID, operator.Username, operator.Role, time.Now())
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to generate operator token")
+	}
+
+	actor := models.OperatorTokenData{
+		OperatorID: operator.ID,
+		Username:   operator.Username,
+		Role:       operator.Role,
+	}
+	if err := s.writeAuditLog(ctx, actor, "operator.login", constants.AuditTargetOperator, operator.Username, nil); err != nil {
+		return resp, err
+	}
+
+	resp.Token = token
+	resp.Role = operator.Role
+	resp.ExpiredAt = expiredAt
+
+	return resp, nil
+}
+
+func (s *AdminService) CreateOperator(ctx context.Context, actor models.OperatorTokenData, req models.CreateOperatorRequest) (models.Operator, error) {
+	operator, err := s.createOperator(ctx, req)
+	if err != nil {
+		return operator, err
+	}
+
+	detail := map[string]interface{}{
+		"role": operator.Role,
+	}
+	if err := s.writeAuditLog(ctx, actor, "operator.create", constants.AuditTargetOperator, operator.Username, detail); err != nil {
+		return operator, err
+	}
+
+	return operator, nil
+}
+
+func (s *AdminService) createOperator(ctx context.Context, req models.CreateOperatorRequest) (models.Operator, error) {
+	var (
+		resp models.Operator
+	)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to hash operator password")
+	}
+
+	resp = models.Operator{
+		Username:     req.Username,
+		PasswordHash: string(hash),
+		Role:         req.Role,
+		Status:       "active",
+	}
+
+	err = s.AdminRepo.CreateOperator(ctx, &resp)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to create operator")
+	}
+
+	return resp, nil
+}
+
+func (s *AdminService) SearchWallets(ctx context.Context, actor models.OperatorTokenData, param models.WalletSearchParam) ([]models.Wallet, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.WalletRepo.SearchWallets(ctx, param.UserID, param.Email, offset, limit)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to search wallets")
+	}
+
+	detail := map[string]interface{}{
+		"user_id": param.UserID,
+		"email":   param.Email,
+	}
+	if err := s.writeAuditLog(ctx, actor, "wallet.search", constants.AuditTargetWallet, "", detail); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (s *AdminService) GetWalletLedger(ctx context.Context, actor models.OperatorTokenData, walletID int, param models.WalletHistoryParam) ([]models.WalletTransaction, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.WalletRepo.GetWalletHistory(ctx, walletID, offset, limit, param.WalletTransactionType)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get wallet ledger")
+	}
+
+	if err := s.writeAuditLog(ctx, actor, "wallet.ledger_view", constants.AuditTargetWallet, strconv.Itoa(walletID), nil); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (s *AdminService) GetWalletStatusHistory(ctx context.Context, actor models.OperatorTokenData, walletID int) ([]models.WalletStatusHistory, error) {
+	resp, err := s.WalletService.GetWalletStatusHistory(ctx, walletID)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := s.writeAuditLog(ctx, actor, "wallet.status_history_view", constants.AuditTargetWallet, strconv.Itoa(walletID), nil); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ChangeWalletStatus is audited by the wallet service, which records the
+// status and balance around the change under the operator's name.
+func (s *AdminService) ChangeWalletStatus(ctx context.Context, actor models.OperatorTokenData, walletID int, action string, req models.WalletStatusRequest) (models.Wallet, error) {
+	return s.WalletService.ChangeWalletStatus(operatorContext(ctx, actor), walletID, action, actor.Username, req)
+}
+
+func (s *AdminService) ResendLinkOTP(ctx context.Context, actor models.OperatorTokenData, walletID int, clientSource string) (*models.WalletStructOTP, error) {
+	otp, err := generateOTP()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.WalletRepo.UpdateWalletLinkOTP(ctx, walletID, clientSource, otp)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update wallet link otp")
+	}
+
+	detail := map[string]interface{}{
+		"client_source": clientSource,
+	}
+	if err := s.writeAuditLog(ctx, actor, "wallet_link.resend_otp", constants.AuditTargetLink, strconv.Itoa(walletID), detail); err != nil {
+		return nil, err
+	}
+
+	return &models.WalletStructOTP{OTP: otp}, nil
+}
+
+func (s *AdminService) GetAuditLogs(ctx context.Context, actor models.OperatorTokenData, param models.AuditLogParam) ([]models.AuditLog, error) {
+	return s.AuditService.GetAuditLogs(ctx, param)
+}
+
+func (s *AdminService) writeAuditLog(ctx context.Context, actor models.OperatorTokenData, action string, targetType string, targetID string, detail interface{}) error {
+	return s.AuditService.Record(operatorContext(ctx, actor), action, targetType, targetID, nil, nil, detail)
+}
+
+// recordCommitted audits an operator action whose change is already
+// committed, a failed write is queued and never fails the action.
+func (s *AdminService) recordCommitted(ctx context.Context, actor models.OperatorTokenData, action string, targetType string, targetID string, detail interface{}) {
+	s.AuditService.RecordCommitted(operatorContext(ctx, actor), action, targetType, targetID, nil, nil, detail)
+}
+
+func operatorContext(ctx context.Context, actor models.OperatorTokenData) context.Context {
+	meta := helpers.GetRequestMeta(ctx)
+	meta.ActorType = constants.AuditActorOperator
+	meta.Actor = actor.Username
+	meta.ActorRole = actor.Role
+
+	return helpers.WithRequestMeta(ctx, meta)
+}
+
+func adminPagination(page int, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = defaultAdminPageLimit
+	}
+	if limit > maxAdminPageLimit {
+		limit = maxAdminPageLimit
+	}
+
+	return (page - 1) * limit, limit
+}
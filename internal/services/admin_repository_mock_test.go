@@ -0,0 +1,182 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_admin_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIAdminRepo is a mock of IAdminRepo interface.
+type MockIAdminRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAdminRepoMockRecorder
+}
+
+// MockIAdminRepoMockRecorder is the mock recorder for MockIAdminRepo.
+type MockIAdminRepoMockRecorder struct {
+	mock *MockIAdminRepo
+}
+
+// NewMockIAdminRepo creates a new mock instance.
+func NewMockIAdminRepo(ctrl *gomock.Controller) *MockIAdminRepo {
+	mock := &MockIAdminRepo{ctrl: ctrl}
+	mock.recorder = &MockIAdminRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAdminRepo) EXPECT() *MockIAdminRepoMockRecorder {
+	return m.recorder
+}
+
+// ApproveAdjustment mocks base method.
+func (m *MockIAdminRepo) ApproveAdjustment(ctx context.Context, adjustmentID, checkerID int, checkerUsername, note string, walletTrx *models.WalletTransaction, now time.Time) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveAdjustment", ctx, adjustmentID, checkerID, checkerUsername, note, walletTrx, now)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveAdjustment indicates an expected call of ApproveAdjustment.
+func (mr *MockIAdminRepoMockRecorder) ApproveAdjustment(ctx, adjustmentID, checkerID, checkerUsername, note, walletTrx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveAdjustment", reflect.TypeOf((*MockIAdminRepo)(nil).ApproveAdjustment), ctx, adjustmentID, checkerID, checkerUsername, note, walletTrx, now)
+}
+
+// CountOperators mocks base method.
+func (m *MockIAdminRepo) CountOperators(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOperators", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOperators indicates an expected call of CountOperators.
+func (mr *MockIAdminRepoMockRecorder) CountOperators(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOperators", reflect.TypeOf((*MockIAdminRepo)(nil).CountOperators), ctx)
+}
+
+// CreateAdjustment mocks base method.
+func (m *MockIAdminRepo) CreateAdjustment(ctx context.Context, adjustment *models.BalanceAdjustment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdjustment", ctx, adjustment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAdjustment indicates an expected call of CreateAdjustment.
+func (mr *MockIAdminRepoMockRecorder) CreateAdjustment(ctx, adjustment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdjustment", reflect.TypeOf((*MockIAdminRepo)(nil).CreateAdjustment), ctx, adjustment)
+}
+
+// CreateOperator mocks base method.
+func (m *MockIAdminRepo) CreateOperator(ctx context.Context, operator *models.Operator) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOperator", ctx, operator)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOperator indicates an expected call of CreateOperator.
+func (mr *MockIAdminRepoMockRecorder) CreateOperator(ctx, operator interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOperator", reflect.TypeOf((*MockIAdminRepo)(nil).CreateOperator), ctx, operator)
+}
+
+// DecideAdjustment mocks base method.
+func (m *MockIAdminRepo) DecideAdjustment(ctx context.Context, adjustmentID int, status string, checkerID int, checkerUsername, note string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideAdjustment", ctx, adjustmentID, status, checkerID, checkerUsername, note, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecideAdjustment indicates an expected call of DecideAdjustment.
+func (mr *MockIAdminRepoMockRecorder) DecideAdjustment(ctx, adjustmentID, status, checkerID, checkerUsername, note, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideAdjustment", reflect.TypeOf((*MockIAdminRepo)(nil).DecideAdjustment), ctx, adjustmentID, status, checkerID, checkerUsername, note, now)
+}
+
+// FailAdjustment mocks base method.
+func (m *MockIAdminRepo) FailAdjustment(ctx context.Context, adjustmentID, checkerID int, checkerUsername, note, reason string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailAdjustment", ctx, adjustmentID, checkerID, checkerUsername, note, reason, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailAdjustment indicates an expected call of FailAdjustment.
+func (mr *MockIAdminRepoMockRecorder) FailAdjustment(ctx, adjustmentID, checkerID, checkerUsername, note, reason, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailAdjustment", reflect.TypeOf((*MockIAdminRepo)(nil).FailAdjustment), ctx, adjustmentID, checkerID, checkerUsername, note, reason, now)
+}
+
+// GetAdjustmentByID mocks base method.
+func (m *MockIAdminRepo) GetAdjustmentByID(ctx context.Context, adjustmentID int) (models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdjustmentByID", ctx, adjustmentID)
+	ret0, _ := ret[0].(models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdjustmentByID indicates an expected call of GetAdjustmentByID.
+func (mr *MockIAdminRepoMockRecorder) GetAdjustmentByID(ctx, adjustmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdjustmentByID", reflect.TypeOf((*MockIAdminRepo)(nil).GetAdjustmentByID), ctx, adjustmentID)
+}
+
+// GetAdjustments mocks base method.
+func (m *MockIAdminRepo) GetAdjustments(ctx context.Context, param models.AdjustmentParam, offset, limit int) ([]models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdjustments", ctx, param, offset, limit)
+	ret0, _ := ret[0].([]models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdjustments indicates an expected call of GetAdjustments.
+func (mr *MockIAdminRepoMockRecorder) GetAdjustments(ctx, param, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdjustments", reflect.TypeOf((*MockIAdminRepo)(nil).GetAdjustments), ctx, param, offset, limit)
+}
+
+// GetOperatorByUsername mocks base method.
+func (m *MockIAdminRepo) GetOperatorByUsername(ctx context.Context, username string) (models.Operator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperatorByUsername", ctx, username)
+	ret0, _ := ret[0].(models.Operator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperatorByUsername indicates an expected call of GetOperatorByUsername.
+func (mr *MockIAdminRepoMockRecorder) GetOperatorByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperatorByUsername", reflect.TypeOf((*MockIAdminRepo)(nil).GetOperatorByUsername), ctx, username)
+}
+
+// UpdateAdjustmentStatus mocks base method.
+func (m *MockIAdminRepo) UpdateAdjustmentStatus(ctx context.Context, adjustmentID int, fromStatus, toStatus string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAdjustmentStatus", ctx, adjustmentID, fromStatus, toStatus)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAdjustmentStatus indicates an expected call of UpdateAdjustmentStatus.
+func (mr *MockIAdminRepoMockRecorder) UpdateAdjustmentStatus(ctx, adjustmentID, fromStatus, toStatus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdjustmentStatus", reflect.TypeOf((*MockIAdminRepo)(nil).UpdateAdjustmentStatus), ctx, adjustmentID, fromStatus, toStatus)
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func TestAdminService_Login(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAdminRepo := NewMockIAdminRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	operatorJWT := helpers.JWTConfig{Secret: "admin-secret"}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	operator := models.Operator{
+		ID:           1,
+		Username:     "operator",
+		PasswordHash: string(hash),
+		Role:         constants.OperatorRoleFinance,
+		Status:       "active",
+	}
+
+	type args struct {
+		ctx context.Context
+		req models.OperatorLoginRequest
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+		mockFn  func(args args)
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx: context.Background(),
+				req: models.OperatorLoginRequest{
+					Username: "operator",
+					Password: "correct-password",
+				},
+			},
+			mockFn: func(args args) {
+				mockAdminRepo.EXPECT().GetOperatorByUsername(args.ctx, "operator").Return(operator, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "operator.login", auditLog.Action)
+					assert.Equal(t, constants.OperatorRoleFinance, auditLog.ActorRole)
+					return nil
+				})
+			},
+		},
+		{
+			name: "error wrong password",
+			args: args{
+				ctx: context.Background(),
+				req: models.OperatorLoginRequest{
+					Username: "operator",
+					Password: "wrong-password",
+				},
+			},
+			wantErr: constants.ErrInvalidCredentials,
+			mockFn: func(args args) {
+				mockAdminRepo.EXPECT().GetOperatorByUsername(args.ctx, "operator").Return(operator, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "operator.login_failed", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name: "error unknown operator",
+			args: args{
+				ctx: context.Background(),
+				req: models.OperatorLoginRequest{
+					Username: "unknown",
+					Password: "correct-password",
+				},
+			},
+			wantErr: constants.ErrInvalidCredentials,
+			mockFn: func(args args) {
+				mockAdminRepo.EXPECT().GetOperatorByUsername(args.ctx, "unknown").Return(models.Operator{}, gorm.ErrRecordNotFound)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "error get operator",
+			args: args{
+				ctx: context.Background(),
+				req: models.OperatorLoginRequest{
+					Username: "operator",
+					Password: "correct-password",
+				},
+			},
+			wantErr: assert.AnError,
+			mockFn: func(args args) {
+				mockAdminRepo.EXPECT().GetOperatorByUsername(args.ctx, "operator").Return(models.Operator{}, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.args)
+			s := &AdminService{
+				AdminRepo:    mockAdminRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				OperatorJWT:  operatorJWT,
+			}
+			got, err := s.Login(tt.args.ctx, tt.args.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got.Token)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, got.Token)
+
+			claim, err := helpers.ValidateOperatorToken(tt.args.ctx, operatorJWT, got.Token)
+			assert.NoError(t, err)
+			assert.Equal(t, operator.Role, claim.Role)
+		})
+	}
+}
+
+func TestAdminService_DummyPasswordHash(t *testing.T) {
+	cost, err := bcrypt.Cost(dummyPasswordHash)
+	assert.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
+
+func TestAdminService_ChangeWalletStatus(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAdminRepo := NewMockIAdminRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+
+	actor := models.OperatorTokenData{
+		OperatorID: 1,
+		Username:   "operator",
+		Role:       constants.OperatorRoleSupport,
+	}
+
+	type args struct {
+		ctx      context.Context
+		walletID int
+		action   string
+		req      models.WalletStatusRequest
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mockFn  func(args args)
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx:      context.Background(),
+				walletID: 1,
+				action:   constants.WalletActionFreeze,
+				req: models.WalletStatusRequest{
+					ReasonCode: "FRAUD_SUSPECTED",
+				},
+			},
+			wantErr: false,
+			mockFn: func(args args) {
+				mockRepo.EXPECT().UpdateWalletStatus(gomock.Any(), args.walletID, []string{constants.WalletStatusActive}, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, walletID int, fromStatuses []string, history *models.WalletStatusHistory) (models.Wallet, error) {
+						assert.Equal(t, actor.Username, history.ChangedBy)
+						history.FromStatus = constants.WalletStatusActive
+						return models.Wallet{ID: 1, Status: constants.WalletStatusFrozen}, nil
+					})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "wallet.freeze", auditLog.Action)
+					assert.Equal(t, "1", auditLog.TargetID)
+					assert.Equal(t, constants.AuditActorOperator, auditLog.ActorType)
+					assert.Equal(t, actor.Username, auditLog.Actor)
+					assert.Equal(t, `{"status":"active"}`, auditLog.Before)
+					assert.Equal(t, `{"status":"frozen"}`, auditLog.After)
+					assert.Contains(t, auditLog.Detail, "FRAUD_SUSPECTED")
+					return nil
+				})
+			},
+		},
+		{
+			name: "error audit log",
+			args: args{
+				ctx:      context.Background(),
+				walletID: 1,
+				action:   constants.WalletActionFreeze,
+				req: models.WalletStatusRequest{
+					ReasonCode: "FRAUD_SUSPECTED",
+				},
+			},
+			wantErr: true,
+			mockFn: func(args args) {
+				mockRepo.EXPECT().UpdateWalletStatus(gomock.Any(), args.walletID, []string{constants.WalletStatusActive}, gomock.Any()).
+					Return(models.Wallet{ID: 1, Status: constants.WalletStatusFrozen}, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(assert.AnError)
+			},
+		},
+		{
+			name: "error update status",
+			args: args{
+				ctx:      context.Background(),
+				walletID: 1,
+				action:   constants.WalletActionFreeze,
+				req: models.WalletStatusRequest{
+					ReasonCode: "FRAUD_SUSPECTED",
+				},
+			},
+			wantErr: true,
+			mockFn: func(args args) {
+				mockRepo.EXPECT().UpdateWalletStatus(gomock.Any(), args.walletID, []string{constants.WalletStatusActive}, gomock.Any()).
+					Return(models.Wallet{}, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.args)
+			s := &AdminService{
+				AdminRepo:    mockAdminRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				WalletRepo:   mockRepo,
+				WalletService: &WalletService{
+					WalletRepo:   mockRepo,
+					AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				},
+			}
+			_, err := s.ChangeWalletStatus(tt.args.ctx, actor, tt.args.walletID, tt.args.action, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AdminService.ChangeWalletStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdminService_ResendLinkOTP(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAdminRepo := NewMockIAdminRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+
+	actor := models.OperatorTokenData{
+		OperatorID: 1,
+		Username:   "operator",
+		Role:       constants.OperatorRoleSupport,
+	}
+	clientSource := "fastcampus_ecommerce"
+
+	tests := []struct {
+		name    string
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name:    "success",
+			wantErr: false,
+			mockFn: func() {
+				mockRepo.EXPECT().UpdateWalletLinkOTP(gomock.Any(), 1, clientSource, gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "wallet_link.resend_otp", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error link not pending",
+			wantErr: true,
+			mockFn: func() {
+				mockRepo.EXPECT().UpdateWalletLinkOTP(gomock.Any(), 1, clientSource, gomock.Any()).Return(constants.ErrLinkNotPending)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &AdminService{
+				AdminRepo:    mockAdminRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				WalletRepo:   mockRepo,
+			}
+			got, err := s.ResendLinkOTP(context.Background(), actor, 1, clientSource)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AdminService.ResendLinkOTP() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Len(t, got.OTP, 6)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	auditVerifyBatchSize = 500
+	auditRetryQueueSize  = 10000
+)
+
+type AuditService struct {
+	AuditRepo i_repository.IAuditRepo
+
+	mu      sync.Mutex
+	pending []*models.AuditLog
+}
+
+// Record appends an entry to the audit chain. The actor, request id and ip
+// are taken from the request meta in ctx, before and after hold the state of
+// the target around the change and may be nil.
+func (s *AuditService) Record(ctx context.Context, action string, targetType string, targetID string, before interface{}, after interface{}, detail interface{}) error {
+	auditLog, err := newAuditLog(ctx, action, targetType, targetID, before, after, detail)
+	if err != nil {
+		return err
+	}
+
+	err = s.AuditRepo.AppendAuditLog(ctx, auditLog)
+	if err != nil {
+		return errors.Wrap(err, "failed to write audit log")
+	}
+
+	return nil
+}
+
+// RecordCommitted is Record for a change that is already committed, so it
+// never fails the caller: an entry that cannot be written now is queued and
+// written later by RunRetryWorker, keeping its actor and time.
+func (s *AuditService) RecordCommitted(ctx context.Context, action string, targetType string, targetID string, before interface{}, after interface{}, detail interface{}) {
+	auditLog, err := newAuditLog(ctx, action, targetType, targetID, before, after, detail)
+	if err != nil {
+		log.Printf("failed to build audit log %s of %s %s: %v\n", action, targetType, targetID, err)
+		return
+	}
+
+	err = s.AuditRepo.AppendAuditLog(ctx, auditLog)
+	if err != nil {
+		log.Printf("failed to write audit log %s of %s %s, queued for retry: %v\n", action, targetType, targetID, err)
+		s.queue(auditLog)
+	}
+}
+
+// RunRetryWorker writes the queued audit entries every interval. On shutdown
+// it makes a last attempt, whatever is still left is logged.
+func (s *AuditService) RunRetryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			written := s.RetryPending(context.Background())
+			if written > 0 {
+				log.Printf("%d queued audit logs written\n", written)
+			}
+			s.dropPending()
+			return
+		case <-ticker.C:
+		}
+
+		written := s.RetryPending(ctx)
+		if written > 0 {
+			log.Printf("%d queued audit logs written\n", written)
+		}
+	}
+}
+
+// RetryPending writes the queued entries in the order they were queued and
+// stops at the first one that still fails. It returns how many were written.
+func (s *AuditService) RetryPending(ctx context.Context) int {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	written := 0
+	for i, auditLog := range pending {
+		err := s.AuditRepo.AppendAuditLog(ctx, auditLog)
+		if err != nil {
+			log.Println("failed to write queued audit log: ", err)
+			s.requeue(pending[i:])
+			break
+		}
+		written++
+	}
+
+	return written
+}
+
+// Pending returns how many audit entries wait to be written.
+func (s *AuditService) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.pending)
+}
+
+func (s *AuditService) queue(auditLog *models.AuditLog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) >= auditRetryQueueSize {
+		logLostAuditLog(auditLog)
+		return
+	}
+	s.pending = append(s.pending, auditLog)
+}
+
+// requeue puts entries that failed again in front of the ones queued since.
+func (s *AuditService) requeue(auditLogs []*models.AuditLog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := append(append([]*models.AuditLog{}, auditLogs...), s.pending...)
+	for len(pending) > auditRetryQueueSize {
+		logLostAuditLog(pending[len(pending)-1])
+		pending = pending[:len(pending)-1]
+	}
+	s.pending = pending
+}
+
+func (s *AuditService) dropPending() {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	for _, auditLog := range pending {
+		logLostAuditLog(auditLog)
+	}
+}
+
+// logLostAuditLog writes an entry that could not be kept to the log in full,
+// so it can still be restored from there.
+func logLostAuditLog(auditLog *models.AuditLog) {
+	payload, _ := json.Marshal(auditLog)
+	log.Printf("audit log lost: %s\n", payload)
+}
+
+func newAuditLog(ctx context.Context, action string, targetType string, targetID string, before interface{}, after interface{}, detail interface{}) (*models.AuditLog, error) {
+	meta := helpers.GetRequestMeta(ctx)
+	if meta.ActorType == "" {
+		meta.ActorType = constants.AuditActorSystem
+	}
+
+	auditLog := &models.AuditLog{
+		ActorType:  meta.ActorType,
+		Actor:      meta.Actor,
+		ActorRole:  meta.ActorRole,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		RequestID:  meta.RequestID,
+		IP:         meta.IP,
+		CreatedAt:  time.Now().Truncate(time.Second),
+	}
+
+	var err error
+	if auditLog.Before, err = marshalAuditValue(before); err != nil {
+		return nil, err
+	}
+	if auditLog.After, err = marshalAuditValue(after); err != nil {
+		return nil, err
+	}
+	if auditLog.Detail, err = marshalAuditValue(detail); err != nil {
+		return nil, err
+	}
+
+	return auditLog, nil
+}
+
+func (s *AuditService) GetAuditLogs(ctx context.Context, param models.AuditLogParam) ([]models.AuditLog, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.AuditRepo.GetAuditLogs(ctx, param, offset, limit)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get audit logs")
+	}
+
+	return resp, nil
+}
+
+// VerifyChain walks the whole audit log in id order and recomputes every
+// hash. It stops at the first entry that does not match.
+func (s *AuditService) VerifyChain(ctx context.Context) (models.AuditVerifyResult, error) {
+	var (
+		resp     models.AuditVerifyResult
+		prevHash string
+		lastID   int
+	)
+
+	for {
+		entries, err := s.AuditRepo.GetAuditLogChain(ctx, lastID, auditVerifyBatchSize)
+		if err != nil {
+			return resp, errors.Wrap(err, "failed to get audit log chain")
+		}
+
+		for _, entry := range entries {
+			resp.Checked++
+
+			if entry.PrevHash != prevHash {
+				resp.BrokenAtID = entry.ID
+				resp.BrokenCause = fmt.Sprintf("prev_hash %q does not match previous entry hash %q", entry.PrevHash, prevHash)
+				return resp, nil
+			}
+
+			if entry.ComputeHash() != entry.Hash {
+				resp.BrokenAtID = entry.ID
+				resp.BrokenCause = "entry content does not match its hash"
+				return resp, nil
+			}
+
+			prevHash = entry.Hash
+			lastID = entry.ID
+		}
+
+		if len(entries) < auditVerifyBatchSize {
+			break
+		}
+	}
+
+	resp.Valid = true
+
+	return resp, nil
+}
+
+func marshalAuditValue(value interface{}) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal audit value")
+	}
+
+	return string(payload), nil
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_audit_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIAuditRepo is a mock of IAuditRepo interface.
+type MockIAuditRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAuditRepoMockRecorder
+}
+
+// MockIAuditRepoMockRecorder is the mock recorder for MockIAuditRepo.
+type MockIAuditRepoMockRecorder struct {
+	mock *MockIAuditRepo
+}
+
+// NewMockIAuditRepo creates a new mock instance.
+func NewMockIAuditRepo(ctrl *gomock.Controller) *MockIAuditRepo {
+	mock := &MockIAuditRepo{ctrl: ctrl}
+	mock.recorder = &MockIAuditRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAuditRepo) EXPECT() *MockIAuditRepoMockRecorder {
+	return m.recorder
+}
+
+// AppendAuditLog mocks base method.
+func (m *MockIAuditRepo) AppendAuditLog(ctx context.Context, auditLog *models.AuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendAuditLog", ctx, auditLog)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendAuditLog indicates an expected call of AppendAuditLog.
+func (mr *MockIAuditRepoMockRecorder) AppendAuditLog(ctx, auditLog interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAuditLog", reflect.TypeOf((*MockIAuditRepo)(nil).AppendAuditLog), ctx, auditLog)
+}
+
+// GetAuditLogChain mocks base method.
+func (m *MockIAuditRepo) GetAuditLogChain(ctx context.Context, afterID, limit int) ([]models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogChain", ctx, afterID, limit)
+	ret0, _ := ret[0].([]models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogChain indicates an expected call of GetAuditLogChain.
+func (mr *MockIAuditRepoMockRecorder) GetAuditLogChain(ctx, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogChain", reflect.TypeOf((*MockIAuditRepo)(nil).GetAuditLogChain), ctx, afterID, limit)
+}
+
+// GetAuditLogs mocks base method.
+func (m *MockIAuditRepo) GetAuditLogs(ctx context.Context, param models.AuditLogParam, offset, limit int) ([]models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogs", ctx, param, offset, limit)
+	ret0, _ := ret[0].([]models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogs indicates an expected call of GetAuditLogs.
+func (mr *MockIAuditRepoMockRecorder) GetAuditLogs(ctx, param, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogs", reflect.TypeOf((*MockIAuditRepo)(nil).GetAuditLogs), ctx, param, offset, limit)
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditService_Record(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	requestCtx := helpers.WithRequestMeta(context.Background(), helpers.RequestMeta{
+		RequestID: "request-id",
+		IP:        "10.0.0.1",
+		ActorType: constants.AuditActorClient,
+		Actor:     "fastcampus_ecommerce",
+	})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name:    "success with request meta",
+			ctx:     requestCtx,
+			wantErr: false,
+			mockFn: func() {
+				mockAuditRepo.EXPECT().AppendAuditLog(requestCtx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, constants.AuditActorClient, auditLog.ActorType)
+					assert.Equal(t, "fastcampus_ecommerce", auditLog.Actor)
+					assert.Equal(t, "request-id", auditLog.RequestID)
+					assert.Equal(t, "10.0.0.1", auditLog.IP)
+					assert.Equal(t, `{"balance":100}`, auditLog.Before)
+					assert.Equal(t, `{"balance":50}`, auditLog.After)
+					assert.Empty(t, auditLog.Detail)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "success without request meta",
+			ctx:     context.Background(),
+			wantErr: false,
+			mockFn: func() {
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, constants.AuditActorSystem, auditLog.ActorType)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error",
+			ctx:     context.Background(),
+			wantErr: true,
+			mockFn: func() {
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &AuditService{
+				AuditRepo: mockAuditRepo,
+			}
+			err := s.Record(tt.ctx, "balance.debit", constants.AuditTargetWallet, "1",
+				map[string]interface{}{"balance": 100}, map[string]interface{}{"balance": 50}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AuditService.Record() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuditService_RecordCommitted(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	requestCtx := helpers.WithRequestMeta(context.Background(), helpers.RequestMeta{
+		RequestID: "request-id",
+		ActorType: constants.AuditActorClient,
+		Actor:     "fastcampus_ecommerce",
+	})
+
+	s := &AuditService{
+		AuditRepo: mockAuditRepo,
+	}
+
+	var queued *models.AuditLog
+	mockAuditRepo.EXPECT().AppendAuditLog(requestCtx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+		queued = auditLog
+		return assert.AnError
+	})
+	s.RecordCommitted(requestCtx, "balance.debit", constants.AuditTargetWallet, "1",
+		map[string]interface{}{"balance": 100}, map[string]interface{}{"balance": 50}, nil)
+	assert.Equal(t, 1, s.Pending())
+
+	// later changes are written straight away, the queued entry waits for the retry
+	mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+	s.RecordCommitted(context.Background(), "balance.credit", constants.AuditTargetWallet, "2", nil, nil, nil)
+
+	mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), queued).Return(assert.AnError)
+	assert.Equal(t, 0, s.RetryPending(context.Background()))
+	assert.Equal(t, 1, s.Pending())
+
+	mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+		assert.Same(t, queued, auditLog)
+		assert.Equal(t, "fastcampus_ecommerce", auditLog.Actor)
+		assert.Equal(t, "request-id", auditLog.RequestID)
+		assert.Equal(t, `{"balance":50}`, auditLog.After)
+		return nil
+	})
+	assert.Equal(t, 1, s.RetryPending(context.Background()))
+	assert.Equal(t, 0, s.Pending())
+}
+
+func TestAuditService_VerifyChain(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	buildChain := func() []models.AuditLog {
+		now := time.Now().Truncate(time.Second)
+		chain := []models.AuditLog{
+			{ID: 1, ActorType: "user", Actor: "1", Action: "balance.credit", Before: `{"balance":0}`, After: `{"balance":100}`, CreatedAt: now},
+			{ID: 2, ActorType: "client", Actor: "fastcampus_ecommerce", Action: "balance.debit", Before: `{"balance":100}`, After: `{"balance":40}`, CreatedAt: now},
+			{ID: 3, ActorType: "operator", Actor: "operator", Action: "wallet.freeze", Before: `{"status":"active"}`, After: `{"status":"frozen"}`, CreatedAt: now},
+		}
+		prevHash := ""
+		for i := range chain {
+			chain[i].PrevHash = prevHash
+			chain[i].Hash = chain[i].ComputeHash()
+			prevHash = chain[i].Hash
+		}
+		return chain
+	}
+
+	tests := []struct {
+		name   string
+		chain  func() []models.AuditLog
+		want   models.AuditVerifyResult
+		wantFn func(t *testing.T, got models.AuditVerifyResult)
+	}{
+		{
+			name:  "valid chain",
+			chain: buildChain,
+			want:  models.AuditVerifyResult{Checked: 3, Valid: true},
+		},
+		{
+			name: "tampered entry",
+			chain: func() []models.AuditLog {
+				chain := buildChain()
+				chain[1].After = `{"balance":400}`
+				return chain
+			},
+			want: models.AuditVerifyResult{Checked: 2, BrokenAtID: 2, BrokenCause: "entry content does not match its hash"},
+		},
+		{
+			name: "deleted entry",
+			chain: func() []models.AuditLog {
+				chain := buildChain()
+				return append(chain[:1], chain[2:]...)
+			},
+			wantFn: func(t *testing.T, got models.AuditVerifyResult) {
+				assert.False(t, got.Valid)
+				assert.Equal(t, 3, got.BrokenAtID)
+				assert.Contains(t, got.BrokenCause, "prev_hash")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuditRepo.EXPECT().GetAuditLogChain(gomock.Any(), 0, auditVerifyBatchSize).Return(tt.chain(), nil)
+
+			s := &AuditService{
+				AuditRepo: mockAuditRepo,
+			}
+			got, err := s.VerifyChain(context.Background())
+			assert.NoError(t, err)
+			if tt.wantFn != nil {
+				tt.wantFn(t, got)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// FeeService prices operations from the fee rules and books the fees on the
+// revenue wallet. A nil FeeService charges nothing.
+type FeeService struct {
+	FeeRepo         i_repository.IFeeRepo
+	WalletRepo      i_repository.IWalletRepo
+	AuditService    *AuditService
+	RevenueWalletID int
+}
+
+// EnsureRevenueWallet finds the wallet collecting the fees, and creates it on
+// a fresh database. It is a system wallet, so no request can move it and
+// only the fee postings credit it.
+func (s *FeeService) EnsureRevenueWallet(ctx context.Context) error {
+	wallet, err := s.WalletRepo.GetWalletByUserID(ctx, constants.FeeRevenueUserID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrap(err, "failed to get fee revenue wallet")
+		}
+
+		wallet = models.Wallet{UserID: constants.FeeRevenueUserID, Type: constants.WalletTypeSystem}
+		err = s.WalletRepo.CreateWallet(ctx, &wallet)
+		if err != nil {
+			return errors.Wrap(err, "failed to create fee revenue wallet")
+		}
+	}
+
+	s.RevenueWalletID = wallet.ID
+	return nil
+}
+
+// Quote prices an operation for a wallet. A transfer the free quota covers
+// is quoted free, TransferPosting books it against the quota.
+func (s *FeeService) Quote(ctx context.Context, operation string, clientSource string, walletID int, amount float64) (models.FeeQuote, error) {
+	resp := models.FeeQuote{
+		Operation: operation,
+		Amount:    amount,
+		Total:     amount,
+	}
+
+	if s == nil {
+		return resp, nil
+	}
+
+	rules, err := s.FeeRepo.GetActiveFeeRules(ctx, operation)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get fee rules")
+	}
+
+	if len(rules) == 0 {
+		return resp, nil
+	}
+
+	wallet, err := s.WalletRepo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get wallet")
+	}
+
+	rule, ok := matchFeeRule(rules, clientSource, wallet.Tier)
+	if !ok {
+		return resp, nil
+	}
+	resp.RuleID = rule.ID
+
+	if rule.FreeQuota > 0 {
+		used, err := s.FeeRepo.CountPaidTransfers(ctx, walletID, monthStart(time.Now()))
+		if err != nil {
+			return resp, errors.Wrap(err, "failed to count transfers")
+		}
+
+		if used < rule.FreeQuota {
+			resp.FreeQuotaLeft = rule.FreeQuota - used
+			resp.FreeQuota = rule.FreeQuota
+			resp.QuotaFee = calculateFee(rule, amount)
+			return resp, nil
+		}
+	}
+
+	resp.Fee = calculateFee(rule, amount)
+	resp.Total = amount + resp.Fee
+
+	return resp, nil
+}
+
+// Posting builds the ledger rows of a fee paid by walletID, nil when there is
+// no fee. The rows are linked to the principal when they are booked.
+func (s *FeeService) Posting(walletID int, clientID string, amount float64) *models.FeePosting {
+	if s == nil || amount <= 0 {
+		return nil
+	}
+
+	return &models.FeePosting{
+		Fee: &models.WalletTransaction{
+			WalletID:              walletID,
+			Amount:                amount,
+			WalletTransactionType: constants.TransactionTypeFee,
+			ClientID:              clientID,
+		},
+		Revenue: &models.WalletTransaction{
+			WalletID:              s.RevenueWalletID,
+			Amount:                amount,
+			WalletTransactionType: constants.TransactionTypeCredit,
+		},
+	}
+}
+
+// TransferPosting is the posting of a quoted P2P transfer. A transfer quoted
+// free carries the quota, the booking transaction counts the paid transfers
+// under the payer's lock and charges the fee if the quota is used up.
+func (s *FeeService) TransferPosting(walletID int, quote models.FeeQuote, now time.Time) *models.FeePosting {
+	if quote.FreeQuota == 0 {
+		return s.Posting(walletID, "", quote.Fee)
+	}
+
+	fee := s.Posting(walletID, "", quote.QuotaFee)
+	if fee != nil {
+		fee.FreeQuota = quote.FreeQuota
+		fee.QuotaSince = monthStart(now)
+	}
+
+	return fee
+}
+
+func (s *FeeService) CreateFeeRule(ctx context.Context, createdBy string, req models.FeeRuleRequest) (models.FeeRule, error) {
+	var (
+		resp models.FeeRule
+	)
+
+	err := checkFeeRule(req)
+	if err != nil {
+		return resp, err
+	}
+
+	resp = models.FeeRule{
+		Operation:    req.Operation,
+		ClientSource: req.ClientSource,
+		WalletTier:   req.WalletTier,
+		FeeType:      req.FeeType,
+		FlatAmount:   req.FlatAmount,
+		Percentage:   req.Percentage,
+		Tiers:        req.Tiers,
+		MinFee:       req.MinFee,
+		MaxFee:       req.MaxFee,
+		FreeQuota:    req.FreeQuota,
+		Active:       true,
+		CreatedBy:    createdBy,
+	}
+
+	err = s.FeeRepo.CreateFeeRule(ctx, &resp)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to create fee rule")
+	}
+
+	err = s.AuditService.Record(ctx, "fee_rule.create", constants.AuditTargetFeeRule, strconv.Itoa(resp.ID), nil, resp, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+func (s *FeeService) GetFeeRules(ctx context.Context, param models.FeeRuleParam) ([]models.FeeRule, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.FeeRepo.GetFeeRules(ctx, param.Operation, offset, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get fee rules")
+	}
+
+	return resp, nil
+}
+
+// DisableFeeRule stops a rule from applying. Rules are never edited, a new
+// rule replaces a disabled one so the audit log keeps every schedule.
+func (s *FeeService) DisableFeeRule(ctx context.Context, ruleID int) error {
+	err := s.FeeRepo.DisableFeeRule(ctx, ruleID, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to disable fee rule")
+	}
+
+	return s.AuditService.Record(ctx, "fee_rule.disable", constants.AuditTargetFeeRule, strconv.Itoa(ruleID),
+		map[string]interface{}{"active": true}, map[string]interface{}{"active": false}, nil)
+}
+
+func (s *AdminService) CreateFeeRule(ctx context.Context, actor models.OperatorTokenData, req models.FeeRuleRequest) (models.FeeRule, error) {
+	return s.FeeService.CreateFeeRule(operatorContext(ctx, actor), actor.Username, req)
+}
+
+func (s *AdminService) GetFeeRules(ctx context.Context, actor models.OperatorTokenData, param models.FeeRuleParam) ([]models.FeeRule, error) {
+	return s.FeeService.GetFeeRules(ctx, param)
+}
+
+func (s *AdminService) DisableFeeRule(ctx context.Context, actor models.OperatorTokenData, ruleID int) error {
+	return s.FeeService.DisableFeeRule(operatorContext(ctx, actor), ruleID)
+}
+
+func checkFeeRule(req models.FeeRuleRequest) error {
+	if req.ClientSource != "" && req.Operation != constants.FeeOperationExternalDebit {
+		return fmt.Errorf("%w: client source only applies to external debits", constants.ErrInvalidFeeRule)
+	}
+
+	if req.FreeQuota > 0 && req.Operation != constants.FeeOperationP2PTransfer {
+		return fmt.Errorf("%w: free quota only applies to p2p transfers", constants.ErrInvalidFeeRule)
+	}
+
+	if req.FeeType != constants.FeeTypeTiered {
+		if len(req.Tiers) > 0 {
+			return fmt.Errorf("%w: tiers only apply to tiered fees", constants.ErrInvalidFeeRule)
+		}
+		return nil
+	}
+
+	if len(req.Tiers) == 0 {
+		return fmt.Errorf("%w: tiered fee without tiers", constants.ErrInvalidFeeRule)
+	}
+
+	// tiers go up and the last one covers everything above
+	last := len(req.Tiers) - 1
+	for i, tier := range req.Tiers {
+		if i == last {
+			if tier.UpTo != 0 {
+				return fmt.Errorf("%w: the last tier can not have an upper bound", constants.ErrInvalidFeeRule)
+			}
+			continue
+		}
+
+		if tier.UpTo <= 0 || (i > 0 && tier.UpTo <= req.Tiers[i-1].UpTo) {
+			return fmt.Errorf("%w: tier %d must end above the previous one", constants.ErrInvalidFeeRule, i+1)
+		}
+	}
+
+	return nil
+}
+
+// matchFeeRule picks the most specific rule, a client match weighs more than a
+// tier match and the oldest rule wins a tie.
+func matchFeeRule(rules []models.FeeRule, clientSource string, tier string) (models.FeeRule, bool) {
+	if tier == "" {
+		tier = constants.WalletTierBasic
+	}
+
+	best, bestScore := -1, -1
+	for i, rule := range rules {
+		score := 0
+		if rule.ClientSource != "" {
+			if rule.ClientSource != clientSource {
+				continue
+			}
+			score += 2
+		}
+		if rule.WalletTier != "" {
+			if rule.WalletTier != tier {
+				continue
+			}
+			score++
+		}
+
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	if best < 0 {
+		return models.FeeRule{}, false
+	}
+
+	return rules[best], true
+}
+
+func calculateFee(rule models.FeeRule, amount float64) float64 {
+	var fee float64
+	switch rule.FeeType {
+	case constants.FeeTypeFlat:
+		fee = rule.FlatAmount
+	case constants.FeeTypePercentage:
+		fee = amount * rule.Percentage / 100
+	case constants.FeeTypeTiered:
+		for _, tier := range rule.Tiers {
+			if tier.UpTo == 0 || amount <= tier.UpTo {
+				fee = tier.FlatAmount + amount*tier.Percentage/100
+				break
+			}
+		}
+	}
+
+	if fee < rule.MinFee {
+		fee = rule.MinFee
+	}
+	if rule.MaxFee > 0 && fee > rule.MaxFee {
+		fee = rule.MaxFee
+	}
+
+	// balances are stored with two decimals
+	return math.Round(fee*100) / 100
+}
+
+func monthStart(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
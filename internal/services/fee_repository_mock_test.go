@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_fee_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIFeeRepo is a mock of IFeeRepo interface.
+type MockIFeeRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIFeeRepoMockRecorder
+}
+
+// MockIFeeRepoMockRecorder is the mock recorder for MockIFeeRepo.
+type MockIFeeRepoMockRecorder struct {
+	mock *MockIFeeRepo
+}
+
+// NewMockIFeeRepo creates a new mock instance.
+func NewMockIFeeRepo(ctrl *gomock.Controller) *MockIFeeRepo {
+	mock := &MockIFeeRepo{ctrl: ctrl}
+	mock.recorder = &MockIFeeRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIFeeRepo) EXPECT() *MockIFeeRepoMockRecorder {
+	return m.recorder
+}
+
+// CountPaidTransfers mocks base method.
+func (m *MockIFeeRepo) CountPaidTransfers(ctx context.Context, walletID int, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPaidTransfers", ctx, walletID, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPaidTransfers indicates an expected call of CountPaidTransfers.
+func (mr *MockIFeeRepoMockRecorder) CountPaidTransfers(ctx, walletID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPaidTransfers", reflect.TypeOf((*MockIFeeRepo)(nil).CountPaidTransfers), ctx, walletID, since)
+}
+
+// CreateFeeRule mocks base method.
+func (m *MockIFeeRepo) CreateFeeRule(ctx context.Context, rule *models.FeeRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFeeRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFeeRule indicates an expected call of CreateFeeRule.
+func (mr *MockIFeeRepoMockRecorder) CreateFeeRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeeRule", reflect.TypeOf((*MockIFeeRepo)(nil).CreateFeeRule), ctx, rule)
+}
+
+// DisableFeeRule mocks base method.
+func (m *MockIFeeRepo) DisableFeeRule(ctx context.Context, ruleID int, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableFeeRule", ctx, ruleID, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableFeeRule indicates an expected call of DisableFeeRule.
+func (mr *MockIFeeRepoMockRecorder) DisableFeeRule(ctx, ruleID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableFeeRule", reflect.TypeOf((*MockIFeeRepo)(nil).DisableFeeRule), ctx, ruleID, now)
+}
+
+// GetActiveFeeRules mocks base method.
+func (m *MockIFeeRepo) GetActiveFeeRules(ctx context.Context, operation string) ([]models.FeeRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveFeeRules", ctx, operation)
+	ret0, _ := ret[0].([]models.FeeRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveFeeRules indicates an expected call of GetActiveFeeRules.
+func (mr *MockIFeeRepoMockRecorder) GetActiveFeeRules(ctx, operation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveFeeRules", reflect.TypeOf((*MockIFeeRepo)(nil).GetActiveFeeRules), ctx, operation)
+}
+
+// GetFeeRules mocks base method.
+func (m *MockIFeeRepo) GetFeeRules(ctx context.Context, operation string, offset, limit int) ([]models.FeeRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeRules", ctx, operation, offset, limit)
+	ret0, _ := ret[0].([]models.FeeRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeRules indicates an expected call of GetFeeRules.
+func (mr *MockIFeeRepoMockRecorder) GetFeeRules(ctx, operation, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeRules", reflect.TypeOf((*MockIFeeRepo)(nil).GetFeeRules), ctx, operation, offset, limit)
+}
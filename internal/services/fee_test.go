@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_calculateFee(t *testing.T) {
+	tiered := models.FeeTiers{
+		{UpTo: 100000, FlatAmount: 1000},
+		{UpTo: 1000000, Percentage: 1},
+		{FlatAmount: 5000, Percentage: 0.5},
+	}
+
+	tests := []struct {
+		name   string
+		rule   models.FeeRule
+		amount float64
+		want   float64
+	}{
+		{
+			name:   "flat",
+			rule:   models.FeeRule{FeeType: constants.FeeTypeFlat, FlatAmount: 2500},
+			amount: 100000,
+			want:   2500,
+		},
+		{
+			name:   "percentage rounded to two decimals",
+			rule:   models.FeeRule{FeeType: constants.FeeTypePercentage, Percentage: 0.7},
+			amount: 12345,
+			want:   86.42,
+		},
+		{
+			name:   "percentage raised to the minimum",
+			rule:   models.FeeRule{FeeType: constants.FeeTypePercentage, Percentage: 1, MinFee: 500},
+			amount: 10000,
+			want:   500,
+		},
+		{
+			name:   "percentage capped at the maximum",
+			rule:   models.FeeRule{FeeType: constants.FeeTypePercentage, Percentage: 1, MaxFee: 5000},
+			amount: 1000000,
+			want:   5000,
+		},
+		{
+			name:   "tiered first tier",
+			rule:   models.FeeRule{FeeType: constants.FeeTypeTiered, Tiers: tiered},
+			amount: 100000,
+			want:   1000,
+		},
+		{
+			name:   "tiered middle tier",
+			rule:   models.FeeRule{FeeType: constants.FeeTypeTiered, Tiers: tiered},
+			amount: 500000,
+			want:   5000,
+		},
+		{
+			name:   "tiered unbounded tier",
+			rule:   models.FeeRule{FeeType: constants.FeeTypeTiered, Tiers: tiered},
+			amount: 2000000,
+			want:   15000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, calculateFee(tt.rule, tt.amount))
+		})
+	}
+}
+
+func Test_matchFeeRule(t *testing.T) {
+	generic := models.FeeRule{ID: 1}
+	premium := models.FeeRule{ID: 2, WalletTier: constants.WalletTierPremium}
+	client := models.FeeRule{ID: 3, ClientSource: "merchant-a"}
+	clientPremium := models.FeeRule{ID: 4, ClientSource: "merchant-a", WalletTier: constants.WalletTierPremium}
+	basic := models.FeeRule{ID: 5, WalletTier: constants.WalletTierBasic}
+
+	tests := []struct {
+		name         string
+		rules        []models.FeeRule
+		clientSource string
+		tier         string
+		wantID       int
+		wantOk       bool
+	}{
+		{
+			name:         "most specific rule wins",
+			rules:        []models.FeeRule{generic, premium, client, clientPremium},
+			clientSource: "merchant-a",
+			tier:         constants.WalletTierPremium,
+			wantID:       4,
+			wantOk:       true,
+		},
+		{
+			name:         "client match beats tier match",
+			rules:        []models.FeeRule{generic, premium, client},
+			clientSource: "merchant-a",
+			tier:         constants.WalletTierPremium,
+			wantID:       3,
+			wantOk:       true,
+		},
+		{
+			name:   "empty tier is basic",
+			rules:  []models.FeeRule{generic, premium, basic},
+			wantID: 5,
+			wantOk: true,
+		},
+		{
+			name:         "oldest rule wins a tie",
+			rules:        []models.FeeRule{generic, {ID: 6}},
+			clientSource: "merchant-b",
+			wantID:       1,
+			wantOk:       true,
+		},
+		{
+			name:         "no matching rule",
+			rules:        []models.FeeRule{premium, client},
+			clientSource: "merchant-b",
+			tier:         constants.WalletTierBasic,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := matchFeeRule(tt.rules, tt.clientSource, tt.tier)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantID, got.ID)
+		})
+	}
+}
+
+func TestFeeService_Quote(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockFeeRepo := NewMockIFeeRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+
+	transferFee := models.FeeRule{ID: 7, Operation: constants.FeeOperationP2PTransfer, FeeType: constants.FeeTypeFlat, FlatAmount: 1000, FreeQuota: 3}
+
+	tests := []struct {
+		name    string
+		want    models.FeeQuote
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success no rules",
+			want: models.FeeQuote{Operation: constants.FeeOperationP2PTransfer, Amount: 50000, Total: 50000},
+			mockFn: func() {
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationP2PTransfer).Return(nil, nil)
+			},
+		},
+		{
+			name: "success within the free quota",
+			want: models.FeeQuote{Operation: constants.FeeOperationP2PTransfer, Amount: 50000, Total: 50000, RuleID: 7, FreeQuotaLeft: 2, FreeQuota: 3, QuotaFee: 1000},
+			mockFn: func() {
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationP2PTransfer).Return([]models.FeeRule{transferFee}, nil)
+				mockWalletRepo.EXPECT().GetWalletByID(gomock.Any(), 2).Return(models.Wallet{ID: 2}, nil)
+				mockFeeRepo.EXPECT().CountPaidTransfers(gomock.Any(), 2, gomock.Any()).Return(1, nil)
+			},
+		},
+		{
+			name: "success quota used up",
+			want: models.FeeQuote{Operation: constants.FeeOperationP2PTransfer, Amount: 50000, Fee: 1000, Total: 51000, RuleID: 7},
+			mockFn: func() {
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationP2PTransfer).Return([]models.FeeRule{transferFee}, nil)
+				mockWalletRepo.EXPECT().GetWalletByID(gomock.Any(), 2).Return(models.Wallet{ID: 2}, nil)
+				mockFeeRepo.EXPECT().CountPaidTransfers(gomock.Any(), 2, gomock.Any()).Return(3, nil)
+			},
+		},
+		{
+			name:    "error get rules",
+			wantErr: true,
+			mockFn: func() {
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationP2PTransfer).Return(nil, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &FeeService{
+				FeeRepo:    mockFeeRepo,
+				WalletRepo: mockWalletRepo,
+			}
+			got, err := s.Quote(context.Background(), constants.FeeOperationP2PTransfer, "", 2, 50000)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFeeService_TransferPosting(t *testing.T) {
+	now := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	s := &FeeService{RevenueWalletID: 9}
+
+	tests := []struct {
+		name  string
+		quote models.FeeQuote
+		want  *models.FeePosting
+	}{
+		{
+			name:  "success charged fee",
+			quote: models.FeeQuote{Fee: 1000},
+			want: &models.FeePosting{
+				Fee:     &models.WalletTransaction{WalletID: 2, Amount: 1000, WalletTransactionType: constants.TransactionTypeFee},
+				Revenue: &models.WalletTransaction{WalletID: 9, Amount: 1000, WalletTransactionType: constants.TransactionTypeCredit},
+			},
+		},
+		{
+			name:  "success free transfer carries the quota",
+			quote: models.FeeQuote{FreeQuotaLeft: 1, FreeQuota: 3, QuotaFee: 1000},
+			want: &models.FeePosting{
+				Fee:        &models.WalletTransaction{WalletID: 2, Amount: 1000, WalletTransactionType: constants.TransactionTypeFee},
+				Revenue:    &models.WalletTransaction{WalletID: 9, Amount: 1000, WalletTransactionType: constants.TransactionTypeCredit},
+				FreeQuota:  3,
+				QuotaSince: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "success no fee",
+			quote: models.FeeQuote{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.TransferPosting(2, tt.quote, now))
+		})
+	}
+}
+
+func TestFeeService_CreateFeeRule(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockFeeRepo := NewMockIFeeRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	tests := []struct {
+		name    string
+		req     models.FeeRuleRequest
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success tiered",
+			req: models.FeeRuleRequest{
+				Operation: constants.FeeOperationWithdrawal,
+				FeeType:   constants.FeeTypeTiered,
+				Tiers:     models.FeeTiers{{UpTo: 100000, FlatAmount: 1000}, {FlatAmount: 2500}},
+			},
+			mockFn: func() {
+				mockFeeRepo.EXPECT().CreateFeeRule(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, rule *models.FeeRule) error {
+					assert.True(t, rule.Active)
+					assert.Equal(t, "finance", rule.CreatedBy)
+					rule.ID = 1
+					return nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "fee_rule.create", auditLog.Action)
+					assert.Equal(t, "1", auditLog.TargetID)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error client source on a withdrawal",
+			req:     models.FeeRuleRequest{Operation: constants.FeeOperationWithdrawal, ClientSource: "merchant-a", FeeType: constants.FeeTypeFlat},
+			wantErr: constants.ErrInvalidFeeRule,
+			mockFn:  func() {},
+		},
+		{
+			name:    "error free quota on an external debit",
+			req:     models.FeeRuleRequest{Operation: constants.FeeOperationExternalDebit, FreeQuota: 3, FeeType: constants.FeeTypeFlat},
+			wantErr: constants.ErrInvalidFeeRule,
+			mockFn:  func() {},
+		},
+		{
+			name:    "error tiers on a flat fee",
+			req:     models.FeeRuleRequest{Operation: constants.FeeOperationWithdrawal, FeeType: constants.FeeTypeFlat, Tiers: models.FeeTiers{{FlatAmount: 1000}}},
+			wantErr: constants.ErrInvalidFeeRule,
+			mockFn:  func() {},
+		},
+		{
+			name: "error tiers not ascending",
+			req: models.FeeRuleRequest{
+				Operation: constants.FeeOperationWithdrawal,
+				FeeType:   constants.FeeTypeTiered,
+				Tiers:     models.FeeTiers{{UpTo: 100000}, {UpTo: 50000}, {}},
+			},
+			wantErr: constants.ErrInvalidFeeRule,
+			mockFn:  func() {},
+		},
+		{
+			name: "error last tier bounded",
+			req: models.FeeRuleRequest{
+				Operation: constants.FeeOperationWithdrawal,
+				FeeType:   constants.FeeTypeTiered,
+				Tiers:     models.FeeTiers{{UpTo: 100000}},
+			},
+			wantErr: constants.ErrInvalidFeeRule,
+			mockFn:  func() {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &FeeService{
+				FeeRepo:      mockFeeRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+			}
+			got, err := s.CreateFeeRule(context.Background(), "finance", tt.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, got.ID)
+		})
+	}
+}
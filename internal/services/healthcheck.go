@@ -1,13 +1,162 @@
 package services
 
 import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/interfaces/i_external"
 	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
 )
 
+const defaultHealthCheckTimeout = 2 * time.Second
+
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context, warming bool) models.ComponentHealth
+}
+
 type Healthcheck struct {
 	HealthcheckRepository i_repository.IHealthcheckRepo
+	// UMS is nil when the tokens are checked locally and is not checked then
+	UMS     i_external.UMSConnection
+	Timeout time.Duration
+
+	started atomic.Bool
 }
 
 func (s *Healthcheck) HealthcheckServices() (string, error) {
 	return "service healty", nil
 }
+
+// Readiness checks every dependency at once, each within Timeout. Until the
+// first time everything is up the service counts as warming and also waits
+// for the UMS connection to be ready, later an idle or connecting one is
+// fine since it reconnects on the next call.
+func (s *Healthcheck) Readiness(ctx context.Context) models.Readiness {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	checks := []healthCheck{
+		{name: constants.HealthComponentDatabase, check: s.checkDatabase},
+		{name: constants.HealthComponentMigrations, check: s.checkMigrations},
+	}
+	if s.UMS != nil {
+		checks = append(checks, healthCheck{name: constants.HealthComponentUMS, check: s.checkUMS})
+	}
+
+	warming := !s.started.Load()
+	components := make([]models.ComponentHealth, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c healthCheck) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			component := c.check(checkCtx, warming)
+			component.Name = c.name
+			component.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+			components[i] = component
+		}(i, c)
+	}
+	wg.Wait()
+
+	resp := models.Readiness{
+		Status:     constants.HealthStatusUp,
+		Components: components,
+	}
+	for _, component := range components {
+		if component.Status != constants.HealthStatusUp {
+			resp.Status = constants.HealthStatusDown
+		}
+	}
+
+	if warming && resp.Status == constants.HealthStatusUp && s.started.CompareAndSwap(false, true) {
+		log.Println("service is warm, taking traffic")
+	}
+	resp.Started = s.started.Load()
+
+	return resp
+}
+
+// Startup answers the startup probe, once the service is warm it is up
+// without checking anything again.
+func (s *Healthcheck) Startup(ctx context.Context) models.Readiness {
+	if s.started.Load() {
+		return models.Readiness{
+			Status:  constants.HealthStatusUp,
+			Started: true,
+		}
+	}
+
+	return s.Readiness(ctx)
+}
+
+func (s *Healthcheck) checkDatabase(ctx context.Context, warming bool) models.ComponentHealth {
+	err := s.HealthcheckRepository.Ping(ctx)
+	if err != nil {
+		return healthDown(fmt.Errorf("failed to ping database: %w", err))
+	}
+
+	return models.ComponentHealth{Status: constants.HealthStatusUp}
+}
+
+func (s *Healthcheck) checkMigrations(ctx context.Context, warming bool) models.ComponentHealth {
+	migrations, err := s.HealthcheckRepository.GetMigrationHealth(ctx)
+	if err != nil {
+		return healthDown(fmt.Errorf("failed to get migration status: %w", err))
+	}
+
+	var resp models.ComponentHealth
+	switch {
+	case migrations.Pending > 0:
+		resp = healthDown(fmt.Errorf("%d migrations pending", migrations.Pending))
+	case migrations.Modified > 0:
+		resp = healthDown(fmt.Errorf("%d migrations changed after they were applied", migrations.Modified))
+	default:
+		resp.Status = constants.HealthStatusUp
+	}
+	resp.Detail = fmt.Sprintf("latest version %d", migrations.Latest)
+
+	return resp
+}
+
+func (s *Healthcheck) checkUMS(ctx context.Context, warming bool) models.ComponentHealth {
+	state, breaker := s.UMS.ConnState(), s.UMS.State()
+
+	var resp models.ComponentHealth
+	switch {
+	case state == connectivity.TransientFailure || state == connectivity.Shutdown:
+		resp = healthDown(fmt.Errorf("ums connection is %s", strings.ToLower(state.String())))
+	case warming && state != connectivity.Ready:
+		resp = healthDown(fmt.Errorf("ums connection is %s, not ready yet", strings.ToLower(state.String())))
+	case breaker == constants.CircuitOpen:
+		resp = healthDown(fmt.Errorf("ums circuit breaker is open"))
+	default:
+		resp.Status = constants.HealthStatusUp
+	}
+	resp.Detail = fmt.Sprintf("connection %s, circuit %s", strings.ToLower(state.String()), breaker)
+
+	return resp
+}
+
+func healthDown(err error) models.ComponentHealth {
+	return models.ComponentHealth{
+		Status: constants.HealthStatusDown,
+		Error:  err.Error(),
+	}
+}
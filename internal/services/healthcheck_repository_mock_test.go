@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_healthcheckRepo.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIHealthcheckRepo is a mock of IHealthcheckRepo interface.
+type MockIHealthcheckRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIHealthcheckRepoMockRecorder
+}
+
+// MockIHealthcheckRepoMockRecorder is the mock recorder for MockIHealthcheckRepo.
+type MockIHealthcheckRepoMockRecorder struct {
+	mock *MockIHealthcheckRepo
+}
+
+// NewMockIHealthcheckRepo creates a new mock instance.
+func NewMockIHealthcheckRepo(ctrl *gomock.Controller) *MockIHealthcheckRepo {
+	mock := &MockIHealthcheckRepo{ctrl: ctrl}
+	mock.recorder = &MockIHealthcheckRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIHealthcheckRepo) EXPECT() *MockIHealthcheckRepoMockRecorder {
+	return m.recorder
+}
+
+// GetMigrationHealth mocks base method.
+func (m *MockIHealthcheckRepo) GetMigrationHealth(ctx context.Context) (models.MigrationHealth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMigrationHealth", ctx)
+	ret0, _ := ret[0].(models.MigrationHealth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMigrationHealth indicates an expected call of GetMigrationHealth.
+func (mr *MockIHealthcheckRepoMockRecorder) GetMigrationHealth(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigrationHealth", reflect.TypeOf((*MockIHealthcheckRepo)(nil).GetMigrationHealth), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockIHealthcheckRepo) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockIHealthcheckRepoMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockIHealthcheckRepo)(nil).Ping), ctx)
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/connectivity"
+)
+
+func componentStatuses(readiness models.Readiness) map[string]string {
+	resp := map[string]string{}
+	for _, component := range readiness.Components {
+		resp[component.Name] = component.Status
+	}
+	return resp
+}
+
+func TestHealthcheck_Readiness(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockIHealthcheckRepo(ctrlMock)
+	mockUMS := NewMockUMSConnection(ctrlMock)
+
+	current := models.MigrationHealth{Latest: 1}
+
+	tests := []struct {
+		name       string
+		started    bool
+		withoutUMS bool
+		mockFn     func()
+		want       string
+		wantDown   []string
+	}{
+		{
+			name: "all up",
+			mockFn: func() {
+				mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(current, nil)
+				mockUMS.EXPECT().ConnState().Return(connectivity.Ready)
+				mockUMS.EXPECT().State().Return(constants.CircuitClosed)
+			},
+			want: constants.HealthStatusUp,
+		},
+		{
+			name:       "local auth skips the ums",
+			withoutUMS: true,
+			mockFn: func() {
+				mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(current, nil)
+			},
+			want: constants.HealthStatusUp,
+		},
+		{
+			name: "database down",
+			mockFn: func() {
+				mockRepo.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+				mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(models.MigrationHealth{}, errors.New("connection refused"))
+				mockUMS.EXPECT().ConnState().Return(connectivity.Ready)
+				mockUMS.EXPECT().State().Return(constants.CircuitClosed)
+			},
+			want:     constants.HealthStatusDown,
+			wantDown: []string{constants.HealthComponentDatabase, constants.HealthComponentMigrations},
+		},
+		{
+			name: "pending migrations",
+			mockFn: func() {
+				mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(models.MigrationHealth{Latest: 2, Pending: 1}, nil)
+				mockUMS.EXPECT().ConnState().Return(connectivity.Ready)
+				mockUMS.EXPECT().State().Return(constants.CircuitClosed)
+			},
+			want:     constants.HealthStatusDown,
+			wantDown: []string{constants.HealthComponentMigrations},
+		},
+		{
+			name: "modified migrations",
+			mockFn: func() {
+				mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(models.MigrationHealth{Latest: 1, Modified: 1}, nil)
+				mockUMS.EXPECT().ConnState().Return(connectivity.Ready)
+				mockUMS.EXPECT().State().Return(constants.CircuitClosed)
+			},
+			want:     constants.HealthStatusDown,
+			wantDown: []string{constants.HealthComponentMigrations},
+		},
+		{
+			name: "ums connection failing",
+			mockFn: func() {
+				mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(current, nil)
+				mockUMS.EXPECT().ConnState().Return(connectivity.TransientFailure)
+				mockUMS.EXPECT().State().Return(constants.CircuitClosed)
+			},
+			started:  true,
+			want:     constants.HealthStatusDown,
+			wantDown: []string{constants.HealthComponentUMS},
+		},
+		{
+			name: "ums circuit open",
+			mockFn: func() {
+				mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(current, nil)
+				mockUMS.EXPECT().ConnState().Return(connectivity.Ready)
+				mockUMS.EXPECT().State().Return(constants.CircuitOpen)
+			},
+			started:  true,
+			want:     constants.HealthStatusDown,
+			wantDown: []string{constants.HealthComponentUMS},
+		},
+		{
+			name: "idle ums connection while warming",
+			mockFn: func() {
+				mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(current, nil)
+				mockUMS.EXPECT().ConnState().Return(connectivity.Idle)
+				mockUMS.EXPECT().State().Return(constants.CircuitClosed)
+			},
+			want:     constants.HealthStatusDown,
+			wantDown: []string{constants.HealthComponentUMS},
+		},
+		{
+			name: "idle ums connection once started",
+			mockFn: func() {
+				mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(current, nil)
+				mockUMS.EXPECT().ConnState().Return(connectivity.Idle)
+				mockUMS.EXPECT().State().Return(constants.CircuitClosed)
+			},
+			started: true,
+			want:    constants.HealthStatusUp,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+
+			s := &Healthcheck{
+				HealthcheckRepository: mockRepo,
+				UMS:                   mockUMS,
+			}
+			if tt.withoutUMS {
+				s.UMS = nil
+			}
+			s.started.Store(tt.started)
+
+			got := s.Readiness(context.Background())
+			assert.Equal(t, tt.want, got.Status)
+
+			want := map[string]string{
+				constants.HealthComponentDatabase:   constants.HealthStatusUp,
+				constants.HealthComponentMigrations: constants.HealthStatusUp,
+				constants.HealthComponentUMS:        constants.HealthStatusUp,
+			}
+			if tt.withoutUMS {
+				delete(want, constants.HealthComponentUMS)
+			}
+			for _, name := range tt.wantDown {
+				want[name] = constants.HealthStatusDown
+			}
+			assert.Equal(t, want, componentStatuses(got))
+		})
+	}
+}
+
+func TestHealthcheck_ReadinessTimeout(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockIHealthcheckRepo(ctrlMock)
+	mockRepo.EXPECT().Ping(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(models.MigrationHealth{Latest: 1}, nil)
+
+	s := &Healthcheck{
+		HealthcheckRepository: mockRepo,
+		Timeout:               50 * time.Millisecond,
+	}
+
+	start := time.Now()
+	got := s.Readiness(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+
+	assert.Equal(t, constants.HealthStatusDown, got.Status)
+	assert.Equal(t, constants.HealthComponentDatabase, got.Components[0].Name)
+	assert.Contains(t, got.Components[0].Error, context.DeadlineExceeded.Error())
+	assert.GreaterOrEqual(t, got.Components[0].LatencyMS, 50.0)
+}
+
+func TestHealthcheck_Startup(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockIHealthcheckRepo(ctrlMock)
+	s := &Healthcheck{
+		HealthcheckRepository: mockRepo,
+	}
+
+	// still migrating, the service is not warm yet
+	mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+	mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(models.MigrationHealth{Latest: 2, Pending: 1}, nil)
+	got := s.Startup(context.Background())
+	assert.Equal(t, constants.HealthStatusDown, got.Status)
+	assert.False(t, got.Started)
+
+	mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+	mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(models.MigrationHealth{Latest: 2}, nil)
+	got = s.Startup(context.Background())
+	assert.Equal(t, constants.HealthStatusUp, got.Status)
+	assert.True(t, got.Started)
+
+	// once warm the startup probe checks nothing, readiness still does
+	got = s.Startup(context.Background())
+	assert.Equal(t, models.Readiness{Status: constants.HealthStatusUp, Started: true}, got)
+
+	mockRepo.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+	mockRepo.EXPECT().GetMigrationHealth(gomock.Any()).Return(models.MigrationHealth{Latest: 2}, nil)
+	got = s.Readiness(context.Background())
+	assert.Equal(t, constants.HealthStatusDown, got.Status)
+	assert.True(t, got.Started)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_otp_sender.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOTPSender is a mock of OTPSender interface.
+type MockOTPSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockOTPSenderMockRecorder
+}
+
+// MockOTPSenderMockRecorder is the mock recorder for MockOTPSender.
+type MockOTPSenderMockRecorder struct {
+	mock *MockOTPSender
+}
+
+// NewMockOTPSender creates a new mock instance.
+func NewMockOTPSender(ctrl *gomock.Controller) *MockOTPSender {
+	mock := &MockOTPSender{ctrl: ctrl}
+	mock.recorder = &MockOTPSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOTPSender) EXPECT() *MockOTPSenderMockRecorder {
+	return m.recorder
+}
+
+// SendOTP mocks base method.
+func (m *MockOTPSender) SendOTP(ctx context.Context, msg models.OTPMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendOTP", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendOTP indicates an expected call of SendOTP.
+func (mr *MockOTPSenderMockRecorder) SendOTP(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendOTP", reflect.TypeOf((*MockOTPSender)(nil).SendOTP), ctx, msg)
+}
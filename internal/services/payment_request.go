@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const paymentRequestSweepBatchSize = 100
+
+type PaymentRequestService struct {
+	PaymentRequestRepo i_repository.IPaymentRequestRepo
+	WalletRepo         i_repository.IWalletRepo
+	WalletService      *WalletService
+	AuditService       *AuditService
+	FeeService         *FeeService
+	PINService         *PINService
+	RiskService        *RiskService
+}
+
+func (s *PaymentRequestService) CreatePaymentRequest(ctx context.Context, userID uint64, req models.CreatePaymentRequest) (models.PaymentRequest, error) {
+	var (
+		resp models.PaymentRequest
+	)
+
+	if req.PayerUserID == userID {
+		return resp, fmt.Errorf("%w: can not request money from yourself", constants.ErrInvalidPaymentRequest)
+	}
+
+	requesterWallet, err := s.WalletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get wallet")
+	}
+
+	payerWallet, err := s.WalletRepo.GetWalletByUserID(ctx, req.PayerUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return resp, fmt.Errorf("%w: payer has no wallet", constants.ErrInvalidPaymentRequest)
+		}
+		return resp, errors.Wrap(err, "failed to get payer wallet")
+	}
+
+	if payerWallet.Type == constants.WalletTypeSystem {
+		return resp, fmt.Errorf("%w: payer has no wallet", constants.ErrInvalidPaymentRequest)
+	}
+
+	expiresInHours := req.ExpiresInHours
+	if expiresInHours == 0 {
+		expiresInHours = constants.DefaultPaymentRequestExpiryHours
+	}
+
+	now := time.Now()
+	resp = models.PaymentRequest{
+		RequesterUserID:   userID,
+		RequesterWalletID: requesterWallet.ID,
+		PayerUserID:       req.PayerUserID,
+		PayerWalletID:     payerWallet.ID,
+		Amount:            req.Amount,
+		Note:              req.Note,
+		Status:            constants.PaymentRequestStatusPending,
+		Reference:         fmt.Sprintf("PRQ-%d-%d", requesterWallet.ID, now.UnixNano()),
+		ExpiresAt:         now.Add(time.Duration(expiresInHours) * time.Hour),
+	}
+
+	err = s.PaymentRequestRepo.CreatePaymentRequest(ctx, &resp)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to create payment request")
+	}
+
+	s.recordRequestChange(ctx, "payment_request.create", resp, "")
+
+	return resp, nil
+}
+
+func (s *PaymentRequestService) GetIncomingPaymentRequests(ctx context.Context, userID uint64, param models.PaymentRequestParam) ([]models.PaymentRequest, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.PaymentRequestRepo.GetPaymentRequests(ctx, 0, userID, param.Status, offset, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get incoming payment requests")
+	}
+
+	return resp, nil
+}
+
+func (s *PaymentRequestService) GetOutgoingPaymentRequests(ctx context.Context, userID uint64, param models.PaymentRequestParam) ([]models.PaymentRequest, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.PaymentRequestRepo.GetPaymentRequests(ctx, userID, 0, param.Status, offset, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get outgoing payment requests")
+	}
+
+	return resp, nil
+}
+
+// AcceptPaymentRequest pays the request, and the P2P fee, from the payer's
+// wallet. The status change and the ledger rows are written in one
+// transaction.
+func (s *PaymentRequestService) AcceptPaymentRequest(ctx context.Context, userID uint64, requestID int) (models.PaymentRequest, error) {
+	request, err := s.getPaymentRequest(ctx, requestID)
+	if err != nil {
+		return request, err
+	}
+
+	if request.PayerUserID != userID {
+		return models.PaymentRequest{}, constants.ErrPaymentRequestNotFound
+	}
+
+	err = s.PINService.Authorize(ctx, userID, request.Amount)
+	if err != nil {
+		return request, err
+	}
+
+	err = s.RiskService.Screen(ctx, models.RiskCheck{
+		Operation:        constants.RiskOperationTransfer,
+		TransactionType:  constants.TransactionTypeDebit,
+		UserID:           userID,
+		WalletID:         request.PayerWalletID,
+		Amount:           request.Amount,
+		Reference:        request.Reference + "-D",
+		PaymentRequestID: request.ID,
+	})
+	if err != nil {
+		return request, err
+	}
+
+	return s.payPaymentRequest(ctx, request)
+}
+
+func (s *PaymentRequestService) payPaymentRequest(ctx context.Context, request models.PaymentRequest) (models.PaymentRequest, error) {
+	debit := &models.WalletTransaction{
+		WalletID:              request.PayerWalletID,
+		Amount:                request.Amount,
+		WalletTransactionType: constants.TransactionTypeDebit,
+		Reference:             request.Reference + "-D",
+	}
+	credit := &models.WalletTransaction{
+		WalletID:              request.RequesterWalletID,
+		Amount:                request.Amount,
+		WalletTransactionType: constants.TransactionTypeCredit,
+		Reference:             request.Reference + "-C",
+	}
+
+	quote, err := s.FeeService.Quote(ctx, constants.FeeOperationP2PTransfer, "", request.PayerWalletID, request.Amount)
+	if err != nil {
+		return request, err
+	}
+	now := time.Now()
+	fee := s.FeeService.TransferPosting(request.PayerWalletID, quote, now)
+
+	payer, requester, err := s.PaymentRequestRepo.PayPaymentRequest(ctx, request.ID, debit, credit, fee, now)
+	if err != nil {
+		return request, errors.Wrap(err, "failed to pay payment request")
+	}
+
+	request.Status = constants.PaymentRequestStatusPaid
+	request.Fee = fee.Amount()
+	request.DecidedAt = &now
+
+	s.WalletService.recordBalanceChange(ctx, "balance.debit", payer.ID, payer.Balance, payer.Balance-request.Amount, debit)
+
+	if fee.Amount() > 0 {
+		balance := payer.Balance - request.Amount
+		s.WalletService.recordBalanceChange(ctx, "balance.fee", payer.ID, balance, balance-fee.Amount(), fee.Fee)
+	}
+
+	s.WalletService.recordBalanceChange(ctx, "balance.credit", requester.ID, requester.Balance, requester.Balance+request.Amount, credit)
+
+	s.recordRequestChange(ctx, "payment_request.accept", request, constants.PaymentRequestStatusPending)
+
+	return request, nil
+}
+
+func (s *PaymentRequestService) DeclinePaymentRequest(ctx context.Context, userID uint64, requestID int) (models.PaymentRequest, error) {
+	request, err := s.getPaymentRequest(ctx, requestID)
+	if err != nil {
+		return request, err
+	}
+
+	if request.PayerUserID != userID {
+		return models.PaymentRequest{}, constants.ErrPaymentRequestNotFound
+	}
+
+	return s.closeRequest(ctx, request, constants.PaymentRequestStatusDeclined, "payment_request.decline", time.Now())
+}
+
+func (s *PaymentRequestService) CancelPaymentRequest(ctx context.Context, userID uint64, requestID int) (models.PaymentRequest, error) {
+	request, err := s.getPaymentRequest(ctx, requestID)
+	if err != nil {
+		return request, err
+	}
+
+	if request.RequesterUserID != userID {
+		return models.PaymentRequest{}, constants.ErrPaymentRequestNotFound
+	}
+
+	return s.closeRequest(ctx, request, constants.PaymentRequestStatusCancelled, "payment_request.cancel", time.Now())
+}
+
+// ExpirePaymentRequests expires the pending requests whose expiry passed and
+// returns how many were expired.
+func (s *PaymentRequestService) ExpirePaymentRequests(ctx context.Context, now time.Time) (int, error) {
+	requests, err := s.PaymentRequestRepo.GetExpiredPaymentRequests(ctx, now, paymentRequestSweepBatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get expired payment requests")
+	}
+
+	expired := 0
+	for _, request := range requests {
+		_, err := s.closeRequest(ctx, request, constants.PaymentRequestStatusExpired, "payment_request.expire", now)
+		if err != nil {
+			// decided by the payer or requester since it was read
+			if errors.Is(err, constants.ErrPaymentRequestNotPending) {
+				continue
+			}
+			return expired, err
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// RunExpirySweeper expires payment requests every interval until ctx is
+// cancelled.
+func (s *PaymentRequestService) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := s.ExpirePaymentRequests(ctx, time.Now())
+		if err != nil {
+			log.Println("failed to expire payment requests: ", err)
+			continue
+		}
+
+		if expired > 0 {
+			log.Printf("%d payment requests expired\n", expired)
+		}
+	}
+}
+
+func (s *PaymentRequestService) getPaymentRequest(ctx context.Context, requestID int) (models.PaymentRequest, error) {
+	request, err := s.PaymentRequestRepo.GetPaymentRequestByID(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return request, constants.ErrPaymentRequestNotFound
+		}
+		return request, errors.Wrap(err, "failed to get payment request")
+	}
+
+	return request, nil
+}
+
+func (s *PaymentRequestService) closeRequest(ctx context.Context, request models.PaymentRequest, status string, action string, now time.Time) (models.PaymentRequest, error) {
+	err := s.PaymentRequestRepo.UpdatePaymentRequestStatus(ctx, request.ID, status, now)
+	if err != nil {
+		return request, errors.Wrapf(err, "failed to %s payment request", status)
+	}
+
+	fromStatus := request.Status
+	request.Status = status
+	request.DecidedAt = &now
+
+	s.recordRequestChange(ctx, action, request, fromStatus)
+
+	return request, nil
+}
+
+// recordRequestChange audits a status change already committed, a failed
+// write is queued and never fails the change.
+func (s *PaymentRequestService) recordRequestChange(ctx context.Context, action string, request models.PaymentRequest, fromStatus string) {
+	var before interface{}
+	if fromStatus != "" {
+		before = map[string]interface{}{"status": fromStatus}
+	}
+
+	detail := map[string]interface{}{
+		"requester_wallet_id": request.RequesterWalletID,
+		"payer_wallet_id":     request.PayerWalletID,
+		"amount":              request.Amount,
+		"reference":           request.Reference,
+	}
+
+	s.AuditService.RecordCommitted(ctx, action, constants.AuditTargetPaymentRequest, strconv.Itoa(request.ID),
+		before, map[string]interface{}{"status": request.Status}, detail)
+}
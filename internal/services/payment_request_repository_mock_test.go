@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_payment_request_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIPaymentRequestRepo is a mock of IPaymentRequestRepo interface.
+type MockIPaymentRequestRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPaymentRequestRepoMockRecorder
+}
+
+// MockIPaymentRequestRepoMockRecorder is the mock recorder for MockIPaymentRequestRepo.
+type MockIPaymentRequestRepoMockRecorder struct {
+	mock *MockIPaymentRequestRepo
+}
+
+// NewMockIPaymentRequestRepo creates a new mock instance.
+func NewMockIPaymentRequestRepo(ctrl *gomock.Controller) *MockIPaymentRequestRepo {
+	mock := &MockIPaymentRequestRepo{ctrl: ctrl}
+	mock.recorder = &MockIPaymentRequestRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPaymentRequestRepo) EXPECT() *MockIPaymentRequestRepoMockRecorder {
+	return m.recorder
+}
+
+// CreatePaymentRequest mocks base method.
+func (m *MockIPaymentRequestRepo) CreatePaymentRequest(ctx context.Context, request *models.PaymentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentRequest", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePaymentRequest indicates an expected call of CreatePaymentRequest.
+func (mr *MockIPaymentRequestRepoMockRecorder) CreatePaymentRequest(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentRequest", reflect.TypeOf((*MockIPaymentRequestRepo)(nil).CreatePaymentRequest), ctx, request)
+}
+
+// GetExpiredPaymentRequests mocks base method.
+func (m *MockIPaymentRequestRepo) GetExpiredPaymentRequests(ctx context.Context, now time.Time, limit int) ([]models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredPaymentRequests", ctx, now, limit)
+	ret0, _ := ret[0].([]models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredPaymentRequests indicates an expected call of GetExpiredPaymentRequests.
+func (mr *MockIPaymentRequestRepoMockRecorder) GetExpiredPaymentRequests(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredPaymentRequests", reflect.TypeOf((*MockIPaymentRequestRepo)(nil).GetExpiredPaymentRequests), ctx, now, limit)
+}
+
+// GetPaymentRequestByID mocks base method.
+func (m *MockIPaymentRequestRepo) GetPaymentRequestByID(ctx context.Context, requestID int) (models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequestByID", ctx, requestID)
+	ret0, _ := ret[0].(models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequestByID indicates an expected call of GetPaymentRequestByID.
+func (mr *MockIPaymentRequestRepoMockRecorder) GetPaymentRequestByID(ctx, requestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequestByID", reflect.TypeOf((*MockIPaymentRequestRepo)(nil).GetPaymentRequestByID), ctx, requestID)
+}
+
+// GetPaymentRequests mocks base method.
+func (m *MockIPaymentRequestRepo) GetPaymentRequests(ctx context.Context, requesterUserID, payerUserID uint64, status string, offset, limit int) ([]models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequests", ctx, requesterUserID, payerUserID, status, offset, limit)
+	ret0, _ := ret[0].([]models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequests indicates an expected call of GetPaymentRequests.
+func (mr *MockIPaymentRequestRepoMockRecorder) GetPaymentRequests(ctx, requesterUserID, payerUserID, status, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequests", reflect.TypeOf((*MockIPaymentRequestRepo)(nil).GetPaymentRequests), ctx, requesterUserID, payerUserID, status, offset, limit)
+}
+
+// PayPaymentRequest mocks base method.
+func (m *MockIPaymentRequestRepo) PayPaymentRequest(ctx context.Context, requestID int, debit, credit *models.WalletTransaction, fee *models.FeePosting, now time.Time) (models.Wallet, models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PayPaymentRequest", ctx, requestID, debit, credit, fee, now)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(models.Wallet)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PayPaymentRequest indicates an expected call of PayPaymentRequest.
+func (mr *MockIPaymentRequestRepoMockRecorder) PayPaymentRequest(ctx, requestID, debit, credit, fee, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayPaymentRequest", reflect.TypeOf((*MockIPaymentRequestRepo)(nil).PayPaymentRequest), ctx, requestID, debit, credit, fee, now)
+}
+
+// UpdatePaymentRequestStatus mocks base method.
+func (m *MockIPaymentRequestRepo) UpdatePaymentRequestStatus(ctx context.Context, requestID int, toStatus string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentRequestStatus", ctx, requestID, toStatus, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePaymentRequestStatus indicates an expected call of UpdatePaymentRequestStatus.
+func (mr *MockIPaymentRequestRepoMockRecorder) UpdatePaymentRequestStatus(ctx, requestID, toStatus, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentRequestStatus", reflect.TypeOf((*MockIPaymentRequestRepo)(nil).UpdatePaymentRequestStatus), ctx, requestID, toStatus, now)
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestPaymentRequestService_CreatePaymentRequest(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRequestRepo := NewMockIPaymentRequestRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	req := models.CreatePaymentRequest{
+		PayerUserID: 20,
+		Amount:      25000,
+		Note:        "dinner",
+	}
+
+	tests := []struct {
+		name    string
+		req     models.CreatePaymentRequest
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			req:  req,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(10)).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(20)).Return(models.Wallet{ID: 2, UserID: 20}, nil)
+				mockRequestRepo.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, request *models.PaymentRequest) error {
+					request.ID = 5
+					return nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "payment_request.create", auditLog.Action)
+					assert.Equal(t, "5", auditLog.TargetID)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error request to self",
+			req:     models.CreatePaymentRequest{PayerUserID: 10, Amount: 25000},
+			wantErr: constants.ErrInvalidPaymentRequest,
+			mockFn:  func() {},
+		},
+		{
+			name:    "error payer has no wallet",
+			req:     req,
+			wantErr: constants.ErrInvalidPaymentRequest,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(10)).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(20)).Return(models.Wallet{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:    "error",
+			req:     req,
+			wantErr: assert.AnError,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(10)).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(20)).Return(models.Wallet{ID: 2, UserID: 20}, nil)
+				mockRequestRepo.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Return(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &PaymentRequestService{
+				PaymentRequestRepo: mockRequestRepo,
+				WalletRepo:         mockWalletRepo,
+				AuditService:       &AuditService{AuditRepo: mockAuditRepo},
+			}
+			before := time.Now()
+			got, err := s.CreatePaymentRequest(context.Background(), 10, tt.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 5, got.ID)
+			assert.Equal(t, 1, got.RequesterWalletID)
+			assert.Equal(t, 2, got.PayerWalletID)
+			assert.Equal(t, constants.PaymentRequestStatusPending, got.Status)
+			assert.Regexp(t, `^PRQ-1-\d+$`, got.Reference)
+			assert.WithinDuration(t, before.Add(constants.DefaultPaymentRequestExpiryHours*time.Hour), got.ExpiresAt, time.Minute)
+		})
+	}
+}
+
+func TestPaymentRequestService_AcceptPaymentRequest(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRequestRepo := NewMockIPaymentRequestRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockFeeRepo := NewMockIFeeRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+
+	request := models.PaymentRequest{
+		ID:                5,
+		RequesterUserID:   10,
+		RequesterWalletID: 1,
+		PayerUserID:       20,
+		PayerWalletID:     2,
+		Amount:            25000,
+		Status:            constants.PaymentRequestStatusPending,
+		Reference:         "PRQ-1-1",
+	}
+	transferFee := models.FeeRule{ID: 1, Operation: constants.FeeOperationP2PTransfer, FeeType: constants.FeeTypeFlat, FlatAmount: 1000, FreeQuota: 3, Active: true}
+
+	tests := []struct {
+		name    string
+		userID  uint64
+		wantFee float64
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name:   "success",
+			userID: 20,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(request, nil)
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationP2PTransfer).Return(nil, nil)
+				mockRequestRepo.EXPECT().PayPaymentRequest(gomock.Any(), 5, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, requestID int, debit *models.WalletTransaction, credit *models.WalletTransaction, fee *models.FeePosting, now time.Time) (models.Wallet, models.Wallet, error) {
+						assert.Equal(t, models.WalletTransaction{WalletID: 2, Amount: 25000, WalletTransactionType: constants.TransactionTypeDebit, Reference: "PRQ-1-1-D"}, *debit)
+						assert.Equal(t, models.WalletTransaction{WalletID: 1, Amount: 25000, WalletTransactionType: constants.TransactionTypeCredit, Reference: "PRQ-1-1-C"}, *credit)
+						assert.Nil(t, fee)
+						return models.Wallet{ID: 2, Balance: 100000}, models.Wallet{ID: 1, Balance: 5000}, nil
+					})
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.debit", auditLog.Action)
+						assert.Equal(t, "2", auditLog.TargetID)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.credit", auditLog.Action)
+						assert.Equal(t, "1", auditLog.TargetID)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "payment_request.accept", auditLog.Action)
+						return nil
+					}),
+				)
+			},
+		},
+		{
+			name:   "success audit write fails after the transfer",
+			userID: 20,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(request, nil)
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationP2PTransfer).Return(nil, nil)
+				mockRequestRepo.EXPECT().PayPaymentRequest(gomock.Any(), 5, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(models.Wallet{ID: 2, Balance: 100000}, models.Wallet{ID: 1, Balance: 5000}, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(assert.AnError).Times(3)
+			},
+		},
+		{
+			name:    "success fee after the free quota",
+			userID:  20,
+			wantFee: 1000,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(request, nil)
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationP2PTransfer).Return([]models.FeeRule{transferFee}, nil)
+				mockWalletRepo.EXPECT().GetWalletByID(gomock.Any(), 2).Return(models.Wallet{ID: 2}, nil)
+				mockFeeRepo.EXPECT().CountPaidTransfers(gomock.Any(), 2, gomock.Any()).Return(3, nil)
+				mockRequestRepo.EXPECT().PayPaymentRequest(gomock.Any(), 5, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, requestID int, debit *models.WalletTransaction, credit *models.WalletTransaction, fee *models.FeePosting, now time.Time) (models.Wallet, models.Wallet, error) {
+						assert.Equal(t, models.WalletTransaction{WalletID: 2, Amount: 1000, WalletTransactionType: constants.TransactionTypeFee}, *fee.Fee)
+						assert.Equal(t, models.WalletTransaction{WalletID: 99, Amount: 1000, WalletTransactionType: constants.TransactionTypeCredit}, *fee.Revenue)
+						return models.Wallet{ID: 2, Balance: 100000}, models.Wallet{ID: 1, Balance: 5000}, nil
+					})
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.fee", auditLog.Action)
+						assert.JSONEq(t, `{"balance":74000}`, auditLog.After)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil),
+				)
+			},
+		},
+		{
+			name:    "error requester can not accept",
+			userID:  10,
+			wantErr: constants.ErrPaymentRequestNotFound,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(request, nil)
+			},
+		},
+		{
+			name:    "error not found",
+			userID:  20,
+			wantErr: constants.ErrPaymentRequestNotFound,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(models.PaymentRequest{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:    "error insufficient balance",
+			userID:  20,
+			wantErr: constants.ErrInsufficientBalance,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(request, nil)
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationP2PTransfer).Return(nil, nil)
+				mockRequestRepo.EXPECT().PayPaymentRequest(gomock.Any(), 5, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(models.Wallet{}, models.Wallet{}, constants.ErrInsufficientBalance)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+			s := &PaymentRequestService{
+				PaymentRequestRepo: mockRequestRepo,
+				WalletService:      &WalletService{AuditService: auditSvc},
+				AuditService:       auditSvc,
+				FeeService:         &FeeService{FeeRepo: mockFeeRepo, WalletRepo: mockWalletRepo, RevenueWalletID: 99},
+			}
+			got, err := s.AcceptPaymentRequest(context.Background(), tt.userID, 5)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, constants.PaymentRequestStatusPaid, got.Status)
+			assert.Equal(t, tt.wantFee, got.Fee)
+			assert.NotNil(t, got.DecidedAt)
+		})
+	}
+}
+
+func TestPaymentRequestService_DeclineAndCancelPaymentRequest(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRequestRepo := NewMockIPaymentRequestRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	request := models.PaymentRequest{
+		ID:              5,
+		RequesterUserID: 10,
+		PayerUserID:     20,
+		Amount:          25000,
+		Status:          constants.PaymentRequestStatusPending,
+	}
+
+	s := &PaymentRequestService{
+		PaymentRequestRepo: mockRequestRepo,
+		AuditService:       &AuditService{AuditRepo: mockAuditRepo},
+	}
+
+	tests := []struct {
+		name       string
+		decide     func(ctx context.Context, userID uint64, requestID int) (models.PaymentRequest, error)
+		userID     uint64
+		wantStatus string
+		wantErr    error
+		mockFn     func()
+	}{
+		{
+			name:       "payer declines",
+			decide:     s.DeclinePaymentRequest,
+			userID:     20,
+			wantStatus: constants.PaymentRequestStatusDeclined,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(request, nil)
+				mockRequestRepo.EXPECT().UpdatePaymentRequestStatus(gomock.Any(), 5, constants.PaymentRequestStatusDeclined, gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "payment_request.decline", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "requester can not decline",
+			decide:  s.DeclinePaymentRequest,
+			userID:  10,
+			wantErr: constants.ErrPaymentRequestNotFound,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(request, nil)
+			},
+		},
+		{
+			name:       "requester cancels",
+			decide:     s.CancelPaymentRequest,
+			userID:     10,
+			wantStatus: constants.PaymentRequestStatusCancelled,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(request, nil)
+				mockRequestRepo.EXPECT().UpdatePaymentRequestStatus(gomock.Any(), 5, constants.PaymentRequestStatusCancelled, gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "payment_request.cancel", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "payer can not cancel",
+			decide:  s.CancelPaymentRequest,
+			userID:  20,
+			wantErr: constants.ErrPaymentRequestNotFound,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(request, nil)
+			},
+		},
+		{
+			name:    "already decided",
+			decide:  s.CancelPaymentRequest,
+			userID:  10,
+			wantErr: constants.ErrPaymentRequestNotPending,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetPaymentRequestByID(gomock.Any(), 5).Return(request, nil)
+				mockRequestRepo.EXPECT().UpdatePaymentRequestStatus(gomock.Any(), 5, constants.PaymentRequestStatusCancelled, gomock.Any()).Return(constants.ErrPaymentRequestNotPending)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			got, err := tt.decide(context.Background(), tt.userID, 5)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.Status)
+		})
+	}
+}
+
+func TestPaymentRequestService_ExpirePaymentRequests(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRequestRepo := NewMockIPaymentRequestRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	now := time.Now()
+	requests := []models.PaymentRequest{
+		{ID: 5, Status: constants.PaymentRequestStatusPending},
+		{ID: 6, Status: constants.PaymentRequestStatusPending},
+	}
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success skips requests decided meanwhile",
+			want: 1,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetExpiredPaymentRequests(gomock.Any(), now, 100).Return(requests, nil)
+				mockRequestRepo.EXPECT().UpdatePaymentRequestStatus(gomock.Any(), 5, constants.PaymentRequestStatusExpired, now).Return(constants.ErrPaymentRequestNotPending)
+				mockRequestRepo.EXPECT().UpdatePaymentRequestStatus(gomock.Any(), 6, constants.PaymentRequestStatusExpired, now).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "payment_request.expire", auditLog.Action)
+					assert.Equal(t, "6", auditLog.TargetID)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error",
+			wantErr: assert.AnError,
+			mockFn: func() {
+				mockRequestRepo.EXPECT().GetExpiredPaymentRequests(gomock.Any(), now, 100).Return(nil, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &PaymentRequestService{
+				PaymentRequestRepo: mockRequestRepo,
+				AuditService:       &AuditService{AuditRepo: mockAuditRepo},
+			}
+			got, err := s.ExpirePaymentRequests(context.Background(), now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_payout_provider.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPayoutProvider is a mock of PayoutProvider interface.
+type MockPayoutProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockPayoutProviderMockRecorder
+}
+
+// MockPayoutProviderMockRecorder is the mock recorder for MockPayoutProvider.
+type MockPayoutProviderMockRecorder struct {
+	mock *MockPayoutProvider
+}
+
+// NewMockPayoutProvider creates a new mock instance.
+func NewMockPayoutProvider(ctrl *gomock.Controller) *MockPayoutProvider {
+	mock := &MockPayoutProvider{ctrl: ctrl}
+	mock.recorder = &MockPayoutProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPayoutProvider) EXPECT() *MockPayoutProviderMockRecorder {
+	return m.recorder
+}
+
+// CreatePayout mocks base method.
+func (m *MockPayoutProvider) CreatePayout(ctx context.Context, req models.PayoutRequest) (models.Payout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePayout", ctx, req)
+	ret0, _ := ret[0].(models.Payout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePayout indicates an expected call of CreatePayout.
+func (mr *MockPayoutProviderMockRecorder) CreatePayout(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayout", reflect.TypeOf((*MockPayoutProvider)(nil).CreatePayout), ctx, req)
+}
+
+// InquireAccount mocks base method.
+func (m *MockPayoutProvider) InquireAccount(ctx context.Context, bankCode, accountNumber string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InquireAccount", ctx, bankCode, accountNumber)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InquireAccount indicates an expected call of InquireAccount.
+func (mr *MockPayoutProviderMockRecorder) InquireAccount(ctx, bankCode, accountNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InquireAccount", reflect.TypeOf((*MockPayoutProvider)(nil).InquireAccount), ctx, bankCode, accountNumber)
+}
+
+// Name mocks base method.
+func (m *MockPayoutProvider) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockPayoutProviderMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockPayoutProvider)(nil).Name))
+}
+
+// VerifyCallback mocks base method.
+func (m *MockPayoutProvider) VerifyCallback(ctx context.Context, payload []byte, signature string) (models.PayoutCallback, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyCallback", ctx, payload, signature)
+	ret0, _ := ret[0].(models.PayoutCallback)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyCallback indicates an expected call of VerifyCallback.
+func (mr *MockPayoutProviderMockRecorder) VerifyCallback(ctx, payload, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyCallback", reflect.TypeOf((*MockPayoutProvider)(nil).VerifyCallback), ctx, payload, signature)
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/interfaces/i_external"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// otpResendInterval is how long a user waits before another reset OTP is
+// sent.
+const otpResendInterval = time.Minute
+
+// PINService manages the wallet PIN and authorizes the debits above
+// Threshold with it. A nil PINService asks for no PIN.
+type PINService struct {
+	PINRepo      i_repository.IPINRepo
+	WalletRepo   i_repository.IWalletRepo
+	AuditService *AuditService
+	OTPSender    i_external.OTPSender
+	Threshold    float64
+	MaxAttempts  int
+	LockDuration time.Duration
+	OTPTTL       time.Duration
+	StepUpTTL    time.Duration
+	StepUpJWT    helpers.JWTConfig
+}
+
+func (s *PINService) SetPIN(ctx context.Context, userID uint64, req models.SetPINRequest) error {
+	wallet, err := s.WalletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get wallet")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.PIN), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.Wrap(err, "failed to hash pin")
+	}
+
+	err = s.PINRepo.CreateWalletPIN(ctx, &models.WalletPIN{
+		WalletID: wallet.ID,
+		PINHash:  string(hash),
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return constants.ErrPINAlreadySet
+		}
+		return errors.Wrap(err, "failed to create wallet pin")
+	}
+
+	return s.recordPINEvent(ctx, "pin.set", wallet.ID, nil)
+}
+
+func (s *PINService) ChangePIN(ctx context.Context, userID uint64, req models.ChangePINRequest) error {
+	wallet, pin, err := s.getWalletPIN(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkPIN(ctx, pin, req.OldPIN)
+	if err != nil {
+		return err
+	}
+
+	err = s.updatePIN(ctx, wallet.ID, req.NewPIN)
+	if err != nil {
+		return err
+	}
+
+	return s.recordPINEvent(ctx, "pin.change", wallet.ID, nil)
+}
+
+// RequestPINReset sends an OTP to the user that lets them pick a new PIN,
+// also while the PIN is locked.
+func (s *PINService) RequestPINReset(ctx context.Context, userID uint64, email string) error {
+	wallet, pin, err := s.getWalletPIN(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if pin.ResetOTPSentAt != nil && now.Sub(*pin.ResetOTPSentAt) < otpResendInterval {
+		return constants.ErrOTPTooSoon
+	}
+
+	otp, err := generateOTP()
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(otp), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.Wrap(err, "failed to hash otp")
+	}
+
+	err = s.PINRepo.SaveResetOTP(ctx, wallet.ID, string(hash), now)
+	if err != nil {
+		return errors.Wrap(err, "failed to save reset otp")
+	}
+
+	err = s.OTPSender.SendOTP(ctx, models.OTPMessage{
+		UserID:    userID,
+		Email:     email,
+		Purpose:   constants.OTPPurposePINReset,
+		OTP:       otp,
+		ExpiresAt: now.Add(s.OTPTTL),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to send otp")
+	}
+
+	return s.recordPINEvent(ctx, "pin.reset_request", wallet.ID, nil)
+}
+
+func (s *PINService) ResetPIN(ctx context.Context, userID uint64, req models.ResetPINRequest) error {
+	wallet, pin, err := s.getWalletPIN(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if pin.ResetOTPHash == "" || pin.ResetOTPSentAt == nil || time.Since(*pin.ResetOTPSentAt) > s.OTPTTL {
+		return constants.ErrInvalidOTP
+	}
+
+	// the attempt is counted before the compare, a wrong guess racing others
+	// can not get past the limit
+	attempt, err := s.PINRepo.ReserveResetAttempt(ctx, wallet.ID, s.MaxAttempts)
+	if err != nil {
+		return errors.Wrap(err, "failed to reserve otp attempt")
+	}
+
+	if attempt == 0 {
+		return constants.ErrInvalidOTP
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(pin.ResetOTPHash), []byte(req.OTP)) != nil {
+		err = s.recordPINEvent(ctx, "pin.reset_failed", wallet.ID, map[string]interface{}{"attempt": attempt})
+		if err != nil {
+			return err
+		}
+		return constants.ErrInvalidOTP
+	}
+
+	err = s.updatePIN(ctx, wallet.ID, req.NewPIN)
+	if err != nil {
+		return err
+	}
+
+	return s.recordPINEvent(ctx, "pin.reset", wallet.ID, nil)
+}
+
+// VerifyPIN trades the PIN for a step-up token, so a client asks for the PIN
+// once for a few debits in a row.
+func (s *PINService) VerifyPIN(ctx context.Context, userID uint64, req models.VerifyPINRequest) (models.StepUpToken, error) {
+	var (
+		resp models.StepUpToken
+	)
+
+	wallet, pin, err := s.getWalletPIN(ctx, userID)
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.checkPIN(ctx, pin, req.PIN)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Token, resp.ExpiredAt, err = helpers.GenerateStepUpToken(ctx, s.StepUpJWT, userID, wallet.ID, time.Now(), s.StepUpTTL)
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.recordPINEvent(ctx, "pin.step_up", wallet.ID, map[string]interface{}{"expired_at": resp.ExpiredAt})
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Authorize lets a debit of amount from the user's wallet through when it is
+// at most Threshold, or when the request carries a step-up token for the
+// wallet or the right PIN.
+func (s *PINService) Authorize(ctx context.Context, userID uint64, amount float64) error {
+	if s == nil || amount <= s.Threshold {
+		return nil
+	}
+
+	auth := helpers.GetTransactionAuth(ctx)
+	if auth.StepUpToken != "" {
+		claims, err := helpers.ValidateStepUpToken(ctx, s.StepUpJWT, auth.StepUpToken)
+		if err != nil || claims.UserID != userID {
+			return constants.ErrInvalidStepUpToken
+		}
+		return nil
+	}
+
+	if auth.PIN == "" {
+		return constants.ErrPINRequired
+	}
+
+	_, pin, err := s.getWalletPIN(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.checkPIN(ctx, pin, auth.PIN)
+}
+
+func (s *PINService) getWalletPIN(ctx context.Context, userID uint64) (models.Wallet, models.WalletPIN, error) {
+	wallet, err := s.WalletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return wallet, models.WalletPIN{}, errors.Wrap(err, "failed to get wallet")
+	}
+
+	pin, err := s.PINRepo.GetWalletPIN(ctx, wallet.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return wallet, pin, constants.ErrPINNotSet
+		}
+		return wallet, pin, errors.Wrap(err, "failed to get wallet pin")
+	}
+
+	return wallet, pin, nil
+}
+
+// checkPIN compares given with the stored PIN. The try is counted before the
+// compare, a wrong PIN racing others can not get past MaxAttempts, and the
+// try reaching it locks the PIN for LockDuration unless the PIN was right.
+func (s *PINService) checkPIN(ctx context.Context, pin models.WalletPIN, given string) error {
+	now := time.Now()
+	lockUntil := now.Add(s.LockDuration)
+	attempt, err := s.PINRepo.ReservePINAttempt(ctx, pin.WalletID, s.MaxAttempts, now, lockUntil)
+	if err != nil {
+		return errors.Wrap(err, "failed to reserve pin attempt")
+	}
+
+	if attempt == 0 {
+		return constants.ErrPINLocked
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(pin.PINHash), []byte(given)) == nil {
+		err = s.PINRepo.ResetPINFailures(ctx, pin.WalletID)
+		if err != nil {
+			return errors.Wrap(err, "failed to reset pin failures")
+		}
+		return nil
+	}
+
+	if attempt >= s.MaxAttempts {
+		err = s.recordPINEvent(ctx, "pin.locked", pin.WalletID, map[string]interface{}{"locked_until": lockUntil})
+		if err != nil {
+			return err
+		}
+		return constants.ErrPINLocked
+	}
+
+	err = s.recordPINEvent(ctx, "pin.verify_failed", pin.WalletID, map[string]interface{}{"failed_attempts": attempt})
+	if err != nil {
+		return err
+	}
+
+	return constants.ErrInvalidPIN
+}
+
+func (s *PINService) updatePIN(ctx context.Context, walletID int, newPIN string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPIN), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.Wrap(err, "failed to hash pin")
+	}
+
+	err = s.PINRepo.UpdatePINHash(ctx, walletID, string(hash), time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to update wallet pin")
+	}
+
+	return nil
+}
+
+// recordPINEvent audits what happened to the PIN, never the PIN itself.
+func (s *PINService) recordPINEvent(ctx context.Context, action string, walletID int, detail interface{}) error {
+	return s.AuditService.Record(ctx, action, constants.AuditTargetPIN, strconv.Itoa(walletID), nil, nil, detail)
+}
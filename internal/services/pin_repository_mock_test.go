@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_pin_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIPINRepo is a mock of IPINRepo interface.
+type MockIPINRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPINRepoMockRecorder
+}
+
+// MockIPINRepoMockRecorder is the mock recorder for MockIPINRepo.
+type MockIPINRepoMockRecorder struct {
+	mock *MockIPINRepo
+}
+
+// NewMockIPINRepo creates a new mock instance.
+func NewMockIPINRepo(ctrl *gomock.Controller) *MockIPINRepo {
+	mock := &MockIPINRepo{ctrl: ctrl}
+	mock.recorder = &MockIPINRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPINRepo) EXPECT() *MockIPINRepoMockRecorder {
+	return m.recorder
+}
+
+// CreateWalletPIN mocks base method.
+func (m *MockIPINRepo) CreateWalletPIN(ctx context.Context, pin *models.WalletPIN) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWalletPIN", ctx, pin)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWalletPIN indicates an expected call of CreateWalletPIN.
+func (mr *MockIPINRepoMockRecorder) CreateWalletPIN(ctx, pin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWalletPIN", reflect.TypeOf((*MockIPINRepo)(nil).CreateWalletPIN), ctx, pin)
+}
+
+// GetWalletPIN mocks base method.
+func (m *MockIPINRepo) GetWalletPIN(ctx context.Context, walletID int) (models.WalletPIN, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletPIN", ctx, walletID)
+	ret0, _ := ret[0].(models.WalletPIN)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletPIN indicates an expected call of GetWalletPIN.
+func (mr *MockIPINRepoMockRecorder) GetWalletPIN(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletPIN", reflect.TypeOf((*MockIPINRepo)(nil).GetWalletPIN), ctx, walletID)
+}
+
+// ReservePINAttempt mocks base method.
+func (m *MockIPINRepo) ReservePINAttempt(ctx context.Context, walletID, maxAttempts int, now, lockUntil time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReservePINAttempt", ctx, walletID, maxAttempts, now, lockUntil)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReservePINAttempt indicates an expected call of ReservePINAttempt.
+func (mr *MockIPINRepoMockRecorder) ReservePINAttempt(ctx, walletID, maxAttempts, now, lockUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReservePINAttempt", reflect.TypeOf((*MockIPINRepo)(nil).ReservePINAttempt), ctx, walletID, maxAttempts, now, lockUntil)
+}
+
+// ReserveResetAttempt mocks base method.
+func (m *MockIPINRepo) ReserveResetAttempt(ctx context.Context, walletID, maxAttempts int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveResetAttempt", ctx, walletID, maxAttempts)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveResetAttempt indicates an expected call of ReserveResetAttempt.
+func (mr *MockIPINRepoMockRecorder) ReserveResetAttempt(ctx, walletID, maxAttempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveResetAttempt", reflect.TypeOf((*MockIPINRepo)(nil).ReserveResetAttempt), ctx, walletID, maxAttempts)
+}
+
+// ResetPINFailures mocks base method.
+func (m *MockIPINRepo) ResetPINFailures(ctx context.Context, walletID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPINFailures", ctx, walletID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPINFailures indicates an expected call of ResetPINFailures.
+func (mr *MockIPINRepoMockRecorder) ResetPINFailures(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPINFailures", reflect.TypeOf((*MockIPINRepo)(nil).ResetPINFailures), ctx, walletID)
+}
+
+// SaveResetOTP mocks base method.
+func (m *MockIPINRepo) SaveResetOTP(ctx context.Context, walletID int, otpHash string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveResetOTP", ctx, walletID, otpHash, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveResetOTP indicates an expected call of SaveResetOTP.
+func (mr *MockIPINRepoMockRecorder) SaveResetOTP(ctx, walletID, otpHash, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveResetOTP", reflect.TypeOf((*MockIPINRepo)(nil).SaveResetOTP), ctx, walletID, otpHash, now)
+}
+
+// UpdatePINHash mocks base method.
+func (m *MockIPINRepo) UpdatePINHash(ctx context.Context, walletID int, pinHash string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePINHash", ctx, walletID, pinHash, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePINHash indicates an expected call of UpdatePINHash.
+func (mr *MockIPINRepoMockRecorder) UpdatePINHash(ctx, walletID, pinHash, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePINHash", reflect.TypeOf((*MockIPINRepo)(nil).UpdatePINHash), ctx, walletID, pinHash, now)
+}
@@ -0,0 +1,494 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func newTestPINHash(t *testing.T, pin string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.MinCost)
+	assert.NoError(t, err)
+	return string(hash)
+}
+
+func TestPINService_Authorize(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockPINRepo := NewMockIPINRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	stepUpJWT := helpers.JWTConfig{Secret: "step-up-secret"}
+
+	now := time.Now()
+	stepUpToken, _, err := helpers.GenerateStepUpToken(context.Background(), stepUpJWT, 7, 1, now, time.Minute)
+	assert.NoError(t, err)
+	otherUserToken, _, err := helpers.GenerateStepUpToken(context.Background(), stepUpJWT, 8, 2, now, time.Minute)
+	assert.NoError(t, err)
+	expiredToken, _, err := helpers.GenerateStepUpToken(context.Background(), stepUpJWT, 7, 1, now.Add(-time.Hour), time.Minute)
+	assert.NoError(t, err)
+
+	pin := models.WalletPIN{ID: 3, WalletID: 1, PINHash: newTestPINHash(t, "123456")}
+
+	tests := []struct {
+		name    string
+		amount  float64
+		auth    helpers.TransactionAuth
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name:   "success below threshold",
+			amount: 100000,
+			mockFn: func() {},
+		},
+		{
+			name:   "success step-up token",
+			amount: 2000000,
+			auth:   helpers.TransactionAuth{StepUpToken: stepUpToken},
+			mockFn: func() {},
+		},
+		{
+			name:   "success pin",
+			amount: 2000000,
+			auth:   helpers.TransactionAuth{PIN: "123456"},
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(pin, nil)
+				mockPINRepo.EXPECT().ReservePINAttempt(gomock.Any(), 1, 3, gomock.Any(), gomock.Any()).Return(1, nil)
+				mockPINRepo.EXPECT().ResetPINFailures(gomock.Any(), 1).Return(nil)
+			},
+		},
+		{
+			name:   "success right pin on the last attempt",
+			amount: 2000000,
+			auth:   helpers.TransactionAuth{PIN: "123456"},
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(pin, nil)
+				mockPINRepo.EXPECT().ReservePINAttempt(gomock.Any(), 1, 3, gomock.Any(), gomock.Any()).Return(3, nil)
+				mockPINRepo.EXPECT().ResetPINFailures(gomock.Any(), 1).Return(nil)
+			},
+		},
+		{
+			name:    "error no pin sent",
+			amount:  2000000,
+			wantErr: constants.ErrPINRequired,
+			mockFn:  func() {},
+		},
+		{
+			name:    "error step-up token of another user",
+			amount:  2000000,
+			auth:    helpers.TransactionAuth{StepUpToken: otherUserToken},
+			wantErr: constants.ErrInvalidStepUpToken,
+			mockFn:  func() {},
+		},
+		{
+			name:    "error step-up token expired",
+			amount:  2000000,
+			auth:    helpers.TransactionAuth{StepUpToken: expiredToken},
+			wantErr: constants.ErrInvalidStepUpToken,
+			mockFn:  func() {},
+		},
+		{
+			name:    "error pin not set",
+			amount:  2000000,
+			auth:    helpers.TransactionAuth{PIN: "123456"},
+			wantErr: constants.ErrPINNotSet,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(models.WalletPIN{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:    "error wrong pin",
+			amount:  2000000,
+			auth:    helpers.TransactionAuth{PIN: "654321"},
+			wantErr: constants.ErrInvalidPIN,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(pin, nil)
+				mockPINRepo.EXPECT().ReservePINAttempt(gomock.Any(), 1, 3, gomock.Any(), gomock.Any()).Return(1, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "pin.verify_failed", auditLog.Action)
+					assert.Equal(t, constants.AuditTargetPIN, auditLog.TargetType)
+					assert.JSONEq(t, `{"failed_attempts":1}`, auditLog.Detail)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error wrong pin locks the pin",
+			amount:  2000000,
+			auth:    helpers.TransactionAuth{PIN: "654321"},
+			wantErr: constants.ErrPINLocked,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(pin, nil)
+				mockPINRepo.EXPECT().ReservePINAttempt(gomock.Any(), 1, 3, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, walletID int, maxAttempts int, now time.Time, lockUntil time.Time) (int, error) {
+						assert.Equal(t, now.Add(15*time.Minute), lockUntil)
+						return 3, nil
+					})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "pin.locked", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error locked pin is not compared",
+			amount:  2000000,
+			auth:    helpers.TransactionAuth{PIN: "123456"},
+			wantErr: constants.ErrPINLocked,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(pin, nil)
+				mockPINRepo.EXPECT().ReservePINAttempt(gomock.Any(), 1, 3, gomock.Any(), gomock.Any()).Return(0, nil)
+			},
+		},
+		{
+			name:    "error reserve attempt",
+			amount:  2000000,
+			auth:    helpers.TransactionAuth{PIN: "123456"},
+			wantErr: assert.AnError,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(pin, nil)
+				mockPINRepo.EXPECT().ReservePINAttempt(gomock.Any(), 1, 3, gomock.Any(), gomock.Any()).Return(0, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &PINService{
+				PINRepo:      mockPINRepo,
+				WalletRepo:   mockWalletRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				Threshold:    1000000,
+				MaxAttempts:  3,
+				LockDuration: 15 * time.Minute,
+				StepUpJWT:    stepUpJWT,
+			}
+			ctx := helpers.WithTransactionAuth(context.Background(), tt.auth)
+			err := s.Authorize(ctx, 7, tt.amount)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("nil service asks for no pin", func(t *testing.T) {
+		var s *PINService
+		assert.NoError(t, s.Authorize(context.Background(), 7, 2000000))
+	})
+}
+
+func TestPINService_SetPIN(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockPINRepo := NewMockIPINRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().CreateWalletPIN(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pin *models.WalletPIN) error {
+					assert.Equal(t, 1, pin.WalletID)
+					assert.NotEqual(t, "123456", pin.PINHash)
+					assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(pin.PINHash), []byte("123456")))
+					return nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "pin.set", auditLog.Action)
+					assert.Equal(t, "1", auditLog.TargetID)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error already set",
+			wantErr: constants.ErrPINAlreadySet,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().CreateWalletPIN(gomock.Any(), gomock.Any()).Return(gorm.ErrDuplicatedKey)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &PINService{
+				PINRepo:      mockPINRepo,
+				WalletRepo:   mockWalletRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+			}
+			err := s.SetPIN(context.Background(), 7, models.SetPINRequest{PIN: "123456"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPINService_RequestPINReset(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockPINRepo := NewMockIPINRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockOTPSender := NewMockOTPSender(ctrlMock)
+
+	justSent := time.Now().Add(-10 * time.Second)
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				var otpHash string
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(models.WalletPIN{WalletID: 1}, nil)
+				mockPINRepo.EXPECT().SaveResetOTP(gomock.Any(), 1, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, walletID int, hash string, now time.Time) error {
+						otpHash = hash
+						return nil
+					})
+				mockOTPSender.EXPECT().SendOTP(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg models.OTPMessage) error {
+					assert.Equal(t, "user@mail.com", msg.Email)
+					assert.Equal(t, constants.OTPPurposePINReset, msg.Purpose)
+					assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(otpHash), []byte(msg.OTP)))
+					return nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "pin.reset_request", auditLog.Action)
+					assert.NotContains(t, auditLog.Detail, "otp")
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error otp sent moments ago",
+			wantErr: constants.ErrOTPTooSoon,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(models.WalletPIN{WalletID: 1, ResetOTPSentAt: &justSent}, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &PINService{
+				PINRepo:      mockPINRepo,
+				WalletRepo:   mockWalletRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				OTPSender:    mockOTPSender,
+				OTPTTL:       10 * time.Minute,
+			}
+			err := s.RequestPINReset(context.Background(), 7, "user@mail.com")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPINService_ResetPIN(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockPINRepo := NewMockIPINRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	sentAt := time.Now().Add(-time.Minute)
+	expiredAt := time.Now().Add(-time.Hour)
+	lockedUntil := time.Now().Add(time.Hour)
+	pin := models.WalletPIN{WalletID: 1, ResetOTPHash: newTestPINHash(t, "246810"), ResetOTPSentAt: &sentAt, LockedUntil: &lockedUntil}
+
+	tests := []struct {
+		name    string
+		otp     string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success while the pin is locked",
+			otp:  "246810",
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(pin, nil)
+				mockPINRepo.EXPECT().ReserveResetAttempt(gomock.Any(), 1, 3).Return(1, nil)
+				mockPINRepo.EXPECT().UpdatePINHash(gomock.Any(), 1, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, walletID int, hash string, now time.Time) error {
+						assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("135790")))
+						return nil
+					})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "pin.reset", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error wrong otp",
+			otp:     "111111",
+			wantErr: constants.ErrInvalidOTP,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(pin, nil)
+				mockPINRepo.EXPECT().ReserveResetAttempt(gomock.Any(), 1, 3).Return(2, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "pin.reset_failed", auditLog.Action)
+					assert.JSONEq(t, `{"attempt":2}`, auditLog.Detail)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error attempts used up",
+			otp:     "246810",
+			wantErr: constants.ErrInvalidOTP,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(pin, nil)
+				mockPINRepo.EXPECT().ReserveResetAttempt(gomock.Any(), 1, 3).Return(0, nil)
+			},
+		},
+		{
+			name:    "error reserve attempt",
+			otp:     "246810",
+			wantErr: assert.AnError,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(pin, nil)
+				mockPINRepo.EXPECT().ReserveResetAttempt(gomock.Any(), 1, 3).Return(0, assert.AnError)
+			},
+		},
+		{
+			name:    "error otp expired",
+			otp:     "246810",
+			wantErr: constants.ErrInvalidOTP,
+			mockFn: func() {
+				expired := pin
+				expired.ResetOTPSentAt = &expiredAt
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(expired, nil)
+			},
+		},
+		{
+			name:    "error no otp requested",
+			otp:     "246810",
+			wantErr: constants.ErrInvalidOTP,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(models.WalletPIN{WalletID: 1}, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &PINService{
+				PINRepo:      mockPINRepo,
+				WalletRepo:   mockWalletRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				MaxAttempts:  3,
+				OTPTTL:       10 * time.Minute,
+			}
+			err := s.ResetPIN(context.Background(), 7, models.ResetPINRequest{OTP: tt.otp, NewPIN: "135790"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPINService_VerifyPIN(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockPINRepo := NewMockIPINRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	stepUpJWT := helpers.JWTConfig{Secret: "step-up-secret"}
+
+	mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+	mockPINRepo.EXPECT().GetWalletPIN(gomock.Any(), 1).Return(models.WalletPIN{WalletID: 1, PINHash: newTestPINHash(t, "123456")}, nil)
+	mockPINRepo.EXPECT().ReservePINAttempt(gomock.Any(), 1, 3, gomock.Any(), gomock.Any()).Return(1, nil)
+	mockPINRepo.EXPECT().ResetPINFailures(gomock.Any(), 1).Return(nil)
+	mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+		assert.Equal(t, "pin.step_up", auditLog.Action)
+		return nil
+	})
+
+	s := &PINService{
+		PINRepo:      mockPINRepo,
+		WalletRepo:   mockWalletRepo,
+		AuditService: &AuditService{AuditRepo: mockAuditRepo},
+		MaxAttempts:  3,
+		StepUpTTL:    5 * time.Minute,
+		StepUpJWT:    stepUpJWT,
+	}
+	got, err := s.VerifyPIN(context.Background(), 7, models.VerifyPINRequest{PIN: "123456"})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), got.ExpiredAt, time.Minute)
+
+	claims, err := helpers.ValidateStepUpToken(context.Background(), stepUpJWT, got.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), claims.UserID)
+	assert.Equal(t, 1, claims.WalletID)
+}
+
+func TestWalletService_DebitBalanceAboveThreshold(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+
+	mockRepo.EXPECT().GetWalletTransactionByReference(gomock.Any(), "reference").Return(models.WalletTransaction{}, gorm.ErrRecordNotFound)
+
+	s := &WalletService{
+		WalletRepo: mockRepo,
+		PINService: &PINService{Threshold: 1000000},
+	}
+	_, err := s.DebitBalance(context.Background(), 7, models.TransactionRequest{Amount: 2000000, Reference: "reference"})
+	assert.ErrorIs(t, err, constants.ErrPINRequired)
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const promoExpiryBatchSize = 100
+
+// PromoService grants promo credit, as campaign cashback or by hand, and takes
+// it back when it expires. A nil PromoService pays no cashback.
+type PromoService struct {
+	PromoRepo     i_repository.IPromoRepo
+	WalletService *WalletService
+	AuditService  *AuditService
+}
+
+func (s *PromoService) CreateCampaign(ctx context.Context, createdBy string, req models.CampaignRequest) (models.Campaign, error) {
+	resp := models.Campaign{
+		Name:         req.Name,
+		ClientSource: req.ClientSource,
+		Percentage:   req.Percentage,
+		MaxCashback:  req.MaxCashback,
+		ValidityDays: req.ValidityDays,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		Active:       true,
+		CreatedBy:    createdBy,
+	}
+	if resp.ValidityDays == 0 {
+		resp.ValidityDays = constants.DefaultPromoValidityDays
+	}
+
+	if _, ok := constants.MappingClient[req.ClientSource]; !ok {
+		return resp, fmt.Errorf("%w: unknown client source %s", constants.ErrInvalidCampaign, req.ClientSource)
+	}
+
+	err := s.PromoRepo.CreateCampaign(ctx, &resp)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to create campaign")
+	}
+
+	err = s.AuditService.Record(ctx, "campaign.create", constants.AuditTargetCampaign, strconv.Itoa(resp.ID), nil, resp, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+func (s *PromoService) GetCampaigns(ctx context.Context, param models.CampaignParam) ([]models.Campaign, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.PromoRepo.GetCampaigns(ctx, param.ClientSource, offset, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get campaigns")
+	}
+
+	return resp, nil
+}
+
+// DisableCampaign stops the cashback, the credit already granted keeps its
+// expiry.
+func (s *PromoService) DisableCampaign(ctx context.Context, campaignID int) error {
+	err := s.PromoRepo.DisableCampaign(ctx, campaignID, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to disable campaign")
+	}
+
+	return s.AuditService.Record(ctx, "campaign.disable", constants.AuditTargetCampaign, strconv.Itoa(campaignID),
+		map[string]interface{}{"active": true}, map[string]interface{}{"active": false}, nil)
+}
+
+// GrantCashback pays the cashback of a partner debit as promo credit, from the
+// running campaign of the partner that gives the most. The credit row is
+// referenced by the debit id, so a debit is never paid cashback twice.
+func (s *PromoService) GrantCashback(ctx context.Context, debit *models.WalletTransaction) (float64, error) {
+	if s == nil {
+		return 0, nil
+	}
+
+	now := time.Now()
+	campaigns, err := s.PromoRepo.GetActiveCampaigns(ctx, debit.ClientID, now)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get campaigns")
+	}
+
+	var (
+		campaign models.Campaign
+		cashback float64
+	)
+	for _, c := range campaigns {
+		amount := calculateCashback(c, debit.Amount)
+		if amount > cashback {
+			campaign, cashback = c, amount
+		}
+	}
+
+	if cashback <= 0 {
+		return 0, nil
+	}
+
+	grant := &models.PromoGrant{
+		WalletID:   debit.WalletID,
+		CampaignID: campaign.ID,
+		Amount:     cashback,
+		Status:     constants.PromoGrantStatusActive,
+		ExpiresAt:  now.AddDate(0, 0, campaign.ValidityDays),
+		GrantedBy:  "campaign",
+	}
+	credit := &models.WalletTransaction{
+		WalletID:              debit.WalletID,
+		Amount:                cashback,
+		WalletTransactionType: constants.TransactionTypeCredit,
+		Reference:             fmt.Sprintf("CASHBACK-%d", debit.ID),
+		ParentID:              debit.ID,
+		BalanceType:           constants.BalanceTypePromo,
+	}
+
+	err = s.grant(ctx, grant, credit)
+	if err != nil {
+		return 0, err
+	}
+
+	return cashback, nil
+}
+
+// GrantPromoCredit gives promo credit by hand, a retried request with the
+// same reference is refused.
+func (s *PromoService) GrantPromoCredit(ctx context.Context, grantedBy string, walletID int, req models.PromoCreditRequest) (models.PromoGrant, error) {
+	validityDays := req.ValidityDays
+	if validityDays == 0 {
+		validityDays = constants.DefaultPromoValidityDays
+	}
+
+	grant := &models.PromoGrant{
+		WalletID:  walletID,
+		Amount:    req.Amount,
+		Status:    constants.PromoGrantStatusActive,
+		ExpiresAt: time.Now().AddDate(0, 0, validityDays),
+		GrantedBy: grantedBy,
+		Note:      req.Note,
+	}
+	credit := &models.WalletTransaction{
+		WalletID:              walletID,
+		Amount:                req.Amount,
+		WalletTransactionType: constants.TransactionTypeCredit,
+		Reference:             "PROMO-GRANT-" + req.Reference,
+		BalanceType:           constants.BalanceTypePromo,
+	}
+
+	err := s.grant(ctx, grant, credit)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return *grant, constants.ErrDuplicateReference
+		}
+		return *grant, err
+	}
+
+	return *grant, nil
+}
+
+func (s *PromoService) GetPromoGrants(ctx context.Context, walletID int, param models.CampaignParam) ([]models.PromoGrant, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.PromoRepo.GetPromoGrants(ctx, walletID, offset, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get promo grants")
+	}
+
+	return resp, nil
+}
+
+// ExpirePromoGrants takes back what is left of the grants expired by now.
+func (s *PromoService) ExpirePromoGrants(ctx context.Context, now time.Time) (int, error) {
+	grants, err := s.PromoRepo.GetExpiredPromoGrants(ctx, now, promoExpiryBatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get expired promo grants")
+	}
+
+	expired := 0
+	for _, grant := range grants {
+		clawback := &models.WalletTransaction{
+			WalletID:              grant.WalletID,
+			WalletTransactionType: constants.TransactionTypeDebit,
+			Reference:             fmt.Sprintf("PROMO-EXP-%d", grant.ID),
+			ParentID:              grant.TransactionID,
+			BalanceType:           constants.BalanceTypePromo,
+		}
+
+		wallet, err := s.PromoRepo.ExpirePromoGrant(ctx, grant, clawback, now)
+		if err != nil {
+			// expired by another worker since it was read
+			if errors.Is(err, constants.ErrPromoGrantNotFound) {
+				continue
+			}
+			return expired, errors.Wrap(err, "failed to expire promo grant")
+		}
+		expired++
+
+		if clawback.Amount > 0 {
+			s.WalletService.recordBalanceChange(ctx, "balance.promo_expire", wallet.ID, wallet.Balance, wallet.Balance-clawback.Amount, clawback)
+		}
+
+		s.AuditService.RecordCommitted(ctx, "promo_grant.expire", constants.AuditTargetPromoGrant, strconv.Itoa(grant.ID),
+			map[string]interface{}{"status": constants.PromoGrantStatusActive},
+			map[string]interface{}{"status": constants.PromoGrantStatusExpired, "clawed_back": clawback.Amount}, nil)
+	}
+
+	return expired, nil
+}
+
+// RunExpiryWorker expires promo credit every interval until ctx is cancelled.
+func (s *PromoService) RunExpiryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := s.ExpirePromoGrants(ctx, time.Now())
+		if err != nil {
+			log.Println("failed to expire promo credit: ", err)
+			continue
+		}
+
+		if expired > 0 {
+			log.Printf("%d promo grants expired\n", expired)
+		}
+	}
+}
+
+func (s *PromoService) grant(ctx context.Context, grant *models.PromoGrant, credit *models.WalletTransaction) error {
+	wallet, err := s.PromoRepo.GrantPromo(ctx, grant, credit)
+	if err != nil {
+		return errors.Wrap(err, "failed to grant promo credit")
+	}
+
+	s.WalletService.recordBalanceChange(ctx, "balance.promo_grant", wallet.ID, wallet.Balance, wallet.Balance+grant.Amount, credit)
+
+	s.AuditService.RecordCommitted(ctx, "promo_grant.create", constants.AuditTargetPromoGrant, strconv.Itoa(grant.ID), nil, grant, nil)
+
+	return nil
+}
+
+func (s *AdminService) CreateCampaign(ctx context.Context, actor models.OperatorTokenData, req models.CampaignRequest) (models.Campaign, error) {
+	return s.PromoService.CreateCampaign(operatorContext(ctx, actor), actor.Username, req)
+}
+
+func (s *AdminService) GetCampaigns(ctx context.Context, actor models.OperatorTokenData, param models.CampaignParam) ([]models.Campaign, error) {
+	return s.PromoService.GetCampaigns(ctx, param)
+}
+
+func (s *AdminService) DisableCampaign(ctx context.Context, actor models.OperatorTokenData, campaignID int) error {
+	return s.PromoService.DisableCampaign(operatorContext(ctx, actor), campaignID)
+}
+
+func (s *AdminService) GrantPromoCredit(ctx context.Context, actor models.OperatorTokenData, walletID int, req models.PromoCreditRequest) (models.PromoGrant, error) {
+	return s.PromoService.GrantPromoCredit(operatorContext(ctx, actor), actor.Username, walletID, req)
+}
+
+func (s *AdminService) GetPromoGrants(ctx context.Context, actor models.OperatorTokenData, walletID int, param models.CampaignParam) ([]models.PromoGrant, error) {
+	return s.PromoService.GetPromoGrants(ctx, walletID, param)
+}
+
+func calculateCashback(campaign models.Campaign, amount float64) float64 {
+	cashback := math.Min(amount*campaign.Percentage/100, campaign.MaxCashback)
+
+	// balances are stored with two decimals
+	return math.Floor(cashback*100) / 100
+}
@@ -0,0 +1,155 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_promo_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIPromoRepo is a mock of IPromoRepo interface.
+type MockIPromoRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPromoRepoMockRecorder
+}
+
+// MockIPromoRepoMockRecorder is the mock recorder for MockIPromoRepo.
+type MockIPromoRepoMockRecorder struct {
+	mock *MockIPromoRepo
+}
+
+// NewMockIPromoRepo creates a new mock instance.
+func NewMockIPromoRepo(ctrl *gomock.Controller) *MockIPromoRepo {
+	mock := &MockIPromoRepo{ctrl: ctrl}
+	mock.recorder = &MockIPromoRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPromoRepo) EXPECT() *MockIPromoRepoMockRecorder {
+	return m.recorder
+}
+
+// CreateCampaign mocks base method.
+func (m *MockIPromoRepo) CreateCampaign(ctx context.Context, campaign *models.Campaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", ctx, campaign)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockIPromoRepoMockRecorder) CreateCampaign(ctx, campaign interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockIPromoRepo)(nil).CreateCampaign), ctx, campaign)
+}
+
+// DisableCampaign mocks base method.
+func (m *MockIPromoRepo) DisableCampaign(ctx context.Context, campaignID int, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableCampaign", ctx, campaignID, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableCampaign indicates an expected call of DisableCampaign.
+func (mr *MockIPromoRepoMockRecorder) DisableCampaign(ctx, campaignID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableCampaign", reflect.TypeOf((*MockIPromoRepo)(nil).DisableCampaign), ctx, campaignID, now)
+}
+
+// ExpirePromoGrant mocks base method.
+func (m *MockIPromoRepo) ExpirePromoGrant(ctx context.Context, grant models.PromoGrant, clawback *models.WalletTransaction, now time.Time) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePromoGrant", ctx, grant, clawback, now)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePromoGrant indicates an expected call of ExpirePromoGrant.
+func (mr *MockIPromoRepoMockRecorder) ExpirePromoGrant(ctx, grant, clawback, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePromoGrant", reflect.TypeOf((*MockIPromoRepo)(nil).ExpirePromoGrant), ctx, grant, clawback, now)
+}
+
+// GetActiveCampaigns mocks base method.
+func (m *MockIPromoRepo) GetActiveCampaigns(ctx context.Context, clientSource string, now time.Time) ([]models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveCampaigns", ctx, clientSource, now)
+	ret0, _ := ret[0].([]models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveCampaigns indicates an expected call of GetActiveCampaigns.
+func (mr *MockIPromoRepoMockRecorder) GetActiveCampaigns(ctx, clientSource, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveCampaigns", reflect.TypeOf((*MockIPromoRepo)(nil).GetActiveCampaigns), ctx, clientSource, now)
+}
+
+// GetCampaigns mocks base method.
+func (m *MockIPromoRepo) GetCampaigns(ctx context.Context, clientSource string, offset, limit int) ([]models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaigns", ctx, clientSource, offset, limit)
+	ret0, _ := ret[0].([]models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaigns indicates an expected call of GetCampaigns.
+func (mr *MockIPromoRepoMockRecorder) GetCampaigns(ctx, clientSource, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaigns", reflect.TypeOf((*MockIPromoRepo)(nil).GetCampaigns), ctx, clientSource, offset, limit)
+}
+
+// GetExpiredPromoGrants mocks base method.
+func (m *MockIPromoRepo) GetExpiredPromoGrants(ctx context.Context, now time.Time, limit int) ([]models.PromoGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredPromoGrants", ctx, now, limit)
+	ret0, _ := ret[0].([]models.PromoGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredPromoGrants indicates an expected call of GetExpiredPromoGrants.
+func (mr *MockIPromoRepoMockRecorder) GetExpiredPromoGrants(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredPromoGrants", reflect.TypeOf((*MockIPromoRepo)(nil).GetExpiredPromoGrants), ctx, now, limit)
+}
+
+// GetPromoGrants mocks base method.
+func (m *MockIPromoRepo) GetPromoGrants(ctx context.Context, walletID, offset, limit int) ([]models.PromoGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromoGrants", ctx, walletID, offset, limit)
+	ret0, _ := ret[0].([]models.PromoGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromoGrants indicates an expected call of GetPromoGrants.
+func (mr *MockIPromoRepoMockRecorder) GetPromoGrants(ctx, walletID, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromoGrants", reflect.TypeOf((*MockIPromoRepo)(nil).GetPromoGrants), ctx, walletID, offset, limit)
+}
+
+// GrantPromo mocks base method.
+func (m *MockIPromoRepo) GrantPromo(ctx context.Context, grant *models.PromoGrant, credit *models.WalletTransaction) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantPromo", ctx, grant, credit)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantPromo indicates an expected call of GrantPromo.
+func (mr *MockIPromoRepoMockRecorder) GrantPromo(ctx, grant, credit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantPromo", reflect.TypeOf((*MockIPromoRepo)(nil).GrantPromo), ctx, grant, credit)
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_calculateCashback(t *testing.T) {
+	tests := []struct {
+		name     string
+		campaign models.Campaign
+		amount   float64
+		want     float64
+	}{
+		{
+			name:     "percentage of the debit",
+			campaign: models.Campaign{Percentage: 5, MaxCashback: 10000},
+			amount:   50000,
+			want:     2500,
+		},
+		{
+			name:     "capped per debit",
+			campaign: models.Campaign{Percentage: 5, MaxCashback: 1000},
+			amount:   50000,
+			want:     1000,
+		},
+		{
+			name:     "rounded down to two decimals",
+			campaign: models.Campaign{Percentage: 1.5, MaxCashback: 10000},
+			amount:   333.33,
+			want:     4.99,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, calculateCashback(tt.campaign, tt.amount))
+		})
+	}
+}
+
+func TestPromoService_GrantCashback(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockPromoRepo := NewMockIPromoRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	debit := &models.WalletTransaction{ID: 11, WalletID: 1, Amount: 50000, WalletTransactionType: constants.TransactionTypeDebit, ClientID: "fastcampus_ecommerce"}
+	small := models.Campaign{ID: 1, Percentage: 1, MaxCashback: 10000, ValidityDays: 30}
+	big := models.Campaign{ID: 2, Percentage: 5, MaxCashback: 2000, ValidityDays: 7}
+
+	tests := []struct {
+		name    string
+		want    float64
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success campaign paying the most",
+			want: 2000,
+			mockFn: func() {
+				mockPromoRepo.EXPECT().GetActiveCampaigns(gomock.Any(), "fastcampus_ecommerce", gomock.Any()).Return([]models.Campaign{small, big}, nil)
+				mockPromoRepo.EXPECT().GrantPromo(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, grant *models.PromoGrant, credit *models.WalletTransaction) (models.Wallet, error) {
+						assert.Equal(t, 2, grant.CampaignID)
+						assert.Equal(t, float64(2000), grant.Amount)
+						assert.WithinDuration(t, time.Now().AddDate(0, 0, 7), grant.ExpiresAt, time.Minute)
+						assert.Equal(t, models.WalletTransaction{
+							WalletID:              1,
+							Amount:                2000,
+							WalletTransactionType: constants.TransactionTypeCredit,
+							Reference:             "CASHBACK-11",
+							ParentID:              11,
+							BalanceType:           constants.BalanceTypePromo,
+						}, *credit)
+						grant.ID = 5
+						return models.Wallet{ID: 1, Balance: 50000}, nil
+					})
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.promo_grant", auditLog.Action)
+						assert.JSONEq(t, `{"balance":52000}`, auditLog.After)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "promo_grant.create", auditLog.Action)
+						assert.Equal(t, "5", auditLog.TargetID)
+						return nil
+					}),
+				)
+			},
+		},
+		{
+			name: "success no running campaign",
+			mockFn: func() {
+				mockPromoRepo.EXPECT().GetActiveCampaigns(gomock.Any(), "fastcampus_ecommerce", gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name:    "error grant",
+			wantErr: true,
+			mockFn: func() {
+				mockPromoRepo.EXPECT().GetActiveCampaigns(gomock.Any(), "fastcampus_ecommerce", gomock.Any()).Return([]models.Campaign{small}, nil)
+				mockPromoRepo.EXPECT().GrantPromo(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.Wallet{}, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+			s := &PromoService{
+				PromoRepo:     mockPromoRepo,
+				WalletService: &WalletService{AuditService: auditSvc},
+				AuditService:  auditSvc,
+			}
+			got, err := s.GrantCashback(context.Background(), debit)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("nil service pays no cashback", func(t *testing.T) {
+		var s *PromoService
+		got, err := s.GrantCashback(context.Background(), debit)
+		assert.NoError(t, err)
+		assert.Zero(t, got)
+	})
+}
+
+func TestPromoService_GrantPromoCredit(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockPromoRepo := NewMockIPromoRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	req := models.PromoCreditRequest{Amount: 10000, Reference: "GOODWILL-1", Note: "late refund"}
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mockPromoRepo.EXPECT().GrantPromo(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, grant *models.PromoGrant, credit *models.WalletTransaction) (models.Wallet, error) {
+						assert.Equal(t, "finance", grant.GrantedBy)
+						assert.WithinDuration(t, time.Now().AddDate(0, 0, constants.DefaultPromoValidityDays), grant.ExpiresAt, time.Minute)
+						assert.Equal(t, "PROMO-GRANT-GOODWILL-1", credit.Reference)
+						assert.Equal(t, constants.BalanceTypePromo, credit.BalanceType)
+						return models.Wallet{ID: 1}, nil
+					})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			},
+		},
+		{
+			name:    "error reference already granted",
+			wantErr: constants.ErrDuplicateReference,
+			mockFn: func() {
+				mockPromoRepo.EXPECT().GrantPromo(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.Wallet{}, gorm.ErrDuplicatedKey)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+			s := &PromoService{
+				PromoRepo:     mockPromoRepo,
+				WalletService: &WalletService{AuditService: auditSvc},
+				AuditService:  auditSvc,
+			}
+			_, err := s.GrantPromoCredit(context.Background(), "finance", 1, req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPromoService_ExpirePromoGrants(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockPromoRepo := NewMockIPromoRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	spent := models.PromoGrant{ID: 4, WalletID: 1, Amount: 1000, TransactionID: 10, ExpiresAt: now}
+	left := models.PromoGrant{ID: 5, WalletID: 1, Amount: 3000, TransactionID: 12, ExpiresAt: now}
+	raced := models.PromoGrant{ID: 6, WalletID: 2, Amount: 3000, TransactionID: 13, ExpiresAt: now}
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			want: 2,
+			mockFn: func() {
+				mockPromoRepo.EXPECT().GetExpiredPromoGrants(gomock.Any(), now, promoExpiryBatchSize).Return([]models.PromoGrant{spent, left, raced}, nil)
+				mockPromoRepo.EXPECT().ExpirePromoGrant(gomock.Any(), spent, gomock.Any(), now).Return(models.Wallet{ID: 1, Balance: 20000}, nil)
+				mockPromoRepo.EXPECT().ExpirePromoGrant(gomock.Any(), left, gomock.Any(), now).
+					DoAndReturn(func(ctx context.Context, grant models.PromoGrant, clawback *models.WalletTransaction, now time.Time) (models.Wallet, error) {
+						assert.Equal(t, "PROMO-EXP-5", clawback.Reference)
+						assert.Equal(t, 12, clawback.ParentID)
+						assert.Equal(t, constants.BalanceTypePromo, clawback.BalanceType)
+						clawback.Amount = 2000
+						return models.Wallet{ID: 1, Balance: 20000}, nil
+					})
+				mockPromoRepo.EXPECT().ExpirePromoGrant(gomock.Any(), raced, gomock.Any(), now).Return(models.Wallet{}, constants.ErrPromoGrantNotFound)
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "promo_grant.expire", auditLog.Action)
+						assert.Equal(t, "4", auditLog.TargetID)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.promo_expire", auditLog.Action)
+						assert.JSONEq(t, `{"balance":18000}`, auditLog.After)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "promo_grant.expire", auditLog.Action)
+						assert.Equal(t, "5", auditLog.TargetID)
+						return nil
+					}),
+				)
+			},
+		},
+		{
+			name:    "error expire",
+			wantErr: true,
+			mockFn: func() {
+				mockPromoRepo.EXPECT().GetExpiredPromoGrants(gomock.Any(), now, promoExpiryBatchSize).Return([]models.PromoGrant{left}, nil)
+				mockPromoRepo.EXPECT().ExpirePromoGrant(gomock.Any(), left, gomock.Any(), now).Return(models.Wallet{}, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+			s := &PromoService{
+				PromoRepo:     mockPromoRepo,
+				WalletService: &WalletService{AuditService: auditSvc},
+				AuditService:  auditSvc,
+			}
+			got, err := s.ExpirePromoGrants(context.Background(), now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWalletService_ExternalTransactionWithPromo(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockPromoRepo := NewMockIPromoRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	req := models.ExternalTransactionRequest{
+		Amount:          50000,
+		Reference:       "reference",
+		TransactionType: constants.TransactionTypeDebit,
+		WalletID:        1,
+		ClientID:        "fastcampus_ecommerce",
+	}
+	campaign := models.Campaign{ID: 2, Percentage: 2, MaxCashback: 5000, ValidityDays: 30}
+
+	tests := []struct {
+		name   string
+		want   models.BalanceResponse
+		mockFn func()
+	}{
+		{
+			name: "success promo spent and cashback granted",
+			want: models.BalanceResponse{Balance: 151000, PromoBalance: 1000, PromoSpent: 10000, Cashback: 1000},
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletTransactionByReference(gomock.Any(), "reference").Return(models.WalletTransaction{}, gorm.ErrRecordNotFound)
+				mockRepo.EXPECT().DebitWithFee(gomock.Any(), gomock.Any(), nil).
+					DoAndReturn(func(ctx context.Context, debit *models.WalletTransaction, fee *models.FeePosting) (models.Wallet, float64, error) {
+						debit.ID = 11
+						return models.Wallet{ID: 1, Balance: 200000, PromoBalance: 10000}, 10000, nil
+					})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+				mockPromoRepo.EXPECT().GetActiveCampaigns(gomock.Any(), "fastcampus_ecommerce", gomock.Any()).Return([]models.Campaign{campaign}, nil)
+				mockPromoRepo.EXPECT().GrantPromo(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.Wallet{ID: 1, Balance: 150000}, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			},
+		},
+		{
+			name: "success cashback failure does not fail the debit",
+			want: models.BalanceResponse{Balance: 150000},
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletTransactionByReference(gomock.Any(), "reference").Return(models.WalletTransaction{}, gorm.ErrRecordNotFound)
+				mockRepo.EXPECT().DebitWithFee(gomock.Any(), gomock.Any(), nil).Return(models.Wallet{ID: 1, Balance: 200000}, 0.0, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+				mockPromoRepo.EXPECT().GetActiveCampaigns(gomock.Any(), "fastcampus_ecommerce", gomock.Any()).Return(nil, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+			s := &WalletService{
+				WalletRepo:   mockRepo,
+				AuditService: auditSvc,
+			}
+			s.PromoService = &PromoService{PromoRepo: mockPromoRepo, WalletService: s, AuditService: auditSvc}
+
+			got, err := s.ExternalTransaction(context.Background(), req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWalletService_UpdateSpendOrder(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	req := models.SpendOrderRequest{SpendOrder: constants.SpendOrderCashFirst}
+
+	tests := []struct {
+		name    string
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1, SpendOrder: constants.SpendOrderPromoFirst}, nil)
+				mockRepo.EXPECT().UpdateSpendOrder(gomock.Any(), uint64(7), constants.SpendOrderCashFirst).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "wallet.spend_order", auditLog.Action)
+					assert.JSONEq(t, `{"spend_order":"cash_first"}`, auditLog.After)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error wallet not found",
+			wantErr: true,
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{}, gorm.ErrRecordNotFound)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &WalletService{
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+			}
+			err := s.UpdateSpendOrder(context.Background(), 7, req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type ReconciliationService struct {
+	ReconciliationRepo i_repository.IReconciliationRepo
+	AuditService       *AuditService
+	// RecheckDelay gives in-flight writes time to land before a mismatch is
+	// reported, the balance update and its ledger row are separate writes.
+	RecheckDelay time.Duration
+}
+
+func (s *ReconciliationService) Run(ctx context.Context, opts models.ReconciliationOptions) (models.ReconciliationReport, error) {
+	var (
+		resp models.ReconciliationReport
+	)
+
+	for _, format := range opts.Formats {
+		if format != constants.ReportFormatJSON && format != constants.ReportFormatCSV {
+			return resp, fmt.Errorf("unknown report format %s", format)
+		}
+	}
+
+	run := models.ReconciliationRun{
+		Status:    constants.ReconciliationStatusRunning,
+		StartedAt: time.Now(),
+	}
+	err := s.ReconciliationRepo.CreateRun(ctx, &run)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to create reconciliation run")
+	}
+
+	mismatches, err := s.reconcile(ctx, &run, opts)
+	if err != nil {
+		run.Status = constants.ReconciliationStatusFailed
+		if updateErr := s.ReconciliationRepo.UpdateRun(ctx, &run); updateErr != nil {
+			log.Println("failed to mark reconciliation run as failed: ", updateErr)
+		}
+		return resp, err
+	}
+
+	finishedAt := time.Now()
+	run.Status = constants.ReconciliationStatusCompleted
+	run.MismatchCount = len(mismatches)
+	run.FinishedAt = &finishedAt
+	err = s.ReconciliationRepo.UpdateRun(ctx, &run)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to complete reconciliation run")
+	}
+
+	resp.Run = run
+	resp.Mismatches = mismatches
+
+	for _, format := range opts.Formats {
+		path, err := writeReconciliationReport(resp, opts.OutputDir, format)
+		if err != nil {
+			return resp, err
+		}
+		resp.Files = append(resp.Files, path)
+	}
+
+	return resp, nil
+}
+
+func (s *ReconciliationService) reconcile(ctx context.Context, run *models.ReconciliationRun, opts models.ReconciliationOptions) ([]models.ReconciliationMismatch, error) {
+	snapshot, err := s.ReconciliationRepo.GetLedgerSnapshot(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ledger snapshot")
+	}
+	run.WalletCount = snapshot.WalletCount
+	run.LastTransactionID = snapshot.LastTransactionID
+
+	if len(snapshot.Mismatches) > 0 && s.RecheckDelay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.RecheckDelay):
+		}
+
+		walletIDs := make([]int, 0, len(snapshot.Mismatches))
+		for _, mismatch := range snapshot.Mismatches {
+			walletIDs = append(walletIDs, mismatch.WalletID)
+		}
+
+		snapshot, err = s.ReconciliationRepo.GetLedgerSnapshot(ctx, walletIDs)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to recheck ledger snapshot")
+		}
+	}
+
+	resp := make([]models.ReconciliationMismatch, 0, len(snapshot.Mismatches))
+	for _, balance := range snapshot.Mismatches {
+		cleanID, err := s.ReconciliationRepo.GetLastCleanTransactionID(ctx, balance.WalletID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get last clean reconciliation")
+		}
+
+		window, err := s.ReconciliationRepo.GetTransactionWindow(ctx, balance.WalletID, cleanID, snapshot.LastTransactionID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get transaction window")
+		}
+
+		mismatch := models.ReconciliationMismatch{
+			RunID:                  run.ID,
+			WalletID:               balance.WalletID,
+			Balance:                balance.Balance,
+			LedgerBalance:          balance.LedgerBalance,
+			Difference:             math.Round((balance.Balance-balance.LedgerBalance)*100) / 100,
+			PromoBalance:           balance.PromoBalance,
+			PromoLedgerBalance:     balance.PromoLedgerBalance,
+			PromoDifference:        math.Round((balance.PromoBalance-balance.PromoLedgerBalance)*100) / 100,
+			WindowFromID:           window.FromID,
+			WindowToID:             window.ToID,
+			WindowFromTime:         window.FromTime,
+			WindowToTime:           window.ToTime,
+			WindowTransactionCount: window.TransactionCount,
+		}
+
+		if opts.CreateSuspense {
+			mismatch.SuspenseEntryID, err = s.raiseSuspense(ctx, run.ID, mismatch)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp = append(resp, mismatch)
+	}
+
+	err = s.ReconciliationRepo.CreateMismatches(ctx, resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to save reconciliation mismatches")
+	}
+
+	return resp, nil
+}
+
+// raiseSuspense parks the difference of the total balance, or the one of the
+// promo balance when only the split between cash and promo drifted.
+func (s *ReconciliationService) raiseSuspense(ctx context.Context, runID int, mismatch models.ReconciliationMismatch) (int, error) {
+	entry := &models.SuspenseEntry{
+		WalletID: mismatch.WalletID,
+		RunID:    runID,
+		Amount:   mismatch.Difference,
+		Status:   constants.SuspenseStatusOpen,
+		Note:     fmt.Sprintf("balance %.2f does not match ledger %.2f", mismatch.Balance, mismatch.LedgerBalance),
+	}
+	if math.Abs(mismatch.Difference) < constants.ReconciliationTolerance {
+		entry.Amount = mismatch.PromoDifference
+		entry.Note = fmt.Sprintf("promo balance %.2f does not match promo ledger %.2f", mismatch.PromoBalance, mismatch.PromoLedgerBalance)
+	}
+
+	created, err := s.ReconciliationRepo.CreateSuspenseEntry(ctx, entry)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create suspense entry")
+	}
+
+	if created {
+		err = s.AuditService.Record(ctx, "suspense.open", constants.AuditTargetWallet, strconv.Itoa(mismatch.WalletID),
+			map[string]interface{}{"balance": mismatch.Balance, "promo_balance": mismatch.PromoBalance},
+			map[string]interface{}{"ledger_balance": mismatch.LedgerBalance, "promo_ledger_balance": mismatch.PromoLedgerBalance},
+			map[string]interface{}{"suspense_entry_id": entry.ID, "run_id": runID, "amount": entry.Amount})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return entry.ID, nil
+}
+
+// Schedule runs the reconciliation every day at the given local HH:MM until
+// ctx is cancelled. A failed run is logged and retried on the next day.
+func (s *ReconciliationService) Schedule(ctx context.Context, at string, opts models.ReconciliationOptions) error {
+	runAt, err := time.Parse("15:04", at)
+	if err != nil {
+		return errors.Wrap(err, "invalid reconciliation schedule")
+	}
+
+	for {
+		wait := time.Until(nextDailyRun(time.Now(), runAt.Hour(), runAt.Minute()))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+
+		report, err := s.Run(ctx, opts)
+		if err != nil {
+			log.Println("reconciliation run failed: ", err)
+			continue
+		}
+		log.Printf("reconciliation run %d finished, %d wallets checked, %d mismatches\n",
+			report.Run.ID, report.Run.WalletCount, report.Run.MismatchCount)
+	}
+}
+
+func nextDailyRun(now time.Time, hour int, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
+
+func writeReconciliationReport(report models.ReconciliationReport, dir string, format string) (string, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create report directory")
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("reconciliation-%d-%s.%s", report.Run.ID, report.Run.StartedAt.Format("20060102"), format))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create report file")
+	}
+	defer file.Close()
+
+	switch format {
+	case constants.ReportFormatJSON:
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	case constants.ReportFormatCSV:
+		err = writeReconciliationCSV(file, report)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to write report")
+	}
+
+	return path, nil
+}
+
+func writeReconciliationCSV(file *os.File, report models.ReconciliationReport) error {
+	w := csv.NewWriter(file)
+	err := w.Write([]string{
+		"run_id", "wallet_id", "balance", "ledger_balance", "difference",
+		"window_from_id", "window_to_id", "window_from_time", "window_to_time",
+		"window_transaction_count", "suspense_entry_id",
+		"promo_balance", "promo_ledger_balance", "promo_difference",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, mismatch := range report.Mismatches {
+		err = w.Write([]string{
+			strconv.Itoa(mismatch.RunID),
+			strconv.Itoa(mismatch.WalletID),
+			strconv.FormatFloat(mismatch.Balance, 'f', 2, 64),
+			strconv.FormatFloat(mismatch.LedgerBalance, 'f', 2, 64),
+			strconv.FormatFloat(mismatch.Difference, 'f', 2, 64),
+			strconv.Itoa(mismatch.WindowFromID),
+			strconv.Itoa(mismatch.WindowToID),
+			formatReportTime(mismatch.WindowFromTime),
+			formatReportTime(mismatch.WindowToTime),
+			strconv.FormatInt(mismatch.WindowTransactionCount, 10),
+			strconv.Itoa(mismatch.SuspenseEntryID),
+			strconv.FormatFloat(mismatch.PromoBalance, 'f', 2, 64),
+			strconv.FormatFloat(mismatch.PromoLedgerBalance, 'f', 2, 64),
+			strconv.FormatFloat(mismatch.PromoDifference, 'f', 2, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func formatReportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_reconciliation_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIReconciliationRepo is a mock of IReconciliationRepo interface.
+type MockIReconciliationRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIReconciliationRepoMockRecorder
+}
+
+// MockIReconciliationRepoMockRecorder is the mock recorder for MockIReconciliationRepo.
+type MockIReconciliationRepoMockRecorder struct {
+	mock *MockIReconciliationRepo
+}
+
+// NewMockIReconciliationRepo creates a new mock instance.
+func NewMockIReconciliationRepo(ctrl *gomock.Controller) *MockIReconciliationRepo {
+	mock := &MockIReconciliationRepo{ctrl: ctrl}
+	mock.recorder = &MockIReconciliationRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIReconciliationRepo) EXPECT() *MockIReconciliationRepoMockRecorder {
+	return m.recorder
+}
+
+// CreateMismatches mocks base method.
+func (m *MockIReconciliationRepo) CreateMismatches(ctx context.Context, mismatches []models.ReconciliationMismatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMismatches", ctx, mismatches)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMismatches indicates an expected call of CreateMismatches.
+func (mr *MockIReconciliationRepoMockRecorder) CreateMismatches(ctx, mismatches interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMismatches", reflect.TypeOf((*MockIReconciliationRepo)(nil).CreateMismatches), ctx, mismatches)
+}
+
+// CreateRun mocks base method.
+func (m *MockIReconciliationRepo) CreateRun(ctx context.Context, run *models.ReconciliationRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRun indicates an expected call of CreateRun.
+func (mr *MockIReconciliationRepoMockRecorder) CreateRun(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRun", reflect.TypeOf((*MockIReconciliationRepo)(nil).CreateRun), ctx, run)
+}
+
+// CreateSuspenseEntry mocks base method.
+func (m *MockIReconciliationRepo) CreateSuspenseEntry(ctx context.Context, entry *models.SuspenseEntry) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSuspenseEntry", ctx, entry)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSuspenseEntry indicates an expected call of CreateSuspenseEntry.
+func (mr *MockIReconciliationRepoMockRecorder) CreateSuspenseEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSuspenseEntry", reflect.TypeOf((*MockIReconciliationRepo)(nil).CreateSuspenseEntry), ctx, entry)
+}
+
+// GetLastCleanTransactionID mocks base method.
+func (m *MockIReconciliationRepo) GetLastCleanTransactionID(ctx context.Context, walletID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastCleanTransactionID", ctx, walletID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastCleanTransactionID indicates an expected call of GetLastCleanTransactionID.
+func (mr *MockIReconciliationRepoMockRecorder) GetLastCleanTransactionID(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastCleanTransactionID", reflect.TypeOf((*MockIReconciliationRepo)(nil).GetLastCleanTransactionID), ctx, walletID)
+}
+
+// GetLedgerSnapshot mocks base method.
+func (m *MockIReconciliationRepo) GetLedgerSnapshot(ctx context.Context, walletIDs []int) (models.LedgerSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerSnapshot", ctx, walletIDs)
+	ret0, _ := ret[0].(models.LedgerSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerSnapshot indicates an expected call of GetLedgerSnapshot.
+func (mr *MockIReconciliationRepoMockRecorder) GetLedgerSnapshot(ctx, walletIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerSnapshot", reflect.TypeOf((*MockIReconciliationRepo)(nil).GetLedgerSnapshot), ctx, walletIDs)
+}
+
+// GetTransactionWindow mocks base method.
+func (m *MockIReconciliationRepo) GetTransactionWindow(ctx context.Context, walletID, afterID, toID int) (models.TransactionWindow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionWindow", ctx, walletID, afterID, toID)
+	ret0, _ := ret[0].(models.TransactionWindow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionWindow indicates an expected call of GetTransactionWindow.
+func (mr *MockIReconciliationRepoMockRecorder) GetTransactionWindow(ctx, walletID, afterID, toID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionWindow", reflect.TypeOf((*MockIReconciliationRepo)(nil).GetTransactionWindow), ctx, walletID, afterID, toID)
+}
+
+// UpdateRun mocks base method.
+func (m *MockIReconciliationRepo) UpdateRun(ctx context.Context, run *models.ReconciliationRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRun indicates an expected call of UpdateRun.
+func (mr *MockIReconciliationRepoMockRecorder) UpdateRun(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRun", reflect.TypeOf((*MockIReconciliationRepo)(nil).UpdateRun), ctx, run)
+}
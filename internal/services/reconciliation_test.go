@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconciliationService_Run(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockReconRepo := NewMockIReconciliationRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	now := time.Now()
+
+	// the mismatches GetLedgerSnapshot finds when wallet 2 lost the ledger row
+	// of a 300 credit and wallet 4 had its balance bumped without any ledger
+	// row at all, the engine test of the repository seeds the same drift
+	mismatches := []models.WalletLedgerBalance{
+		{WalletID: 2, Balance: 500, LedgerBalance: 200},
+		{WalletID: 4, Balance: 25, LedgerBalance: 0},
+	}
+
+	tests := []struct {
+		name           string
+		opts           models.ReconciliationOptions
+		wantErr        bool
+		wantMismatches []models.ReconciliationMismatch
+		mockFn         func()
+	}{
+		{
+			name: "success report mismatches",
+			opts: models.ReconciliationOptions{
+				Formats: []string{"json", "csv"},
+			},
+			wantErr: false,
+			wantMismatches: []models.ReconciliationMismatch{
+				{RunID: 9, WalletID: 2, Balance: 500, LedgerBalance: 200, Difference: 300, WindowFromID: 4, WindowToID: 4, WindowFromTime: &now, WindowToTime: &now, WindowTransactionCount: 1},
+				{RunID: 9, WalletID: 4, Balance: 25, LedgerBalance: 0, Difference: 25},
+			},
+			mockFn: func() {
+				mockReconRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, run *models.ReconciliationRun) error {
+					assert.Equal(t, constants.ReconciliationStatusRunning, run.Status)
+					run.ID = 9
+					return nil
+				})
+				mockReconRepo.EXPECT().GetLedgerSnapshot(gomock.Any(), nil).Return(models.LedgerSnapshot{LastTransactionID: 6, WalletCount: 4, Mismatches: mismatches}, nil)
+				mockReconRepo.EXPECT().GetLedgerSnapshot(gomock.Any(), []int{2, 4}).Return(models.LedgerSnapshot{LastTransactionID: 6, WalletCount: 4, Mismatches: mismatches}, nil)
+				// wallet 2 was consistent up to transaction 3 in an earlier run
+				mockReconRepo.EXPECT().GetLastCleanTransactionID(gomock.Any(), 2).Return(3, nil)
+				mockReconRepo.EXPECT().GetTransactionWindow(gomock.Any(), 2, 3, 6).Return(models.TransactionWindow{FromID: 4, ToID: 4, FromTime: &now, ToTime: &now, TransactionCount: 1}, nil)
+				mockReconRepo.EXPECT().GetLastCleanTransactionID(gomock.Any(), 4).Return(0, nil)
+				mockReconRepo.EXPECT().GetTransactionWindow(gomock.Any(), 4, 0, 6).Return(models.TransactionWindow{}, nil)
+				mockReconRepo.EXPECT().CreateMismatches(gomock.Any(), gomock.Len(2)).Return(nil)
+				mockReconRepo.EXPECT().UpdateRun(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, run *models.ReconciliationRun) error {
+					assert.Equal(t, constants.ReconciliationStatusCompleted, run.Status)
+					assert.Equal(t, 2, run.MismatchCount)
+					assert.Equal(t, int64(4), run.WalletCount)
+					assert.Equal(t, 6, run.LastTransactionID)
+					assert.NotNil(t, run.FinishedAt)
+					return nil
+				})
+			},
+		},
+		{
+			name:           "success mismatch settles on recheck",
+			opts:           models.ReconciliationOptions{},
+			wantErr:        false,
+			wantMismatches: []models.ReconciliationMismatch{},
+			mockFn: func() {
+				mockReconRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).Return(nil)
+				// the balance of wallet 1 moved before its ledger row was written
+				inFlight := []models.WalletLedgerBalance{{WalletID: 1, Balance: 250, LedgerBalance: 150}}
+				mockReconRepo.EXPECT().GetLedgerSnapshot(gomock.Any(), nil).Return(models.LedgerSnapshot{LastTransactionID: 6, WalletCount: 1, Mismatches: inFlight}, nil)
+				mockReconRepo.EXPECT().GetLedgerSnapshot(gomock.Any(), []int{1}).Return(models.LedgerSnapshot{LastTransactionID: 7, WalletCount: 1}, nil)
+				mockReconRepo.EXPECT().CreateMismatches(gomock.Any(), gomock.Len(0)).Return(nil)
+				mockReconRepo.EXPECT().UpdateRun(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "success with suspense entry",
+			opts:    models.ReconciliationOptions{CreateSuspense: true},
+			wantErr: false,
+			wantMismatches: []models.ReconciliationMismatch{
+				{WalletID: 4, Balance: 25, LedgerBalance: 0, Difference: 25, SuspenseEntryID: 11},
+			},
+			mockFn: func() {
+				mockReconRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).Return(nil)
+				mockReconRepo.EXPECT().GetLedgerSnapshot(gomock.Any(), gomock.Any()).Return(models.LedgerSnapshot{LastTransactionID: 6, WalletCount: 1, Mismatches: mismatches[1:]}, nil).Times(2)
+				mockReconRepo.EXPECT().GetLastCleanTransactionID(gomock.Any(), 4).Return(0, nil)
+				mockReconRepo.EXPECT().GetTransactionWindow(gomock.Any(), 4, 0, 6).Return(models.TransactionWindow{}, nil)
+				mockReconRepo.EXPECT().CreateSuspenseEntry(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, entry *models.SuspenseEntry) (bool, error) {
+					assert.Equal(t, float64(25), entry.Amount)
+					assert.Equal(t, constants.SuspenseStatusOpen, entry.Status)
+					entry.ID = 11
+					return true, nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "suspense.open", auditLog.Action)
+					assert.Equal(t, constants.AuditActorSystem, auditLog.ActorType)
+					return nil
+				})
+				mockReconRepo.EXPECT().CreateMismatches(gomock.Any(), gomock.Len(1)).Return(nil)
+				mockReconRepo.EXPECT().UpdateRun(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "success with suspense entry for promo drift",
+			opts:    models.ReconciliationOptions{CreateSuspense: true},
+			wantErr: false,
+			wantMismatches: []models.ReconciliationMismatch{
+				{WalletID: 5, Balance: 100, LedgerBalance: 100, PromoBalance: 40, PromoLedgerBalance: 30, PromoDifference: 10, SuspenseEntryID: 12},
+			},
+			mockFn: func() {
+				mockReconRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).Return(nil)
+				// the total matches, 10 of cash was booked as promo credit
+				promoDrift := []models.WalletLedgerBalance{{WalletID: 5, Balance: 100, LedgerBalance: 100, PromoBalance: 40, PromoLedgerBalance: 30}}
+				mockReconRepo.EXPECT().GetLedgerSnapshot(gomock.Any(), gomock.Any()).Return(models.LedgerSnapshot{LastTransactionID: 6, WalletCount: 1, Mismatches: promoDrift}, nil).Times(2)
+				mockReconRepo.EXPECT().GetLastCleanTransactionID(gomock.Any(), 5).Return(0, nil)
+				mockReconRepo.EXPECT().GetTransactionWindow(gomock.Any(), 5, 0, 6).Return(models.TransactionWindow{}, nil)
+				mockReconRepo.EXPECT().CreateSuspenseEntry(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, entry *models.SuspenseEntry) (bool, error) {
+					assert.Equal(t, float64(10), entry.Amount)
+					assert.Equal(t, "promo balance 40.00 does not match promo ledger 30.00", entry.Note)
+					entry.ID = 12
+					return true, nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+				mockReconRepo.EXPECT().CreateMismatches(gomock.Any(), gomock.Len(1)).Return(nil)
+				mockReconRepo.EXPECT().UpdateRun(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "error snapshot marks run failed",
+			opts:    models.ReconciliationOptions{},
+			wantErr: true,
+			mockFn: func() {
+				mockReconRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).Return(nil)
+				mockReconRepo.EXPECT().GetLedgerSnapshot(gomock.Any(), nil).Return(models.LedgerSnapshot{}, assert.AnError)
+				mockReconRepo.EXPECT().UpdateRun(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, run *models.ReconciliationRun) error {
+					assert.Equal(t, constants.ReconciliationStatusFailed, run.Status)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error unknown format",
+			opts:    models.ReconciliationOptions{Formats: []string{"xml"}},
+			wantErr: true,
+			mockFn:  func() {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			tt.opts.OutputDir = t.TempDir()
+
+			s := &ReconciliationService{
+				ReconciliationRepo: mockReconRepo,
+				AuditService:       &AuditService{AuditRepo: mockAuditRepo},
+				RecheckDelay:       time.Millisecond,
+			}
+			got, err := s.Run(context.Background(), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReconciliationService.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			assert.Equal(t, tt.wantMismatches, got.Mismatches)
+			assert.Len(t, got.Files, len(tt.opts.Formats))
+			for _, file := range got.Files {
+				assert.FileExists(t, file)
+			}
+		})
+	}
+}
+
+func TestWriteReconciliationReport(t *testing.T) {
+	dir := t.TempDir()
+	report := models.ReconciliationReport{
+		Run: models.ReconciliationRun{ID: 3, Status: "completed", StartedAt: time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)},
+		Mismatches: []models.ReconciliationMismatch{
+			{RunID: 3, WalletID: 2, Balance: 500, LedgerBalance: 200, Difference: 300, PromoBalance: 50, PromoLedgerBalance: 20, PromoDifference: 30, WindowFromID: 4, WindowToID: 9, WindowTransactionCount: 2},
+		},
+	}
+
+	path, err := writeReconciliationReport(report, dir, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, dir+"/reconciliation-3-20240501.csv", path)
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, []string{"3", "2", "500.00", "200.00", "300.00", "4", "9", "", "", "2", "0", "50.00", "20.00", "30.00"}, rows[1])
+
+	path, err = writeReconciliationReport(report, dir, "json")
+	assert.NoError(t, err)
+	payload, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var decoded models.ReconciliationReport
+	assert.NoError(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, report.Mismatches, decoded.Mismatches)
+}
+
+func TestNextDailyRun(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 5, 2, 2, 0, 0, 0, time.UTC), nextDailyRun(now, 2, 0))
+	assert.Equal(t, time.Date(2024, 5, 1, 23, 15, 0, 0, time.UTC), nextDailyRun(now, 23, 15))
+	assert.Equal(t, time.Date(2024, 5, 2, 10, 30, 0, 0, time.UTC), nextDailyRun(now, 10, 30))
+}
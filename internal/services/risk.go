@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var riskSeverity = map[string]int{
+	constants.RiskOutcomeAllow:  0,
+	constants.RiskOutcomeReview: 1,
+	constants.RiskOutcomeBlock:  2,
+}
+
+// RiskService screens debits, transfers and external transactions against
+// Rules before the money moves, and holds the ones to review until an
+// operator decides. A nil RiskService lets everything through.
+type RiskService struct {
+	RiskRepo              i_repository.IRiskRepo
+	WalletRepo            i_repository.IWalletRepo
+	WalletService         *WalletService
+	PaymentRequestService *PaymentRequestService
+	WithdrawalService     *WithdrawalService
+	// ScheduledPaymentService moves on the schedules waiting for a decided
+	// review.
+	ScheduledPaymentService *ScheduledPaymentService
+	AuditService            *AuditService
+	Rules                   []models.RiskRule
+}
+
+// LoadRiskRules reads the rules file, every rule has to be valid.
+func LoadRiskRules(path string) ([]models.RiskRule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open risk rules")
+	}
+	defer file.Close()
+
+	var set models.RiskRuleSet
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&set)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse risk rules")
+	}
+
+	for i := range set.Rules {
+		err = validateRiskRule(&set.Rules[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return set.Rules, nil
+}
+
+func validateRiskRule(rule *models.RiskRule) error {
+	err := rule.Validate()
+	if err != nil {
+		return fmt.Errorf("%w %s: %v", constants.ErrInvalidRiskRule, rule.Name, err)
+	}
+
+	rule.WindowDuration, err = time.ParseDuration(rule.Window)
+	if err != nil || rule.WindowDuration <= 0 {
+		return fmt.Errorf("%w %s: window %q", constants.ErrInvalidRiskRule, rule.Name, rule.Window)
+	}
+
+	switch rule.Type {
+	case constants.RiskRuleDebitVelocity, constants.RiskRuleClientReferences:
+		if rule.MaxCount <= 0 {
+			return fmt.Errorf("%w %s: max_count is required", constants.ErrInvalidRiskRule, rule.Name)
+		}
+	case constants.RiskRuleNewLinkLargeDebit:
+		if rule.MinAmount <= 0 {
+			return fmt.Errorf("%w %s: min_amount is required", constants.ErrInvalidRiskRule, rule.Name)
+		}
+	case constants.RiskRuleUnusualAmount:
+		if rule.Multiplier <= 1 {
+			return fmt.Errorf("%w %s: multiplier has to be above 1", constants.ErrInvalidRiskRule, rule.Name)
+		}
+	}
+
+	return nil
+}
+
+// Screen decides on the movement and records the decision with its reasons.
+// A blocked movement fails with ErrTransactionBlocked, one to review is held
+// and fails with ErrTransactionUnderReview.
+func (s *RiskService) Screen(ctx context.Context, check models.RiskCheck) error {
+	if s == nil {
+		return nil
+	}
+
+	if check.WalletID == 0 {
+		wallet, err := s.WalletRepo.GetWalletByUserID(ctx, check.UserID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get wallet")
+		}
+		check.WalletID = wallet.ID
+	}
+
+	outcome, reasons, err := s.Evaluate(ctx, check, time.Now())
+	if err != nil {
+		return err
+	}
+
+	decision := &models.RiskDecision{
+		Operation:       check.Operation,
+		TransactionType: check.TransactionType,
+		WalletID:        check.WalletID,
+		UserID:          check.UserID,
+		ClientID:        check.ClientID,
+		Amount:          check.Amount,
+		Reference:       check.Reference,
+		Outcome:         outcome,
+		Reasons:         strings.Join(reasons, "; "),
+	}
+
+	var review *models.RiskReview
+	if outcome == constants.RiskOutcomeReview {
+		review = &models.RiskReview{
+			Operation:        check.Operation,
+			TransactionType:  check.TransactionType,
+			WalletID:         check.WalletID,
+			UserID:           check.UserID,
+			ClientID:         check.ClientID,
+			Amount:           check.Amount,
+			Reference:        check.Reference,
+			PaymentRequestID: check.PaymentRequestID,
+			BankAccountID:    check.BankAccountID,
+			Reasons:          decision.Reasons,
+			Status:           constants.RiskReviewStatusPending,
+		}
+	}
+
+	err = s.RiskRepo.RecordRiskDecision(ctx, decision, review)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return constants.ErrDuplicateReference
+		}
+		return errors.Wrap(err, "failed to record risk decision")
+	}
+
+	switch outcome {
+	case constants.RiskOutcomeBlock:
+		return constants.ErrTransactionBlocked
+	case constants.RiskOutcomeReview:
+		err = s.AuditService.Record(ctx, "risk_review.create", constants.AuditTargetRiskReview, strconv.Itoa(review.ID), nil, review, nil)
+		if err != nil {
+			return err
+		}
+		return constants.ErrTransactionUnderReview
+	}
+
+	return nil
+}
+
+// Evaluate runs every rule that applies to the movement, the outcome is the
+// worst of the rules that fired.
+func (s *RiskService) Evaluate(ctx context.Context, check models.RiskCheck, now time.Time) (string, []string, error) {
+	var (
+		outcome = constants.RiskOutcomeAllow
+		reasons []string
+	)
+
+	for _, rule := range s.Rules {
+		if rule.Disabled || !rule.AppliesTo(check.Operation) {
+			continue
+		}
+
+		reason, err := s.evaluateRule(ctx, rule, check, now)
+		if err != nil {
+			return outcome, reasons, errors.Wrapf(err, "failed to evaluate risk rule %s", rule.Name)
+		}
+
+		if reason == "" {
+			continue
+		}
+
+		reasons = append(reasons, rule.Name+": "+reason)
+		if riskSeverity[rule.Outcome] > riskSeverity[outcome] {
+			outcome = rule.Outcome
+		}
+	}
+
+	return outcome, reasons, nil
+}
+
+// evaluateRule returns why the rule fired, or an empty reason. The checks on
+// debits only look at debits, the movement itself is not in the ledger yet.
+func (s *RiskService) evaluateRule(ctx context.Context, rule models.RiskRule, check models.RiskCheck, now time.Time) (string, error) {
+	since := now.Add(-rule.WindowDuration)
+	debit := check.TransactionType == constants.TransactionTypeDebit
+
+	switch rule.Type {
+	case constants.RiskRuleDebitVelocity:
+		if !debit {
+			return "", nil
+		}
+
+		count, err := s.RiskRepo.CountDebitsSince(ctx, check.WalletID, since)
+		if err != nil {
+			return "", err
+		}
+
+		if count+1 > rule.MaxCount {
+			return fmt.Sprintf("%d debits within %s", count+1, rule.Window), nil
+		}
+	case constants.RiskRuleNewLinkLargeDebit:
+		if !debit || check.Amount < rule.MinAmount {
+			return "", nil
+		}
+
+		count, err := s.RiskRepo.CountLinksSince(ctx, check.WalletID, since)
+		if err != nil {
+			return "", err
+		}
+
+		if count > 0 {
+			return fmt.Sprintf("debit of %.2f within %s of linking a client", check.Amount, rule.Window), nil
+		}
+	case constants.RiskRuleClientReferences:
+		if check.ClientID == "" {
+			return "", nil
+		}
+
+		count, err := s.RiskRepo.CountClientReferencesSince(ctx, check.ClientID, since)
+		if err != nil {
+			return "", err
+		}
+
+		if count+1 > rule.MaxCount {
+			return fmt.Sprintf("%d references from %s within %s", count+1, check.ClientID, rule.Window), nil
+		}
+	case constants.RiskRuleUnusualAmount:
+		if !debit {
+			return "", nil
+		}
+
+		stats, err := s.RiskRepo.GetDebitStats(ctx, check.WalletID, since)
+		if err != nil {
+			return "", err
+		}
+
+		if stats.Count < rule.MinHistory || stats.Average <= 0 {
+			return "", nil
+		}
+
+		if check.Amount > stats.Average*rule.Multiplier {
+			return fmt.Sprintf("amount %.2f is over %gx the average debit of %.2f", check.Amount, rule.Multiplier, stats.Average), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (s *RiskService) GetRiskReviews(ctx context.Context, param models.RiskReviewParam) ([]models.RiskReview, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.RiskRepo.GetRiskReviews(ctx, param, offset, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get risk reviews")
+	}
+
+	return resp, nil
+}
+
+// ApproveRiskReview lets the held movement through. It is replayed without
+// screening it again, a replay that fails marks the review failed.
+func (s *RiskService) ApproveRiskReview(ctx context.Context, decidedBy string, reviewID int, req models.RiskReviewDecisionRequest) (models.RiskReview, error) {
+	review, err := s.decideRiskReview(ctx, "risk_review.approve", decidedBy, reviewID, constants.RiskReviewStatusApproved, req.Note)
+	if err != nil {
+		return review, err
+	}
+
+	err = s.release(ctx, review)
+	if err != nil {
+		failErr := s.RiskRepo.UpdateRiskReviewStatus(ctx, reviewID, constants.RiskReviewStatusApproved, constants.RiskReviewStatusFailed, err.Error())
+		if failErr != nil {
+			return review, errors.Wrap(failErr, "failed to mark risk review as failed")
+		}
+		review.Status = constants.RiskReviewStatusFailed
+
+		auditErr := s.AuditService.Record(ctx, "risk_review.fail", constants.AuditTargetRiskReview, strconv.Itoa(reviewID),
+			map[string]interface{}{"status": constants.RiskReviewStatusApproved}, map[string]interface{}{"status": review.Status},
+			map[string]interface{}{"error": err.Error()})
+		if auditErr != nil {
+			return review, auditErr
+		}
+
+		settleErr := s.ScheduledPaymentService.settleReview(ctx, review, false)
+		if settleErr != nil {
+			return review, settleErr
+		}
+
+		return review, errors.Wrap(err, "failed to release held transaction")
+	}
+
+	err = s.ScheduledPaymentService.settleReview(ctx, review, true)
+	if err != nil {
+		return review, err
+	}
+
+	return review, nil
+}
+
+func (s *RiskService) RejectRiskReview(ctx context.Context, decidedBy string, reviewID int, req models.RiskReviewDecisionRequest) (models.RiskReview, error) {
+	review, err := s.decideRiskReview(ctx, "risk_review.reject", decidedBy, reviewID, constants.RiskReviewStatusRejected, req.Note)
+	if err != nil {
+		return review, err
+	}
+
+	err = s.ScheduledPaymentService.settleReview(ctx, review, false)
+	if err != nil {
+		return review, err
+	}
+
+	return review, nil
+}
+
+func (s *RiskService) decideRiskReview(ctx context.Context, action string, decidedBy string, reviewID int, status string, note string) (models.RiskReview, error) {
+	review, err := s.RiskRepo.GetRiskReviewByID(ctx, reviewID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return review, constants.ErrRiskReviewNotFound
+		}
+		return review, errors.Wrap(err, "failed to get risk review")
+	}
+
+	if review.Status != constants.RiskReviewStatusPending {
+		return review, constants.ErrRiskReviewNotPending
+	}
+
+	now := time.Now()
+	err = s.RiskRepo.DecideRiskReview(ctx, reviewID, status, decidedBy, note, now)
+	if err != nil {
+		return review, errors.Wrap(err, "failed to decide risk review")
+	}
+
+	review.Status = status
+	review.DecidedBy = decidedBy
+	review.DecisionNote = note
+	review.DecidedAt = &now
+
+	err = s.AuditService.Record(ctx, action, constants.AuditTargetRiskReview, strconv.Itoa(reviewID),
+		map[string]interface{}{"status": constants.RiskReviewStatusPending}, map[string]interface{}{"status": status}, map[string]interface{}{"note": note})
+	if err != nil {
+		return review, err
+	}
+
+	return review, nil
+}
+
+// release moves the money of an approved review.
+func (s *RiskService) release(ctx context.Context, review models.RiskReview) error {
+	switch review.Operation {
+	case constants.RiskOperationDebit:
+		_, err := s.WalletService.debitBalance(ctx, review.UserID, models.TransactionRequest{
+			Reference: review.Reference,
+			Amount:    review.Amount,
+		})
+		return err
+	case constants.RiskOperationExternal:
+		_, err := s.WalletService.externalTransaction(ctx, models.ExternalTransactionRequest{
+			Amount:          review.Amount,
+			Reference:       review.Reference,
+			TransactionType: review.TransactionType,
+			WalletID:        review.WalletID,
+			ClientID:        review.ClientID,
+		})
+		return err
+	case constants.RiskOperationTransfer:
+		request, err := s.PaymentRequestService.getPaymentRequest(ctx, review.PaymentRequestID)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.PaymentRequestService.payPaymentRequest(ctx, request)
+		return err
+	case constants.RiskOperationWithdrawal:
+		_, err := s.WithdrawalService.releaseWithdrawal(ctx, review)
+		return err
+	default:
+		return fmt.Errorf("unknown risk operation %s", review.Operation)
+	}
+}
+
+func (s *AdminService) GetRiskReviews(ctx context.Context, actor models.OperatorTokenData, param models.RiskReviewParam) ([]models.RiskReview, error) {
+	return s.RiskService.GetRiskReviews(ctx, param)
+}
+
+func (s *AdminService) ApproveRiskReview(ctx context.Context, actor models.OperatorTokenData, reviewID int, req models.RiskReviewDecisionRequest) (models.RiskReview, error) {
+	return s.RiskService.ApproveRiskReview(operatorContext(ctx, actor), actor.Username, reviewID, req)
+}
+
+func (s *AdminService) RejectRiskReview(ctx context.Context, actor models.OperatorTokenData, reviewID int, req models.RiskReviewDecisionRequest) (models.RiskReview, error) {
+	return s.RiskService.RejectRiskReview(operatorContext(ctx, actor), actor.Username, reviewID, req)
+}
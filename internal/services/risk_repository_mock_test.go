@@ -0,0 +1,169 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_risk_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIRiskRepo is a mock of IRiskRepo interface.
+type MockIRiskRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIRiskRepoMockRecorder
+}
+
+// MockIRiskRepoMockRecorder is the mock recorder for MockIRiskRepo.
+type MockIRiskRepoMockRecorder struct {
+	mock *MockIRiskRepo
+}
+
+// NewMockIRiskRepo creates a new mock instance.
+func NewMockIRiskRepo(ctrl *gomock.Controller) *MockIRiskRepo {
+	mock := &MockIRiskRepo{ctrl: ctrl}
+	mock.recorder = &MockIRiskRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIRiskRepo) EXPECT() *MockIRiskRepoMockRecorder {
+	return m.recorder
+}
+
+// CountClientReferencesSince mocks base method.
+func (m *MockIRiskRepo) CountClientReferencesSince(ctx context.Context, clientID string, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountClientReferencesSince", ctx, clientID, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountClientReferencesSince indicates an expected call of CountClientReferencesSince.
+func (mr *MockIRiskRepoMockRecorder) CountClientReferencesSince(ctx, clientID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountClientReferencesSince", reflect.TypeOf((*MockIRiskRepo)(nil).CountClientReferencesSince), ctx, clientID, since)
+}
+
+// CountDebitsSince mocks base method.
+func (m *MockIRiskRepo) CountDebitsSince(ctx context.Context, walletID int, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDebitsSince", ctx, walletID, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDebitsSince indicates an expected call of CountDebitsSince.
+func (mr *MockIRiskRepoMockRecorder) CountDebitsSince(ctx, walletID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDebitsSince", reflect.TypeOf((*MockIRiskRepo)(nil).CountDebitsSince), ctx, walletID, since)
+}
+
+// CountLinksSince mocks base method.
+func (m *MockIRiskRepo) CountLinksSince(ctx context.Context, walletID int, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountLinksSince", ctx, walletID, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountLinksSince indicates an expected call of CountLinksSince.
+func (mr *MockIRiskRepoMockRecorder) CountLinksSince(ctx, walletID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountLinksSince", reflect.TypeOf((*MockIRiskRepo)(nil).CountLinksSince), ctx, walletID, since)
+}
+
+// DecideRiskReview mocks base method.
+func (m *MockIRiskRepo) DecideRiskReview(ctx context.Context, reviewID int, status, decidedBy, note string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideRiskReview", ctx, reviewID, status, decidedBy, note, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecideRiskReview indicates an expected call of DecideRiskReview.
+func (mr *MockIRiskRepoMockRecorder) DecideRiskReview(ctx, reviewID, status, decidedBy, note, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideRiskReview", reflect.TypeOf((*MockIRiskRepo)(nil).DecideRiskReview), ctx, reviewID, status, decidedBy, note, now)
+}
+
+// GetDebitStats mocks base method.
+func (m *MockIRiskRepo) GetDebitStats(ctx context.Context, walletID int, since time.Time) (models.RiskDebitStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDebitStats", ctx, walletID, since)
+	ret0, _ := ret[0].(models.RiskDebitStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDebitStats indicates an expected call of GetDebitStats.
+func (mr *MockIRiskRepoMockRecorder) GetDebitStats(ctx, walletID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDebitStats", reflect.TypeOf((*MockIRiskRepo)(nil).GetDebitStats), ctx, walletID, since)
+}
+
+// GetRiskReviewByID mocks base method.
+func (m *MockIRiskRepo) GetRiskReviewByID(ctx context.Context, reviewID int) (models.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskReviewByID", ctx, reviewID)
+	ret0, _ := ret[0].(models.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskReviewByID indicates an expected call of GetRiskReviewByID.
+func (mr *MockIRiskRepoMockRecorder) GetRiskReviewByID(ctx, reviewID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskReviewByID", reflect.TypeOf((*MockIRiskRepo)(nil).GetRiskReviewByID), ctx, reviewID)
+}
+
+// GetRiskReviews mocks base method.
+func (m *MockIRiskRepo) GetRiskReviews(ctx context.Context, param models.RiskReviewParam, offset, limit int) ([]models.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskReviews", ctx, param, offset, limit)
+	ret0, _ := ret[0].([]models.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskReviews indicates an expected call of GetRiskReviews.
+func (mr *MockIRiskRepoMockRecorder) GetRiskReviews(ctx, param, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskReviews", reflect.TypeOf((*MockIRiskRepo)(nil).GetRiskReviews), ctx, param, offset, limit)
+}
+
+// RecordRiskDecision mocks base method.
+func (m *MockIRiskRepo) RecordRiskDecision(ctx context.Context, decision *models.RiskDecision, review *models.RiskReview) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordRiskDecision", ctx, decision, review)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordRiskDecision indicates an expected call of RecordRiskDecision.
+func (mr *MockIRiskRepoMockRecorder) RecordRiskDecision(ctx, decision, review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordRiskDecision", reflect.TypeOf((*MockIRiskRepo)(nil).RecordRiskDecision), ctx, decision, review)
+}
+
+// UpdateRiskReviewStatus mocks base method.
+func (m *MockIRiskRepo) UpdateRiskReviewStatus(ctx context.Context, reviewID int, fromStatus, toStatus, note string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRiskReviewStatus", ctx, reviewID, fromStatus, toStatus, note)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRiskReviewStatus indicates an expected call of UpdateRiskReviewStatus.
+func (mr *MockIRiskRepoMockRecorder) UpdateRiskReviewStatus(ctx, reviewID, fromStatus, toStatus, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRiskReviewStatus", reflect.TypeOf((*MockIRiskRepo)(nil).UpdateRiskReviewStatus), ctx, reviewID, fromStatus, toStatus, note)
+}
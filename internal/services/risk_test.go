@@ -0,0 +1,522 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestLoadRiskRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []models.RiskRule
+		wantErr error
+	}{
+		{
+			name: "success",
+			content: `{"rules": [
+				{"name": "burst", "type": "debit_velocity", "outcome": "review", "operations": ["debit"], "window": "10m", "max_count": 5},
+				{"name": "unusual", "type": "unusual_amount", "outcome": "block", "window": "720h", "multiplier": 10, "min_history": 5}
+			]}`,
+			want: []models.RiskRule{
+				{Name: "burst", Type: constants.RiskRuleDebitVelocity, Outcome: constants.RiskOutcomeReview, Operations: []string{"debit"}, Window: "10m", MaxCount: 5, WindowDuration: 10 * time.Minute},
+				{Name: "unusual", Type: constants.RiskRuleUnusualAmount, Outcome: constants.RiskOutcomeBlock, Window: "720h", Multiplier: 10, MinHistory: 5, WindowDuration: 720 * time.Hour},
+			},
+		},
+		{
+			name:    "error unknown type",
+			content: `{"rules": [{"name": "x", "type": "geo", "outcome": "review", "window": "1m"}]}`,
+			wantErr: constants.ErrInvalidRiskRule,
+		},
+		{
+			name:    "error allow outcome",
+			content: `{"rules": [{"name": "x", "type": "debit_velocity", "outcome": "allow", "window": "1m", "max_count": 1}]}`,
+			wantErr: constants.ErrInvalidRiskRule,
+		},
+		{
+			name:    "error bad window",
+			content: `{"rules": [{"name": "x", "type": "debit_velocity", "outcome": "review", "window": "soon", "max_count": 1}]}`,
+			wantErr: constants.ErrInvalidRiskRule,
+		},
+		{
+			name:    "error missing max count",
+			content: `{"rules": [{"name": "x", "type": "client_references", "outcome": "review", "window": "1m"}]}`,
+			wantErr: constants.ErrInvalidRiskRule,
+		},
+		{
+			name:    "error unknown field",
+			content: `{"rules": [{"name": "x", "type": "debit_velocity", "outcome": "review", "window": "1m", "max": 1}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "risk_rules.json")
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			got, err := LoadRiskRules(path)
+			if tt.want == nil {
+				assert.Error(t, err)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("the sample rules load", func(t *testing.T) {
+		_, err := LoadRiskRules("../../risk_rules.json")
+		assert.NoError(t, err)
+	})
+}
+
+func TestRiskService_Evaluate(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRiskRepo := NewMockIRiskRepo(ctrlMock)
+
+	now := time.Now()
+	velocity := models.RiskRule{Name: "burst", Type: constants.RiskRuleDebitVelocity, Outcome: constants.RiskOutcomeReview, Window: "10m", MaxCount: 3, WindowDuration: 10 * time.Minute}
+	newLink := models.RiskRule{Name: "new_link", Type: constants.RiskRuleNewLinkLargeDebit, Outcome: constants.RiskOutcomeReview, Window: "24h", MinAmount: 500000, WindowDuration: 24 * time.Hour}
+	references := models.RiskRule{Name: "references", Type: constants.RiskRuleClientReferences, Outcome: constants.RiskOutcomeBlock, Window: "1m", MaxCount: 10, WindowDuration: time.Minute}
+	unusual := models.RiskRule{Name: "unusual", Type: constants.RiskRuleUnusualAmount, Outcome: constants.RiskOutcomeReview, Window: "720h", Multiplier: 5, MinHistory: 3, WindowDuration: 720 * time.Hour}
+
+	debit := models.RiskCheck{Operation: constants.RiskOperationDebit, TransactionType: constants.TransactionTypeDebit, WalletID: 1, Amount: 100000}
+	external := models.RiskCheck{Operation: constants.RiskOperationExternal, TransactionType: constants.TransactionTypeDebit, WalletID: 1, ClientID: "fastcampus_ecommerce", Amount: 600000}
+
+	tests := []struct {
+		name        string
+		rules       []models.RiskRule
+		check       models.RiskCheck
+		wantOutcome string
+		wantReasons []string
+		wantErr     bool
+		mockFn      func()
+	}{
+		{
+			name:        "allow under every limit",
+			rules:       []models.RiskRule{velocity, unusual},
+			check:       debit,
+			wantOutcome: constants.RiskOutcomeAllow,
+			mockFn: func() {
+				mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, now.Add(-10*time.Minute)).Return(2, nil)
+				mockRiskRepo.EXPECT().GetDebitStats(gomock.Any(), 1, now.Add(-720*time.Hour)).Return(models.RiskDebitStats{Count: 10, Average: 50000}, nil)
+			},
+		},
+		{
+			name:        "review too many debits",
+			rules:       []models.RiskRule{velocity},
+			check:       debit,
+			wantOutcome: constants.RiskOutcomeReview,
+			wantReasons: []string{"burst: 4 debits within 10m"},
+			mockFn: func() {
+				mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(3, nil)
+			},
+		},
+		{
+			name:        "review large debit after a new link",
+			rules:       []models.RiskRule{newLink},
+			check:       external,
+			wantOutcome: constants.RiskOutcomeReview,
+			wantReasons: []string{"new_link: debit of 600000.00 within 24h of linking a client"},
+			mockFn: func() {
+				mockRiskRepo.EXPECT().CountLinksSince(gomock.Any(), 1, now.Add(-24*time.Hour)).Return(1, nil)
+			},
+		},
+		{
+			name:        "allow small debit after a new link",
+			rules:       []models.RiskRule{newLink},
+			check:       debit,
+			wantOutcome: constants.RiskOutcomeAllow,
+			mockFn:      func() {},
+		},
+		{
+			name:        "review amount far over the history",
+			rules:       []models.RiskRule{unusual},
+			check:       debit,
+			wantOutcome: constants.RiskOutcomeReview,
+			wantReasons: []string{"unusual: amount 100000.00 is over 5x the average debit of 10000.00"},
+			mockFn: func() {
+				mockRiskRepo.EXPECT().GetDebitStats(gomock.Any(), 1, gomock.Any()).Return(models.RiskDebitStats{Count: 3, Average: 10000}, nil)
+			},
+		},
+		{
+			name:        "allow without enough history",
+			rules:       []models.RiskRule{unusual},
+			check:       debit,
+			wantOutcome: constants.RiskOutcomeAllow,
+			mockFn: func() {
+				mockRiskRepo.EXPECT().GetDebitStats(gomock.Any(), 1, gomock.Any()).Return(models.RiskDebitStats{Count: 2, Average: 10000}, nil)
+			},
+		},
+		{
+			name:        "block wins over review",
+			rules:       []models.RiskRule{velocity, references},
+			check:       external,
+			wantOutcome: constants.RiskOutcomeBlock,
+			wantReasons: []string{"burst: 5 debits within 10m", "references: 11 references from fastcampus_ecommerce within 1m"},
+			mockFn: func() {
+				mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(4, nil)
+				mockRiskRepo.EXPECT().CountClientReferencesSince(gomock.Any(), "fastcampus_ecommerce", now.Add(-time.Minute)).Return(10, nil)
+			},
+		},
+		{
+			name: "skip credits, other operations and disabled rules",
+			rules: []models.RiskRule{
+				velocity,
+				unusual,
+				{Name: "debit_only", Type: constants.RiskRuleClientReferences, Outcome: constants.RiskOutcomeBlock, Operations: []string{constants.RiskOperationDebit}, MaxCount: 1, WindowDuration: time.Minute},
+				{Name: "off", Type: constants.RiskRuleClientReferences, Outcome: constants.RiskOutcomeBlock, MaxCount: 1, WindowDuration: time.Minute, Disabled: true},
+			},
+			check:       models.RiskCheck{Operation: constants.RiskOperationExternal, TransactionType: constants.TransactionTypeCredit, WalletID: 1, ClientID: "fastcampus_ecommerce", Amount: 100000},
+			wantOutcome: constants.RiskOutcomeAllow,
+			mockFn:      func() {},
+		},
+		{
+			name:    "error count debits",
+			rules:   []models.RiskRule{velocity},
+			check:   debit,
+			wantErr: true,
+			mockFn: func() {
+				mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(0, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &RiskService{
+				RiskRepo: mockRiskRepo,
+				Rules:    tt.rules,
+			}
+			outcome, reasons, err := s.Evaluate(context.Background(), tt.check, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOutcome, outcome)
+			assert.Equal(t, tt.wantReasons, reasons)
+		})
+	}
+}
+
+func TestRiskService_Screen(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRiskRepo := NewMockIRiskRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	velocity := models.RiskRule{Name: "burst", Type: constants.RiskRuleDebitVelocity, Outcome: constants.RiskOutcomeReview, Window: "10m", MaxCount: 3, WindowDuration: 10 * time.Minute}
+	flood := models.RiskRule{Name: "flood", Type: constants.RiskRuleDebitVelocity, Outcome: constants.RiskOutcomeBlock, Window: "10m", MaxCount: 5, WindowDuration: 10 * time.Minute}
+
+	check := models.RiskCheck{
+		Operation:       constants.RiskOperationDebit,
+		TransactionType: constants.TransactionTypeDebit,
+		UserID:          7,
+		Amount:          100000,
+		Reference:       "REF-1",
+	}
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success allow is recorded",
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(0, nil).Times(2)
+				mockRiskRepo.EXPECT().RecordRiskDecision(gomock.Any(), gomock.Any(), nil).
+					DoAndReturn(func(ctx context.Context, decision *models.RiskDecision, review *models.RiskReview) error {
+						assert.Equal(t, models.RiskDecision{
+							Operation:       constants.RiskOperationDebit,
+							TransactionType: constants.TransactionTypeDebit,
+							WalletID:        1,
+							UserID:          7,
+							Amount:          100000,
+							Reference:       "REF-1",
+							Outcome:         constants.RiskOutcomeAllow,
+						}, *decision)
+						return nil
+					})
+			},
+		},
+		{
+			name:    "review is held",
+			wantErr: constants.ErrTransactionUnderReview,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(3, nil).Times(2)
+				mockRiskRepo.EXPECT().RecordRiskDecision(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, decision *models.RiskDecision, review *models.RiskReview) error {
+						assert.Equal(t, constants.RiskOutcomeReview, decision.Outcome)
+						assert.Equal(t, "burst: 4 debits within 10m", decision.Reasons)
+						assert.Equal(t, constants.RiskReviewStatusPending, review.Status)
+						assert.Equal(t, decision.Reasons, review.Reasons)
+						assert.Equal(t, "REF-1", review.Reference)
+						assert.Equal(t, uint64(7), review.UserID)
+						review.ID = 9
+						return nil
+					})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "risk_review.create", auditLog.Action)
+					assert.Equal(t, constants.AuditTargetRiskReview, auditLog.TargetType)
+					assert.Equal(t, "9", auditLog.TargetID)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "block is refused",
+			wantErr: constants.ErrTransactionBlocked,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(5, nil).Times(2)
+				mockRiskRepo.EXPECT().RecordRiskDecision(gomock.Any(), gomock.Any(), nil).
+					DoAndReturn(func(ctx context.Context, decision *models.RiskDecision, review *models.RiskReview) error {
+						assert.Equal(t, constants.RiskOutcomeBlock, decision.Outcome)
+						assert.Equal(t, "burst: 6 debits within 10m; flood: 6 debits within 10m", decision.Reasons)
+						return nil
+					})
+			},
+		},
+		{
+			name:    "error reference already held",
+			wantErr: constants.ErrDuplicateReference,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(3, nil).Times(2)
+				mockRiskRepo.EXPECT().RecordRiskDecision(gomock.Any(), gomock.Any(), gomock.Any()).Return(gorm.ErrDuplicatedKey)
+			},
+		},
+		{
+			name:    "error record decision",
+			wantErr: assert.AnError,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+				mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(0, nil).Times(2)
+				mockRiskRepo.EXPECT().RecordRiskDecision(gomock.Any(), gomock.Any(), nil).Return(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &RiskService{
+				RiskRepo:     mockRiskRepo,
+				WalletRepo:   mockWalletRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				Rules:        []models.RiskRule{velocity, flood},
+			}
+			err := s.Screen(context.Background(), check)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("nil service allows everything", func(t *testing.T) {
+		var s *RiskService
+		assert.NoError(t, s.Screen(context.Background(), check))
+	})
+}
+
+func TestRiskService_ApproveRiskReview(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRiskRepo := NewMockIRiskRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	review := models.RiskReview{
+		ID:              9,
+		Operation:       constants.RiskOperationDebit,
+		TransactionType: constants.TransactionTypeDebit,
+		WalletID:        1,
+		UserID:          7,
+		Amount:          100000,
+		Reference:       "REF-1",
+		Status:          constants.RiskReviewStatusPending,
+	}
+	req := models.RiskReviewDecisionRequest{Note: "called the customer"}
+
+	tests := []struct {
+		name       string
+		wantStatus string
+		wantErr    error
+		mockFn     func()
+	}{
+		{
+			name:       "success replays the debit",
+			wantStatus: constants.RiskReviewStatusApproved,
+			mockFn: func() {
+				mockRiskRepo.EXPECT().GetRiskReviewByID(gomock.Any(), 9).Return(review, nil)
+				mockRiskRepo.EXPECT().DecideRiskReview(gomock.Any(), 9, constants.RiskReviewStatusApproved, "alice", "called the customer", gomock.Any()).Return(nil)
+				mockWalletRepo.EXPECT().UpdateBalance(gomock.Any(), uint64(7), -100000.0, &models.WalletTransaction{
+					Amount:                100000,
+					Reference:             "REF-1",
+					WalletTransactionType: constants.TransactionTypeDebit,
+				}).Return(models.Wallet{ID: 1, Balance: 300000}, nil)
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "risk_review.approve", auditLog.Action)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.debit", auditLog.Action)
+						return nil
+					}),
+				)
+			},
+		},
+		{
+			name:       "error replay marks the review failed",
+			wantStatus: constants.RiskReviewStatusFailed,
+			wantErr:    constants.ErrInsufficientBalance,
+			mockFn: func() {
+				mockRiskRepo.EXPECT().GetRiskReviewByID(gomock.Any(), 9).Return(review, nil)
+				mockRiskRepo.EXPECT().DecideRiskReview(gomock.Any(), 9, constants.RiskReviewStatusApproved, "alice", "called the customer", gomock.Any()).Return(nil)
+				mockWalletRepo.EXPECT().UpdateBalance(gomock.Any(), uint64(7), -100000.0, gomock.Any()).Return(models.Wallet{}, constants.ErrInsufficientBalance)
+				mockRiskRepo.EXPECT().UpdateRiskReviewStatus(gomock.Any(), 9, constants.RiskReviewStatusApproved, constants.RiskReviewStatusFailed, gomock.Any()).Return(nil)
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "risk_review.fail", auditLog.Action)
+						return nil
+					}),
+				)
+			},
+		},
+		{
+			name:    "error not pending",
+			wantErr: constants.ErrRiskReviewNotPending,
+			mockFn: func() {
+				rejected := review
+				rejected.Status = constants.RiskReviewStatusRejected
+				mockRiskRepo.EXPECT().GetRiskReviewByID(gomock.Any(), 9).Return(rejected, nil)
+			},
+		},
+		{
+			name:    "error not found",
+			wantErr: constants.ErrRiskReviewNotFound,
+			mockFn: func() {
+				mockRiskRepo.EXPECT().GetRiskReviewByID(gomock.Any(), 9).Return(models.RiskReview{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:    "error decided by another operator",
+			wantErr: constants.ErrRiskReviewNotPending,
+			mockFn: func() {
+				mockRiskRepo.EXPECT().GetRiskReviewByID(gomock.Any(), 9).Return(review, nil)
+				mockRiskRepo.EXPECT().DecideRiskReview(gomock.Any(), 9, constants.RiskReviewStatusApproved, "alice", "called the customer", gomock.Any()).Return(constants.ErrRiskReviewNotPending)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+			s := &RiskService{
+				RiskRepo:     mockRiskRepo,
+				AuditService: auditSvc,
+				WalletService: &WalletService{
+					WalletRepo:   mockWalletRepo,
+					AuditService: auditSvc,
+				},
+			}
+			got, err := s.ApproveRiskReview(context.Background(), "alice", 9, req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.wantStatus != "" {
+				assert.Equal(t, tt.wantStatus, got.Status)
+			}
+		})
+	}
+}
+
+func TestRiskService_RejectRiskReview(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRiskRepo := NewMockIRiskRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	review := models.RiskReview{ID: 9, Operation: constants.RiskOperationDebit, Status: constants.RiskReviewStatusPending}
+
+	mockRiskRepo.EXPECT().GetRiskReviewByID(gomock.Any(), 9).Return(review, nil)
+	mockRiskRepo.EXPECT().DecideRiskReview(gomock.Any(), 9, constants.RiskReviewStatusRejected, "alice", "fraud", gomock.Any()).Return(nil)
+	mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+		assert.Equal(t, "risk_review.reject", auditLog.Action)
+		assert.JSONEq(t, `{"note":"fraud"}`, auditLog.Detail)
+		return nil
+	})
+
+	s := &RiskService{
+		RiskRepo:     mockRiskRepo,
+		AuditService: &AuditService{AuditRepo: mockAuditRepo},
+	}
+	got, err := s.RejectRiskReview(context.Background(), "alice", 9, models.RiskReviewDecisionRequest{Note: "fraud"})
+	assert.NoError(t, err)
+	assert.Equal(t, constants.RiskReviewStatusRejected, got.Status)
+	assert.Equal(t, "alice", got.DecidedBy)
+	assert.NotNil(t, got.DecidedAt)
+}
+
+func TestWalletService_DebitBalanceHeldForReview(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockRiskRepo := NewMockIRiskRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	req := models.TransactionRequest{Reference: "REF-1", Amount: 100000}
+
+	mockWalletRepo.EXPECT().GetWalletTransactionByReference(gomock.Any(), "REF-1").Return(models.WalletTransaction{}, gorm.ErrRecordNotFound)
+	mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(7)).Return(models.Wallet{ID: 1}, nil)
+	mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(3, nil)
+	mockRiskRepo.EXPECT().RecordRiskDecision(gomock.Any(), gomock.Any(), gomock.Not(gomock.Nil())).Return(nil)
+	mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+
+	auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+	s := &WalletService{
+		WalletRepo:   mockWalletRepo,
+		AuditService: auditSvc,
+		RiskService: &RiskService{
+			RiskRepo:     mockRiskRepo,
+			WalletRepo:   mockWalletRepo,
+			AuditService: auditSvc,
+			Rules: []models.RiskRule{
+				{Name: "burst", Type: constants.RiskRuleDebitVelocity, Outcome: constants.RiskOutcomeReview, Window: "10m", MaxCount: 3, WindowDuration: 10 * time.Minute},
+			},
+		},
+	}
+	_, err := s.DebitBalance(context.Background(), 7, req)
+	assert.ErrorIs(t, err, constants.ErrTransactionUnderReview)
+}
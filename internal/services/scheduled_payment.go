@@ -0,0 +1,498 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	scheduleBatchSize = 100
+	// scheduleLease is how long a worker owns a claimed schedule, long enough
+	// for one debit and short enough for another worker to take over a crash.
+	scheduleLease = 5 * time.Minute
+)
+
+type ScheduledPaymentService struct {
+	ScheduledPaymentRepo i_repository.IScheduledPaymentRepo
+	WalletRepo           i_repository.IWalletRepo
+	WalletService        *WalletService
+	AuditService         *AuditService
+	// RetryInterval is the wait before a failed occurrence, usually one that
+	// hit insufficient funds, is attempted again. After MaxRetries failed
+	// retries the occurrence is skipped and the schedule moves on. An
+	// occurrence held for a risk review is not retried, the schedule waits
+	// for the decision.
+	RetryInterval time.Duration
+	MaxRetries    int
+}
+
+func (s *ScheduledPaymentService) CreateScheduledPayment(ctx context.Context, clientID string, req models.ScheduledPaymentRequest) (models.ScheduledPayment, error) {
+	var (
+		resp models.ScheduledPayment
+	)
+
+	link, err := s.WalletRepo.GetWalletLink(ctx, req.WalletID, clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return resp, constants.ErrWalletNotLinked
+		}
+		return resp, errors.Wrap(err, "failed to get wallet link")
+	}
+
+	if link.Status != "linked" {
+		return resp, constants.ErrWalletNotLinked
+	}
+
+	now := time.Now()
+	startAt := now
+	if req.StartAt != nil {
+		if req.StartAt.Before(now.Add(-time.Minute)) {
+			return resp, fmt.Errorf("%w: start_at is in the past", constants.ErrInvalidScheduleRequest)
+		}
+		startAt = *req.StartAt
+	}
+
+	if req.EndAt != nil && req.EndAt.Before(startAt) {
+		return resp, fmt.Errorf("%w: end_at is before start_at", constants.ErrInvalidScheduleRequest)
+	}
+
+	resp = models.ScheduledPayment{
+		WalletID:       req.WalletID,
+		ClientID:       clientID,
+		Amount:         req.Amount,
+		Description:    req.Description,
+		Frequency:      req.Frequency,
+		StartAt:        startAt,
+		EndAt:          req.EndAt,
+		MaxOccurrences: req.MaxOccurrences,
+		NextRunAt:      startAt,
+		Status:         constants.ScheduleStatusActive,
+	}
+
+	err = s.ScheduledPaymentRepo.CreateScheduledPayment(ctx, &resp)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to create scheduled payment")
+	}
+
+	err = s.recordScheduleChange(ctx, "scheduled_payment.create", resp, nil, map[string]interface{}{"status": resp.Status})
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+func (s *ScheduledPaymentService) GetUserScheduledPayments(ctx context.Context, userID uint64) ([]models.ScheduledPayment, error) {
+	wallet, err := s.WalletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get wallet")
+	}
+
+	resp, err := s.ScheduledPaymentRepo.GetScheduledPayments(ctx, wallet.ID, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get scheduled payments")
+	}
+
+	return resp, nil
+}
+
+func (s *ScheduledPaymentService) GetClientScheduledPayments(ctx context.Context, clientID string, param models.ScheduledPaymentParam) ([]models.ScheduledPayment, error) {
+	resp, err := s.ScheduledPaymentRepo.GetScheduledPayments(ctx, param.WalletID, clientID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get scheduled payments")
+	}
+
+	return resp, nil
+}
+
+// ChangeUserScheduledPayment pauses, resumes or cancels a schedule debiting
+// the user's own wallet.
+func (s *ScheduledPaymentService) ChangeUserScheduledPayment(ctx context.Context, userID uint64, paymentID int, action string) (models.ScheduledPayment, error) {
+	wallet, err := s.WalletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return models.ScheduledPayment{}, errors.Wrap(err, "failed to get wallet")
+	}
+
+	payment, err := s.getScheduledPayment(ctx, paymentID)
+	if err != nil {
+		return payment, err
+	}
+
+	if payment.WalletID != wallet.ID {
+		return models.ScheduledPayment{}, constants.ErrScheduleNotFound
+	}
+
+	return s.changeStatus(ctx, payment, action)
+}
+
+// ChangeClientScheduledPayment pauses, resumes or cancels a schedule created
+// by the client.
+func (s *ScheduledPaymentService) ChangeClientScheduledPayment(ctx context.Context, clientID string, paymentID int, action string) (models.ScheduledPayment, error) {
+	payment, err := s.getScheduledPayment(ctx, paymentID)
+	if err != nil {
+		return payment, err
+	}
+
+	if payment.ClientID != clientID {
+		return models.ScheduledPayment{}, constants.ErrScheduleNotFound
+	}
+
+	return s.changeStatus(ctx, payment, action)
+}
+
+func (s *ScheduledPaymentService) getScheduledPayment(ctx context.Context, paymentID int) (models.ScheduledPayment, error) {
+	payment, err := s.ScheduledPaymentRepo.GetScheduledPaymentByID(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return payment, constants.ErrScheduleNotFound
+		}
+		return payment, errors.Wrap(err, "failed to get scheduled payment")
+	}
+
+	return payment, nil
+}
+
+func (s *ScheduledPaymentService) changeStatus(ctx context.Context, payment models.ScheduledPayment, action string) (models.ScheduledPayment, error) {
+	var (
+		err        error
+		fromStatus = payment.Status
+	)
+
+	switch action {
+	case constants.ScheduleActionPause:
+		err = s.ScheduledPaymentRepo.UpdateScheduledPaymentStatus(ctx, payment.ID, []string{constants.ScheduleStatusActive}, constants.ScheduleStatusPaused)
+		payment.Status = constants.ScheduleStatusPaused
+	case constants.ScheduleActionCancel:
+		err = s.ScheduledPaymentRepo.UpdateScheduledPaymentStatus(ctx, payment.ID,
+			[]string{constants.ScheduleStatusActive, constants.ScheduleStatusPaused}, constants.ScheduleStatusCancelled)
+		payment.Status = constants.ScheduleStatusCancelled
+	case constants.ScheduleActionResume:
+		err = s.resume(ctx, &payment, time.Now())
+	default:
+		return payment, fmt.Errorf("%w: unknown action %s", constants.ErrInvalidScheduleChange, action)
+	}
+	if err != nil {
+		return payment, errors.Wrapf(err, "failed to %s scheduled payment", action)
+	}
+
+	err = s.recordScheduleChange(ctx, "scheduled_payment."+action, payment,
+		map[string]interface{}{"status": fromStatus}, map[string]interface{}{"status": payment.Status})
+	if err != nil {
+		return payment, err
+	}
+
+	return payment, nil
+}
+
+// resume skips the occurrences that fell due while the schedule was paused,
+// a paused subscription is not charged for the time it was off.
+func (s *ScheduledPaymentService) resume(ctx context.Context, payment *models.ScheduledPayment, now time.Time) error {
+	if payment.Status != constants.ScheduleStatusPaused {
+		return fmt.Errorf("%w: scheduled payment is %s", constants.ErrInvalidScheduleChange, payment.Status)
+	}
+
+	next := occurrenceTime(payment.StartAt, payment.Frequency, payment.OccurrenceCount)
+	for next.Before(now) {
+		payment.OccurrenceCount++
+		next = occurrenceTime(payment.StartAt, payment.Frequency, payment.OccurrenceCount)
+	}
+	payment.NextRunAt = next
+	payment.RetryCount = 0
+
+	if scheduleFinished(*payment) {
+		payment.Status = constants.ScheduleStatusCompleted
+		return s.ScheduledPaymentRepo.UpdateScheduledPaymentStatus(ctx, payment.ID, []string{constants.ScheduleStatusPaused}, constants.ScheduleStatusCompleted)
+	}
+
+	payment.Status = constants.ScheduleStatusActive
+	return s.ScheduledPaymentRepo.ResumeScheduledPayment(ctx, payment)
+}
+
+// ProcessDuePayments charges every schedule due at now and returns how many
+// were handled. Schedules claimed by another worker are left alone.
+func (s *ScheduledPaymentService) ProcessDuePayments(ctx context.Context, now time.Time) (int, error) {
+	payments, err := s.ScheduledPaymentRepo.GetDueScheduledPayments(ctx, now, scheduleBatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get due scheduled payments")
+	}
+
+	processed := 0
+	for _, payment := range payments {
+		claimed, err := s.ScheduledPaymentRepo.ClaimScheduledPayment(ctx, payment.ID, now, now.Add(scheduleLease))
+		if err != nil {
+			return processed, errors.Wrap(err, "failed to claim scheduled payment")
+		}
+
+		if !claimed {
+			continue
+		}
+
+		s.runOccurrence(ctx, &payment, now)
+
+		err = s.ScheduledPaymentRepo.UpdateScheduledPaymentRun(ctx, &payment)
+		if err != nil {
+			return processed, errors.Wrap(err, "failed to update scheduled payment")
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+func (s *ScheduledPaymentService) runOccurrence(ctx context.Context, payment *models.ScheduledPayment, now time.Time) {
+	payment.LastRunAt = &now
+	reference := scheduleReference(payment.ID, payment.OccurrenceCount+1)
+
+	link, err := s.WalletRepo.GetWalletLink(ctx, payment.WalletID, payment.ClientID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.retryOccurrence(ctx, payment, now, err)
+		return
+	}
+
+	if link.Status != "linked" {
+		s.stopSchedule(ctx, payment, constants.ErrWalletNotLinked)
+		return
+	}
+
+	_, err = s.WalletService.ExternalTransaction(ctx, models.ExternalTransactionRequest{
+		Amount:          payment.Amount,
+		Reference:       reference,
+		TransactionType: constants.TransactionTypeDebit,
+		WalletID:        payment.WalletID,
+		ClientID:        payment.ClientID,
+	})
+	if errors.Is(err, constants.ErrDuplicateReference) {
+		// an earlier run posted the occurrence but failed to record it
+		err = s.checkPostedOccurrence(ctx, *payment, reference)
+	}
+
+	switch {
+	case err == nil:
+		payment.PaidCount++
+		payment.LastError = ""
+		advanceSchedule(payment)
+	case errors.Is(err, constants.ErrWalletClosed):
+		s.stopSchedule(ctx, payment, err)
+	case errors.Is(err, constants.ErrTransactionUnderReview):
+		s.parkSchedule(ctx, payment, reference, err)
+	default:
+		s.retryOccurrence(ctx, payment, now, err)
+	}
+}
+
+func (s *ScheduledPaymentService) checkPostedOccurrence(ctx context.Context, payment models.ScheduledPayment, reference string) error {
+	trx, err := s.WalletRepo.GetWalletTransactionByReference(ctx, reference)
+	if err != nil {
+		return errors.Wrap(err, "failed to check posted occurrence")
+	}
+
+	if trx.WalletID != payment.WalletID || trx.ClientID != payment.ClientID || trx.WalletTransactionType != constants.TransactionTypeDebit {
+		return fmt.Errorf("%w: %s belongs to another transaction", constants.ErrDuplicateReference, reference)
+	}
+
+	return nil
+}
+
+func (s *ScheduledPaymentService) retryOccurrence(ctx context.Context, payment *models.ScheduledPayment, now time.Time, cause error) {
+	payment.LastError = truncateScheduleError(cause)
+	payment.RetryCount++
+
+	maxRetries := s.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = constants.DefaultScheduleMaxRetries
+	}
+
+	if payment.RetryCount <= maxRetries {
+		retryInterval := s.RetryInterval
+		if retryInterval == 0 {
+			retryInterval = time.Hour
+		}
+		payment.NextRunAt = now.Add(retryInterval)
+		return
+	}
+
+	occurrence := payment.OccurrenceCount + 1
+	advanceSchedule(payment)
+	s.recordWorkerChange(ctx, "scheduled_payment.skip", *payment, map[string]interface{}{
+		"occurrence": occurrence,
+		"reference":  scheduleReference(payment.ID, occurrence),
+		"error":      payment.LastError,
+	})
+}
+
+// parkSchedule holds the schedule on the occurrence under review, retrying it
+// would only run into the held reference.
+func (s *ScheduledPaymentService) parkSchedule(ctx context.Context, payment *models.ScheduledPayment, reference string, cause error) {
+	payment.LastError = truncateScheduleError(cause)
+	payment.Status = constants.ScheduleStatusUnderReview
+	s.recordWorkerChange(ctx, "scheduled_payment.review", *payment, map[string]interface{}{
+		"occurrence": payment.OccurrenceCount + 1,
+		"reference":  reference,
+	})
+}
+
+// settleReview moves a schedule parked on the decided review past the held
+// occurrence, counted as paid when the review let the debit through. A review
+// of anything else than a parked occurrence is left alone.
+func (s *ScheduledPaymentService) settleReview(ctx context.Context, review models.RiskReview, paid bool) error {
+	if s == nil || review.Operation != constants.RiskOperationExternal {
+		return nil
+	}
+
+	var paymentID, occurrence int
+	_, err := fmt.Sscanf(review.Reference, "SCH-%d-%d", &paymentID, &occurrence)
+	if err != nil || review.Reference != scheduleReference(paymentID, occurrence) {
+		return nil
+	}
+
+	payment, err := s.ScheduledPaymentRepo.GetScheduledPaymentByID(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to get scheduled payment")
+	}
+
+	if payment.Status != constants.ScheduleStatusUnderReview || payment.OccurrenceCount+1 != occurrence ||
+		payment.WalletID != review.WalletID || payment.ClientID != review.ClientID {
+		return nil
+	}
+
+	action := "scheduled_payment.release"
+	if paid {
+		payment.PaidCount++
+		payment.LastError = ""
+	} else {
+		action = "scheduled_payment.skip"
+		payment.LastError = "risk review " + review.Status
+	}
+	payment.Status = constants.ScheduleStatusActive
+	advanceSchedule(&payment)
+
+	err = s.ScheduledPaymentRepo.SettleScheduledPaymentReview(ctx, &payment, occurrence)
+	if err != nil {
+		return errors.Wrap(err, "failed to settle scheduled payment")
+	}
+
+	s.recordWorkerChange(ctx, action, payment, map[string]interface{}{
+		"occurrence": occurrence,
+		"reference":  review.Reference,
+		"review_id":  review.ID,
+	})
+
+	return nil
+}
+
+func (s *ScheduledPaymentService) stopSchedule(ctx context.Context, payment *models.ScheduledPayment, cause error) {
+	payment.LastError = truncateScheduleError(cause)
+	payment.Status = constants.ScheduleStatusCancelled
+	s.recordWorkerChange(ctx, "scheduled_payment.cancel", *payment, map[string]interface{}{
+		"error": payment.LastError,
+	})
+}
+
+// RunWorker processes due schedules every interval until ctx is cancelled.
+func (s *ScheduledPaymentService) RunWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		processed, err := s.ProcessDuePayments(ctx, time.Now())
+		if err != nil {
+			log.Println("failed to process scheduled payments: ", err)
+			continue
+		}
+
+		if processed > 0 {
+			log.Printf("%d scheduled payments processed\n", processed)
+		}
+	}
+}
+
+func (s *ScheduledPaymentService) recordScheduleChange(ctx context.Context, action string, payment models.ScheduledPayment, before interface{}, after interface{}) error {
+	detail := map[string]interface{}{
+		"wallet_id": payment.WalletID,
+		"client_id": payment.ClientID,
+		"amount":    payment.Amount,
+		"frequency": payment.Frequency,
+	}
+
+	return s.AuditService.Record(ctx, action, constants.AuditTargetSchedule, strconv.Itoa(payment.ID), before, after, detail)
+}
+
+// recordWorkerChange only logs a failing audit write, the run outcome still
+// has to be stored to release the schedule.
+func (s *ScheduledPaymentService) recordWorkerChange(ctx context.Context, action string, payment models.ScheduledPayment, detail map[string]interface{}) {
+	detail["wallet_id"] = payment.WalletID
+	detail["client_id"] = payment.ClientID
+
+	err := s.AuditService.Record(ctx, action, constants.AuditTargetSchedule, strconv.Itoa(payment.ID), nil, map[string]interface{}{"status": payment.Status}, detail)
+	if err != nil {
+		log.Println("failed to audit scheduled payment: ", err)
+	}
+}
+
+func advanceSchedule(payment *models.ScheduledPayment) {
+	payment.OccurrenceCount++
+	payment.RetryCount = 0
+	payment.NextRunAt = occurrenceTime(payment.StartAt, payment.Frequency, payment.OccurrenceCount)
+	if scheduleFinished(*payment) {
+		payment.Status = constants.ScheduleStatusCompleted
+	}
+}
+
+func scheduleFinished(payment models.ScheduledPayment) bool {
+	if payment.MaxOccurrences > 0 && payment.OccurrenceCount >= payment.MaxOccurrences {
+		return true
+	}
+
+	return payment.EndAt != nil && payment.NextRunAt.After(*payment.EndAt)
+}
+
+func scheduleReference(paymentID int, occurrence int) string {
+	return fmt.Sprintf("SCH-%d-%d", paymentID, occurrence)
+}
+
+// occurrenceTime returns when the occurrence with the given zero based index
+// is due. Occurrences are always counted from the start so a monthly schedule
+// started on the 31st runs on the last day of shorter months and returns to
+// the 31st afterwards.
+func occurrenceTime(start time.Time, frequency string, index int) time.Time {
+	switch frequency {
+	case constants.ScheduleFrequencyWeekly:
+		return start.AddDate(0, 0, 7*index)
+	case constants.ScheduleFrequencyMonthly:
+		year, month, day := start.Date()
+		first := time.Date(year, month+time.Month(index), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		if lastDay := first.AddDate(0, 1, -1).Day(); day > lastDay {
+			day = lastDay
+		}
+		return first.AddDate(0, 0, day-1)
+	default:
+		return start.AddDate(0, 0, index)
+	}
+}
+
+func truncateScheduleError(err error) string {
+	msg := err.Error()
+	if len(msg) > 255 {
+		msg = msg[:255]
+	}
+
+	return msg
+}
@@ -0,0 +1,167 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_scheduled_payment_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIScheduledPaymentRepo is a mock of IScheduledPaymentRepo interface.
+type MockIScheduledPaymentRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIScheduledPaymentRepoMockRecorder
+}
+
+// MockIScheduledPaymentRepoMockRecorder is the mock recorder for MockIScheduledPaymentRepo.
+type MockIScheduledPaymentRepoMockRecorder struct {
+	mock *MockIScheduledPaymentRepo
+}
+
+// NewMockIScheduledPaymentRepo creates a new mock instance.
+func NewMockIScheduledPaymentRepo(ctrl *gomock.Controller) *MockIScheduledPaymentRepo {
+	mock := &MockIScheduledPaymentRepo{ctrl: ctrl}
+	mock.recorder = &MockIScheduledPaymentRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIScheduledPaymentRepo) EXPECT() *MockIScheduledPaymentRepoMockRecorder {
+	return m.recorder
+}
+
+// ClaimScheduledPayment mocks base method.
+func (m *MockIScheduledPaymentRepo) ClaimScheduledPayment(ctx context.Context, paymentID int, now, until time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimScheduledPayment", ctx, paymentID, now, until)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimScheduledPayment indicates an expected call of ClaimScheduledPayment.
+func (mr *MockIScheduledPaymentRepoMockRecorder) ClaimScheduledPayment(ctx, paymentID, now, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimScheduledPayment", reflect.TypeOf((*MockIScheduledPaymentRepo)(nil).ClaimScheduledPayment), ctx, paymentID, now, until)
+}
+
+// CreateScheduledPayment mocks base method.
+func (m *MockIScheduledPaymentRepo) CreateScheduledPayment(ctx context.Context, payment *models.ScheduledPayment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledPayment", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateScheduledPayment indicates an expected call of CreateScheduledPayment.
+func (mr *MockIScheduledPaymentRepoMockRecorder) CreateScheduledPayment(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledPayment", reflect.TypeOf((*MockIScheduledPaymentRepo)(nil).CreateScheduledPayment), ctx, payment)
+}
+
+// GetDueScheduledPayments mocks base method.
+func (m *MockIScheduledPaymentRepo) GetDueScheduledPayments(ctx context.Context, now time.Time, limit int) ([]models.ScheduledPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueScheduledPayments", ctx, now, limit)
+	ret0, _ := ret[0].([]models.ScheduledPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueScheduledPayments indicates an expected call of GetDueScheduledPayments.
+func (mr *MockIScheduledPaymentRepoMockRecorder) GetDueScheduledPayments(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueScheduledPayments", reflect.TypeOf((*MockIScheduledPaymentRepo)(nil).GetDueScheduledPayments), ctx, now, limit)
+}
+
+// GetScheduledPaymentByID mocks base method.
+func (m *MockIScheduledPaymentRepo) GetScheduledPaymentByID(ctx context.Context, paymentID int) (models.ScheduledPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledPaymentByID", ctx, paymentID)
+	ret0, _ := ret[0].(models.ScheduledPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledPaymentByID indicates an expected call of GetScheduledPaymentByID.
+func (mr *MockIScheduledPaymentRepoMockRecorder) GetScheduledPaymentByID(ctx, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledPaymentByID", reflect.TypeOf((*MockIScheduledPaymentRepo)(nil).GetScheduledPaymentByID), ctx, paymentID)
+}
+
+// GetScheduledPayments mocks base method.
+func (m *MockIScheduledPaymentRepo) GetScheduledPayments(ctx context.Context, walletID int, clientID string) ([]models.ScheduledPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledPayments", ctx, walletID, clientID)
+	ret0, _ := ret[0].([]models.ScheduledPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledPayments indicates an expected call of GetScheduledPayments.
+func (mr *MockIScheduledPaymentRepoMockRecorder) GetScheduledPayments(ctx, walletID, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledPayments", reflect.TypeOf((*MockIScheduledPaymentRepo)(nil).GetScheduledPayments), ctx, walletID, clientID)
+}
+
+// ResumeScheduledPayment mocks base method.
+func (m *MockIScheduledPaymentRepo) ResumeScheduledPayment(ctx context.Context, payment *models.ScheduledPayment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeScheduledPayment", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeScheduledPayment indicates an expected call of ResumeScheduledPayment.
+func (mr *MockIScheduledPaymentRepoMockRecorder) ResumeScheduledPayment(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeScheduledPayment", reflect.TypeOf((*MockIScheduledPaymentRepo)(nil).ResumeScheduledPayment), ctx, payment)
+}
+
+// SettleScheduledPaymentReview mocks base method.
+func (m *MockIScheduledPaymentRepo) SettleScheduledPaymentReview(ctx context.Context, payment *models.ScheduledPayment, occurrence int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SettleScheduledPaymentReview", ctx, payment, occurrence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SettleScheduledPaymentReview indicates an expected call of SettleScheduledPaymentReview.
+func (mr *MockIScheduledPaymentRepoMockRecorder) SettleScheduledPaymentReview(ctx, payment, occurrence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SettleScheduledPaymentReview", reflect.TypeOf((*MockIScheduledPaymentRepo)(nil).SettleScheduledPaymentReview), ctx, payment, occurrence)
+}
+
+// UpdateScheduledPaymentRun mocks base method.
+func (m *MockIScheduledPaymentRepo) UpdateScheduledPaymentRun(ctx context.Context, payment *models.ScheduledPayment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledPaymentRun", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateScheduledPaymentRun indicates an expected call of UpdateScheduledPaymentRun.
+func (mr *MockIScheduledPaymentRepoMockRecorder) UpdateScheduledPaymentRun(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledPaymentRun", reflect.TypeOf((*MockIScheduledPaymentRepo)(nil).UpdateScheduledPaymentRun), ctx, payment)
+}
+
+// UpdateScheduledPaymentStatus mocks base method.
+func (m *MockIScheduledPaymentRepo) UpdateScheduledPaymentStatus(ctx context.Context, paymentID int, fromStatuses []string, toStatus string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledPaymentStatus", ctx, paymentID, fromStatuses, toStatus)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateScheduledPaymentStatus indicates an expected call of UpdateScheduledPaymentStatus.
+func (mr *MockIScheduledPaymentRepoMockRecorder) UpdateScheduledPaymentStatus(ctx, paymentID, fromStatuses, toStatus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledPaymentStatus", reflect.TypeOf((*MockIScheduledPaymentRepo)(nil).UpdateScheduledPaymentStatus), ctx, paymentID, fromStatuses, toStatus)
+}
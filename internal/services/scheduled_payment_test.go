@@ -0,0 +1,586 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestScheduledPaymentService_CreateScheduledPayment(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockScheduleRepo := NewMockIScheduledPaymentRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	startAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	endAt := startAt.AddDate(0, 6, 0)
+	req := models.ScheduledPaymentRequest{
+		WalletID:       1,
+		Amount:         49000,
+		Description:    "premium plan",
+		Frequency:      constants.ScheduleFrequencyMonthly,
+		StartAt:        &startAt,
+		EndAt:          &endAt,
+		MaxOccurrences: 6,
+	}
+
+	tests := []struct {
+		name    string
+		req     models.ScheduledPaymentRequest
+		want    models.ScheduledPayment
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			req:  req,
+			want: models.ScheduledPayment{
+				ID:             3,
+				WalletID:       1,
+				ClientID:       "fastcampus_ecommerce",
+				Amount:         49000,
+				Description:    "premium plan",
+				Frequency:      constants.ScheduleFrequencyMonthly,
+				StartAt:        startAt,
+				EndAt:          &endAt,
+				MaxOccurrences: 6,
+				NextRunAt:      startAt,
+				Status:         constants.ScheduleStatusActive,
+			},
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(models.WalletLink{Status: "linked"}, nil)
+				mockScheduleRepo.EXPECT().CreateScheduledPayment(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, payment *models.ScheduledPayment) error {
+					payment.ID = 3
+					return nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "scheduled_payment.create", auditLog.Action)
+					assert.Equal(t, "3", auditLog.TargetID)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error wallet not linked",
+			req:     req,
+			wantErr: constants.ErrWalletNotLinked,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(models.WalletLink{Status: "pending"}, nil)
+			},
+		},
+		{
+			name:    "error link not found",
+			req:     req,
+			wantErr: constants.ErrWalletNotLinked,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(models.WalletLink{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "error end before start",
+			req: models.ScheduledPaymentRequest{
+				WalletID:  1,
+				Amount:    49000,
+				Frequency: constants.ScheduleFrequencyDaily,
+				StartAt:   &endAt,
+				EndAt:     &startAt,
+			},
+			wantErr: constants.ErrInvalidScheduleRequest,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(models.WalletLink{Status: "linked"}, nil)
+			},
+		},
+		{
+			name:    "error",
+			req:     req,
+			wantErr: assert.AnError,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(models.WalletLink{Status: "linked"}, nil)
+				mockScheduleRepo.EXPECT().CreateScheduledPayment(gomock.Any(), gomock.Any()).Return(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &ScheduledPaymentService{
+				ScheduledPaymentRepo: mockScheduleRepo,
+				WalletRepo:           mockWalletRepo,
+				AuditService:         &AuditService{AuditRepo: mockAuditRepo},
+			}
+			got, err := s.CreateScheduledPayment(context.Background(), "fastcampus_ecommerce", tt.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScheduledPaymentService_ChangeUserScheduledPayment(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockScheduleRepo := NewMockIScheduledPaymentRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	// started almost 10 days ago, two occurrences were charged before the
+	// pause and the eleventh is due in an hour
+	startAt := time.Now().AddDate(0, 0, -10).Add(time.Hour).Truncate(time.Second)
+	paused := models.ScheduledPayment{
+		ID:              3,
+		WalletID:        1,
+		ClientID:        "fastcampus_ecommerce",
+		Frequency:       constants.ScheduleFrequencyDaily,
+		StartAt:         startAt,
+		OccurrenceCount: 2,
+		RetryCount:      1,
+		Status:          constants.ScheduleStatusPaused,
+	}
+
+	tests := []struct {
+		name       string
+		action     string
+		wantStatus string
+		wantErr    error
+		mockFn     func()
+	}{
+		{
+			name:       "success cancel",
+			action:     constants.ScheduleActionCancel,
+			wantStatus: constants.ScheduleStatusCancelled,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(1)).Return(models.Wallet{ID: 1}, nil)
+				mockScheduleRepo.EXPECT().GetScheduledPaymentByID(gomock.Any(), 3).Return(paused, nil)
+				mockScheduleRepo.EXPECT().UpdateScheduledPaymentStatus(gomock.Any(), 3,
+					[]string{constants.ScheduleStatusActive, constants.ScheduleStatusPaused}, constants.ScheduleStatusCancelled).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:       "success resume skips missed occurrences",
+			action:     constants.ScheduleActionResume,
+			wantStatus: constants.ScheduleStatusActive,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(1)).Return(models.Wallet{ID: 1}, nil)
+				mockScheduleRepo.EXPECT().GetScheduledPaymentByID(gomock.Any(), 3).Return(paused, nil)
+				mockScheduleRepo.EXPECT().ResumeScheduledPayment(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, payment *models.ScheduledPayment) error {
+					assert.Equal(t, 10, payment.OccurrenceCount)
+					assert.Equal(t, startAt.AddDate(0, 0, 10), payment.NextRunAt)
+					assert.Equal(t, 0, payment.RetryCount)
+					return nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "error pause a paused schedule",
+			action:  constants.ScheduleActionPause,
+			wantErr: constants.ErrInvalidScheduleChange,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(1)).Return(models.Wallet{ID: 1}, nil)
+				mockScheduleRepo.EXPECT().GetScheduledPaymentByID(gomock.Any(), 3).Return(paused, nil)
+				mockScheduleRepo.EXPECT().UpdateScheduledPaymentStatus(gomock.Any(), 3,
+					[]string{constants.ScheduleStatusActive}, constants.ScheduleStatusPaused).Return(constants.ErrInvalidScheduleChange)
+			},
+		},
+		{
+			name:    "error schedule of another wallet",
+			action:  constants.ScheduleActionCancel,
+			wantErr: constants.ErrScheduleNotFound,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(1)).Return(models.Wallet{ID: 2}, nil)
+				mockScheduleRepo.EXPECT().GetScheduledPaymentByID(gomock.Any(), 3).Return(paused, nil)
+			},
+		},
+		{
+			name:    "error schedule not found",
+			action:  constants.ScheduleActionCancel,
+			wantErr: constants.ErrScheduleNotFound,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(1)).Return(models.Wallet{ID: 1}, nil)
+				mockScheduleRepo.EXPECT().GetScheduledPaymentByID(gomock.Any(), 3).Return(models.ScheduledPayment{}, gorm.ErrRecordNotFound)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &ScheduledPaymentService{
+				ScheduledPaymentRepo: mockScheduleRepo,
+				WalletRepo:           mockWalletRepo,
+				AuditService:         &AuditService{AuditRepo: mockAuditRepo},
+			}
+			got, err := s.ChangeUserScheduledPayment(context.Background(), 1, 3, tt.action)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.Status)
+		})
+	}
+}
+
+func TestScheduledPaymentService_ProcessDuePayments(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockScheduleRepo := NewMockIScheduledPaymentRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	now := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	startAt := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	due := models.ScheduledPayment{
+		ID:              3,
+		WalletID:        1,
+		ClientID:        "fastcampus_ecommerce",
+		Amount:          49000,
+		Frequency:       constants.ScheduleFrequencyMonthly,
+		StartAt:         startAt,
+		MaxOccurrences:  6,
+		OccurrenceCount: 2,
+		PaidCount:       2,
+		NextRunAt:       now,
+		Status:          constants.ScheduleStatusActive,
+	}
+	linked := models.WalletLink{Status: "linked"}
+	reference := "SCH-3-3"
+
+	expectDebit := func(err error) {
+		mockWalletRepo.EXPECT().GetWalletTransactionByReference(gomock.Any(), reference).Return(models.WalletTransaction{}, gorm.ErrRecordNotFound)
+		debit := &models.WalletTransaction{
+			WalletID:              1,
+			Amount:                49000,
+			Reference:             reference,
+			WalletTransactionType: constants.TransactionTypeDebit,
+			ClientID:              "fastcampus_ecommerce",
+		}
+		if err != nil {
+			mockWalletRepo.EXPECT().DebitWithFee(gomock.Any(), debit, nil).Return(models.Wallet{}, 0.0, err)
+			return
+		}
+		mockWalletRepo.EXPECT().DebitWithFee(gomock.Any(), debit, nil).Return(models.Wallet{ID: 1, Balance: 100000}, 0.0, nil)
+		mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+	}
+
+	tests := []struct {
+		name      string
+		payment   models.ScheduledPayment
+		claimed   bool
+		want      models.ScheduledPayment
+		wantCount int
+		mockFn    func()
+	}{
+		{
+			name:      "success charge occurrence",
+			payment:   due,
+			claimed:   true,
+			wantCount: 1,
+			want: func() models.ScheduledPayment {
+				p := due
+				p.OccurrenceCount = 3
+				p.PaidCount = 3
+				p.NextRunAt = time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+				p.LastRunAt = &now
+				return p
+			}(),
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(linked, nil)
+				expectDebit(nil)
+			},
+		},
+		{
+			name:      "success retry on insufficient funds",
+			payment:   due,
+			claimed:   true,
+			wantCount: 1,
+			want: func() models.ScheduledPayment {
+				p := due
+				p.RetryCount = 1
+				p.NextRunAt = now.Add(30 * time.Minute)
+				p.LastRunAt = &now
+				p.LastError = "failed to debit balance: current balance is not enough to perform the transaction: 10.000000 - -49000.000000"
+				return p
+			}(),
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(linked, nil)
+				expectDebit(fmt.Errorf("%w: %f - %f", constants.ErrInsufficientBalance, 10.0, -49000.0))
+			},
+		},
+		{
+			name: "success skip occurrence after last retry",
+			payment: func() models.ScheduledPayment {
+				p := due
+				p.RetryCount = 2
+				return p
+			}(),
+			claimed:   true,
+			wantCount: 1,
+			want: func() models.ScheduledPayment {
+				p := due
+				p.OccurrenceCount = 3
+				p.NextRunAt = time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+				p.LastRunAt = &now
+				p.LastError = "failed to debit balance: current balance is not enough to perform the transaction"
+				return p
+			}(),
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(linked, nil)
+				expectDebit(constants.ErrInsufficientBalance)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "scheduled_payment.skip", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name:      "success occurrence already posted",
+			payment:   due,
+			claimed:   true,
+			wantCount: 1,
+			want: func() models.ScheduledPayment {
+				p := due
+				p.OccurrenceCount = 3
+				p.PaidCount = 3
+				p.NextRunAt = time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+				p.LastRunAt = &now
+				return p
+			}(),
+			mockFn: func() {
+				posted := models.WalletTransaction{ID: 9, WalletID: 1, WalletTransactionType: constants.TransactionTypeDebit, ClientID: "fastcampus_ecommerce"}
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(linked, nil)
+				mockWalletRepo.EXPECT().GetWalletTransactionByReference(gomock.Any(), reference).Return(posted, nil).Times(2)
+			},
+		},
+		{
+			name: "success complete last occurrence",
+			payment: func() models.ScheduledPayment {
+				p := due
+				p.MaxOccurrences = 3
+				return p
+			}(),
+			claimed:   true,
+			wantCount: 1,
+			want: func() models.ScheduledPayment {
+				p := due
+				p.MaxOccurrences = 3
+				p.OccurrenceCount = 3
+				p.PaidCount = 3
+				p.NextRunAt = time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+				p.LastRunAt = &now
+				p.Status = constants.ScheduleStatusCompleted
+				return p
+			}(),
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(linked, nil)
+				expectDebit(nil)
+			},
+		},
+		{
+			name:      "success cancel when unlinked",
+			payment:   due,
+			claimed:   true,
+			wantCount: 1,
+			want: func() models.ScheduledPayment {
+				p := due
+				p.LastRunAt = &now
+				p.LastError = constants.ErrWalletNotLinked.Error()
+				p.Status = constants.ScheduleStatusCancelled
+				return p
+			}(),
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(models.WalletLink{Status: "unlinked"}, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "scheduled_payment.cancel", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "success claimed by another worker",
+			payment: due,
+			claimed: false,
+			mockFn:  func() {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockScheduleRepo.EXPECT().GetDueScheduledPayments(gomock.Any(), now, scheduleBatchSize).Return([]models.ScheduledPayment{tt.payment}, nil)
+			mockScheduleRepo.EXPECT().ClaimScheduledPayment(gomock.Any(), 3, now, now.Add(scheduleLease)).Return(tt.claimed, nil)
+			tt.mockFn()
+			if tt.claimed {
+				mockScheduleRepo.EXPECT().UpdateScheduledPaymentRun(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, payment *models.ScheduledPayment) error {
+					assert.Equal(t, tt.want, *payment)
+					return nil
+				})
+			}
+
+			walletSvc := &WalletService{
+				WalletRepo:   mockWalletRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+			}
+			s := &ScheduledPaymentService{
+				ScheduledPaymentRepo: mockScheduleRepo,
+				WalletRepo:           mockWalletRepo,
+				WalletService:        walletSvc,
+				AuditService:         walletSvc.AuditService,
+				RetryInterval:        30 * time.Minute,
+				MaxRetries:           2,
+			}
+			got, err := s.ProcessDuePayments(context.Background(), now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCount, got)
+		})
+	}
+}
+
+func TestScheduledPaymentService_ReviewThenApproval(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockScheduleRepo := NewMockIScheduledPaymentRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockRiskRepo := NewMockIRiskRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	now := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	due := models.ScheduledPayment{
+		ID:              3,
+		WalletID:        1,
+		ClientID:        "fastcampus_ecommerce",
+		Amount:          49000,
+		Frequency:       constants.ScheduleFrequencyMonthly,
+		StartAt:         time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC),
+		OccurrenceCount: 2,
+		PaidCount:       2,
+		NextRunAt:       now,
+		Status:          constants.ScheduleStatusActive,
+	}
+
+	var actions []string
+	mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+		actions = append(actions, auditLog.Action)
+		return nil
+	}).AnyTimes()
+
+	auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+	walletSvc := &WalletService{
+		WalletRepo:   mockWalletRepo,
+		AuditService: auditSvc,
+	}
+	riskSvc := &RiskService{
+		RiskRepo:      mockRiskRepo,
+		WalletRepo:    mockWalletRepo,
+		WalletService: walletSvc,
+		AuditService:  auditSvc,
+		Rules: []models.RiskRule{
+			{Name: "burst", Type: constants.RiskRuleDebitVelocity, Outcome: constants.RiskOutcomeReview, Window: "10m", MaxCount: 3, WindowDuration: 10 * time.Minute},
+		},
+	}
+	walletSvc.RiskService = riskSvc
+	s := &ScheduledPaymentService{
+		ScheduledPaymentRepo: mockScheduleRepo,
+		WalletRepo:           mockWalletRepo,
+		WalletService:        walletSvc,
+		AuditService:         auditSvc,
+		RetryInterval:        30 * time.Minute,
+		MaxRetries:           2,
+	}
+	riskSvc.ScheduledPaymentService = s
+
+	// the run is held for review, the schedule waits for the decision
+	// instead of retrying into the held reference
+	var review models.RiskReview
+	mockScheduleRepo.EXPECT().GetDueScheduledPayments(gomock.Any(), now, scheduleBatchSize).Return([]models.ScheduledPayment{due}, nil)
+	mockScheduleRepo.EXPECT().ClaimScheduledPayment(gomock.Any(), 3, now, now.Add(scheduleLease)).Return(true, nil)
+	mockWalletRepo.EXPECT().GetWalletLink(gomock.Any(), 1, "fastcampus_ecommerce").Return(models.WalletLink{Status: "linked"}, nil)
+	mockWalletRepo.EXPECT().GetWalletTransactionByReference(gomock.Any(), "SCH-3-3").Return(models.WalletTransaction{}, gorm.ErrRecordNotFound)
+	mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(3, nil)
+	mockRiskRepo.EXPECT().RecordRiskDecision(gomock.Any(), gomock.Any(), gomock.Not(gomock.Nil())).
+		DoAndReturn(func(ctx context.Context, decision *models.RiskDecision, held *models.RiskReview) error {
+			held.ID = 9
+			review = *held
+			return nil
+		})
+	parked := due
+	mockScheduleRepo.EXPECT().UpdateScheduledPaymentRun(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, payment *models.ScheduledPayment) error {
+		assert.Equal(t, constants.ScheduleStatusUnderReview, payment.Status)
+		assert.Equal(t, 2, payment.OccurrenceCount)
+		assert.Equal(t, 0, payment.RetryCount)
+		parked = *payment
+		return nil
+	})
+
+	processed, err := s.ProcessDuePayments(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, []string{"risk_review.create", "scheduled_payment.review"}, actions)
+
+	// the approval posts the held occurrence and moves the schedule on
+	actions = nil
+	mockRiskRepo.EXPECT().GetRiskReviewByID(gomock.Any(), 9).Return(review, nil)
+	mockRiskRepo.EXPECT().DecideRiskReview(gomock.Any(), 9, constants.RiskReviewStatusApproved, "alice", "", gomock.Any()).Return(nil)
+	mockWalletRepo.EXPECT().DebitWithFee(gomock.Any(), &models.WalletTransaction{
+		WalletID:              1,
+		Amount:                49000,
+		Reference:             "SCH-3-3",
+		WalletTransactionType: constants.TransactionTypeDebit,
+		ClientID:              "fastcampus_ecommerce",
+	}, nil).Return(models.Wallet{ID: 1, Balance: 100000}, 0.0, nil)
+	mockScheduleRepo.EXPECT().GetScheduledPaymentByID(gomock.Any(), 3).DoAndReturn(func(ctx context.Context, paymentID int) (models.ScheduledPayment, error) {
+		return parked, nil
+	})
+	mockScheduleRepo.EXPECT().SettleScheduledPaymentReview(gomock.Any(), gomock.Any(), 3).DoAndReturn(func(ctx context.Context, payment *models.ScheduledPayment, occurrence int) error {
+		assert.Equal(t, constants.ScheduleStatusActive, payment.Status)
+		assert.Equal(t, 3, payment.OccurrenceCount)
+		assert.Equal(t, 3, payment.PaidCount)
+		assert.Equal(t, time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC), payment.NextRunAt)
+		assert.Empty(t, payment.LastError)
+		return nil
+	})
+
+	got, err := riskSvc.ApproveRiskReview(context.Background(), "alice", 9, models.RiskReviewDecisionRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, constants.RiskReviewStatusApproved, got.Status)
+	assert.Equal(t, []string{"risk_review.approve", "balance.debit", "scheduled_payment.release"}, actions)
+}
+
+func Test_occurrenceTime(t *testing.T) {
+	start := time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		frequency string
+		index     int
+		want      time.Time
+	}{
+		{name: "daily", frequency: constants.ScheduleFrequencyDaily, index: 1, want: time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC)},
+		{name: "weekly", frequency: constants.ScheduleFrequencyWeekly, index: 2, want: time.Date(2024, 2, 14, 9, 30, 0, 0, time.UTC)},
+		{name: "monthly first", frequency: constants.ScheduleFrequencyMonthly, index: 0, want: start},
+		{name: "monthly clamps to leap february", frequency: constants.ScheduleFrequencyMonthly, index: 1, want: time.Date(2024, 2, 29, 9, 30, 0, 0, time.UTC)},
+		{name: "monthly returns to the 31st", frequency: constants.ScheduleFrequencyMonthly, index: 2, want: time.Date(2024, 3, 31, 9, 30, 0, 0, time.UTC)},
+		{name: "monthly clamps to 30 days", frequency: constants.ScheduleFrequencyMonthly, index: 3, want: time.Date(2024, 4, 30, 9, 30, 0, 0, time.UTC)},
+		{name: "monthly over the year", frequency: constants.ScheduleFrequencyMonthly, index: 13, want: time.Date(2025, 2, 28, 9, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, occurrenceTime(start, tt.frequency, tt.index))
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	models "ewallet-wallet/internal/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -63,6 +64,37 @@ func (mr *MockIWalletRepoMockRecorder) CreateWalletTrx(ctx, walletHistory interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWalletTrx", reflect.TypeOf((*MockIWalletRepo)(nil).CreateWalletTrx), ctx, walletHistory)
 }
 
+// DebitWithFee mocks base method.
+func (m *MockIWalletRepo) DebitWithFee(ctx context.Context, debit *models.WalletTransaction, fee *models.FeePosting) (models.Wallet, float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DebitWithFee", ctx, debit, fee)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(float64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// DebitWithFee indicates an expected call of DebitWithFee.
+func (mr *MockIWalletRepoMockRecorder) DebitWithFee(ctx, debit, fee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DebitWithFee", reflect.TypeOf((*MockIWalletRepo)(nil).DebitWithFee), ctx, debit, fee)
+}
+
+// GetStatementSnapshot mocks base method.
+func (m *MockIWalletRepo) GetStatementSnapshot(ctx context.Context, walletID int, from, to time.Time) (models.StatementSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatementSnapshot", ctx, walletID, from, to)
+	ret0, _ := ret[0].(models.StatementSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatementSnapshot indicates an expected call of GetStatementSnapshot.
+func (mr *MockIWalletRepoMockRecorder) GetStatementSnapshot(ctx, walletID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatementSnapshot", reflect.TypeOf((*MockIWalletRepo)(nil).GetStatementSnapshot), ctx, walletID, from, to)
+}
+
 // GetWalletByID mocks base method.
 func (m *MockIWalletRepo) GetWalletByID(ctx context.Context, walletID int) (models.Wallet, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +155,21 @@ func (mr *MockIWalletRepoMockRecorder) GetWalletLink(ctx, walletID, clientSource
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletLink", reflect.TypeOf((*MockIWalletRepo)(nil).GetWalletLink), ctx, walletID, clientSource)
 }
 
+// GetWalletStatusHistory mocks base method.
+func (m *MockIWalletRepo) GetWalletStatusHistory(ctx context.Context, walletID int) ([]models.WalletStatusHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletStatusHistory", ctx, walletID)
+	ret0, _ := ret[0].([]models.WalletStatusHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletStatusHistory indicates an expected call of GetWalletStatusHistory.
+func (mr *MockIWalletRepoMockRecorder) GetWalletStatusHistory(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletStatusHistory", reflect.TypeOf((*MockIWalletRepo)(nil).GetWalletStatusHistory), ctx, walletID)
+}
+
 // GetWalletTransactionByReference mocks base method.
 func (m *MockIWalletRepo) GetWalletTransactionByReference(ctx context.Context, reference string) (models.WalletTransaction, error) {
 	m.ctrl.T.Helper()
@@ -152,34 +199,63 @@ func (mr *MockIWalletRepoMockRecorder) InsertWalletLink(ctx, req interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWalletLink", reflect.TypeOf((*MockIWalletRepo)(nil).InsertWalletLink), ctx, req)
 }
 
+// SearchWallets mocks base method.
+func (m *MockIWalletRepo) SearchWallets(ctx context.Context, userID uint64, email string, offset, limit int) ([]models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchWallets", ctx, userID, email, offset, limit)
+	ret0, _ := ret[0].([]models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchWallets indicates an expected call of SearchWallets.
+func (mr *MockIWalletRepoMockRecorder) SearchWallets(ctx, userID, email, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchWallets", reflect.TypeOf((*MockIWalletRepo)(nil).SearchWallets), ctx, userID, email, offset, limit)
+}
+
 // UpdateBalance mocks base method.
-func (m *MockIWalletRepo) UpdateBalance(ctx context.Context, userID uint64, amount float64) (models.Wallet, error) {
+func (m *MockIWalletRepo) UpdateBalance(ctx context.Context, userID uint64, amount float64, walletTrx *models.WalletTransaction) (models.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBalance", ctx, userID, amount)
+	ret := m.ctrl.Call(m, "UpdateBalance", ctx, userID, amount, walletTrx)
 	ret0, _ := ret[0].(models.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateBalance indicates an expected call of UpdateBalance.
-func (mr *MockIWalletRepoMockRecorder) UpdateBalance(ctx, userID, amount interface{}) *gomock.Call {
+func (mr *MockIWalletRepoMockRecorder) UpdateBalance(ctx, userID, amount, walletTrx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockIWalletRepo)(nil).UpdateBalance), ctx, userID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockIWalletRepo)(nil).UpdateBalance), ctx, userID, amount, walletTrx)
 }
 
 // UpdateBalanceByID mocks base method.
-func (m *MockIWalletRepo) UpdateBalanceByID(ctx context.Context, walletID int, amount float64) (models.Wallet, error) {
+func (m *MockIWalletRepo) UpdateBalanceByID(ctx context.Context, walletID int, amount float64, walletTrx *models.WalletTransaction) (models.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBalanceByID", ctx, walletID, amount)
+	ret := m.ctrl.Call(m, "UpdateBalanceByID", ctx, walletID, amount, walletTrx)
 	ret0, _ := ret[0].(models.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateBalanceByID indicates an expected call of UpdateBalanceByID.
-func (mr *MockIWalletRepoMockRecorder) UpdateBalanceByID(ctx, walletID, amount interface{}) *gomock.Call {
+func (mr *MockIWalletRepoMockRecorder) UpdateBalanceByID(ctx, walletID, amount, walletTrx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalanceByID", reflect.TypeOf((*MockIWalletRepo)(nil).UpdateBalanceByID), ctx, walletID, amount, walletTrx)
+}
+
+// UpdateSpendOrder mocks base method.
+func (m *MockIWalletRepo) UpdateSpendOrder(ctx context.Context, userID uint64, spendOrder string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSpendOrder", ctx, userID, spendOrder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSpendOrder indicates an expected call of UpdateSpendOrder.
+func (mr *MockIWalletRepoMockRecorder) UpdateSpendOrder(ctx, userID, spendOrder interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalanceByID", reflect.TypeOf((*MockIWalletRepo)(nil).UpdateBalanceByID), ctx, walletID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSpendOrder", reflect.TypeOf((*MockIWalletRepo)(nil).UpdateSpendOrder), ctx, userID, spendOrder)
 }
 
 // UpdateStatusWalletLink mocks base method.
@@ -195,3 +271,46 @@ func (mr *MockIWalletRepoMockRecorder) UpdateStatusWalletLink(ctx, walletID, cli
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusWalletLink", reflect.TypeOf((*MockIWalletRepo)(nil).UpdateStatusWalletLink), ctx, walletID, clientSource, status)
 }
+
+// UpdateWalletEmail mocks base method.
+func (m *MockIWalletRepo) UpdateWalletEmail(ctx context.Context, userID uint64, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletEmail", ctx, userID, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWalletEmail indicates an expected call of UpdateWalletEmail.
+func (mr *MockIWalletRepoMockRecorder) UpdateWalletEmail(ctx, userID, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletEmail", reflect.TypeOf((*MockIWalletRepo)(nil).UpdateWalletEmail), ctx, userID, email)
+}
+
+// UpdateWalletLinkOTP mocks base method.
+func (m *MockIWalletRepo) UpdateWalletLinkOTP(ctx context.Context, walletID int, clientSource, otp string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletLinkOTP", ctx, walletID, clientSource, otp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWalletLinkOTP indicates an expected call of UpdateWalletLinkOTP.
+func (mr *MockIWalletRepoMockRecorder) UpdateWalletLinkOTP(ctx, walletID, clientSource, otp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletLinkOTP", reflect.TypeOf((*MockIWalletRepo)(nil).UpdateWalletLinkOTP), ctx, walletID, clientSource, otp)
+}
+
+// UpdateWalletStatus mocks base method.
+func (m *MockIWalletRepo) UpdateWalletStatus(ctx context.Context, walletID int, fromStatuses []string, history *models.WalletStatusHistory) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletStatus", ctx, walletID, fromStatuses, history)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWalletStatus indicates an expected call of UpdateWalletStatus.
+func (mr *MockIWalletRepoMockRecorder) UpdateWalletStatus(ctx, walletID, fromStatuses, history interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletStatus", reflect.TypeOf((*MockIWalletRepo)(nil).UpdateWalletStatus), ctx, walletID, fromStatuses, history)
+}
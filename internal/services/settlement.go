@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	settlementDateLayout = "2006-01-02"
+	maxSettlementDays    = 31
+)
+
+type SettlementService struct {
+	SettlementRepo i_repository.ISettlementRepo
+	// Location decides where a settlement day starts and ends, defaults to
+	// the server's local time.
+	Location *time.Location
+}
+
+// GetClientSettlement returns one row per day between dateFrom and dateTo,
+// both inclusive, including days without any transaction.
+func (s *SettlementService) GetClientSettlement(ctx context.Context, clientID string, dateFrom string, dateTo string) ([]models.SettlementDay, error) {
+	from, to, err := s.parseRange(dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.clientSettlement(ctx, clientID, from, to)
+}
+
+// GetSettlements builds the settlement of every client that booked anything
+// in the range, used by the batch job.
+func (s *SettlementService) GetSettlements(ctx context.Context, dateFrom string, dateTo string) ([]models.SettlementDay, error) {
+	from, to, err := s.parseRange(dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	clientIDs, err := s.SettlementRepo.GetSettlementClientIDs(ctx, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get settlement clients")
+	}
+
+	resp := []models.SettlementDay{}
+	for _, clientID := range clientIDs {
+		days, err := s.clientSettlement(ctx, clientID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, days...)
+	}
+
+	return resp, nil
+}
+
+func (s *SettlementService) clientSettlement(ctx context.Context, clientID string, from time.Time, to time.Time) ([]models.SettlementDay, error) {
+	resp := []models.SettlementDay{}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		totals, err := s.SettlementRepo.GetSettlementTotals(ctx, clientID, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get settlement totals")
+		}
+
+		resp = append(resp, models.SettlementDay{
+			ClientID:         clientID,
+			Date:             day.Format(settlementDateLayout),
+			GrossCredit:      totals.GrossCredit,
+			Debit:            totals.Debit,
+			Refund:           totals.Refund,
+			Fee:              totals.Fee,
+			Net:              math.Round((totals.GrossCredit+totals.Refund-totals.Debit-totals.Fee)*100) / 100,
+			TransactionCount: totals.TransactionCount,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *SettlementService) parseRange(dateFrom string, dateTo string) (time.Time, time.Time, error) {
+	loc := s.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	from, err := time.ParseInLocation(settlementDateLayout, dateFrom, loc)
+	if err != nil {
+		return from, from, fmt.Errorf("%w: date_from %s", constants.ErrInvalidSettlementRange, dateFrom)
+	}
+
+	to, err := time.ParseInLocation(settlementDateLayout, dateTo, loc)
+	if err != nil {
+		return from, to, fmt.Errorf("%w: date_to %s", constants.ErrInvalidSettlementRange, dateTo)
+	}
+
+	// the range is inclusive, settle up to the start of the next day
+	to = to.AddDate(0, 0, 1)
+	if !to.After(from) || to.After(from.AddDate(0, 0, maxSettlementDays)) {
+		return from, to, fmt.Errorf("%w: at most %d days from %s", constants.ErrInvalidSettlementRange, maxSettlementDays, dateFrom)
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_settlement_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockISettlementRepo is a mock of ISettlementRepo interface.
+type MockISettlementRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockISettlementRepoMockRecorder
+}
+
+// MockISettlementRepoMockRecorder is the mock recorder for MockISettlementRepo.
+type MockISettlementRepoMockRecorder struct {
+	mock *MockISettlementRepo
+}
+
+// NewMockISettlementRepo creates a new mock instance.
+func NewMockISettlementRepo(ctrl *gomock.Controller) *MockISettlementRepo {
+	mock := &MockISettlementRepo{ctrl: ctrl}
+	mock.recorder = &MockISettlementRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISettlementRepo) EXPECT() *MockISettlementRepoMockRecorder {
+	return m.recorder
+}
+
+// GetSettlementClientIDs mocks base method.
+func (m *MockISettlementRepo) GetSettlementClientIDs(ctx context.Context, from, to time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettlementClientIDs", ctx, from, to)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettlementClientIDs indicates an expected call of GetSettlementClientIDs.
+func (mr *MockISettlementRepoMockRecorder) GetSettlementClientIDs(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettlementClientIDs", reflect.TypeOf((*MockISettlementRepo)(nil).GetSettlementClientIDs), ctx, from, to)
+}
+
+// GetSettlementTotals mocks base method.
+func (m *MockISettlementRepo) GetSettlementTotals(ctx context.Context, clientID string, from, to time.Time) (models.SettlementTotals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettlementTotals", ctx, clientID, from, to)
+	ret0, _ := ret[0].(models.SettlementTotals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettlementTotals indicates an expected call of GetSettlementTotals.
+func (mr *MockISettlementRepoMockRecorder) GetSettlementTotals(ctx, clientID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettlementTotals", reflect.TypeOf((*MockISettlementRepo)(nil).GetSettlementTotals), ctx, clientID, from, to)
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettlementService_GetClientSettlement(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockISettlementRepo(ctrlMock)
+
+	loc := time.FixedZone("WIB", 7*60*60)
+	day1 := time.Date(2024, 5, 1, 0, 0, 0, 0, loc)
+	day2 := day1.AddDate(0, 0, 1)
+	day3 := day2.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name     string
+		dateFrom string
+		dateTo   string
+		want     []models.SettlementDay
+		wantErr  error
+		mockFn   func()
+	}{
+		{
+			name:     "success",
+			dateFrom: "2024-05-01",
+			dateTo:   "2024-05-02",
+			want: []models.SettlementDay{
+				{ClientID: "fastcampus_ecommerce", Date: "2024-05-01", GrossCredit: 500, Debit: 120.3, Refund: 20, Fee: 2.5, Net: 397.2, TransactionCount: 6},
+				{ClientID: "fastcampus_ecommerce", Date: "2024-05-02", Net: 0},
+			},
+			mockFn: func() {
+				mockRepo.EXPECT().GetSettlementTotals(gomock.Any(), "fastcampus_ecommerce", day1, day2).
+					Return(models.SettlementTotals{GrossCredit: 500, Debit: 120.3, Refund: 20, Fee: 2.5, TransactionCount: 6}, nil)
+				mockRepo.EXPECT().GetSettlementTotals(gomock.Any(), "fastcampus_ecommerce", day2, day3).
+					Return(models.SettlementTotals{}, nil)
+			},
+		},
+		{
+			name:     "error invalid date",
+			dateFrom: "01-05-2024",
+			dateTo:   "2024-05-02",
+			wantErr:  constants.ErrInvalidSettlementRange,
+			mockFn:   func() {},
+		},
+		{
+			name:     "error date to before date from",
+			dateFrom: "2024-05-02",
+			dateTo:   "2024-05-01",
+			wantErr:  constants.ErrInvalidSettlementRange,
+			mockFn:   func() {},
+		},
+		{
+			name:     "error range too long",
+			dateFrom: "2024-05-01",
+			dateTo:   "2024-06-01",
+			wantErr:  constants.ErrInvalidSettlementRange,
+			mockFn:   func() {},
+		},
+		{
+			name:     "error",
+			dateFrom: "2024-05-01",
+			dateTo:   "2024-05-01",
+			wantErr:  assert.AnError,
+			mockFn: func() {
+				mockRepo.EXPECT().GetSettlementTotals(gomock.Any(), "fastcampus_ecommerce", day1, day2).
+					Return(models.SettlementTotals{}, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &SettlementService{
+				SettlementRepo: mockRepo,
+				Location:       loc,
+			}
+			got, err := s.GetClientSettlement(context.Background(), "fastcampus_ecommerce", tt.dateFrom, tt.dateTo)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSettlementService_GetSettlements(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockISettlementRepo(ctrlMock)
+
+	day1 := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name    string
+		want    []models.SettlementDay
+		wantErr bool
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			want: []models.SettlementDay{
+				{ClientID: "fastcampus_ecommerce", Date: "2024-05-01", GrossCredit: 100, Net: 100, TransactionCount: 1},
+				{ClientID: "fastcampus_travel", Date: "2024-05-01", Debit: 40, Fee: 1, Net: -41, TransactionCount: 2},
+			},
+			mockFn: func() {
+				mockRepo.EXPECT().GetSettlementClientIDs(gomock.Any(), day1, day2).Return([]string{"fastcampus_ecommerce", "fastcampus_travel"}, nil)
+				mockRepo.EXPECT().GetSettlementTotals(gomock.Any(), "fastcampus_ecommerce", day1, day2).
+					Return(models.SettlementTotals{GrossCredit: 100, TransactionCount: 1}, nil)
+				mockRepo.EXPECT().GetSettlementTotals(gomock.Any(), "fastcampus_travel", day1, day2).
+					Return(models.SettlementTotals{Debit: 40, Fee: 1, TransactionCount: 2}, nil)
+			},
+		},
+		{
+			name:    "error get clients",
+			wantErr: true,
+			mockFn: func() {
+				mockRepo.EXPECT().GetSettlementClientIDs(gomock.Any(), day1, day2).Return(nil, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &SettlementService{
+				SettlementRepo: mockRepo,
+				Location:       time.UTC,
+			}
+			got, err := s.GetSettlements(context.Background(), "2024-05-01", "2024-05-01")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
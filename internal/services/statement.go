@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	statementDateLayout = "2006-01-02"
+	maxStatementDays    = 366
+)
+
+// GetStatement builds the statement of the user's wallet between dateFrom and
+// dateTo, both inclusive and in server local time.
+func (s *WalletService) GetStatement(ctx context.Context, userID uint64, dateFrom string, dateTo string) (models.Statement, error) {
+	var (
+		resp models.Statement
+	)
+
+	from, err := time.ParseInLocation(statementDateLayout, dateFrom, time.Local)
+	if err != nil {
+		return resp, fmt.Errorf("%w: date_from %s", constants.ErrInvalidStatementRange, dateFrom)
+	}
+
+	to, err := time.ParseInLocation(statementDateLayout, dateTo, time.Local)
+	if err != nil {
+		return resp, fmt.Errorf("%w: date_to %s", constants.ErrInvalidStatementRange, dateTo)
+	}
+
+	to = to.AddDate(0, 0, 1)
+	if !to.After(from) || to.After(from.AddDate(0, 0, maxStatementDays)) {
+		return resp, fmt.Errorf("%w: at most %d days from %s", constants.ErrInvalidStatementRange, maxStatementDays, dateFrom)
+	}
+
+	wallet, err := s.WalletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get wallet")
+	}
+
+	snapshot, err := s.WalletRepo.GetStatementSnapshot(ctx, wallet.ID, from, to)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get statement snapshot")
+	}
+
+	resp = models.Statement{
+		WalletID:       wallet.ID,
+		DateFrom:       dateFrom,
+		DateTo:         dateTo,
+		OpeningBalance: snapshot.OpeningBalance,
+		Entries:        make([]models.StatementEntry, 0, len(snapshot.Transactions)),
+	}
+
+	balance := snapshot.OpeningBalance
+	for _, trx := range snapshot.Transactions {
+		amount := ledgerAmount(trx)
+		if amount >= 0 {
+			resp.TotalCredit = roundAmount(resp.TotalCredit + amount)
+		} else {
+			resp.TotalDebit = roundAmount(resp.TotalDebit - amount)
+		}
+		balance = roundAmount(balance + amount)
+
+		resp.Entries = append(resp.Entries, models.StatementEntry{
+			ID:                    trx.ID,
+			Date:                  trx.CreatedAt,
+			WalletTransactionType: trx.WalletTransactionType,
+			Reference:             trx.Reference,
+			Amount:                amount,
+			Balance:               balance,
+		})
+	}
+	resp.ClosingBalance = balance
+
+	return resp, nil
+}
+
+// ledgerAmount is the signed effect of a ledger row on the balance, the same
+// rule the reconciliation applies in SQL.
+func ledgerAmount(trx models.WalletTransaction) float64 {
+	switch trx.WalletTransactionType {
+	case constants.TransactionTypeCredit, constants.TransactionTypeRefund, constants.TransactionTypeAdjustment:
+		return trx.Amount
+	case constants.TransactionTypeDebit, constants.TransactionTypeFee:
+		return -trx.Amount
+	default:
+		return 0
+	}
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
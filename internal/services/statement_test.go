@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletService_GetStatement(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)
+	day := time.Date(2024, 5, 10, 8, 0, 0, 0, time.Local)
+	wallet := models.Wallet{ID: 7, UserID: 1}
+
+	tests := []struct {
+		name     string
+		dateFrom string
+		dateTo   string
+		want     models.Statement
+		wantErr  error
+		mockFn   func()
+	}{
+		{
+			name:     "success",
+			dateFrom: "2024-05-01",
+			dateTo:   "2024-05-31",
+			want: models.Statement{
+				WalletID:       7,
+				DateFrom:       "2024-05-01",
+				DateTo:         "2024-05-31",
+				OpeningBalance: 100,
+				TotalCredit:    250.1,
+				TotalDebit:     80.7,
+				ClosingBalance: 269.4,
+				Entries: []models.StatementEntry{
+					{ID: 11, Date: day, WalletTransactionType: "CREDIT", Reference: "ref-11", Amount: 200, Balance: 300},
+					{ID: 12, Date: day, WalletTransactionType: "DEBIT", Reference: "ref-12", Amount: -80.2, Balance: 219.8},
+					{ID: 13, Date: day, WalletTransactionType: "FEE", Reference: "ref-13", Amount: -0.5, Balance: 219.3},
+					{ID: 14, Date: day, WalletTransactionType: "REFUND", Reference: "ref-14", Amount: 40.1, Balance: 259.4},
+					{ID: 15, Date: day, WalletTransactionType: "ADJUSTMENT", Reference: "ref-15", Amount: 10, Balance: 269.4},
+				},
+			},
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(1)).Return(wallet, nil)
+				mockRepo.EXPECT().GetStatementSnapshot(gomock.Any(), 7, from, to).Return(models.StatementSnapshot{
+					OpeningBalance: 100,
+					Transactions: []models.WalletTransaction{
+						{ID: 11, WalletID: 7, Amount: 200, WalletTransactionType: "CREDIT", Reference: "ref-11", CreatedAt: day},
+						{ID: 12, WalletID: 7, Amount: 80.2, WalletTransactionType: "DEBIT", Reference: "ref-12", CreatedAt: day},
+						{ID: 13, WalletID: 7, Amount: 0.5, WalletTransactionType: "FEE", Reference: "ref-13", CreatedAt: day},
+						{ID: 14, WalletID: 7, Amount: 40.1, WalletTransactionType: "REFUND", Reference: "ref-14", CreatedAt: day},
+						{ID: 15, WalletID: 7, Amount: 10, WalletTransactionType: "ADJUSTMENT", Reference: "ref-15", CreatedAt: day},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:     "success without movements",
+			dateFrom: "2024-05-01",
+			dateTo:   "2024-05-31",
+			want: models.Statement{
+				WalletID:       7,
+				DateFrom:       "2024-05-01",
+				DateTo:         "2024-05-31",
+				OpeningBalance: 100,
+				ClosingBalance: 100,
+				Entries:        []models.StatementEntry{},
+			},
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(1)).Return(wallet, nil)
+				mockRepo.EXPECT().GetStatementSnapshot(gomock.Any(), 7, from, to).Return(models.StatementSnapshot{OpeningBalance: 100}, nil)
+			},
+		},
+		{
+			name:     "error invalid date",
+			dateFrom: "2024/05/01",
+			dateTo:   "2024-05-31",
+			wantErr:  constants.ErrInvalidStatementRange,
+			mockFn:   func() {},
+		},
+		{
+			name:     "error range too long",
+			dateFrom: "2023-01-01",
+			dateTo:   "2024-05-31",
+			wantErr:  constants.ErrInvalidStatementRange,
+			mockFn:   func() {},
+		},
+		{
+			name:     "error get snapshot",
+			dateFrom: "2024-05-01",
+			dateTo:   "2024-05-31",
+			wantErr:  assert.AnError,
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(1)).Return(wallet, nil)
+				mockRepo.EXPECT().GetStatementSnapshot(gomock.Any(), 7, from, to).Return(models.StatementSnapshot{}, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &WalletService{
+				WalletRepo: mockRepo,
+			}
+			got, err := s.GetStatement(context.Background(), 1, tt.dateFrom, tt.dateTo)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
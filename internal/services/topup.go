@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/interfaces/i_external"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const topUpSweepBatchSize = 100
+
+type TopUpService struct {
+	TopUpRepo     i_repository.ITopUpRepo
+	WalletRepo    i_repository.IWalletRepo
+	WalletService *WalletService
+	AuditService  *AuditService
+	Gateway       i_external.PaymentGateway
+}
+
+// CreateTopUp asks the gateway for payment instructions, the wallet is only
+// credited when the gateway calls back.
+func (s *TopUpService) CreateTopUp(ctx context.Context, userID uint64, req models.TopUpRequest) (models.TopUpIntent, error) {
+	var (
+		resp models.TopUpIntent
+	)
+
+	wallet, err := s.WalletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get wallet")
+	}
+
+	// do not take money that could not be credited
+	switch wallet.Status {
+	case constants.WalletStatusSuspended:
+		return resp, constants.ErrWalletSuspended
+	case constants.WalletStatusClosed:
+		return resp, constants.ErrWalletClosed
+	}
+
+	now := time.Now()
+	resp = models.TopUpIntent{
+		UserID:    userID,
+		WalletID:  wallet.ID,
+		Amount:    req.Amount,
+		Status:    constants.TopUpStatusPending,
+		Reference: fmt.Sprintf("TOP-%d-%d", wallet.ID, now.UnixNano()),
+		Gateway:   s.Gateway.Name(),
+		ExpiresAt: now.Add(constants.DefaultTopUpExpiryHours * time.Hour),
+	}
+
+	payment, err := s.Gateway.CreatePayment(ctx, models.GatewayPaymentRequest{
+		Reference: resp.Reference,
+		WalletID:  resp.WalletID,
+		Amount:    resp.Amount,
+		ExpiresAt: resp.ExpiresAt,
+	})
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to create gateway payment")
+	}
+
+	resp.GatewayReference = payment.GatewayReference
+	resp.PaymentCode = payment.PaymentCode
+	resp.PaymentURL = payment.PaymentURL
+
+	err = s.TopUpRepo.CreateTopUpIntent(ctx, &resp)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to create top-up intent")
+	}
+
+	s.recordTopUpChange(ctx, "topup.create", resp, "")
+
+	return resp, nil
+}
+
+func (s *TopUpService) GetTopUp(ctx context.Context, userID uint64, intentID int) (models.TopUpIntent, error) {
+	resp, err := s.getTopUp(ctx, intentID)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.UserID != userID {
+		return models.TopUpIntent{}, constants.ErrTopUpNotFound
+	}
+
+	return resp, nil
+}
+
+func (s *TopUpService) GetTopUps(ctx context.Context, userID uint64, param models.TopUpParam) ([]models.TopUpIntent, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.TopUpRepo.GetTopUpIntents(ctx, userID, param.Status, offset, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get top-ups")
+	}
+
+	return resp, nil
+}
+
+// HandleCallback applies a gateway callback. Gateways retry until they get a
+// success, so a callback that was already applied returns the intent as is.
+func (s *TopUpService) HandleCallback(ctx context.Context, payload []byte, signature string) (models.TopUpIntent, error) {
+	callback, err := s.Gateway.VerifyCallback(ctx, payload, signature)
+	if err != nil {
+		return models.TopUpIntent{}, err
+	}
+
+	intent, err := s.TopUpRepo.GetTopUpIntentByReference(ctx, callback.Reference)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return intent, constants.ErrTopUpNotFound
+		}
+		return intent, errors.Wrap(err, "failed to get top-up")
+	}
+
+	if callback.GatewayReference != intent.GatewayReference {
+		return intent, fmt.Errorf("%w: gateway reference %s does not match", constants.ErrInvalidGatewayCallback, callback.GatewayReference)
+	}
+
+	switch callback.Status {
+	case constants.TopUpStatusPaid:
+		return s.completeTopUp(ctx, intent, callback)
+	case constants.TopUpStatusFailed:
+		if intent.Status == constants.TopUpStatusFailed {
+			return intent, nil
+		}
+
+		return s.closeTopUp(ctx, intent, constants.TopUpStatusFailed, callback.FailureReason, "topup.fail", time.Now())
+	default:
+		return intent, fmt.Errorf("%w: unknown status %s", constants.ErrInvalidGatewayCallback, callback.Status)
+	}
+}
+
+// ExpireTopUps expires the pending intents whose expiry passed and returns how
+// many were expired.
+func (s *TopUpService) ExpireTopUps(ctx context.Context, now time.Time) (int, error) {
+	intents, err := s.TopUpRepo.GetExpiredTopUpIntents(ctx, now, topUpSweepBatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get expired top-ups")
+	}
+
+	expired := 0
+	for _, intent := range intents {
+		_, err := s.closeTopUp(ctx, intent, constants.TopUpStatusExpired, "", "topup.expire", now)
+		if err != nil {
+			// paid or failed by a callback since it was read
+			if errors.Is(err, constants.ErrTopUpNotPending) {
+				continue
+			}
+			return expired, err
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// RunExpirySweeper expires top-ups every interval until ctx is cancelled.
+func (s *TopUpService) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := s.ExpireTopUps(ctx, time.Now())
+		if err != nil {
+			log.Println("failed to expire top-ups: ", err)
+			continue
+		}
+
+		if expired > 0 {
+			log.Printf("%d top-ups expired\n", expired)
+		}
+	}
+}
+
+func (s *TopUpService) completeTopUp(ctx context.Context, intent models.TopUpIntent, callback models.GatewayCallback) (models.TopUpIntent, error) {
+	if intent.Status == constants.TopUpStatusPaid {
+		return intent, nil
+	}
+
+	if callback.Amount != intent.Amount {
+		return intent, fmt.Errorf("%w: paid %f for %f", constants.ErrInvalidGatewayCallback, callback.Amount, intent.Amount)
+	}
+
+	walletTrx := &models.WalletTransaction{
+		WalletID:              intent.WalletID,
+		Amount:                intent.Amount,
+		WalletTransactionType: constants.TransactionTypeCredit,
+		Reference:             intent.Reference,
+	}
+
+	now := time.Now()
+	wallet, err := s.TopUpRepo.CompleteTopUpIntent(ctx, intent.ID, walletTrx, now)
+	if err != nil {
+		// a concurrent delivery of the same callback won the race
+		if errors.Is(err, constants.ErrTopUpAlreadyPaid) {
+			return s.getTopUp(ctx, intent.ID)
+		}
+		return intent, errors.Wrap(err, "failed to credit top-up")
+	}
+
+	fromStatus := intent.Status
+	intent.Status = constants.TopUpStatusPaid
+	intent.PaidAt = &now
+
+	s.WalletService.recordBalanceChange(ctx, "balance.credit", wallet.ID, wallet.Balance, wallet.Balance+intent.Amount, walletTrx)
+
+	s.recordTopUpChange(ctx, "topup.paid", intent, fromStatus)
+
+	return intent, nil
+}
+
+func (s *TopUpService) getTopUp(ctx context.Context, intentID int) (models.TopUpIntent, error) {
+	intent, err := s.TopUpRepo.GetTopUpIntentByID(ctx, intentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return intent, constants.ErrTopUpNotFound
+		}
+		return intent, errors.Wrap(err, "failed to get top-up")
+	}
+
+	return intent, nil
+}
+
+func (s *TopUpService) closeTopUp(ctx context.Context, intent models.TopUpIntent, status string, reason string, action string, now time.Time) (models.TopUpIntent, error) {
+	err := s.TopUpRepo.UpdateTopUpIntentStatus(ctx, intent.ID, status, reason, now)
+	if err != nil {
+		return intent, errors.Wrapf(err, "failed to mark top-up %s", status)
+	}
+
+	fromStatus := intent.Status
+	intent.Status = status
+	intent.FailureReason = reason
+
+	s.recordTopUpChange(ctx, action, intent, fromStatus)
+
+	return intent, nil
+}
+
+// recordTopUpChange audits a status change already committed, a failed write
+// is queued and never fails the change.
+func (s *TopUpService) recordTopUpChange(ctx context.Context, action string, intent models.TopUpIntent, fromStatus string) {
+	var before interface{}
+	if fromStatus != "" {
+		before = map[string]interface{}{"status": fromStatus}
+	}
+
+	detail := map[string]interface{}{
+		"wallet_id":         intent.WalletID,
+		"amount":            intent.Amount,
+		"reference":         intent.Reference,
+		"gateway":           intent.Gateway,
+		"gateway_reference": intent.GatewayReference,
+	}
+	if intent.FailureReason != "" {
+		detail["failure_reason"] = intent.FailureReason
+	}
+
+	s.AuditService.RecordCommitted(ctx, action, constants.AuditTargetTopUp, strconv.Itoa(intent.ID),
+		before, map[string]interface{}{"status": intent.Status}, detail)
+}
@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_topup_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockITopUpRepo is a mock of ITopUpRepo interface.
+type MockITopUpRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockITopUpRepoMockRecorder
+}
+
+// MockITopUpRepoMockRecorder is the mock recorder for MockITopUpRepo.
+type MockITopUpRepoMockRecorder struct {
+	mock *MockITopUpRepo
+}
+
+// NewMockITopUpRepo creates a new mock instance.
+func NewMockITopUpRepo(ctrl *gomock.Controller) *MockITopUpRepo {
+	mock := &MockITopUpRepo{ctrl: ctrl}
+	mock.recorder = &MockITopUpRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockITopUpRepo) EXPECT() *MockITopUpRepoMockRecorder {
+	return m.recorder
+}
+
+// CompleteTopUpIntent mocks base method.
+func (m *MockITopUpRepo) CompleteTopUpIntent(ctx context.Context, intentID int, walletTrx *models.WalletTransaction, now time.Time) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTopUpIntent", ctx, intentID, walletTrx, now)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTopUpIntent indicates an expected call of CompleteTopUpIntent.
+func (mr *MockITopUpRepoMockRecorder) CompleteTopUpIntent(ctx, intentID, walletTrx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTopUpIntent", reflect.TypeOf((*MockITopUpRepo)(nil).CompleteTopUpIntent), ctx, intentID, walletTrx, now)
+}
+
+// CreateTopUpIntent mocks base method.
+func (m *MockITopUpRepo) CreateTopUpIntent(ctx context.Context, intent *models.TopUpIntent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTopUpIntent", ctx, intent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTopUpIntent indicates an expected call of CreateTopUpIntent.
+func (mr *MockITopUpRepoMockRecorder) CreateTopUpIntent(ctx, intent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTopUpIntent", reflect.TypeOf((*MockITopUpRepo)(nil).CreateTopUpIntent), ctx, intent)
+}
+
+// GetExpiredTopUpIntents mocks base method.
+func (m *MockITopUpRepo) GetExpiredTopUpIntents(ctx context.Context, now time.Time, limit int) ([]models.TopUpIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredTopUpIntents", ctx, now, limit)
+	ret0, _ := ret[0].([]models.TopUpIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredTopUpIntents indicates an expected call of GetExpiredTopUpIntents.
+func (mr *MockITopUpRepoMockRecorder) GetExpiredTopUpIntents(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredTopUpIntents", reflect.TypeOf((*MockITopUpRepo)(nil).GetExpiredTopUpIntents), ctx, now, limit)
+}
+
+// GetTopUpIntentByID mocks base method.
+func (m *MockITopUpRepo) GetTopUpIntentByID(ctx context.Context, intentID int) (models.TopUpIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopUpIntentByID", ctx, intentID)
+	ret0, _ := ret[0].(models.TopUpIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopUpIntentByID indicates an expected call of GetTopUpIntentByID.
+func (mr *MockITopUpRepoMockRecorder) GetTopUpIntentByID(ctx, intentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopUpIntentByID", reflect.TypeOf((*MockITopUpRepo)(nil).GetTopUpIntentByID), ctx, intentID)
+}
+
+// GetTopUpIntentByReference mocks base method.
+func (m *MockITopUpRepo) GetTopUpIntentByReference(ctx context.Context, reference string) (models.TopUpIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopUpIntentByReference", ctx, reference)
+	ret0, _ := ret[0].(models.TopUpIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopUpIntentByReference indicates an expected call of GetTopUpIntentByReference.
+func (mr *MockITopUpRepoMockRecorder) GetTopUpIntentByReference(ctx, reference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopUpIntentByReference", reflect.TypeOf((*MockITopUpRepo)(nil).GetTopUpIntentByReference), ctx, reference)
+}
+
+// GetTopUpIntents mocks base method.
+func (m *MockITopUpRepo) GetTopUpIntents(ctx context.Context, userID uint64, status string, offset, limit int) ([]models.TopUpIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopUpIntents", ctx, userID, status, offset, limit)
+	ret0, _ := ret[0].([]models.TopUpIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopUpIntents indicates an expected call of GetTopUpIntents.
+func (mr *MockITopUpRepoMockRecorder) GetTopUpIntents(ctx, userID, status, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopUpIntents", reflect.TypeOf((*MockITopUpRepo)(nil).GetTopUpIntents), ctx, userID, status, offset, limit)
+}
+
+// UpdateTopUpIntentStatus mocks base method.
+func (m *MockITopUpRepo) UpdateTopUpIntentStatus(ctx context.Context, intentID int, toStatus, reason string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTopUpIntentStatus", ctx, intentID, toStatus, reason, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTopUpIntentStatus indicates an expected call of UpdateTopUpIntentStatus.
+func (mr *MockITopUpRepoMockRecorder) UpdateTopUpIntentStatus(ctx, intentID, toStatus, reason, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTopUpIntentStatus", reflect.TypeOf((*MockITopUpRepo)(nil).UpdateTopUpIntentStatus), ctx, intentID, toStatus, reason, now)
+}
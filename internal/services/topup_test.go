@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestTopUpService_CreateTopUp(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockTopUpRepo := NewMockITopUpRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockGateway := NewMockPaymentGateway(ctrlMock)
+
+	req := models.TopUpRequest{Amount: 100000}
+
+	tests := []struct {
+		name    string
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(10)).Return(models.Wallet{ID: 1, UserID: 10, Status: constants.WalletStatusActive}, nil)
+				mockGateway.EXPECT().Name().Return(constants.GatewayFake)
+				mockGateway.EXPECT().CreatePayment(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req models.GatewayPaymentRequest) (models.GatewayPayment, error) {
+					assert.Regexp(t, `^TOP-1-\d+$`, req.Reference)
+					assert.Equal(t, 1, req.WalletID)
+					assert.Equal(t, float64(100000), req.Amount)
+					return models.GatewayPayment{GatewayReference: "FAKE-" + req.Reference, PaymentCode: "8808000000000001"}, nil
+				})
+				mockTopUpRepo.EXPECT().CreateTopUpIntent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, intent *models.TopUpIntent) error {
+					intent.ID = 7
+					return nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "topup.create", auditLog.Action)
+					assert.Equal(t, "7", auditLog.TargetID)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error wallet closed",
+			wantErr: constants.ErrWalletClosed,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(10)).Return(models.Wallet{ID: 1, UserID: 10, Status: constants.WalletStatusClosed}, nil)
+			},
+		},
+		{
+			name:    "error gateway",
+			wantErr: assert.AnError,
+			mockFn: func() {
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(10)).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+				mockGateway.EXPECT().Name().Return(constants.GatewayFake)
+				mockGateway.EXPECT().CreatePayment(gomock.Any(), gomock.Any()).Return(models.GatewayPayment{}, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &TopUpService{
+				TopUpRepo:    mockTopUpRepo,
+				WalletRepo:   mockWalletRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				Gateway:      mockGateway,
+			}
+			got, err := s.CreateTopUp(context.Background(), 10, req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 7, got.ID)
+			assert.Equal(t, constants.TopUpStatusPending, got.Status)
+			assert.Equal(t, constants.GatewayFake, got.Gateway)
+			assert.Equal(t, "FAKE-"+got.Reference, got.GatewayReference)
+			assert.Equal(t, "8808000000000001", got.PaymentCode)
+		})
+	}
+}
+
+func TestTopUpService_HandleCallback(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockTopUpRepo := NewMockITopUpRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockGateway := NewMockPaymentGateway(ctrlMock)
+
+	payload := []byte(`{"reference":"TOP-1-1"}`)
+	pending := models.TopUpIntent{
+		ID:               7,
+		UserID:           10,
+		WalletID:         1,
+		Amount:           100000,
+		Status:           constants.TopUpStatusPending,
+		Reference:        "TOP-1-1",
+		GatewayReference: "FAKE-TOP-1-1",
+	}
+	paid := pending
+	paid.Status = constants.TopUpStatusPaid
+	paidCallback := models.GatewayCallback{Reference: "TOP-1-1", GatewayReference: "FAKE-TOP-1-1", Status: constants.TopUpStatusPaid, Amount: 100000}
+
+	tests := []struct {
+		name       string
+		wantStatus string
+		wantErr    error
+		mockFn     func()
+	}{
+		{
+			name:       "success paid credits the wallet",
+			wantStatus: constants.TopUpStatusPaid,
+			mockFn: func() {
+				mockGateway.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(paidCallback, nil)
+				mockTopUpRepo.EXPECT().GetTopUpIntentByReference(gomock.Any(), "TOP-1-1").Return(pending, nil)
+				mockTopUpRepo.EXPECT().CompleteTopUpIntent(gomock.Any(), 7, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, intentID int, walletTrx *models.WalletTransaction, now time.Time) (models.Wallet, error) {
+						assert.Equal(t, models.WalletTransaction{WalletID: 1, Amount: 100000, WalletTransactionType: constants.TransactionTypeCredit, Reference: "TOP-1-1"}, *walletTrx)
+						return models.Wallet{ID: 1, Balance: 5000}, nil
+					})
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.credit", auditLog.Action)
+						assert.Equal(t, "1", auditLog.TargetID)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "topup.paid", auditLog.Action)
+						return nil
+					}),
+				)
+			},
+		},
+		{
+			name:       "success replayed callback is not credited again",
+			wantStatus: constants.TopUpStatusPaid,
+			mockFn: func() {
+				mockGateway.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(paidCallback, nil)
+				mockTopUpRepo.EXPECT().GetTopUpIntentByReference(gomock.Any(), "TOP-1-1").Return(paid, nil)
+			},
+		},
+		{
+			name:       "success concurrent callback already credited",
+			wantStatus: constants.TopUpStatusPaid,
+			mockFn: func() {
+				mockGateway.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(paidCallback, nil)
+				mockTopUpRepo.EXPECT().GetTopUpIntentByReference(gomock.Any(), "TOP-1-1").Return(pending, nil)
+				mockTopUpRepo.EXPECT().CompleteTopUpIntent(gomock.Any(), 7, gomock.Any(), gomock.Any()).Return(models.Wallet{}, constants.ErrTopUpAlreadyPaid)
+				mockTopUpRepo.EXPECT().GetTopUpIntentByID(gomock.Any(), 7).Return(paid, nil)
+			},
+		},
+		{
+			name:       "success failed",
+			wantStatus: constants.TopUpStatusFailed,
+			mockFn: func() {
+				mockGateway.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").
+					Return(models.GatewayCallback{Reference: "TOP-1-1", GatewayReference: "FAKE-TOP-1-1", Status: constants.TopUpStatusFailed, FailureReason: "card declined"}, nil)
+				mockTopUpRepo.EXPECT().GetTopUpIntentByReference(gomock.Any(), "TOP-1-1").Return(pending, nil)
+				mockTopUpRepo.EXPECT().UpdateTopUpIntentStatus(gomock.Any(), 7, constants.TopUpStatusFailed, "card declined", gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "topup.fail", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error invalid signature",
+			wantErr: constants.ErrInvalidGatewaySignature,
+			mockFn: func() {
+				mockGateway.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(models.GatewayCallback{}, constants.ErrInvalidGatewaySignature)
+			},
+		},
+		{
+			name:    "error unknown reference",
+			wantErr: constants.ErrTopUpNotFound,
+			mockFn: func() {
+				mockGateway.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(paidCallback, nil)
+				mockTopUpRepo.EXPECT().GetTopUpIntentByReference(gomock.Any(), "TOP-1-1").Return(models.TopUpIntent{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:    "error amount mismatch",
+			wantErr: constants.ErrInvalidGatewayCallback,
+			mockFn: func() {
+				mockGateway.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").
+					Return(models.GatewayCallback{Reference: "TOP-1-1", GatewayReference: "FAKE-TOP-1-1", Status: constants.TopUpStatusPaid, Amount: 10}, nil)
+				mockTopUpRepo.EXPECT().GetTopUpIntentByReference(gomock.Any(), "TOP-1-1").Return(pending, nil)
+			},
+		},
+		{
+			name:    "error gateway reference mismatch",
+			wantErr: constants.ErrInvalidGatewayCallback,
+			mockFn: func() {
+				mockGateway.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").
+					Return(models.GatewayCallback{Reference: "TOP-1-1", GatewayReference: "FAKE-OTHER", Status: constants.TopUpStatusPaid, Amount: 100000}, nil)
+				mockTopUpRepo.EXPECT().GetTopUpIntentByReference(gomock.Any(), "TOP-1-1").Return(pending, nil)
+			},
+		},
+		{
+			name:    "error fail after paid",
+			wantErr: constants.ErrTopUpNotPending,
+			mockFn: func() {
+				mockGateway.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").
+					Return(models.GatewayCallback{Reference: "TOP-1-1", GatewayReference: "FAKE-TOP-1-1", Status: constants.TopUpStatusFailed}, nil)
+				mockTopUpRepo.EXPECT().GetTopUpIntentByReference(gomock.Any(), "TOP-1-1").Return(paid, nil)
+				mockTopUpRepo.EXPECT().UpdateTopUpIntentStatus(gomock.Any(), 7, constants.TopUpStatusFailed, "", gomock.Any()).Return(constants.ErrTopUpNotPending)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+			s := &TopUpService{
+				TopUpRepo:     mockTopUpRepo,
+				WalletService: &WalletService{AuditService: auditSvc},
+				AuditService:  auditSvc,
+				Gateway:       mockGateway,
+			}
+			got, err := s.HandleCallback(context.Background(), payload, "signature")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.Status)
+		})
+	}
+}
+
+func TestTopUpService_ExpireTopUps(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockTopUpRepo := NewMockITopUpRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	now := time.Now()
+
+	mockTopUpRepo.EXPECT().GetExpiredTopUpIntents(gomock.Any(), now, 100).Return([]models.TopUpIntent{
+		{ID: 7, Status: constants.TopUpStatusPending},
+		{ID: 8, Status: constants.TopUpStatusPending},
+	}, nil)
+	mockTopUpRepo.EXPECT().UpdateTopUpIntentStatus(gomock.Any(), 7, constants.TopUpStatusExpired, "", now).Return(nil)
+	mockTopUpRepo.EXPECT().UpdateTopUpIntentStatus(gomock.Any(), 8, constants.TopUpStatusExpired, "", now).Return(constants.ErrTopUpNotPending)
+	mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+		assert.Equal(t, "topup.expire", auditLog.Action)
+		assert.Equal(t, "7", auditLog.TargetID)
+		return nil
+	})
+
+	s := &TopUpService{
+		TopUpRepo:    mockTopUpRepo,
+		AuditService: &AuditService{AuditRepo: mockAuditRepo},
+	}
+	got, err := s.ExpireTopUps(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, got)
+}
@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_ums_connection.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	connectivity "google.golang.org/grpc/connectivity"
+)
+
+// MockUMSConnection is a mock of UMSConnection interface.
+type MockUMSConnection struct {
+	ctrl     *gomock.Controller
+	recorder *MockUMSConnectionMockRecorder
+}
+
+// MockUMSConnectionMockRecorder is the mock recorder for MockUMSConnection.
+type MockUMSConnectionMockRecorder struct {
+	mock *MockUMSConnection
+}
+
+// NewMockUMSConnection creates a new mock instance.
+func NewMockUMSConnection(ctrl *gomock.Controller) *MockUMSConnection {
+	mock := &MockUMSConnection{ctrl: ctrl}
+	mock.recorder = &MockUMSConnectionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUMSConnection) EXPECT() *MockUMSConnectionMockRecorder {
+	return m.recorder
+}
+
+// ConnState mocks base method.
+func (m *MockUMSConnection) ConnState() connectivity.State {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnState")
+	ret0, _ := ret[0].(connectivity.State)
+	return ret0
+}
+
+// ConnState indicates an expected call of ConnState.
+func (mr *MockUMSConnectionMockRecorder) ConnState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnState", reflect.TypeOf((*MockUMSConnection)(nil).ConnState))
+}
+
+// State mocks base method.
+func (m *MockUMSConnection) State() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "State")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// State indicates an expected call of State.
+func (mr *MockUMSConnectionMockRecorder) State() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockUMSConnection)(nil).State))
+}
@@ -2,24 +2,61 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"ewallet-wallet/constants"
 	"ewallet-wallet/internal/interfaces/i_repository"
 	"ewallet-wallet/internal/models"
 	"fmt"
-	"math/rand"
+	"log"
+	"math/big"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
 )
 
 type WalletService struct {
-	WalletRepo i_repository.IWalletRepo
+	WalletRepo   i_repository.IWalletRepo
+	AuditService *AuditService
+	FeeService   *FeeService
+	PromoService *PromoService
+	PINService   *PINService
+	RiskService  *RiskService
+
+	// syncedEmails is the email last written for each user, a validation
+	// bringing the same email again does not go to the database
+	syncedEmails sync.Map
 }
 
 func (s *WalletService) Create(ctx context.Context, wallet *models.Wallet) error {
 	return s.WalletRepo.CreateWallet(ctx, wallet)
 }
 
+// SyncEmail copies the email of a validated token onto the wallet of its
+// user, so the admin search by email finds wallets whatever was sent when
+// they were created. Only an email that changed since the last sync is
+// written, a failure only delays that until the next validation.
+func (s *WalletService) SyncEmail(ctx context.Context, data models.TokenData) {
+	if data.UserID == 0 || data.Email == "" {
+		return
+	}
+
+	if synced, ok := s.syncedEmails.Load(data.UserID); ok && synced == data.Email {
+		return
+	}
+
+	err := s.WalletRepo.UpdateWalletEmail(ctx, data.UserID, data.Email)
+	if err != nil {
+		log.Println("failed to sync wallet email: ", err)
+		return
+	}
+
+	s.syncedEmails.Store(data.UserID, data.Email)
+}
+
 func (s *WalletService) CreditBalance(ctx context.Context, userID uint64, req models.TransactionRequest) (models.BalanceResponse, error) {
 	var (
 		resp models.BalanceResponse
@@ -33,28 +70,24 @@ func (s *WalletService) CreditBalance(ctx context.Context, userID uint64, req mo
 	}
 
 	if history.ID > 0 {
-		return resp, errors.New("reference is duplicated")
-	}
-
-	wallet, err := s.WalletRepo.UpdateBalance(ctx, userID, req.Amount)
-	if err != nil {
-		return resp, errors.Wrap(err, "failed to updated balance")
+		return resp, constants.ErrDuplicateReference
 	}
 
 	walletTrx := &models.WalletTransaction{
-		WalletID:              wallet.ID,
 		Amount:                req.Amount,
 		Reference:             req.Reference,
 		WalletTransactionType: "CREDIT",
 	}
 
-	err = s.WalletRepo.CreateWalletTrx(ctx, walletTrx)
+	wallet, err := s.WalletRepo.UpdateBalance(ctx, userID, req.Amount, walletTrx)
 	if err != nil {
-		return resp, errors.Wrap(err, "failed to insert wallet transaction")
+		return resp, errors.Wrap(err, "failed to updated balance")
 	}
 
 	resp.Balance = wallet.Balance + req.Amount
 
+	s.recordBalanceChange(ctx, "balance.credit", wallet.ID, wallet.Balance, resp.Balance, walletTrx)
+
 	return resp, nil
 }
 
@@ -71,28 +104,48 @@ func (s *WalletService) DebitBalance(ctx context.Context, userID uint64, req mod
 	}
 
 	if history.ID > 0 {
-		return resp, errors.New("reference is duplicated")
+		return resp, constants.ErrDuplicateReference
 	}
 
-	wallet, err := s.WalletRepo.UpdateBalance(ctx, userID, -req.Amount)
+	err = s.PINService.Authorize(ctx, userID, req.Amount)
 	if err != nil {
-		return resp, errors.Wrap(err, "failed to updated balance")
+		return resp, err
+	}
+
+	err = s.RiskService.Screen(ctx, models.RiskCheck{
+		Operation:       constants.RiskOperationDebit,
+		TransactionType: constants.TransactionTypeDebit,
+		UserID:          userID,
+		Amount:          req.Amount,
+		Reference:       req.Reference,
+	})
+	if err != nil {
+		return resp, err
 	}
 
+	return s.debitBalance(ctx, userID, req)
+}
+
+func (s *WalletService) debitBalance(ctx context.Context, userID uint64, req models.TransactionRequest) (models.BalanceResponse, error) {
+	var (
+		resp models.BalanceResponse
+	)
+
 	walletTrx := &models.WalletTransaction{
-		WalletID:              wallet.ID,
 		Amount:                req.Amount,
 		Reference:             req.Reference,
 		WalletTransactionType: "DEBIT",
 	}
 
-	err = s.WalletRepo.CreateWalletTrx(ctx, walletTrx)
+	wallet, err := s.WalletRepo.UpdateBalance(ctx, userID, -req.Amount, walletTrx)
 	if err != nil {
-		return resp, errors.Wrap(err, "failed to insert wallet transaction")
+		return resp, errors.Wrap(err, "failed to updated balance")
 	}
 
 	resp.Balance = wallet.Balance - req.Amount
 
+	s.recordBalanceChange(ctx, "balance.debit", wallet.ID, wallet.Balance, resp.Balance, walletTrx)
+
 	return resp, nil
 }
 
@@ -107,6 +160,8 @@ func (s *WalletService) GetBalance(ctx context.Context, userID uint64) (models.B
 	}
 
 	resp.Balance = wallet.Balance
+	resp.HeldBalance = wallet.HeldBalance
+	resp.PromoBalance = wallet.PromoBalance
 
 	return resp, nil
 }
@@ -122,6 +177,8 @@ func (s *WalletService) ExGetBalance(ctx context.Context, walletID int) (models.
 	}
 
 	resp.Balance = wallet.Balance
+	resp.HeldBalance = wallet.HeldBalance
+	resp.PromoBalance = wallet.PromoBalance
 
 	return resp, nil
 }
@@ -150,13 +207,22 @@ func (s *WalletService) GetWalletHistory(ctx context.Context, userID uint64, par
 func (s *WalletService) CreateWalletLink(ctx context.Context, clientSource string, req *models.WalletLink) (*models.WalletStructOTP, error) {
 	req.ClientSource = clientSource
 	req.Status = "pending"
-	req.OTP = strconv.Itoa(rand.Intn(999999))
+	otp, err := generateOTP()
+	if err != nil {
+		return nil, err
+	}
+	req.OTP = otp
 
-	err := s.WalletRepo.InsertWalletLink(ctx, req)
+	err = s.WalletRepo.InsertWalletLink(ctx, req)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to insert wallet link")
 	}
 
+	err = s.recordLinkChange(ctx, "wallet_link.create", req.WalletID, clientSource, nil, map[string]interface{}{"status": req.Status})
+	if err != nil {
+		return nil, err
+	}
+
 	resp := models.WalletStructOTP{
 		OTP: req.OTP,
 	}
@@ -178,12 +244,32 @@ func (s *WalletService) WalletLinkConfirmation(ctx context.Context, walletID int
 		return fmt.Errorf("invalid otp. requested = %s, store = %s", otp, walletLink.OTP)
 	}
 
-	return s.WalletRepo.UpdateStatusWalletLink(ctx, walletID, clientSource, "linked")
+	err = s.WalletRepo.UpdateStatusWalletLink(ctx, walletID, clientSource, "linked")
+	if err != nil {
+		return err
+	}
+
+	return s.recordLinkChange(ctx, "wallet_link.confirm", walletID, clientSource,
+		map[string]interface{}{"status": walletLink.Status}, map[string]interface{}{"status": "linked"})
 }
 
 func (s *WalletService) WalletUnlink(ctx context.Context, walletID int, clientSource string) error {
+	walletLink, err := s.WalletRepo.GetWalletLink(ctx, walletID, clientSource)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return errors.Wrap(err, "failed to get wallet link")
+	}
+
+	err = s.WalletRepo.UpdateStatusWalletLink(ctx, walletID, clientSource, "unlinked")
+	if err != nil {
+		return err
+	}
+
+	var before interface{}
+	if walletLink.ID > 0 {
+		before = map[string]interface{}{"status": walletLink.Status}
+	}
 
-	return s.WalletRepo.UpdateStatusWalletLink(ctx, walletID, clientSource, "unlinked")
+	return s.recordLinkChange(ctx, "wallet_link.unlink", walletID, clientSource, before, map[string]interface{}{"status": "unlinked"})
 }
 
 func (s *WalletService) ExternalTransaction(ctx context.Context, req models.ExternalTransactionRequest) (models.BalanceResponse, error) {
@@ -199,32 +285,244 @@ func (s *WalletService) ExternalTransaction(ctx context.Context, req models.Exte
 	}
 
 	if history.ID > 0 {
-		return resp, errors.New("reference is duplicated")
+		return resp, constants.ErrDuplicateReference
+	}
+
+	err = s.RiskService.Screen(ctx, models.RiskCheck{
+		Operation:       constants.RiskOperationExternal,
+		TransactionType: req.TransactionType,
+		WalletID:        req.WalletID,
+		ClientID:        req.ClientID,
+		Amount:          req.Amount,
+		Reference:       req.Reference,
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	return s.externalTransaction(ctx, req)
+}
+
+func (s *WalletService) externalTransaction(ctx context.Context, req models.ExternalTransactionRequest) (models.BalanceResponse, error) {
+	var (
+		resp models.BalanceResponse
+	)
+
+	if req.TransactionType == constants.TransactionTypeDebit {
+		quote, err := s.FeeService.Quote(ctx, constants.FeeOperationExternalDebit, req.ClientID, req.WalletID, req.Amount)
+		if err != nil {
+			return resp, err
+		}
+
+		return s.externalDebit(ctx, req, s.FeeService.Posting(req.WalletID, req.ClientID, quote.Fee))
 	}
 
 	amount := req.Amount
-	if req.TransactionType == "DEBIT" {
-		amount = -req.Amount
+	walletTrx := &models.WalletTransaction{
+		Amount:                req.Amount,
+		Reference:             req.Reference,
+		WalletTransactionType: req.TransactionType,
+		ClientID:              req.ClientID,
 	}
 
-	wallet, err := s.WalletRepo.UpdateBalanceByID(ctx, req.WalletID, amount)
+	wallet, err := s.WalletRepo.UpdateBalanceByID(ctx, req.WalletID, amount, walletTrx)
 	if err != nil {
 		return resp, errors.Wrap(err, "failed to updated balance")
 	}
 
+	resp.Balance = wallet.Balance + amount
+
+	s.recordBalanceChange(ctx, "balance."+strings.ToLower(req.TransactionType), wallet.ID, wallet.Balance, resp.Balance, walletTrx)
+
+	return resp, nil
+}
+
+// externalDebit debits a partner payment and its fee together, spending the
+// promo credit first or last as the wallet asks, then pays the cashback of the
+// partner's campaign. A failed cashback does not fail the paid debit.
+func (s *WalletService) externalDebit(ctx context.Context, req models.ExternalTransactionRequest, fee *models.FeePosting) (models.BalanceResponse, error) {
+	var (
+		resp models.BalanceResponse
+	)
+
 	walletTrx := &models.WalletTransaction{
-		WalletID:              wallet.ID,
+		WalletID:              req.WalletID,
 		Amount:                req.Amount,
 		Reference:             req.Reference,
-		WalletTransactionType: req.TransactionType,
+		WalletTransactionType: constants.TransactionTypeDebit,
+		ClientID:              req.ClientID,
 	}
 
-	err = s.WalletRepo.CreateWalletTrx(ctx, walletTrx)
+	wallet, promo, err := s.WalletRepo.DebitWithFee(ctx, walletTrx, fee)
 	if err != nil {
-		return resp, errors.Wrap(err, "failed to insert wallet transaction")
+		return resp, errors.Wrap(err, "failed to debit balance")
 	}
 
-	resp.Balance = wallet.Balance + amount
+	balance := wallet.Balance - req.Amount
+	resp.Balance = balance - fee.Amount()
+	resp.PromoBalance = wallet.PromoBalance - promo
+	resp.PromoSpent = promo
+	resp.Fee = fee.Amount()
+
+	s.recordBalanceChange(ctx, "balance.debit", wallet.ID, wallet.Balance, balance, walletTrx)
+
+	if fee != nil {
+		s.recordBalanceChange(ctx, "balance.fee", wallet.ID, balance, resp.Balance, fee.Fee)
+	}
+
+	cashback, err := s.PromoService.GrantCashback(ctx, walletTrx)
+	if err != nil {
+		log.Println("failed to grant cashback: ", err)
+		return resp, nil
+	}
+
+	resp.Cashback = cashback
+	resp.Balance += cashback
+	resp.PromoBalance += cashback
+
+	return resp, nil
+}
+
+// UpdateSpendOrder picks whether partner debits spend the promo credit or the
+// cash first.
+func (s *WalletService) UpdateSpendOrder(ctx context.Context, userID uint64, req models.SpendOrderRequest) error {
+	wallet, err := s.WalletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get wallet")
+	}
+
+	err = s.WalletRepo.UpdateSpendOrder(ctx, userID, req.SpendOrder)
+	if err != nil {
+		return errors.Wrap(err, "failed to update spend order")
+	}
+
+	return s.AuditService.Record(ctx, "wallet.spend_order", constants.AuditTargetWallet, strconv.Itoa(wallet.ID),
+		map[string]interface{}{"spend_order": wallet.SpendOrder}, map[string]interface{}{"spend_order": req.SpendOrder}, nil)
+}
+
+// QuoteFee previews the fee of a withdrawal or a P2P transfer from the user's
+// wallet.
+func (s *WalletService) QuoteFee(ctx context.Context, userID uint64, param models.FeeQuoteParam) (models.FeeQuote, error) {
+	wallet, err := s.WalletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return models.FeeQuote{}, errors.Wrap(err, "failed to get wallet")
+	}
+
+	return s.FeeService.Quote(ctx, param.Operation, "", wallet.ID, param.Amount)
+}
+
+// ExQuoteFee previews the fee of a partner debit.
+func (s *WalletService) ExQuoteFee(ctx context.Context, walletID int, clientID string, param models.ExFeeQuoteParam) (models.FeeQuote, error) {
+	return s.FeeService.Quote(ctx, constants.FeeOperationExternalDebit, clientID, walletID, param.Amount)
+}
+
+type walletStatusTransition struct {
+	From []string
+	To   string
+}
+
+var walletStatusTransitions = map[string]walletStatusTransition{
+	constants.WalletActionFreeze: {
+		From: []string{constants.WalletStatusActive},
+		To:   constants.WalletStatusFrozen,
+	},
+	constants.WalletActionUnfreeze: {
+		From: []string{constants.WalletStatusFrozen},
+		To:   constants.WalletStatusActive,
+	},
+	constants.WalletActionSuspend: {
+		From: []string{constants.WalletStatusActive, constants.WalletStatusFrozen},
+		To:   constants.WalletStatusSuspended,
+	},
+	constants.WalletActionUnsuspend: {
+		From: []string{constants.WalletStatusSuspended},
+		To:   constants.WalletStatusActive,
+	},
+	constants.WalletActionClose: {
+		From: []string{constants.WalletStatusActive, constants.WalletStatusFrozen, constants.WalletStatusSuspended},
+		To:   constants.WalletStatusClosed,
+	},
+}
+
+func (s *WalletService) ChangeWalletStatus(ctx context.Context, walletID int, action string, changedBy string, req models.WalletStatusRequest) (models.Wallet, error) {
+	transition, ok := walletStatusTransitions[action]
+	if !ok {
+		return models.Wallet{}, fmt.Errorf("%w: unknown action %s", constants.ErrInvalidStatusChange, action)
+	}
+
+	if _, ok := constants.MappingWalletStatusReasonCode[req.ReasonCode]; !ok {
+		return models.Wallet{}, fmt.Errorf("%w: unknown reason code %s", constants.ErrInvalidStatusChange, req.ReasonCode)
+	}
+
+	history := &models.WalletStatusHistory{
+		ToStatus:   transition.To,
+		ReasonCode: req.ReasonCode,
+		Note:       req.Note,
+		ChangedBy:  changedBy,
+	}
+	if transition.To == constants.WalletStatusClosed {
+		history.PayoutRef = fmt.Sprintf("CLOSE-%d-%d", walletID, time.Now().UnixNano())
+	}
+
+	wallet, err := s.WalletRepo.UpdateWalletStatus(ctx, walletID, transition.From, history)
+	if err != nil {
+		return wallet, errors.Wrap(err, "failed to update wallet status")
+	}
+
+	before := map[string]interface{}{"status": history.FromStatus}
+	after := map[string]interface{}{"status": history.ToStatus}
+	if history.PayoutRef != "" {
+		before["balance"] = history.PayoutAmount
+		after["balance"] = wallet.Balance
+	}
+	detail := map[string]interface{}{
+		"reason_code":      req.ReasonCode,
+		"note":             req.Note,
+		"payout_reference": history.PayoutRef,
+	}
+	err = s.AuditService.Record(ctx, "wallet."+action, constants.AuditTargetWallet, strconv.Itoa(wallet.ID), before, after, detail)
+	if err != nil {
+		return wallet, err
+	}
+
+	return wallet, nil
+}
+
+func (s *WalletService) GetWalletStatusHistory(ctx context.Context, walletID int) ([]models.WalletStatusHistory, error) {
+	resp, err := s.WalletRepo.GetWalletStatusHistory(ctx, walletID)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to get wallet status history")
+	}
 
 	return resp, nil
 }
+
+func generateOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate otp")
+	}
+
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// recordBalanceChange audits a balance change that is already committed, a
+// failed audit write is retried later rather than failing the change.
+func (s *WalletService) recordBalanceChange(ctx context.Context, action string, walletID int, before float64, after float64, walletTrx *models.WalletTransaction) {
+	detail := map[string]interface{}{
+		"reference":        walletTrx.Reference,
+		"transaction_type": walletTrx.WalletTransactionType,
+		"amount":           walletTrx.Amount,
+	}
+
+	s.AuditService.RecordCommitted(ctx, action, constants.AuditTargetWallet, strconv.Itoa(walletID),
+		map[string]interface{}{"balance": before}, map[string]interface{}{"balance": after}, detail)
+}
+
+func (s *WalletService) recordLinkChange(ctx context.Context, action string, walletID int, clientSource string, before interface{}, after interface{}) error {
+	detail := map[string]interface{}{
+		"client_source": clientSource,
+	}
+
+	return s.AuditService.Record(ctx, action, constants.AuditTargetLink, strconv.Itoa(walletID), before, after, detail)
+}
@@ -2,13 +2,16 @@ package services
 
 import (
 	"context"
+	"ewallet-wallet/constants"
 	"ewallet-wallet/internal/models"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestWalletService_Create(t *testing.T) {
@@ -16,6 +19,7 @@ func TestWalletService_Create(t *testing.T) {
 	defer ctrlMock.Finish()
 
 	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
 
 	type args struct {
 		ctx    context.Context
@@ -60,7 +64,8 @@ func TestWalletService_Create(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.args)
 			s := &WalletService{
-				WalletRepo: mockRepo,
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
 			}
 			if err := s.Create(tt.args.ctx, tt.args.wallet); (err != nil) != tt.wantErr {
 				t.Errorf("WalletService.Create() error = %v, wantErr %v", err, tt.wantErr)
@@ -74,6 +79,7 @@ func TestWalletService_CreditBalance(t *testing.T) {
 	defer ctrlMock.Finish()
 
 	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
 
 	now := time.Now()
 
@@ -106,7 +112,11 @@ func TestWalletService_CreditBalance(t *testing.T) {
 			mockFn: func(args args) {
 				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{}, nil)
 
-				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, args.req.Amount).Return(models.Wallet{
+				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, args.req.Amount, &models.WalletTransaction{
+					Amount:                args.req.Amount,
+					WalletTransactionType: "CREDIT",
+					Reference:             args.req.Reference,
+				}).Return(models.Wallet{
 					ID:        1,
 					UserID:    1,
 					Balance:   200000,
@@ -114,16 +124,18 @@ func TestWalletService_CreditBalance(t *testing.T) {
 					UpdatedAt: now,
 				}, nil)
 
-				mockRepo.EXPECT().CreateWalletTrx(args.ctx, &models.WalletTransaction{
-					WalletID:              1,
-					Amount:                args.req.Amount,
-					WalletTransactionType: "CREDIT",
-					Reference:             args.req.Reference,
-				}).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(args.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "balance.credit", auditLog.Action)
+					assert.Equal(t, "1", auditLog.TargetID)
+					assert.Equal(t, `{"balance":200000}`, auditLog.Before)
+					assert.Equal(t, `{"balance":300000}`, auditLog.After)
+					assert.Contains(t, auditLog.Detail, args.req.Reference)
+					return nil
+				})
 			},
 		},
 		{
-			name: "error, got wallet with duplicate reference",
+			name: "success, audit write failure does not fail the credit",
 			args: args{
 				ctx:    context.Background(),
 				userID: 1,
@@ -132,22 +144,24 @@ func TestWalletService_CreditBalance(t *testing.T) {
 					Amount:    100000,
 				},
 			},
-			want:    models.BalanceResponse{},
-			wantErr: true,
+			want: models.BalanceResponse{
+				Balance: 300000,
+			},
+			wantErr: false,
 			mockFn: func(args args) {
-				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{
-					ID:                    1,
-					WalletID:              1,
-					Amount:                100000,
-					Reference:             "reference",
-					WalletTransactionType: "CREDIT",
-					CreatedAt:             now,
-					UpdatedAt:             now,
+				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{}, nil)
+
+				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, args.req.Amount, gomock.Any()).Return(models.Wallet{
+					ID:      1,
+					UserID:  1,
+					Balance: 200000,
 				}, nil)
+
+				mockAuditRepo.EXPECT().AppendAuditLog(args.ctx, gomock.Any()).Return(assert.AnError)
 			},
 		},
 		{
-			name: "error update balance",
+			name: "error, got wallet with duplicate reference",
 			args: args{
 				ctx:    context.Background(),
 				userID: 1,
@@ -159,13 +173,19 @@ func TestWalletService_CreditBalance(t *testing.T) {
 			want:    models.BalanceResponse{},
 			wantErr: true,
 			mockFn: func(args args) {
-				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{}, nil)
-
-				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, args.req.Amount).Return(models.Wallet{}, assert.AnError)
+				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{
+					ID:                    1,
+					WalletID:              1,
+					Amount:                100000,
+					Reference:             "reference",
+					WalletTransactionType: "CREDIT",
+					CreatedAt:             now,
+					UpdatedAt:             now,
+				}, nil)
 			},
 		},
 		{
-			name: "error create transaction",
+			name: "error update balance",
 			args: args{
 				ctx:    context.Background(),
 				userID: 1,
@@ -179,20 +199,7 @@ func TestWalletService_CreditBalance(t *testing.T) {
 			mockFn: func(args args) {
 				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{}, nil)
 
-				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, args.req.Amount).Return(models.Wallet{
-					ID:        1,
-					UserID:    1,
-					Balance:   200000,
-					CreatedAt: now,
-					UpdatedAt: now,
-				}, nil)
-
-				mockRepo.EXPECT().CreateWalletTrx(args.ctx, &models.WalletTransaction{
-					WalletID:              1,
-					Amount:                args.req.Amount,
-					WalletTransactionType: "CREDIT",
-					Reference:             args.req.Reference,
-				}).Return(assert.AnError)
+				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, args.req.Amount, gomock.Any()).Return(models.Wallet{}, assert.AnError)
 			},
 		},
 	}
@@ -201,7 +208,8 @@ func TestWalletService_CreditBalance(t *testing.T) {
 			tt.mockFn(tt.args)
 
 			s := &WalletService{
-				WalletRepo: mockRepo,
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
 			}
 			got, err := s.CreditBalance(tt.args.ctx, tt.args.userID, tt.args.req)
 			if (err != nil) != tt.wantErr {
@@ -220,6 +228,7 @@ func TestWalletService_DebitBalance(t *testing.T) {
 	defer ctrlMock.Finish()
 
 	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
 
 	now := time.Now()
 
@@ -252,7 +261,11 @@ func TestWalletService_DebitBalance(t *testing.T) {
 			mockFn: func(args args) {
 				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{}, nil)
 
-				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, (-args.req.Amount)).Return(models.Wallet{
+				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, (-args.req.Amount), &models.WalletTransaction{
+					Amount:                args.req.Amount,
+					WalletTransactionType: "DEBIT",
+					Reference:             args.req.Reference,
+				}).Return(models.Wallet{
 					ID:        1,
 					UserID:    1,
 					Balance:   200000,
@@ -260,12 +273,7 @@ func TestWalletService_DebitBalance(t *testing.T) {
 					UpdatedAt: now,
 				}, nil)
 
-				mockRepo.EXPECT().CreateWalletTrx(args.ctx, &models.WalletTransaction{
-					WalletID:              1,
-					Amount:                args.req.Amount,
-					WalletTransactionType: "DEBIT",
-					Reference:             args.req.Reference,
-				}).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(args.ctx, gomock.Any()).Return(nil)
 			},
 		},
 		{
@@ -307,38 +315,7 @@ func TestWalletService_DebitBalance(t *testing.T) {
 			mockFn: func(args args) {
 				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{}, nil)
 
-				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, (-args.req.Amount)).Return(models.Wallet{}, assert.AnError)
-			},
-		},
-		{
-			name: "error create transaction",
-			args: args{
-				ctx:    context.Background(),
-				userID: 1,
-				req: models.TransactionRequest{
-					Reference: "reference",
-					Amount:    100000,
-				},
-			},
-			want:    models.BalanceResponse{},
-			wantErr: true,
-			mockFn: func(args args) {
-				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{}, nil)
-
-				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, (-args.req.Amount)).Return(models.Wallet{
-					ID:        1,
-					UserID:    1,
-					Balance:   200000,
-					CreatedAt: now,
-					UpdatedAt: now,
-				}, nil)
-
-				mockRepo.EXPECT().CreateWalletTrx(args.ctx, &models.WalletTransaction{
-					WalletID:              1,
-					Amount:                args.req.Amount,
-					WalletTransactionType: "DEBIT",
-					Reference:             args.req.Reference,
-				}).Return(assert.AnError)
+				mockRepo.EXPECT().UpdateBalance(args.ctx, args.userID, (-args.req.Amount), gomock.Any()).Return(models.Wallet{}, assert.AnError)
 			},
 		},
 	}
@@ -346,7 +323,8 @@ func TestWalletService_DebitBalance(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.args)
 			s := &WalletService{
-				WalletRepo: mockRepo,
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
 			}
 			got, err := s.DebitBalance(tt.args.ctx, tt.args.userID, tt.args.req)
 			if (err != nil) != tt.wantErr {
@@ -365,6 +343,7 @@ func TestWalletService_GetBalance(t *testing.T) {
 	defer ctrlMock.Finish()
 
 	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
 
 	now := time.Now()
 	type args struct {
@@ -415,7 +394,8 @@ func TestWalletService_GetBalance(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.args)
 			s := &WalletService{
-				WalletRepo: mockRepo,
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
 			}
 			got, err := s.GetBalance(tt.args.ctx, tt.args.userID)
 			if (err != nil) != tt.wantErr {
@@ -434,6 +414,7 @@ func TestWalletService_ExGetBalance(t *testing.T) {
 	defer ctrlMock.Finish()
 
 	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
 	type args struct {
 		ctx      context.Context
 		walletID int
@@ -484,7 +465,8 @@ func TestWalletService_ExGetBalance(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.args)
 			s := &WalletService{
-				WalletRepo: mockRepo,
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
 			}
 			got, err := s.ExGetBalance(tt.args.ctx, tt.args.walletID)
 			if (err != nil) != tt.wantErr {
@@ -503,6 +485,7 @@ func TestWalletService_GetWalletHistory(t *testing.T) {
 	defer ctrlMock.Finish()
 
 	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
 
 	now := time.Now()
 	type args struct {
@@ -635,7 +618,8 @@ func TestWalletService_GetWalletHistory(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.args)
 			s := &WalletService{
-				WalletRepo: mockRepo,
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
 			}
 			got, err := s.GetWalletHistory(tt.args.ctx, tt.args.userID, tt.args.param)
 			if (err != nil) != tt.wantErr {
@@ -654,6 +638,7 @@ func TestWalletService_CreateWalletLink(t *testing.T) {
 	defer ctrlMock.Finish()
 
 	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
 
 	clientSource := "fastcampus_wallet"
 
@@ -681,6 +666,11 @@ func TestWalletService_CreateWalletLink(t *testing.T) {
 			wantErr: false,
 			mockFn: func(args args) {
 				mockRepo.EXPECT().InsertWalletLink(args.ctx, gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(args.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "wallet_link.create", auditLog.Action)
+					assert.NotContains(t, auditLog.After, args.req.OTP)
+					return nil
+				})
 			},
 		},
 		{
@@ -703,7 +693,8 @@ func TestWalletService_CreateWalletLink(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.args)
 			s := &WalletService{
-				WalletRepo: mockRepo,
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
 			}
 			got, err := s.CreateWalletLink(tt.args.ctx, tt.args.clientSource, tt.args.req)
 			if (err != nil) != tt.wantErr {
@@ -726,6 +717,7 @@ func TestWalletService_WalletLinkConfirmation(t *testing.T) {
 	defer ctrlMock.Finish()
 
 	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
 
 	clientSource := "fastcampus_wallet"
 	now := time.Now()
@@ -762,6 +754,12 @@ func TestWalletService_WalletLinkConfirmation(t *testing.T) {
 				}, nil)
 
 				mockRepo.EXPECT().UpdateStatusWalletLink(args.ctx, args.walletID, clientSource, "linked").Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(args.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "wallet_link.confirm", auditLog.Action)
+					assert.Equal(t, `{"status":"pending"}`, auditLog.Before)
+					assert.Equal(t, `{"status":"linked"}`, auditLog.After)
+					return nil
+				})
 			},
 		},
 		{
@@ -848,7 +846,8 @@ func TestWalletService_WalletLinkConfirmation(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.args)
 			s := &WalletService{
-				WalletRepo: mockRepo,
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
 			}
 			if err := s.WalletLinkConfirmation(tt.args.ctx, tt.args.walletID, tt.args.clientSource, tt.args.otp); (err != nil) != tt.wantErr {
 				t.Errorf("WalletService.WalletLinkConfirmation() error = %v, wantErr %v", err, tt.wantErr)
@@ -862,6 +861,7 @@ func TestWalletService_WalletUnlink(t *testing.T) {
 	defer ctrlMock.Finish()
 
 	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
 
 	clientSource := "fastcampus_wallet"
 	type args struct {
@@ -884,7 +884,13 @@ func TestWalletService_WalletUnlink(t *testing.T) {
 			},
 			wantErr: false,
 			mockFn: func(args args) {
+				mockRepo.EXPECT().GetWalletLink(args.ctx, args.walletID, clientSource).Return(models.WalletLink{ID: 1, Status: "linked"}, nil)
 				mockRepo.EXPECT().UpdateStatusWalletLink(args.ctx, args.walletID, clientSource, "unlinked").Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(args.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "wallet_link.unlink", auditLog.Action)
+					assert.Equal(t, `{"status":"linked"}`, auditLog.Before)
+					return nil
+				})
 			},
 		},
 		{
@@ -896,6 +902,7 @@ func TestWalletService_WalletUnlink(t *testing.T) {
 			},
 			wantErr: true,
 			mockFn: func(args args) {
+				mockRepo.EXPECT().GetWalletLink(args.ctx, args.walletID, clientSource).Return(models.WalletLink{}, gorm.ErrRecordNotFound)
 				mockRepo.EXPECT().UpdateStatusWalletLink(args.ctx, args.walletID, clientSource, "unlinked").Return(assert.AnError)
 			},
 		},
@@ -904,7 +911,8 @@ func TestWalletService_WalletUnlink(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.args)
 			s := &WalletService{
-				WalletRepo: mockRepo,
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
 			}
 			if err := s.WalletUnlink(tt.args.ctx, tt.args.walletID, tt.args.clientSource); (err != nil) != tt.wantErr {
 				t.Errorf("WalletService.WalletUnlink() error = %v, wantErr %v", err, tt.wantErr)
@@ -918,6 +926,7 @@ func TestWalletService_ExternalTransaction(t *testing.T) {
 	defer ctrlMock.Finish()
 
 	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
 
 	now := time.Now()
 	type args struct {
@@ -940,6 +949,7 @@ func TestWalletService_ExternalTransaction(t *testing.T) {
 					Reference:       "reference",
 					TransactionType: "DEBIT",
 					WalletID:        1,
+					ClientID:        "fastcampus_ecommerce",
 				},
 			},
 			want: models.BalanceResponse{
@@ -956,15 +966,18 @@ func TestWalletService_ExternalTransaction(t *testing.T) {
 					CreatedAt: now,
 					UpdatedAt: now,
 				}
-				mockRepo.EXPECT().UpdateBalanceByID(args.ctx, args.req.WalletID, (-args.req.Amount)).Return(wallet, nil)
-
 				walletTrx := &models.WalletTransaction{
 					WalletID:              wallet.ID,
 					Amount:                args.req.Amount,
 					Reference:             args.req.Reference,
 					WalletTransactionType: args.req.TransactionType,
+					ClientID:              args.req.ClientID,
 				}
-				mockRepo.EXPECT().CreateWalletTrx(args.ctx, walletTrx).Return(nil)
+				mockRepo.EXPECT().DebitWithFee(args.ctx, walletTrx, nil).Return(wallet, 0.0, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(args.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "balance."+strings.ToLower(args.req.TransactionType), auditLog.Action)
+					return nil
+				})
 
 			},
 		},
@@ -993,15 +1006,17 @@ func TestWalletService_ExternalTransaction(t *testing.T) {
 					CreatedAt: now,
 					UpdatedAt: now,
 				}
-				mockRepo.EXPECT().UpdateBalanceByID(args.ctx, args.req.WalletID, args.req.Amount).Return(wallet, nil)
-
 				walletTrx := &models.WalletTransaction{
-					WalletID:              wallet.ID,
 					Amount:                args.req.Amount,
 					Reference:             args.req.Reference,
 					WalletTransactionType: args.req.TransactionType,
+					ClientID:              args.req.ClientID,
 				}
-				mockRepo.EXPECT().CreateWalletTrx(args.ctx, walletTrx).Return(nil)
+				mockRepo.EXPECT().UpdateBalanceByID(args.ctx, args.req.WalletID, args.req.Amount, walletTrx).Return(wallet, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(args.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "balance."+strings.ToLower(args.req.TransactionType), auditLog.Action)
+					return nil
+				})
 
 			},
 		},
@@ -1047,7 +1062,7 @@ func TestWalletService_ExternalTransaction(t *testing.T) {
 			mockFn: func(args args) {
 				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{}, nil)
 
-				mockRepo.EXPECT().UpdateBalanceByID(args.ctx, args.req.WalletID, args.req.Amount).Return(models.Wallet{}, assert.AnError)
+				mockRepo.EXPECT().UpdateBalanceByID(args.ctx, args.req.WalletID, args.req.Amount, gomock.Any()).Return(models.Wallet{}, assert.AnError)
 
 			},
 		},
@@ -1067,22 +1082,13 @@ func TestWalletService_ExternalTransaction(t *testing.T) {
 			mockFn: func(args args) {
 				mockRepo.EXPECT().GetWalletTransactionByReference(args.ctx, args.req.Reference).Return(models.WalletTransaction{}, nil)
 
-				wallet := models.Wallet{
-					ID:        1,
-					UserID:    1,
-					Balance:   200000,
-					CreatedAt: now,
-					UpdatedAt: now,
-				}
-				mockRepo.EXPECT().UpdateBalanceByID(args.ctx, args.req.WalletID, (-args.req.Amount)).Return(wallet, nil)
-
 				walletTrx := &models.WalletTransaction{
-					WalletID:              wallet.ID,
+					WalletID:              args.req.WalletID,
 					Amount:                args.req.Amount,
 					Reference:             args.req.Reference,
 					WalletTransactionType: args.req.TransactionType,
 				}
-				mockRepo.EXPECT().CreateWalletTrx(args.ctx, walletTrx).Return(assert.AnError)
+				mockRepo.EXPECT().DebitWithFee(args.ctx, walletTrx, nil).Return(models.Wallet{}, 0.0, assert.AnError)
 
 			},
 		},
@@ -1091,7 +1097,8 @@ func TestWalletService_ExternalTransaction(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.args)
 			s := &WalletService{
-				WalletRepo: mockRepo,
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
 			}
 			got, err := s.ExternalTransaction(tt.args.ctx, tt.args.req)
 			if (err != nil) != tt.wantErr {
@@ -1104,3 +1111,306 @@ func TestWalletService_ExternalTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletService_ExternalTransactionWithFee(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockFeeRepo := NewMockIFeeRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	req := models.ExternalTransactionRequest{
+		Amount:          50000,
+		Reference:       "reference",
+		TransactionType: constants.TransactionTypeDebit,
+		WalletID:        1,
+		ClientID:        "fastcampus_ecommerce",
+	}
+	clientFee := models.FeeRule{ID: 3, Operation: constants.FeeOperationExternalDebit, ClientSource: "fastcampus_ecommerce", FeeType: constants.FeeTypePercentage, Percentage: 1}
+
+	tests := []struct {
+		name    string
+		want    models.BalanceResponse
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success debit and fee",
+			want: models.BalanceResponse{Balance: 149500, Fee: 500},
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletTransactionByReference(gomock.Any(), "reference").Return(models.WalletTransaction{}, gorm.ErrRecordNotFound)
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationExternalDebit).Return([]models.FeeRule{clientFee}, nil)
+				mockRepo.EXPECT().GetWalletByID(gomock.Any(), 1).Return(models.Wallet{ID: 1}, nil)
+				mockRepo.EXPECT().DebitWithFee(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, debit *models.WalletTransaction, fee *models.FeePosting) (models.Wallet, float64, error) {
+						assert.Equal(t, float64(50000), debit.Amount)
+						assert.Equal(t, models.WalletTransaction{WalletID: 1, Amount: 500, WalletTransactionType: constants.TransactionTypeFee, ClientID: "fastcampus_ecommerce"}, *fee.Fee)
+						assert.Equal(t, 99, fee.Revenue.WalletID)
+						return models.Wallet{ID: 1, Balance: 200000}, 0, nil
+					})
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.debit", auditLog.Action)
+						assert.JSONEq(t, `{"balance":150000}`, auditLog.After)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.fee", auditLog.Action)
+						assert.JSONEq(t, `{"balance":149500}`, auditLog.After)
+						return nil
+					}),
+				)
+			},
+		},
+		{
+			name:    "error balance does not cover the fee",
+			wantErr: constants.ErrInsufficientBalance,
+			mockFn: func() {
+				mockRepo.EXPECT().GetWalletTransactionByReference(gomock.Any(), "reference").Return(models.WalletTransaction{}, gorm.ErrRecordNotFound)
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationExternalDebit).Return([]models.FeeRule{clientFee}, nil)
+				mockRepo.EXPECT().GetWalletByID(gomock.Any(), 1).Return(models.Wallet{ID: 1}, nil)
+				mockRepo.EXPECT().DebitWithFee(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.Wallet{}, 0.0, constants.ErrInsufficientBalance)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &WalletService{
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+				FeeService:   &FeeService{FeeRepo: mockFeeRepo, WalletRepo: mockRepo, RevenueWalletID: 99},
+			}
+			got, err := s.ExternalTransaction(context.Background(), req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWalletService_ChangeWalletStatus(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+
+	type args struct {
+		ctx       context.Context
+		walletID  int
+		action    string
+		changedBy string
+		req       models.WalletStatusRequest
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    models.Wallet
+		wantErr bool
+		mockFn  func(args args)
+	}{
+		{
+			name: "success freeze",
+			args: args{
+				ctx:       context.Background(),
+				walletID:  1,
+				action:    "freeze",
+				changedBy: "operator",
+				req: models.WalletStatusRequest{
+					ReasonCode: "FRAUD_SUSPECTED",
+				},
+			},
+			want: models.Wallet{
+				ID:      1,
+				Balance: 100000,
+				Status:  "frozen",
+			},
+			wantErr: false,
+			mockFn: func(args args) {
+				mockRepo.EXPECT().UpdateWalletStatus(args.ctx, args.walletID, []string{"active"}, &models.WalletStatusHistory{
+					ToStatus:   "frozen",
+					ReasonCode: "FRAUD_SUSPECTED",
+					ChangedBy:  "operator",
+				}).Return(models.Wallet{
+					ID:      1,
+					Balance: 100000,
+					Status:  "frozen",
+				}, nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(args.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "wallet.freeze", auditLog.Action)
+					assert.Equal(t, `{"status":"frozen"}`, auditLog.After)
+					assert.Contains(t, auditLog.Detail, "FRAUD_SUSPECTED")
+					return nil
+				})
+			},
+		},
+		{
+			name: "success close",
+			args: args{
+				ctx:       context.Background(),
+				walletID:  1,
+				action:    "close",
+				changedBy: "operator",
+				req: models.WalletStatusRequest{
+					ReasonCode: "CUSTOMER_REQUEST",
+				},
+			},
+			want: models.Wallet{
+				ID:     1,
+				Status: "closed",
+			},
+			wantErr: false,
+			mockFn: func(args args) {
+				mockRepo.EXPECT().UpdateWalletStatus(args.ctx, args.walletID, []string{"active", "frozen", "suspended"}, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, walletID int, fromStatuses []string, history *models.WalletStatusHistory) (models.Wallet, error) {
+						assert.Equal(t, "closed", history.ToStatus)
+						assert.NotEmpty(t, history.PayoutRef)
+						history.FromStatus = "active"
+						history.PayoutAmount = 100000
+						return models.Wallet{ID: 1, Status: "closed"}, nil
+					})
+				mockAuditRepo.EXPECT().AppendAuditLog(args.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "wallet.close", auditLog.Action)
+					assert.Equal(t, `{"balance":100000,"status":"active"}`, auditLog.Before)
+					assert.Equal(t, `{"balance":0,"status":"closed"}`, auditLog.After)
+					return nil
+				})
+			},
+		},
+		{
+			name: "error unknown action",
+			args: args{
+				ctx:      context.Background(),
+				walletID: 1,
+				action:   "delete",
+				req: models.WalletStatusRequest{
+					ReasonCode: "FRAUD_SUSPECTED",
+				},
+			},
+			want:    models.Wallet{},
+			wantErr: true,
+			mockFn:  func(args args) {},
+		},
+		{
+			name: "error unknown reason code",
+			args: args{
+				ctx:      context.Background(),
+				walletID: 1,
+				action:   "freeze",
+				req: models.WalletStatusRequest{
+					ReasonCode: "BECAUSE",
+				},
+			},
+			want:    models.Wallet{},
+			wantErr: true,
+			mockFn:  func(args args) {},
+		},
+		{
+			name: "error",
+			args: args{
+				ctx:       context.Background(),
+				walletID:  1,
+				action:    "unfreeze",
+				changedBy: "operator",
+				req: models.WalletStatusRequest{
+					ReasonCode: "REVIEW_CLEARED",
+				},
+			},
+			want:    models.Wallet{},
+			wantErr: true,
+			mockFn: func(args args) {
+				mockRepo.EXPECT().UpdateWalletStatus(args.ctx, args.walletID, []string{"frozen"}, gomock.Any()).Return(models.Wallet{}, assert.AnError)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.args)
+			s := &WalletService{
+				WalletRepo:   mockRepo,
+				AuditService: &AuditService{AuditRepo: mockAuditRepo},
+			}
+			got, err := s.ChangeWalletStatus(tt.args.ctx, tt.args.walletID, tt.args.action, tt.args.changedBy, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WalletService.ChangeWalletStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WalletService.ChangeWalletStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_SyncEmail(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := NewMockIWalletRepo(ctrlMock)
+
+	tests := []struct {
+		name   string
+		data   models.TokenData
+		mockFn func()
+	}{
+		{
+			name: "success",
+			data: models.TokenData{UserID: 1, Email: "alice@example.com"},
+			mockFn: func() {
+				mockRepo.EXPECT().UpdateWalletEmail(gomock.Any(), uint64(1), "alice@example.com").Return(nil)
+			},
+		},
+		{
+			name: "error is only logged",
+			data: models.TokenData{UserID: 1, Email: "alice@example.com"},
+			mockFn: func() {
+				mockRepo.EXPECT().UpdateWalletEmail(gomock.Any(), uint64(1), "alice@example.com").Return(assert.AnError)
+			},
+		},
+		{
+			name:   "token without email",
+			data:   models.TokenData{UserID: 1},
+			mockFn: func() {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &WalletService{
+				WalletRepo: mockRepo,
+			}
+			s.SyncEmail(context.Background(), tt.data)
+		})
+	}
+
+	t.Run("success unchanged email is written once", func(t *testing.T) {
+		gomock.InOrder(
+			mockRepo.EXPECT().UpdateWalletEmail(gomock.Any(), uint64(1), "alice@example.com").Return(nil),
+			mockRepo.EXPECT().UpdateWalletEmail(gomock.Any(), uint64(1), "alice@example.org").Return(nil),
+		)
+		s := &WalletService{
+			WalletRepo: mockRepo,
+		}
+		s.SyncEmail(context.Background(), models.TokenData{UserID: 1, Email: "alice@example.com"})
+		s.SyncEmail(context.Background(), models.TokenData{UserID: 1, Email: "alice@example.com"})
+		s.SyncEmail(context.Background(), models.TokenData{UserID: 1, Email: "alice@example.org"})
+	})
+
+	t.Run("success failed write is tried again", func(t *testing.T) {
+		gomock.InOrder(
+			mockRepo.EXPECT().UpdateWalletEmail(gomock.Any(), uint64(1), "alice@example.com").Return(assert.AnError),
+			mockRepo.EXPECT().UpdateWalletEmail(gomock.Any(), uint64(1), "alice@example.com").Return(nil),
+		)
+		s := &WalletService{
+			WalletRepo: mockRepo,
+		}
+		s.SyncEmail(context.Background(), models.TokenData{UserID: 1, Email: "alice@example.com"})
+		s.SyncEmail(context.Background(), models.TokenData{UserID: 1, Email: "alice@example.com"})
+	})
+}
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/interfaces/i_external"
+	"ewallet-wallet/internal/interfaces/i_repository"
+	"ewallet-wallet/internal/models"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	withdrawalRetryBatchSize = 100
+	// a withdrawal still requested after this long was never accepted by the
+	// provider and is submitted again
+	withdrawalResubmitAfter = time.Minute
+)
+
+type WithdrawalService struct {
+	WithdrawalRepo i_repository.IWithdrawalRepo
+	WalletRepo     i_repository.IWalletRepo
+	WalletService  *WalletService
+	AuditService   *AuditService
+	Provider       i_external.PayoutProvider
+	FeeService     *FeeService
+	PINService     *PINService
+	RiskService    *RiskService
+}
+
+func (s *WithdrawalService) AddBankAccount(ctx context.Context, userID uint64, req models.BankAccountRequest) (models.BankAccount, error) {
+	var (
+		resp models.BankAccount
+	)
+
+	if constants.MappingBankCode[req.BankCode] == "" {
+		return resp, fmt.Errorf("%w: unknown bank %s", constants.ErrInvalidBankAccount, req.BankCode)
+	}
+
+	accountName, err := s.Provider.InquireAccount(ctx, req.BankCode, req.AccountNumber)
+	if err != nil {
+		return resp, errors.Wrap(err, "failed to inquire bank account")
+	}
+
+	resp = models.BankAccount{
+		UserID:        userID,
+		BankCode:      req.BankCode,
+		AccountNumber: req.AccountNumber,
+		AccountName:   accountName,
+	}
+	err = s.WithdrawalRepo.CreateBankAccount(ctx, &resp)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return resp, fmt.Errorf("%w: already saved", constants.ErrInvalidBankAccount)
+		}
+		return resp, errors.Wrap(err, "failed to create bank account")
+	}
+
+	err = s.AuditService.Record(ctx, "bank_account.create", constants.AuditTargetBankAccount, strconv.Itoa(resp.ID),
+		nil, resp, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+func (s *WithdrawalService) GetBankAccounts(ctx context.Context, userID uint64) ([]models.BankAccount, error) {
+	resp, err := s.WithdrawalRepo.GetBankAccounts(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get bank accounts")
+	}
+
+	return resp, nil
+}
+
+func (s *WithdrawalService) DeleteBankAccount(ctx context.Context, userID uint64, accountID int) error {
+	err := s.WithdrawalRepo.DeleteBankAccount(ctx, userID, accountID)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete bank account")
+	}
+
+	return s.AuditService.Record(ctx, "bank_account.delete", constants.AuditTargetBankAccount, strconv.Itoa(accountID),
+		nil, nil, nil)
+}
+
+// CreateWithdrawal screens the withdrawal, holds the amount and the fee on the
+// wallet and submits the payout. Repeating a request with the same reference
+// returns the existing withdrawal, and submits it again if the provider never
+// accepted it.
+func (s *WithdrawalService) CreateWithdrawal(ctx context.Context, userID uint64, req models.WithdrawalRequest) (models.Withdrawal, error) {
+	existing, err := s.WithdrawalRepo.GetWithdrawalByReference(ctx, userID, req.Reference)
+	if err == nil {
+		return s.replayWithdrawal(ctx, existing, req)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return existing, errors.Wrap(err, "failed to check reference")
+	}
+
+	account, err := s.WithdrawalRepo.GetBankAccountByID(ctx, req.BankAccountID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Withdrawal{}, errors.Wrap(err, "failed to get bank account")
+	}
+	if account.ID == 0 || account.UserID != userID {
+		return models.Withdrawal{}, constants.ErrBankAccountNotFound
+	}
+
+	err = s.PINService.Authorize(ctx, userID, req.Amount)
+	if err != nil {
+		return models.Withdrawal{}, err
+	}
+
+	wallet, err := s.WalletRepo.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return models.Withdrawal{}, errors.Wrap(err, "failed to get wallet")
+	}
+
+	err = s.RiskService.Screen(ctx, models.RiskCheck{
+		Operation:       constants.RiskOperationWithdrawal,
+		TransactionType: constants.TransactionTypeDebit,
+		UserID:          userID,
+		WalletID:        wallet.ID,
+		Amount:          req.Amount,
+		Reference:       withdrawalRiskReference(userID, req.Reference),
+		BankAccountID:   account.ID,
+	})
+	if err != nil {
+		return models.Withdrawal{}, err
+	}
+
+	return s.createWithdrawal(ctx, wallet, account, req)
+}
+
+// releaseWithdrawal places the withdrawal an operator let through after a
+// risk review.
+func (s *WithdrawalService) releaseWithdrawal(ctx context.Context, review models.RiskReview) (models.Withdrawal, error) {
+	account, err := s.WithdrawalRepo.GetBankAccountByID(ctx, review.BankAccountID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Withdrawal{}, errors.Wrap(err, "failed to get bank account")
+	}
+	if account.ID == 0 || account.UserID != review.UserID {
+		return models.Withdrawal{}, constants.ErrBankAccountNotFound
+	}
+
+	wallet, err := s.WalletRepo.GetWalletByID(ctx, review.WalletID)
+	if err != nil {
+		return models.Withdrawal{}, errors.Wrap(err, "failed to get wallet")
+	}
+
+	return s.createWithdrawal(ctx, wallet, account, models.WithdrawalRequest{
+		BankAccountID: account.ID,
+		Amount:        review.Amount,
+		Reference:     strings.TrimPrefix(review.Reference, withdrawalRiskReference(review.UserID, "")),
+	})
+}
+
+func (s *WithdrawalService) createWithdrawal(ctx context.Context, wallet models.Wallet, account models.BankAccount, req models.WithdrawalRequest) (models.Withdrawal, error) {
+	userID := account.UserID
+	quote, err := s.FeeService.Quote(ctx, constants.FeeOperationWithdrawal, "", wallet.ID, req.Amount)
+	if err != nil {
+		return models.Withdrawal{}, err
+	}
+
+	withdrawal := models.Withdrawal{
+		UserID:          userID,
+		WalletID:        wallet.ID,
+		BankAccountID:   account.ID,
+		BankCode:        account.BankCode,
+		AccountNumber:   account.AccountNumber,
+		AccountName:     account.AccountName,
+		Amount:          req.Amount,
+		Fee:             quote.Fee,
+		Status:          constants.WithdrawalStatusRequested,
+		Reference:       req.Reference,
+		PayoutReference: fmt.Sprintf("WDR-%d-%d", wallet.ID, time.Now().UnixNano()),
+		Provider:        s.Provider.Name(),
+	}
+
+	err = s.WithdrawalRepo.CreateWithdrawal(ctx, &withdrawal)
+	if err != nil {
+		// the same request raced us
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			existing, err := s.WithdrawalRepo.GetWithdrawalByReference(ctx, userID, req.Reference)
+			if err != nil {
+				return existing, errors.Wrap(err, "failed to get withdrawal")
+			}
+			return s.replayWithdrawal(ctx, existing, req)
+		}
+		return withdrawal, errors.Wrap(err, "failed to hold withdrawal")
+	}
+
+	s.recordWithdrawalChange(ctx, "withdrawal.request", withdrawal, "")
+
+	return s.submitWithdrawal(ctx, withdrawal)
+}
+
+func (s *WithdrawalService) GetWithdrawal(ctx context.Context, userID uint64, withdrawalID int) (models.Withdrawal, error) {
+	resp, err := s.WithdrawalRepo.GetWithdrawalByID(ctx, withdrawalID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return resp, constants.ErrWithdrawalNotFound
+		}
+		return resp, errors.Wrap(err, "failed to get withdrawal")
+	}
+
+	if resp.UserID != userID {
+		return models.Withdrawal{}, constants.ErrWithdrawalNotFound
+	}
+
+	return resp, nil
+}
+
+func (s *WithdrawalService) GetWithdrawals(ctx context.Context, userID uint64, param models.WithdrawalParam) ([]models.Withdrawal, error) {
+	offset, limit := adminPagination(param.Page, param.Limit)
+
+	resp, err := s.WithdrawalRepo.GetWithdrawals(ctx, userID, param.Status, offset, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get withdrawals")
+	}
+
+	return resp, nil
+}
+
+// HandleCallback finalizes or releases the hold of a withdrawal. Providers
+// retry until they get a success, so an outcome that was already applied
+// returns the withdrawal as is.
+func (s *WithdrawalService) HandleCallback(ctx context.Context, payload []byte, signature string) (models.Withdrawal, error) {
+	callback, err := s.Provider.VerifyCallback(ctx, payload, signature)
+	if err != nil {
+		return models.Withdrawal{}, err
+	}
+
+	withdrawal, err := s.WithdrawalRepo.GetWithdrawalByPayoutReference(ctx, callback.Reference)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return withdrawal, constants.ErrWithdrawalNotFound
+		}
+		return withdrawal, errors.Wrap(err, "failed to get withdrawal")
+	}
+
+	// the callback may beat the submit response, before that the provider
+	// reference is unknown
+	if withdrawal.ProviderReference != "" && callback.ProviderReference != withdrawal.ProviderReference {
+		return withdrawal, fmt.Errorf("%w: provider reference %s does not match", constants.ErrInvalidPayoutCallback, callback.ProviderReference)
+	}
+
+	if withdrawal.Status == callback.Status {
+		return withdrawal, nil
+	}
+
+	now := time.Now()
+	switch callback.Status {
+	case constants.WithdrawalStatusSucceeded:
+		return s.completeWithdrawal(ctx, withdrawal, now)
+	case constants.WithdrawalStatusFailed:
+		err = s.WithdrawalRepo.FailWithdrawal(ctx, withdrawal, callback.FailureReason, now)
+		if err != nil {
+			return s.checkOutcome(ctx, withdrawal, callback.Status, errors.Wrap(err, "failed to release withdrawal"))
+		}
+
+		fromStatus := withdrawal.Status
+		withdrawal.Status = constants.WithdrawalStatusFailed
+		withdrawal.FailureReason = callback.FailureReason
+		withdrawal.CompletedAt = &now
+
+		s.recordWithdrawalChange(ctx, "withdrawal.fail", withdrawal, fromStatus)
+
+		return withdrawal, nil
+	default:
+		return withdrawal, fmt.Errorf("%w: unknown status %s", constants.ErrInvalidPayoutCallback, callback.Status)
+	}
+}
+
+// SubmitPendingWithdrawals submits again the withdrawals the provider never
+// accepted and returns how many are now processing.
+func (s *WithdrawalService) SubmitPendingWithdrawals(ctx context.Context, now time.Time) (int, error) {
+	withdrawals, err := s.WithdrawalRepo.GetUnsubmittedWithdrawals(ctx, now.Add(-withdrawalResubmitAfter), withdrawalRetryBatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get unsubmitted withdrawals")
+	}
+
+	submitted := 0
+	for _, withdrawal := range withdrawals {
+		withdrawal, err := s.submitWithdrawal(ctx, withdrawal)
+		if err != nil {
+			return submitted, err
+		}
+
+		if withdrawal.Status != constants.WithdrawalStatusRequested {
+			submitted++
+		}
+	}
+
+	return submitted, nil
+}
+
+// withdrawalRiskReference keeps the reference of a withdrawal, only unique per
+// user, apart from the references of other users and other movements.
+func withdrawalRiskReference(userID uint64, reference string) string {
+	return fmt.Sprintf("WD-%d-%s", userID, reference)
+}
+
+// RunWorker submits pending withdrawals every interval until ctx is
+// cancelled.
+func (s *WithdrawalService) RunWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		submitted, err := s.SubmitPendingWithdrawals(ctx, time.Now())
+		if err != nil {
+			log.Println("failed to submit withdrawals: ", err)
+			continue
+		}
+
+		if submitted > 0 {
+			log.Printf("%d withdrawals submitted\n", submitted)
+		}
+	}
+}
+
+func (s *WithdrawalService) replayWithdrawal(ctx context.Context, withdrawal models.Withdrawal, req models.WithdrawalRequest) (models.Withdrawal, error) {
+	if withdrawal.BankAccountID != req.BankAccountID || withdrawal.Amount != req.Amount {
+		return withdrawal, fmt.Errorf("%w: %s was used for another withdrawal", constants.ErrDuplicateReference, req.Reference)
+	}
+
+	if withdrawal.Status == constants.WithdrawalStatusRequested {
+		return s.submitWithdrawal(ctx, withdrawal)
+	}
+
+	return withdrawal, nil
+}
+
+// submitWithdrawal hands the payout to the provider. When the provider does
+// not answer the withdrawal stays requested and is submitted again later.
+func (s *WithdrawalService) submitWithdrawal(ctx context.Context, withdrawal models.Withdrawal) (models.Withdrawal, error) {
+	payout, err := s.Provider.CreatePayout(ctx, models.PayoutRequest{
+		Reference:     withdrawal.PayoutReference,
+		BankCode:      withdrawal.BankCode,
+		AccountNumber: withdrawal.AccountNumber,
+		AccountName:   withdrawal.AccountName,
+		Amount:        withdrawal.Amount,
+	})
+	if err != nil {
+		log.Printf("failed to submit withdrawal %d: %v\n", withdrawal.ID, err)
+		return withdrawal, nil
+	}
+
+	now := time.Now()
+	err = s.WithdrawalRepo.MarkWithdrawalProcessing(ctx, withdrawal.ID, payout.ProviderReference, now)
+	if err != nil {
+		// a callback or another submit got there first
+		if errors.Is(err, constants.ErrWithdrawalNotInFlight) {
+			return s.GetWithdrawal(ctx, withdrawal.UserID, withdrawal.ID)
+		}
+		return withdrawal, errors.Wrap(err, "failed to mark withdrawal processing")
+	}
+
+	withdrawal.Status = constants.WithdrawalStatusProcessing
+	withdrawal.ProviderReference = payout.ProviderReference
+	withdrawal.SubmittedAt = &now
+
+	s.recordWithdrawalChange(ctx, "withdrawal.submit", withdrawal, constants.WithdrawalStatusRequested)
+
+	return withdrawal, nil
+}
+
+func (s *WithdrawalService) completeWithdrawal(ctx context.Context, withdrawal models.Withdrawal, now time.Time) (models.Withdrawal, error) {
+	debit := &models.WalletTransaction{
+		WalletID:              withdrawal.WalletID,
+		Amount:                withdrawal.Amount,
+		WalletTransactionType: constants.TransactionTypeDebit,
+		Reference:             withdrawal.PayoutReference,
+	}
+
+	// the fee was quoted when the hold was placed
+	fee := s.FeeService.Posting(withdrawal.WalletID, "", withdrawal.Fee)
+
+	wallet, err := s.WithdrawalRepo.CompleteWithdrawal(ctx, withdrawal, debit, fee, now)
+	if err != nil {
+		return s.checkOutcome(ctx, withdrawal, constants.WithdrawalStatusSucceeded, errors.Wrap(err, "failed to complete withdrawal"))
+	}
+
+	fromStatus := withdrawal.Status
+	withdrawal.Status = constants.WithdrawalStatusSucceeded
+	withdrawal.CompletedAt = &now
+
+	balance := wallet.Balance - withdrawal.Amount
+	s.WalletService.recordBalanceChange(ctx, "balance.debit", wallet.ID, wallet.Balance, balance, debit)
+
+	if fee != nil {
+		s.WalletService.recordBalanceChange(ctx, "balance.fee", wallet.ID, balance, balance-withdrawal.Fee, fee.Fee)
+	}
+
+	s.recordWithdrawalChange(ctx, "withdrawal.succeed", withdrawal, fromStatus)
+
+	return withdrawal, nil
+}
+
+// checkOutcome tells a replayed outcome, applied concurrently, from a real
+// failure to apply it.
+func (s *WithdrawalService) checkOutcome(ctx context.Context, withdrawal models.Withdrawal, status string, err error) (models.Withdrawal, error) {
+	if !errors.Is(err, constants.ErrWithdrawalNotInFlight) {
+		return withdrawal, err
+	}
+
+	current, getErr := s.GetWithdrawal(ctx, withdrawal.UserID, withdrawal.ID)
+	if getErr != nil {
+		return withdrawal, getErr
+	}
+
+	if current.Status == status {
+		return current, nil
+	}
+
+	return current, err
+}
+
+// recordWithdrawalChange audits a status change already committed, a failed
+// write is queued and never fails the change.
+func (s *WithdrawalService) recordWithdrawalChange(ctx context.Context, action string, withdrawal models.Withdrawal, fromStatus string) {
+	var before interface{}
+	if fromStatus != "" {
+		before = map[string]interface{}{"status": fromStatus}
+	}
+
+	detail := map[string]interface{}{
+		"wallet_id":        withdrawal.WalletID,
+		"amount":           withdrawal.Amount,
+		"fee":              withdrawal.Fee,
+		"reference":        withdrawal.Reference,
+		"payout_reference": withdrawal.PayoutReference,
+		"provider":         withdrawal.Provider,
+	}
+	if withdrawal.FailureReason != "" {
+		detail["failure_reason"] = withdrawal.FailureReason
+	}
+
+	s.AuditService.RecordCommitted(ctx, action, constants.AuditTargetWithdrawal, strconv.Itoa(withdrawal.ID),
+		before, map[string]interface{}{"status": withdrawal.Status}, detail)
+}
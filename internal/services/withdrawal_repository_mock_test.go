@@ -0,0 +1,227 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: i_withdrawal_repository.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	models "ewallet-wallet/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIWithdrawalRepo is a mock of IWithdrawalRepo interface.
+type MockIWithdrawalRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWithdrawalRepoMockRecorder
+}
+
+// MockIWithdrawalRepoMockRecorder is the mock recorder for MockIWithdrawalRepo.
+type MockIWithdrawalRepoMockRecorder struct {
+	mock *MockIWithdrawalRepo
+}
+
+// NewMockIWithdrawalRepo creates a new mock instance.
+func NewMockIWithdrawalRepo(ctrl *gomock.Controller) *MockIWithdrawalRepo {
+	mock := &MockIWithdrawalRepo{ctrl: ctrl}
+	mock.recorder = &MockIWithdrawalRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWithdrawalRepo) EXPECT() *MockIWithdrawalRepoMockRecorder {
+	return m.recorder
+}
+
+// CompleteWithdrawal mocks base method.
+func (m *MockIWithdrawalRepo) CompleteWithdrawal(ctx context.Context, withdrawal models.Withdrawal, debit *models.WalletTransaction, fee *models.FeePosting, now time.Time) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteWithdrawal", ctx, withdrawal, debit, fee, now)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteWithdrawal indicates an expected call of CompleteWithdrawal.
+func (mr *MockIWithdrawalRepoMockRecorder) CompleteWithdrawal(ctx, withdrawal, debit, fee, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteWithdrawal", reflect.TypeOf((*MockIWithdrawalRepo)(nil).CompleteWithdrawal), ctx, withdrawal, debit, fee, now)
+}
+
+// CreateBankAccount mocks base method.
+func (m *MockIWithdrawalRepo) CreateBankAccount(ctx context.Context, account *models.BankAccount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBankAccount", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBankAccount indicates an expected call of CreateBankAccount.
+func (mr *MockIWithdrawalRepoMockRecorder) CreateBankAccount(ctx, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBankAccount", reflect.TypeOf((*MockIWithdrawalRepo)(nil).CreateBankAccount), ctx, account)
+}
+
+// CreateWithdrawal mocks base method.
+func (m *MockIWithdrawalRepo) CreateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithdrawal", ctx, withdrawal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWithdrawal indicates an expected call of CreateWithdrawal.
+func (mr *MockIWithdrawalRepoMockRecorder) CreateWithdrawal(ctx, withdrawal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdrawal", reflect.TypeOf((*MockIWithdrawalRepo)(nil).CreateWithdrawal), ctx, withdrawal)
+}
+
+// DeleteBankAccount mocks base method.
+func (m *MockIWithdrawalRepo) DeleteBankAccount(ctx context.Context, userID uint64, accountID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBankAccount", ctx, userID, accountID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBankAccount indicates an expected call of DeleteBankAccount.
+func (mr *MockIWithdrawalRepoMockRecorder) DeleteBankAccount(ctx, userID, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBankAccount", reflect.TypeOf((*MockIWithdrawalRepo)(nil).DeleteBankAccount), ctx, userID, accountID)
+}
+
+// FailWithdrawal mocks base method.
+func (m *MockIWithdrawalRepo) FailWithdrawal(ctx context.Context, withdrawal models.Withdrawal, reason string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailWithdrawal", ctx, withdrawal, reason, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailWithdrawal indicates an expected call of FailWithdrawal.
+func (mr *MockIWithdrawalRepoMockRecorder) FailWithdrawal(ctx, withdrawal, reason, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailWithdrawal", reflect.TypeOf((*MockIWithdrawalRepo)(nil).FailWithdrawal), ctx, withdrawal, reason, now)
+}
+
+// GetBankAccountByID mocks base method.
+func (m *MockIWithdrawalRepo) GetBankAccountByID(ctx context.Context, accountID int) (models.BankAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBankAccountByID", ctx, accountID)
+	ret0, _ := ret[0].(models.BankAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBankAccountByID indicates an expected call of GetBankAccountByID.
+func (mr *MockIWithdrawalRepoMockRecorder) GetBankAccountByID(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankAccountByID", reflect.TypeOf((*MockIWithdrawalRepo)(nil).GetBankAccountByID), ctx, accountID)
+}
+
+// GetBankAccounts mocks base method.
+func (m *MockIWithdrawalRepo) GetBankAccounts(ctx context.Context, userID uint64) ([]models.BankAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBankAccounts", ctx, userID)
+	ret0, _ := ret[0].([]models.BankAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBankAccounts indicates an expected call of GetBankAccounts.
+func (mr *MockIWithdrawalRepoMockRecorder) GetBankAccounts(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankAccounts", reflect.TypeOf((*MockIWithdrawalRepo)(nil).GetBankAccounts), ctx, userID)
+}
+
+// GetUnsubmittedWithdrawals mocks base method.
+func (m *MockIWithdrawalRepo) GetUnsubmittedWithdrawals(ctx context.Context, before time.Time, limit int) ([]models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnsubmittedWithdrawals", ctx, before, limit)
+	ret0, _ := ret[0].([]models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnsubmittedWithdrawals indicates an expected call of GetUnsubmittedWithdrawals.
+func (mr *MockIWithdrawalRepoMockRecorder) GetUnsubmittedWithdrawals(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnsubmittedWithdrawals", reflect.TypeOf((*MockIWithdrawalRepo)(nil).GetUnsubmittedWithdrawals), ctx, before, limit)
+}
+
+// GetWithdrawalByID mocks base method.
+func (m *MockIWithdrawalRepo) GetWithdrawalByID(ctx context.Context, withdrawalID int) (models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalByID", ctx, withdrawalID)
+	ret0, _ := ret[0].(models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalByID indicates an expected call of GetWithdrawalByID.
+func (mr *MockIWithdrawalRepoMockRecorder) GetWithdrawalByID(ctx, withdrawalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalByID", reflect.TypeOf((*MockIWithdrawalRepo)(nil).GetWithdrawalByID), ctx, withdrawalID)
+}
+
+// GetWithdrawalByPayoutReference mocks base method.
+func (m *MockIWithdrawalRepo) GetWithdrawalByPayoutReference(ctx context.Context, payoutReference string) (models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalByPayoutReference", ctx, payoutReference)
+	ret0, _ := ret[0].(models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalByPayoutReference indicates an expected call of GetWithdrawalByPayoutReference.
+func (mr *MockIWithdrawalRepoMockRecorder) GetWithdrawalByPayoutReference(ctx, payoutReference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalByPayoutReference", reflect.TypeOf((*MockIWithdrawalRepo)(nil).GetWithdrawalByPayoutReference), ctx, payoutReference)
+}
+
+// GetWithdrawalByReference mocks base method.
+func (m *MockIWithdrawalRepo) GetWithdrawalByReference(ctx context.Context, userID uint64, reference string) (models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalByReference", ctx, userID, reference)
+	ret0, _ := ret[0].(models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalByReference indicates an expected call of GetWithdrawalByReference.
+func (mr *MockIWithdrawalRepoMockRecorder) GetWithdrawalByReference(ctx, userID, reference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalByReference", reflect.TypeOf((*MockIWithdrawalRepo)(nil).GetWithdrawalByReference), ctx, userID, reference)
+}
+
+// GetWithdrawals mocks base method.
+func (m *MockIWithdrawalRepo) GetWithdrawals(ctx context.Context, userID uint64, status string, offset, limit int) ([]models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawals", ctx, userID, status, offset, limit)
+	ret0, _ := ret[0].([]models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawals indicates an expected call of GetWithdrawals.
+func (mr *MockIWithdrawalRepoMockRecorder) GetWithdrawals(ctx, userID, status, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockIWithdrawalRepo)(nil).GetWithdrawals), ctx, userID, status, offset, limit)
+}
+
+// MarkWithdrawalProcessing mocks base method.
+func (m *MockIWithdrawalRepo) MarkWithdrawalProcessing(ctx context.Context, withdrawalID int, providerReference string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWithdrawalProcessing", ctx, withdrawalID, providerReference, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWithdrawalProcessing indicates an expected call of MarkWithdrawalProcessing.
+func (mr *MockIWithdrawalRepoMockRecorder) MarkWithdrawalProcessing(ctx, withdrawalID, providerReference, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWithdrawalProcessing", reflect.TypeOf((*MockIWithdrawalRepo)(nil).MarkWithdrawalProcessing), ctx, withdrawalID, providerReference, now)
+}
@@ -0,0 +1,511 @@
+package services
+
+import (
+	"context"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestWithdrawalService_AddBankAccount(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockWithdrawalRepo := NewMockIWithdrawalRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockProvider := NewMockPayoutProvider(ctrlMock)
+
+	tests := []struct {
+		name    string
+		req     models.BankAccountRequest
+		wantErr error
+		mockFn  func()
+	}{
+		{
+			name: "success",
+			req:  models.BankAccountRequest{BankCode: "BCA", AccountNumber: "1234567890"},
+			mockFn: func() {
+				mockProvider.EXPECT().InquireAccount(gomock.Any(), "BCA", "1234567890").Return("JOHN DOE", nil)
+				mockWithdrawalRepo.EXPECT().CreateBankAccount(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, account *models.BankAccount) error {
+					assert.Equal(t, models.BankAccount{UserID: 10, BankCode: "BCA", AccountNumber: "1234567890", AccountName: "JOHN DOE"}, *account)
+					account.ID = 3
+					return nil
+				})
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "bank_account.create", auditLog.Action)
+					assert.Equal(t, "3", auditLog.TargetID)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error unknown bank",
+			req:     models.BankAccountRequest{BankCode: "XYZ", AccountNumber: "1234567890"},
+			wantErr: constants.ErrInvalidBankAccount,
+			mockFn:  func() {},
+		},
+		{
+			name:    "error account not found at the bank",
+			req:     models.BankAccountRequest{BankCode: "BCA", AccountNumber: "1234560000"},
+			wantErr: constants.ErrInvalidBankAccount,
+			mockFn: func() {
+				mockProvider.EXPECT().InquireAccount(gomock.Any(), "BCA", "1234560000").Return("", constants.ErrInvalidBankAccount)
+			},
+		},
+		{
+			name:    "error already saved",
+			req:     models.BankAccountRequest{BankCode: "BCA", AccountNumber: "1234567890"},
+			wantErr: constants.ErrInvalidBankAccount,
+			mockFn: func() {
+				mockProvider.EXPECT().InquireAccount(gomock.Any(), "BCA", "1234567890").Return("JOHN DOE", nil)
+				mockWithdrawalRepo.EXPECT().CreateBankAccount(gomock.Any(), gomock.Any()).Return(gorm.ErrDuplicatedKey)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &WithdrawalService{
+				WithdrawalRepo: mockWithdrawalRepo,
+				AuditService:   &AuditService{AuditRepo: mockAuditRepo},
+				Provider:       mockProvider,
+			}
+			got, err := s.AddBankAccount(context.Background(), 10, tt.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 3, got.ID)
+			assert.Equal(t, "JOHN DOE", got.AccountName)
+		})
+	}
+}
+
+func TestWithdrawalService_CreateWithdrawal(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockWithdrawalRepo := NewMockIWithdrawalRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockProvider := NewMockPayoutProvider(ctrlMock)
+	mockFeeRepo := NewMockIFeeRepo(ctrlMock)
+
+	withdrawalFee := models.FeeRule{ID: 1, Operation: constants.FeeOperationWithdrawal, FeeType: constants.FeeTypeFlat, FlatAmount: 2500, Active: true}
+	req := models.WithdrawalRequest{BankAccountID: 3, Amount: 100000, Reference: "ref-1"}
+	account := models.BankAccount{ID: 3, UserID: 10, BankCode: "BCA", AccountNumber: "1234567890", AccountName: "JOHN DOE"}
+	requested := models.Withdrawal{
+		ID:              5,
+		UserID:          10,
+		WalletID:        1,
+		BankAccountID:   3,
+		Amount:          100000,
+		Fee:             2500,
+		Status:          constants.WithdrawalStatusRequested,
+		Reference:       "ref-1",
+		PayoutReference: "WDR-1-1",
+	}
+	processing := requested
+	processing.Status = constants.WithdrawalStatusProcessing
+	processing.ProviderReference = "SIM-WDR-1-1"
+
+	tests := []struct {
+		name       string
+		wantStatus string
+		wantErr    error
+		mockFn     func()
+	}{
+		{
+			name:       "success holds and submits",
+			wantStatus: constants.WithdrawalStatusProcessing,
+			mockFn: func() {
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByReference(gomock.Any(), uint64(10), "ref-1").Return(models.Withdrawal{}, gorm.ErrRecordNotFound)
+				mockWithdrawalRepo.EXPECT().GetBankAccountByID(gomock.Any(), 3).Return(account, nil)
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(10)).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationWithdrawal).Return([]models.FeeRule{withdrawalFee}, nil)
+				mockWalletRepo.EXPECT().GetWalletByID(gomock.Any(), 1).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+				mockProvider.EXPECT().Name().Return(constants.PayoutProviderSimulated)
+				mockWithdrawalRepo.EXPECT().CreateWithdrawal(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, withdrawal *models.Withdrawal) error {
+					assert.Regexp(t, `^WDR-1-\d+$`, withdrawal.PayoutReference)
+					assert.Equal(t, float64(2500), withdrawal.Fee)
+					assert.Equal(t, "JOHN DOE", withdrawal.AccountName)
+					assert.Equal(t, constants.WithdrawalStatusRequested, withdrawal.Status)
+					withdrawal.ID = 5
+					return nil
+				})
+				mockProvider.EXPECT().CreatePayout(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req models.PayoutRequest) (models.Payout, error) {
+					assert.Equal(t, float64(100000), req.Amount)
+					assert.Equal(t, "1234567890", req.AccountNumber)
+					return models.Payout{ProviderReference: "SIM-" + req.Reference}, nil
+				})
+				mockWithdrawalRepo.EXPECT().MarkWithdrawalProcessing(gomock.Any(), 5, gomock.Any(), gomock.Any()).Return(nil)
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "withdrawal.request", auditLog.Action)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "withdrawal.submit", auditLog.Action)
+						return nil
+					}),
+				)
+			},
+		},
+		{
+			name:       "success provider down keeps the hold",
+			wantStatus: constants.WithdrawalStatusRequested,
+			mockFn: func() {
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByReference(gomock.Any(), uint64(10), "ref-1").Return(models.Withdrawal{}, gorm.ErrRecordNotFound)
+				mockWithdrawalRepo.EXPECT().GetBankAccountByID(gomock.Any(), 3).Return(account, nil)
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(10)).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationWithdrawal).Return([]models.FeeRule{withdrawalFee}, nil)
+				mockWalletRepo.EXPECT().GetWalletByID(gomock.Any(), 1).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+				mockProvider.EXPECT().Name().Return(constants.PayoutProviderSimulated)
+				mockWithdrawalRepo.EXPECT().CreateWithdrawal(gomock.Any(), gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+				mockProvider.EXPECT().CreatePayout(gomock.Any(), gomock.Any()).Return(models.Payout{}, assert.AnError)
+			},
+		},
+		{
+			name:       "success replayed reference",
+			wantStatus: constants.WithdrawalStatusProcessing,
+			mockFn: func() {
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByReference(gomock.Any(), uint64(10), "ref-1").Return(processing, nil)
+			},
+		},
+		{
+			name:       "success replayed reference submits again",
+			wantStatus: constants.WithdrawalStatusProcessing,
+			mockFn: func() {
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByReference(gomock.Any(), uint64(10), "ref-1").Return(requested, nil)
+				mockProvider.EXPECT().CreatePayout(gomock.Any(), gomock.Any()).Return(models.Payout{ProviderReference: "SIM-WDR-1-1"}, nil)
+				mockWithdrawalRepo.EXPECT().MarkWithdrawalProcessing(gomock.Any(), 5, "SIM-WDR-1-1", gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "error reference used for another withdrawal",
+			wantErr: constants.ErrDuplicateReference,
+			mockFn: func() {
+				other := processing
+				other.Amount = 5000
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByReference(gomock.Any(), uint64(10), "ref-1").Return(other, nil)
+			},
+		},
+		{
+			name:    "error bank account of another user",
+			wantErr: constants.ErrBankAccountNotFound,
+			mockFn: func() {
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByReference(gomock.Any(), uint64(10), "ref-1").Return(models.Withdrawal{}, gorm.ErrRecordNotFound)
+				mockWithdrawalRepo.EXPECT().GetBankAccountByID(gomock.Any(), 3).Return(models.BankAccount{ID: 3, UserID: 11}, nil)
+			},
+		},
+		{
+			name:    "error insufficient available balance",
+			wantErr: constants.ErrInsufficientBalance,
+			mockFn: func() {
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByReference(gomock.Any(), uint64(10), "ref-1").Return(models.Withdrawal{}, gorm.ErrRecordNotFound)
+				mockWithdrawalRepo.EXPECT().GetBankAccountByID(gomock.Any(), 3).Return(account, nil)
+				mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(10)).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+				mockFeeRepo.EXPECT().GetActiveFeeRules(gomock.Any(), constants.FeeOperationWithdrawal).Return([]models.FeeRule{withdrawalFee}, nil)
+				mockWalletRepo.EXPECT().GetWalletByID(gomock.Any(), 1).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+				mockProvider.EXPECT().Name().Return(constants.PayoutProviderSimulated)
+				mockWithdrawalRepo.EXPECT().CreateWithdrawal(gomock.Any(), gomock.Any()).Return(constants.ErrInsufficientBalance)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			s := &WithdrawalService{
+				WithdrawalRepo: mockWithdrawalRepo,
+				WalletRepo:     mockWalletRepo,
+				AuditService:   &AuditService{AuditRepo: mockAuditRepo},
+				Provider:       mockProvider,
+				FeeService:     &FeeService{FeeRepo: mockFeeRepo, WalletRepo: mockWalletRepo},
+			}
+			got, err := s.CreateWithdrawal(context.Background(), 10, req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.Status)
+		})
+	}
+}
+
+func TestWithdrawalService_CreateWithdrawalScreened(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockWithdrawalRepo := NewMockIWithdrawalRepo(ctrlMock)
+	mockWalletRepo := NewMockIWalletRepo(ctrlMock)
+	mockRiskRepo := NewMockIRiskRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockProvider := NewMockPayoutProvider(ctrlMock)
+
+	req := models.WithdrawalRequest{BankAccountID: 3, Amount: 100000, Reference: "ref-1"}
+	account := models.BankAccount{ID: 3, UserID: 10, BankCode: "BCA", AccountNumber: "1234567890", AccountName: "JOHN DOE"}
+
+	var actions []string
+	mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+		actions = append(actions, auditLog.Action)
+		return nil
+	}).AnyTimes()
+
+	auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+	riskSvc := &RiskService{
+		RiskRepo:     mockRiskRepo,
+		WalletRepo:   mockWalletRepo,
+		AuditService: auditSvc,
+		Rules: []models.RiskRule{
+			{Name: "burst", Type: constants.RiskRuleDebitVelocity, Outcome: constants.RiskOutcomeReview, Operations: []string{constants.RiskOperationWithdrawal},
+				Window: "10m", MaxCount: 3, WindowDuration: 10 * time.Minute},
+		},
+	}
+	s := &WithdrawalService{
+		WithdrawalRepo: mockWithdrawalRepo,
+		WalletRepo:     mockWalletRepo,
+		AuditService:   auditSvc,
+		Provider:       mockProvider,
+		RiskService:    riskSvc,
+	}
+	riskSvc.WithdrawalService = s
+
+	// the withdrawal is held before anything is placed on the wallet
+	var review models.RiskReview
+	mockWithdrawalRepo.EXPECT().GetWithdrawalByReference(gomock.Any(), uint64(10), "ref-1").Return(models.Withdrawal{}, gorm.ErrRecordNotFound)
+	mockWithdrawalRepo.EXPECT().GetBankAccountByID(gomock.Any(), 3).Return(account, nil)
+	mockWalletRepo.EXPECT().GetWalletByUserID(gomock.Any(), uint64(10)).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+	mockRiskRepo.EXPECT().CountDebitsSince(gomock.Any(), 1, gomock.Any()).Return(3, nil)
+	mockRiskRepo.EXPECT().RecordRiskDecision(gomock.Any(), gomock.Any(), gomock.Not(gomock.Nil())).
+		DoAndReturn(func(ctx context.Context, decision *models.RiskDecision, held *models.RiskReview) error {
+			assert.Equal(t, constants.RiskOperationWithdrawal, held.Operation)
+			assert.Equal(t, "WD-10-ref-1", held.Reference)
+			assert.Equal(t, 3, held.BankAccountID)
+			held.ID = 9
+			review = *held
+			return nil
+		})
+
+	_, err := s.CreateWithdrawal(context.Background(), 10, req)
+	assert.ErrorIs(t, err, constants.ErrTransactionUnderReview)
+	assert.Equal(t, []string{"risk_review.create"}, actions)
+
+	// the approval places and submits it without screening it again
+	actions = nil
+	mockRiskRepo.EXPECT().GetRiskReviewByID(gomock.Any(), 9).Return(review, nil)
+	mockRiskRepo.EXPECT().DecideRiskReview(gomock.Any(), 9, constants.RiskReviewStatusApproved, "alice", "", gomock.Any()).Return(nil)
+	mockWithdrawalRepo.EXPECT().GetBankAccountByID(gomock.Any(), 3).Return(account, nil)
+	mockWalletRepo.EXPECT().GetWalletByID(gomock.Any(), 1).Return(models.Wallet{ID: 1, UserID: 10}, nil)
+	mockProvider.EXPECT().Name().Return(constants.PayoutProviderSimulated)
+	mockWithdrawalRepo.EXPECT().CreateWithdrawal(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, withdrawal *models.Withdrawal) error {
+		assert.Equal(t, uint64(10), withdrawal.UserID)
+		assert.Equal(t, "ref-1", withdrawal.Reference)
+		assert.Equal(t, float64(100000), withdrawal.Amount)
+		withdrawal.ID = 5
+		return nil
+	})
+	mockProvider.EXPECT().CreatePayout(gomock.Any(), gomock.Any()).Return(models.Payout{ProviderReference: "SIM-WDR-1-1"}, nil)
+	mockWithdrawalRepo.EXPECT().MarkWithdrawalProcessing(gomock.Any(), 5, "SIM-WDR-1-1", gomock.Any()).Return(nil)
+
+	got, err := riskSvc.ApproveRiskReview(context.Background(), "alice", 9, models.RiskReviewDecisionRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, constants.RiskReviewStatusApproved, got.Status)
+	assert.Equal(t, []string{"risk_review.approve", "withdrawal.request", "withdrawal.submit"}, actions)
+}
+
+func TestWithdrawalService_HandleCallback(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockWithdrawalRepo := NewMockIWithdrawalRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockProvider := NewMockPayoutProvider(ctrlMock)
+
+	payload := []byte(`{"reference":"WDR-1-1"}`)
+	processing := models.Withdrawal{
+		ID:                5,
+		UserID:            10,
+		WalletID:          1,
+		Amount:            100000,
+		Fee:               2500,
+		Status:            constants.WithdrawalStatusProcessing,
+		Reference:         "ref-1",
+		PayoutReference:   "WDR-1-1",
+		ProviderReference: "SIM-WDR-1-1",
+	}
+	succeeded := processing
+	succeeded.Status = constants.WithdrawalStatusSucceeded
+	succeededCallback := models.PayoutCallback{Reference: "WDR-1-1", ProviderReference: "SIM-WDR-1-1", Status: constants.WithdrawalStatusSucceeded}
+	failedCallback := models.PayoutCallback{Reference: "WDR-1-1", ProviderReference: "SIM-WDR-1-1", Status: constants.WithdrawalStatusFailed, FailureReason: "account closed"}
+
+	tests := []struct {
+		name       string
+		wantStatus string
+		wantErr    error
+		mockFn     func()
+	}{
+		{
+			name:       "success debits the amount and the fee",
+			wantStatus: constants.WithdrawalStatusSucceeded,
+			mockFn: func() {
+				mockProvider.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(succeededCallback, nil)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByPayoutReference(gomock.Any(), "WDR-1-1").Return(processing, nil)
+				mockWithdrawalRepo.EXPECT().CompleteWithdrawal(gomock.Any(), processing, gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, withdrawal models.Withdrawal, debit *models.WalletTransaction, fee *models.FeePosting, now time.Time) (models.Wallet, error) {
+						assert.Equal(t, models.WalletTransaction{WalletID: 1, Amount: 100000, WalletTransactionType: constants.TransactionTypeDebit, Reference: "WDR-1-1"}, *debit)
+						assert.Equal(t, models.WalletTransaction{WalletID: 1, Amount: 2500, WalletTransactionType: constants.TransactionTypeFee}, *fee.Fee)
+						assert.Equal(t, models.WalletTransaction{WalletID: 99, Amount: 2500, WalletTransactionType: constants.TransactionTypeCredit}, *fee.Revenue)
+						return models.Wallet{ID: 1, Balance: 200000, HeldBalance: 102500}, nil
+					})
+				gomock.InOrder(
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.debit", auditLog.Action)
+						assert.JSONEq(t, `{"balance":100000}`, auditLog.After)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "balance.fee", auditLog.Action)
+						assert.JSONEq(t, `{"balance":97500}`, auditLog.After)
+						return nil
+					}),
+					mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+						assert.Equal(t, "withdrawal.succeed", auditLog.Action)
+						return nil
+					}),
+				)
+			},
+		},
+		{
+			name:       "success replayed callback is not debited again",
+			wantStatus: constants.WithdrawalStatusSucceeded,
+			mockFn: func() {
+				mockProvider.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(succeededCallback, nil)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByPayoutReference(gomock.Any(), "WDR-1-1").Return(succeeded, nil)
+			},
+		},
+		{
+			name:       "success concurrent callback already debited",
+			wantStatus: constants.WithdrawalStatusSucceeded,
+			mockFn: func() {
+				mockProvider.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(succeededCallback, nil)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByPayoutReference(gomock.Any(), "WDR-1-1").Return(processing, nil)
+				mockWithdrawalRepo.EXPECT().CompleteWithdrawal(gomock.Any(), processing, gomock.Any(), gomock.Any(), gomock.Any()).Return(models.Wallet{}, constants.ErrWithdrawalNotInFlight)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByID(gomock.Any(), 5).Return(succeeded, nil)
+			},
+		},
+		{
+			name:       "success failed releases the hold",
+			wantStatus: constants.WithdrawalStatusFailed,
+			mockFn: func() {
+				mockProvider.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(failedCallback, nil)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByPayoutReference(gomock.Any(), "WDR-1-1").Return(processing, nil)
+				mockWithdrawalRepo.EXPECT().FailWithdrawal(gomock.Any(), processing, "account closed", gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+					assert.Equal(t, "withdrawal.fail", auditLog.Action)
+					return nil
+				})
+			},
+		},
+		{
+			name:    "error invalid signature",
+			wantErr: constants.ErrInvalidPayoutSignature,
+			mockFn: func() {
+				mockProvider.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(models.PayoutCallback{}, constants.ErrInvalidPayoutSignature)
+			},
+		},
+		{
+			name:    "error unknown reference",
+			wantErr: constants.ErrWithdrawalNotFound,
+			mockFn: func() {
+				mockProvider.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(succeededCallback, nil)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByPayoutReference(gomock.Any(), "WDR-1-1").Return(models.Withdrawal{}, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:    "error provider reference mismatch",
+			wantErr: constants.ErrInvalidPayoutCallback,
+			mockFn: func() {
+				mockProvider.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").
+					Return(models.PayoutCallback{Reference: "WDR-1-1", ProviderReference: "SIM-OTHER", Status: constants.WithdrawalStatusSucceeded}, nil)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByPayoutReference(gomock.Any(), "WDR-1-1").Return(processing, nil)
+			},
+		},
+		{
+			name:    "error fail after success",
+			wantErr: constants.ErrWithdrawalNotInFlight,
+			mockFn: func() {
+				mockProvider.EXPECT().VerifyCallback(gomock.Any(), payload, "signature").Return(failedCallback, nil)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByPayoutReference(gomock.Any(), "WDR-1-1").Return(succeeded, nil)
+				mockWithdrawalRepo.EXPECT().FailWithdrawal(gomock.Any(), succeeded, "account closed", gomock.Any()).Return(constants.ErrWithdrawalNotInFlight)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalByID(gomock.Any(), 5).Return(succeeded, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+			auditSvc := &AuditService{AuditRepo: mockAuditRepo}
+			s := &WithdrawalService{
+				WithdrawalRepo: mockWithdrawalRepo,
+				WalletService:  &WalletService{AuditService: auditSvc},
+				AuditService:   auditSvc,
+				Provider:       mockProvider,
+				FeeService:     &FeeService{RevenueWalletID: 99},
+			}
+			got, err := s.HandleCallback(context.Background(), payload, "signature")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.Status)
+		})
+	}
+}
+
+func TestWithdrawalService_SubmitPendingWithdrawals(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockWithdrawalRepo := NewMockIWithdrawalRepo(ctrlMock)
+	mockAuditRepo := NewMockIAuditRepo(ctrlMock)
+	mockProvider := NewMockPayoutProvider(ctrlMock)
+
+	now := time.Now()
+
+	mockWithdrawalRepo.EXPECT().GetUnsubmittedWithdrawals(gomock.Any(), now.Add(-time.Minute), 100).Return([]models.Withdrawal{
+		{ID: 5, UserID: 10, Status: constants.WithdrawalStatusRequested, PayoutReference: "WDR-1-1"},
+		{ID: 6, UserID: 10, Status: constants.WithdrawalStatusRequested, PayoutReference: "WDR-1-2"},
+	}, nil)
+	gomock.InOrder(
+		mockProvider.EXPECT().CreatePayout(gomock.Any(), gomock.Any()).Return(models.Payout{ProviderReference: "SIM-WDR-1-1"}, nil),
+		mockProvider.EXPECT().CreatePayout(gomock.Any(), gomock.Any()).Return(models.Payout{}, assert.AnError),
+	)
+	mockWithdrawalRepo.EXPECT().MarkWithdrawalProcessing(gomock.Any(), 5, "SIM-WDR-1-1", gomock.Any()).Return(nil)
+	mockAuditRepo.EXPECT().AppendAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog *models.AuditLog) error {
+		assert.Equal(t, "withdrawal.submit", auditLog.Action)
+		assert.Equal(t, "5", auditLog.TargetID)
+		return nil
+	})
+
+	s := &WithdrawalService{
+		WithdrawalRepo: mockWithdrawalRepo,
+		AuditService:   &AuditService{AuditRepo: mockAuditRepo},
+		Provider:       mockProvider,
+	}
+	got, err := s.SubmitPendingWithdrawals(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, got)
+}
@@ -0,0 +1,215 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+type server struct {
+	name     string
+	serve    func() error
+	shutdown func(ctx context.Context) error
+}
+
+type worker struct {
+	name string
+	fn   func(ctx context.Context)
+}
+
+type closer struct {
+	name string
+	fn   func() error
+}
+
+// Manager runs the servers and background workers of the process until a
+// signal arrives, then stops them in order: the servers stop accepting and
+// drain their in flight requests, the workers are cancelled and waited for,
+// and last the closers release the shared resources such as the DB pool.
+// Draining and waiting share one ShutdownTimeout.
+type Manager struct {
+	ShutdownTimeout time.Duration
+	Signals         []os.Signal
+
+	servers []server
+	workers []worker
+	closers []closer
+}
+
+func New(shutdownTimeout time.Duration) *Manager {
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+
+	return &Manager{
+		ShutdownTimeout: shutdownTimeout,
+		Signals:         []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+	}
+}
+
+// AddHTTP serves srv on lis.
+func (m *Manager) AddHTTP(name string, srv *http.Server, lis net.Listener) {
+	m.servers = append(m.servers, server{
+		name: name,
+		serve: func() error {
+			err := srv.Serve(lis)
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return err
+		},
+		shutdown: func(ctx context.Context) error {
+			err := srv.Shutdown(ctx)
+			if err != nil {
+				// the requests still running are cut off
+				srv.Close()
+			}
+			return err
+		},
+	})
+}
+
+// AddGRPC serves srv on lis.
+func (m *Manager) AddGRPC(name string, srv *grpc.Server, lis net.Listener) {
+	m.servers = append(m.servers, server{
+		name: name,
+		serve: func() error {
+			return srv.Serve(lis)
+		},
+		shutdown: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+// Go runs a background worker, ctx is cancelled once the servers are
+// drained and the worker should return soon after.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.workers = append(m.workers, worker{name: name, fn: fn})
+}
+
+// OnStop adds a closer, they run after the workers in the reverse order of
+// adding, so a resource added first is released last.
+func (m *Manager) OnStop(name string, fn func() error) {
+	m.closers = append(m.closers, closer{name: name, fn: fn})
+}
+
+// Run starts everything and blocks until ctx is done, a signal arrives or a
+// server fails, then shuts down. The error reports the failed server and
+// every step that did not finish in time.
+func (m *Manager) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, m.Signals...)
+	defer stop()
+
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+
+	var (
+		workers sync.WaitGroup
+		mu      sync.Mutex
+		running = make([]bool, len(m.workers))
+	)
+	for i, w := range m.workers {
+		running[i] = true
+		workers.Add(1)
+		go func(i int, w worker) {
+			defer workers.Done()
+			w.fn(workerCtx)
+
+			mu.Lock()
+			running[i] = false
+			mu.Unlock()
+		}(i, w)
+	}
+
+	serveErrs := make(chan error, len(m.servers))
+	for _, srv := range m.servers {
+		go func(srv server) {
+			log.Printf("%s server started\n", srv.name)
+			if err := srv.serve(); err != nil {
+				serveErrs <- fmt.Errorf("%s server failed: %w", srv.name, err)
+			}
+		}(srv)
+	}
+
+	var errs []error
+	select {
+	case <-ctx.Done():
+		log.Println("shutting down")
+	case err := <-serveErrs:
+		log.Println("shutting down: ", err)
+		errs = append(errs, err)
+	}
+	// a second signal kills the process the usual way
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), m.ShutdownTimeout)
+	defer cancel()
+
+	var drained sync.WaitGroup
+	for _, srv := range m.servers {
+		drained.Add(1)
+		go func(srv server) {
+			defer drained.Done()
+			if err := srv.shutdown(shutdownCtx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s server did not drain in time: %w", srv.name, err))
+				mu.Unlock()
+			}
+		}(srv)
+	}
+	drained.Wait()
+
+	cancelWorkers()
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+		mu.Lock()
+		var names []string
+		for i, w := range m.workers {
+			if running[i] {
+				names = append(names, w.name)
+			}
+		}
+		mu.Unlock()
+		errs = append(errs, fmt.Errorf("workers did not stop in time: %s", strings.Join(names, ", ")))
+	}
+
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		if err := m.closers[i].fn(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", m.closers[i].name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,230 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, event)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string{}, e.list...)
+}
+
+func listen(t *testing.T) net.Listener {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	return lis
+}
+
+func run(m *Manager) chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Run(context.Background())
+	}()
+	return done
+}
+
+func sendSignal(t *testing.T, sig syscall.Signal) {
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), sig))
+}
+
+func get(url string) chan error {
+	done := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			done <- err
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err == nil && string(body) != "done" {
+			err = errors.New("unexpected body " + string(body))
+		}
+		done <- err
+	}()
+	return done
+}
+
+func TestManager_DrainsInFlightRequest(t *testing.T) {
+	var (
+		got     events
+		entered = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	m := New(5 * time.Second)
+	lis := listen(t)
+	m.AddHTTP("http", &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		got.add("request finished")
+		w.Write([]byte("done"))
+	})}, lis)
+	m.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		got.add("worker stopped")
+	})
+	m.OnStop("database", func() error {
+		got.add("database closed")
+		return nil
+	})
+	m.OnStop("ums client", func() error {
+		got.add("ums client closed")
+		return nil
+	})
+
+	stopped := run(m)
+	request := get("http://" + lis.Addr().String())
+	<-entered
+
+	sendSignal(t, syscall.SIGTERM)
+
+	// no new connection is accepted while the request drains
+	assert.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", lis.Addr().String())
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("stopped before the request finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Empty(t, got.get())
+
+	close(release)
+	assert.NoError(t, <-request)
+	assert.NoError(t, <-stopped)
+	assert.Equal(t, []string{"request finished", "worker stopped", "ums client closed", "database closed"}, got.get())
+}
+
+func TestManager_DrainTimeout(t *testing.T) {
+	var (
+		got     events
+		entered = make(chan struct{})
+		release = make(chan struct{})
+	)
+	defer close(release)
+
+	m := New(100 * time.Millisecond)
+	lis := listen(t)
+	m.AddHTTP("http", &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+	})}, lis)
+	m.OnStop("database", func() error {
+		got.add("database closed")
+		return nil
+	})
+
+	stopped := run(m)
+	request := get("http://" + lis.Addr().String())
+	<-entered
+
+	sendSignal(t, syscall.SIGINT)
+
+	err := <-stopped
+	assert.ErrorContains(t, err, "http server did not drain in time")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	// the request still running is cut off
+	assert.Error(t, <-request)
+	assert.Equal(t, []string{"database closed"}, got.get())
+}
+
+func TestManager_WorkerTimeout(t *testing.T) {
+	var got events
+	release := make(chan struct{})
+	defer close(release)
+
+	m := New(100 * time.Millisecond)
+	m.Go("sweeper", func(ctx context.Context) {
+		<-ctx.Done()
+	})
+	m.Go("stuck worker", func(ctx context.Context) {
+		<-release
+	})
+	m.OnStop("database", func() error {
+		got.add("database closed")
+		return errors.New("pool busy")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := m.Run(ctx)
+	assert.EqualError(t, err, "workers did not stop in time: stuck worker\nfailed to close database: pool busy")
+	assert.Equal(t, []string{"database closed"}, got.get())
+}
+
+func TestManager_ServerFails(t *testing.T) {
+	var got events
+
+	m := New(time.Second)
+	lis := listen(t)
+	lis.Close()
+	m.AddHTTP("http", &http.Server{}, lis)
+	m.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		got.add("worker stopped")
+	})
+
+	err := m.Run(context.Background())
+	assert.ErrorContains(t, err, "http server failed")
+	assert.Equal(t, []string{"worker stopped"}, got.get())
+}
+
+func TestManager_GRPC(t *testing.T) {
+	m := New(time.Second)
+	lis := listen(t)
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	m.AddGRPC("grpc", srv, lis)
+
+	stopped := run(m)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	sendSignal(t, syscall.SIGTERM)
+	assert.NoError(t, <-stopped)
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Error(t, err)
+}
@@ -2,23 +2,47 @@ package main
 
 import (
 	"ewallet-wallet/cmd"
+	"ewallet-wallet/config"
 	"ewallet-wallet/helpers"
+	"log"
+	"os"
 )
 
 func main() {
 	// load config
-	helpers.SetupConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// load log
 	helpers.SetupLogger()
+	log.Printf("loaded config:\n%s", cfg)
 
 	// load db
-	helpers.SetupMySQL()
+	helpers.SetupDatabase(cfg.DB)
 
-	// run grpc
-	// go cmd.ServeGRPC()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			cmd.RunMigrate(cfg.DB, os.Args[2:])
+		case "audit-verify":
+			cmd.RunAuditVerify()
+		case "reconcile":
+			cmd.RunReconciliation(cfg.Reconciliation, os.Args[2:])
+		case "settlement":
+			cmd.RunSettlement(cfg.Settlement, os.Args[2:])
+		case "topup-callback":
+			cmd.RunTopUpCallback(cfg, os.Args[2:])
+		case "payout-callback":
+			cmd.RunPayoutCallback(cfg, os.Args[2:])
+		default:
+			log.Fatalf("unknown command %s", os.Args[1])
+		}
+		return
+	}
 
-	// run http
-	cmd.ServeHttp()
+	// run http, and grpc when GRPC_ENABLED, until SIGTERM or SIGINT
+	cmd.ServeHttp(cfg)
 
 }
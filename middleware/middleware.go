@@ -4,15 +4,20 @@ import (
 	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
+	"errors"
 	"ewallet-wallet/constants"
 	"ewallet-wallet/helpers"
 	"ewallet-wallet/internal/handler/wallet"
+	"ewallet-wallet/internal/models"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +26,48 @@ import (
 
 type ExternalDependency struct {
 	External wallet.External
+	// DefaultScopes are granted to user tokens without a scope claim
+	DefaultScopes []string
+	OperatorJWT   helpers.JWTConfig
+	// InternalAPIKey empty keeps every caller out of the internal routes
+	InternalAPIKey string
+	// LegacyBalanceSignature accepts the old GET signature on
+	// constants.LegacySignatureRoute
+	LegacyBalanceSignature bool
+}
+
+// MiddlewareRequestMeta tags every request with a request id and the caller
+// ip so that audit entries written further down can be traced back to it.
+func (d *ExternalDependency) MiddlewareRequestMeta(c *gin.Context) {
+	requestID := c.Request.Header.Get("X-Request-ID")
+	if requestID == "" || len(requestID) > 64 {
+		requestID = helpers.GenerateRequestID()
+	}
+	c.Header("X-Request-ID", requestID)
+
+	meta := helpers.RequestMeta{
+		RequestID: requestID,
+		IP:        c.ClientIP(),
+	}
+	ctx := helpers.WithRequestMeta(c.Request.Context(), meta)
+
+	// the PIN or step-up token is checked by the services debiting above the
+	// PIN threshold, requests below it do not need one
+	ctx = helpers.WithTransactionAuth(ctx, helpers.TransactionAuth{
+		PIN:         c.Request.Header.Get(constants.HeaderWalletPIN),
+		StepUpToken: c.Request.Header.Get(constants.HeaderStepUpToken),
+	})
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+func setRequestActor(c *gin.Context, actorType string, actor string, actorRole string) {
+	meta := helpers.GetRequestMeta(c.Request.Context())
+	meta.ActorType = actorType
+	meta.Actor = actor
+	meta.ActorRole = actorRole
+	c.Request = c.Request.WithContext(helpers.WithRequestMeta(c.Request.Context(), meta))
 }
 
 func (d *ExternalDependency) MiddlewareValidateToken(c *gin.Context) {
@@ -36,16 +83,62 @@ func (d *ExternalDependency) MiddlewareValidateToken(c *gin.Context) {
 	tokenData, err := d.External.ValidateToken(c.Request.Context(), auth)
 	if err != nil {
 		fmt.Printf("%v", err)
+		if errors.Is(err, constants.ErrUMSUnavailable) {
+			helpers.SendResponseHTTP(c, http.StatusServiceUnavailable, constants.ErrUMSUnavailable.Error(), nil)
+			c.Abort()
+			return
+		}
+		if errors.Is(err, constants.ErrKeySetUnavailable) {
+			helpers.SendResponseHTTP(c, http.StatusServiceUnavailable, constants.ErrKeySetUnavailable.Error(), nil)
+			c.Abort()
+			return
+		}
 		helpers.SendResponseHTTP(c, http.StatusUnauthorized, "unauthorized", nil)
 		c.Abort()
+		return
+	}
 
+	if len(tokenData.Scopes) == 0 {
+		tokenData.Scopes = d.DefaultScopes
 	}
 
 	c.Set("token", tokenData)
+	setRequestActor(c, constants.AuditActorUser, strconv.FormatUint(tokenData.UserID, 10), "")
 
 	c.Next()
 }
 
+// MiddlewareRequireScope runs after MiddlewareValidateToken and lets through
+// the user tokens granted the scope, or the admin scope.
+func (d *ExternalDependency) MiddlewareRequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := c.Get("token")
+		if !ok {
+			log.Println("token data empty")
+			helpers.SendResponseHTTP(c, http.StatusUnauthorized, "unauthorized", nil)
+			c.Abort()
+			return
+		}
+
+		tokenData, ok := token.(models.TokenData)
+		if !ok || tokenData.UserID == 0 {
+			log.Println("invalid token data")
+			helpers.SendResponseHTTP(c, http.StatusUnauthorized, "unauthorized", nil)
+			c.Abort()
+			return
+		}
+
+		if !slices.Contains(tokenData.Scopes, scope) && !slices.Contains(tokenData.Scopes, constants.ScopeAdmin) {
+			log.Printf("user %d is missing scope %s\n", tokenData.UserID, scope)
+			helpers.SendResponseHTTP(c, http.StatusForbidden, "forbidden", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 func (d *ExternalDependency) MiddlewareSignatureValidation(c *gin.Context) {
 
 	clientID := c.Request.Header.Get("Client-id")
@@ -72,10 +165,12 @@ func (d *ExternalDependency) MiddlewareSignatureValidation(c *gin.Context) {
 		return
 	}
 
+	// a captured request can only be sent again while its timestamp is
+	// fresh, one dated ahead would stay valid for longer
 	requestTime, err := time.Parse(time.RFC3339, timestamp)
-	now := time.Now()
+	age := time.Since(requestTime)
 
-	if err != nil || now.Sub(requestTime) > 5*time.Minute {
+	if err != nil || age > constants.SignatureMaxAge || age < -constants.SignatureMaxAge {
 		log.Println("invalid timestamp request")
 		helpers.SendResponseHTTP(c, http.StatusUnauthorized, "unauthorized", nil)
 		c.Abort()
@@ -90,9 +185,12 @@ func (d *ExternalDependency) MiddlewareSignatureValidation(c *gin.Context) {
 		return
 	}
 
-	strPayload := ""
+	var strPayload string
 
-	if c.Request.Method != http.MethodGet {
+	if c.Request.Method == http.MethodGet {
+		// without a body the signature covers what the request asks for
+		strPayload = c.Request.Method + "\n" + c.Request.URL.Path + "\n" + c.Request.URL.RawQuery + "\n" + timestamp
+	} else {
 		byteData, err := io.ReadAll(c.Request.Body)
 		if err != nil {
 			log.Println("failed to read request body")
@@ -110,9 +208,12 @@ func (d *ExternalDependency) MiddlewareSignatureValidation(c *gin.Context) {
 		strPayload = strings.ToLower(strPayload) + timestamp + endpoint
 	}
 
-	h := hmac.New(sha256.New, []byte(secretKey))
-	h.Write([]byte(strPayload))
-	generatedSignature := hex.EncodeToString(h.Sum(nil))
+	generatedSignature := signPayload(secretKey, strPayload)
+
+	if signature != generatedSignature && d.acceptsLegacySignature(c, secretKey, signature) {
+		log.Printf("client %s signed %s %s with the deprecated empty payload\n", clientID, c.Request.Method, c.Request.URL.Path)
+		generatedSignature = signature
+	}
 
 	if signature != generatedSignature {
 		log.Printf("invalid signature, requested: %s, generated: %s \n", signature, generatedSignature)
@@ -122,5 +223,86 @@ func (d *ExternalDependency) MiddlewareSignatureValidation(c *gin.Context) {
 	}
 
 	c.Set("client_id", clientID)
+	setRequestActor(c, constants.AuditActorClient, clientID, "")
 	c.Next()
 }
+
+// acceptsLegacySignature tells whether the request is signed the way GET
+// requests were before the signature covered them, over an empty payload. Only
+// the balance route still takes it, and only during the deprecation window.
+func (d *ExternalDependency) acceptsLegacySignature(c *gin.Context, secretKey string, signature string) bool {
+	if !d.LegacyBalanceSignature || c.Request.Method != http.MethodGet || c.FullPath() != constants.LegacySignatureRoute {
+		return false
+	}
+
+	return signature == signPayload(secretKey, "")
+}
+
+func signPayload(secretKey string, payload string) string {
+	h := hmac.New(sha256.New, []byte(secretKey))
+	h.Write([]byte(payload))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MiddlewareInternalOnly lets through only the services sharing the
+// internal API key, when the key is not configured nobody gets in.
+func (d *ExternalDependency) MiddlewareInternalOnly(c *gin.Context) {
+	internalKey := d.InternalAPIKey
+	requestKey := c.Request.Header.Get(constants.HeaderInternalKey)
+	if internalKey == "" || subtle.ConstantTimeCompare([]byte(requestKey), []byte(internalKey)) != 1 {
+		log.Println("invalid internal key")
+		helpers.SendResponseHTTP(c, http.StatusForbidden, "forbidden", nil)
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+func (d *ExternalDependency) MiddlewareValidateOperator(c *gin.Context) {
+	auth := strings.TrimPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+	if auth == "" {
+		log.Println("authorization empty")
+		helpers.SendResponseHTTP(c, http.StatusUnauthorized, "unauthorized", nil)
+		c.Abort()
+		return
+	}
+
+	claim, err := helpers.ValidateOperatorToken(c.Request.Context(), d.OperatorJWT, auth)
+	if err != nil {
+		log.Println("invalid operator token: ", err)
+		helpers.SendResponseHTTP(c, http.StatusUnauthorized, "unauthorized", nil)
+		c.Abort()
+		return
+	}
+
+	c.Set("operator", models.OperatorTokenData{
+		OperatorID: claim.OperatorID,
+		Username:   claim.Username,
+		Role:       claim.Role,
+	})
+	setRequestActor(c, constants.AuditActorOperator, claim.Username, claim.Role)
+	c.Next()
+}
+
+func (d *ExternalDependency) MiddlewareRequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		operator, ok := c.Get("operator")
+		if !ok {
+			log.Println("operator data empty")
+			helpers.SendResponseHTTP(c, http.StatusUnauthorized, "unauthorized", nil)
+			c.Abort()
+			return
+		}
+
+		operatorData, ok := operator.(models.OperatorTokenData)
+		if !ok || !slices.Contains(constants.MappingRolePermissions[operatorData.Role], permission) {
+			log.Printf("operator %v is not allowed to %s\n", operator, permission)
+			helpers.SendResponseHTTP(c, http.StatusForbidden, "forbidden", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
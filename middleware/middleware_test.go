@@ -2,9 +2,13 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"ewallet-wallet/constants"
 	"ewallet-wallet/generate_signature"
+	"ewallet-wallet/helpers"
 	"ewallet-wallet/internal/models"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -23,34 +27,90 @@ func TestExternalDependency_MiddlewareValidateToken(t *testing.T) {
 	mockExt := NewMockExternal(ctrlMock)
 
 	auth := "Authorization"
+	defaultScopes := []string{constants.ScopeWalletRead, constants.ScopeWalletDebit}
 
 	tests := []struct {
 		name               string
 		wantErr            bool
+		auth               string
 		mockFn             func()
 		expectedStatusCode int
+		expectedToken      models.TokenData
 	}{
 		{
 			name:    "success",
 			wantErr: false,
+			auth:    auth,
 			mockFn: func() {
 				mockExt.EXPECT().ValidateToken(gomock.Any(), auth).Return(models.TokenData{
 					UserID:   1,
 					Username: "username",
 					Fullname: "fullname",
 					Email:    "email@gmail.com",
+					Scopes:   []string{constants.ScopeWalletRead},
 				}, nil)
 			},
 			expectedStatusCode: http.StatusOK,
+			expectedToken: models.TokenData{
+				UserID:   1,
+				Username: "username",
+				Fullname: "fullname",
+				Email:    "email@gmail.com",
+				Scopes:   []string{constants.ScopeWalletRead},
+			},
+		},
+		{
+			name:    "success token without scopes gets the default scopes",
+			wantErr: false,
+			auth:    auth,
+			mockFn: func() {
+				mockExt.EXPECT().ValidateToken(gomock.Any(), auth).Return(models.TokenData{UserID: 1}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedToken:      models.TokenData{UserID: 1, Scopes: defaultScopes},
+		},
+		{
+			name:               "error empty authorization",
+			wantErr:            true,
+			mockFn:             func() {},
+			expectedStatusCode: http.StatusUnauthorized,
 		},
 		{
 			name:    "error",
 			wantErr: true,
+			auth:    auth,
 			mockFn: func() {
 				mockExt.EXPECT().ValidateToken(gomock.Any(), auth).Return(models.TokenData{}, assert.AnError)
 			},
 			expectedStatusCode: http.StatusUnauthorized,
 		},
+		{
+			name:    "error invalid token",
+			wantErr: true,
+			auth:    auth,
+			mockFn: func() {
+				mockExt.EXPECT().ValidateToken(gomock.Any(), auth).Return(models.TokenData{}, fmt.Errorf("%w: token is expired", constants.ErrInvalidToken))
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:    "error ums unavailable",
+			wantErr: true,
+			auth:    auth,
+			mockFn: func() {
+				mockExt.EXPECT().ValidateToken(gomock.Any(), auth).Return(models.TokenData{}, fmt.Errorf("%w: deadline exceeded", constants.ErrUMSUnavailable))
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:    "error key set unavailable",
+			wantErr: true,
+			auth:    auth,
+			mockFn: func() {
+				mockExt.EXPECT().ValidateToken(gomock.Any(), auth).Return(models.TokenData{}, fmt.Errorf("%w: connection refused", constants.ErrKeySetUnavailable))
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -58,20 +118,184 @@ func TestExternalDependency_MiddlewareValidateToken(t *testing.T) {
 			api := gin.New()
 
 			d := &ExternalDependency{
-				External: mockExt,
+				External:      mockExt,
+				DefaultScopes: defaultScopes,
 			}
 
+			reached := false
 			w := httptest.NewRecorder()
 			endPoint := "/validate-token"
-			api.GET(endPoint, d.MiddlewareValidateToken)
+			api.GET(endPoint, d.MiddlewareValidateToken, func(c *gin.Context) {
+				reached = true
+				token, _ := c.Get("token")
+				assert.Equal(t, tt.expectedToken, token)
+				c.Status(http.StatusOK)
+			})
 
 			req, err := http.NewRequest(http.MethodGet, endPoint, nil)
 			assert.NoError(t, err)
-			req.Header.Set("Authorization", auth)
+			req.Header.Set("Authorization", tt.auth)
 
 			api.ServeHTTP(w, req)
 			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			// a rejected request must never reach the handler
+			assert.Equal(t, !tt.wantErr, reached)
+		})
+	}
+}
 
+func TestExternalDependency_MiddlewareRequireScope(t *testing.T) {
+	tests := []struct {
+		name               string
+		token              interface{}
+		scope              string
+		expectedStatusCode int
+	}{
+		{
+			name:               "success",
+			token:              models.TokenData{UserID: 1, Scopes: []string{constants.ScopeWalletRead, constants.ScopeWalletDebit}},
+			scope:              constants.ScopeWalletDebit,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "success admin",
+			token:              models.TokenData{UserID: 1, Scopes: []string{constants.ScopeAdmin}},
+			scope:              constants.ScopeWalletCredit,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error missing scope",
+			token:              models.TokenData{UserID: 1, Scopes: []string{constants.ScopeWalletRead}},
+			scope:              constants.ScopeWalletDebit,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "error no scopes",
+			token:              models.TokenData{UserID: 1},
+			scope:              constants.ScopeWalletRead,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "error scope prefix is not the scope",
+			token:              models.TokenData{UserID: 1, Scopes: []string{"wallet"}},
+			scope:              constants.ScopeWalletRead,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "error token data empty",
+			expectedStatusCode: http.StatusUnauthorized,
+			scope:              constants.ScopeWalletRead,
+		},
+		{
+			name:               "error token data without user",
+			token:              models.TokenData{Scopes: []string{constants.ScopeAdmin}},
+			scope:              constants.ScopeWalletRead,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "error token data of another type",
+			token:              "token",
+			scope:              constants.ScopeWalletRead,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := gin.New()
+			d := &ExternalDependency{}
+
+			reached := false
+			w := httptest.NewRecorder()
+			endPoint := "/require-scope"
+			api.GET(endPoint, func(c *gin.Context) {
+				if tt.token != nil {
+					c.Set("token", tt.token)
+				}
+				c.Next()
+			}, d.MiddlewareRequireScope(tt.scope), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, endPoint, nil)
+			assert.NoError(t, err)
+
+			api.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedStatusCode == http.StatusOK, reached)
+		})
+	}
+}
+
+func TestExternalDependency_UnscopedTokenScopes(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockExt := NewMockExternal(ctrlMock)
+
+	auth := "Authorization"
+	legacyScopes := []string{constants.ScopeWalletRead, constants.ScopeWalletDebit, constants.ScopeWalletCredit}
+
+	tests := []struct {
+		name               string
+		defaultScopes      []string
+		scope              string
+		expectedStatusCode int
+	}{
+		{
+			name:               "error no default scopes refused on debit",
+			scope:              constants.ScopeWalletDebit,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "error no default scopes refused on read",
+			scope:              constants.ScopeWalletRead,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "success read only default on read",
+			defaultScopes:      []string{constants.ScopeWalletRead},
+			scope:              constants.ScopeWalletRead,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error read only default refused on debit",
+			defaultScopes:      []string{constants.ScopeWalletRead},
+			scope:              constants.ScopeWalletDebit,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "success legacy full access on debit",
+			defaultScopes:      legacyScopes,
+			scope:              constants.ScopeWalletDebit,
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExt.EXPECT().ValidateToken(gomock.Any(), auth).Return(models.TokenData{UserID: 1}, nil)
+			api := gin.New()
+
+			d := &ExternalDependency{
+				External:      mockExt,
+				DefaultScopes: tt.defaultScopes,
+			}
+
+			reached := false
+			w := httptest.NewRecorder()
+			endPoint := "/unscoped-token"
+			api.POST(endPoint, d.MiddlewareValidateToken, d.MiddlewareRequireScope(tt.scope), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodPost, endPoint, nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", auth)
+
+			api.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedStatusCode == http.StatusOK, reached)
 		})
 	}
 }
@@ -86,6 +310,9 @@ func TestExternalDependency_MiddlewareSignatureValidation(t *testing.T) {
 	endPoint := "/signature-validation"
 
 	now := time.Now()
+	// a request captured earlier and sent again
+	captured := now.Add(-10 * time.Minute)
+	query := "?from=2024-01-01&to=2024-01-31"
 
 	model := models.WalletLink{
 		WalletID:     1,
@@ -102,6 +329,8 @@ func TestExternalDependency_MiddlewareSignatureValidation(t *testing.T) {
 		expectedStatusCode int
 		signature          string
 		method             string
+		target             string
+		timestamp          time.Time
 	}{
 		{
 			name:               "success with get method",
@@ -117,6 +346,47 @@ func TestExternalDependency_MiddlewareSignatureValidation(t *testing.T) {
 			signature:          "signature",
 			method:             http.MethodGet,
 		},
+		{
+			name:               "success with get method and query",
+			wantErr:            false,
+			expectedStatusCode: http.StatusOK,
+			signature:          generate_signature.GenerateSignature(endPoint+query, clientID, now, http.MethodGet, ``),
+			method:             http.MethodGet,
+			target:             endPoint + query,
+		},
+		{
+			name:               "error with get method and altered query",
+			wantErr:            true,
+			expectedStatusCode: http.StatusUnauthorized,
+			signature:          generate_signature.GenerateSignature(endPoint+query, clientID, now, http.MethodGet, ``),
+			method:             http.MethodGet,
+			target:             endPoint + "?from=2024-01-01&to=2024-12-31",
+		},
+		{
+			name:               "error with get method signed without query",
+			wantErr:            true,
+			expectedStatusCode: http.StatusUnauthorized,
+			signature:          generate_signature.GenerateSignature(endPoint, clientID, now, http.MethodGet, ``),
+			method:             http.MethodGet,
+			target:             endPoint + query,
+		},
+		{
+			name:               "error with replayed get request",
+			wantErr:            true,
+			expectedStatusCode: http.StatusUnauthorized,
+			signature:          generate_signature.GenerateSignature(endPoint+query, clientID, captured, http.MethodGet, ``),
+			method:             http.MethodGet,
+			target:             endPoint + query,
+			timestamp:          captured,
+		},
+		{
+			name:               "error with timestamp ahead",
+			wantErr:            true,
+			expectedStatusCode: http.StatusUnauthorized,
+			signature:          generate_signature.GenerateSignature(endPoint, clientID, now.Add(time.Hour), http.MethodGet, ``),
+			method:             http.MethodGet,
+			timestamp:          now.Add(time.Hour),
+		},
 		{
 			name:               "success with non get method",
 			wantErr:            false,
@@ -131,6 +401,14 @@ func TestExternalDependency_MiddlewareSignatureValidation(t *testing.T) {
 			signature:          "signature",
 			method:             http.MethodPost,
 		},
+		{
+			name:               "error with replayed non get request",
+			wantErr:            true,
+			expectedStatusCode: http.StatusUnauthorized,
+			signature:          generate_signature.GenerateSignature(endPoint, clientID, captured, http.MethodPost, string(val)),
+			method:             http.MethodPost,
+			timestamp:          captured,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -152,15 +430,321 @@ func TestExternalDependency_MiddlewareSignatureValidation(t *testing.T) {
 				body = bytes.NewReader(val)
 			}
 
-			req, err := http.NewRequest(tt.method, endPoint, body)
+			target := tt.target
+			if target == "" {
+				target = endPoint
+			}
+			timestamp := tt.timestamp
+			if timestamp.IsZero() {
+				timestamp = now
+			}
+
+			req, err := http.NewRequest(tt.method, target, body)
+			assert.NoError(t, err)
+
+			req.Header.Set("Client-id", clientID)
+			req.Header.Set("Timestamp", timestamp.Format(time.RFC3339))
+			req.Header.Set("Signature", tt.signature)
+
+			api.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestExternalDependency_MiddlewareLegacyBalanceSignature(t *testing.T) {
+	clientID := "fastcampus_ecommerce"
+	now := time.Now()
+
+	// the old scheme signed every GET over an empty payload
+	legacySignature := signPayload(constants.MappingClient[clientID], "")
+
+	tests := []struct {
+		name               string
+		legacy             bool
+		target             string
+		signature          string
+		expectedStatusCode int
+	}{
+		{
+			name:               "success legacy signature on the balance route",
+			legacy:             true,
+			target:             "/wallet/v1/ex/1/balance",
+			signature:          legacySignature,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "success new signature on the balance route",
+			legacy:             true,
+			target:             "/wallet/v1/ex/1/balance",
+			signature:          generate_signature.GenerateSignature("/wallet/v1/ex/1/balance", clientID, now, http.MethodGet, ``),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error legacy signature after the window",
+			target:             "/wallet/v1/ex/1/balance",
+			signature:          legacySignature,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "error legacy signature on another route",
+			legacy:             true,
+			target:             "/wallet/v1/ex/settlements",
+			signature:          legacySignature,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := gin.New()
+
+			d := &ExternalDependency{
+				LegacyBalanceSignature: tt.legacy,
+			}
+
+			api.GET(constants.LegacySignatureRoute, d.MiddlewareSignatureValidation)
+			api.GET("/wallet/v1/ex/settlements", d.MiddlewareSignatureValidation)
+
+			req, err := http.NewRequest(http.MethodGet, tt.target, nil)
 			assert.NoError(t, err)
 
 			req.Header.Set("Client-id", clientID)
 			req.Header.Set("Timestamp", now.Format(time.RFC3339))
 			req.Header.Set("Signature", tt.signature)
 
+			w := httptest.NewRecorder()
+			api.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestExternalDependency_MiddlewareValidateOperator(t *testing.T) {
+	operatorJWT := helpers.JWTConfig{Secret: "admin-secret"}
+
+	token, _, err := helpers.GenerateOperatorToken(context.Background(), operatorJWT, 1, "operator", "support", time.Now())
+	assert.NoError(t, err)
+
+	expiredToken, _, err := helpers.GenerateOperatorToken(context.Background(), operatorJWT, 1, "operator", "support", time.Now().Add(-24*time.Hour))
+	assert.NoError(t, err)
+
+	userToken, err := helpers.GenerateToken(context.Background(), helpers.JWTConfig{Secret: "app-secret"}, 1, "username", "fullname", "email@gmail.com", time.Now(), "token")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		auth               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "success",
+			auth:               "Bearer " + token,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error empty token",
+			auth:               "",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "error expired token",
+			auth:               "Bearer " + expiredToken,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "error end user token",
+			auth:               "Bearer " + userToken,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := gin.New()
+
+			d := &ExternalDependency{OperatorJWT: operatorJWT}
+
+			w := httptest.NewRecorder()
+			endPoint := "/admin"
+			api.GET(endPoint, d.MiddlewareValidateOperator, func(c *gin.Context) {
+				operator, ok := c.Get("operator")
+				assert.True(t, ok)
+				assert.Equal(t, models.OperatorTokenData{OperatorID: 1, Username: "operator", Role: "support"}, operator)
+				meta := helpers.GetRequestMeta(c.Request.Context())
+				assert.Equal(t, "operator", meta.ActorType)
+				assert.Equal(t, "operator", meta.Actor)
+				c.Status(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, endPoint, nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", tt.auth)
+
+			api.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestExternalDependency_MiddlewareRequirePermission(t *testing.T) {
+	tests := []struct {
+		name               string
+		role               string
+		permission         string
+		expectedStatusCode int
+	}{
+		{
+			name:               "success support freeze",
+			role:               "support",
+			permission:         "wallet:status",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error viewer freeze",
+			role:               "viewer",
+			permission:         "wallet:status",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "error support close",
+			role:               "support",
+			permission:         "wallet:close",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "success admin close",
+			role:               "admin",
+			permission:         "wallet:close",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error unknown role",
+			role:               "root",
+			permission:         "wallet:read",
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := gin.New()
+
+			d := &ExternalDependency{}
+
+			w := httptest.NewRecorder()
+			endPoint := "/admin"
+			api.GET(endPoint, func(c *gin.Context) {
+				c.Set("operator", models.OperatorTokenData{OperatorID: 1, Username: "operator", Role: tt.role})
+				c.Next()
+			}, d.MiddlewareRequirePermission(tt.permission), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, endPoint, nil)
+			assert.NoError(t, err)
+
+			api.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestExternalDependency_MiddlewareRequestMeta(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestID     string
+		wantRequestID string
+	}{
+		{
+			name:          "keep caller request id",
+			requestID:     "caller-request-id",
+			wantRequestID: "caller-request-id",
+		},
+		{
+			name:      "generate request id",
+			requestID: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := gin.New()
+
+			d := &ExternalDependency{}
+
+			w := httptest.NewRecorder()
+			endPoint := "/request-meta"
+			api.GET(endPoint, d.MiddlewareRequestMeta, func(c *gin.Context) {
+				meta := helpers.GetRequestMeta(c.Request.Context())
+				assert.NotEmpty(t, meta.RequestID)
+				if tt.wantRequestID != "" {
+					assert.Equal(t, tt.wantRequestID, meta.RequestID)
+				}
+				assert.Equal(t, "10.1.2.3", meta.IP)
+				assert.Equal(t, helpers.TransactionAuth{PIN: "123456"}, helpers.GetTransactionAuth(c.Request.Context()))
+				c.Status(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, endPoint, nil)
+			assert.NoError(t, err)
+			req.RemoteAddr = "10.1.2.3:5000"
+			req.Header.Set("X-Request-ID", tt.requestID)
+			req.Header.Set(constants.HeaderWalletPIN, "123456")
+
 			api.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+		})
+	}
+}
 
+func TestExternalDependency_MiddlewareInternalOnly(t *testing.T) {
+	tests := []struct {
+		name               string
+		internalKey        string
+		requestKey         string
+		expectedStatusCode int
+	}{
+		{
+			name:               "success",
+			internalKey:        "internal_secret",
+			requestKey:         "internal_secret",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "error wrong key",
+			internalKey:        "internal_secret",
+			requestKey:         "guess",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "error missing key",
+			internalKey:        "internal_secret",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "error key not configured",
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := gin.New()
+
+			d := &ExternalDependency{InternalAPIKey: tt.internalKey}
+
+			w := httptest.NewRecorder()
+			endPoint := "/internal"
+			api.PUT(endPoint, d.MiddlewareInternalOnly, func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodPut, endPoint, nil)
+			assert.NoError(t, err)
+			if tt.requestKey != "" {
+				req.Header.Set("X-Internal-Key", tt.requestKey)
+			}
+
+			api.ServeHTTP(w, req)
 			assert.Equal(t, tt.expectedStatusCode, w.Code)
 		})
 	}
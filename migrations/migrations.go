@@ -0,0 +1,540 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"ewallet-wallet/constants"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// every driver has its own directory of migrations, with the same versions
+// and names, so a version means the same schema on every engine
+//
+//go:embed sql/*/*.sql
+var files embed.FS
+
+const (
+	lockName           = "ewallet_wallet_schema_migrations"
+	defaultLockTimeout = time.Minute
+	lockPollInterval   = 500 * time.Millisecond
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one numbered schema change, Down undoes Up.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status is a migration of the build and whether the database has it.
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Modified is set when the file changed after it was applied
+	Modified bool `json:"modified,omitempty"`
+}
+
+type appliedMigration struct {
+	version   int
+	checksum  string
+	appliedAt time.Time
+}
+
+// Migrator applies the SQL migrations embedded in the binary and records
+// them in schema_migrations. Every run holds a database lock, so pods
+// starting together migrate one after the other. On postgres and sqlite a
+// migration and its schema_migrations row commit together in one transaction.
+// MySQL commits every DDL statement on its own, so a migration failing there
+// half way leaves the statements before the failure in place and unrecorded,
+// they have to be undone by hand before migrating again.
+type Migrator struct {
+	DB          *sql.DB
+	Driver      string
+	LockTimeout time.Duration
+
+	migrations []Migration
+}
+
+// New loads the embedded migrations of driver, mysql, postgres or sqlite.
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	switch driver {
+	case constants.DBDriverMySQL, constants.DBDriverPostgres, constants.DBDriverSQLite:
+	default:
+		return nil, fmt.Errorf("no migrations for database driver %s", driver)
+	}
+
+	sub, err := fs.Sub(files, "sql/"+driver)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := NewFromFS(db, sub)
+	if err != nil {
+		return nil, err
+	}
+	migrator.Driver = driver
+
+	return migrator, nil
+}
+
+// NewFromFS reads the migrations from <version>_<name>.up.sql and
+// <version>_<name>.down.sql files, every version needs both.
+func NewFromFS(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		DB:          db,
+		Driver:      constants.DBDriverMySQL,
+		LockTimeout: defaultLockTimeout,
+		migrations:  migrations,
+	}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read migrations")
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		raw, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read migration")
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(raw)
+			sum := sha256.Sum256(raw)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(raw)
+		}
+	}
+
+	resp := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d needs both an up and a down file", m.Version)
+		}
+		resp = append(resp, *m)
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		return resp[i].Version < resp[j].Version
+	})
+
+	return resp, nil
+}
+
+// Latest is the version of the newest migration of the build.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the last steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var resp []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.checkApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(resp) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			resp = append(resp, migration)
+		}
+
+		return nil
+	})
+
+	return resp, err
+}
+
+// To migrates up or down until version is the newest applied migration,
+// version 0 reverts everything.
+func (m *Migrator) To(ctx context.Context, version int) ([]Migration, error) {
+	if version != 0 && !m.known(version) {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+
+	var resp []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.checkApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+				continue
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			resp = append(resp, migration)
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			resp = append(resp, migration)
+		}
+
+		return nil
+	})
+
+	return resp, err
+}
+
+// Status lists the migrations of the build, it does not take the lock.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get database connection")
+	}
+	defer conn.Close()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []Status
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.appliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = row.checksum != migration.Checksum
+		}
+		resp = append(resp, status)
+	}
+
+	return resp, nil
+}
+
+func (m *Migrator) known(version int) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	// the lock belongs to the session, so everything runs on one connection
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get database connection")
+	}
+	defer conn.Close()
+
+	unlock, err := m.lock(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// lock takes the named lock of the engine, GET_LOCK waits by itself while
+// the postgres advisory lock is polled until LockTimeout. A sqlite file has
+// a single process using it, so there is nothing to take.
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) (func(), error) {
+	if m.Driver == constants.DBDriverSQLite {
+		return func() {}, nil
+	}
+
+	if m.Driver == constants.DBDriverPostgres {
+		deadline := time.Now().Add(m.LockTimeout)
+		for {
+			var locked bool
+			err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", lockName).Scan(&locked)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to take migration lock")
+			}
+			if locked {
+				break
+			}
+			if time.Now().After(deadline) {
+				return nil, constants.ErrMigrationLocked
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(lockPollInterval):
+			}
+		}
+
+		return func() {
+			conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", lockName)
+		}, nil
+	}
+
+	var locked sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(m.LockTimeout.Seconds())).Scan(&locked)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to take migration lock")
+	}
+	if locked.Int64 != 1 {
+		return nil, constants.ErrMigrationLocked
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+	}, nil
+}
+
+// bind turns the ? placeholders into the $n ones postgres expects.
+func (m *Migrator) bind(query string) string {
+	if m.Driver != constants.DBDriverPostgres {
+		return query
+	}
+
+	var (
+		b strings.Builder
+		n int
+	)
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		fmt.Fprintf(&b, "$%d", n)
+	}
+
+	return b.String()
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	timestamp := "datetime(3)"
+	switch m.Driver {
+	case constants.DBDriverPostgres:
+		timestamp = "timestamptz(3)"
+	case constants.DBDriverSQLite:
+		timestamp = "datetime"
+	}
+
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version bigint NOT NULL,
+  name varchar(255) NOT NULL,
+  checksum char(64) NOT NULL,
+  applied_at `+timestamp+` NOT NULL,
+  PRIMARY KEY (version)
+)`)
+	if err != nil {
+		return errors.Wrap(err, "failed to create schema_migrations")
+	}
+
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, checksum, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get applied migrations")
+	}
+	defer rows.Close()
+
+	resp := map[int]appliedMigration{}
+	for rows.Next() {
+		var row appliedMigration
+		if err := rows.Scan(&row.version, &row.checksum, &row.appliedAt); err != nil {
+			return nil, errors.Wrap(err, "failed to read applied migration")
+		}
+		resp[row.version] = row
+	}
+
+	return resp, rows.Err()
+}
+
+// checkApplied refuses to migrate a database whose history does not match
+// the files of the build.
+func (m *Migrator) checkApplied(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]Migration{}
+	for _, migration := range m.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	for version, row := range applied {
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: version %d", constants.ErrMigrationUnknown, version)
+		}
+		if row.checksum != migration.Checksum {
+			return nil, fmt.Errorf("%w: %d_%s", constants.ErrMigrationModified, version, migration.Name)
+		}
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	return m.inTransaction(ctx, conn, func(db execer) error {
+		if err := execScript(ctx, db, migration.Up); err != nil {
+			return errors.Wrapf(err, "failed to apply migration %d_%s", migration.Version, migration.Name)
+		}
+
+		_, err := db.ExecContext(ctx, m.bind("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)"),
+			migration.Version, migration.Name, migration.Checksum, time.Now())
+		if err != nil {
+			return errors.Wrapf(err, "failed to record migration %d_%s", migration.Version, migration.Name)
+		}
+
+		return nil
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	return m.inTransaction(ctx, conn, func(db execer) error {
+		if err := execScript(ctx, db, migration.Down); err != nil {
+			return errors.Wrapf(err, "failed to revert migration %d_%s", migration.Version, migration.Name)
+		}
+
+		_, err := db.ExecContext(ctx, m.bind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+		if err != nil {
+			return errors.Wrapf(err, "failed to record revert of migration %d_%s", migration.Version, migration.Name)
+		}
+
+		return nil
+	})
+}
+
+// execer is the connection or the transaction a migration runs on.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// inTransaction runs fn in a transaction on the engines with transactional
+// DDL, on MySQL it runs on the connection as it is.
+func (m *Migrator) inTransaction(ctx context.Context, conn *sql.Conn, fn func(db execer) error) error {
+	if m.Driver == constants.DBDriverMySQL {
+		return fn(conn)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin migration transaction")
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "failed to commit migration")
+}
+
+// execScript runs the statements of a file one by one, the driver does not
+// allow several in one call.
+func execScript(ctx context.Context, db execer, script string) error {
+	for _, statement := range splitStatements(script) {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitStatements cuts a script at the semicolons ending a line, comment
+// lines are dropped.
+func splitStatements(script string) []string {
+	var (
+		resp    []string
+		current []string
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current = append(current, line)
+		if strings.HasSuffix(trimmed, ";") {
+			statement := strings.TrimSuffix(strings.TrimSpace(strings.Join(current, "\n")), ";")
+			resp = append(resp, statement)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		resp = append(resp, strings.TrimSpace(strings.Join(current, "\n")))
+	}
+
+	return resp
+}
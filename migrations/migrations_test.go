@@ -0,0 +1,454 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"ewallet-wallet/constants"
+	"ewallet-wallet/helpers"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFiles = fstest.MapFS{
+	"0001_init.up.sql":       {Data: []byte("-- wallets\nCREATE TABLE wallets (\n  id bigint\n);\nCREATE INDEX idx_wallets_id ON wallets (id);\n")},
+	"0001_init.down.sql":     {Data: []byte("DROP TABLE wallets;\n")},
+	"0002_add_tier.up.sql":   {Data: []byte("ALTER TABLE wallets ADD COLUMN tier varchar(20);\n")},
+	"0002_add_tier.down.sql": {Data: []byte("ALTER TABLE wallets DROP COLUMN tier;\n")},
+	"0003_backfill.up.sql":   {Data: []byte("UPDATE wallets SET tier = 'basic';\n")},
+	"0003_backfill.down.sql": {Data: []byte("UPDATE wallets SET tier = NULL;\n")},
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := NewFromFS(db, testFiles)
+	assert.NoError(t, err)
+
+	return m, mock
+}
+
+func expectLock(mock sqlmock.Sqlmock, got int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).WithArgs(lockName, 60).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(got))
+}
+
+func expectApplied(mock sqlmock.Sqlmock, m *Migrator, versions ...int) {
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version", "checksum", "applied_at"})
+	for _, version := range versions {
+		rows.AddRow(version, m.migrations[version-1].Checksum, time.Now())
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, checksum, applied_at FROM schema_migrations ORDER BY version")).WillReturnRows(rows)
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WithArgs(lockName).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestNewFromFS(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:  "success",
+			files: testFiles,
+		},
+		{
+			name: "error missing down file",
+			files: fstest.MapFS{
+				"0001_init.up.sql": {Data: []byte("CREATE TABLE wallets (id bigint);")},
+			},
+			wantErr: "migration 1 needs both an up and a down file",
+		},
+		{
+			name: "error invalid file name",
+			files: fstest.MapFS{
+				"init.sql": {Data: []byte("CREATE TABLE wallets (id bigint);")},
+			},
+			wantErr: "invalid migration file name init.sql",
+		},
+		{
+			name: "error version with two names",
+			files: fstest.MapFS{
+				"0001_init.up.sql":    {Data: []byte("CREATE TABLE wallets (id bigint);")},
+				"0001_other.down.sql": {Data: []byte("DROP TABLE wallets;")},
+			},
+			wantErr: "migration 1 has two names",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewFromFS(nil, tt.files)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 3, m.Latest())
+			assert.Equal(t, []string{"init", "add_tier", "backfill"}, []string{m.migrations[0].Name, m.migrations[1].Name, m.migrations[2].Name})
+		})
+	}
+}
+
+func TestNew_EmbeddedMigrations(t *testing.T) {
+	tests := []struct {
+		driver  string
+		indexes []string
+	}{
+		{
+			driver: constants.DBDriverMySQL,
+			indexes: []string{
+				"CREATE INDEX `idx_wallet_transactions_wallet_id_id` ON `wallet_transactions` (`wallet_id`, `id`)",
+				"CREATE INDEX `idx_wallet_links_wallet_id_client_source` ON `wallet_links` (`wallet_id`, `client_source`)",
+			},
+		},
+		{
+			driver: constants.DBDriverPostgres,
+			indexes: []string{
+				"CREATE INDEX IF NOT EXISTS idx_wallet_transactions_wallet_id_id ON wallet_transactions (wallet_id, id)",
+				"CREATE INDEX IF NOT EXISTS idx_wallet_links_wallet_id_client_source ON wallet_links (wallet_id, client_source)",
+			},
+		},
+		{
+			driver: constants.DBDriverSQLite,
+			indexes: []string{
+				"CREATE INDEX IF NOT EXISTS idx_wallet_transactions_wallet_id_id ON wallet_transactions (wallet_id, id)",
+				"CREATE INDEX IF NOT EXISTS idx_wallet_links_wallet_id_client_source ON wallet_links (wallet_id, client_source)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			m, err := New(nil, tt.driver)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.driver, m.Driver)
+			assert.Equal(t, 1, m.migrations[0].Version)
+			for _, index := range tt.indexes {
+				assert.Contains(t, m.migrations[0].Up, index)
+			}
+
+			// every table created by 0001 is dropped by its down file
+			for _, statement := range splitStatements(m.migrations[0].Up) {
+				if !strings.HasPrefix(statement, "CREATE TABLE IF NOT EXISTS ") {
+					continue
+				}
+				table := strings.Fields(statement)[5]
+				assert.Contains(t, m.migrations[0].Down, "DROP TABLE IF EXISTS "+table+";")
+			}
+		})
+	}
+}
+
+func TestNew_DriversInStep(t *testing.T) {
+	mysql, err := New(nil, constants.DBDriverMySQL)
+	assert.NoError(t, err)
+
+	names := func(m *Migrator) []string {
+		var resp []string
+		for _, migration := range m.migrations {
+			resp = append(resp, fmt.Sprintf("%d_%s", migration.Version, migration.Name))
+		}
+		return resp
+	}
+	tables := func(m *Migrator) []string {
+		var resp []string
+		for _, migration := range m.migrations {
+			for _, statement := range splitStatements(migration.Up) {
+				if strings.HasPrefix(statement, "CREATE TABLE IF NOT EXISTS ") {
+					resp = append(resp, strings.Trim(strings.Fields(statement)[5], "`"))
+				}
+			}
+		}
+		return resp
+	}
+
+	// a version has to mean the same change on every engine
+	for _, driver := range []string{constants.DBDriverPostgres, constants.DBDriverSQLite} {
+		m, err := New(nil, driver)
+		assert.NoError(t, err)
+		assert.Equal(t, names(mysql), names(m), driver)
+		assert.Equal(t, tables(mysql), tables(m), driver)
+	}
+
+	_, err = New(nil, "oracle")
+	assert.ErrorContains(t, err, "no migrations for database driver oracle")
+}
+
+func TestMigrator_Up(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	expectLock(mock, 1)
+	expectApplied(mock, m, 1)
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE wallets ADD COLUMN tier varchar(20)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)")).
+		WithArgs(2, "add_tier", m.migrations[1].Checksum, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET tier = 'basic'")).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)")).
+		WithArgs(3, "backfill", m.migrations[2].Checksum, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectUnlock(mock)
+
+	got, err := m.Up(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, versions(got))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_UpErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockFn  func(m *Migrator, mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "error locked by another migration",
+			mockFn: func(m *Migrator, mock sqlmock.Sqlmock) {
+				expectLock(mock, 0)
+			},
+			wantErr: constants.ErrMigrationLocked,
+		},
+		{
+			name: "error applied migration modified",
+			mockFn: func(m *Migrator, mock sqlmock.Sqlmock) {
+				expectLock(mock, 1)
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT version, checksum, applied_at FROM schema_migrations")).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).AddRow(1, "changed", time.Now()))
+				expectUnlock(mock)
+			},
+			wantErr: constants.ErrMigrationModified,
+		},
+		{
+			name: "error applied migration unknown",
+			mockFn: func(m *Migrator, mock sqlmock.Sqlmock) {
+				expectLock(mock, 1)
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT version, checksum, applied_at FROM schema_migrations")).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).AddRow(9, "checksum", time.Now()))
+				expectUnlock(mock)
+			},
+			wantErr: constants.ErrMigrationUnknown,
+		},
+		{
+			name: "error failed statement is not recorded",
+			mockFn: func(m *Migrator, mock sqlmock.Sqlmock) {
+				expectLock(mock, 1)
+				expectApplied(mock, m, 1, 2)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET tier = 'basic'")).WillReturnError(sql.ErrConnDone)
+				expectUnlock(mock)
+			},
+			wantErr: sql.ErrConnDone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, mock := newTestMigrator(t)
+			tt.mockFn(m, mock)
+
+			got, err := m.Up(context.Background())
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	expectLock(mock, 1)
+	expectApplied(mock, m, 1, 2, 3)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET tier = NULL")).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = ?")).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE wallets DROP COLUMN tier")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = ?")).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectUnlock(mock)
+
+	got, err := m.Down(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 2}, versions(got))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_To(t *testing.T) {
+	t.Run("success down to version", func(t *testing.T) {
+		m, mock := newTestMigrator(t)
+
+		expectLock(mock, 1)
+		expectApplied(mock, m, 1, 2, 3)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET tier = NULL")).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = ?")).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE wallets DROP COLUMN tier")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = ?")).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectUnlock(mock)
+
+		got, err := m.To(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3, 2}, versions(got))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success up to version", func(t *testing.T) {
+		m, mock := newTestMigrator(t)
+
+		expectLock(mock, 1)
+		expectApplied(mock, m)
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE wallets (\n  id bigint\n)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx_wallets_id ON wallets (id)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations")).WithArgs(1, "init", m.migrations[0].Checksum, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE wallets ADD COLUMN tier varchar(20)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations")).WithArgs(2, "add_tier", m.migrations[1].Checksum, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectUnlock(mock)
+
+		got, err := m.To(context.Background(), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, versions(got))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error unknown version", func(t *testing.T) {
+		m, _ := newTestMigrator(t)
+
+		_, err := m.To(context.Background(), 7)
+		assert.ErrorContains(t, err, "unknown migration version 7")
+	})
+}
+
+func TestMigrator_Status(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	appliedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, checksum, applied_at FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).
+			AddRow(1, m.migrations[0].Checksum, appliedAt).
+			AddRow(2, "changed", appliedAt))
+
+	got, err := m.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Status{
+		{Version: 1, Name: "init", Applied: true, AppliedAt: &appliedAt},
+		{Version: 2, Name: "add_tier", Applied: true, AppliedAt: &appliedAt, Modified: true},
+		{Version: 3, Name: "backfill"},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSplitStatements(t *testing.T) {
+	got := splitStatements("-- comment\nCREATE TABLE a (\n  id bigint,\n  note varchar(10) DEFAULT 'a;b'\n);\n\nDROP TABLE b;\nSELECT 1")
+	assert.Equal(t, []string{
+		"CREATE TABLE a (\n  id bigint,\n  note varchar(10) DEFAULT 'a;b'\n)",
+		"DROP TABLE b",
+		"SELECT 1",
+	}, got)
+}
+
+func versions(migrations []Migration) []int {
+	var resp []int
+	for _, m := range migrations {
+		resp = append(resp, m.Version)
+	}
+	return resp
+}
+
+func TestMigrator_Postgres(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	m.Driver = constants.DBDriverPostgres
+
+	// the advisory lock is polled until it is free
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT pg_try_advisory_lock(hashtext($1))")).WithArgs(lockName).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT pg_try_advisory_lock(hashtext($1))")).WithArgs(lockName).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta("applied_at timestamptz(3) NOT NULL")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, checksum, applied_at FROM schema_migrations ORDER BY version")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).
+			AddRow(1, m.migrations[0].Checksum, time.Now()).
+			AddRow(2, m.migrations[1].Checksum, time.Now()))
+	// the migration and its record commit together
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET tier = 'basic'")).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)")).
+		WithArgs(3, "backfill", m.migrations[2].Checksum, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock(hashtext($1))")).WithArgs(lockName).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	got, err := m.Up(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3}, versions(got))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_PostgresRollsBackFailedMigration(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	m.Driver = constants.DBDriverPostgres
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT pg_try_advisory_lock(hashtext($1))")).WithArgs(lockName).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta("applied_at timestamptz(3) NOT NULL")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, checksum, applied_at FROM schema_migrations ORDER BY version")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "checksum", "applied_at"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE wallets")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx_wallets_id ON wallets (id)")).WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock(hashtext($1))")).WithArgs(lockName).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	got, err := m.Up(context.Background())
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.Empty(t, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_SQLiteFailedMigrationLeavesNothing(t *testing.T) {
+	db, err := helpers.OpenSQLite(filepath.Join(t.TempDir(), "migrations.db"))
+	assert.NoError(t, err)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	defer sqlDB.Close()
+
+	m, err := NewFromFS(sqlDB, fstest.MapFS{
+		"0001_init.up.sql":   {Data: []byte("CREATE TABLE wallets (\n  id bigint\n);\nINSERT INTO missing (id) VALUES (1);\n")},
+		"0001_init.down.sql": {Data: []byte("DROP TABLE wallets;\n")},
+	})
+	assert.NoError(t, err)
+	m.Driver = constants.DBDriverSQLite
+
+	_, err = m.Up(context.Background())
+	assert.Error(t, err)
+
+	// the table created before the failing statement is rolled back with it
+	var tables int
+	assert.NoError(t, sqlDB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'wallets'").Scan(&tables))
+	assert.Equal(t, 0, tables)
+
+	status, err := m.Status(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, status[0].Applied)
+}
+
+func TestMigrator_PostgresLockTimeout(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	m.Driver = constants.DBDriverPostgres
+	m.LockTimeout = 0
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT pg_try_advisory_lock(hashtext($1))")).WithArgs(lockName).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+
+	_, err := m.Up(context.Background())
+	assert.ErrorIs(t, err, constants.ErrMigrationLocked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,21 @@
+DROP TABLE IF EXISTS `risk_reviews`;
+DROP TABLE IF EXISTS `risk_decisions`;
+DROP TABLE IF EXISTS `wallet_pins`;
+DROP TABLE IF EXISTS `promo_grants`;
+DROP TABLE IF EXISTS `campaigns`;
+DROP TABLE IF EXISTS `fee_rules`;
+DROP TABLE IF EXISTS `withdrawals`;
+DROP TABLE IF EXISTS `bank_accounts`;
+DROP TABLE IF EXISTS `topup_intents`;
+DROP TABLE IF EXISTS `payment_requests`;
+DROP TABLE IF EXISTS `scheduled_payments`;
+DROP TABLE IF EXISTS `suspense_entries`;
+DROP TABLE IF EXISTS `reconciliation_mismatches`;
+DROP TABLE IF EXISTS `reconciliation_runs`;
+DROP TABLE IF EXISTS `balance_adjustments`;
+DROP TABLE IF EXISTS `audit_logs`;
+DROP TABLE IF EXISTS `operators`;
+DROP TABLE IF EXISTS `wallet_status_histories`;
+DROP TABLE IF EXISTS `wallet_links`;
+DROP TABLE IF EXISTS `wallet_transactions`;
+DROP TABLE IF EXISTS `wallets`;
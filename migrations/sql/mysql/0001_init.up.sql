@@ -0,0 +1,395 @@
+-- Baseline schema, the tables gorm AutoMigrate used to create. IF NOT EXISTS
+-- lets databases created by AutoMigrate adopt it without changes.
+
+CREATE TABLE IF NOT EXISTS `wallets` (
+  `id` bigint AUTO_INCREMENT,
+  `user_id` bigint unsigned,
+  `email` varchar(100),
+  `balance` decimal(15,2),
+  `held_balance` decimal(15,2) DEFAULT 0,
+  `status` varchar(20) DEFAULT 'active',
+  `tier` varchar(20) DEFAULT 'basic',
+  `promo_balance` decimal(15,2) DEFAULT 0,
+  `spend_order` varchar(20) DEFAULT 'promo_first',
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_wallets_email` (`email`),
+  CONSTRAINT `uni_wallets_user_id` UNIQUE (`user_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `wallet_transactions` (
+  `id` bigint AUTO_INCREMENT,
+  `wallet_id` bigint,
+  `amount` decimal(15,2),
+  `wallet_transaction_type` enum('CREDIT', 'DEBIT', 'ADJUSTMENT', 'REFUND', 'FEE'),
+  `reference` varchar(100),
+  `client_id` varchar(100),
+  `parent_id` bigint,
+  `balance_type` varchar(10) DEFAULT 'cash',
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_wallet_transactions_client_id` (`client_id`),
+  INDEX `idx_wallet_transactions_parent_id` (`parent_id`),
+  CONSTRAINT `uni_wallet_transactions_reference` UNIQUE (`reference`)
+);
+
+CREATE TABLE IF NOT EXISTS `wallet_links` (
+  `id` bigint AUTO_INCREMENT,
+  `wallet_id` bigint,
+  `client_source` varchar(100),
+  `otp` varchar(6),
+  `status` varchar(10),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `wallet_status_histories` (
+  `id` bigint AUTO_INCREMENT,
+  `wallet_id` bigint,
+  `from_status` varchar(20),
+  `to_status` varchar(20),
+  `reason_code` varchar(50),
+  `note` varchar(255),
+  `changed_by` varchar(100),
+  `payout_reference` varchar(100),
+  `payout_amount` decimal(15,2),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_wallet_status_histories_wallet_id` (`wallet_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `operators` (
+  `id` bigint AUTO_INCREMENT,
+  `username` varchar(100),
+  `password_hash` varchar(255),
+  `role` varchar(20),
+  `status` varchar(20) DEFAULT 'active',
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `uni_operators_username` UNIQUE (`username`)
+);
+
+CREATE TABLE IF NOT EXISTS `audit_logs` (
+  `id` bigint AUTO_INCREMENT,
+  `actor_type` varchar(20),
+  `actor` varchar(100),
+  `actor_role` varchar(20),
+  `action` varchar(100),
+  `target_type` varchar(50),
+  `target_id` varchar(100),
+  `request_id` varchar(64),
+  `ip` varchar(45),
+  `before_value` text,
+  `after_value` text,
+  `detail` text,
+  `prev_hash` varchar(64),
+  `hash` varchar(64),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_audit_logs_request_id` (`request_id`),
+  UNIQUE INDEX `idx_audit_logs_prev_hash` (`prev_hash`),
+  UNIQUE INDEX `idx_audit_logs_hash` (`hash`),
+  INDEX `idx_audit_logs_actor` (`actor`),
+  INDEX `idx_audit_logs_action` (`action`)
+);
+
+CREATE TABLE IF NOT EXISTS `balance_adjustments` (
+  `id` bigint AUTO_INCREMENT,
+  `wallet_id` bigint,
+  `amount` decimal(15,2),
+  `direction` varchar(10),
+  `reason_code` varchar(50),
+  `note` varchar(255),
+  `status` varchar(20),
+  `reference` varchar(100),
+  `maker_id` bigint,
+  `maker_username` varchar(100),
+  `checker_id` bigint,
+  `checker_username` varchar(100),
+  `decision_note` varchar(255),
+  `expires_at` datetime(3) NULL,
+  `decided_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_balance_adjustments_wallet_id` (`wallet_id`),
+  INDEX `idx_balance_adjustments_status` (`status`),
+  CONSTRAINT `uni_balance_adjustments_reference` UNIQUE (`reference`)
+);
+
+CREATE TABLE IF NOT EXISTS `reconciliation_runs` (
+  `id` bigint AUTO_INCREMENT,
+  `status` varchar(20),
+  `wallet_count` bigint,
+  `mismatch_count` bigint,
+  `last_transaction_id` bigint,
+  `started_at` datetime(3) NULL,
+  `finished_at` datetime(3) NULL,
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `reconciliation_mismatches` (
+  `id` bigint AUTO_INCREMENT,
+  `run_id` bigint,
+  `wallet_id` bigint,
+  `balance` decimal(15,2),
+  `ledger_balance` decimal(15,2),
+  `difference` decimal(15,2),
+  `window_from_id` bigint,
+  `window_to_id` bigint,
+  `window_from_time` datetime(3) NULL,
+  `window_to_time` datetime(3) NULL,
+  `window_transaction_count` bigint,
+  `suspense_entry_id` bigint,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_reconciliation_mismatches_wallet_id` (`wallet_id`),
+  INDEX `idx_reconciliation_mismatches_run_id` (`run_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `suspense_entries` (
+  `id` bigint AUTO_INCREMENT,
+  `wallet_id` bigint,
+  `run_id` bigint,
+  `amount` decimal(15,2),
+  `status` varchar(20),
+  `note` varchar(255),
+  `resolved_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_suspense_entries_status` (`status`),
+  INDEX `idx_suspense_entries_wallet_id` (`wallet_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `scheduled_payments` (
+  `id` bigint AUTO_INCREMENT,
+  `wallet_id` bigint,
+  `client_id` varchar(100),
+  `amount` decimal(15,2),
+  `description` varchar(255),
+  `frequency` varchar(10),
+  `start_at` datetime(3) NULL,
+  `end_at` datetime(3) NULL,
+  `max_occurrences` bigint,
+  `occurrence_count` bigint,
+  `paid_count` bigint,
+  `retry_count` bigint,
+  `next_run_at` datetime(3) NULL,
+  `last_run_at` datetime(3) NULL,
+  `last_error` varchar(255),
+  `status` varchar(20),
+  `locked_until` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_scheduled_payments_wallet_id` (`wallet_id`),
+  INDEX `idx_scheduled_payments_client_id` (`client_id`),
+  INDEX `idx_scheduled_payments_next_run_at` (`next_run_at`),
+  INDEX `idx_scheduled_payments_status` (`status`)
+);
+
+CREATE TABLE IF NOT EXISTS `payment_requests` (
+  `id` bigint AUTO_INCREMENT,
+  `requester_user_id` bigint unsigned,
+  `requester_wallet_id` bigint,
+  `payer_user_id` bigint unsigned,
+  `payer_wallet_id` bigint,
+  `amount` decimal(15,2),
+  `fee` decimal(15,2) DEFAULT 0,
+  `note` varchar(255),
+  `status` varchar(20),
+  `reference` varchar(100),
+  `expires_at` datetime(3) NULL,
+  `decided_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_payment_requests_requester_user_id` (`requester_user_id`),
+  INDEX `idx_payment_requests_payer_user_id` (`payer_user_id`),
+  INDEX `idx_payment_requests_status` (`status`),
+  INDEX `idx_payment_requests_expires_at` (`expires_at`),
+  CONSTRAINT `uni_payment_requests_reference` UNIQUE (`reference`)
+);
+
+CREATE TABLE IF NOT EXISTS `topup_intents` (
+  `id` bigint AUTO_INCREMENT,
+  `user_id` bigint unsigned,
+  `wallet_id` bigint,
+  `amount` decimal(15,2),
+  `status` varchar(20),
+  `reference` varchar(100),
+  `gateway` varchar(50),
+  `gateway_reference` varchar(100),
+  `payment_code` varchar(100),
+  `payment_url` varchar(255),
+  `failure_reason` varchar(255),
+  `expires_at` datetime(3) NULL,
+  `paid_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_topup_intents_user_id` (`user_id`),
+  INDEX `idx_topup_intents_status` (`status`),
+  INDEX `idx_topup_intents_expires_at` (`expires_at`),
+  CONSTRAINT `uni_topup_intents_reference` UNIQUE (`reference`)
+);
+
+CREATE TABLE IF NOT EXISTS `bank_accounts` (
+  `id` bigint AUTO_INCREMENT,
+  `user_id` bigint unsigned,
+  `bank_code` varchar(20),
+  `account_number` varchar(30),
+  `account_name` varchar(100),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_bank_account_user_number` (`user_id`,`bank_code`,`account_number`)
+);
+
+CREATE TABLE IF NOT EXISTS `withdrawals` (
+  `id` bigint AUTO_INCREMENT,
+  `user_id` bigint unsigned,
+  `wallet_id` bigint,
+  `bank_account_id` bigint,
+  `bank_code` varchar(20),
+  `account_number` varchar(30),
+  `account_name` varchar(100),
+  `amount` decimal(15,2),
+  `fee` decimal(15,2),
+  `status` varchar(20),
+  `reference` varchar(100),
+  `payout_reference` varchar(100),
+  `provider` varchar(50),
+  `provider_reference` varchar(100),
+  `failure_reason` varchar(255),
+  `submitted_at` datetime(3) NULL,
+  `completed_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_withdrawal_user_reference` (`user_id`,`reference`),
+  INDEX `idx_withdrawals_wallet_id` (`wallet_id`),
+  INDEX `idx_withdrawals_status` (`status`),
+  CONSTRAINT `uni_withdrawals_payout_reference` UNIQUE (`payout_reference`)
+);
+
+CREATE TABLE IF NOT EXISTS `fee_rules` (
+  `id` bigint AUTO_INCREMENT,
+  `operation` varchar(30),
+  `client_source` varchar(100),
+  `wallet_tier` varchar(20),
+  `fee_type` varchar(20),
+  `flat_amount` decimal(15,2),
+  `percentage` decimal(7,4),
+  `tiers` text,
+  `min_fee` decimal(15,2),
+  `max_fee` decimal(15,2),
+  `free_quota` bigint,
+  `active` boolean DEFAULT true,
+  `created_by` varchar(100),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_fee_rules_operation` (`operation`)
+);
+
+CREATE TABLE IF NOT EXISTS `campaigns` (
+  `id` bigint AUTO_INCREMENT,
+  `name` varchar(100),
+  `client_source` varchar(100),
+  `percentage` decimal(7,4),
+  `max_cashback` decimal(15,2),
+  `validity_days` bigint,
+  `starts_at` datetime(3) NULL,
+  `ends_at` datetime(3) NULL,
+  `active` boolean DEFAULT true,
+  `created_by` varchar(100),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_campaigns_client_source` (`client_source`)
+);
+
+CREATE TABLE IF NOT EXISTS `promo_grants` (
+  `id` bigint AUTO_INCREMENT,
+  `wallet_id` bigint,
+  `campaign_id` bigint,
+  `amount` decimal(15,2),
+  `transaction_id` bigint,
+  `clawed_back` decimal(15,2) DEFAULT 0,
+  `status` varchar(20),
+  `expires_at` datetime(3) NULL,
+  `granted_by` varchar(100),
+  `note` varchar(255),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_promo_grants_wallet_id` (`wallet_id`),
+  INDEX `idx_promo_grants_campaign_id` (`campaign_id`),
+  INDEX `idx_promo_grants_status_expires` (`status`,`expires_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `wallet_pins` (
+  `id` bigint AUTO_INCREMENT,
+  `wallet_id` bigint,
+  `pin_hash` varchar(100),
+  `failed_attempts` bigint DEFAULT 0,
+  `locked_until` datetime(3) NULL,
+  `reset_otp_hash` varchar(100),
+  `reset_otp_sent_at` datetime(3) NULL,
+  `reset_attempts` bigint DEFAULT 0,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_wallet_pins_wallet_id` (`wallet_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `risk_decisions` (
+  `id` bigint AUTO_INCREMENT,
+  `operation` varchar(20),
+  `transaction_type` varchar(10),
+  `wallet_id` bigint,
+  `user_id` bigint unsigned,
+  `client_id` varchar(100),
+  `amount` decimal(15,2),
+  `reference` varchar(100),
+  `outcome` varchar(10),
+  `reasons` text,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_risk_decisions_wallet_id` (`wallet_id`),
+  INDEX `idx_risk_decisions_reference` (`reference`),
+  INDEX `idx_risk_decisions_outcome` (`outcome`)
+);
+
+CREATE TABLE IF NOT EXISTS `risk_reviews` (
+  `id` bigint AUTO_INCREMENT,
+  `decision_id` bigint,
+  `operation` varchar(20),
+  `transaction_type` varchar(10),
+  `wallet_id` bigint,
+  `user_id` bigint unsigned,
+  `client_id` varchar(100),
+  `amount` decimal(15,2),
+  `reference` varchar(100),
+  `payment_request_id` bigint,
+  `reasons` text,
+  `status` varchar(20),
+  `decided_by` varchar(100),
+  `decision_note` varchar(255),
+  `decided_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_risk_reviews_status` (`status`),
+  INDEX `idx_risk_reviews_wallet_id` (`wallet_id`),
+  UNIQUE INDEX `idx_risk_reviews_reference` (`reference`)
+);
+
+CREATE INDEX `idx_wallet_transactions_wallet_id_id` ON `wallet_transactions` (`wallet_id`, `id`);
+CREATE INDEX `idx_wallet_links_wallet_id_client_source` ON `wallet_links` (`wallet_id`, `client_source`);
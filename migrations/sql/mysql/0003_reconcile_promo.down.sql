@@ -0,0 +1,5 @@
+ALTER TABLE `reconciliation_mismatches` DROP COLUMN `promo_difference`;
+
+ALTER TABLE `reconciliation_mismatches` DROP COLUMN `promo_ledger_balance`;
+
+ALTER TABLE `reconciliation_mismatches` DROP COLUMN `promo_balance`;
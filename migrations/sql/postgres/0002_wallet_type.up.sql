@@ -0,0 +1,6 @@
+-- The fee revenue wallet becomes a system wallet, which no user facing path
+-- can move, instead of being known only by its user id 0.
+
+ALTER TABLE wallets ADD COLUMN type varchar(20) DEFAULT 'user';
+
+UPDATE wallets SET type = 'system' WHERE user_id = 0;
@@ -0,0 +1,3 @@
+-- A failed posting keeps its reason apart from the checker's decision note.
+
+ALTER TABLE balance_adjustments ADD COLUMN failure_reason varchar(255);
@@ -0,0 +1,392 @@
+-- Baseline schema for SQLite, the same tables and indexes as the MySQL
+-- baseline. Ids are integer primary keys so they alias the rowid.
+
+CREATE TABLE IF NOT EXISTS wallets (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  user_id bigint,
+  email varchar(100),
+  balance decimal(15,2),
+  held_balance decimal(15,2) DEFAULT 0,
+  status varchar(20) DEFAULT 'active',
+  tier varchar(20) DEFAULT 'basic',
+  promo_balance decimal(15,2) DEFAULT 0,
+  spend_order varchar(20) DEFAULT 'promo_first',
+  created_at datetime NULL,
+  updated_at datetime NULL,
+  CONSTRAINT uni_wallets_user_id UNIQUE (user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallets_email ON wallets (email);
+
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  wallet_id bigint,
+  amount decimal(15,2),
+  wallet_transaction_type varchar(20) CHECK (wallet_transaction_type IN ('CREDIT', 'DEBIT', 'ADJUSTMENT', 'REFUND', 'FEE')),
+  reference varchar(100),
+  client_id varchar(100),
+  parent_id bigint,
+  balance_type varchar(10) DEFAULT 'cash',
+  created_at datetime NULL,
+  updated_at datetime NULL,
+  CONSTRAINT uni_wallet_transactions_reference UNIQUE (reference)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_client_id ON wallet_transactions (client_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_parent_id ON wallet_transactions (parent_id);
+
+CREATE TABLE IF NOT EXISTS wallet_links (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  wallet_id bigint,
+  client_source varchar(100),
+  otp varchar(6),
+  status varchar(10),
+  created_at datetime NULL,
+  updated_at datetime NULL
+);
+
+CREATE TABLE IF NOT EXISTS wallet_status_histories (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  wallet_id bigint,
+  from_status varchar(20),
+  to_status varchar(20),
+  reason_code varchar(50),
+  note varchar(255),
+  changed_by varchar(100),
+  payout_reference varchar(100),
+  payout_amount decimal(15,2),
+  created_at datetime NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_status_histories_wallet_id ON wallet_status_histories (wallet_id);
+
+CREATE TABLE IF NOT EXISTS operators (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  username varchar(100),
+  password_hash varchar(255),
+  role varchar(20),
+  status varchar(20) DEFAULT 'active',
+  created_at datetime NULL,
+  updated_at datetime NULL,
+  CONSTRAINT uni_operators_username UNIQUE (username)
+);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  actor_type varchar(20),
+  actor varchar(100),
+  actor_role varchar(20),
+  action varchar(100),
+  target_type varchar(50),
+  target_id varchar(100),
+  request_id varchar(64),
+  ip varchar(45),
+  before_value text,
+  after_value text,
+  detail text,
+  prev_hash varchar(64),
+  hash varchar(64),
+  created_at datetime NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs (request_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_prev_hash ON audit_logs (prev_hash);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_hash ON audit_logs (hash);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  wallet_id bigint,
+  amount decimal(15,2),
+  direction varchar(10),
+  reason_code varchar(50),
+  note varchar(255),
+  status varchar(20),
+  reference varchar(100),
+  maker_id bigint,
+  maker_username varchar(100),
+  checker_id bigint,
+  checker_username varchar(100),
+  decision_note varchar(255),
+  expires_at datetime NULL,
+  decided_at datetime NULL,
+  created_at datetime NULL,
+  updated_at datetime NULL,
+  CONSTRAINT uni_balance_adjustments_reference UNIQUE (reference)
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_wallet_id ON balance_adjustments (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_status ON balance_adjustments (status);
+
+CREATE TABLE IF NOT EXISTS reconciliation_runs (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  status varchar(20),
+  wallet_count bigint,
+  mismatch_count bigint,
+  last_transaction_id bigint,
+  started_at datetime NULL,
+  finished_at datetime NULL
+);
+
+CREATE TABLE IF NOT EXISTS reconciliation_mismatches (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  run_id bigint,
+  wallet_id bigint,
+  balance decimal(15,2),
+  ledger_balance decimal(15,2),
+  difference decimal(15,2),
+  window_from_id bigint,
+  window_to_id bigint,
+  window_from_time datetime NULL,
+  window_to_time datetime NULL,
+  window_transaction_count bigint,
+  suspense_entry_id bigint,
+  created_at datetime NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_reconciliation_mismatches_wallet_id ON reconciliation_mismatches (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_mismatches_run_id ON reconciliation_mismatches (run_id);
+
+CREATE TABLE IF NOT EXISTS suspense_entries (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  wallet_id bigint,
+  run_id bigint,
+  amount decimal(15,2),
+  status varchar(20),
+  note varchar(255),
+  resolved_at datetime NULL,
+  created_at datetime NULL,
+  updated_at datetime NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_suspense_entries_status ON suspense_entries (status);
+CREATE INDEX IF NOT EXISTS idx_suspense_entries_wallet_id ON suspense_entries (wallet_id);
+
+CREATE TABLE IF NOT EXISTS scheduled_payments (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  wallet_id bigint,
+  client_id varchar(100),
+  amount decimal(15,2),
+  description varchar(255),
+  frequency varchar(10),
+  start_at datetime NULL,
+  end_at datetime NULL,
+  max_occurrences bigint,
+  occurrence_count bigint,
+  paid_count bigint,
+  retry_count bigint,
+  next_run_at datetime NULL,
+  last_run_at datetime NULL,
+  last_error varchar(255),
+  status varchar(20),
+  locked_until datetime NULL,
+  created_at datetime NULL,
+  updated_at datetime NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_payments_wallet_id ON scheduled_payments (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_payments_client_id ON scheduled_payments (client_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_payments_next_run_at ON scheduled_payments (next_run_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_payments_status ON scheduled_payments (status);
+
+CREATE TABLE IF NOT EXISTS payment_requests (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  requester_user_id bigint,
+  requester_wallet_id bigint,
+  payer_user_id bigint,
+  payer_wallet_id bigint,
+  amount decimal(15,2),
+  fee decimal(15,2) DEFAULT 0,
+  note varchar(255),
+  status varchar(20),
+  reference varchar(100),
+  expires_at datetime NULL,
+  decided_at datetime NULL,
+  created_at datetime NULL,
+  updated_at datetime NULL,
+  CONSTRAINT uni_payment_requests_reference UNIQUE (reference)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_requests_requester_user_id ON payment_requests (requester_user_id);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_payer_user_id ON payment_requests (payer_user_id);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_status ON payment_requests (status);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_expires_at ON payment_requests (expires_at);
+
+CREATE TABLE IF NOT EXISTS topup_intents (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  user_id bigint,
+  wallet_id bigint,
+  amount decimal(15,2),
+  status varchar(20),
+  reference varchar(100),
+  gateway varchar(50),
+  gateway_reference varchar(100),
+  payment_code varchar(100),
+  payment_url varchar(255),
+  failure_reason varchar(255),
+  expires_at datetime NULL,
+  paid_at datetime NULL,
+  created_at datetime NULL,
+  updated_at datetime NULL,
+  CONSTRAINT uni_topup_intents_reference UNIQUE (reference)
+);
+
+CREATE INDEX IF NOT EXISTS idx_topup_intents_user_id ON topup_intents (user_id);
+CREATE INDEX IF NOT EXISTS idx_topup_intents_status ON topup_intents (status);
+CREATE INDEX IF NOT EXISTS idx_topup_intents_expires_at ON topup_intents (expires_at);
+
+CREATE TABLE IF NOT EXISTS bank_accounts (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  user_id bigint,
+  bank_code varchar(20),
+  account_number varchar(30),
+  account_name varchar(100),
+  created_at datetime NULL,
+  updated_at datetime NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bank_account_user_number ON bank_accounts (user_id, bank_code, account_number);
+
+CREATE TABLE IF NOT EXISTS withdrawals (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  user_id bigint,
+  wallet_id bigint,
+  bank_account_id bigint,
+  bank_code varchar(20),
+  account_number varchar(30),
+  account_name varchar(100),
+  amount decimal(15,2),
+  fee decimal(15,2),
+  status varchar(20),
+  reference varchar(100),
+  payout_reference varchar(100),
+  provider varchar(50),
+  provider_reference varchar(100),
+  failure_reason varchar(255),
+  submitted_at datetime NULL,
+  completed_at datetime NULL,
+  created_at datetime NULL,
+  updated_at datetime NULL,
+  CONSTRAINT uni_withdrawals_payout_reference UNIQUE (payout_reference)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_withdrawal_user_reference ON withdrawals (user_id, reference);
+CREATE INDEX IF NOT EXISTS idx_withdrawals_wallet_id ON withdrawals (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_withdrawals_status ON withdrawals (status);
+
+CREATE TABLE IF NOT EXISTS fee_rules (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  operation varchar(30),
+  client_source varchar(100),
+  wallet_tier varchar(20),
+  fee_type varchar(20),
+  flat_amount decimal(15,2),
+  percentage decimal(7,4),
+  tiers text,
+  min_fee decimal(15,2),
+  max_fee decimal(15,2),
+  free_quota bigint,
+  active boolean DEFAULT true,
+  created_by varchar(100),
+  created_at datetime NULL,
+  updated_at datetime NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_fee_rules_operation ON fee_rules (operation);
+
+CREATE TABLE IF NOT EXISTS campaigns (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  name varchar(100),
+  client_source varchar(100),
+  percentage decimal(7,4),
+  max_cashback decimal(15,2),
+  validity_days bigint,
+  starts_at datetime NULL,
+  ends_at datetime NULL,
+  active boolean DEFAULT true,
+  created_by varchar(100),
+  created_at datetime NULL,
+  updated_at datetime NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_client_source ON campaigns (client_source);
+
+CREATE TABLE IF NOT EXISTS promo_grants (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  wallet_id bigint,
+  campaign_id bigint,
+  amount decimal(15,2),
+  transaction_id bigint,
+  clawed_back decimal(15,2) DEFAULT 0,
+  status varchar(20),
+  expires_at datetime NULL,
+  granted_by varchar(100),
+  note varchar(255),
+  created_at datetime NULL,
+  updated_at datetime NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_grants_wallet_id ON promo_grants (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_promo_grants_campaign_id ON promo_grants (campaign_id);
+CREATE INDEX IF NOT EXISTS idx_promo_grants_status_expires ON promo_grants (status, expires_at);
+
+CREATE TABLE IF NOT EXISTS wallet_pins (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  wallet_id bigint,
+  pin_hash varchar(100),
+  failed_attempts bigint DEFAULT 0,
+  locked_until datetime NULL,
+  reset_otp_hash varchar(100),
+  reset_otp_sent_at datetime NULL,
+  reset_attempts bigint DEFAULT 0,
+  created_at datetime NULL,
+  updated_at datetime NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_pins_wallet_id ON wallet_pins (wallet_id);
+
+CREATE TABLE IF NOT EXISTS risk_decisions (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  operation varchar(20),
+  transaction_type varchar(10),
+  wallet_id bigint,
+  user_id bigint,
+  client_id varchar(100),
+  amount decimal(15,2),
+  reference varchar(100),
+  outcome varchar(10),
+  reasons text,
+  created_at datetime NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_risk_decisions_wallet_id ON risk_decisions (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_risk_decisions_reference ON risk_decisions (reference);
+CREATE INDEX IF NOT EXISTS idx_risk_decisions_outcome ON risk_decisions (outcome);
+
+CREATE TABLE IF NOT EXISTS risk_reviews (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  decision_id bigint,
+  operation varchar(20),
+  transaction_type varchar(10),
+  wallet_id bigint,
+  user_id bigint,
+  client_id varchar(100),
+  amount decimal(15,2),
+  reference varchar(100),
+  payment_request_id bigint,
+  reasons text,
+  status varchar(20),
+  decided_by varchar(100),
+  decision_note varchar(255),
+  decided_at datetime NULL,
+  created_at datetime NULL,
+  updated_at datetime NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_risk_reviews_status ON risk_reviews (status);
+CREATE INDEX IF NOT EXISTS idx_risk_reviews_wallet_id ON risk_reviews (wallet_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_risk_reviews_reference ON risk_reviews (reference);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_wallet_id_id ON wallet_transactions (wallet_id, id);
+CREATE INDEX IF NOT EXISTS idx_wallet_links_wallet_id_client_source ON wallet_links (wallet_id, client_source);
@@ -0,0 +1,7 @@
+-- Reconciliation also checks the promo balance against the promo ledger rows.
+
+ALTER TABLE reconciliation_mismatches ADD COLUMN promo_balance decimal(15,2);
+
+ALTER TABLE reconciliation_mismatches ADD COLUMN promo_ledger_balance decimal(15,2);
+
+ALTER TABLE reconciliation_mismatches ADD COLUMN promo_difference decimal(15,2);
@@ -0,0 +1 @@
+ALTER TABLE balance_adjustments DROP COLUMN failure_reason;
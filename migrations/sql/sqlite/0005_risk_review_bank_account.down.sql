@@ -0,0 +1 @@
+ALTER TABLE risk_reviews DROP COLUMN bank_account_id;
@@ -0,0 +1,3 @@
+-- A held withdrawal keeps its bank account so the approval can replay it.
+
+ALTER TABLE risk_reviews ADD COLUMN bank_account_id bigint;